@@ -6,6 +6,9 @@ ALLTOOLS_ARCHIVE_NAME="core-geth-alltools-${BUILD_OS_NAME}-$(git describe --abbr
 
 if [[ "${BUILD_OS_NAME}" == "win64" ]]; then
   7z a "$GETH_ARCHIVE_NAME.zip" ./build/bin/geth.exe
+  if [[ -f ./build/bin/geth.exe.sig ]]; then
+    7z a "$GETH_ARCHIVE_NAME.zip" ./build/bin/geth.exe.sig
+  fi
 
   sha256sum $GETH_ARCHIVE_NAME.zip
   sha256sum $GETH_ARCHIVE_NAME.zip > $GETH_ARCHIVE_NAME.zip.sha256
@@ -17,6 +20,9 @@ if [[ "${BUILD_OS_NAME}" == "win64" ]]; then
 
 else
   zip -j "$GETH_ARCHIVE_NAME.zip" build/bin/geth
+  if [[ -f build/bin/geth.sig ]]; then
+    zip -j "$GETH_ARCHIVE_NAME.zip" build/bin/geth.sig
+  fi
 
   shasum -a 256 $GETH_ARCHIVE_NAME.zip
   shasum -a 256 $GETH_ARCHIVE_NAME.zip > $GETH_ARCHIVE_NAME.zip.sha256
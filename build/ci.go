@@ -24,7 +24,7 @@ Usage: go run build/ci.go <command> <command flags/arguments>
 
 Available commands are:
 
-	install    [ -arch architecture ] [ -cc compiler ] [ packages... ]                          -- builds packages and executables
+	install    [ -arch architecture ] [ -cc compiler ] [ -signify key-envvar ] [ packages... ]  -- builds packages and executables
 	test       [ -coverage ] [ packages... ]                                                    -- runs the tests
 	lint                                                                                        -- runs certain pre-selected linters
 	archive    [ -arch architecture ] [ -type zip|tar ] [ -signer key-envvar ] [ -signify key-envvar ] [ -upload dest ] -- archives build artifacts
@@ -39,6 +39,7 @@ package main
 
 import (
 	"bytes"
+	"debug/buildinfo"
 	"encoding/base64"
 	"flag"
 	"fmt"
@@ -56,6 +57,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto/signify"
 	"github.com/ethereum/go-ethereum/internal/build"
+	"github.com/ethereum/go-ethereum/internal/version"
 	"github.com/ethereum/go-ethereum/params"
 )
 
@@ -195,6 +197,7 @@ func doInstall(cmdline []string) {
 		arch       = flag.String("arch", "", "Architecture to cross build for")
 		cc         = flag.String("cc", "", "C compiler to cross build with")
 		staticlink = flag.Bool("static", false, "Create statically-linked executable")
+		signifyVar = flag.String("signify", "", `Environment variable holding the signify key used to sign the build provenance and executables (e.g. LINUX_SIGNIFY_KEY)`)
 	)
 	flag.CommandLine.Parse(cmdline)
 	env := build.Env()
@@ -248,6 +251,60 @@ func doInstall(cmdline []string) {
 		args = append(args, "-o", executablePath(path.Base(pkg)))
 		args = append(args, pkg)
 		build.MustRun(&exec.Cmd{Path: gobuild.Path, Args: args, Env: gobuild.Env})
+
+		if *signifyVar != "" && !*build.DryRunFlag {
+			embedProvenance(gobuild, pkg, env, *signifyVar)
+		}
+	}
+}
+
+// embedProvenance rebuilds the executable of the package, embedding its signed
+// build provenance. The provenance is derived from the executable built first,
+// the second build only adds linker flags and thus links the same code.
+func embedProvenance(gobuild *exec.Cmd, pkg string, env build.Environment, signifyVar string) {
+	key := os.Getenv(signifyVar)
+	if key == "" {
+		log.Fatalf("signify key %s is not set", signifyVar)
+	}
+	exe := executablePath(path.Base(pkg))
+	info, err := buildinfo.ReadFile(exe)
+	if err != nil {
+		log.Fatalf("Failed to read build info of %s: %v", exe, err)
+	}
+	builder := "local build"
+	if env.CI {
+		builder = fmt.Sprintf("%s build %s of %s", env.Name, env.Buildnum, env.Repo)
+	}
+	p := version.NewProvenance(info, builder, env.Commit, env.Date)
+	ld, err := version.EncodeProvenance(p, func(data []byte) ([]byte, error) {
+		comment := fmt.Sprintf("provenance of %s %s", path.Base(pkg), p.Version)
+		return signify.Sign(data, key, "", comment)
+	})
+	if err != nil {
+		log.Fatalf("Failed to sign build provenance of %s: %v", exe, err)
+	}
+	// Extend the existing linker flags, the go tool only honours the last ones.
+	args := make([]string, 0, len(gobuild.Args)+4)
+	extended := false
+	for i := 0; i < len(gobuild.Args); i++ {
+		args = append(args, gobuild.Args[i])
+		if gobuild.Args[i] == "-ldflags" && i+1 < len(gobuild.Args) {
+			i++
+			args = append(args, gobuild.Args[i]+" "+strings.Join(ld, " "))
+			extended = true
+		}
+	}
+	if !extended {
+		args = append(args, "-ldflags", strings.Join(ld, " "))
+	}
+	args = append(args, "-o", exe, pkg)
+	build.MustRun(&exec.Cmd{Path: gobuild.Path, Args: args, Env: gobuild.Env})
+
+	// The provenance only describes the build, sign the final executable too so
+	// verify-binary can check that its contents are unmodified.
+	trusted := fmt.Sprintf("%s %s (%s)", path.Base(pkg), p.Version, time.Now().UTC().Format(time.RFC1123))
+	if err := signify.SignFile(exe, exe+".sig", key, "verify with geth-release.pub", trusted); err != nil {
+		log.Fatalf("Failed to sign %s: %v", exe, err)
 	}
 }
 
@@ -440,10 +497,10 @@ func doArchive(cmdline []string) {
 		alltools = "geth-alltools-" + basegeth + ext
 	)
 	maybeSkipArchive(env)
-	if err := build.WriteArchive(geth, gethArchiveFiles); err != nil {
+	if err := build.WriteArchive(geth, withSignatures(gethArchiveFiles)); err != nil {
 		log.Fatal(err)
 	}
-	if err := build.WriteArchive(alltools, allToolsArchiveFiles); err != nil {
+	if err := build.WriteArchive(alltools, withSignatures(allToolsArchiveFiles)); err != nil {
 		log.Fatal(err)
 	}
 	for _, archive := range []string{geth, alltools} {
@@ -453,6 +510,18 @@ func doArchive(cmdline []string) {
 	}
 }
 
+// withSignatures adds the detached executable signatures created by the install
+// -signify option to the archived files.
+func withSignatures(files []string) []string {
+	all := append([]string{}, files...)
+	for _, file := range files {
+		if _, err := os.Stat(file + ".sig"); err == nil {
+			all = append(all, file+".sig")
+		}
+	}
+	return all
+}
+
 func archiveBasename(arch string, archiveVersion string) string {
 	platform := runtime.GOOS + "-" + arch
 	if arch == "arm" {
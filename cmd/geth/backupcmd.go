@@ -0,0 +1,249 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	backupDestFlag = &cli.StringFlag{
+		Name:     "dest",
+		Usage:    "Directory to write the backup to, must not exist yet, or an s3://bucket/prefix URL to upload it to",
+		Required: true,
+	}
+	backupS3EndpointFlag = &cli.StringFlag{
+		Name:  "s3.endpoint",
+		Usage: "URL of the S3 compatible storage service to upload s3:// backups to (default: AWS S3 in the configured region)",
+	}
+	backupSrcFlag = &cli.StringFlag{
+		Name:     "src",
+		Usage:    "Directory of the backup to restore",
+		Required: true,
+	}
+	backupCommand = &cli.Command{
+		Name:  "backup",
+		Usage: "Back up and restore the chain database",
+		Subcommands: []*cli.Command{
+			{
+				Action: backupCreate,
+				Name:   "create",
+				Usage:  "Back up the chain database of a running node",
+				Flags:  append([]cli.Flag{backupDestFlag, backupS3EndpointFlag}, ctlFlags...),
+				Description: `
+    geth backup create --dest /backups/2024-03-01
+    geth backup create --dest s3://backups/2024-03-01
+
+Instructs a running node, over IPC by default, to write a consistent copy of its
+chain database into the destination directory, which is resolved on the machine
+of the node. The node keeps running while the backup is taken: the key-value
+store is copied from a database snapshot and the append-only ancient store up to
+the head block of that snapshot. The state history of path-based state storage
+is not backed up, so the node cannot roll back its state past the restored head.
+
+Backups to s3:// URLs are staged in a temporary directory, which must be on the
+machine of the node, and uploaded from there. The AWS credentials and region are
+taken from the environment (AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_REGION)
+or the shared AWS configuration files. To restore such a backup, download it
+into a local directory first, e.g. with 'aws s3 cp --recursive'.`,
+			},
+			{
+				Action: backupRestore,
+				Name:   "restore",
+				Usage:  "Restore the chain database from a backup",
+				Flags:  []cli.Flag{backupSrcFlag, utils.DataDirFlag, utils.AncientFlag},
+				Description: `
+    geth backup restore --src /backups/2024-03-01
+
+Copies a backup made with 'geth backup create' into the data directory. The node
+must be stopped, and its existing chain database removed first, e.g. with
+'geth removedb'.`,
+			},
+		},
+	}
+)
+
+func backupCreate(ctx *cli.Context) error {
+	loc, remote, err := parseS3URL(ctx.String(backupDestFlag.Name))
+	if err != nil {
+		utils.Fatalf("Invalid backup destination: %v", err)
+	}
+	if remote {
+		return backupCreateS3(ctx, loc)
+	}
+	dest, err := filepath.Abs(ctx.String(backupDestFlag.Name))
+	if err != nil {
+		utils.Fatalf("Invalid backup destination: %v", err)
+	}
+	var (
+		stats rawdb.BackupStats
+		start = time.Now()
+	)
+	ctlCall(ctx, &stats, "admin_backup", dest)
+	log.Info("Backup created", "dest", dest, "head", stats.Head, "keys", stats.Keys, "size", stats.Size, "ancients", stats.Ancients, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// backupCreateS3 backs up the chain database into a temporary directory and
+// uploads it to S3.
+func backupCreateS3(ctx *cli.Context, loc s3Location) error {
+	uploader, err := newS3Uploader(ctx.Context, ctx.String(backupS3EndpointFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to set up S3 upload: %v", err)
+	}
+	staging, err := os.MkdirTemp("", "geth-backup-")
+	if err != nil {
+		utils.Fatalf("Failed to create staging directory: %v", err)
+	}
+	defer os.RemoveAll(staging)
+
+	var (
+		stats rawdb.BackupStats
+		dest  = filepath.Join(staging, "backup")
+		start = time.Now()
+	)
+	ctlCall(ctx, &stats, "admin_backup", dest)
+	log.Info("Backup staged", "dir", dest, "head", stats.Head, "keys", stats.Keys, "size", stats.Size, "ancients", stats.Ancients, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	files, size, err := uploader.uploadDir(ctx.Context, dest, loc)
+	if err != nil {
+		utils.Fatalf("Failed to upload backup: %v", err)
+	}
+	log.Info("Backup uploaded", "bucket", loc.bucket, "prefix", loc.prefix, "files", files, "size", common.StorageSize(size), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func backupRestore(ctx *cli.Context) error {
+	src := filepath.Join(ctx.String(backupSrcFlag.Name), "chaindata")
+	if !common.FileExist(src) {
+		utils.Fatalf("No backup found in %s", ctx.String(backupSrcFlag.Name))
+	}
+	// Creating the node locks the data directory, ensuring it is not in use
+	stack, config := makeConfigNode(ctx)
+	defer stack.Close()
+
+	var (
+		rootDir    = stack.ResolvePath("chaindata")
+		ancientDir = config.Eth.DatabaseFreezer
+	)
+	switch {
+	case ancientDir == "":
+		ancientDir = filepath.Join(rootDir, "ancient")
+	case !filepath.IsAbs(ancientDir):
+		ancientDir = config.Node.ResolvePath(ancientDir)
+	}
+	for _, dir := range []string{rootDir, ancientDir} {
+		if hasFiles(dir) {
+			utils.Fatalf("Database already exists in %s, remove it first with 'geth removedb'", dir)
+		}
+	}
+	start := time.Now()
+	srcAncient := filepath.Join(src, "ancient")
+	if err := copyTree(src, rootDir, srcAncient); err != nil {
+		utils.Fatalf("Failed to restore key-value store: %v", err)
+	}
+	if err := copyTree(srcAncient, ancientDir, ""); err != nil {
+		utils.Fatalf("Failed to restore ancient store: %v", err)
+	}
+	// Open the restored database to verify it is usable
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	head := rawdb.ReadHeadBlock(db)
+	if head == nil {
+		utils.Fatalf("Restored database has no head block")
+	}
+	log.Info("Backup restored", "number", head.Number(), "hash", head.Hash(), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// hasFiles reports whether the directory contains any files, in any of its
+// subdirectories.
+func hasFiles(dir string) bool {
+	errFound := errors.New("found")
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() {
+			return errFound
+		}
+		return nil
+	})
+	return err == errFound
+}
+
+// copyTree recursively copies the files of the src directory into dest, except
+// the subtree rooted at skip.
+func copyTree(src, dest, skip string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == skip {
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		return copyFile(path, target)
+	})
+}
+
+// copyFile copies a file, keeping its permissions.
+func copyFile(src, dest string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to sync %s: %v", dest, err)
+	}
+	return out.Close()
+}
@@ -0,0 +1,141 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCopyTree(t *testing.T) {
+	var (
+		src  = t.TempDir()
+		dest = filepath.Join(t.TempDir(), "chaindata")
+	)
+	for _, file := range []string{"CURRENT", "000001.log", "ancient/chain/headers.cidx"} {
+		path := filepath.Join(src, file)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(file), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if hasFiles(dest) {
+		t.Fatal("missing directory reported to have files")
+	}
+	if err := copyTree(src, dest, filepath.Join(src, "ancient")); err != nil {
+		t.Fatalf("copy failed: %v", err)
+	}
+	for _, file := range []string{"CURRENT", "000001.log"} {
+		if blob, err := os.ReadFile(filepath.Join(dest, file)); err != nil || string(blob) != file {
+			t.Errorf("file %s not copied: %v", file, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dest, "ancient")); !os.IsNotExist(err) {
+		t.Error("skipped subtree copied")
+	}
+	if !hasFiles(dest) {
+		t.Fatal("restored directory reported to have no files")
+	}
+	// Existing files must not be overwritten
+	if err := copyTree(src, dest, ""); err == nil {
+		t.Fatal("copy overwrote existing files")
+	}
+}
+
+func TestParseS3URL(t *testing.T) {
+	tests := []struct {
+		dest   string
+		remote bool
+		loc    s3Location
+		fail   bool
+	}{
+		{dest: "/backups/today"},
+		{dest: "s3://bucket", remote: true, loc: s3Location{bucket: "bucket"}},
+		{dest: "s3://bucket/daily/today/", remote: true, loc: s3Location{bucket: "bucket", prefix: "daily/today"}},
+		{dest: "s3:///today", remote: true, fail: true},
+	}
+	for _, tt := range tests {
+		loc, remote, err := parseS3URL(tt.dest)
+		if remote != tt.remote || (err != nil) != tt.fail || (err == nil && loc != tt.loc) {
+			t.Errorf("%s: have %+v (remote %v, err %v), want %+v (remote %v, fail %v)", tt.dest, loc, remote, err, tt.loc, tt.remote, tt.fail)
+		}
+	}
+}
+
+func TestS3Upload(t *testing.T) {
+	t.Setenv("AWS_REGION", "eu-central-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+
+	var (
+		lock    sync.Mutex
+		objects = make(map[string]string)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		blob, _ := io.ReadAll(r.Body)
+		lock.Lock()
+		objects[r.URL.Path] = string(blob)
+		lock.Unlock()
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	for _, file := range []string{"chaindata/CURRENT", "chaindata/ancient/chain/headers.cidx"} {
+		path := filepath.Join(dir, file)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(file), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	uploader, err := newS3Uploader(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("failed to create uploader: %v", err)
+	}
+	files, size, err := uploader.uploadDir(context.Background(), dir, s3Location{bucket: "backups", prefix: "today"})
+	if err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+	if files != 2 || size != int64(len("chaindata/CURRENT")+len("chaindata/ancient/chain/headers.cidx")) {
+		t.Fatalf("upload stats mismatch: %d files, %d bytes", files, size)
+	}
+	for _, file := range []string{"chaindata/CURRENT", "chaindata/ancient/chain/headers.cidx"} {
+		if have := objects["/backups/today/"+file]; have != file {
+			t.Errorf("object %s mismatch: have %q", file, have)
+		}
+	}
+	// Rejected uploads are reported
+	t.Setenv("AWS_ACCESS_KEY_ID", "other")
+	if uploader, err = newS3Uploader(context.Background(), server.URL); err != nil {
+		t.Fatalf("failed to create uploader: %v", err)
+	}
+	if _, _, err := uploader.uploadDir(context.Background(), dir, s3Location{bucket: "backups"}); err == nil {
+		t.Fatal("rejected upload succeeded")
+	}
+}
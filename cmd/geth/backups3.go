@@ -0,0 +1,168 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// s3Location is an S3 bucket and the key prefix of the objects stored in it.
+type s3Location struct {
+	bucket string
+	prefix string
+}
+
+// parseS3URL parses an s3://bucket/prefix destination. It reports false for
+// destinations which are not S3 URLs.
+func parseS3URL(dest string) (s3Location, bool, error) {
+	if !strings.HasPrefix(dest, "s3://") {
+		return s3Location{}, false, nil
+	}
+	u, err := url.Parse(dest)
+	if err != nil {
+		return s3Location{}, true, err
+	}
+	if u.Host == "" {
+		return s3Location{}, true, errors.New("missing bucket name")
+	}
+	return s3Location{bucket: u.Host, prefix: strings.Trim(u.Path, "/")}, true, nil
+}
+
+// key returns the object key of a file in the uploaded directory.
+func (l s3Location) key(rel string) string {
+	return path.Join(l.prefix, filepath.ToSlash(rel))
+}
+
+// s3Uploader uploads files to S3, or an S3 compatible storage service, with
+// signed path-style PUT requests.
+type s3Uploader struct {
+	client   *http.Client
+	endpoint string
+	region   string
+	creds    aws.CredentialsProvider
+	signer   *v4.Signer
+}
+
+// newS3Uploader creates an uploader using the credentials and region of the
+// default AWS configuration, i.e. the AWS_* environment variables and the
+// shared configuration files. An empty endpoint selects AWS S3 in the region.
+func newS3Uploader(ctx context.Context, endpoint string) (*s3Uploader, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't initialize AWS configuration: %v", err)
+	}
+	if cfg.Region == "" {
+		return nil, errors.New("no AWS region configured, set AWS_REGION")
+	}
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	}
+	return &s3Uploader{
+		client:   http.DefaultClient,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		region:   cfg.Region,
+		creds:    cfg.Credentials,
+		signer:   v4.NewSigner(),
+	}, nil
+}
+
+// uploadDir uploads all files of the directory below the given location,
+// returning the number of files and bytes uploaded.
+func (u *s3Uploader) uploadDir(ctx context.Context, dir string, loc s3Location) (int, int64, error) {
+	var (
+		files int
+		size  int64
+	)
+	err := filepath.WalkDir(dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		n, err := u.put(ctx, loc.bucket, loc.key(rel), file)
+		if err != nil {
+			return fmt.Errorf("failed to upload %s: %v", rel, err)
+		}
+		files, size = files+1, size+n
+		return nil
+	})
+	return files, size, err
+}
+
+// put uploads a file as the object with the given key.
+func (u *s3Uploader) put(ctx context.Context, bucket, key, file string) (int64, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	// The payload is signed too, hash it before rewinding for the upload
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, f)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	target := u.endpoint + "/" + url.PathEscape(bucket) + "/" + (&url.URL{Path: key}).EscapedPath()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, io.NopCloser(f))
+	if err != nil {
+		return 0, err
+	}
+	req.ContentLength = size
+	payloadHash := hex.EncodeToString(hasher.Sum(nil))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	creds, err := u.creds.Retrieve(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("can't retrieve AWS credentials: %v", err)
+	}
+	if err := u.signer.SignHTTP(ctx, creds, req, payloadHash, "s3", u.region, time.Now()); err != nil {
+		return 0, err
+	}
+	res, err := u.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return 0, fmt.Errorf("%s: %s", res.Status, strings.TrimSpace(string(body)))
+	}
+	return size, nil
+}
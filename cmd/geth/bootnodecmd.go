@@ -0,0 +1,195 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// bootnodeDefaultPort is the default UDP port of the discovery listener,
+	// matching the standalone bootnode.
+	bootnodeDefaultPort = 30301
+
+	// bootnodeReportInterval is the interval at which the local node record
+	// is checked for changes and the node set file is rewritten.
+	bootnodeReportInterval = 5 * time.Minute
+)
+
+var (
+	bootnodeNetworkFlag = &cli.StringFlag{
+		Name:  "network",
+		Usage: "Network to serve discovery for (e.g. classic, mordor)",
+	}
+	bootnodeNodesFileFlag = &cli.StringFlag{
+		Name:  "bootnode.nodesfile",
+		Usage: "Periodically write the live nodes of the discovery table to this file, in the node set format used by 'devp2p dns'",
+	}
+	bootnodeCommand = &cli.Command{
+		Action: bootnode,
+		Name:   "bootnode",
+		Usage:  "Run a discovery-only bootstrap node",
+		Flags: flags.Merge(utils.NetworkFlags, []cli.Flag{
+			bootnodeNetworkFlag,
+			bootnodeNodesFileFlag,
+			utils.DataDirFlag,
+			utils.NodeKeyFileFlag,
+			utils.NodeKeyHexFlag,
+			utils.DiscoveryPortFlag,
+			utils.NATFlag,
+			utils.NetrestrictFlag,
+			utils.BootnodesFlag,
+			utils.DiscoveryV4Flag,
+			utils.DiscoveryV5Flag,
+		}, metricsFlags),
+		Description: `
+    geth bootnode --network classic
+
+Runs a bootstrap node for the discovery protocols of the given network, without
+a chain database or RPC endpoints and without accepting or dialing any peers.
+
+The node key and node database are kept in the 'bootnode' directory of the
+network's data directory, so the node identity, its record sequence number and
+the discovery table survive restarts. The node seeds its table from the default
+bootnodes of the network, unless --bootnodes is given.
+
+If --bootnode.nodesfile is set, the live nodes of the discovery table are
+regularly written to the given file, which can be signed and published to DNS
+using the 'devp2p dns' commands.`,
+	}
+)
+
+// bootnode runs a discovery-only node until interrupted.
+func bootnode(ctx *cli.Context) error {
+	if args := ctx.Args().Slice(); len(args) > 0 {
+		return fmt.Errorf("invalid command: %q", args[0])
+	}
+	if network := ctx.String(bootnodeNetworkFlag.Name); network != "" {
+		if utils.IsNetworkPreset(ctx) {
+			return fmt.Errorf("--%s can't be used together with network flags", bootnodeNetworkFlag.Name)
+		}
+		if err := ctx.Set(network, "true"); err != nil {
+			return fmt.Errorf("unknown network %q", network)
+		}
+	}
+	cfg := defaultNodeConfig()
+	utils.SetNodeConfig(ctx, &cfg)
+
+	// Strip the node down to discovery only
+	cfg.Name = "bootnode"
+	cfg.IPCPath, cfg.HTTPHost, cfg.WSHost = "", "", ""
+	cfg.P2P.MaxPeers = 0
+	cfg.P2P.NoDial = true
+	cfg.P2P.NoDiscovery = false
+	cfg.P2P.ListenAddr = ""
+	if cfg.P2P.DiscAddr == "" {
+		cfg.P2P.DiscAddr = fmt.Sprintf(":%d", bootnodeDefaultPort)
+	}
+	stack, err := node.New(&cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create the bootnode: %v", err)
+	}
+	defer stack.Close()
+
+	utils.SetupMetrics(ctx)
+	utils.StartNode(ctx, stack, false)
+
+	server := stack.Server()
+	self := server.Self()
+	log.Info("Bootnode started", "enode", self.URLv4(), "enr", self.String())
+
+	quit := make(chan struct{})
+	go reportBootnode(server, ctx.String(bootnodeNodesFileFlag.Name), quit)
+	stack.Wait()
+	close(quit)
+	return nil
+}
+
+// reportBootnode logs changes of the local node record and writes the node
+// set file, until the node is shut down.
+func reportBootnode(server *p2p.Server, nodesFile string, quit chan struct{}) {
+	var (
+		ticker = time.NewTicker(bootnodeReportInterval)
+		seq    = server.Self().Seq()
+	)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if self := server.Self(); self.Seq() != seq {
+				seq = self.Seq()
+				log.Info("Local node record updated", "seq", seq, "enr", self.String())
+			}
+			if nodesFile != "" {
+				if err := writeBootnodeNodes(server, nodesFile); err != nil {
+					log.Warn("Failed to write node set", "file", nodesFile, "err", err)
+				}
+			}
+		case <-quit:
+			return
+		}
+	}
+}
+
+// bootnodeNodeJSON is the node set entry format of the devp2p tool.
+type bootnodeNodeJSON struct {
+	Seq    uint64      `json:"seq"`
+	Record *enode.Node `json:"record"`
+}
+
+// writeBootnodeNodes atomically replaces the node set file with the live
+// nodes of the discovery tables.
+func writeBootnodeNodes(server *p2p.Server, file string) error {
+	nodes := make(map[enode.ID]bootnodeNodeJSON)
+	if disc := server.DiscoveryV4(); disc != nil {
+		for _, bucket := range disc.TableBuckets() {
+			for _, n := range bucket {
+				if n.Live {
+					nodes[n.Node.ID()] = bootnodeNodeJSON{Seq: n.Node.Seq(), Record: n.Node}
+				}
+			}
+		}
+	}
+	if disc := server.DiscoveryV5(); disc != nil {
+		for _, n := range disc.AllNodes() {
+			nodes[n.ID()] = bootnodeNodeJSON{Seq: n.Seq(), Record: n}
+		}
+	}
+	blob, err := json.MarshalIndent(nodes, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(filepath.Dir(file), "."+filepath.Base(file)+".tmp")
+	if err := os.WriteFile(tmp, blob, 0644); err != nil {
+		return err
+	}
+	log.Debug("Wrote node set", "file", file, "nodes", len(nodes))
+	return os.Rename(tmp, file)
+}
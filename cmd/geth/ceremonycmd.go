@@ -0,0 +1,463 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	cliqueExtraVanity = 32                     // Extra-data prefix bytes reserved for signer vanity
+	cliqueExtraSeal   = crypto.SignatureLength // Extra-data suffix bytes reserved for the signer seal
+
+	ceremonyGenesisFile       = "genesis.json"
+	ceremonyContributionFiles = ".contribution.json"
+	ceremonyAttestationFiles  = ".attestation.json"
+)
+
+var operatorNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+var (
+	ceremonyOperatorFlag = &cli.StringFlag{
+		Name:  "operator",
+		Usage: "Name identifying the operator in the ceremony directory",
+	}
+	ceremonyKeyFileFlag = &cli.PathFlag{
+		Name:      "keyfile",
+		Usage:     "Keystore file of the operator's signer account",
+		TakesFile: true,
+	}
+
+	ceremonyCommand = &cli.Command{
+		Name:  "ceremony",
+		Usage: "Set up the genesis of a multi-operator clique network",
+		Description: `
+The ceremony commands let several operators agree on the genesis of a clique
+network by exchanging files in a shared directory (e.g. a git repository):
+
+ 1. every operator contributes their signer:
+        geth ceremony contribute --operator alice --keyfile <key> <dir>
+ 2. one of them assembles the genesis from a template with the clique config:
+        geth ceremony assemble <template.json> <dir>
+ 3. every operator independently recomputes and attests the genesis hash:
+        geth ceremony attest --operator alice --keyfile <key> <template.json> <dir>
+ 4. anyone checks that all parties agreed on the same genesis:
+        geth ceremony verify <dir>
+
+Contributions and attestations are signed with the signer key, proving every
+operator controls the address they submitted and attested the genesis on their
+own.`,
+		Subcommands: []*cli.Command{
+			{
+				Action:    ceremonyContribute,
+				Name:      "contribute",
+				Usage:     "Contribute the signer of an operator",
+				ArgsUsage: "<dir>",
+				Flags: []cli.Flag{
+					ceremonyOperatorFlag,
+					ceremonyKeyFileFlag,
+					utils.PasswordFileFlag,
+				},
+			},
+			{
+				Action:    ceremonyAssemble,
+				Name:      "assemble",
+				Usage:     "Assemble the genesis from the contributions",
+				ArgsUsage: "<template.json> <dir>",
+				Description: `
+The template is a genesis file with a clique chain configuration. Its extra-data
+is replaced with the agreed one: the template's vanity, followed by the signers
+of all contributions in ascending order.`,
+			},
+			{
+				Action:    ceremonyAttest,
+				Name:      "attest",
+				Usage:     "Attest the hash of the assembled genesis",
+				ArgsUsage: "<template.json> <dir>",
+				Flags: []cli.Flag{
+					ceremonyOperatorFlag,
+					ceremonyKeyFileFlag,
+					utils.PasswordFileFlag,
+				},
+				Description: `
+This command assembles the genesis from the template and the contributions on its
+own, checks it matches the shared genesis file and records its hash, signed with
+the operator's signer key.`,
+			},
+			{
+				Action:    ceremonyVerify,
+				Name:      "verify",
+				Usage:     "Verify all operators attested the same genesis",
+				ArgsUsage: "<dir>",
+			},
+		},
+	}
+)
+
+// ceremonyContribution is the signer an operator contributes to the genesis.
+type ceremonyContribution struct {
+	Operator  string         `json:"operator"`
+	Signer    common.Address `json:"signer"`
+	Signature hexutil.Bytes  `json:"signature"` // Signature of the contribution by the signer
+}
+
+// ceremonyAttestation is the genesis hash an operator computed.
+type ceremonyAttestation struct {
+	Operator  string        `json:"operator"`
+	Genesis   common.Hash   `json:"genesis"`
+	Signature hexutil.Bytes `json:"signature"` // Signature of the attestation by the operator's signer
+}
+
+// message returns the message signed by the signer of a contribution.
+func (c *ceremonyContribution) message() []byte {
+	return []byte(fmt.Sprintf("genesis ceremony: operator %s contributes signer %s", c.Operator, c.Signer.Hex()))
+}
+
+// verify checks the contribution was signed by its signer.
+func (c *ceremonyContribution) verify() error {
+	signer, err := ceremonySigner(c.message(), c.Signature)
+	if err != nil {
+		return fmt.Errorf("operator %s: %v", c.Operator, err)
+	}
+	if signer != c.Signer {
+		return fmt.Errorf("operator %s: contribution of %s signed by %s", c.Operator, c.Signer.Hex(), signer.Hex())
+	}
+	return nil
+}
+
+// message returns the message signed by the signer of an attestation.
+func (a *ceremonyAttestation) message() []byte {
+	return []byte(fmt.Sprintf("genesis ceremony: operator %s attests genesis %s", a.Operator, a.Genesis.Hex()))
+}
+
+// ceremonySigner recovers the address which signed a ceremony message.
+func ceremonySigner(message []byte, signature []byte) (common.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("invalid signature length %d", len(signature))
+	}
+	sig := common.CopyBytes(signature)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pub, err := crypto.SigToPub(accounts.TextHash(message), sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid signature: %v", err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+func ceremonyContribute(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("required argument: %v", ctx.Command.ArgsUsage)
+	}
+	operator, err := ceremonyOperator(ctx)
+	if err != nil {
+		return err
+	}
+	key, err := ceremonyKey(ctx)
+	if err != nil {
+		return err
+	}
+	contrib := &ceremonyContribution{Operator: operator, Signer: key.Address}
+	if contrib.Signature, err = crypto.Sign(accounts.TextHash(contrib.message()), key.PrivateKey); err != nil {
+		return err
+	}
+	path := filepath.Join(ctx.Args().First(), operator+ceremonyContributionFiles)
+	if err := writeCeremonyFile(path, contrib); err != nil {
+		return err
+	}
+	fmt.Printf("Contributed signer %s of operator %s to %s\n", contrib.Signer.Hex(), operator, path)
+	return nil
+}
+
+func ceremonyAssemble(ctx *cli.Context) error {
+	if ctx.NArg() != 2 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	genesis, contribs, err := assembleFromDir(ctx.Args().Get(0), ctx.Args().Get(1))
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(ctx.Args().Get(1), ceremonyGenesisFile)
+	if err := writeCeremonyFile(path, genesis); err != nil {
+		return err
+	}
+	fmt.Printf("Assembled genesis of %d signers to %s\n", len(contribs), path)
+	for _, contrib := range contribs {
+		fmt.Printf("  %s  %s\n", contrib.Signer.Hex(), contrib.Operator)
+	}
+	fmt.Printf("Genesis hash: %s\n", core.GenesisToBlock(genesis, nil).Hash().Hex())
+	return nil
+}
+
+func ceremonyAttest(ctx *cli.Context) error {
+	if ctx.NArg() != 2 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	operator, err := ceremonyOperator(ctx)
+	if err != nil {
+		return err
+	}
+	key, err := ceremonyKey(ctx)
+	if err != nil {
+		return err
+	}
+	dir := ctx.Args().Get(1)
+	genesis, contribs, err := assembleFromDir(ctx.Args().Get(0), dir)
+	if err != nil {
+		return err
+	}
+	// Only the signer contributed by the operator may attest on their behalf
+	var contributed bool
+	for _, contrib := range contribs {
+		if contrib.Operator == operator {
+			if contrib.Signer != key.Address {
+				return fmt.Errorf("operator %s contributed signer %s, not %s", operator, contrib.Signer.Hex(), key.Address.Hex())
+			}
+			contributed = true
+		}
+	}
+	if !contributed {
+		return fmt.Errorf("operator %s has no contribution in the ceremony directory", operator)
+	}
+	hash := core.GenesisToBlock(genesis, nil).Hash()
+	shared, err := utils.ReadGenesis(filepath.Join(dir, ceremonyGenesisFile), "")
+	if err != nil {
+		return fmt.Errorf("failed to read the assembled genesis: %v", err)
+	}
+	if have := core.GenesisToBlock(shared, nil).Hash(); have != hash {
+		return fmt.Errorf("assembled genesis %s does not match the one computed locally %s", have.Hex(), hash.Hex())
+	}
+	attest := &ceremonyAttestation{Operator: operator, Genesis: hash}
+	if attest.Signature, err = crypto.Sign(accounts.TextHash(attest.message()), key.PrivateKey); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, operator+ceremonyAttestationFiles)
+	if err := writeCeremonyFile(path, attest); err != nil {
+		return err
+	}
+	fmt.Printf("Attested genesis %s as operator %s in %s\n", hash.Hex(), operator, path)
+	return nil
+}
+
+func ceremonyVerify(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("required argument: %v", ctx.Command.ArgsUsage)
+	}
+	hash, problems, err := verifyCeremony(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("ceremony not complete (%d problems)", len(problems))
+	}
+	fmt.Printf("All operators attested genesis %s\n", hash.Hex())
+	return nil
+}
+
+// ceremonyOperator returns the validated operator name given on the command line.
+func ceremonyOperator(ctx *cli.Context) (string, error) {
+	operator := ctx.String(ceremonyOperatorFlag.Name)
+	if !operatorNameRegexp.MatchString(operator) {
+		return "", fmt.Errorf("invalid operator name %q, --%s must be letters, digits, '-' or '_'", operator, ceremonyOperatorFlag.Name)
+	}
+	return operator, nil
+}
+
+// ceremonyKey decrypts the operator's signer key given on the command line.
+func ceremonyKey(ctx *cli.Context) (*keystore.Key, error) {
+	keyfile := ctx.Path(ceremonyKeyFileFlag.Name)
+	if keyfile == "" {
+		return nil, fmt.Errorf("the signer keystore file must be given with --%s", ceremonyKeyFileFlag.Name)
+	}
+	keyjson, err := os.ReadFile(keyfile)
+	if err != nil {
+		return nil, err
+	}
+	password := utils.GetPassPhraseWithList("Unlocking the signer key", false, 0, utils.MakePasswordList(ctx))
+	key, err := keystore.DecryptKey(keyjson, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt the signer key: %v", err)
+	}
+	return key, nil
+}
+
+// assembleFromDir assembles the genesis from the template and the contributions
+// found in the ceremony directory.
+func assembleFromDir(template string, dir string) (*genesisT.Genesis, []*ceremonyContribution, error) {
+	genesis, err := utils.ReadGenesis(template, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read genesis template: %v", err)
+	}
+	contribs, err := readContributions(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := assembleGenesis(genesis, contribs); err != nil {
+		return nil, nil, err
+	}
+	return genesis, contribs, nil
+}
+
+// assembleGenesis sets the extra-data of the clique genesis to the vanity of the
+// template followed by the signers of the contributions, which are sorted as
+// clique requires.
+func assembleGenesis(genesis *genesisT.Genesis, contribs []*ceremonyContribution) error {
+	if genesis.Config == nil || !genesis.Config.GetConsensusEngineType().IsClique() {
+		return errors.New("genesis template must have a clique chain configuration")
+	}
+	if len(contribs) == 0 {
+		return errors.New("no contributions in ceremony directory")
+	}
+	sort.Slice(contribs, func(i, j int) bool {
+		return bytes.Compare(contribs[i].Signer[:], contribs[j].Signer[:]) < 0
+	})
+	extra := make([]byte, cliqueExtraVanity, cliqueExtraVanity+len(contribs)*common.AddressLength+cliqueExtraSeal)
+	copy(extra, genesis.ExtraData)
+	for _, contrib := range contribs {
+		extra = append(extra, contrib.Signer[:]...)
+	}
+	genesis.ExtraData = append(extra, make([]byte, cliqueExtraSeal)...)
+	return nil
+}
+
+// readContributions reads and verifies all contributions in the ceremony
+// directory, rejecting duplicate operators and signers.
+func readContributions(dir string) ([]*ceremonyContribution, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+ceremonyContributionFiles))
+	if err != nil {
+		return nil, err
+	}
+	var (
+		contribs []*ceremonyContribution
+		signers  = make(map[common.Address]string)
+	)
+	for _, file := range files {
+		contrib := new(ceremonyContribution)
+		if err := readCeremonyFile(file, contrib); err != nil {
+			return nil, err
+		}
+		if name := strings.TrimSuffix(filepath.Base(file), ceremonyContributionFiles); contrib.Operator != name {
+			return nil, fmt.Errorf("%s: contribution of operator %q", file, contrib.Operator)
+		}
+		if err := contrib.verify(); err != nil {
+			return nil, err
+		}
+		if other, ok := signers[contrib.Signer]; ok {
+			return nil, fmt.Errorf("signer %s contributed by both %s and %s", contrib.Signer.Hex(), other, contrib.Operator)
+		}
+		signers[contrib.Signer] = contrib.Operator
+		contribs = append(contribs, contrib)
+	}
+	return contribs, nil
+}
+
+// verifyCeremony checks that every contributing operator attested the genesis
+// in the ceremony directory with their signer key, returning its hash and the
+// problems found.
+func verifyCeremony(dir string) (common.Hash, []string, error) {
+	genesis, err := utils.ReadGenesis(filepath.Join(dir, ceremonyGenesisFile), "")
+	if err != nil {
+		return common.Hash{}, nil, fmt.Errorf("failed to read the assembled genesis: %v", err)
+	}
+	hash := core.GenesisToBlock(genesis, nil).Hash()
+
+	contribs, err := readContributions(dir)
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	var (
+		problems []string
+		signers  = make(map[common.Address]bool)
+		extra    = genesis.ExtraData
+	)
+	if len(extra) != cliqueExtraVanity+len(contribs)*common.AddressLength+cliqueExtraSeal {
+		problems = append(problems, fmt.Sprintf("genesis extra-data doesn't hold the %d contributed signers", len(contribs)))
+	} else {
+		for i := cliqueExtraVanity; i < len(extra)-cliqueExtraSeal; i += common.AddressLength {
+			signers[common.BytesToAddress(extra[i:i+common.AddressLength])] = true
+		}
+	}
+	for _, contrib := range contribs {
+		if len(signers) > 0 && !signers[contrib.Signer] {
+			problems = append(problems, fmt.Sprintf("operator %s: signer %s missing from genesis", contrib.Operator, contrib.Signer.Hex()))
+		}
+		attest := new(ceremonyAttestation)
+		if err := readCeremonyFile(filepath.Join(dir, contrib.Operator+ceremonyAttestationFiles), attest); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				problems = append(problems, fmt.Sprintf("operator %s: no attestation", contrib.Operator))
+				continue
+			}
+			return common.Hash{}, nil, err
+		}
+		if attest.Operator != contrib.Operator {
+			problems = append(problems, fmt.Sprintf("operator %s: attestation of operator %q", contrib.Operator, attest.Operator))
+			continue
+		}
+		if signer, err := ceremonySigner(attest.message(), attest.Signature); err != nil {
+			problems = append(problems, fmt.Sprintf("operator %s: attestation %v", contrib.Operator, err))
+			continue
+		} else if signer != contrib.Signer {
+			problems = append(problems, fmt.Sprintf("operator %s: attestation signed by %s instead of signer %s", contrib.Operator, signer.Hex(), contrib.Signer.Hex()))
+			continue
+		}
+		if attest.Genesis != hash {
+			problems = append(problems, fmt.Sprintf("operator %s: attested genesis %s, expected %s", contrib.Operator, attest.Genesis.Hex(), hash.Hex()))
+		}
+	}
+	return hash, problems, nil
+}
+
+func readCeremonyFile(path string, v interface{}) error {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(blob, v); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
+
+func writeCeremonyFile(path string, v interface{}) error {
+	blob, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(blob, '\n'), 0644)
+}
@@ -0,0 +1,117 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+)
+
+func TestCeremony(t *testing.T) {
+	var (
+		dir      = t.TempDir()
+		template = filepath.Join(t.TempDir(), "template.json")
+		signers  []common.Address
+		keys     = make(map[string]*ecdsa.PrivateKey)
+	)
+	err := writeCeremonyFile(template, &genesisT.Genesis{
+		Config:     params.AllCliqueProtocolChanges,
+		ExtraData:  []byte("testnet"),
+		GasLimit:   8_000_000,
+		Difficulty: big.NewInt(1),
+		Alloc:      genesisT.GenesisAlloc{common.HexToAddress("0xa"): {Balance: big.NewInt(1)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, operator := range []string{"alice", "bob", "carol"} {
+		key, _ := crypto.GenerateKey()
+		contrib := &ceremonyContribution{Operator: operator, Signer: crypto.PubkeyToAddress(key.PublicKey)}
+		contrib.Signature, _ = crypto.Sign(accounts.TextHash(contrib.message()), key)
+		keys[operator] = key
+		if err := writeCeremonyFile(filepath.Join(dir, operator+ceremonyContributionFiles), contrib); err != nil {
+			t.Fatal(err)
+		}
+		signers = append(signers, contrib.Signer)
+	}
+	genesis, contribs, err := assembleFromDir(template, dir)
+	if err != nil {
+		t.Fatalf("failed to assemble genesis: %v", err)
+	}
+	// The signers must be sorted between the vanity and the seal
+	extra := genesis.ExtraData
+	if len(extra) != cliqueExtraVanity+3*common.AddressLength+cliqueExtraSeal || !bytes.HasPrefix(extra, []byte("testnet")) {
+		t.Fatalf("wrong extra-data %x", extra)
+	}
+	for i := 1; i < len(contribs); i++ {
+		if bytes.Compare(contribs[i-1].Signer[:], contribs[i].Signer[:]) >= 0 {
+			t.Fatalf("signers not sorted")
+		}
+	}
+	if err := writeCeremonyFile(filepath.Join(dir, ceremonyGenesisFile), genesis); err != nil {
+		t.Fatal(err)
+	}
+	hash := core.GenesisToBlock(genesis, nil).Hash()
+
+	// Missing and wrong attestations must be reported
+	attest := func(operator string, hash common.Hash, key *ecdsa.PrivateKey) {
+		attest := &ceremonyAttestation{Operator: operator, Genesis: hash}
+		attest.Signature, _ = crypto.Sign(accounts.TextHash(attest.message()), key)
+		if err := writeCeremonyFile(filepath.Join(dir, operator+ceremonyAttestationFiles), attest); err != nil {
+			t.Fatal(err)
+		}
+	}
+	attest("alice", hash, keys["alice"])
+	attest("bob", common.Hash{1}, keys["bob"])
+	if _, problems, err := verifyCeremony(dir); err != nil || len(problems) != 2 {
+		t.Fatalf("wrong verification of incomplete ceremony: problems %q, err %v", problems, err)
+	}
+	// Attestations signed by anyone but the operator's signer must be reported
+	attest("bob", hash, keys["alice"])
+	attest("carol", hash, keys["carol"])
+	if _, problems, err := verifyCeremony(dir); err != nil || len(problems) != 1 {
+		t.Fatalf("wrong verification of forged attestation: problems %q, err %v", problems, err)
+	}
+	attest("bob", hash, keys["bob"])
+	have, problems, err := verifyCeremony(dir)
+	if err != nil || len(problems) != 0 {
+		t.Fatalf("verification of complete ceremony failed: problems %q, err %v", problems, err)
+	}
+	if have != hash {
+		t.Fatalf("wrong genesis hash: have %x, want %x", have, hash)
+	}
+	// Contributions signed by another key must be rejected
+	forged := &ceremonyContribution{Operator: "mallory", Signer: signers[0]}
+	key, _ := crypto.GenerateKey()
+	forged.Signature, _ = crypto.Sign(accounts.TextHash(forged.message()), key)
+	if err := writeCeremonyFile(filepath.Join(dir, "mallory"+ceremonyContributionFiles), forged); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := assembleFromDir(template, dir); err == nil {
+		t.Fatal("forged contribution accepted")
+	}
+}
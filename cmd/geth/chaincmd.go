@@ -17,6 +17,8 @@
 package main
 
 import (
+	"bufio"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -29,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/console/prompt"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
@@ -50,8 +53,9 @@ var (
 		Action:    initGenesis,
 		Name:      "init",
 		Usage:     "Bootstrap and initialize a new genesis block",
-		ArgsUsage: "<genesisPath>",
+		ArgsUsage: "<genesisPath or URL>",
 		Flags: flags.Merge([]cli.Flag{
+			utils.GenesisSHA256Flag,
 			utils.CachePreimagesFlag,
 			utils.OverrideCancun,
 			utils.OverrideVerkle,
@@ -61,7 +65,9 @@ The init command initializes a new genesis block and definition for the network.
 This is a destructive action and changes the network in which you will be
 participating.
 
-It expects the genesis file as argument.`,
+It expects the genesis file as argument, either as a local path or an http(s)
+URL. If --sha256 is given, the genesis file is only accepted if its SHA256
+checksum matches.`,
 	}
 	dumpGenesisCommand = &cli.Command{
 		Action:    dumpGenesis,
@@ -99,6 +105,10 @@ if one is set.  Otherwise it prints the genesis from the datadir.`,
 			utils.MetricsInfluxDBTokenFlag,
 			utils.MetricsInfluxDBBucketFlag,
 			utils.MetricsInfluxDBOrganizationFlag,
+			utils.MetricsRemoteWriteEndpointFlag,
+			utils.MetricsRemoteWriteUsernameFlag,
+			utils.MetricsRemoteWritePasswordFlag,
+			utils.MetricsRemoteWriteLabelsFlag,
 			utils.TxLookupLimitFlag,
 			utils.TransactionHistoryFlag,
 			utils.StateHistoryFlag,
@@ -118,13 +128,33 @@ processing will proceed even if an individual RLP-file import failure occurs.`,
 		Flags: flags.Merge([]cli.Flag{
 			utils.CacheFlag,
 			utils.SyncModeFlag,
+			exportSinceLastFlag,
+			exportCursorFlag,
+			exportConfirmationsFlag,
 		}, utils.DatabaseFlags),
 		Description: `
 Requires a first argument of the file to write to.
 Optional second and third arguments control the first and
 last block to write. In this mode, the file will be appended
 if already existing. If the file ends with .gz, the output will
-be gzipped.`,
+be gzipped.
+
+With --since-last, only the blocks following the last block exported
+with --since-last are written, which is tracked in a cursor file. This
+produces incremental exports for append-only archival. The export fails
+if the last exported block was reorged out of the canonical chain.`,
+	}
+	exportSinceLastFlag = &cli.BoolFlag{
+		Name:  "since-last",
+		Usage: "Export the blocks following the last export done with --since-last",
+	}
+	exportCursorFlag = &cli.StringFlag{
+		Name:  "cursor",
+		Usage: "File tracking the last block exported with --since-last (default = export.cursor in the data directory)",
+	}
+	exportConfirmationsFlag = &cli.Uint64Flag{
+		Name:  "confirmations",
+		Usage: "Number of blocks below the head left out of exports with --since-last",
 	}
 	importHistoryCommand = &cli.Command{
 		Action:    importHistory,
@@ -152,6 +182,83 @@ from Era archives.
 The export-history command will export blocks and their corresponding receipts
 into Era archives. Eras are typically packaged in steps of 8192 blocks.
 `,
+	}
+	exportStatsCSVFlag = &cli.BoolFlag{
+		Name:  "csv",
+		Usage: "Write the statistics as CSV instead of JSON lines",
+	}
+	exportStatsCommand = &cli.Command{
+		Action:    exportStats,
+		Name:      "export-stats",
+		Usage:     "Export per-block chain statistics",
+		ArgsUsage: "<filename> [<blockNumFirst> <blockNumLast>]",
+		Flags: flags.Merge([]cli.Flag{
+			utils.CacheFlag,
+			exportStatsCSVFlag,
+		}, utils.DatabaseFlags),
+		Description: `
+The export-stats command writes the statistics of every canonical block (gas
+used and limit, transaction and uncle counts, difficulty and the time since the
+parent block) to the given file, or to stdout if the filename is "-".
+Optional second and third arguments control the first and last block to write,
+by default all blocks up to the current head are written. The output is one
+JSON object per line, or CSV with a header row if --csv is given.`,
+	}
+	auditReplayCommand = &cli.Command{
+		Action:    auditReplay,
+		Name:      "audit-replay",
+		Usage:     "Report historical transactions without replay protection",
+		ArgsUsage: "<address> [<blockNumFirst> <blockNumLast>]",
+		Flags: flags.Merge([]cli.Flag{
+			utils.CacheFlag,
+		}, utils.DatabaseFlags),
+		Description: `
+The audit-replay command scans the canonical chain for transactions sent by the
+given address without EIP-155 replay protection, writing one JSON object per
+line to stdout. Such transactions are valid on every chain sharing the account's
+history, e.g. both ETH and ETC for accounts active before the DAO fork, and may
+still be replayed on the other chain if the account's nonces match there.
+Optional second and third arguments control the first and last block to scan,
+by default all blocks up to the current head are scanned.`,
+	}
+	rollbackToFlag = &cli.Uint64Flag{
+		Name:     "to",
+		Usage:    "Number of the block to roll the chain back to",
+		Required: true,
+	}
+	rollbackDryRunFlag = &cli.BoolFlag{
+		Name:  "dryrun",
+		Usage: "Only print the preflight report, don't modify the database",
+	}
+	rollbackYesFlag = &cli.BoolFlag{
+		Name:  "yes",
+		Usage: "Don't ask for confirmation after the preflight report",
+	}
+	rollbackCommand = &cli.Command{
+		Action:    rollback,
+		Name:      "rollback",
+		Usage:     "Roll the canonical chain back to an earlier block",
+		ArgsUsage: "",
+		Flags: flags.Merge([]cli.Flag{
+			rollbackToFlag,
+			rollbackDryRunFlag,
+			rollbackYesFlag,
+			utils.CacheFlag,
+			utils.SnapshotFlag,
+		}, utils.DatabaseFlags),
+		Description: `
+    geth rollback --to <blockNum> [--dryrun] [--yes]
+
+The rollback command rewinds the canonical chain of a stopped node to the given
+block, removing the later headers, bodies and receipts (also from the freezer)
+along with their transaction lookup entries, and scheduling the regeneration of
+the state snapshot. Unlike debug_setHead, the transaction index is kept
+consistent with the chain. If the state of the target block is not available,
+the head block is set to the first earlier block with state, and the node
+re-executes the blocks up to the target when syncing.
+
+A preflight report of the changes is printed first, and the rollback is only
+performed after confirmation.`,
 	}
 	importPreimagesCommand = &cli.Command{
 		Action:    importPreimages,
@@ -198,17 +305,10 @@ func initGenesis(ctx *cli.Context) error {
 	if len(genesisPath) == 0 {
 		utils.Fatalf("invalid path to genesis file")
 	}
-
-	genesis := new(genesisT.Genesis)
-	bs, err := os.ReadFile(genesisPath)
+	genesis, err := utils.ReadGenesis(genesisPath, ctx.String(utils.GenesisSHA256Flag.Name))
 	if err != nil {
 		utils.Fatalf("Failed to read genesis file: %v", err)
 	}
-
-	err = genesis.UnmarshalJSON(bs)
-	if err != nil {
-		utils.Fatalf("invalid genesis file: %v", err)
-	}
 	// Open and initialise both full and light databases
 	stack, _ := makeConfigNode(ctx)
 	defer stack.Close()
@@ -229,7 +329,7 @@ func initGenesis(ctx *cli.Context) error {
 		}
 		defer chaindb.Close()
 
-		triedb := utils.MakeTrieDatabase(ctx, chaindb, ctx.Bool(utils.CachePreimagesFlag.Name), false, genesis.IsVerkle())
+		triedb := utils.MakeTrieDatabase(ctx, chaindb, ctx.Bool(utils.CachePreimagesFlag.Name), false, genesis)
 		defer triedb.Close()
 
 		_, hash, err := core.SetupGenesisBlockWithOverride(chaindb, triedb, genesis, &overrides)
@@ -378,7 +478,12 @@ func exportChain(ctx *cli.Context) error {
 
 	var err error
 	fp := ctx.Args().First()
-	if ctx.Args().Len() < 3 {
+	if ctx.Bool(exportSinceLastFlag.Name) {
+		if ctx.Args().Len() != 1 {
+			utils.Fatalf("Export error: --%s doesn't take a block range\n", exportSinceLastFlag.Name)
+		}
+		err = exportSinceLast(ctx, stack, chain, fp)
+	} else if ctx.Args().Len() < 3 {
 		err = utils.ExportChain(chain, fp)
 	} else {
 		// This can be improved to allow for numbers larger than 9223372036854775807
@@ -402,6 +507,76 @@ func exportChain(ctx *cli.Context) error {
 	return nil
 }
 
+// exportCursor is the last block exported with --since-last.
+type exportCursor struct {
+	Number uint64      `json:"number"`
+	Hash   common.Hash `json:"hash"`
+}
+
+// exportSinceLast exports the canonical blocks following the one recorded in the
+// export cursor, and advances the cursor to the last exported block.
+func exportSinceLast(ctx *cli.Context, stack *node.Node, chain *core.BlockChain, fp string) error {
+	path := ctx.String(exportCursorFlag.Name)
+	if path == "" {
+		path = stack.ResolvePath("export.cursor")
+	}
+	cursor, err := readExportCursor(path)
+	if err != nil {
+		return err
+	}
+	var first uint64
+	if cursor != nil {
+		if hash := chain.GetCanonicalHash(cursor.Number); hash != cursor.Hash {
+			return fmt.Errorf("last exported block #%d %x is not canonical anymore (canonical %x)", cursor.Number, cursor.Hash, hash)
+		}
+		first = cursor.Number + 1
+	}
+	head := chain.CurrentSnapBlock().Number.Uint64()
+	confirmations := ctx.Uint64(exportConfirmationsFlag.Name)
+	if head < confirmations || first > head-confirmations {
+		log.Info("No new blocks to export", "next", first, "head", head, "confirmations", confirmations)
+		return nil
+	}
+	last := head - confirmations
+	if err := utils.ExportAppendChain(chain, fp, first, last); err != nil {
+		return err
+	}
+	if err := writeExportCursor(path, &exportCursor{Number: last, Hash: chain.GetCanonicalHash(last)}); err != nil {
+		return fmt.Errorf("failed to update export cursor: %v", err)
+	}
+	log.Info("Exported blocks since last export", "first", first, "last", last, "cursor", path)
+	return nil
+}
+
+// readExportCursor loads the export cursor, returning nil if none exists yet.
+func readExportCursor(path string) (*exportCursor, error) {
+	blob, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cursor := new(exportCursor)
+	if err := json.Unmarshal(blob, cursor); err != nil {
+		return nil, fmt.Errorf("invalid export cursor %s: %v", path, err)
+	}
+	return cursor, nil
+}
+
+// writeExportCursor atomically replaces the export cursor.
+func writeExportCursor(path string, cursor *exportCursor) error {
+	blob, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, blob, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
 func importHistory(ctx *cli.Context) error {
 	if ctx.Args().Len() != 1 {
 		utils.Fatalf("usage: %s", ctx.Command.ArgsUsage)
@@ -493,6 +668,165 @@ func exportHistory(ctx *cli.Context) error {
 	return nil
 }
 
+// exportStats exports the statistics of the canonical blocks in the given
+// range, or of the whole chain, to the specified file.
+func exportStats(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 && ctx.Args().Len() != 3 {
+		utils.Fatalf("usage: %s", ctx.Command.ArgsUsage)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack, true)
+	defer db.Close()
+
+	first, last := blockRangeArgs(ctx, chain)
+	out := os.Stdout
+	if fn := ctx.Args().First(); fn != "-" {
+		fh, err := os.Create(fn)
+		if err != nil {
+			utils.Fatalf("Export error: %v\n", err)
+		}
+		defer fh.Close()
+		out = fh
+	}
+	var (
+		w       = bufio.NewWriter(out)
+		start   = time.Now()
+		logged  = time.Now()
+		write   func(*core.BlockStats) error
+		csvOut  *csv.Writer
+		jsonOut = json.NewEncoder(w)
+	)
+	if ctx.Bool(exportStatsCSVFlag.Name) {
+		csvOut = csv.NewWriter(w)
+		if err := csvOut.Write(core.BlockStatsCSVHeader); err != nil {
+			utils.Fatalf("Export error: %v\n", err)
+		}
+		write = func(s *core.BlockStats) error { return csvOut.Write(s.CSVRecord()) }
+	} else {
+		write = func(s *core.BlockStats) error { return jsonOut.Encode(s) }
+	}
+	err := chain.IterateBlockStats(first, last, func(s *core.BlockStats) error {
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Exporting block statistics", "number", s.Number, "last", last, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+		return write(s)
+	})
+	if err == nil && csvOut != nil {
+		csvOut.Flush()
+		err = csvOut.Error()
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		utils.Fatalf("Export error: %v\n", err)
+	}
+	log.Info("Exported block statistics", "first", first, "last", last, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func rollback(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack, false)
+	defer db.Close()
+	defer chain.Stop()
+
+	target := ctx.Uint64(rollbackToFlag.Name)
+	report, err := chain.PlanRollback(target)
+	if err != nil {
+		utils.Fatalf("Rollback error: %v", err)
+	}
+	printRollbackReport(report)
+	if ctx.Bool(rollbackDryRunFlag.Name) {
+		return nil
+	}
+	if !ctx.Bool(rollbackYesFlag.Name) {
+		confirm, err := prompt.Stdin.PromptConfirm(fmt.Sprintf("Roll the chain back to block #%d?", target))
+		if err != nil {
+			utils.Fatalf("%v", err)
+		}
+		if !confirm {
+			log.Info("Rollback skipped")
+			return nil
+		}
+	}
+	start := time.Now()
+	if report, err = chain.Rollback(target); err != nil {
+		utils.Fatalf("Rollback error: %v", err)
+	}
+	fmt.Printf("Rolled back to block #%d in %v\n", report.NewBlock, common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// printRollbackReport prints the effects of a rollback for confirmation.
+func printRollbackReport(report *core.RollbackReport) {
+	fmt.Printf("Head header:           #%d -> #%d\n", report.OldHeader, report.Target)
+	fmt.Printf("Head block:            #%d -> #%d\n", report.OldBlock, report.NewBlock)
+	if report.NewBlock < report.Target {
+		fmt.Printf("                       (state of #%d missing, blocks #%d-#%d will be re-executed)\n", report.Target, report.NewBlock+1, report.Target)
+	}
+	fmt.Printf("Headers removed:       %d\n", report.Headers)
+	fmt.Printf("Frozen blocks removed: %d\n", report.Frozen)
+	fmt.Printf("Tx lookups removed:    %d\n", report.Transactions)
+	if report.TxIndexTail != nil {
+		fmt.Printf("Tx index tail:         #%d\n", *report.TxIndexTail)
+	} else {
+		fmt.Printf("Tx index tail:         none\n")
+	}
+	fmt.Printf("Snapshot regenerated:  %t\n", report.Snapshot)
+}
+
+func auditReplay(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 && ctx.Args().Len() != 3 {
+		utils.Fatalf("usage: %s", ctx.Command.ArgsUsage)
+	}
+	if !common.IsHexAddress(ctx.Args().First()) {
+		utils.Fatalf("Invalid address: %s", ctx.Args().First())
+	}
+	address := common.HexToAddress(ctx.Args().First())
+
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack, true)
+	defer db.Close()
+
+	first, last := blockRangeArgs(ctx, chain)
+	var (
+		out   = json.NewEncoder(os.Stdout)
+		start = time.Now()
+		found int
+	)
+	err := chain.IterateUnprotectedTxs(first, last, &address, func(tx *core.UnprotectedTx) error {
+		found++
+		return out.Encode(tx)
+	})
+	if err != nil {
+		utils.Fatalf("Audit error: %v\n", err)
+	}
+	log.Info("Audited replay protection", "address", address, "first", first, "last", last, "unprotected", found, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// blockRangeArgs returns the block range given as the optional second and third
+// arguments of a chain scanning command, defaulting to the whole chain.
+func blockRangeArgs(ctx *cli.Context, chain *core.BlockChain) (uint64, uint64) {
+	if ctx.Args().Len() != 3 {
+		return 0, chain.CurrentBlock().Number.Uint64()
+	}
+	first, ferr := strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+	last, lerr := strconv.ParseUint(ctx.Args().Get(2), 10, 64)
+	if ferr != nil || lerr != nil {
+		utils.Fatalf("Error in parsing parameters: block number not an integer\n")
+	}
+	return first, last
+}
+
 // importPreimages imports preimage data from the specified file.
 // it is deprecated, and the export function has been removed, but
 // the import function is kept around for the time being so that
@@ -584,7 +918,7 @@ func dump(ctx *cli.Context) error {
 	if err != nil {
 		return err
 	}
-	triedb := utils.MakeTrieDatabase(ctx, db, true, true, false) // always enable preimage lookup
+	triedb := utils.MakeTrieDatabase(ctx, db, true, true, nil) // always enable preimage lookup
 	defer triedb.Close()
 
 	state, err := state.New(root, state.NewDatabaseWithNodeDB(db, triedb), nil)
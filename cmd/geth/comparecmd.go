@@ -0,0 +1,506 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	compareOtherFlag = &cli.StringFlag{
+		Name:  "other",
+		Usage: "RPC endpoint of the node to compare the state with",
+	}
+	compareBlockFlag = &cli.Uint64Flag{
+		Name:  "block",
+		Usage: "Number of the block whose state is compared (default = head)",
+	}
+	compareAgainstFlag = &cli.Uint64Flag{
+		Name:  "against",
+		Usage: "Number of the local block whose state is compared with, instead of another node",
+	}
+	compareLimitFlag = &cli.IntFlag{
+		Name:  "limit",
+		Usage: "Maximum number of differences to report (0 = no limit)",
+		Value: 100,
+	}
+	compareNoStorageFlag = &cli.BoolFlag{
+		Name:  "nostorage",
+		Usage: "Don't compare the storage slots of accounts with differing storage roots",
+	}
+	compareStateCommand = &cli.Command{
+		Action:    compareState,
+		Name:      "compare-state",
+		Usage:     "Compare the state with another node or another block",
+		ArgsUsage: "",
+		Flags: flags.Merge([]cli.Flag{
+			compareOtherFlag,
+			compareBlockFlag,
+			compareAgainstFlag,
+			compareLimitFlag,
+			compareNoStorageFlag,
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `
+    geth compare-state --other http://node2:8545 --block 19000000
+    geth compare-state --block 19000000 --against 19000001
+
+Walks the state at the given block and the state of the other node at the same
+block number, or the state of another local block, and reports the accounts and
+storage slots that differ. This is meant for diagnosing consensus splits between
+nodes or clients. The other node must serve debug_accountRange, and, to compare
+storage slots, debug_storageRangeAt. Storage slots of the other node's head
+block can't be compared, as they are read from the state before the next block.`,
+	}
+)
+
+// errDiffLimit is returned when the maximum number of differences was reported.
+var errDiffLimit = errors.New("difference limit reached")
+
+// compareAccount is an account of a compared state.
+type compareAccount struct {
+	hash     common.Hash
+	address  *common.Address // nil if the preimage is unknown
+	nonce    uint64
+	balance  *big.Int
+	codeHash common.Hash
+	root     common.Hash
+}
+
+// compareSlot is a storage slot of a compared state.
+type compareSlot struct {
+	hash  common.Hash
+	value common.Hash
+}
+
+// stateSource provides the accounts and storage slots of a state, in the order
+// of their hashes.
+type stateSource interface {
+	name() string
+	accounts() (func() (*compareAccount, error), error)
+	storage(account *compareAccount) (func() (*compareSlot, error), error)
+}
+
+// localStateSource is a stateSource reading a state from the local database.
+type localStateSource struct {
+	label  string
+	root   common.Hash
+	triedb *triedb.Database
+}
+
+func (s *localStateSource) name() string { return s.label }
+
+func (s *localStateSource) accounts() (func() (*compareAccount, error), error) {
+	tr, err := trie.NewStateTrie(trie.StateTrieID(s.root), s.triedb)
+	if err != nil {
+		return nil, err
+	}
+	nodeIt, err := tr.NodeIterator(nil)
+	if err != nil {
+		return nil, err
+	}
+	it := trie.NewIterator(nodeIt)
+	return func() (*compareAccount, error) {
+		if !it.Next() {
+			return nil, it.Err
+		}
+		var data types.StateAccount
+		if err := rlp.DecodeBytes(it.Value, &data); err != nil {
+			return nil, err
+		}
+		account := &compareAccount{
+			hash:     common.BytesToHash(it.Key),
+			nonce:    data.Nonce,
+			balance:  data.Balance.ToBig(),
+			codeHash: common.BytesToHash(data.CodeHash),
+			root:     data.Root,
+		}
+		if preimage := tr.GetKey(it.Key); preimage != nil {
+			addr := common.BytesToAddress(preimage)
+			account.address = &addr
+		}
+		return account, nil
+	}, nil
+}
+
+func (s *localStateSource) storage(account *compareAccount) (func() (*compareSlot, error), error) {
+	tr, err := trie.NewStateTrie(trie.StorageTrieID(s.root, account.hash, account.root), s.triedb)
+	if err != nil {
+		return nil, err
+	}
+	nodeIt, err := tr.NodeIterator(nil)
+	if err != nil {
+		return nil, err
+	}
+	it := trie.NewIterator(nodeIt)
+	return func() (*compareSlot, error) {
+		if !it.Next() {
+			return nil, it.Err
+		}
+		_, content, _, err := rlp.Split(it.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &compareSlot{hash: common.BytesToHash(it.Key), value: common.BytesToHash(content)}, nil
+	}, nil
+}
+
+// remoteStateSource is a stateSource reading a state from another node via the
+// debug_accountRange and debug_storageRangeAt RPC methods.
+type remoteStateSource struct {
+	client       *rpc.Client
+	block        common.Hash  // Block whose state is compared
+	storageBlock *common.Hash // Child block, whose pre-state is read for storage slots
+}
+
+func (s *remoteStateSource) name() string { return "other" }
+
+func (s *remoteStateSource) accounts() (func() (*compareAccount, error), error) {
+	var (
+		page  []*compareAccount
+		start = hexutil.Bytes{}
+		done  bool
+	)
+	return func() (*compareAccount, error) {
+		for len(page) == 0 {
+			if done {
+				return nil, nil
+			}
+			var dump state.Dump
+			err := s.client.Call(&dump, "debug_accountRange", rpc.BlockNumberOrHashWithHash(s.block, false), start, 256, true, true, true)
+			if err != nil {
+				return nil, err
+			}
+			for _, acc := range dump.Accounts {
+				balance, ok := new(big.Int).SetString(acc.Balance, 10)
+				if !ok {
+					return nil, fmt.Errorf("invalid balance %q of account %x", acc.Balance, acc.AddressHash)
+				}
+				page = append(page, &compareAccount{
+					hash:     common.BytesToHash(acc.AddressHash),
+					address:  acc.Address,
+					nonce:    acc.Nonce,
+					balance:  balance,
+					codeHash: common.BytesToHash(acc.CodeHash),
+					root:     common.BytesToHash(acc.Root),
+				})
+			}
+			sort.Slice(page, func(i, j int) bool { return page[i].hash.Cmp(page[j].hash) < 0 })
+			start, done = dump.Next, len(dump.Next) == 0
+		}
+		account := page[0]
+		page = page[1:]
+		return account, nil
+	}, nil
+}
+
+func (s *remoteStateSource) storage(account *compareAccount) (func() (*compareSlot, error), error) {
+	if s.storageBlock == nil {
+		return nil, errors.New("storage of the other node's head state is not available")
+	}
+	if account.address == nil {
+		return nil, errors.New("address unknown")
+	}
+	var (
+		page  []*compareSlot
+		start = hexutil.Bytes{}
+		done  bool
+	)
+	return func() (*compareSlot, error) {
+		for len(page) == 0 {
+			if done {
+				return nil, nil
+			}
+			var result struct {
+				Storage map[common.Hash]struct {
+					Value common.Hash `json:"value"`
+				} `json:"storage"`
+				NextKey *common.Hash `json:"nextKey"`
+			}
+			err := s.client.Call(&result, "debug_storageRangeAt", rpc.BlockNumberOrHashWithHash(*s.storageBlock, false), 0, *account.address, start, 1024)
+			if err != nil {
+				return nil, err
+			}
+			for hash, entry := range result.Storage {
+				page = append(page, &compareSlot{hash: hash, value: entry.Value})
+			}
+			sort.Slice(page, func(i, j int) bool { return page[i].hash.Cmp(page[j].hash) < 0 })
+			if result.NextKey == nil {
+				done = true
+			} else {
+				start = result.NextKey.Bytes()
+			}
+		}
+		slot := page[0]
+		page = page[1:]
+		return slot, nil
+	}, nil
+}
+
+// accountLabel returns the hash of an account, along with its address if known.
+func accountLabel(a, b *compareAccount) string {
+	for _, acc := range []*compareAccount{a, b} {
+		if acc != nil && acc.address != nil {
+			return fmt.Sprintf("%x (%v)", acc.hash, *acc.address)
+		}
+	}
+	if a != nil {
+		return fmt.Sprintf("%x", a.hash)
+	}
+	return fmt.Sprintf("%x", b.hash)
+}
+
+// compareStates walks two states in parallel and reports all accounts, and if
+// requested storage slots, that differ. Reporting stops with errDiffLimit once
+// report returns it.
+func compareStates(a, b stateSource, storage bool, report func(string) error) error {
+	nextA, err := a.accounts()
+	if err != nil {
+		return fmt.Errorf("%s: %v", a.name(), err)
+	}
+	nextB, err := b.accounts()
+	if err != nil {
+		return fmt.Errorf("%s: %v", b.name(), err)
+	}
+	accA, errA := nextA()
+	accB, errB := nextB()
+	for {
+		if errA != nil {
+			return fmt.Errorf("%s: %v", a.name(), errA)
+		}
+		if errB != nil {
+			return fmt.Errorf("%s: %v", b.name(), errB)
+		}
+		switch {
+		case accA == nil && accB == nil:
+			return nil
+
+		case accB == nil || (accA != nil && accA.hash.Cmp(accB.hash) < 0):
+			if err := report(fmt.Sprintf("account %s: only in %s", accountLabel(accA, nil), a.name())); err != nil {
+				return err
+			}
+			accA, errA = nextA()
+
+		case accA == nil || accB.hash.Cmp(accA.hash) < 0:
+			if err := report(fmt.Sprintf("account %s: only in %s", accountLabel(nil, accB), b.name())); err != nil {
+				return err
+			}
+			accB, errB = nextB()
+
+		default:
+			if err := compareAccounts(a, b, accA, accB, storage, report); err != nil {
+				return err
+			}
+			accA, errA = nextA()
+			accB, errB = nextB()
+		}
+	}
+}
+
+// compareAccounts reports the differences between two versions of an account.
+func compareAccounts(a, b stateSource, accA, accB *compareAccount, storage bool, report func(string) error) error {
+	label := accountLabel(accA, accB)
+	field := func(name string, valA, valB interface{}) error {
+		return report(fmt.Sprintf("account %s: %s %v (%s) != %v (%s)", label, name, valA, a.name(), valB, b.name()))
+	}
+	if accA.nonce != accB.nonce {
+		if err := field("nonce", accA.nonce, accB.nonce); err != nil {
+			return err
+		}
+	}
+	if accA.balance.Cmp(accB.balance) != 0 {
+		if err := field("balance", accA.balance, accB.balance); err != nil {
+			return err
+		}
+	}
+	if accA.codeHash != accB.codeHash {
+		if err := field("code hash", accA.codeHash, accB.codeHash); err != nil {
+			return err
+		}
+	}
+	if accA.root == accB.root {
+		return nil
+	}
+	if err := field("storage root", accA.root, accB.root); err != nil {
+		return err
+	}
+	if !storage {
+		return nil
+	}
+	// Both versions must know the address for remote storage lookups
+	if accA.address == nil {
+		accA.address = accB.address
+	}
+	if accB.address == nil {
+		accB.address = accA.address
+	}
+	return compareStorage(a, b, accA, accB, label, report)
+}
+
+// compareStorage reports the storage slots differing between two versions of an
+// account.
+func compareStorage(a, b stateSource, accA, accB *compareAccount, label string, report func(string) error) error {
+	nextA, err := a.storage(accA)
+	if err != nil {
+		return report(fmt.Sprintf("account %s: storage of %s not available: %v", label, a.name(), err))
+	}
+	nextB, err := b.storage(accB)
+	if err != nil {
+		return report(fmt.Sprintf("account %s: storage of %s not available: %v", label, b.name(), err))
+	}
+	slotA, errA := nextA()
+	slotB, errB := nextB()
+	for {
+		if errA != nil {
+			return fmt.Errorf("%s: account %s: %v", a.name(), label, errA)
+		}
+		if errB != nil {
+			return fmt.Errorf("%s: account %s: %v", b.name(), label, errB)
+		}
+		switch {
+		case slotA == nil && slotB == nil:
+			return nil
+
+		case slotB == nil || (slotA != nil && slotA.hash.Cmp(slotB.hash) < 0):
+			if err := report(fmt.Sprintf("slot %s %x: %x (%s) != unset (%s)", label, slotA.hash, slotA.value, a.name(), b.name())); err != nil {
+				return err
+			}
+			slotA, errA = nextA()
+
+		case slotA == nil || slotB.hash.Cmp(slotA.hash) < 0:
+			if err := report(fmt.Sprintf("slot %s %x: unset (%s) != %x (%s)", label, slotB.hash, a.name(), slotB.value, b.name())); err != nil {
+				return err
+			}
+			slotB, errB = nextB()
+
+		default:
+			if slotA.value != slotB.value {
+				if err := report(fmt.Sprintf("slot %s %x: %x (%s) != %x (%s)", label, slotA.hash, slotA.value, a.name(), slotB.value, b.name())); err != nil {
+					return err
+				}
+			}
+			slotA, errA = nextA()
+			slotB, errB = nextB()
+		}
+	}
+}
+
+func compareState(ctx *cli.Context) error {
+	if ctx.IsSet(compareOtherFlag.Name) == ctx.IsSet(compareAgainstFlag.Name) {
+		utils.Fatalf("Exactly one of --%s and --%s must be given", compareOtherFlag.Name, compareAgainstFlag.Name)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chaindb := utils.MakeChainDatabase(ctx, stack, true)
+	defer chaindb.Close()
+
+	triedb := utils.MakeTrieDatabase(ctx, chaindb, true, true, nil)
+	defer triedb.Close()
+
+	readHeader := func(number uint64) *types.Header {
+		hash := rawdb.ReadCanonicalHash(chaindb, number)
+		header := rawdb.ReadHeader(chaindb, hash, number)
+		if header == nil {
+			utils.Fatalf("Block #%d not found", number)
+		}
+		return header
+	}
+	head := rawdb.ReadHeadHeader(chaindb)
+	if head == nil {
+		utils.Fatalf("Failed to load head header")
+	}
+	number := head.Number.Uint64()
+	if ctx.IsSet(compareBlockFlag.Name) {
+		number = ctx.Uint64(compareBlockFlag.Name)
+	}
+	header := readHeader(number)
+	local := &localStateSource{label: fmt.Sprintf("#%d", number), root: header.Root, triedb: triedb}
+
+	var other stateSource
+	if ctx.IsSet(compareAgainstFlag.Name) {
+		against := readHeader(ctx.Uint64(compareAgainstFlag.Name))
+		if against.Root == header.Root {
+			log.Info("States are identical", "root", header.Root)
+			return nil
+		}
+		other = &localStateSource{label: fmt.Sprintf("#%d", against.Number), root: against.Root, triedb: triedb}
+	} else {
+		local.label = "local"
+
+		client, err := rpc.Dial(ctx.String(compareOtherFlag.Name))
+		if err != nil {
+			utils.Fatalf("Failed to connect to other node: %v", err)
+		}
+		defer client.Close()
+
+		remote, err := ethclient.NewClient(client).HeaderByNumber(context.Background(), new(big.Int).SetUint64(number))
+		if err != nil {
+			utils.Fatalf("Failed to retrieve block #%d from other node: %v", number, err)
+		}
+		if remote.Hash() != header.Hash() {
+			log.Warn("Other node has a different block", "number", number, "local", header.Hash(), "other", remote.Hash())
+		}
+		if remote.Root == header.Root {
+			log.Info("States are identical", "number", number, "root", header.Root)
+			return nil
+		}
+		source := &remoteStateSource{client: client, block: remote.Hash()}
+		if child, err := ethclient.NewClient(client).HeaderByNumber(context.Background(), new(big.Int).SetUint64(number+1)); err == nil {
+			hash := child.Hash()
+			source.storageBlock = &hash
+		}
+		other = source
+	}
+	var (
+		start = time.Now()
+		limit = ctx.Int(compareLimitFlag.Name)
+		diffs int
+	)
+	log.Info("Comparing states", "root", header.Root, "with", other.name())
+	err := compareStates(local, other, !ctx.Bool(compareNoStorageFlag.Name), func(diff string) error {
+		fmt.Println(diff)
+		if diffs++; limit > 0 && diffs >= limit {
+			return errDiffLimit
+		}
+		return nil
+	})
+	if err != nil && err != errDiffLimit {
+		utils.Fatalf("Comparison failed: %v", err)
+	}
+	log.Info("Compared states", "differences", diffs, "complete", err == nil, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/holiman/uint256"
+)
+
+func TestCompareStates(t *testing.T) {
+	var (
+		disk = rawdb.NewMemoryDatabase()
+		tdb  = triedb.NewDatabase(disk, &triedb.Config{Preimages: true})
+		db   = state.NewDatabaseWithNodeDB(disk, tdb)
+		a    = common.HexToAddress("0xa")
+		b    = common.HexToAddress("0xb")
+		c    = common.HexToAddress("0xc")
+		d    = common.HexToAddress("0xd")
+	)
+	build := func(fn func(*state.StateDB)) common.Hash {
+		statedb, _ := state.New(types.EmptyRootHash, db, nil)
+		fn(statedb)
+		root, err := statedb.Commit(0, true)
+		if err != nil {
+			t.Fatalf("failed to commit state: %v", err)
+		}
+		return root
+	}
+	rootA := build(func(s *state.StateDB) {
+		s.SetBalance(a, uint256.NewInt(1))
+		s.SetBalance(b, uint256.NewInt(2))
+		s.SetState(b, common.Hash{1}, common.Hash{1})
+		s.SetNonce(c, 1)
+	})
+	rootB := build(func(s *state.StateDB) {
+		s.SetBalance(a, uint256.NewInt(1))
+		s.SetBalance(b, uint256.NewInt(3))
+		s.SetState(b, common.Hash{1}, common.Hash{2})
+		s.SetState(b, common.Hash{2}, common.Hash{1})
+		s.SetNonce(d, 1)
+	})
+	srcA := &localStateSource{label: "A", root: rootA, triedb: tdb}
+	srcB := &localStateSource{label: "B", root: rootB, triedb: tdb}
+
+	var diffs []string
+	err := compareStates(srcA, srcB, true, func(diff string) error {
+		diffs = append(diffs, diff)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("comparison failed: %v", err)
+	}
+	out := strings.Join(diffs, "\n")
+	for _, want := range []string{
+		c.Hex() + "): only in A",
+		d.Hex() + "): only in B",
+		b.Hex() + "): balance 2 (A) != 3 (B)",
+		b.Hex() + "): storage root",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing difference %q in:\n%s", want, out)
+		}
+	}
+	// Two slots of b differ: one changed, one only set in B
+	if have := strings.Count(out, "slot "); have != 2 {
+		t.Errorf("have %d slot differences, want 2:\n%s", have, out)
+	}
+	if len(diffs) != 6 {
+		t.Errorf("have %d differences, want 6:\n%s", len(diffs), out)
+	}
+	// Identical states have no differences
+	err = compareStates(srcA, srcA, true, func(diff string) error {
+		t.Errorf("unexpected difference: %s", diff)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("comparison failed: %v", err)
+	}
+	// Reporting stops at the limit
+	var count int
+	err = compareStates(srcA, srcB, true, func(string) error {
+		if count++; count == 2 {
+			return errDiffLimit
+		}
+		return nil
+	})
+	if err != errDiffLimit || count != 2 {
+		t.Errorf("limit not applied: err %v, %d differences", err, count)
+	}
+}
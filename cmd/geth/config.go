@@ -18,9 +18,11 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
+	"math/big"
 	"os"
 	"reflect"
 	"runtime"
@@ -35,8 +37,11 @@ import (
 	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/eth/catalyst"
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/ethereum/go-ethereum/internal/version"
@@ -44,6 +49,8 @@ import (
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/confp"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
 	"github.com/naoina/toml"
 	"github.com/urfave/cli/v2"
 )
@@ -54,8 +61,13 @@ var (
 		Name:        "dumpconfig",
 		Usage:       "Export configuration values in a TOML format",
 		ArgsUsage:   "<dumpfile (optional)>",
-		Flags:       flags.Merge(nodeFlags, rpcFlags),
-		Description: `Export configuration values in TOML format (to stdout by default).`,
+		Flags:       flags.Merge([]cli.Flag{chainConfigFlag}, nodeFlags, rpcFlags),
+		Description: `Export configuration values in TOML format (to stdout by default). With --chainconfig, the fully-resolved chain configuration is exported as JSON instead.`,
+	}
+
+	chainConfigFlag = &cli.BoolFlag{
+		Name:  "chainconfig",
+		Usage: "Export the fully-resolved chain configuration as JSON",
 	}
 
 	configFileFlag = &cli.StringFlag{
@@ -222,10 +234,18 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 	if ctx.IsSet(utils.GraphQLEnabledFlag.Name) {
 		utils.RegisterGraphQLService(stack, backend, filterSystem, &cfg.Node)
 	}
+	// Configure the meta-transaction relay if requested.
+	if ctx.IsSet(utils.MetaRelayAccountFlag.Name) {
+		utils.RegisterMetaRelayService(ctx, stack, backend)
+	}
 	// Add the Ethereum Stats daemon if requested.
 	if cfg.Ethstats.URL != "" {
 		utils.RegisterEthStatsService(stack, backend, cfg.Ethstats.URL)
 	}
+	// Add the event sinks if requested.
+	if ctx.IsSet(utils.EventSinksFlag.Name) {
+		utils.RegisterEventSinkService(stack, backend, ctx.String(utils.EventSinksFlag.Name))
+	}
 	// Configure full-sync tester service if requested
 	if ctx.IsSet(utils.SyncTargetFlag.Name) {
 		hex := hexutil.MustDecode(ctx.String(utils.SyncTargetFlag.Name))
@@ -254,7 +274,10 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 
 // dumpConfig is the dumpconfig command.
 func dumpConfig(ctx *cli.Context) error {
-	_, cfg := makeConfigNode(ctx)
+	stack, cfg := makeConfigNode(ctx)
+	if ctx.Bool(chainConfigFlag.Name) {
+		return dumpChainConfig(ctx, stack, &cfg.Eth)
+	}
 	comment := ""
 
 	if cfg.Eth.Genesis != nil {
@@ -281,6 +304,71 @@ func dumpConfig(ctx *cli.Context) error {
 	return nil
 }
 
+// chainConfigDump is the effective chain configuration exported by dumpconfig.
+type chainConfigDump struct {
+	NetworkID       uint64                   `json:"networkId"`
+	ChainID         *big.Int                 `json:"chainId"`
+	GenesisHash     common.Hash              `json:"genesisHash"`
+	ConsensusEngine string                   `json:"consensusEngine"`
+	BlockForks      []uint64                 `json:"blockForks"`
+	TimeForks       []uint64                 `json:"timeForks"`
+	Config          ctypes.ChainConfigurator `json:"config"`
+}
+
+// dumpChainConfig resolves the chain configuration the node would run with and
+// exports it as JSON.
+func dumpChainConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) error {
+	// Resolve against the existing database, if any, without modifying it.
+	var db ethdb.Database
+	if _, err := os.Stat(stack.ResolvePath("chaindata")); err == nil {
+		db = utils.MakeChainDatabase(ctx, stack, true)
+	} else {
+		db = rawdb.NewMemoryDatabase()
+	}
+	defer db.Close()
+
+	overrides := &core.ChainOverrides{
+		OverrideCancun: cfg.OverrideCancun,
+		OverrideVerkle: cfg.OverrideVerkle,
+	}
+	config, hash, err := core.ResolveChainConfig(db, cfg.Genesis, overrides)
+	if err != nil {
+		return err
+	}
+	var genesisTime uint64
+	if header := rawdb.ReadHeader(db, hash, 0); header != nil {
+		genesisTime = header.Time
+	} else if cfg.Genesis != nil {
+		genesisTime = cfg.Genesis.Timestamp
+	} else {
+		genesisTime = params.DefaultGenesisBlock().Timestamp
+	}
+	dump := chainConfigDump{
+		NetworkID:       cfg.NetworkId,
+		ChainID:         config.GetChainID(),
+		GenesisHash:     hash,
+		ConsensusEngine: config.GetConsensusEngineType().String(),
+		BlockForks:      confp.BlockForks(config),
+		TimeForks:       confp.TimeForks(config, genesisTime),
+		Config:          config,
+	}
+	// The network ID defaults to the chain ID, see eth.New.
+	if dump.NetworkID == 0 && dump.ChainID != nil {
+		dump.NetworkID = dump.ChainID.Uint64()
+	}
+	out := os.Stdout
+	if ctx.NArg() > 0 {
+		out, err = os.OpenFile(ctx.Args().Get(0), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(dump)
+}
+
 func applyMetricConfig(ctx *cli.Context, cfg *gethConfig) {
 	if ctx.IsSet(utils.MetricsEnabledFlag.Name) {
 		cfg.Metrics.Enabled = ctx.Bool(utils.MetricsEnabledFlag.Name)
@@ -324,6 +412,18 @@ func applyMetricConfig(ctx *cli.Context, cfg *gethConfig) {
 	if ctx.IsSet(utils.MetricsInfluxDBOrganizationFlag.Name) {
 		cfg.Metrics.InfluxDBOrganization = ctx.String(utils.MetricsInfluxDBOrganizationFlag.Name)
 	}
+	if ctx.IsSet(utils.MetricsRemoteWriteEndpointFlag.Name) {
+		cfg.Metrics.RemoteWriteEndpoint = ctx.String(utils.MetricsRemoteWriteEndpointFlag.Name)
+	}
+	if ctx.IsSet(utils.MetricsRemoteWriteUsernameFlag.Name) {
+		cfg.Metrics.RemoteWriteUsername = ctx.String(utils.MetricsRemoteWriteUsernameFlag.Name)
+	}
+	if ctx.IsSet(utils.MetricsRemoteWritePasswordFlag.Name) {
+		cfg.Metrics.RemoteWritePassword = ctx.String(utils.MetricsRemoteWritePasswordFlag.Name)
+	}
+	if ctx.IsSet(utils.MetricsRemoteWriteLabelsFlag.Name) {
+		cfg.Metrics.RemoteWriteLabels = ctx.String(utils.MetricsRemoteWriteLabelsFlag.Name)
+	}
 }
 
 func deprecated(field string) bool {
@@ -18,11 +18,14 @@ package main
 
 import (
 	"fmt"
+	"net/url"
+	"path/filepath"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/ethereum/go-ethereum/console"
 	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/urfave/cli/v2"
 )
 
@@ -45,12 +48,17 @@ See https://geth.ethereum.org/docs/interacting-with-geth/javascript-console.`,
 		Name:      "attach",
 		Usage:     "Start an interactive JavaScript environment (connect to node)",
 		ArgsUsage: "[endpoint]",
-		Flags:     flags.Merge([]cli.Flag{utils.DataDirFlag, utils.HttpHeaderFlag}, consoleFlags),
+		Flags:     flags.Merge([]cli.Flag{utils.DataDirFlag, utils.HttpHeaderFlag, utils.AttachTLSCertFlag, utils.AttachTLSKeyFlag, utils.AttachTLSCAFlag}, consoleFlags),
 		Description: `
 The Geth console is an interactive shell for the JavaScript runtime environment
 which exposes a node admin interface as well as the Ðapp JavaScript API.
 See https://geth.ethereum.org/docs/interacting-with-geth/javascript-console.
-This command allows to open a console on a running geth node.`,
+This command allows to open a console on a running geth node.
+HTTPS and WSS endpoints requiring mutual TLS can be attached to with the
+--attach.tls.cert and --attach.tls.key flags.
+The command history is kept per node: next to the IPC socket of the node, or
+in the data directory in a file named after the remote endpoint. Press ctrl-r
+to search it.`,
 	}
 
 	javascriptCommand = &cli.Command{
@@ -119,12 +127,22 @@ func remoteConsole(ctx *cli.Context) error {
 		utils.SetDataDir(ctx, &cfg)
 		endpoint = cfg.IPCEndpoint()
 	}
-	client, err := utils.DialRPCWithHeaders(endpoint, ctx.StringSlice(utils.HttpHeaderFlag.Name))
+	tlsConfig, err := utils.MakeAttachTLSConfig(ctx)
+	if err != nil {
+		utils.Fatalf("Invalid TLS configuration: %v", err)
+	}
+	var opts []rpc.ClientOption
+	if tlsConfig != nil {
+		opts = append(opts, rpc.WithTLSConfig(tlsConfig))
+	}
+	client, err := utils.DialRPCWithHeaders(endpoint, ctx.StringSlice(utils.HttpHeaderFlag.Name), opts...)
 	if err != nil {
 		utils.Fatalf("Unable to attach to remote geth: %v", err)
 	}
+	datadir, history := consoleHistory(utils.MakeDataDir(ctx), ctx.Args().First())
 	config := console.Config{
-		DataDir: utils.MakeDataDir(ctx),
+		DataDir: datadir,
+		History: history,
 		DocRoot: ctx.String(utils.JSpathFlag.Name),
 		Client:  client,
 		Preload: utils.MakeConsolePreloads(ctx),
@@ -146,6 +164,36 @@ func remoteConsole(ctx *cli.Context) error {
 	return nil
 }
 
+// consoleHistory returns the data directory and the file within it to keep the
+// history of a console attached to the given endpoint in, so that consoles of
+// different nodes don't share their history. The history of an IPC endpoint is
+// kept next to the socket, in the data directory of the node serving it. The
+// history of a remote endpoint is kept in the local data directory, in a file
+// named after the endpoint.
+func consoleHistory(datadir, endpoint string) (string, string) {
+	if endpoint == "" || endpoint == "stdio" {
+		return datadir, console.HistoryFile
+	}
+	u, err := url.Parse(endpoint)
+	if err == nil && u.Scheme == "ipc" {
+		path := u.Path
+		if path == "" {
+			path = u.Opaque
+		}
+		return filepath.Dir(filepath.FromSlash(path)), console.HistoryFile
+	}
+	if err != nil || u.Host == "" {
+		return filepath.Dir(endpoint), console.HistoryFile
+	}
+	name := strings.Map(func(r rune) rune {
+		if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, strings.TrimSuffix(u.Host+u.Path, "/"))
+	return datadir, console.HistoryFile + "-" + name
+}
+
 // ephemeralConsole starts a new geth node, attaches an ephemeral JavaScript
 // console to it, executes each of the files specified as arguments and tears
 // everything down.
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
 	"math/rand"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -118,8 +120,9 @@ func TestGethStartupLogs(t *testing.T) {
 	defer os.RemoveAll(semiPersistentDatadir)
 
 	type matching struct {
-		pattern string // pattern is the pattern to match against geth's stderr log.
-		matches bool   // matches defines if the pattern should succeed or fail, ie. if the pattern should exist or should not exist.
+		level   string // level is the level of the expected log record, empty matches any level.
+		msg     string // msg is the message prefix of the expected log record, empty matches any message.
+		matches bool   // matches defines if the record should exist or should not exist.
 	}
 	cases := []struct {
 		flags    []string
@@ -134,7 +137,7 @@ func TestGethStartupLogs(t *testing.T) {
 			// Use without a --<chain> flag is deprecated. User will be warned.
 			flags: []string{},
 			matchers: []matching{
-				{pattern: "(?ism).+WARN.+Not specifying a chain flag is deprecated.*", matches: true},
+				{level: "warn", msg: "Not specifying a chain flag is deprecated", matches: true},
 			},
 		},
 		{
@@ -143,7 +146,7 @@ func TestGethStartupLogs(t *testing.T) {
 			// Same same but different as above.
 			flags: []string{"--networkid=42"},
 			matchers: []matching{
-				{pattern: "(?ism).+WARN.+Not specifying a chain flag is deprecated.*", matches: true},
+				{level: "warn", msg: "Not specifying a chain flag is deprecated", matches: true},
 			},
 		},
 		// Little bit of a HACK.
@@ -158,7 +161,7 @@ func TestGethStartupLogs(t *testing.T) {
 			// --<chain> flag is given. All is well. Database (storing genesis) is initialized.
 			flags: []string{"--datadir", semiPersistentDatadir, "--mainnet"},
 			matchers: []matching{
-				{pattern: "(?ism).*", matches: true},
+				{matches: true},
 			},
 		},
 		{
@@ -167,8 +170,8 @@ func TestGethStartupLogs(t *testing.T) {
 			// User should NOT be warned.
 			flags: []string{"--datadir", semiPersistentDatadir},
 			matchers: []matching{
-				{pattern: "(?ism).+WARN.+Not specifying a chain flag is deprecated.*", matches: false},
-				{pattern: "(?ism).+INFO.+Found stored genesis block.*", matches: true},
+				{level: "warn", msg: "Not specifying a chain flag is deprecated", matches: false},
+				{level: "info", msg: "Found stored genesis block", matches: true},
 			},
 			callback: func() error {
 				// Clean up this mini-suite.
@@ -178,7 +181,7 @@ func TestGethStartupLogs(t *testing.T) {
 	}
 	for i, c := range cases {
 		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
-			caseFlags := append(c.flags, "--exec", "admin.nodeInfo.name", "console")
+			caseFlags := append(c.flags, "--log.format", "json", "--exec", "admin.nodeInfo.name", "console")
 			t.Log("flags:", caseFlags)
 			geth := runGeth(t, caseFlags...)
 			geth.KillTimeout = 10 * time.Second
@@ -187,9 +190,17 @@ func TestGethStartupLogs(t *testing.T) {
 			if status := geth.ExitStatus(); status != 0 {
 				t.Errorf("expected exit status == 0, got: %d", status)
 			}
+			records := parseJSONLogs(geth.StderrText())
 			for _, match := range c.matchers {
-				if matched := regexp.MustCompile(match.pattern).MatchString(geth.StderrText()); matched != match.matches {
-					t.Errorf("unexpected stderr output; want: %s (matching?=%v) got: %s", match.pattern, match.matches, geth.StderrText())
+				matched := false
+				for _, record := range records {
+					if (match.level == "" || record.Level == match.level) && strings.HasPrefix(record.Msg, match.msg) {
+						matched = true
+						break
+					}
+				}
+				if matched != match.matches {
+					t.Errorf("unexpected stderr output; want: %s %q (matching?=%v) got: %s", match.level, match.msg, match.matches, geth.StderrText())
 				}
 			}
 			if c.callback != nil {
@@ -200,3 +211,24 @@ func TestGethStartupLogs(t *testing.T) {
 		})
 	}
 }
+
+// jsonLogRecord is a log record written by geth with --log.format=json.
+type jsonLogRecord struct {
+	Level string `json:"lvl"`
+	Msg   string `json:"msg"`
+}
+
+// parseJSONLogs decodes the JSON log records in the given output, skipping
+// any lines which are not log records.
+func parseJSONLogs(output string) []jsonLogRecord {
+	var records []jsonLogRecord
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		var record jsonLogRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err == nil && record.Msg != "" {
+			records = append(records, record)
+		}
+	}
+	return records
+}
@@ -179,3 +179,25 @@ func trulyRandInt(lo, hi int) int {
 	num, _ := rand.Int(rand.Reader, big.NewInt(int64(hi-lo)))
 	return int(num.Int64()) + lo
 }
+
+func TestConsoleHistory(t *testing.T) {
+	datadir := filepath.Join("home", ".ethereum", "classic")
+	tests := []struct {
+		endpoint     string
+		dir, history string
+	}{
+		{"", datadir, "history"},
+		{"stdio", datadir, "history"},
+		{filepath.Join("data", "mordor", "geth.ipc"), filepath.Join("data", "mordor"), "history"},
+		{"ipc:/tmp/mordor/geth.ipc", filepath.Join("/tmp", "mordor"), "history"},
+		{"ipc:data/mordor/geth.ipc", filepath.Join("data", "mordor"), "history"},
+		{"http://localhost:8545", datadir, "history-localhost_8545"},
+		{"wss://node.example.org/rpc/", datadir, "history-node.example.org_rpc"},
+	}
+	for _, tt := range tests {
+		dir, history := consoleHistory(datadir, tt.endpoint)
+		if dir != tt.dir || history != tt.history {
+			t.Errorf("endpoint %q: have %s, %s, want %s, %s", tt.endpoint, dir, history, tt.dir, tt.history)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/console/prompt"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	ctlEndpointFlag = &cli.StringFlag{
+		Name:  "endpoint",
+		Usage: "RPC endpoint of the node (default = IPC endpoint of the data directory)",
+	}
+	ctlJSONFlag = &cli.BoolFlag{
+		Name:  "json",
+		Usage: "Print the results as JSON instead of tables",
+	}
+	ctlTrustedFlag = &cli.BoolFlag{
+		Name:  "trusted",
+		Usage: "Add or remove the peer as a trusted peer",
+	}
+	ctlThreadsFlag = &cli.IntFlag{
+		Name:  "threads",
+		Usage: "Number of mining threads (default = keep the current setting)",
+	}
+	ctlYesFlag = &cli.BoolFlag{
+		Name:  "yes",
+		Usage: "Don't ask for confirmation",
+	}
+	ctlFlags = []cli.Flag{ctlEndpointFlag, ctlJSONFlag, utils.DataDirFlag, utils.HttpHeaderFlag}
+
+	ctlCommand = &cli.Command{
+		Name:  "ctl",
+		Usage: "Administer a running node over RPC",
+		Description: `
+The ctl commands perform routine operations on a running node over IPC, HTTP or
+WebSocket, without requiring the JavaScript console. Results are printed as
+tables, or as JSON with --json.`,
+		Subcommands: []*cli.Command{
+			{
+				Name:  "peers",
+				Usage: "Manage the peers of the node",
+				Subcommands: []*cli.Command{
+					{
+						Action: ctlPeersList,
+						Name:   "list",
+						Usage:  "List the connected peers",
+						Flags:  ctlFlags,
+					},
+					{
+						Action:    ctlPeersAdd,
+						Name:      "add",
+						Usage:     "Connect to a peer and keep the connection",
+						ArgsUsage: "<enode>",
+						Flags:     append([]cli.Flag{ctlTrustedFlag}, ctlFlags...),
+					},
+					{
+						Action:    ctlPeersRemove,
+						Name:      "remove",
+						Usage:     "Disconnect from a peer",
+						ArgsUsage: "<enode>",
+						Flags:     append([]cli.Flag{ctlTrustedFlag}, ctlFlags...),
+					},
+				},
+			},
+			{
+				Name:  "txpool",
+				Usage: "Inspect the transaction pool of the node",
+				Subcommands: []*cli.Command{
+					{
+						Action: ctlTxPoolStatus,
+						Name:   "status",
+						Usage:  "Show the number of pending and queued transactions",
+						Flags:  ctlFlags,
+					},
+				},
+			},
+			{
+				Action:    ctlSetHead,
+				Name:      "sethead",
+				Usage:     "Rewind the chain of the node to the given block",
+				ArgsUsage: "<number>",
+				Flags:     append([]cli.Flag{ctlYesFlag}, ctlFlags...),
+			},
+			{
+				Name:  "mining",
+				Usage: "Start or stop mining",
+				Subcommands: []*cli.Command{
+					{
+						Action: ctlMiningStart,
+						Name:   "start",
+						Usage:  "Start mining",
+						Flags:  append([]cli.Flag{ctlThreadsFlag}, ctlFlags...),
+					},
+					{
+						Action: ctlMiningStop,
+						Name:   "stop",
+						Usage:  "Stop mining",
+						Flags:  ctlFlags,
+					},
+				},
+			},
+		},
+	}
+)
+
+// dialCtl connects to the node administered by a ctl command.
+func dialCtl(ctx *cli.Context) *rpc.Client {
+	endpoint := ctx.String(ctlEndpointFlag.Name)
+	if endpoint == "" {
+		cfg := defaultNodeConfig()
+		utils.SetDataDir(ctx, &cfg)
+		endpoint = cfg.IPCEndpoint()
+	}
+	client, err := utils.DialRPCWithHeaders(endpoint, ctx.StringSlice(utils.HttpHeaderFlag.Name))
+	if err != nil {
+		utils.Fatalf("Unable to attach to remote geth: %v", err)
+	}
+	return client
+}
+
+// ctlCall performs a single RPC call against the administered node, exiting on
+// failure.
+func ctlCall(ctx *cli.Context, result interface{}, method string, args ...interface{}) {
+	client := dialCtl(ctx)
+	defer client.Close()
+
+	if err := client.Call(result, method, args...); err != nil {
+		utils.Fatalf("%s failed: %v", method, err)
+	}
+}
+
+// ctlPrint prints the result of a ctl command, either as JSON or as a table with
+// the given header and rows.
+func ctlPrint(ctx *cli.Context, w io.Writer, result interface{}, header []string, rows [][]string) {
+	if ctx.Bool(ctlJSONFlag.Name) {
+		blob, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			utils.Fatalf("Failed to encode result: %v", err)
+		}
+		fmt.Fprintln(w, string(blob))
+		return
+	}
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(header)
+	table.AppendBulk(rows)
+	table.Render()
+}
+
+// ctlArg returns the only argument of a ctl command.
+func ctlArg(ctx *cli.Context) string {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("This command requires exactly one argument: %s", ctx.Command.ArgsUsage)
+	}
+	return ctx.Args().First()
+}
+
+func ctlPeersList(ctx *cli.Context) error {
+	var peers []*p2p.PeerInfo
+	ctlCall(ctx, &peers, "admin_peers")
+
+	ctlPrint(ctx, os.Stdout, peers, []string{"ID", "Name", "Remote address", "Direction", "Flags", "Caps"}, peerRows(peers))
+	return nil
+}
+
+// peerRows formats the peers as table rows.
+func peerRows(peers []*p2p.PeerInfo) [][]string {
+	rows := make([][]string, 0, len(peers))
+	for _, peer := range peers {
+		direction := "outbound"
+		if peer.Network.Inbound {
+			direction = "inbound"
+		}
+		var flags []string
+		if peer.Network.Trusted {
+			flags = append(flags, "trusted")
+		}
+		if peer.Network.Static {
+			flags = append(flags, "static")
+		}
+		id := peer.ID
+		if len(id) > 16 {
+			id = id[:16]
+		}
+		rows = append(rows, []string{id, peer.Name, peer.Network.RemoteAddress, direction, strings.Join(flags, ","), strings.Join(peer.Caps, ",")})
+	}
+	return rows
+}
+
+func ctlPeersAdd(ctx *cli.Context) error {
+	method := "admin_addPeer"
+	if ctx.Bool(ctlTrustedFlag.Name) {
+		method = "admin_addTrustedPeer"
+	}
+	return ctlPeerChange(ctx, method, "added")
+}
+
+func ctlPeersRemove(ctx *cli.Context) error {
+	method := "admin_removePeer"
+	if ctx.Bool(ctlTrustedFlag.Name) {
+		method = "admin_removeTrustedPeer"
+	}
+	return ctlPeerChange(ctx, method, "removed")
+}
+
+// ctlPeerChange adds or removes a peer with the given admin method.
+func ctlPeerChange(ctx *cli.Context, method string, action string) error {
+	var (
+		enode = ctlArg(ctx)
+		ok    bool
+	)
+	ctlCall(ctx, &ok, method, enode)
+	ctlPrint(ctx, os.Stdout, ok, []string{"Peer", "Result"}, [][]string{{enode, action}})
+	return nil
+}
+
+func ctlTxPoolStatus(ctx *cli.Context) error {
+	var status map[string]hexutil.Uint
+	ctlCall(ctx, &status, "txpool_status")
+
+	ctlPrint(ctx, os.Stdout, status, []string{"Pending", "Queued"}, [][]string{{
+		strconv.FormatUint(uint64(status["pending"]), 10),
+		strconv.FormatUint(uint64(status["queued"]), 10),
+	}})
+	return nil
+}
+
+func ctlSetHead(ctx *cli.Context) error {
+	number, err := strconv.ParseUint(ctlArg(ctx), 0, 64)
+	if err != nil {
+		utils.Fatalf("Invalid block number: %v", err)
+	}
+	if !ctx.Bool(ctlYesFlag.Name) {
+		confirm, err := prompt.Stdin.PromptConfirm(fmt.Sprintf("Rewind the chain of the node to block #%d?", number))
+		if err != nil {
+			utils.Fatalf("Failed to read confirmation: %v", err)
+		}
+		if !confirm {
+			return nil
+		}
+	}
+	ctlCall(ctx, nil, "debug_setHead", hexutil.Uint64(number))
+	ctlPrint(ctx, os.Stdout, hexutil.Uint64(number), []string{"Head"}, [][]string{{fmt.Sprintf("#%d", number)}})
+	return nil
+}
+
+func ctlMiningStart(ctx *cli.Context) error {
+	var threads *int
+	if ctx.IsSet(ctlThreadsFlag.Name) {
+		n := ctx.Int(ctlThreadsFlag.Name)
+		threads = &n
+	}
+	ctlCall(ctx, nil, "miner_start", threads)
+	ctlPrint(ctx, os.Stdout, true, []string{"Mining"}, [][]string{{"started"}})
+	return nil
+}
+
+func ctlMiningStop(ctx *cli.Context) error {
+	ctlCall(ctx, nil, "miner_stop")
+	ctlPrint(ctx, os.Stdout, true, []string{"Mining"}, [][]string{{"stopped"}})
+	return nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/p2p"
+)
+
+func TestPeerRows(t *testing.T) {
+	inbound := &p2p.PeerInfo{ID: "0123456789abcdef0123", Name: "Geth/v1.13.0", Caps: []string{"eth/67", "snap/1"}}
+	inbound.Network.RemoteAddress = "10.0.0.1:30303"
+	inbound.Network.Inbound = true
+	inbound.Network.Trusted = true
+
+	outbound := &p2p.PeerInfo{ID: "abcd", Name: "Nethermind"}
+	outbound.Network.RemoteAddress = "10.0.0.2:30303"
+	outbound.Network.Static = true
+
+	want := [][]string{
+		{"0123456789abcdef", "Geth/v1.13.0", "10.0.0.1:30303", "inbound", "trusted", "eth/67,snap/1"},
+		{"abcd", "Nethermind", "10.0.0.2:30303", "outbound", "static", ""},
+	}
+	if have := peerRows([]*p2p.PeerInfo{inbound, outbound}); !reflect.DeepEqual(have, want) {
+		t.Fatalf("rows mismatch:\nhave %v\nwant %v", have, want)
+	}
+}
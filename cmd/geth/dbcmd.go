@@ -18,6 +18,7 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -32,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/console/prompt"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -52,6 +54,20 @@ var (
 		Usage: "If set, selects the state data for removal",
 	}
 
+	dbForceFlag = &cli.BoolFlag{
+		Name:  "i-know-what-im-doing",
+		Usage: "Confirm low-level database modifications, which may corrupt the database",
+	}
+	dbStatsWatchFlag = &cli.DurationFlag{
+		Name:  "watch",
+		Usage: "Periodically sample the size of each category of data and show the changes (e.g. 10m)",
+	}
+	dbIterateLimitFlag = &cli.IntFlag{
+		Name:  "limit",
+		Usage: "Maximum number of entries to show (0 = no limit)",
+		Value: 100,
+	}
+
 	removedbCommand = &cli.Command{
 		Action:    removeDB,
 		Name:      "removedb",
@@ -73,12 +89,17 @@ Remove blockchain and state databases`,
 			dbGetCmd,
 			dbDeleteCmd,
 			dbPutCmd,
+			dbIterateCmd,
 			dbGetSlotsCmd,
 			dbDumpFreezerIndex,
 			dbImportCmd,
 			dbExportCmd,
 			dbMetadataCmd,
 			dbCheckStateContentCmd,
+			dbConvertBinaryCmd,
+			dbIndexLogsCmd,
+			dbCompressAncientsCmd,
+			dbInspectHistoryCmd,
 		},
 	}
 	dbInspectCmd = &cli.Command{
@@ -107,7 +128,13 @@ a data corruption.`,
 		Usage:  "Print leveldb statistics",
 		Flags: flags.Merge([]cli.Flag{
 			utils.SyncModeFlag,
+			dbStatsWatchFlag,
 		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command prints the leveldb statistics. With --watch, the key-value store
+is instead inspected at the given interval, and the size and number of items of
+each category of data are shown along with their change since the previous and
+the first sample, revealing which component is growing. Note that every sample
+iterates the entire key-value store.`,
 	}
 	dbCompactCmd = &cli.Command{
 		Action: dbCompact,
@@ -139,9 +166,11 @@ corruption if it is aborted during execution'!`,
 		ArgsUsage: "<hex-encoded key>",
 		Flags: flags.Merge([]cli.Flag{
 			utils.SyncModeFlag,
+			dbForceFlag,
 		}, utils.NetworkFlags, utils.DatabaseFlags),
 		Description: `This command deletes the specified database key from the database.
-WARNING: This is a low-level operation which may cause database corruption!`,
+WARNING: This is a low-level operation which may cause database corruption!
+It must be confirmed with --i-know-what-im-doing.`,
 	}
 	dbPutCmd = &cli.Command{
 		Action:    dbPut,
@@ -150,9 +179,25 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		ArgsUsage: "<hex-encoded key> <hex-encoded value>",
 		Flags: flags.Merge([]cli.Flag{
 			utils.SyncModeFlag,
+			dbForceFlag,
 		}, utils.NetworkFlags, utils.DatabaseFlags),
 		Description: `This command sets a given database key to the given value.
-WARNING: This is a low-level operation which may cause database corruption!`,
+WARNING: This is a low-level operation which may cause database corruption!
+It must be confirmed with --i-know-what-im-doing.`,
+	}
+	dbIterateCmd = &cli.Command{
+		Action:    dbIterate,
+		Name:      "iterate",
+		Usage:     "Show the database entries with a given key prefix",
+		ArgsUsage: "<hex-encoded prefix> <hex-encoded start (optional)>",
+		Flags: flags.Merge([]cli.Flag{
+			utils.SyncModeFlag,
+			dbIterateLimitFlag,
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command iterates the database entries whose keys start with the given
+prefix, optionally starting at the given key (excluding the prefix). Entries of
+known types are decoded, and entries whose content doesn't match their key are
+reported as corrupt. The database is opened read-only.`,
 	}
 	dbGetSlotsCmd = &cli.Command{
 		Action:    dbDumpTrie,
@@ -194,6 +239,52 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		}, utils.NetworkFlags, utils.DatabaseFlags),
 		Description: "Exports the specified chain data to an RLP encoded stream, optionally gzip-compressed.",
 	}
+	dbConvertBinaryCmd = &cli.Command{
+		Action:    convertBinary,
+		Name:      "convert-binary",
+		Usage:     "Convert the state of a block into the experimental binary trie layout",
+		ArgsUsage: "<output directory> <number|hash (optional)>",
+		Flags: flags.Merge([]cli.Flag{
+			utils.SyncModeFlag,
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command copies the merkle-patricia state of the given block, or of the
+head block if none is given, into binary tries stored in a new database in the
+output directory, and reports the resulting state root and node statistics.
+The converted database can be inspected with the regular db commands, but it
+doesn't contain any chain data.`,
+	}
+	dbIndexLogsCmd = &cli.Command{
+		Action:    indexLogs,
+		Name:      "index-logs",
+		Usage:     "Build the column-oriented log index of existing blocks",
+		ArgsUsage: "<start (optional)> <end (optional)>",
+		Flags: flags.Merge([]cli.Flag{
+			utils.SyncModeFlag,
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command adds the logs of the canonical blocks in the given range, or
+of the entire chain if none is given, to the log index used to answer
+address-filtered log queries. Blocks imported with --history.logindex are
+indexed already and skipped, as are blocks without receipts. The index only
+covers blocks in the key-value store, its entries are deleted along with the
+blocks when they move to the freezer, so frozen blocks are not indexed.
+The command can be interrupted and resumed at any time.`,
+	}
+	dbCompressAncientsCmd = &cli.Command{
+		Action: compressAncients,
+		Name:   "compress-ancients",
+		Usage:  "Convert the compressed ancient tables to zstd compression",
+		Flags: flags.Merge([]cli.Flag{
+			utils.SyncModeFlag,
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command rewrites the compressed tables of the chain freezer (headers,
+bodies and receipts) with zstd compression, which is considerably denser than
+the default snappy compression. Reads are transparent, the tables record their
+compression algorithm. Tables already compressed with zstd are skipped; an
+interrupted conversion is resumed on the next run.
+
+Run the node with --datadir.ancient.compression=zstd for newly created tables
+to use zstd as well.`,
+	}
 	dbMetadataCmd = &cli.Command{
 		Action: showMetaData,
 		Name:   "metadata",
@@ -395,10 +486,66 @@ func dbStats(ctx *cli.Context) error {
 	db := utils.MakeChainDatabase(ctx, stack, true)
 	defer db.Close()
 
+	if interval := ctx.Duration(dbStatsWatchFlag.Name); interval > 0 {
+		return watchKeyValueStats(db, interval)
+	}
 	showLeveldbStats(db)
 	return nil
 }
 
+// watchKeyValueStats inspects the key-value store periodically and renders the
+// changes of each category of data, until interrupted.
+func watchKeyValueStats(db ethdb.Database, interval time.Duration) error {
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+
+	var first, prev map[string]*rawdb.KeyValueStat
+	for {
+		start := time.Now()
+		stats, err := rawdb.InspectKeyValueStore(db, nil, nil)
+		if err != nil {
+			return err
+		}
+		if first == nil {
+			first, prev = stats, stats
+		}
+		fmt.Printf("Key-value store at %v (took %v)\n", start.Format(time.RFC3339), common.PrettyDuration(time.Since(start)))
+		renderKeyValueStats(stats, prev, first)
+		prev = stats
+
+		select {
+		case <-time.After(interval):
+		case <-sigc:
+			return nil
+		}
+	}
+}
+
+// renderKeyValueStats prints the key-value store statistics with their change
+// since the previous and the first sample.
+func renderKeyValueStats(stats, prev, first map[string]*rawdb.KeyValueStat) {
+	delta := func(cur, old *rawdb.KeyValueStat) (string, string) {
+		return fmt.Sprintf("%+.2f MiB", (float64(cur.Size)-float64(old.Size))/1024/1024),
+			fmt.Sprintf("%+d", int64(cur.Count)-int64(old.Count))
+	}
+	names := make([]string, 0, len(rawdb.KeyValueCategories)+1)
+	for _, category := range rawdb.KeyValueCategories {
+		names = append(names, category[1])
+	}
+	names = append(names, rawdb.CategoryUnaccounted)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Category", "Size", "Items", "Δ Size", "Δ Items", "Δ Size (total)", "Δ Items (total)"})
+	for _, name := range names {
+		cur := stats[name]
+		prevSize, prevCount := delta(cur, prev[name])
+		firstSize, firstCount := delta(cur, first[name])
+		table.Append([]string{name, cur.Size.String(), fmt.Sprintf("%d", cur.Count), prevSize, prevCount, firstSize, firstCount})
+	}
+	table.Render()
+}
+
 func dbCompact(ctx *cli.Context) error {
 	stack, _ := makeConfigNode(ctx)
 	defer stack.Close()
@@ -442,14 +589,60 @@ func dbGet(ctx *cli.Context) error {
 		return err
 	}
 	fmt.Printf("key %#x: %#x\n", key, data)
+	if kind, summary := rawdb.DescribeEntry(key, data); kind != "unknown" {
+		fmt.Printf("%s %s\n", kind, summary)
+	}
 	return nil
 }
 
+// dbIterate shows the entries with a given key prefix
+func dbIterate(ctx *cli.Context) error {
+	if ctx.NArg() < 1 || ctx.NArg() > 2 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	prefix, err := common.ParseHexOrString(ctx.Args().Get(0))
+	if err != nil {
+		log.Info("Could not decode the prefix", "error", err)
+		return err
+	}
+	var start []byte
+	if ctx.NArg() > 1 {
+		if start, err = hexutil.Decode(ctx.Args().Get(1)); err != nil {
+			log.Info("Could not decode the start", "error", err)
+			return err
+		}
+	}
+	it := db.NewIterator(prefix, start)
+	defer it.Release()
+
+	limit := ctx.Int(dbIterateLimitFlag.Name)
+	count := 0
+	for it.Next() {
+		if limit > 0 && count >= limit {
+			fmt.Printf("limit of %d entries reached, next key %#x\n", limit, it.Key())
+			break
+		}
+		kind, summary := rawdb.DescribeEntry(it.Key(), it.Value())
+		fmt.Printf("%#x %s %s\n", it.Key(), kind, summary)
+		count++
+	}
+	return it.Error()
+}
+
 // dbDelete deletes a key from the database
 func dbDelete(ctx *cli.Context) error {
 	if ctx.NArg() != 1 {
 		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
 	}
+	if !ctx.Bool(dbForceFlag.Name) {
+		return fmt.Errorf("deleting raw database entries may corrupt the database, confirm with --%s", dbForceFlag.Name)
+	}
 	stack, _ := makeConfigNode(ctx)
 	defer stack.Close()
 
@@ -477,6 +670,9 @@ func dbPut(ctx *cli.Context) error {
 	if ctx.NArg() != 2 {
 		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
 	}
+	if !ctx.Bool(dbForceFlag.Name) {
+		return fmt.Errorf("writing raw database entries may corrupt the database, confirm with --%s", dbForceFlag.Name)
+	}
 	stack, _ := makeConfigNode(ctx)
 	defer stack.Close()
 
@@ -517,7 +713,7 @@ func dbDumpTrie(ctx *cli.Context) error {
 	db := utils.MakeChainDatabase(ctx, stack, true)
 	defer db.Close()
 
-	triedb := utils.MakeTrieDatabase(ctx, db, false, true, false)
+	triedb := utils.MakeTrieDatabase(ctx, db, false, true, nil)
 	defer triedb.Close()
 
 	var (
@@ -730,6 +926,184 @@ func exportChaindata(ctx *cli.Context) error {
 	return utils.ExportChaindata(ctx.Args().Get(1), kind, exporter(db), stop)
 }
 
+func convertBinary(ctx *cli.Context) error {
+	if ctx.NArg() < 1 || ctx.NArg() > 2 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	output := ctx.Args().Get(0)
+	if _, err := os.Stat(output); err == nil {
+		return fmt.Errorf("output directory %s already exists", output)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	if rawdb.ReadStateLayout(db) == rawdb.BinaryTrieLayout {
+		return errors.New("state is already in the binary trie layout")
+	}
+	header := rawdb.ReadHeadHeader(db)
+	if ctx.NArg() == 2 {
+		arg := ctx.Args().Get(1)
+		if hashish(arg) {
+			hash := common.HexToHash(arg)
+			if number := rawdb.ReadHeaderNumber(db, hash); number != nil {
+				header = rawdb.ReadHeader(db, hash, *number)
+			} else {
+				return fmt.Errorf("block %x not found", hash)
+			}
+		} else {
+			number, err := strconv.ParseUint(arg, 10, 64)
+			if err != nil {
+				return err
+			}
+			header = rawdb.ReadHeader(db, rawdb.ReadCanonicalHash(db, number), number)
+		}
+	}
+	if header == nil {
+		return errors.New("block not found")
+	}
+	triedb := utils.MakeTrieDatabase(ctx, db, false, true, nil)
+	defer triedb.Close()
+
+	out, err := rawdb.Open(rawdb.OpenOptions{
+		Directory: output,
+		Cache:     ctx.Int(utils.CacheFlag.Name) * ctx.Int(utils.CacheDatabaseFlag.Name) / 100,
+		Handles:   utils.MakeDatabaseHandles(ctx.Int(utils.FDLimitFlag.Name)),
+	})
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	log.Info("Converting state to binary tries", "number", header.Number, "hash", header.Hash(), "root", header.Root)
+	var (
+		start  = time.Now()
+		logged = time.Now()
+	)
+	root, stats, err := state.ConvertToBinaryTrie(triedb, header.Root, out, func(stats state.BinaryConvertStats) {
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Converting state to binary tries", "accounts", stats.Accounts, "slots", stats.Slots, "nodes", stats.Nodes, "size", common.StorageSize(stats.Size), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	})
+	if err != nil {
+		return err
+	}
+	rawdb.WriteStateLayout(out, rawdb.BinaryTrieLayout)
+
+	log.Info("Converted state to binary tries", "root", root, "accounts", stats.Accounts, "slots", stats.Slots, "nodes", stats.Nodes, "size", common.StorageSize(stats.Size), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func indexLogs(ctx *cli.Context) error {
+	if ctx.NArg() > 2 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	head := rawdb.ReadHeadBlock(db)
+	if head == nil {
+		return errors.New("head block not found")
+	}
+	var (
+		first uint64
+		last  = head.NumberU64()
+	)
+	if ctx.NArg() > 0 {
+		n, err := strconv.ParseUint(ctx.Args().Get(0), 10, 64)
+		if err != nil {
+			return err
+		}
+		first = n
+	}
+	if ctx.NArg() > 1 {
+		n, err := strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+		if err != nil {
+			return err
+		}
+		last = min(n, last)
+	}
+	// Frozen blocks are never deleted from the key-value store again, so their
+	// index entries would outlive the rest of the index.
+	if frozen, err := db.Ancients(); err == nil && frozen > first {
+		first = frozen
+	}
+	if first > last {
+		return fmt.Errorf("invalid range %d-%d", first, last)
+	}
+	var (
+		interrupt = make(chan os.Signal, 1)
+		batch     = db.NewBatch()
+		start     = time.Now()
+		logged    = time.Now()
+
+		indexed, skipped, missing uint64
+	)
+	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	log.Info("Indexing block logs", "first", first, "last", last)
+loop:
+	for number := first; number <= last; number++ {
+		select {
+		case <-interrupt:
+			log.Info("Log indexing interrupted", "next", number)
+			break loop
+		default:
+		}
+		hash := rawdb.ReadCanonicalHash(db, number)
+		if hash == (common.Hash{}) {
+			return fmt.Errorf("canonical hash #%d not found", number)
+		}
+		if rawdb.HasBlockLogs(db, hash, number) {
+			skipped++
+			continue
+		}
+		logs := rawdb.ReadLogs(db, hash, number)
+		if logs == nil {
+			missing++
+			continue
+		}
+		rawdb.WriteBlockLogs(batch, hash, number, logs)
+		indexed++
+
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Indexing block logs", "number", number, "indexed", indexed, "skipped", skipped, "missing", missing, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("Indexed block logs", "indexed", indexed, "skipped", skipped, "missing", missing, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func compressAncients(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	ancient := stack.ResolveAncient("chaindata", ctx.String(utils.AncientFlag.Name))
+	stack.Close()
+
+	start := time.Now()
+	if err := rawdb.CompressAncients(ancient); err != nil {
+		return err
+	}
+	log.Info("Compressed ancient tables", "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
 func showMetaData(ctx *cli.Context) error {
 	stack, _ := makeConfigNode(ctx)
 	defer stack.Close()
@@ -0,0 +1,274 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	dbInspectHistoryFlags = flags.Merge([]cli.Flag{
+		utils.SyncModeFlag,
+	}, utils.NetworkFlags, utils.DatabaseFlags)
+
+	dbInspectHistoryCmd = &cli.Command{
+		Name:  "inspect-history",
+		Usage: "Track the growth of the database across runs",
+		Description: `These commands record the storage size of every category of data in the
+database (trie nodes, receipts, snapshots, ancient tables...) as snapshots kept
+in the database itself, and compare them, showing which components drive the
+growth of the database between runs.`,
+		Subcommands: []*cli.Command{
+			{
+				Action: dbInspectHistorySnapshot,
+				Name:   "snapshot",
+				Usage:  "Record the current size of every category of data",
+				Flags:  dbInspectHistoryFlags,
+				Description: `This command iterates the entire database like 'geth db inspect' does, and
+stores the sizes found as a new snapshot. The growth since the previous snapshot
+is reported.`,
+			},
+			{
+				Action: dbInspectHistoryList,
+				Name:   "list",
+				Usage:  "List the recorded snapshots",
+				Flags:  dbInspectHistoryFlags,
+			},
+			{
+				Action:    dbInspectHistoryDiff,
+				Name:      "diff",
+				Usage:     "Show the growth of every category of data between two snapshots",
+				ArgsUsage: "[<from> [<to>]]",
+				Flags:     dbInspectHistoryFlags,
+				Description: `This command compares two snapshots, identified by their number as shown by
+'geth db inspect-history list'. By default, the last two snapshots are compared.
+If only <from> is given, it is compared to the latest snapshot. Categories are
+ordered by growth, largest first.`,
+			},
+			{
+				Action:    dbInspectHistoryDelete,
+				Name:      "delete",
+				Usage:     "Delete a recorded snapshot",
+				ArgsUsage: "<number>",
+				Flags:     dbInspectHistoryFlags,
+			},
+		},
+	}
+)
+
+func dbInspectHistorySnapshot(ctx *cli.Context) error {
+	if ctx.NArg() != 0 {
+		return errors.New("this command takes no arguments")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	snap, err := rawdb.TakeInspectSnapshot(db)
+	if err != nil {
+		return err
+	}
+	snaps := rawdb.ReadInspectSnapshots(db)
+	rawdb.WriteInspectSnapshot(db, snap)
+
+	fmt.Printf("Recorded snapshot #%d at %s, head block %d, total size %v\n",
+		len(snaps)+1, time.Unix(int64(snap.Time), 0).Format(time.DateTime), snap.Head, common.StorageSize(snap.Size()))
+	if len(snaps) > 0 {
+		printInspectDiff(snaps[len(snaps)-1], snap)
+	}
+	return nil
+}
+
+func dbInspectHistoryList(ctx *cli.Context) error {
+	if ctx.NArg() != 0 {
+		return errors.New("this command takes no arguments")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	snaps := rawdb.ReadInspectSnapshots(db)
+	if len(snaps) == 0 {
+		fmt.Println("No snapshots recorded, take one with 'geth db inspect-history snapshot'")
+		return nil
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"#", "Time", "Head", "Size", "Δ Size"})
+	for i, snap := range snaps {
+		growth := ""
+		if i > 0 {
+			growth = sizeDelta(snaps[i-1].Size(), snap.Size())
+		}
+		table.Append([]string{
+			strconv.Itoa(i + 1),
+			time.Unix(int64(snap.Time), 0).Format(time.DateTime),
+			strconv.FormatUint(snap.Head, 10),
+			common.StorageSize(snap.Size()).String(),
+			growth,
+		})
+	}
+	table.Render()
+	return nil
+}
+
+func dbInspectHistoryDiff(ctx *cli.Context) error {
+	if ctx.NArg() > 2 {
+		return fmt.Errorf("max 2 arguments: %v", ctx.Command.ArgsUsage)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	snaps := rawdb.ReadInspectSnapshots(db)
+	if len(snaps) < 2 {
+		return fmt.Errorf("need at least 2 snapshots to compare, have %d", len(snaps))
+	}
+	from, to := len(snaps)-1, len(snaps)
+	var err error
+	if ctx.NArg() >= 1 {
+		if from, err = snapshotNumber(ctx.Args().Get(0), len(snaps)); err != nil {
+			return err
+		}
+	}
+	if ctx.NArg() >= 2 {
+		if to, err = snapshotNumber(ctx.Args().Get(1), len(snaps)); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Growth from snapshot #%d (%s, head %d) to #%d (%s, head %d)\n",
+		from, time.Unix(int64(snaps[from-1].Time), 0).Format(time.DateTime), snaps[from-1].Head,
+		to, time.Unix(int64(snaps[to-1].Time), 0).Format(time.DateTime), snaps[to-1].Head)
+	printInspectDiff(snaps[from-1], snaps[to-1])
+	return nil
+}
+
+func dbInspectHistoryDelete(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("required argument: %v", ctx.Command.ArgsUsage)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	snaps := rawdb.ReadInspectSnapshots(db)
+	number, err := snapshotNumber(ctx.Args().Get(0), len(snaps))
+	if err != nil {
+		return err
+	}
+	rawdb.DeleteInspectSnapshot(db, snaps[number-1].Time)
+	fmt.Printf("Deleted snapshot #%d\n", number)
+	return nil
+}
+
+// snapshotNumber parses the number of a snapshot, as shown by the list command.
+func snapshotNumber(arg string, count int) (int, error) {
+	number, err := strconv.Atoi(arg)
+	if err != nil || number < 1 || number > count {
+		return 0, fmt.Errorf("invalid snapshot number %q, have %d snapshots", arg, count)
+	}
+	return number, nil
+}
+
+// printInspectDiff prints the growth of every category of data between two
+// database size snapshots.
+func printInspectDiff(from, to *rawdb.InspectSnapshot) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Database", "Category", "Size", "Δ Size", "Δ Size (%)", "Δ Items"})
+	table.SetFooter([]string{"", "Total", common.StorageSize(to.Size()).String(), sizeDelta(from.Size(), to.Size()), sizeGrowth(from.Size(), to.Size()), ""})
+	table.AppendBulk(inspectDiff(from, to))
+	table.Render()
+}
+
+// inspectDiff returns the rows reporting the growth of every category of data
+// between two database size snapshots, largest growth first. Categories empty
+// in both are left out.
+func inspectDiff(from, to *rawdb.InspectSnapshot) [][]string {
+	type category struct{ database, name string }
+	var (
+		order []category
+		old   = make(map[category]rawdb.InspectStat)
+		cur   = make(map[category]rawdb.InspectStat)
+	)
+	for _, stat := range from.Stats {
+		c := category{stat.Database, stat.Category}
+		old[c] = stat
+		order = append(order, c)
+	}
+	for _, stat := range to.Stats {
+		c := category{stat.Database, stat.Category}
+		if _, ok := old[c]; !ok {
+			order = append(order, c)
+		}
+		cur[c] = stat
+	}
+	growth := func(c category) int64 {
+		return int64(cur[c].Size) - int64(old[c].Size)
+	}
+	sort.SliceStable(order, func(i, j int) bool { return growth(order[i]) > growth(order[j]) })
+
+	var rows [][]string
+	for _, c := range order {
+		o, n := old[c], cur[c]
+		if o.Size == 0 && n.Size == 0 {
+			continue
+		}
+		rows = append(rows, []string{
+			c.database, c.name,
+			common.StorageSize(n.Size).String(),
+			sizeDelta(o.Size, n.Size),
+			sizeGrowth(o.Size, n.Size),
+			fmt.Sprintf("%+d", int64(n.Count)-int64(o.Count)),
+		})
+	}
+	return rows
+}
+
+// sizeDelta formats the difference between two storage sizes.
+func sizeDelta(from, to uint64) string {
+	return fmt.Sprintf("%+.2f MiB", (float64(to)-float64(from))/1024/1024)
+}
+
+// sizeGrowth formats the relative difference between two storage sizes.
+func sizeGrowth(from, to uint64) string {
+	if from == 0 {
+		if to == 0 {
+			return "+0.0%"
+		}
+		return "new"
+	}
+	return fmt.Sprintf("%+.1f%%", (float64(to)-float64(from))/float64(from)*100)
+}
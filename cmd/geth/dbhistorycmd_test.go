@@ -0,0 +1,50 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+)
+
+func TestInspectDiff(t *testing.T) {
+	const mib = 1024 * 1024
+	from := &rawdb.InspectSnapshot{Stats: []rawdb.InspectStat{
+		{Database: "Key-Value store", Category: "Headers", Size: 10 * mib, Count: 100},
+		{Database: "Key-Value store", Category: "Receipt lists", Size: 20 * mib, Count: 100},
+		{Database: "Key-Value store", Category: "Bodies", Size: 0, Count: 0},
+		{Database: "Key-Value store", Category: "Clique snapshots", Size: 0, Count: 0},
+	}}
+	to := &rawdb.InspectSnapshot{Stats: []rawdb.InspectStat{
+		{Database: "Key-Value store", Category: "Headers", Size: 11 * mib, Count: 110},
+		{Database: "Key-Value store", Category: "Receipt lists", Size: 15 * mib, Count: 90},
+		{Database: "Key-Value store", Category: "Bodies", Size: 4 * mib, Count: 10},
+		{Database: "Key-Value store", Category: "Clique snapshots", Size: 0, Count: 0},
+		{Database: "Ancient store (Chain)", Category: "Headers", Size: 2 * mib, Count: 5},
+	}}
+	want := [][]string{
+		{"Key-Value store", "Bodies", "4.00 MiB", "+4.00 MiB", "new", "+10"},
+		{"Ancient store (Chain)", "Headers", "2.00 MiB", "+2.00 MiB", "new", "+5"},
+		{"Key-Value store", "Headers", "11.00 MiB", "+1.00 MiB", "+10.0%", "+10"},
+		{"Key-Value store", "Receipt lists", "15.00 MiB", "-5.00 MiB", "-25.0%", "-10"},
+	}
+	if have := inspectDiff(from, to); !reflect.DeepEqual(have, want) {
+		t.Errorf("wrong diff\nhave %v\nwant %v", have, want)
+	}
+}
@@ -0,0 +1,426 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/fdlimit"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p/discover"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	doctorOfflineFlag = &cli.BoolFlag{
+		Name:  "offline",
+		Usage: "Skip the checks requiring network access (clock drift, bootnode reachability)",
+	}
+	doctorCommand = &cli.Command{
+		Action:    doctor,
+		Name:      "doctor",
+		Usage:     "Check the environment of the node for common problems",
+		ArgsUsage: "",
+		Flags: flags.Merge([]cli.Flag{
+			doctorOfflineFlag,
+			configFileFlag,
+		}, utils.NetworkFlags, utils.DatabaseFlags, []cli.Flag{
+			utils.ListenPortFlag,
+			utils.BootnodesFlag,
+		}),
+		Description: `
+    geth doctor [--offline]
+
+Runs a series of diagnostics against the environment the node runs in: data
+directory permissions, clock drift against the NTP pool, the open file limit,
+the availability of the p2p listening port, outbound connectivity to the
+bootnodes, the latency of the disk and the consistency of the chain database.
+Every problem found is printed along with a suggested remedy. The command exits
+with a non-zero status if any of the checks failed.
+
+The database check is skipped if the data directory is in use by a running node.`,
+	}
+)
+
+// doctorStatus is the outcome of a single diagnostic check.
+type doctorStatus int
+
+const (
+	doctorOK doctorStatus = iota
+	doctorWarn
+	doctorFail
+	doctorSkip
+)
+
+func (s doctorStatus) String() string {
+	switch s {
+	case doctorOK:
+		return "OK"
+	case doctorWarn:
+		return "WARN"
+	case doctorFail:
+		return "FAIL"
+	default:
+		return "SKIP"
+	}
+}
+
+// doctorResult is the result of a diagnostic check, along with the remedy for
+// the problem detected, if any.
+type doctorResult struct {
+	status doctorStatus
+	detail string
+	remedy string
+}
+
+func doctor(ctx *cli.Context) error {
+	var (
+		cfg     = loadBaseConfig(ctx)
+		datadir = cfg.Node.DataDir
+		failed  int
+	)
+	report := func(name string, res doctorResult) {
+		fmt.Printf("[%-4s] %-12s %s\n", res.status, name, res.detail)
+		if res.remedy != "" && (res.status == doctorWarn || res.status == doctorFail) {
+			fmt.Printf("       %-12s -> %s\n", "", res.remedy)
+		}
+		if res.status == doctorFail {
+			failed++
+		}
+	}
+	report("datadir", checkDataDir(datadir))
+
+	if ctx.Bool(doctorOfflineFlag.Name) {
+		report("clock", doctorResult{status: doctorSkip, detail: "offline mode"})
+	} else {
+		report("clock", checkClockDrift(discover.ClockDrift()))
+	}
+	report("file limit", checkFileLimit(fdlimit.Maximum()))
+	report("p2p port", checkListenPort(cfg.Node.P2P.ListenAddr))
+
+	if ctx.Bool(doctorOfflineFlag.Name) {
+		report("bootnodes", doctorResult{status: doctorSkip, detail: "offline mode"})
+	} else {
+		report("bootnodes", checkBootnodes(cfg.Node.P2P.BootstrapNodes))
+	}
+	report("disk latency", checkDiskLatency(datadir, 20))
+	report("database", checkDatabase(ctx, &cfg.Node))
+
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+// checkDataDir verifies that the data directory, or the directory it is to be
+// created in, is writable and not accessible to other users.
+func checkDataDir(datadir string) doctorResult {
+	if datadir == "" {
+		return doctorResult{status: doctorWarn, detail: "no data directory, the node runs in memory"}
+	}
+	dir := datadir
+	info, err := os.Stat(dir)
+	for os.IsNotExist(err) && filepath.Dir(dir) != dir {
+		dir = filepath.Dir(dir)
+		info, err = os.Stat(dir)
+	}
+	if err != nil {
+		return doctorResult{
+			status: doctorFail,
+			detail: fmt.Sprintf("cannot access %s: %v", dir, err),
+			remedy: "check the permissions of the data directory or pick another one with --datadir",
+		}
+	}
+	if !info.IsDir() {
+		return doctorResult{
+			status: doctorFail,
+			detail: fmt.Sprintf("%s is not a directory", dir),
+			remedy: "point --datadir at a directory",
+		}
+	}
+	probe, err := os.CreateTemp(dir, ".geth-doctor-*")
+	if err != nil {
+		return doctorResult{
+			status: doctorFail,
+			detail: fmt.Sprintf("%s is not writable: %v", dir, err),
+			remedy: fmt.Sprintf("make %s writable by the user running geth (e.g. chown -R $USER %s)", dir, dir),
+		}
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	if dir != datadir {
+		return doctorResult{status: doctorOK, detail: fmt.Sprintf("%s does not exist yet, %s is writable", datadir, dir)}
+	}
+	if info.Mode().Perm()&0o002 != 0 {
+		return doctorResult{
+			status: doctorWarn,
+			detail: fmt.Sprintf("%s is world-writable (%v)", datadir, info.Mode().Perm()),
+			remedy: fmt.Sprintf("restrict access to the keys and the database with chmod 700 %s", datadir),
+		}
+	}
+	return doctorResult{status: doctorOK, detail: fmt.Sprintf("%s is writable", datadir)}
+}
+
+// checkClockDrift evaluates the measured drift of the local clock.
+func checkClockDrift(drift time.Duration, err error) doctorResult {
+	if err != nil {
+		return doctorResult{
+			status: doctorWarn,
+			detail: fmt.Sprintf("failed to query the NTP pool: %v", err),
+			remedy: "allow outbound UDP traffic to port 123, or verify time synchronisation manually",
+		}
+	}
+	if drift < 0 {
+		drift = -drift
+	}
+	drift = drift.Round(time.Millisecond)
+	switch {
+	case drift > 10*time.Second:
+		return doctorResult{
+			status: doctorFail,
+			detail: fmt.Sprintf("clock is off by %v, peers will reject the node", drift),
+			remedy: "enable network time synchronisation (e.g. timedatectl set-ntp true, chrony or ntpd)",
+		}
+	case drift > time.Second:
+		return doctorResult{
+			status: doctorWarn,
+			detail: fmt.Sprintf("clock is off by %v", drift),
+			remedy: "enable network time synchronisation (e.g. timedatectl set-ntp true, chrony or ntpd)",
+		}
+	}
+	return doctorResult{status: doctorOK, detail: fmt.Sprintf("clock is off by %v", drift)}
+}
+
+// checkFileLimit evaluates the maximum number of file descriptors the process
+// may open.
+func checkFileLimit(limit int, err error) doctorResult {
+	if err != nil {
+		return doctorResult{status: doctorWarn, detail: fmt.Sprintf("failed to retrieve the file descriptor limit: %v", err)}
+	}
+	if limit < 2048 {
+		return doctorResult{
+			status: doctorWarn,
+			detail: fmt.Sprintf("only %d file descriptors allowed, half of which are used for the database", limit),
+			remedy: "raise the hard limit of open files (e.g. ulimit -n 65536, or LimitNOFILE in the systemd unit)",
+		}
+	}
+	return doctorResult{status: doctorOK, detail: fmt.Sprintf("%d file descriptors allowed", limit)}
+}
+
+// checkListenPort verifies that the p2p listening address can be bound, both
+// for TCP and UDP.
+func checkListenPort(addr string) doctorResult {
+	if addr == "" {
+		return doctorResult{status: doctorSkip, detail: "p2p listener disabled"}
+	}
+	tcp, err := net.Listen("tcp", addr)
+	if err != nil {
+		return doctorResult{
+			status: doctorFail,
+			detail: fmt.Sprintf("cannot listen on TCP %s: %v", addr, err),
+			remedy: "stop the process using the port (is another node running?) or pick another one with --port",
+		}
+	}
+	tcp.Close()
+
+	udp, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return doctorResult{
+			status: doctorFail,
+			detail: fmt.Sprintf("cannot listen on UDP %s: %v", addr, err),
+			remedy: "stop the process using the port (is another node running?) or pick another one with --port",
+		}
+	}
+	udp.Close()
+
+	return doctorResult{status: doctorOK, detail: fmt.Sprintf("TCP and UDP %s available", addr)}
+}
+
+// checkBootnodes verifies that at least one of the bootnodes can be reached
+// over TCP.
+func checkBootnodes(nodes []*enode.Node) doctorResult {
+	if len(nodes) == 0 {
+		return doctorResult{status: doctorSkip, detail: "no bootnodes configured"}
+	}
+	var reached, tried int
+	for _, n := range nodes {
+		if n.IP() == nil || n.TCP() == 0 {
+			continue
+		}
+		conn, err := net.DialTimeout("tcp", fmt.Sprintf("%v:%d", n.IP(), n.TCP()), 5*time.Second)
+		if err == nil {
+			conn.Close()
+			reached++
+		}
+		if tried++; tried == 5 {
+			break
+		}
+	}
+	if reached == 0 {
+		return doctorResult{
+			status: doctorFail,
+			detail: fmt.Sprintf("none of %d bootnodes reachable", tried),
+			remedy: "allow outbound TCP and UDP traffic in the firewall, the node won't find any peers",
+		}
+	}
+	return doctorResult{status: doctorOK, detail: fmt.Sprintf("%d of %d bootnodes reachable", reached, tried)}
+}
+
+// checkDiskLatency measures the average latency of small synchronous writes in
+// the data directory, which dominates the import speed of the node.
+func checkDiskLatency(datadir string, samples int) doctorResult {
+	dir := datadir
+	for dir != "" && !common.FileExist(dir) && filepath.Dir(dir) != dir {
+		dir = filepath.Dir(dir)
+	}
+	if dir == "" {
+		return doctorResult{status: doctorSkip, detail: "no data directory"}
+	}
+	f, err := os.CreateTemp(dir, ".geth-doctor-*")
+	if err != nil {
+		return doctorResult{status: doctorSkip, detail: fmt.Sprintf("cannot write to %s: %v", dir, err)}
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	blob := make([]byte, 4096)
+	start := time.Now()
+	for i := 0; i < samples; i++ {
+		if _, err := f.WriteAt(blob, int64(i*len(blob))); err != nil {
+			return doctorResult{status: doctorFail, detail: fmt.Sprintf("write failed: %v", err), remedy: "check the health of the disk"}
+		}
+		if err := f.Sync(); err != nil {
+			return doctorResult{status: doctorFail, detail: fmt.Sprintf("sync failed: %v", err), remedy: "check the health of the disk"}
+		}
+	}
+	latency := (time.Since(start) / time.Duration(samples)).Round(10 * time.Microsecond)
+	switch {
+	case latency > 50*time.Millisecond:
+		return doctorResult{
+			status: doctorFail,
+			detail: fmt.Sprintf("synchronous write latency %v", latency),
+			remedy: "the node won't keep up with the chain, move the data directory to a local SSD",
+		}
+	case latency > 10*time.Millisecond:
+		return doctorResult{
+			status: doctorWarn,
+			detail: fmt.Sprintf("synchronous write latency %v", latency),
+			remedy: "this looks like a spinning disk or network storage, syncing will be slow; an SSD is recommended",
+		}
+	}
+	return doctorResult{status: doctorOK, detail: fmt.Sprintf("synchronous write latency %v", latency)}
+}
+
+// checkDatabase opens the chain database read-only and inspects its
+// consistency.
+func checkDatabase(ctx *cli.Context, config *node.Config) doctorResult {
+	if config.DataDir == "" || !common.FileExist(filepath.Join(config.DataDir, config.Name, "chaindata")) {
+		return doctorResult{status: doctorSkip, detail: "no chain database yet"}
+	}
+	stack, err := node.New(config)
+	if errors.Is(err, node.ErrDatadirUsed) {
+		return doctorResult{status: doctorSkip, detail: "data directory in use by a running node"}
+	}
+	if err != nil {
+		return doctorResult{status: doctorFail, detail: fmt.Sprintf("cannot open the data directory: %v", err)}
+	}
+	defer stack.Close()
+
+	db, err := stack.OpenDatabaseWithFreezer("chaindata", 16, utils.MakeDatabaseHandles(0), ctx.String(utils.AncientFlag.Name), "", true)
+	if err != nil {
+		return doctorResult{
+			status: doctorFail,
+			detail: fmt.Sprintf("cannot open the chain database: %v", err),
+			remedy: "if the database is corrupted, remove it with geth removedb and resync",
+		}
+	}
+	defer db.Close()
+
+	return inspectDatabaseConsistency(db)
+}
+
+// inspectDatabaseConsistency checks that the chain head markers point to
+// existing headers, that the freezer is not ahead of the chain and that the
+// state of the head block is available.
+func inspectDatabaseConsistency(db ethdb.Database) doctorResult {
+	headerHash := rawdb.ReadHeadHeaderHash(db)
+	if headerHash == (common.Hash{}) {
+		return doctorResult{status: doctorOK, detail: "empty database"}
+	}
+	heads := []struct {
+		name string
+		hash common.Hash
+	}{
+		{"header", headerHash},
+		{"snap sync block", rawdb.ReadHeadFastBlockHash(db)},
+		{"block", rawdb.ReadHeadBlockHash(db)},
+	}
+	var numbers []uint64
+	for _, head := range heads {
+		number := rawdb.ReadHeaderNumber(db, head.hash)
+		if number == nil || rawdb.ReadHeader(db, head.hash, *number) == nil {
+			return doctorResult{
+				status: doctorFail,
+				detail: fmt.Sprintf("head %s %x missing", head.name, head.hash),
+				remedy: "the database is corrupted, remove it with geth removedb and resync",
+			}
+		}
+		numbers = append(numbers, *number)
+	}
+	if frozen, err := db.Ancients(); err == nil && frozen > numbers[0]+1 {
+		return doctorResult{
+			status: doctorWarn,
+			detail: fmt.Sprintf("freezer holds %d blocks, ahead of head header #%d", frozen, numbers[0]),
+			remedy: "the node will truncate the freezer on startup; if that fails, remove the database with geth removedb",
+		}
+	}
+	head := rawdb.ReadHeader(db, heads[2].hash, numbers[2])
+	var available bool
+	if rawdb.ReadStateScheme(db) == rawdb.PathScheme {
+		available = rawdb.HasAccountTrieNode(db, nil, head.Root) || rawdb.ReadStateID(db, head.Root) != nil
+	} else {
+		available = rawdb.HasLegacyTrieNode(db, head.Root)
+	}
+	detail := fmt.Sprintf("head header #%d, snap sync block #%d, block #%d", numbers[0], numbers[1], numbers[2])
+	if !available {
+		return doctorResult{
+			status: doctorWarn,
+			detail: detail + ", head state missing",
+			remedy: "the node will rewind or resume state sync on startup, which may take a while",
+		}
+	}
+	if root := rawdb.ReadSnapshotRoot(db); root != (common.Hash{}) && root != head.Root {
+		return doctorResult{
+			status: doctorWarn,
+			detail: detail + ", snapshot not at head",
+			remedy: "the snapshot will be regenerated on startup, expect higher disk usage meanwhile",
+		}
+	}
+	return doctorResult{status: doctorOK, detail: detail + ", head state available"}
+}
@@ -0,0 +1,88 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestDoctorChecks(t *testing.T) {
+	tests := []struct {
+		res  doctorResult
+		want doctorStatus
+	}{
+		{checkClockDrift(200*time.Millisecond, nil), doctorOK},
+		{checkClockDrift(-3*time.Second, nil), doctorWarn},
+		{checkClockDrift(time.Minute, nil), doctorFail},
+		{checkClockDrift(0, errors.New("timeout")), doctorWarn},
+		{checkFileLimit(65536, nil), doctorOK},
+		{checkFileLimit(1024, nil), doctorWarn},
+	}
+	for i, tt := range tests {
+		if tt.res.status != tt.want {
+			t.Errorf("test %d: status mismatch: have %v, want %v (%s)", i, tt.res.status, tt.want, tt.res.detail)
+		}
+	}
+}
+
+func TestDoctorDataDir(t *testing.T) {
+	dir := t.TempDir()
+	if res := checkDataDir(dir); res.status != doctorOK {
+		t.Errorf("existing datadir: have %v, want %v (%s)", res.status, doctorOK, res.detail)
+	}
+	if res := checkDataDir(filepath.Join(dir, "a", "b")); res.status != doctorOK {
+		t.Errorf("missing datadir: have %v, want %v (%s)", res.status, doctorOK, res.detail)
+	}
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if res := checkDataDir(file); res.status != doctorFail {
+		t.Errorf("file as datadir: have %v, want %v (%s)", res.status, doctorFail, res.detail)
+	}
+}
+
+func TestDoctorDatabase(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	if res := inspectDatabaseConsistency(db); res.status != doctorOK {
+		t.Fatalf("empty database: have %v, want %v (%s)", res.status, doctorOK, res.detail)
+	}
+	header := &types.Header{Number: big.NewInt(0), Root: common.Hash{0x01}, Difficulty: big.NewInt(1)}
+	rawdb.WriteHeadHeaderHash(db, header.Hash())
+	rawdb.WriteHeadFastBlockHash(db, header.Hash())
+	rawdb.WriteHeadBlockHash(db, header.Hash())
+	if res := inspectDatabaseConsistency(db); res.status != doctorFail {
+		t.Fatalf("missing head header: have %v, want %v (%s)", res.status, doctorFail, res.detail)
+	}
+	rawdb.WriteHeader(db, header)
+	if res := inspectDatabaseConsistency(db); res.status != doctorWarn {
+		t.Fatalf("missing head state: have %v, want %v (%s)", res.status, doctorWarn, res.detail)
+	}
+	rawdb.WriteLegacyTrieNode(db, header.Root, []byte{0xc0})
+	if res := inspectDatabaseConsistency(db); res.status != doctorOK {
+		t.Fatalf("consistent database: have %v, want %v (%s)", res.status, doctorOK, res.detail)
+	}
+}
@@ -0,0 +1,318 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/bn256"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	benchBlockFlag = &cli.Uint64Flag{
+		Name:  "block",
+		Usage: "Number of the block whose active precompiles are benchmarked (default = all scheduled forks)",
+	}
+	benchTargetFlag = &cli.Float64Flag{
+		Name:  "target",
+		Usage: "Target throughput in Mgas/s the gas costs are calibrated against (default = measured ecrecover throughput)",
+	}
+	evmCommand = &cli.Command{
+		Name:  "evm",
+		Usage: "Tools for the EVM of the configured network",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "bench-precompiles",
+				Usage:  "Benchmark the active precompiles and report their gas calibration",
+				Action: benchPrecompiles,
+				Flags: flags.Merge([]cli.Flag{
+					benchBlockFlag,
+					benchTargetFlag,
+					ctlJSONFlag,
+				}, utils.NetworkFlags),
+				Description: `
+    geth --classic evm bench-precompiles
+    geth --classic evm bench-precompiles --block 19250000 --target 40
+
+Measures the execution time of every precompile active on the configured
+network at the given block, for a set of representative inputs, on the local
+hardware. The report lists the charged gas, the measured throughput in Mgas/s
+and the gas cost every input would have if all precompiles ran at the target
+throughput. Precompiles charging much less than their calibrated cost are
+underpriced relative to the target. By convention the target defaults to the
+throughput of ecrecover, whose price is the reference in repricing proposals.`,
+			},
+		},
+	}
+)
+
+// precompileNames are the names of the known precompiles by address.
+var precompileNames = map[common.Address]string{
+	common.BytesToAddress([]byte{0x01}): "ecrecover",
+	common.BytesToAddress([]byte{0x02}): "sha256",
+	common.BytesToAddress([]byte{0x03}): "ripemd160",
+	common.BytesToAddress([]byte{0x04}): "identity",
+	common.BytesToAddress([]byte{0x05}): "modexp",
+	common.BytesToAddress([]byte{0x06}): "bn256Add",
+	common.BytesToAddress([]byte{0x07}): "bn256ScalarMul",
+	common.BytesToAddress([]byte{0x08}): "bn256Pairing",
+	common.BytesToAddress([]byte{0x09}): "blake2F",
+	common.BytesToAddress([]byte{0x0a}): "kzgPointEvaluation",
+}
+
+// precompileInput is a named benchmark input of a precompile.
+type precompileInput struct {
+	name  string
+	input []byte
+}
+
+// PrecompileBench is the benchmark result of a precompile for one input.
+type PrecompileBench struct {
+	Address    common.Address `json:"address"`
+	Name       string         `json:"name"`
+	Input      string         `json:"input"`
+	Size       int            `json:"size"`
+	Gas        uint64         `json:"gas"`
+	NsPerOp    int64          `json:"nsPerOp"`
+	MgasPerSec float64        `json:"mgasPerSec"`
+	Calibrated uint64         `json:"calibratedGas"`
+	Error      string         `json:"error,omitempty"`
+}
+
+func benchPrecompiles(ctx *cli.Context) error {
+	config := utils.MakeGenesis(ctx).Config
+	number, timestamp := new(big.Int).SetUint64(math.MaxInt64), uint64(math.MaxInt64)
+	if ctx.IsSet(benchBlockFlag.Name) {
+		number.SetUint64(ctx.Uint64(benchBlockFlag.Name))
+		timestamp = uint64(time.Now().Unix())
+	}
+	precompiles := vm.PrecompiledContractsForConfig(config, number, &timestamp)
+
+	addrs := make([]common.Address, 0, len(precompiles))
+	for addr := range precompiles {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i].Cmp(addrs[j]) < 0 })
+
+	var results []*PrecompileBench
+	for _, addr := range addrs {
+		inputs, err := precompileInputs(addr)
+		if err != nil {
+			utils.Fatalf("Failed to generate inputs for %x: %v", addr, err)
+		}
+		if len(inputs) == 0 {
+			log.Warn("No benchmark inputs for precompile", "address", addr)
+			continue
+		}
+		for _, input := range inputs {
+			log.Info("Benchmarking precompile", "name", precompileName(addr), "input", input.name)
+			results = append(results, benchPrecompile(addr, precompiles[addr], input))
+		}
+	}
+	target := ctx.Float64(benchTargetFlag.Name)
+	if !ctx.IsSet(benchTargetFlag.Name) {
+		for _, res := range results {
+			if res.Name == "ecrecover" && res.Error == "" {
+				target = res.MgasPerSec
+			}
+		}
+	}
+	if target <= 0 {
+		utils.Fatalf("No calibration target, set --%s", benchTargetFlag.Name)
+	}
+	calibratePrecompiles(results, target)
+
+	rows := make([][]string, 0, len(results))
+	for _, res := range results {
+		rows = append(rows, []string{
+			res.Name, res.Input, strconv.Itoa(res.Size), strconv.FormatUint(res.Gas, 10),
+			time.Duration(res.NsPerOp).String(), fmt.Sprintf("%.2f", res.MgasPerSec),
+			strconv.FormatUint(res.Calibrated, 10), res.Error,
+		})
+	}
+	ctlPrint(ctx, os.Stdout, results, []string{"Precompile", "Input", "Size", "Gas", "Time", "Mgas/s", "Calibrated gas", "Error"}, rows)
+	if !ctx.Bool(ctlJSONFlag.Name) {
+		fmt.Printf("Calibration target: %.2f Mgas/s\n", target)
+	}
+	return nil
+}
+
+// benchPrecompile measures the execution time of a precompile for an input.
+func benchPrecompile(addr common.Address, p vm.PrecompiledContract, input precompileInput) *PrecompileBench {
+	res := &PrecompileBench{
+		Address: addr,
+		Name:    precompileName(addr),
+		Input:   input.name,
+		Size:    len(input.input),
+		Gas:     p.RequiredGas(input.input),
+	}
+	if _, err := p.Run(input.input); err != nil {
+		res.Error = err.Error()
+	}
+	bench := testing.Benchmark(func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			p.Run(input.input)
+		}
+	})
+	res.NsPerOp = bench.NsPerOp()
+	if res.NsPerOp > 0 {
+		res.MgasPerSec = float64(res.Gas) * 1000 / float64(res.NsPerOp)
+	}
+	return res
+}
+
+// calibratePrecompiles sets the gas cost each benchmarked input would have if
+// it executed at the target throughput, given in Mgas/s.
+func calibratePrecompiles(results []*PrecompileBench, target float64) {
+	for _, res := range results {
+		res.Calibrated = uint64(math.Ceil(float64(res.NsPerOp) * target / 1000))
+	}
+}
+
+// precompileName returns the name of a precompile, or its address if unknown.
+func precompileName(addr common.Address) string {
+	if name, ok := precompileNames[addr]; ok {
+		return name
+	}
+	return addr.Hex()
+}
+
+// precompileInputs generates representative, valid inputs for the precompile at
+// the given address. Unknown precompiles have no inputs.
+func precompileInputs(addr common.Address) ([]precompileInput, error) {
+	switch precompileNames[addr] {
+	case "ecrecover":
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		hash := crypto.Keccak256([]byte("precompile benchmark"))
+		sig, err := crypto.Sign(hash, key)
+		if err != nil {
+			return nil, err
+		}
+		input := make([]byte, 128)
+		copy(input, hash)
+		input[63] = sig[64] + 27
+		copy(input[64:], sig[:64])
+		return []precompileInput{{"valid signature", input}}, nil
+
+	case "sha256", "ripemd160", "identity":
+		var inputs []precompileInput
+		for _, size := range []int{32, 128, 1024, 8192} {
+			inputs = append(inputs, precompileInput{fmt.Sprintf("%d bytes", size), benchBytes(size)})
+		}
+		return inputs, nil
+
+	case "modexp":
+		var inputs []precompileInput
+		for _, size := range []int{32, 64, 128, 256, 512} {
+			input := make([]byte, 96+3*size)
+			for i := 0; i < 3; i++ {
+				binary.BigEndian.PutUint64(input[i*32+24:], uint64(size))
+			}
+			copy(input[96:], benchBytes(3*size))
+			input[len(input)-1] |= 1 // odd modulus
+			inputs = append(inputs, precompileInput{fmt.Sprintf("%d bit", size*8), input})
+		}
+		return inputs, nil
+
+	case "bn256Add":
+		a := new(bn256.G1).ScalarBaseMult(big.NewInt(3))
+		b := new(bn256.G1).ScalarBaseMult(big.NewInt(5))
+		return []precompileInput{{"two points", append(a.Marshal(), b.Marshal()...)}}, nil
+
+	case "bn256ScalarMul":
+		p := new(bn256.G1).ScalarBaseMult(big.NewInt(3))
+		scalar := new(big.Int).SetBytes(crypto.Keccak256([]byte("scalar")))
+		return []precompileInput{{"256 bit scalar", append(p.Marshal(), common.LeftPadBytes(scalar.Bytes(), 32)...)}}, nil
+
+	case "bn256Pairing":
+		var inputs []precompileInput
+		for _, pairs := range []int{1, 2, 4, 8} {
+			var input []byte
+			for i := 0; i < pairs; i++ {
+				input = append(input, new(bn256.G1).ScalarBaseMult(big.NewInt(int64(i+2))).Marshal()...)
+				input = append(input, new(bn256.G2).ScalarBaseMult(big.NewInt(int64(i+3))).Marshal()...)
+			}
+			inputs = append(inputs, precompileInput{fmt.Sprintf("%d pairs", pairs), input})
+		}
+		return inputs, nil
+
+	case "blake2F":
+		var inputs []precompileInput
+		for _, rounds := range []uint32{12, 1024} {
+			input := benchBytes(213)
+			binary.BigEndian.PutUint32(input, rounds)
+			input[212] = 1
+			inputs = append(inputs, precompileInput{fmt.Sprintf("%d rounds", rounds), input})
+		}
+		return inputs, nil
+
+	case "kzgPointEvaluation":
+		var (
+			blob  kzg4844.Blob
+			point kzg4844.Point
+		)
+		commitment, err := kzg4844.BlobToCommitment(blob)
+		if err != nil {
+			return nil, err
+		}
+		proof, claim, err := kzg4844.ComputeProof(blob, point)
+		if err != nil {
+			return nil, err
+		}
+		vhash := kzg4844.CalcBlobHashV1(sha256.New(), &commitment)
+
+		input := append(vhash[:], point[:]...)
+		input = append(input, claim[:]...)
+		input = append(input, commitment[:]...)
+		input = append(input, proof[:]...)
+		return []precompileInput{{"valid proof", input}}, nil
+	}
+	return nil, nil
+}
+
+// benchBytes returns deterministic pseudo-random bytes of the given size.
+func benchBytes(size int) []byte {
+	var (
+		out  = make([]byte, 0, size+32)
+		seed = crypto.Keccak256([]byte("precompile benchmark"))
+	)
+	for len(out) < size {
+		seed = crypto.Keccak256(seed)
+		out = append(out, seed...)
+	}
+	return out[:size]
+}
@@ -0,0 +1,66 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the generated benchmark inputs are accepted by the precompiles, so
+// that the measurements cover the actual computation instead of error paths.
+func TestPrecompileInputs(t *testing.T) {
+	timestamp := uint64(0)
+	precompiles := vm.PrecompiledContractsForConfig(params.MergedTestChainConfig, big.NewInt(math.MaxInt64), &timestamp)
+
+	for addr, name := range precompileNames {
+		p, ok := precompiles[addr]
+		if !ok {
+			t.Fatalf("%s: precompile not active", name)
+		}
+		inputs, err := precompileInputs(addr)
+		if err != nil {
+			t.Fatalf("%s: failed to generate inputs: %v", name, err)
+		}
+		if len(inputs) == 0 {
+			t.Fatalf("%s: no inputs", name)
+		}
+		for _, input := range inputs {
+			if _, err := p.Run(input.input); err != nil {
+				t.Errorf("%s (%s): input rejected: %v", name, input.name, err)
+			}
+		}
+	}
+}
+
+func TestCalibratePrecompiles(t *testing.T) {
+	results := []*PrecompileBench{
+		{Name: "ecrecover", Gas: 3000, NsPerOp: 50000},
+		{Name: "sha256", Gas: 72, NsPerOp: 1000},
+	}
+	calibratePrecompiles(results, 60) // 60 Mgas/s == 0.06 gas/ns
+	if results[0].Calibrated != 3000 {
+		t.Errorf("ecrecover calibration mismatch: have %d, want 3000", results[0].Calibrated)
+	}
+	if results[1].Calibrated != 60 {
+		t.Errorf("sha256 calibration mismatch: have %d, want 60", results[1].Calibrated)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -44,3 +45,35 @@ func TestExport(t *testing.T) {
 		t.Fatalf("wrong content exported")
 	}
 }
+
+// TestExportSinceLast tests that incremental exports only write the blocks added
+// since the previous one.
+func TestExportSinceLast(t *testing.T) {
+	t.Parallel()
+	var (
+		datadir = initGeth(t)
+		outdir  = t.TempDir()
+		cursor  = filepath.Join(outdir, "cursor")
+	)
+	geth := runGeth(t, "--datadir", datadir, "export", "--since-last", "--cursor", cursor, filepath.Join(outdir, "first.out"))
+	geth.WaitExit()
+	if have, want := geth.ExitStatus(), 0; have != want {
+		t.Fatalf("exit error, have %d want %d", have, want)
+	}
+	if _, err := os.Stat(filepath.Join(outdir, "first.out")); err != nil {
+		t.Fatalf("first export missing: %v", err)
+	}
+	last, err := readExportCursor(cursor)
+	if err != nil || last == nil || last.Number != 0 {
+		t.Fatalf("cursor mismatch: %v, %v", last, err)
+	}
+	// No blocks were added since, nothing to export
+	geth = runGeth(t, "--datadir", datadir, "export", "--since-last", "--cursor", cursor, filepath.Join(outdir, "second.out"))
+	geth.WaitExit()
+	if have, want := geth.ExitStatus(), 0; have != want {
+		t.Fatalf("exit error, have %d want %d", have, want)
+	}
+	if _, err := os.Stat(filepath.Join(outdir, "second.out")); !os.IsNotExist(err) {
+		t.Fatalf("empty incremental export written: %v", err)
+	}
+}
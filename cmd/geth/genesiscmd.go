@@ -0,0 +1,258 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params/confp"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/holiman/uint256"
+	"github.com/urfave/cli/v2"
+)
+
+var verifyGenesisCommand = &cli.Command{
+	Action:    verifyGenesis,
+	Name:      "verify-genesis",
+	Usage:     "Verify the stored genesis against a genesis specification",
+	ArgsUsage: "<genesisPath or URL>",
+	Flags: flags.Merge([]cli.Flag{
+		utils.GenesisSHA256Flag,
+		compareLimitFlag,
+	}, utils.DatabaseFlags),
+	Description: `
+    geth verify-genesis genesis.json
+
+Compares the genesis specification with the genesis block, chain configuration
+and allocations stored in the database, and reports every mismatch. This is meant
+for validating that a private network was initialized with the intended genesis.
+If the genesis state was pruned from the database, the allocations are compared
+with the genesis specification stored at initialization instead. The command
+exits with an error if any mismatch was found.`,
+}
+
+func verifyGenesis(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("need genesis.json file as the only argument")
+	}
+	genesis, err := utils.ReadGenesis(ctx.Args().First(), ctx.String(utils.GenesisSHA256Flag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to read genesis file: %v", err)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chaindb := utils.MakeChainDatabase(ctx, stack, true)
+	defer chaindb.Close()
+
+	triedb := utils.MakeTrieDatabase(ctx, chaindb, true, true, genesis)
+	defer triedb.Close()
+
+	var (
+		limit = ctx.Int(compareLimitFlag.Name)
+		diffs int
+	)
+	err = verifyGenesisSpec(chaindb, triedb, genesis, func(diff string) error {
+		fmt.Println(diff)
+		if diffs++; limit > 0 && diffs >= limit {
+			return errDiffLimit
+		}
+		return nil
+	})
+	if err != nil && err != errDiffLimit {
+		utils.Fatalf("Verification failed: %v", err)
+	}
+	if diffs > 0 {
+		utils.Fatalf("Stored genesis does not match the specification (%d mismatches, complete: %v)", diffs, err == nil)
+	}
+	log.Info("Stored genesis matches the specification", "hash", rawdb.ReadCanonicalHash(chaindb, 0))
+	return nil
+}
+
+// verifyGenesisSpec reports all differences between a genesis specification and
+// the genesis stored in the database: the block header, the chain configuration
+// and the allocated state. Reporting stops with errDiffLimit once report returns
+// it.
+func verifyGenesisSpec(db ethdb.Database, tdb *triedb.Database, genesis *genesisT.Genesis, report func(string) error) error {
+	if genesis.Config == nil {
+		return errors.New("genesis specification has no chain configuration")
+	}
+	hash := rawdb.ReadCanonicalHash(db, 0)
+	if hash == (common.Hash{}) {
+		return errors.New("database is not initialized")
+	}
+	stored := rawdb.ReadHeader(db, hash, 0)
+	if stored == nil {
+		return fmt.Errorf("genesis header %x missing", hash)
+	}
+	// Compare the header fields, which also covers the state root
+	spec := core.GenesisToBlock(genesis, nil).Header()
+	if spec.Hash() != hash {
+		if err := report(fmt.Sprintf("header: hash %x (spec) != %x (database)", spec.Hash(), hash)); err != nil {
+			return err
+		}
+	}
+	for _, field := range []struct {
+		name       string
+		spec, have interface{}
+	}{
+		{"parent hash", spec.ParentHash, stored.ParentHash},
+		{"coinbase", spec.Coinbase, stored.Coinbase},
+		{"state root", spec.Root, stored.Root},
+		{"difficulty", spec.Difficulty, stored.Difficulty},
+		{"gas limit", spec.GasLimit, stored.GasLimit},
+		{"timestamp", spec.Time, stored.Time},
+		{"extra data", fmt.Sprintf("%x", spec.Extra), fmt.Sprintf("%x", stored.Extra)},
+		{"mix digest", spec.MixDigest, stored.MixDigest},
+		{"nonce", spec.Nonce.Uint64(), stored.Nonce.Uint64()},
+		{"base fee", spec.BaseFee, stored.BaseFee},
+		{"withdrawals hash", spec.WithdrawalsHash, stored.WithdrawalsHash},
+		{"excess blob gas", spec.ExcessBlobGas, stored.ExcessBlobGas},
+		{"blob gas used", spec.BlobGasUsed, stored.BlobGasUsed},
+		{"parent beacon root", spec.ParentBeaconRoot, stored.ParentBeaconRoot},
+	} {
+		if a, b := genesisValue(field.spec), genesisValue(field.have); a != b {
+			if err := report(fmt.Sprintf("header: %s %s (spec) != %s (database)", field.name, a, b)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := verifyGenesisConfig(genesis.Config, rawdb.ReadChainConfig(db, hash), report); err != nil {
+		return err
+	}
+	if spec.Root == stored.Root {
+		return nil
+	}
+	// Compare the allocations with the stored state, or with the stored genesis
+	// specification if the state is no longer available
+	want, err := allocStateSource("spec", genesis.Alloc)
+	if err != nil {
+		return err
+	}
+	var have stateSource = &localStateSource{label: "database", root: stored.Root, triedb: tdb}
+	if _, err := trie.NewStateTrie(trie.StateTrieID(stored.Root), tdb); err != nil {
+		blob := rawdb.ReadGenesisStateSpec(db, hash)
+		if len(blob) == 0 {
+			return report(fmt.Sprintf("alloc: genesis state %x and allocation spec missing from database", stored.Root))
+		}
+		var alloc genesisT.GenesisAlloc
+		if err := alloc.UnmarshalJSON(blob); err != nil {
+			return fmt.Errorf("invalid stored allocation spec: %v", err)
+		}
+		log.Warn("Genesis state missing, comparing the stored allocation spec", "root", stored.Root)
+		if have, err = allocStateSource("database", alloc); err != nil {
+			return err
+		}
+	}
+	return compareStates(want, have, true, report)
+}
+
+// verifyGenesisConfig reports the differences between the chain configuration of
+// a genesis specification and the stored one.
+func verifyGenesisConfig(spec, stored ctypes.ChainConfigurator, report func(string) error) error {
+	if stored == nil {
+		return report("config: missing from database")
+	}
+	for _, field := range []struct {
+		name       string
+		spec, have interface{}
+	}{
+		{"network id", spec.GetNetworkID(), stored.GetNetworkID()},
+		{"chain id", spec.GetChainID(), stored.GetChainID()},
+		{"consensus engine", spec.GetConsensusEngineType(), stored.GetConsensusEngineType()},
+	} {
+		if a, b := genesisValue(field.spec), genesisValue(field.have); a != b {
+			if err := report(fmt.Sprintf("config: %s %s (spec) != %s (database)", field.name, a, b)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, diff := range confp.Equal(reflect.TypeOf((*ctypes.ChainConfigurator)(nil)), spec, stored) {
+		if err := report(fmt.Sprintf("config: %s %s (spec) != %s (database)", diff.Field, genesisValue(diff.A), genesisValue(diff.B))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// genesisValue formats a reported value, dereferencing optional fields.
+func genesisValue(v interface{}) string {
+	if rv, ok := v.(reflect.Value); ok {
+		v = rv.Interface()
+	}
+	switch v := v.(type) {
+	case *uint64:
+		if v == nil {
+			return "unset"
+		}
+		return fmt.Sprint(*v)
+	case *big.Int:
+		if v == nil {
+			return "unset"
+		}
+		return v.String()
+	case *common.Hash:
+		if v == nil {
+			return "unset"
+		}
+		return v.Hex()
+	}
+	return fmt.Sprint(v)
+}
+
+// allocStateSource builds the state of genesis allocations in a temporary
+// in-memory database.
+func allocStateSource(label string, alloc genesisT.GenesisAlloc) (*localStateSource, error) {
+	var (
+		disk = rawdb.NewMemoryDatabase()
+		tdb  = triedb.NewDatabase(disk, &triedb.Config{Preimages: true})
+	)
+	statedb, err := state.New(types.EmptyRootHash, state.NewDatabaseWithNodeDB(disk, tdb), nil)
+	if err != nil {
+		return nil, err
+	}
+	for addr, account := range alloc {
+		if account.Balance != nil {
+			statedb.AddBalance(addr, uint256.MustFromBig(account.Balance))
+		}
+		statedb.SetCode(addr, account.Code)
+		statedb.SetNonce(addr, account.Nonce)
+		for key, value := range account.Storage {
+			statedb.SetState(addr, key, value)
+		}
+	}
+	root, err := statedb.Commit(0, false)
+	if err != nil {
+		return nil, err
+	}
+	return &localStateSource{label: label, root: root, triedb: tdb}, nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+	"github.com/ethereum/go-ethereum/triedb"
+)
+
+func TestVerifyGenesisSpec(t *testing.T) {
+	var (
+		a    = common.HexToAddress("0xa")
+		b    = common.HexToAddress("0xb")
+		disk = rawdb.NewMemoryDatabase()
+		tdb  = triedb.NewDatabase(disk, nil)
+	)
+	spec := func() *genesisT.Genesis {
+		return &genesisT.Genesis{
+			Config:   params.AllEthashProtocolChanges,
+			GasLimit: 8_000_000,
+			Alloc: genesisT.GenesisAlloc{
+				a: {Balance: big.NewInt(1)},
+				b: {Balance: big.NewInt(2), Storage: map[common.Hash]common.Hash{{1}: {1}}},
+			},
+		}
+	}
+	core.MustCommitGenesis(disk, tdb, spec())
+
+	verify := func(genesis *genesisT.Genesis) string {
+		var diffs []string
+		err := verifyGenesisSpec(disk, tdb, genesis, func(diff string) error {
+			diffs = append(diffs, diff)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("verification failed: %v", err)
+		}
+		return strings.Join(diffs, "\n")
+	}
+	if out := verify(spec()); out != "" {
+		t.Fatalf("unexpected mismatches for identical genesis:\n%s", out)
+	}
+	modified := spec()
+	modified.GasLimit = 9_000_000
+	modified.Alloc[a] = genesisT.GenesisAccount{Balance: big.NewInt(3)}
+	out := verify(modified)
+	for _, want := range []string{
+		"header: gas limit 9000000 (spec) != 8000000 (database)",
+		"header: state root",
+		a.Hex() + "): balance 3 (spec) != 1 (database)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing mismatch %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, b.Hex()) {
+		t.Errorf("unchanged account reported:\n%s", out)
+	}
+}
@@ -83,6 +83,8 @@ var (
 		utils.TxPoolNoLocalsFlag,
 		utils.TxPoolJournalFlag,
 		utils.TxPoolRejournalFlag,
+		utils.TxPoolPersistFlag,
+		utils.TxPoolPersistFileFlag,
 		utils.TxPoolPriceLimitFlag,
 		utils.TxPoolPriceBumpFlag,
 		utils.TxPoolAccountSlotsFlag,
@@ -90,6 +92,12 @@ var (
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPoolQueuePoliciesFlag,
+		utils.TxPoolRejectUnprotectedFlag,
+		utils.TxPoolNoGossipFlag,
+		utils.TxPoolGossipHashesOnlyFlag,
+		utils.TxPoolGossipNoLocalsFlag,
+		utils.TxPoolPrivateRelaysFlag,
 		utils.BlobPoolDataDirFlag,
 		utils.BlobPoolDataCapFlag,
 		utils.BlobPoolPriceBumpFlag,
@@ -100,6 +108,9 @@ var (
 		utils.SnapshotFlag,
 		utils.TxLookupLimitFlag, // deprecated
 		utils.TransactionHistoryFlag,
+		utils.TxLookupAddressesFlag,
+		utils.HeaderAccumulatorFlag,
+		utils.LogIndexFlag,
 		utils.StateHistoryFlag,
 		utils.LightServeFlag,    // deprecated
 		utils.LightIngressFlag,  // deprecated
@@ -117,11 +128,19 @@ var (
 		utils.CacheFlag,
 		utils.CacheDatabaseFlag,
 		utils.CacheTrieFlag,
+		utils.CacheTrieWorkersFlag,
 		utils.CacheTrieJournalFlag,   // deprecated
 		utils.CacheTrieRejournalFlag, // deprecated
 		utils.CacheGCFlag,
 		utils.CacheSnapshotFlag,
+		utils.CacheAnalysisFlag,
+		utils.CacheAnalysisJournalFlag,
 		utils.CacheNoPrefetchFlag,
+		utils.ImportPrefetchFlag,
+		utils.ImportPrefetchWorkersFlag,
+		utils.ImportGovernorRPCLatencyFlag,
+		utils.ImportGovernorIOWaitFlag,
+		utils.ImportVerifyWorkersFlag,
 		utils.CachePreimagesFlag,
 		utils.CacheLogSizeFlag,
 		utils.FDLimitFlag,
@@ -130,6 +149,7 @@ var (
 		utils.DiscoveryPortFlag,
 		utils.MaxPeersFlag,
 		utils.MaxPendingPeersFlag,
+		utils.PinnedPeersFlag,
 		utils.MiningEnabledFlag,
 		utils.MinerThreadsFlag,
 		utils.MinerNotifyFlag,
@@ -140,6 +160,9 @@ var (
 		utils.MinerRecommitIntervalFlag,
 		utils.MinerNoVerifyFlag,
 		utils.MinerNewPayloadTimeout,
+		utils.MinerCandidatesFlag,
+		utils.MinerSpeculateFlag,
+		utils.MinerSponsoredFlag,
 		utils.NATFlag,
 		utils.NoDiscoverFlag,
 		utils.DiscoveryV4Flag,
@@ -156,7 +179,10 @@ var (
 		utils.DeveloperGasLimitFlag,
 		utils.VMEnableDebugFlag,
 		utils.NetworkIdFlag,
+		utils.GenesisURLFlag,
+		utils.GenesisSHA256Flag,
 		utils.EthStatsURLFlag,
+		utils.EventSinksFlag,
 		utils.FakePoWFlag,
 		utils.FakePoWPoissonFlag,
 		utils.NoCompactionFlag,
@@ -170,6 +196,10 @@ var (
 		utils.ECBP1100Flag,
 		utils.ECBP1100NoDisableFlag,
 		utils.OverrideECBP1100DeactivateFlag,
+		utils.MaxReorgDepthFlag,
+		utils.AttackRiskWebhookFlag,
+		utils.ClockDriftCheckFlag,
+		utils.ClockDriftAdjustFlag,
 		configFileFlag,
 		utils.LogDebugFlag,
 		utils.LogBacktraceAtFlag,
@@ -188,6 +218,13 @@ var (
 		utils.GraphQLEnabledFlag,
 		utils.GraphQLCORSDomainFlag,
 		utils.GraphQLVirtualHostsFlag,
+		utils.MetaRelayAccountFlag,
+		utils.MetaRelayForwarderFlag,
+		utils.MetaRelayMaxGasFlag,
+		utils.MetaRelayTargetsFlag,
+		utils.MetaRelaySenderLimitFlag,
+		utils.MetaRelayLimitFlag,
+		utils.MetaRelaySpendLimitFlag,
 		utils.HTTPApiFlag,
 		utils.HTTPPathPrefixFlag,
 		utils.WSEnabledFlag,
@@ -202,9 +239,19 @@ var (
 		utils.RPCGlobalGasCapFlag,
 		utils.RPCGlobalEVMTimeoutFlag,
 		utils.RPCGlobalTxFeeCapFlag,
+		utils.RPCServeWhileSyncingFlag,
+		utils.RPCCallCacheFlag,
+		utils.TraceFileDirFlag,
+		utils.TraceFileLimitFlag,
+		utils.TraceSignatureDBFlag,
 		utils.AllowUnprotectedTxs,
 		utils.BatchRequestLimit,
 		utils.BatchResponseMaxSize,
+		utils.RPCLenientParamsFlag,
+		utils.RPCSubscriptionLimitFlag,
+		utils.RPCFilterLimitFlag,
+		utils.RPCFilterTimeoutFlag,
+		utils.RPCFilterPendingTxTimeoutFlag,
 	}
 
 	metricsFlags = []cli.Flag{
@@ -222,6 +269,10 @@ var (
 		utils.MetricsInfluxDBTokenFlag,
 		utils.MetricsInfluxDBBucketFlag,
 		utils.MetricsInfluxDBOrganizationFlag,
+		utils.MetricsRemoteWriteEndpointFlag,
+		utils.MetricsRemoteWriteUsernameFlag,
+		utils.MetricsRemoteWritePasswordFlag,
+		utils.MetricsRemoteWriteLabelsFlag,
 	}
 )
 
@@ -237,10 +288,33 @@ func init() {
 		exportCommand,
 		importHistoryCommand,
 		exportHistoryCommand,
+		exportStatsCommand,
+		auditReplayCommand,
+		rollbackCommand,
 		importPreimagesCommand,
 		removedbCommand,
 		dumpCommand,
 		dumpGenesisCommand,
+		// See comparecmd.go:
+		compareStateCommand,
+		// See doctorcmd.go:
+		doctorCommand,
+		// See genesiscmd.go:
+		verifyGenesisCommand,
+		// See ceremonycmd.go:
+		ceremonyCommand,
+		// See monitorcmd.go:
+		monitorCommand,
+		// See ctlcmd.go:
+		ctlCommand,
+		// See backupcmd.go:
+		backupCommand,
+		// See txpoolcmd.go:
+		txpoolCommand,
+		// See replaycmd.go:
+		replayCommand,
+		// See evmcmd.go:
+		evmCommand,
 		// See accountcmd.go:
 		accountCommand,
 		walletCommand,
@@ -254,8 +328,16 @@ func init() {
 		versionCommand,
 		versionCheckCommand,
 		licenseCommand,
+		// See verifycmd.go:
+		verifyBinaryCommand,
 		// See config.go
 		dumpConfigCommand,
+		// See multichain.go
+		multichainCommand,
+		// See servicecmd.go
+		serviceCommand,
+		// See bootnodecmd.go
+		bootnodeCommand,
 		// see dbcmd.go
 		dbCommand,
 		// See cmd/utils/flags_legacy.go
@@ -276,6 +358,7 @@ func init() {
 		consoleFlags,
 		debug.Flags,
 		metricsFlags,
+		[]cli.Flag{chainsFlag},
 	)
 	flags.AutoEnvVars(app.Flags, "GETH")
 
@@ -296,6 +379,13 @@ func init() {
 }
 
 func main() {
+	if isService, err := runService(os.Args); isService || err != nil {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 	if err := app.Run(os.Args); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
@@ -359,6 +449,9 @@ func checkMainnet(ctx *cli.Context) bool {
 	case ctx.IsSet(utils.MintMeFlag.Name):
 		log.Info("Starting Geth on MintMe.com Coin mainnet...")
 
+	case ctx.IsSet(utils.GenesisURLFlag.Name):
+		log.Info("Starting Geth on custom genesis...", "genesis", ctx.String(utils.GenesisURLFlag.Name))
+
 	case !ctx.IsSet(utils.NetworkIdFlag.Name):
 		log.Info("Starting Geth on Ethereum mainnet...")
 		isMainnet = true
@@ -398,6 +491,10 @@ func geth(ctx *cli.Context) error {
 	if args := ctx.Args().Slice(); len(args) > 0 {
 		return fmt.Errorf("invalid command: %q", args[0])
 	}
+	// Run a node for each of the requested networks, see multichain.go.
+	if ctx.IsSet(chainsFlag.Name) {
+		return multichain(ctx)
+	}
 
 	prepare(ctx)
 	stack, backend := makeFullNode(ctx)
@@ -0,0 +1,308 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	monitorRefreshFlag = &cli.DurationFlag{
+		Name:  "refresh",
+		Usage: "Refresh interval of the dashboard",
+		Value: 3 * time.Second,
+	}
+	monitorHistoryFlag = &cli.IntFlag{
+		Name:  "history",
+		Usage: "Number of samples shown in the charts",
+		Value: 60,
+	}
+	monitorCommand = &cli.Command{
+		Action:    monitor,
+		Name:      "monitor",
+		Usage:     "Monitor and visualize node metrics in the terminal",
+		ArgsUsage: "[endpoint]",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.HttpHeaderFlag,
+			monitorRefreshFlag,
+			monitorHistoryFlag,
+		},
+		Description: `
+    geth monitor [--refresh 3s] [endpoint]
+
+Attaches to a running node, by default over the IPC endpoint of the data
+directory, and renders a live dashboard of its peer count, sync progress,
+transaction pool, block times, transactions per block and memory usage. Metrics
+whose RPC modules are not exposed by the endpoint are shown as unavailable.
+Press Ctrl-C to exit.`,
+	}
+)
+
+// monitorBlock is the part of a block the dashboard is interested in.
+type monitorBlock struct {
+	Hash         common.Hash    `json:"hash"`
+	Number       hexutil.Uint64 `json:"number"`
+	Time         hexutil.Uint64 `json:"timestamp"`
+	ParentHash   common.Hash    `json:"parentHash"`
+	GasUsed      hexutil.Uint64 `json:"gasUsed"`
+	GasLimit     hexutil.Uint64 `json:"gasLimit"`
+	Transactions []common.Hash  `json:"transactions"`
+}
+
+// monitorSample is a single snapshot of the monitored node. Metrics which could
+// not be retrieved are NaN.
+type monitorSample struct {
+	peers     float64
+	head      float64
+	highest   float64 // Highest block known during sync, NaN if not syncing
+	pending   float64
+	queued    float64
+	blockTime float64 // Seconds between the head block and its parent
+	txs       float64 // Transactions in the head block
+	gasUsage  float64 // Percentage of the gas limit used by the head block
+	heap      float64 // Allocated heap in MiB
+	gcs       float64 // Garbage collection cycles since the previous sample
+}
+
+// monitorChart is a chart of the dashboard, plotting one metric of the samples.
+type monitorChart struct {
+	title  string
+	unit   string
+	metric func(s *monitorSample) float64
+}
+
+var monitorCharts = []monitorChart{
+	{"Peers", "", func(s *monitorSample) float64 { return s.peers }},
+	{"Head block", "", func(s *monitorSample) float64 { return s.head }},
+	{"Blocks behind", "", func(s *monitorSample) float64 { return math.Max(s.highest-s.head, 0) }},
+	{"Pending txs", "", func(s *monitorSample) float64 { return s.pending }},
+	{"Queued txs", "", func(s *monitorSample) float64 { return s.queued }},
+	{"Block time", "s", func(s *monitorSample) float64 { return s.blockTime }},
+	{"Txs per block", "", func(s *monitorSample) float64 { return s.txs }},
+	{"Gas usage", "%", func(s *monitorSample) float64 { return s.gasUsage }},
+	{"Heap", "MiB", func(s *monitorSample) float64 { return s.heap }},
+	{"GC cycles", "", func(s *monitorSample) float64 { return s.gcs }},
+}
+
+// nodeMonitor samples the metrics of a node over RPC.
+type nodeMonitor struct {
+	client  *rpc.Client
+	head    monitorBlock // Last seen head block
+	numGC   uint32       // GC cycles at the previous sample
+	samples []*monitorSample
+	history int
+}
+
+func monitor(ctx *cli.Context) error {
+	if ctx.Args().Len() > 1 {
+		utils.Fatalf("invalid command-line: too many arguments")
+	}
+	endpoint := ctx.Args().First()
+	if endpoint == "" {
+		cfg := defaultNodeConfig()
+		utils.SetDataDir(ctx, &cfg)
+		endpoint = cfg.IPCEndpoint()
+	}
+	client, err := utils.DialRPCWithHeaders(endpoint, ctx.StringSlice(utils.HttpHeaderFlag.Name))
+	if err != nil {
+		utils.Fatalf("Unable to attach to remote geth: %v", err)
+	}
+	defer client.Close()
+
+	history := ctx.Int(monitorHistoryFlag.Name)
+	if history < 2 {
+		utils.Fatalf("Chart history must be at least 2 samples")
+	}
+	mon := &nodeMonitor{client: client, history: history}
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+
+	// Hide the cursor while rendering and restore it on exit
+	fmt.Print("\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\n")
+
+	ticker := time.NewTicker(ctx.Duration(monitorRefreshFlag.Name))
+	defer ticker.Stop()
+	for {
+		mon.sample()
+		fmt.Print("\x1b[H\x1b[2J")
+		mon.render(os.Stdout, endpoint)
+
+		select {
+		case <-ticker.C:
+		case <-sigc:
+			return nil
+		}
+	}
+}
+
+// sample retrieves the current metrics of the node and appends them to the
+// sample history.
+func (m *nodeMonitor) sample() {
+	var (
+		nan     = math.NaN()
+		s       = &monitorSample{peers: nan, head: nan, highest: nan, pending: nan, queued: nan, blockTime: nan, txs: nan, gasUsage: nan, heap: nan, gcs: nan}
+		peers   hexutil.Uint64
+		syncing json.RawMessage
+		head    monitorBlock
+		pool    struct{ Pending, Queued hexutil.Uint64 }
+		mem     runtime.MemStats
+	)
+	batch := []rpc.BatchElem{
+		{Method: "net_peerCount", Result: &peers},
+		{Method: "eth_syncing", Result: &syncing},
+		{Method: "eth_getBlockByNumber", Args: []interface{}{"latest", false}, Result: &head},
+		{Method: "txpool_status", Result: &pool},
+		{Method: "debug_memStats", Result: &mem},
+	}
+	if err := m.client.BatchCall(batch); err != nil {
+		m.push(s)
+		return
+	}
+	if batch[0].Error == nil {
+		s.peers = float64(peers)
+	}
+	if batch[2].Error == nil {
+		s.head = float64(head.Number)
+		if head.Hash != m.head.Hash && head.Number > 0 {
+			parent := m.head
+			if head.ParentHash != m.head.Hash {
+				if err := m.client.Call(&parent, "eth_getBlockByHash", head.ParentHash, false); err != nil {
+					parent.Time = head.Time
+				}
+			}
+			s.blockTime = float64(head.Time) - float64(parent.Time)
+		} else if len(m.samples) > 0 {
+			s.blockTime = m.samples[len(m.samples)-1].blockTime
+		}
+		s.txs = float64(len(head.Transactions))
+		if head.GasLimit > 0 {
+			s.gasUsage = 100 * float64(head.GasUsed) / float64(head.GasLimit)
+		}
+		m.head = head
+	}
+	if batch[1].Error == nil {
+		var progress struct{ HighestBlock hexutil.Uint64 }
+		if string(syncing) == "false" {
+			s.highest = s.head
+		} else if json.Unmarshal(syncing, &progress) == nil {
+			s.highest = float64(progress.HighestBlock)
+		}
+	}
+	if batch[3].Error == nil {
+		s.pending, s.queued = float64(pool.Pending), float64(pool.Queued)
+	}
+	if batch[4].Error == nil {
+		s.heap = float64(mem.HeapAlloc) / 1024 / 1024
+		if m.numGC != 0 {
+			s.gcs = float64(mem.NumGC - m.numGC)
+		}
+		m.numGC = mem.NumGC
+	}
+	m.push(s)
+}
+
+// push appends a sample to the history, dropping the oldest one if full.
+func (m *nodeMonitor) push(s *monitorSample) {
+	m.samples = append(m.samples, s)
+	if len(m.samples) > m.history {
+		m.samples = m.samples[len(m.samples)-m.history:]
+	}
+}
+
+// render draws the dashboard of the sampled metrics.
+func (m *nodeMonitor) render(w io.Writer, endpoint string) {
+	fmt.Fprintf(w, "geth monitor - %s - %s\n\n", endpoint, time.Now().Format(time.DateTime))
+	for _, chart := range monitorCharts {
+		values := make([]float64, len(m.samples))
+		for i, s := range m.samples {
+			values[i] = chart.metric(s)
+		}
+		current := "n/a"
+		if v := values[len(values)-1]; !math.IsNaN(v) {
+			current = strings.TrimSpace(fmt.Sprintf("%.6g %s", v, chart.unit))
+		}
+		lo, hi := sparkRange(values)
+		bounds := ""
+		if !math.IsNaN(lo) {
+			bounds = fmt.Sprintf("%.6g..%.6g", lo, hi)
+		}
+		fmt.Fprintf(w, "%-14s %-14s %-*s %s\n", chart.title, current, m.history, sparkline(values, m.history), bounds)
+	}
+}
+
+// sparkBlocks are the glyphs used to plot values, from lowest to highest.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline plots the values as a single line of block glyphs, right aligned
+// to the given width. Missing (NaN) values are plotted as blanks.
+func sparkline(values []float64, width int) string {
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+	lo, hi := sparkRange(values)
+
+	var b strings.Builder
+	b.WriteString(strings.Repeat(" ", width-len(values)))
+	for _, v := range values {
+		switch {
+		case math.IsNaN(v):
+			b.WriteRune(' ')
+		case hi == lo:
+			b.WriteRune(sparkBlocks[0])
+		default:
+			b.WriteRune(sparkBlocks[int((v-lo)/(hi-lo)*float64(len(sparkBlocks)-1)+0.5)])
+		}
+	}
+	return b.String()
+}
+
+// sparkRange returns the minimum and maximum of the non-NaN values, or NaNs if
+// there are none.
+func sparkRange(values []float64) (lo, hi float64) {
+	lo, hi = math.NaN(), math.NaN()
+	for _, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+		if math.IsNaN(lo) || v < lo {
+			lo = v
+		}
+		if math.IsNaN(hi) || v > hi {
+			hi = v
+		}
+	}
+	return lo, hi
+}
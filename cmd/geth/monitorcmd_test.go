@@ -0,0 +1,95 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestSparkline(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		values []float64
+		width  int
+		want   string
+	}{
+		{nil, 3, "   "},
+		{[]float64{1, 1}, 3, " ▁▁"},
+		{[]float64{0, 7, nan, 3.5}, 4, "▁█ ▅"},
+		{[]float64{5, 0, 7}, 2, "▁█"},
+	}
+	for _, test := range tests {
+		if have := sparkline(test.values, test.width); have != test.want {
+			t.Errorf("sparkline(%v, %d): have %q, want %q", test.values, test.width, have, test.want)
+		}
+	}
+}
+
+type monitorTestNet struct{}
+
+func (monitorTestNet) PeerCount() hexutil.Uint { return 7 }
+
+type monitorTestEth struct{ blocks map[common.Hash]*monitorBlock }
+
+func (monitorTestEth) Syncing() interface{} {
+	return map[string]hexutil.Uint64{"highestBlock": 15}
+}
+
+func (api monitorTestEth) GetBlockByNumber(number string, full bool) *monitorBlock {
+	return api.blocks[common.Hash{2}]
+}
+
+func (api monitorTestEth) GetBlockByHash(hash common.Hash, full bool) *monitorBlock {
+	return api.blocks[hash]
+}
+
+func TestMonitorSample(t *testing.T) {
+	server := rpc.NewServer()
+	defer server.Stop()
+
+	blocks := map[common.Hash]*monitorBlock{
+		{1}: {Hash: common.Hash{1}, Number: 9, Time: 100},
+		{2}: {Hash: common.Hash{2}, Number: 10, Time: 112, ParentHash: common.Hash{1}, GasUsed: 25, GasLimit: 100, Transactions: []common.Hash{{3}, {4}}},
+	}
+	server.RegisterName("net", monitorTestNet{})
+	server.RegisterName("eth", monitorTestEth{blocks})
+
+	mon := &nodeMonitor{client: rpc.DialInProc(server), history: 10}
+	mon.sample()
+
+	s := mon.samples[0]
+	if s.peers != 7 || s.head != 10 || s.highest != 15 || s.blockTime != 12 || s.txs != 2 || s.gasUsage != 25 {
+		t.Fatalf("sample mismatch: %+v", *s)
+	}
+	if !math.IsNaN(s.pending) || !math.IsNaN(s.heap) {
+		t.Fatalf("unavailable metrics not NaN: %+v", *s)
+	}
+	var out bytes.Buffer
+	mon.render(&out, "test")
+	for _, want := range []string{"Peers          7", "Blocks behind  5", "Block time     12 s", "Pending txs    n/a"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("missing %q in dashboard:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	chainsFlag = &cli.StringFlag{
+		Name:     "chains",
+		Usage:    "Comma separated list of networks to run in a single process (e.g. classic,mordor)",
+		Value:    "classic,mordor",
+		Category: flags.EthCategory,
+	}
+	multichainCommand = &cli.Command{
+		Action:    multichain,
+		Name:      "multichain",
+		Usage:     "Run several networks in a single process",
+		ArgsUsage: " ",
+		Flags:     flags.Merge(nodeFlags, rpcFlags, []cli.Flag{chainsFlag}),
+		Description: `
+The multichain command runs a full node for each of the networks listed in
+--chains inside a single process, sharing the cache allowance between them.
+Running geth with --chains is equivalent to running this command.
+
+Every network gets its own data directory (a subdirectory named after the
+network), IPC endpoint and devp2p listener. The p2p, discovery, websocket and
+authenticated RPC ports are offset by the position of the network in the list,
+as devp2p networks can't share a listener.
+
+If HTTP-RPC is enabled, a single HTTP server is started on --http.addr and
+--http.port, serving each network under its own path, e.g. /classic and
+/mordor (below --http.rpcprefix if set).`,
+	}
+)
+
+// multichain runs a node for every requested network, waiting until all of
+// them are shut down.
+func multichain(ctx *cli.Context) error {
+	if args := ctx.Args().Slice(); len(args) > 0 {
+		return fmt.Errorf("invalid command: %q", args[0])
+	}
+	if utils.IsNetworkPreset(ctx) {
+		return errors.New("network flags can't be used with multichain, use --chains instead")
+	}
+	if ctx.IsSet(utils.IPCPathFlag.Name) {
+		return errors.New("--ipcpath can't be used with multichain")
+	}
+	chains, err := parseChains(ctx.String(chainsFlag.Name))
+	if err != nil {
+		return err
+	}
+	prepare(ctx)
+
+	var stacks []*node.Node
+	defer func() {
+		for _, stack := range stacks {
+			stack.Close()
+		}
+	}()
+	for i, chain := range chains {
+		chainCtx, err := chainContext(ctx, chain, i, len(chains))
+		if err != nil {
+			return err
+		}
+		log.Info("Starting chain", "chain", chain)
+		stack, backend := makeFullNode(chainCtx)
+		stacks = append(stacks, stack)
+		startNode(chainCtx, stack, backend, false)
+	}
+	if ctx.Bool(utils.HTTPEnabledFlag.Name) {
+		srv, err := startMultichainHTTP(ctx, chains, stacks)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			srv.Shutdown(shutdown)
+		}()
+	}
+	for _, stack := range stacks {
+		stack.Wait()
+	}
+	return nil
+}
+
+// parseChains validates the list of networks to run.
+func parseChains(list string) ([]string, error) {
+	known := make(map[string]bool)
+	for _, f := range utils.NetworkFlags {
+		known[f.Names()[0]] = true
+	}
+	var (
+		chains []string
+		seen   = make(map[string]bool)
+	)
+	for _, chain := range strings.Split(list, ",") {
+		chain = strings.ToLower(strings.TrimSpace(chain))
+		if chain == "" {
+			continue
+		}
+		if !known[chain] {
+			return nil, fmt.Errorf("unknown chain %q", chain)
+		}
+		if seen[chain] {
+			return nil, fmt.Errorf("duplicate chain %q", chain)
+		}
+		seen[chain] = true
+		chains = append(chains, chain)
+	}
+	if len(chains) == 0 {
+		return nil, errors.New("no chains specified")
+	}
+	return chains, nil
+}
+
+// chainContext derives the configuration context of a single network from the
+// command line context, overriding only the settings that must differ between
+// the networks. All other flags are looked up in the parent context.
+func chainContext(ctx *cli.Context, chain string, index, count int) (*cli.Context, error) {
+	offset := func(f *cli.IntFlag) string {
+		return strconv.Itoa(ctx.Int(f.Name) + index)
+	}
+	overrides := map[string]string{
+		chain:                      "true",
+		utils.HTTPEnabledFlag.Name: "false",
+		utils.CacheFlag.Name:       strconv.Itoa(ctx.Int(utils.CacheFlag.Name) / count),
+		utils.ListenPortFlag.Name:  offset(utils.ListenPortFlag),
+		utils.AuthPortFlag.Name:    offset(utils.AuthPortFlag),
+		utils.WSPortFlag.Name:      offset(utils.WSPortFlag),
+	}
+	if ctx.IsSet(utils.DiscoveryPortFlag.Name) {
+		overrides[utils.DiscoveryPortFlag.Name] = offset(utils.DiscoveryPortFlag)
+	}
+	if ctx.IsSet(utils.DataDirFlag.Name) {
+		overrides[utils.DataDirFlag.Name] = filepath.Join(ctx.String(utils.DataDirFlag.Name), chain)
+	}
+	set := flag.NewFlagSet(chain, flag.ContinueOnError)
+	for name, value := range overrides {
+		set.String(name, value, "")
+		if err := set.Set(name, value); err != nil {
+			return nil, fmt.Errorf("chain %s: %v", chain, err)
+		}
+	}
+	return cli.NewContext(ctx.App, set, ctx), nil
+}
+
+// startMultichainHTTP starts the HTTP server shared by all networks, serving
+// the JSON-RPC APIs of each network below its own path.
+func startMultichainHTTP(ctx *cli.Context, chains []string, stacks []*node.Node) (*http.Server, error) {
+	mux := http.NewServeMux()
+	for i, chain := range chains {
+		handler, err := stacks[i].HTTPHandler()
+		if err != nil {
+			return nil, fmt.Errorf("chain %s: %v", chain, err)
+		}
+		prefix := path.Join("/", ctx.String(utils.HTTPPathPrefixFlag.Name), chain)
+		mux.Handle(prefix, handler)
+		mux.Handle(prefix+"/", handler)
+	}
+	endpoint := net.JoinHostPort(ctx.String(utils.HTTPListenAddrFlag.Name), strconv.Itoa(ctx.Int(utils.HTTPPortFlag.Name)))
+	listener, err := net.Listen("tcp", endpoint)
+	if err != nil {
+		return nil, err
+	}
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: rpc.DefaultHTTPTimeouts.ReadHeaderTimeout,
+		ReadTimeout:       rpc.DefaultHTTPTimeouts.ReadTimeout,
+		WriteTimeout:      rpc.DefaultHTTPTimeouts.WriteTimeout,
+		IdleTimeout:       rpc.DefaultHTTPTimeouts.IdleTimeout,
+	}
+	go srv.Serve(listener)
+	log.Info("Multichain HTTP server started", "endpoint", listener.Addr(), "chains", strings.Join(chains, ","))
+	return srv, nil
+}
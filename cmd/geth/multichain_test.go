@@ -0,0 +1,65 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseChains(t *testing.T) {
+	tests := []struct {
+		list   string
+		chains []string
+		fail   bool
+	}{
+		{list: "classic,mordor", chains: []string{"classic", "mordor"}},
+		{list: " Mordor , classic,", chains: []string{"mordor", "classic"}},
+		{list: "classic", chains: []string{"classic"}},
+		{list: "", fail: true},
+		{list: "classic,unknown", fail: true},
+		{list: "classic,classic", fail: true},
+	}
+	for _, tt := range tests {
+		chains, err := parseChains(tt.list)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("list %q: expected error", tt.list)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("list %q: unexpected error: %v", tt.list, err)
+		} else if !reflect.DeepEqual(chains, tt.chains) {
+			t.Errorf("list %q: chains mismatch: have %v, want %v", tt.list, chains, tt.chains)
+		}
+	}
+}
+
+func TestChainsFlag(t *testing.T) {
+	t.Parallel()
+	// The --chains flag of the main command must select the multichain mode.
+	geth := runGeth(t, "--chains", "classic,unknown", "--datadir", t.TempDir())
+	geth.WaitExit()
+	if geth.ExitStatus() == 0 {
+		t.Fatal("geth with unknown chain exited successfully")
+	}
+	if have, want := geth.StderrText(), `unknown chain "unknown"`; !strings.Contains(have, want) {
+		t.Fatalf("wrong error output: %q, want %q", have, want)
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	replayFromFlag = &cli.Uint64Flag{
+		Name:     "from",
+		Usage:    "Number of the first block to replay",
+		Required: true,
+	}
+	replayToFlag = &cli.Uint64Flag{
+		Name:  "to",
+		Usage: "Number of the last block to replay (default = head)",
+	}
+	replayCompareFlag = &cli.BoolFlag{
+		Name:  "vm.compare",
+		Usage: "Execute every block through a second, candidate VM configuration and report divergences",
+	}
+	replayCompareEipsFlag = &cli.StringFlag{
+		Name:  "vm.compare.eips",
+		Usage: "Comma separated list of extra EIPs enabled in the candidate VM configuration",
+	}
+	replayCommand = &cli.Command{
+		Action:    replay,
+		Name:      "replay",
+		Usage:     "Re-execute a range of blocks and report divergences",
+		ArgsUsage: "",
+		Flags: flags.Merge([]cli.Flag{
+			replayFromFlag,
+			replayToFlag,
+			replayCompareFlag,
+			replayCompareEipsFlag,
+			utils.EVMInterpreterFlag,
+			utils.EWASMInterpreterFlag,
+			compareLimitFlag,
+			utils.CacheFlag,
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `
+    geth replay --from 14000000 --to 14001000
+    geth replay --from 14000000 --to 14001000 --vm.compare --vm.evm /path/to/evmc.so
+
+Re-executes the blocks of the given range on top of the state of their parent
+block and compares the resulting state root, receipt root and gas used with the
+canonical headers. With --vm.compare, every block is additionally executed with
+a candidate VM configuration, set up via --vm.evm, --vm.ewasm and
+--vm.compare.eips, and any divergence between the built-in baseline and the
+candidate is reported down to the first differing receipt. This is meant as a
+safety harness for EVM changes. The database is opened read-only and the state
+of every replayed block's parent must be available, e.g. on an archive node.`,
+	}
+)
+
+// replayResult is the outcome of executing a block with a VM configuration.
+type replayResult struct {
+	root        common.Hash
+	receiptHash common.Hash
+	gasUsed     uint64
+	receipts    types.Receipts // nil if the receipts are unknown
+	err         error          // processing error, if the block was rejected
+}
+
+// headerResult returns the outcome of a block as recorded in its header.
+func headerResult(header *types.Header) *replayResult {
+	return &replayResult{
+		root:        header.Root,
+		receiptHash: header.ReceiptHash,
+		gasUsed:     header.GasUsed,
+	}
+}
+
+func replay(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack, true)
+	defer db.Close()
+	defer chain.Stop()
+
+	from, to := ctx.Uint64(replayFromFlag.Name), chain.CurrentBlock().Number.Uint64()
+	if ctx.IsSet(replayToFlag.Name) {
+		to = ctx.Uint64(replayToFlag.Name)
+	}
+	if from == 0 || from > to {
+		utils.Fatalf("Invalid block range %d-%d", from, to)
+	}
+	configs := []vm.Config{{}}
+	if ctx.Bool(replayCompareFlag.Name) {
+		candidate, err := replayCandidateConfig(ctx)
+		if err != nil {
+			utils.Fatalf("Invalid candidate VM configuration: %v", err)
+		}
+		configs = append(configs, candidate)
+	}
+	var (
+		limit  = ctx.Int(compareLimitFlag.Name)
+		diffs  int
+		start  = time.Now()
+		logged = time.Now()
+	)
+	report := func(number uint64, diff string) error {
+		fmt.Printf("block %d: %s\n", number, diff)
+		if diffs++; limit > 0 && diffs >= limit {
+			return errDiffLimit
+		}
+		return nil
+	}
+	var err error
+	for number := from; number <= to && err == nil; number++ {
+		block := chain.GetBlockByNumber(number)
+		if block == nil {
+			utils.Fatalf("Block %d not found", number)
+		}
+		results := make([]*replayResult, len(configs))
+		for i, config := range configs {
+			if results[i], err = replayBlock(chain, block, config); err != nil {
+				utils.Fatalf("Failed to replay block %d: %v", number, err)
+			}
+		}
+		labels := []string{"chain", "baseline", "candidate"}
+		results = append([]*replayResult{headerResult(block.Header())}, results...)
+		for i := 1; i < len(results) && err == nil; i++ {
+			for _, diff := range compareReplay(results[i-1], results[i], labels[i-1], labels[i]) {
+				if err = report(number, diff); err != nil {
+					break
+				}
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Replaying blocks", "number", number, "to", to, "divergences", diffs, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if diffs > 0 {
+		utils.Fatalf("Replay diverged (%d divergences, complete: %v)", diffs, err == nil)
+	}
+	log.Info("Replay matched", "from", from, "to", to, "configs", len(configs), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// replayCandidateConfig assembles the candidate VM configuration from the flags,
+// loading any external interpreters.
+func replayCandidateConfig(ctx *cli.Context) (vm.Config, error) {
+	var config vm.Config
+	if ctx.IsSet(utils.EVMInterpreterFlag.Name) {
+		config.EVMInterpreter = ctx.String(utils.EVMInterpreterFlag.Name)
+		vm.InitEVMCEVM(config.EVMInterpreter)
+	}
+	if ctx.IsSet(utils.EWASMInterpreterFlag.Name) {
+		config.EWASMInterpreter = ctx.String(utils.EWASMInterpreterFlag.Name)
+		vm.InitEVMCEwasm(config.EWASMInterpreter)
+	}
+	if eips := ctx.String(replayCompareEipsFlag.Name); eips != "" {
+		for _, field := range strings.Split(eips, ",") {
+			eip, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil {
+				return vm.Config{}, fmt.Errorf("invalid EIP %q", field)
+			}
+			if !vm.ValidEip(eip) {
+				return vm.Config{}, fmt.Errorf("unsupported EIP %d", eip)
+			}
+			config.ExtraEips = append(config.ExtraEips, eip)
+		}
+	}
+	if config.EVMInterpreter == "" && config.EWASMInterpreter == "" && len(config.ExtraEips) == 0 {
+		return vm.Config{}, errors.New("candidate is identical to the baseline")
+	}
+	return config, nil
+}
+
+// replayBlock executes the block on top of its parent's state with the given VM
+// configuration. Errors are only returned if the block can't be executed at all,
+// a block rejected by the state processor is reported in the result.
+func replayBlock(chain *core.BlockChain, block *types.Block, config vm.Config) (*replayResult, error) {
+	parent := chain.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent %x not found", block.ParentHash())
+	}
+	statedb, err := chain.StateAt(parent.Root)
+	if err != nil {
+		return nil, fmt.Errorf("state of block %d unavailable: %v", parent.Number, err)
+	}
+	receipts, _, gasUsed, err := core.NewStateProcessor(chain.Config(), chain, chain.Engine()).Process(block, statedb, config)
+	if err != nil {
+		return &replayResult{err: err}, nil
+	}
+	chainConfig := chain.Config()
+	return &replayResult{
+		root:        statedb.IntermediateRoot(chainConfig.IsEnabled(chainConfig.GetEIP161dTransition, block.Number())),
+		receiptHash: types.DeriveSha(receipts, trie.NewStackTrie(nil)),
+		gasUsed:     gasUsed,
+		receipts:    receipts,
+	}, nil
+}
+
+// compareReplay returns the divergences between two outcomes of the same block.
+// Receipts are compared one by one to pinpoint the first differing transaction,
+// if they are known for both sides.
+func compareReplay(a, b *replayResult, labelA, labelB string) []string {
+	if a.err != nil || b.err != nil {
+		if fmt.Sprint(a.err) == fmt.Sprint(b.err) {
+			return nil
+		}
+		return []string{fmt.Sprintf("processing error %v (%s) != %v (%s)", a.err, labelA, b.err, labelB)}
+	}
+	var diffs []string
+	if a.root != b.root {
+		diffs = append(diffs, fmt.Sprintf("state root %x (%s) != %x (%s)", a.root, labelA, b.root, labelB))
+	}
+	if a.receiptHash != b.receiptHash {
+		diffs = append(diffs, fmt.Sprintf("receipt root %x (%s) != %x (%s)", a.receiptHash, labelA, b.receiptHash, labelB))
+	}
+	if a.gasUsed != b.gasUsed {
+		diffs = append(diffs, fmt.Sprintf("gas used %d (%s) != %d (%s)", a.gasUsed, labelA, b.gasUsed, labelB))
+	}
+	if a.receipts == nil || b.receipts == nil || len(diffs) == 0 {
+		return diffs
+	}
+	for i := 0; i < len(a.receipts) && i < len(b.receipts); i++ {
+		ra, rb := a.receipts[i], b.receipts[i]
+		switch {
+		case ra.Status != rb.Status:
+			return append(diffs, fmt.Sprintf("tx %d (%x): status %d (%s) != %d (%s)", i, ra.TxHash, ra.Status, labelA, rb.Status, labelB))
+		case ra.GasUsed != rb.GasUsed:
+			return append(diffs, fmt.Sprintf("tx %d (%x): gas used %d (%s) != %d (%s)", i, ra.TxHash, ra.GasUsed, labelA, rb.GasUsed, labelB))
+		case len(ra.Logs) != len(rb.Logs):
+			return append(diffs, fmt.Sprintf("tx %d (%x): %d logs (%s) != %d logs (%s)", i, ra.TxHash, len(ra.Logs), labelA, len(rb.Logs), labelB))
+		case ra.Bloom != rb.Bloom:
+			return append(diffs, fmt.Sprintf("tx %d (%x): log bloom differs", i, ra.TxHash))
+		}
+	}
+	return diffs
+}
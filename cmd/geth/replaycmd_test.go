@@ -0,0 +1,116 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+	"github.com/ethereum/go-ethereum/params/vars"
+)
+
+func TestReplayBlocks(t *testing.T) {
+	var (
+		key, _  = crypto.GenerateKey()
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		genesis = &genesisT.Genesis{
+			Config:  params.TestChainConfig,
+			Alloc:   genesisT.GenesisAlloc{address: {Balance: big.NewInt(1000000000000000000)}},
+			BaseFee: big.NewInt(vars.InitialBaseFee),
+		}
+		engine = ethash.NewFaker()
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, engine, 5, func(i int, gen *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), common.HexToAddress("0xdeadbeef"), big.NewInt(1000), vars.TxGas, big.NewInt(10*vars.InitialBaseFee), nil), types.HomesteadSigner{}, key)
+		gen.AddTx(tx)
+	})
+	cache := core.DefaultCacheConfigWithScheme(rawdb.HashScheme)
+	cache.TrieDirtyDisabled = true
+
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), cache, genesis, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	for _, block := range blocks {
+		baseline, err := replayBlock(chain, block, vm.Config{})
+		if err != nil {
+			t.Fatalf("block %d: replay failed: %v", block.NumberU64(), err)
+		}
+		if diffs := compareReplay(headerResult(block.Header()), baseline, "chain", "baseline"); len(diffs) != 0 {
+			t.Errorf("block %d: baseline diverged from chain: %v", block.NumberU64(), diffs)
+		}
+		candidate, err := replayBlock(chain, block, vm.Config{ExtraEips: []int{3855}})
+		if err != nil {
+			t.Fatalf("block %d: replay failed: %v", block.NumberU64(), err)
+		}
+		if diffs := compareReplay(baseline, candidate, "baseline", "candidate"); len(diffs) != 0 {
+			t.Errorf("block %d: candidate diverged: %v", block.NumberU64(), diffs)
+		}
+	}
+}
+
+func TestCompareReplay(t *testing.T) {
+	a := &replayResult{
+		root:        common.Hash{1},
+		receiptHash: common.Hash{2},
+		gasUsed:     42000,
+		receipts: types.Receipts{
+			{Status: types.ReceiptStatusSuccessful, GasUsed: 21000, TxHash: common.Hash{3}},
+			{Status: types.ReceiptStatusSuccessful, GasUsed: 21000, TxHash: common.Hash{4}},
+		},
+	}
+	b := &replayResult{
+		root:        common.Hash{5},
+		receiptHash: common.Hash{2},
+		gasUsed:     43000,
+		receipts: types.Receipts{
+			{Status: types.ReceiptStatusSuccessful, GasUsed: 21000, TxHash: common.Hash{3}},
+			{Status: types.ReceiptStatusFailed, GasUsed: 22000, TxHash: common.Hash{4}},
+		},
+	}
+	if diffs := compareReplay(a, a, "A", "B"); len(diffs) != 0 {
+		t.Fatalf("identical results diverged: %v", diffs)
+	}
+	diffs := compareReplay(a, b, "A", "B")
+	if len(diffs) != 3 {
+		t.Fatalf("divergence count mismatch: have %d, want 3: %v", len(diffs), diffs)
+	}
+	out := strings.Join(diffs, "\n")
+	for _, want := range []string{"state root", "gas used 42000 (A) != 43000 (B)", "tx 1 ", "status 1 (A) != 0 (B)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in report:\n%s", want, out)
+		}
+	}
+	failed := &replayResult{err: core.ErrNonceTooLow}
+	if diffs := compareReplay(a, failed, "A", "B"); len(diffs) != 1 || !strings.Contains(diffs[0], "processing error") {
+		t.Errorf("processing error not reported: %v", diffs)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	serviceNameFlag = &cli.StringFlag{
+		Name:  "service.name",
+		Usage: "Name of the Windows service",
+		Value: "geth",
+	}
+	serviceCommand = &cli.Command{
+		Name:  "service",
+		Usage: "Manage geth as a Windows service",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "install",
+				Usage:     "Install geth as a Windows service",
+				ArgsUsage: "[-- <geth flags>]",
+				Action:    installService,
+				Flags:     []cli.Flag{serviceNameFlag},
+				Description: `
+    geth service install -- --classic --http
+
+Registers the current geth executable as an automatically started Windows
+service. Any arguments following -- are passed to geth whenever the service is
+started. When the service is stopped, or the system shuts down, the node is
+shut down gracefully, closing all databases.`,
+			},
+			{
+				Name:   "uninstall",
+				Usage:  "Remove the geth Windows service",
+				Action: uninstallService,
+				Flags:  []cli.Flag{serviceNameFlag},
+			},
+			{
+				Name:   "start",
+				Usage:  "Start the geth Windows service",
+				Action: startService,
+				Flags:  []cli.Flag{serviceNameFlag},
+			},
+			{
+				Name:   "stop",
+				Usage:  "Stop the geth Windows service, waiting for the node to shut down",
+				Action: stopService,
+				Flags:  []cli.Flag{serviceNameFlag},
+			},
+		},
+	}
+)
@@ -0,0 +1,38 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"errors"
+
+	"github.com/urfave/cli/v2"
+)
+
+var errServiceUnsupported = errors.New("services are only supported on Windows, use the system service manager instead")
+
+func installService(ctx *cli.Context) error   { return errServiceUnsupported }
+func uninstallService(ctx *cli.Context) error { return errServiceUnsupported }
+func startService(ctx *cli.Context) error     { return errServiceUnsupported }
+func stopService(ctx *cli.Context) error      { return errServiceUnsupported }
+
+// runService reports that the process is never running as a Windows service.
+func runService(args []string) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,188 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// serviceStopTimeout is the time to wait for the node to shut down when the
+// service is stopped from the command line.
+const serviceStopTimeout = 5 * time.Minute
+
+// openService connects to the service manager and opens the named service.
+func openService(ctx *cli.Context) (*mgr.Mgr, *mgr.Service, error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to service manager: %v", err)
+	}
+	name := ctx.String(serviceNameFlag.Name)
+	s, err := m.OpenService(name)
+	if err != nil {
+		m.Disconnect()
+		return nil, nil, fmt.Errorf("failed to open service %s: %v", name, err)
+	}
+	return m, s, nil
+}
+
+func installService(ctx *cli.Context) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to service manager: %v", err)
+	}
+	defer m.Disconnect()
+
+	name := ctx.String(serviceNameFlag.Name)
+	if s, err := m.OpenService(name); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s already exists", name)
+	}
+	config := mgr.Config{
+		DisplayName: "Geth " + name,
+		Description: "Ethereum node",
+		StartType:   mgr.StartAutomatic,
+	}
+	s, err := m.CreateService(name, exe, config, ctx.Args().Slice()...)
+	if err != nil {
+		return fmt.Errorf("failed to create service %s: %v", name, err)
+	}
+	defer s.Close()
+
+	log.Info("Installed service", "name", name, "exe", exe, "args", ctx.Args().Slice())
+	return nil
+}
+
+func uninstallService(ctx *cli.Context) error {
+	m, s, err := openService(ctx)
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	defer s.Close()
+
+	if err := s.Delete(); err != nil {
+		return fmt.Errorf("failed to delete service: %v", err)
+	}
+	log.Info("Removed service", "name", s.Name)
+	return nil
+}
+
+func startService(ctx *cli.Context) error {
+	m, s, err := openService(ctx)
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	defer s.Close()
+
+	if err := s.Start(); err != nil {
+		return fmt.Errorf("failed to start service: %v", err)
+	}
+	log.Info("Started service", "name", s.Name)
+	return nil
+}
+
+func stopService(ctx *cli.Context) error {
+	m, s, err := openService(ctx)
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	defer s.Close()
+
+	status, err := s.Control(svc.Stop)
+	if err != nil {
+		return fmt.Errorf("failed to stop service: %v", err)
+	}
+	// Wait for the node to shut down, so databases are closed when we return
+	deadline := time.Now().Add(serviceStopTimeout)
+	for status.State != svc.Stopped {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for service to stop, state %d", status.State)
+		}
+		time.Sleep(500 * time.Millisecond)
+		if status, err = s.Query(); err != nil {
+			return fmt.Errorf("failed to query service: %v", err)
+		}
+	}
+	log.Info("Stopped service", "name", s.Name)
+	return nil
+}
+
+// gethService runs geth under the Windows service control manager, turning stop
+// and shutdown requests into a graceful node shutdown.
+type gethService struct {
+	args []string
+}
+
+// Execute implements svc.Handler.
+func (s *gethService) Execute(_ []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run(s.args)
+	}()
+	accepts := svc.AcceptStop | svc.AcceptShutdown
+	status <- svc.Status{State: svc.Running, Accepts: accepts}
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				log.Error("Geth service failed", "err", err)
+				return true, 1
+			}
+			return false, 0
+
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				status <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				utils.RequestShutdown()
+			}
+		}
+	}
+}
+
+// runService runs geth as a Windows service if the process was started by the
+// service control manager. It reports whether that was the case.
+func runService(args []string) (bool, error) {
+	isService, err := svc.IsWindowsService()
+	if err != nil || !isService {
+		return false, err
+	}
+	// The service name is ignored for services running in their own process
+	return true, svc.Run("", &gethService{args: args})
+}
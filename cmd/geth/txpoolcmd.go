@@ -0,0 +1,111 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+)
+
+var txpoolCommand = &cli.Command{
+	Name:  "txpool",
+	Usage: "Export and import the transaction pool of a running node",
+	Subcommands: []*cli.Command{
+		{
+			Action:    txpoolExport,
+			Name:      "export",
+			Usage:     "Export the pending and queued transactions of a running node",
+			ArgsUsage: "<file.json>",
+			Flags:     []cli.Flag{ctlEndpointFlag, utils.DataDirFlag, utils.HttpHeaderFlag},
+			Description: `
+    geth txpool export pool.json
+
+Writes the pending and queued transactions of a running node, over IPC by
+default, into a JSON file holding the raw transactions along with their sender,
+nonce and status. Use 'geth txpool import' to load them into another node.`,
+		},
+		{
+			Action:    txpoolImport,
+			Name:      "import",
+			Usage:     "Import exported transactions into a running node",
+			ArgsUsage: "<file.json>",
+			Flags:     []cli.Flag{ctlEndpointFlag, utils.DataDirFlag, utils.HttpHeaderFlag},
+			Description: `
+    geth txpool import pool.json
+
+Adds the transactions of a file written by 'geth txpool export' to the pool of a
+running node as local transactions. Transactions rejected by the pool, e.g.
+because they were already included in the chain, are reported.`,
+		},
+	},
+}
+
+func txpoolExport(ctx *cli.Context) error {
+	file := ctlArg(ctx)
+
+	var txs []*eth.PooledTransaction
+	ctlCall(ctx, &txs, "txpool_export")
+
+	blob, err := json.MarshalIndent(txs, "", "  ")
+	if err != nil {
+		utils.Fatalf("Failed to encode transactions: %v", err)
+	}
+	if err := os.WriteFile(file, blob, 0644); err != nil {
+		utils.Fatalf("Failed to write transactions: %v", err)
+	}
+	var queued int
+	for _, tx := range txs {
+		if tx.Queued {
+			queued++
+		}
+	}
+	log.Info("Exported transaction pool", "file", file, "pending", len(txs)-queued, "queued", queued)
+	return nil
+}
+
+func txpoolImport(ctx *cli.Context) error {
+	file := ctlArg(ctx)
+
+	blob, err := os.ReadFile(file)
+	if err != nil {
+		utils.Fatalf("Failed to read transactions: %v", err)
+	}
+	var txs []*eth.PooledTransaction
+	if err := json.Unmarshal(blob, &txs); err != nil {
+		utils.Fatalf("Invalid transaction file: %v", err)
+	}
+	var result eth.TxPoolImportResult
+	ctlCall(ctx, &result, "txpool_import", txs)
+
+	hashes := make([]common.Hash, 0, len(result.Errors))
+	for hash := range result.Errors {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i].Cmp(hashes[j]) < 0 })
+	for _, hash := range hashes {
+		log.Warn("Transaction rejected", "hash", hash, "err", result.Errors[hash])
+	}
+	log.Info("Imported transaction pool", "file", file, "imported", result.Imported, "rejected", len(result.Errors))
+	return nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/ethereum/go-ethereum/internal/version"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// releasePubKeys are the minisign public keys trusted to sign the build
+	// provenance and executables of release binaries. These are the release
+	// keys also signing the vulnerability feed checked by version-check.
+	releasePubKeys = gethPubKeys
+
+	VerifyBinaryKeyFlag = &cli.StringSliceFlag{
+		Name:  "key",
+		Usage: "Minisign public key trusted to sign the build provenance (replaces the release keys)",
+	}
+	VerifyBinarySignatureFlag = &cli.StringFlag{
+		Name:  "signature",
+		Usage: "Detached signature of the executable (default = <executable>.sig)",
+	}
+
+	verifyBinaryCommand = &cli.Command{
+		Action:    verifyBinary,
+		Name:      "verify-binary",
+		Usage:     "Verifies the signed build provenance of this binary",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			VerifyBinaryKeyFlag,
+			VerifyBinarySignatureFlag,
+		},
+		Description: `
+The verify-binary command checks the build provenance embedded in release
+binaries (builder, source commit, toolchain, platform and a checksum of the
+linked dependencies) against the release signing key, and compares it with the
+binary actually running. It fails for binaries built without provenance, e.g.
+from source, and for binaries not matching their signed provenance.
+
+The provenance is build metadata only, a modified executable may still carry
+it. Release archives therefore ship a detached signature of the executable as
+well (geth.sig next to geth), which is verified if present or given with
+--signature. Without it, only the metadata is checked.
+`,
+	}
+)
+
+func verifyBinary(ctx *cli.Context) error {
+	keys := releasePubKeys
+	if ctx.IsSet(VerifyBinaryKeyFlag.Name) {
+		keys = ctx.StringSlice(VerifyBinaryKeyFlag.Name)
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("no release signing key known, specify one with --%s", VerifyBinaryKeyFlag.Name)
+	}
+	payload, sig, err := version.EmbeddedProvenance()
+	if err != nil {
+		return err
+	}
+	signed, err := checkProvenance(keys, payload, sig)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Signature:  valid")
+	fmt.Println("Builder:   ", signed.Builder)
+	fmt.Println("Version:   ", signed.Version)
+	fmt.Println("Commit:    ", signed.Commit)
+	fmt.Println("Date:      ", signed.Date)
+	fmt.Println("Go Version:", signed.GoVersion)
+	fmt.Println("Platform:  ", signed.Platform)
+	fmt.Println("Modules:   ", signed.Modules)
+
+	actual, err := version.CurrentProvenance()
+	if err != nil {
+		return err
+	}
+	if diffs := signed.Mismatches(actual); len(diffs) > 0 {
+		for _, diff := range diffs {
+			fmt.Println("Mismatch:  ", diff)
+		}
+		return errors.New("binary does not match its signed provenance")
+	}
+	fmt.Println("Binary matches its signed provenance")
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	sigfile := exe + ".sig"
+	if ctx.IsSet(VerifyBinarySignatureFlag.Name) {
+		sigfile = ctx.String(VerifyBinarySignatureFlag.Name)
+	}
+	sigdata, err := os.ReadFile(sigfile)
+	if errors.Is(err, fs.ErrNotExist) && !ctx.IsSet(VerifyBinarySignatureFlag.Name) {
+		fmt.Println("Executable: not verified, no detached signature found")
+		fmt.Println("Only the build metadata was checked, the executable itself may be modified")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := checkExecutable(keys, exe, sigdata); err != nil {
+		return err
+	}
+	fmt.Println("Executable: signature valid")
+	return nil
+}
+
+// checkExecutable verifies the detached signature of the executable file
+// against the trusted keys.
+func checkExecutable(pubkeys []string, exe string, sig []byte) error {
+	data, err := os.ReadFile(exe)
+	if err != nil {
+		return err
+	}
+	if err := verifySignature(pubkeys, data, sig); err != nil {
+		return fmt.Errorf("invalid executable signature: %v", err)
+	}
+	return nil
+}
+
+// checkProvenance verifies the signature of the build provenance against the
+// trusted keys and decodes it.
+func checkProvenance(pubkeys []string, payload, sig []byte) (*version.Provenance, error) {
+	if err := verifySignature(pubkeys, payload, sig); err != nil {
+		return nil, fmt.Errorf("invalid provenance: %v", err)
+	}
+	p := new(version.Provenance)
+	if err := json.Unmarshal(payload, p); err != nil {
+		return nil, fmt.Errorf("invalid provenance: %v", err)
+	}
+	return p, nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto/signify"
+	"github.com/ethereum/go-ethereum/internal/version"
+)
+
+func TestCheckProvenance(t *testing.T) {
+	t.Parallel()
+	// Keys taken from the signify package tests.
+	var (
+		seckey = "RWRCSwAAAABVN5lr2JViGBN8DhX3/Qb/0g0wBdsNAR/APRW2qy9Fjsfr12sK2cd3URUFis1jgzQzaoayK8x4syT4G3Gvlt9RwGIwUYIQW/0mTeI+ECHu1lv5U4Wa2YHEPIesVPyRm5M="
+		pubkey = "RWTAPRW2qy9FjsBiMFGCEFv9Jk3iPhAh7tZb+VOFmtmBxDyHrFT8kZuT"
+		other  = "RWQkliYstQBOKOdtClfgC3IypIPX6TAmoEi7beZ4gyR3wsaezvqOMWsp"
+	)
+	want := &version.Provenance{
+		Builder:   "test build 1",
+		Version:   "1.2.3-stable",
+		Commit:    "0123456789abcdef0123456789abcdef01234567",
+		Date:      "20240101",
+		GoVersion: "go1.22.0",
+		Platform:  "linux/amd64",
+		Modules:   "00",
+	}
+	payload, _ := json.Marshal(want)
+	sig, err := signify.Sign(payload, seckey, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	have, err := checkProvenance([]string{other, pubkey}, payload, sig)
+	if err != nil {
+		t.Fatalf("valid provenance rejected: %v", err)
+	}
+	if *have != *want {
+		t.Fatalf("wrong provenance: have %+v, want %+v", have, want)
+	}
+	if diffs := have.Mismatches(want); len(diffs) != 0 {
+		t.Fatalf("unexpected mismatches: %v", diffs)
+	}
+	// Untrusted keys and tampered payloads must be rejected.
+	if _, err := checkProvenance([]string{other}, payload, sig); err == nil {
+		t.Fatal("provenance signed by untrusted key accepted")
+	}
+	tampered := *want
+	tampered.Commit = "fedcba9876543210fedcba9876543210fedcba98"
+	payload, _ = json.Marshal(&tampered)
+	if _, err := checkProvenance([]string{pubkey}, payload, sig); err == nil {
+		t.Fatal("tampered provenance accepted")
+	}
+	if diffs := want.Mismatches(&tampered); len(diffs) != 1 {
+		t.Fatalf("wrong mismatches: %v", diffs)
+	}
+}
+
+func TestCheckExecutable(t *testing.T) {
+	t.Parallel()
+	// Keys taken from the signify package tests.
+	var (
+		seckey = "RWRCSwAAAABVN5lr2JViGBN8DhX3/Qb/0g0wBdsNAR/APRW2qy9Fjsfr12sK2cd3URUFis1jgzQzaoayK8x4syT4G3Gvlt9RwGIwUYIQW/0mTeI+ECHu1lv5U4Wa2YHEPIesVPyRm5M="
+		pubkey = "RWTAPRW2qy9FjsBiMFGCEFv9Jk3iPhAh7tZb+VOFmtmBxDyHrFT8kZuT"
+		other  = "RWQkliYstQBOKOdtClfgC3IypIPX6TAmoEi7beZ4gyR3wsaezvqOMWsp"
+	)
+	exe := filepath.Join(t.TempDir(), "geth")
+	if err := os.WriteFile(exe, []byte("release executable"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := signify.SignFile(exe, exe+".sig", seckey, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	sig, err := os.ReadFile(exe + ".sig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkExecutable([]string{other, pubkey}, exe, sig); err != nil {
+		t.Fatalf("valid executable rejected: %v", err)
+	}
+	// Untrusted keys and modified executables must be rejected.
+	if err := checkExecutable([]string{other}, exe, sig); err == nil {
+		t.Fatal("executable signed by untrusted key accepted")
+	}
+	if err := os.WriteFile(exe, []byte("patched executable"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkExecutable([]string{pubkey}, exe, sig); err == nil {
+		t.Fatal("modified executable accepted")
+	}
+}
@@ -20,8 +20,11 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"compress/gzip"
 	"container/list"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -31,6 +34,7 @@ import (
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
@@ -40,15 +44,19 @@ var (
 	noASCII     = flag.Bool("noascii", false, "don't print ASCII strings readably")
 	single      = flag.Bool("single", false, "print only the first element, discard the rest")
 	showpos     = flag.Bool("pos", false, "display element byte posititions")
+	schema      = flag.String("schema", "", "decode values as the given structure (header, block, tx, receipt)")
+	rawMode     = flag.Bool("raw", false, "write binary rlp instead of hex in reverse mode")
 )
 
 func init() {
 	flag.Usage = func() {
-		fmt.Fprintln(os.Stderr, "Usage:", os.Args[0], "[-noascii] [-hex <data>][-reverse] [filename]")
+		fmt.Fprintln(os.Stderr, "Usage:", os.Args[0], "[-noascii] [-hex <data>][-reverse [-raw]] [-schema <type>] [filename]")
 		flag.PrintDefaults()
 		fmt.Fprintln(os.Stderr, `
 Dumps RLP data from the given file in readable form.
-If the filename is omitted, data is read from stdin.`)
+If the filename is omitted, data is read from stdin.
+Files ending in .gz are decompressed on the fly, and values are
+processed one by one, so large chain export files can be dumped.`)
 	}
 }
 
@@ -73,6 +81,15 @@ func main() {
 			die(err)
 		}
 		defer fd.Close()
+		if strings.HasSuffix(flag.Arg(0), ".gz") {
+			gz, err := gzip.NewReader(fd)
+			if err != nil {
+				die(err)
+			}
+			defer gz.Close()
+			r = newInStream(bufio.NewReader(gz), 0)
+			break
+		}
 		var size int64
 		finfo, err := fd.Stat()
 		if err == nil {
@@ -87,16 +104,23 @@ func main() {
 	}
 
 	out := os.Stdout
-	if *reverseMode {
+	switch {
+	case *reverseMode:
 		data, err := textToRlp(r)
 		if err != nil {
 			die(err)
 		}
-		fmt.Printf("%#x\n", data)
-		return
-	} else {
-		err := rlpToText(r, out)
-		if err != nil {
+		if *rawMode {
+			out.Write(data)
+		} else {
+			fmt.Printf("%#x\n", data)
+		}
+	case *schema != "":
+		if err := rlpToSchema(r, *schema, out); err != nil {
+			die(err)
+		}
+	default:
+		if err := rlpToText(r, out); err != nil {
 			die(err)
 		}
 	}
@@ -119,6 +143,63 @@ func rlpToText(in *inStream, out io.Writer) error {
 	return nil
 }
 
+// schemaDecoders creates the values known structures are decoded into. The
+// decoders handle the encodings of all forks, e.g. typed transactions and
+// receipts, and headers with optional fields.
+var schemaDecoders = map[string]func(s *rlp.Stream) (interface{}, error){
+	"header": func(s *rlp.Stream) (interface{}, error) {
+		header := new(types.Header)
+		return header, s.Decode(header)
+	},
+	"block": func(s *rlp.Stream) (interface{}, error) {
+		block := new(types.Block)
+		if err := s.Decode(block); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"header":       block.Header(),
+			"transactions": block.Transactions(),
+			"uncles":       block.Uncles(),
+			"withdrawals":  block.Withdrawals(),
+		}, nil
+	},
+	"tx": func(s *rlp.Stream) (interface{}, error) {
+		tx := new(types.Transaction)
+		return tx, s.Decode(tx)
+	},
+	"receipt": func(s *rlp.Stream) (interface{}, error) {
+		receipt := new(types.Receipt)
+		return receipt, s.Decode(receipt)
+	},
+}
+
+// rlpToSchema decodes the values in the input as the given structure and prints
+// them as JSON.
+func rlpToSchema(in *inStream, schema string, out io.Writer) error {
+	decode, ok := schemaDecoders[schema]
+	if !ok {
+		return fmt.Errorf("unknown schema %q", schema)
+	}
+	stream := rlp.NewStream(in, 0)
+	for {
+		pos := in.pos
+		v, err := decode(stream)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("value at offset %d: %v", pos, err)
+		}
+		enc, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%s\n", enc)
+		if *single {
+			return nil
+		}
+	}
+}
+
 func dump(in *inStream, s *rlp.Stream, depth int, out io.Writer) error {
 	if *showpos {
 		fmt.Fprintf(out, "%s: ", in.posLabel())
@@ -202,12 +283,12 @@ func textToRlp(r io.Reader) ([]byte, error) {
 		case "]", "],": // list end
 			parent := stack.Remove(stack.Front()).([]interface{})
 			obj = append(parent, obj)
-		case "[],": // empty list
+		case "[]", "[],": // empty list
 			obj = append(obj, make([]interface{}, 0))
 		default: // element
-			data := []byte(t)[:len(t)-1] // cut off comma
-			if data[0] == '"' {          // ascii string
-				data = []byte(t)[1 : len(data)-1]
+			data := []byte(strings.TrimSuffix(t, ",")) // cut off comma, if any
+			if data[0] == '"' {                        // ascii string
+				data = data[1 : len(data)-1]
 			} else { // hex data
 				data = common.FromHex(string(data))
 			}
@@ -217,8 +298,19 @@ func textToRlp(r io.Reader) ([]byte, error) {
 	if err := scanner.Err(); err != nil {
 		return nil, err
 	}
-	data, err := rlp.EncodeToBytes(obj[0])
-	return data, err
+	if len(obj) == 0 {
+		return nil, errors.New("no input")
+	}
+	// Encode all top-level values, so dumps of export files round-trip
+	var data []byte
+	for _, v := range obj {
+		enc, err := rlp.EncodeToBytes(v)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, enc...)
+	}
+	return data, nil
 }
 
 type inStream struct {
@@ -18,12 +18,16 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"strings"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 func TestRoundtrip(t *testing.T) {
@@ -32,6 +36,7 @@ func TestRoundtrip(t *testing.T) {
 		"0xf880806482520894d0d0d0d0d0d0d0d0d0d0d0d0d0d0d0d0d0d0d0d0a1010000000000000000000000000000000000000000000000000000000000000001801ba0c16787a8e25e941d67691954642876c08f00996163ae7dfadbbfd6cd436f549da06180e5626cae31590f40641fe8f63734316c4bfeb4cdfab6714198c1044d2e28",
 		"0xd5c0d3cb84746573742a2a808213378667617a6f6e6b",
 		"0xc780c0c1c0825208",
+		"0xc10183616263c0", // multiple top-level values
 	} {
 		var out strings.Builder
 		in := newInStream(bytes.NewReader(common.FromHex(want)), 0)
@@ -82,3 +87,44 @@ func TestTextToRlp(t *testing.T) {
 		}
 	}
 }
+
+func TestSchemaDecoding(t *testing.T) {
+	t.Parallel()
+	header := &types.Header{Number: big.NewInt(1920000), Difficulty: big.NewInt(131072), GasLimit: 4712388}
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(61), Nonce: 7, Gas: 21000, GasFeeCap: big.NewInt(1), GasTipCap: big.NewInt(1)})
+	encHeader, _ := rlp.EncodeToBytes(header)
+	encTx, _ := rlp.EncodeToBytes(tx)
+
+	for i, tc := range []struct {
+		schema string
+		data   []byte
+		want   []string // JSON fields expected in order
+	}{
+		{"header", append(encHeader, encHeader...), []string{header.Hash().Hex(), header.Hash().Hex()}},
+		{"tx", encTx, []string{tx.Hash().Hex()}},
+	} {
+		var out strings.Builder
+		in := newInStream(bytes.NewReader(tc.data), 0)
+		if err := rlpToSchema(in, tc.schema, &out); err != nil {
+			t.Fatalf("test %d: error %v", i, err)
+		}
+		dec := json.NewDecoder(strings.NewReader(out.String()))
+		for j, want := range tc.want {
+			var v struct {
+				Hash common.Hash `json:"hash"`
+			}
+			if err := dec.Decode(&v); err != nil {
+				t.Fatalf("test %d: value %d: error %v", i, j, err)
+			}
+			if v.Hash.Hex() != want {
+				t.Errorf("test %d: value %d: hash mismatch, have %v, want %v", i, j, v.Hash.Hex(), want)
+			}
+		}
+		if dec.More() {
+			t.Errorf("test %d: unexpected trailing values", i)
+		}
+	}
+	if err := rlpToSchema(newInStream(bytes.NewReader(encTx), 0), "account", new(strings.Builder)); err == nil {
+		t.Error("expected error for unknown schema")
+	}
+}
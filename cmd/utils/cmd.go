@@ -30,6 +30,7 @@ import (
 	"path"
 	"runtime"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -54,6 +55,18 @@ const (
 	importBatchSize = 2500
 )
 
+var (
+	shutdownRequested = make(chan struct{})
+	shutdownOnce      sync.Once
+)
+
+// RequestShutdown asks all nodes started by StartNode to shut down gracefully,
+// the same way as if the process received SIGTERM. It is meant for platforms
+// and environments where signals can't be delivered, e.g. Windows services.
+func RequestShutdown() {
+	shutdownOnce.Do(func() { close(shutdownRequested) })
+}
+
 // Fatalf formats a message to standard error and exits the program.
 // The message is also printed to standard output if standard error
 // is redirected to a different file.
@@ -92,6 +105,10 @@ func StartNode(ctx *cli.Context, stack *node.Node, isConsole bool) {
 		if minFreeDiskSpace > 0 {
 			go monitorFreeDiskSpace(sigc, stack.InstanceDir(), uint64(minFreeDiskSpace)*1024*1024)
 		}
+		go func() {
+			<-shutdownRequested
+			sigc <- syscall.SIGTERM
+		}()
 
 		shutdown := func() {
 			log.Info("Got interrupt, shutting down...")
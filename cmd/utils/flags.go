@@ -20,6 +20,8 @@ package utils
 import (
 	"context"
 	"crypto/ecdsa"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -40,6 +42,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/fdlimit"
+	"github.com/ethereum/go-ethereum/common/memlimit"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/consensus/lyra2"
 
@@ -59,13 +62,16 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/ethdb/remotedb"
 	"github.com/ethereum/go-ethereum/ethstats"
+	"github.com/ethereum/go-ethereum/eventsink"
 	"github.com/ethereum/go-ethereum/graphql"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metarelay"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/metrics/exp"
 	"github.com/ethereum/go-ethereum/metrics/influxdb"
+	"github.com/ethereum/go-ethereum/metrics/prometheus"
 	"github.com/ethereum/go-ethereum/miner"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/p2p"
@@ -120,6 +126,17 @@ var (
 		Usage:    "Root directory for ancient data (default = inside chaindata)",
 		Category: flags.EthCategory,
 	}
+	AncientCompressionFlag = &cli.StringFlag{
+		Name:     "datadir.ancient.compression",
+		Usage:    "Compression algorithm of newly created ancient tables (snappy, zstd); existing ones are converted with 'geth db compress-ancients'",
+		Value:    "snappy",
+		Category: flags.EthCategory,
+	}
+	DataDirOverlayFlag = &flags.DirectoryFlag{
+		Name:     "datadir.overlay",
+		Usage:    "Directory collecting all writes of the node, leaving the datadir untouched (copy-on-write shadow fork)",
+		Category: flags.EthCategory,
+	}
 	MinFreeDiskSpaceFlag = &flags.DirectoryFlag{
 		Name:     "datadir.minfreedisk",
 		Usage:    "Minimum free disk space in MB, once reached triggers auto shut down (default = --cache.gc converted to MB, 0 = disabled)",
@@ -151,6 +168,17 @@ var (
 		Value:    ethconfig.Defaults.NetworkId,
 		Category: flags.EthCategory,
 	}
+	GenesisURLFlag = &cli.StringFlag{
+		Name:     "genesis.url",
+		Usage:    "Path or http(s) URL of a genesis file to initialize the database with, if empty",
+		Category: flags.EthCategory,
+	}
+	GenesisSHA256Flag = &cli.StringFlag{
+		Name:     "genesis.sha256",
+		Aliases:  []string{"sha256"},
+		Usage:    "Expected SHA256 checksum (hex) of the genesis file",
+		Category: flags.EthCategory,
+	}
 	EthProtocolsFlag = &cli.StringFlag{
 		Name:     "eth.protocols",
 		Usage:    "Sets the Ethereum Protocol versions (first is primary)",
@@ -333,6 +361,22 @@ var (
 		Value:    ethconfig.Defaults.TransactionHistory,
 		Category: flags.StateCategory,
 	}
+	TxLookupAddressesFlag = &cli.PathFlag{
+		Name:      "txlookup.addresses",
+		Usage:     "File with addresses (one per line) restricting the transaction index to their transactions and those of local accounts",
+		TakesFile: true,
+		Category:  flags.StateCategory,
+	}
+	HeaderAccumulatorFlag = &cli.BoolFlag{
+		Name:     "history.accumulator",
+		Usage:    "Maintain a Merkle mountain range over the canonical headers to serve header inclusion proofs",
+		Category: flags.StateCategory,
+	}
+	LogIndexFlag = &cli.BoolFlag{
+		Name:     "history.logindex",
+		Usage:    "Index the logs of recent blocks by emitting address to speed up address-filtered log queries",
+		Category: flags.StateCategory,
+	}
 	// Light server and client settings
 	LightServeFlag = &cli.IntFlag{
 		Name:     "light.serve",
@@ -462,6 +506,17 @@ var (
 		Value:    ethconfig.Defaults.TxPool.Rejournal,
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolPersistFlag = &cli.BoolFlag{
+		Name:     "txpool.persist",
+		Usage:    "Persist all pending and queued transactions across node restarts",
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolPersistFileFlag = &cli.StringFlag{
+		Name:     "txpool.persistfile",
+		Usage:    "Disk file the transaction pool is persisted into on shutdown (relative to datadir)",
+		Value:    ethconfig.Defaults.TxPool.PersistFile,
+		Category: flags.TxPoolCategory,
+	}
 	TxPoolPriceLimitFlag = &cli.Uint64Flag{
 		Name:     "txpool.pricelimit",
 		Usage:    "Minimum gas price tip to enforce for acceptance into the pool",
@@ -504,6 +559,36 @@ var (
 		Value:    ethconfig.Defaults.TxPool.Lifetime,
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolQueuePoliciesFlag = &cli.StringFlag{
+		Name:     "txpool.queuepolicies",
+		Usage:    "Comma separated per-account queue overrides as address:slots[:lifetime] (e.g. exchange hot wallets)",
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolRejectUnprotectedFlag = &cli.BoolFlag{
+		Name:     "txpool.rejectunprotected",
+		Usage:    "Reject transactions without EIP-155 replay protection, which can be replayed on other chains",
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolNoGossipFlag = &cli.BoolFlag{
+		Name:     "txpool.nogossip",
+		Usage:    "Disables outbound transaction gossip (transactions are still received)",
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolGossipHashesOnlyFlag = &cli.BoolFlag{
+		Name:     "txpool.gossip.hashesonly",
+		Usage:    "Only announce transaction hashes to peers, never broadcast full transactions",
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolGossipNoLocalsFlag = &cli.BoolFlag{
+		Name:     "txpool.gossip.nolocals",
+		Usage:    "Excludes transactions from local accounts from outbound gossip",
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolPrivateRelaysFlag = &cli.StringFlag{
+		Name:     "txpool.privaterelays",
+		Usage:    "Comma separated RPC endpoints that eth_sendPrivateTransaction forwards transactions to",
+		Category: flags.TxPoolCategory,
+	}
 	// Blob transaction pool settings
 	BlobPoolDataDirFlag = &cli.StringFlag{
 		Name:     "blobpool.datadir",
@@ -542,6 +627,11 @@ var (
 		Value:    15,
 		Category: flags.PerfCategory,
 	}
+	CacheTrieWorkersFlag = &cli.IntFlag{
+		Name:     "cache.trie.workers",
+		Usage:    "Number of storage tries hashed and committed concurrently (0 = autodetect)",
+		Category: flags.PerfCategory,
+	}
 	CacheGCFlag = &cli.IntFlag{
 		Name:     "cache.gc",
 		Usage:    "Percentage of cache memory allowance to use for trie pruning (default = 25% full mode, 0% archive mode)",
@@ -559,11 +649,48 @@ var (
 		Usage:    "Disable heuristic state prefetch during block import (less CPU and disk IO, more time waiting for data)",
 		Category: flags.PerfCategory,
 	}
+	ImportPrefetchFlag = &cli.IntFlag{
+		Name:     "import.prefetch",
+		Usage:    "Number of followup blocks to prefetch state for during block import (0 = autodetect)",
+		Category: flags.PerfCategory,
+	}
+	ImportPrefetchWorkersFlag = &cli.IntFlag{
+		Name:     "import.prefetchworkers",
+		Usage:    "Number of concurrent state prefetcher goroutines during block import (0 = autodetect)",
+		Category: flags.PerfCategory,
+	}
+	ImportVerifyWorkersFlag = &cli.IntFlag{
+		Name:     "import.verifyworkers",
+		Usage:    "Number of concurrent header verification workers during block import (0 = autodetect)",
+		Category: flags.PerfCategory,
+	}
+	ImportGovernorRPCLatencyFlag = &cli.DurationFlag{
+		Name:     "import.governor.rpclatency",
+		Usage:    "99th percentile RPC serving time above which block import is slowed down (0 = disabled)",
+		Category: flags.PerfCategory,
+	}
+	ImportGovernorIOWaitFlag = &cli.IntFlag{
+		Name:     "import.governor.iowait",
+		Usage:    "Percentage of CPU time spent waiting on disk IO above which block import is slowed down (0 = disabled)",
+		Category: flags.PerfCategory,
+	}
 	CachePreimagesFlag = &cli.BoolFlag{
 		Name:     "cache.preimages",
 		Usage:    "Enable recording the SHA3/keccak preimages of trie keys",
 		Category: flags.PerfCategory,
 	}
+	CacheAnalysisFlag = &cli.IntFlag{
+		Name:     "cache.analysis",
+		Usage:    "Megabytes of memory allocated to the code analysis cache shared across transactions (0 = disabled)",
+		Value:    ethconfig.Defaults.AnalysisCache,
+		Category: flags.PerfCategory,
+	}
+	CacheAnalysisJournalFlag = &cli.StringFlag{
+		Name:     "cache.analysis.journal",
+		Usage:    "Disk journal file for the code analysis cache to survive node restarts (empty = disabled)",
+		Value:    ethconfig.Defaults.AnalysisJournal,
+		Category: flags.PerfCategory,
+	}
 	CacheLogSizeFlag = &cli.IntFlag{
 		Name:     "cache.blocklogs",
 		Usage:    "Size (in number of blocks) of the log cache for filtering",
@@ -643,6 +770,23 @@ var (
 		Value:    ethconfig.Defaults.Miner.NewPayloadTimeout,
 		Category: flags.MinerCategory,
 	}
+	MinerCandidatesFlag = &cli.IntFlag{
+		Name:     "miner.candidates",
+		Usage:    "Number of candidate blocks with different transaction selections to build per recommit, sealing the most profitable one",
+		Value:    1,
+		Category: flags.MinerCategory,
+	}
+	MinerSpeculateFlag = &cli.IntFlag{
+		Name:     "miner.speculate",
+		Usage:    "Number of top pending transactions to keep pre-executed against the head while mining (0 = disabled)",
+		Value:    ethconfig.Defaults.Miner.SpeculativeTxs,
+		Category: flags.MinerCategory,
+	}
+	MinerSponsoredFlag = &cli.BoolFlag{
+		Name:     "miner.sponsored",
+		Usage:    "Enable the experimental sponsor API accepting sponsored transaction bundles for inclusion in mined blocks",
+		Category: flags.MinerCategory,
+	}
 
 	// Account settings
 	UnlockedAccountFlag = &cli.StringFlag{
@@ -695,6 +839,31 @@ var (
 		Value:    ethconfig.Defaults.RPCTxFeeCap,
 		Category: flags.APICategory,
 	}
+	RPCServeWhileSyncingFlag = &cli.BoolFlag{
+		Name:     "rpc.servewhilesyncing",
+		Usage:    "Serve RPC requests from the best available data during initial sync, marking HTTP responses with the sync status",
+		Category: flags.APICategory,
+	}
+	RPCCallCacheFlag = &cli.IntFlag{
+		Name:     "rpc.callcache",
+		Usage:    "Number of eth_call results cached at finalized blocks, invalidated on reorg (0 = disabled)",
+		Category: flags.APICategory,
+	}
+	TraceFileDirFlag = &flags.DirectoryFlag{
+		Name:     "trace.filedir",
+		Usage:    "Directory for the output of debug_standardTraceBlockToFile (default = system temp dir)",
+		Category: flags.APICategory,
+	}
+	TraceFileLimitFlag = &cli.Uint64Flag{
+		Name:     "trace.filelimit",
+		Usage:    "Total size in megabytes of trace files to retain in --trace.filedir, oldest deleted first (0 = unlimited)",
+		Category: flags.APICategory,
+	}
+	TraceSignatureDBFlag = &cli.StringFlag{
+		Name:     "trace.signaturedb",
+		Usage:    "Custom 4byte signature file used next to the embedded database for decoding call data",
+		Category: flags.APICategory,
+	}
 	// Authenticated RPC HTTP settings
 	AuthListenFlag = &cli.StringFlag{
 		Name:     "authrpc.addr",
@@ -726,6 +895,11 @@ var (
 		Usage:    "Reporting URL of a ethstats service (nodename:secret@host:port)",
 		Category: flags.MetricsCategory,
 	}
+	EventSinksFlag = &cli.StringFlag{
+		Name:     "sinks",
+		Usage:    "YAML file mapping node events (newHead, reorg, pendingTx, syncStalled, peerDrop) to delivery destinations",
+		Category: flags.MetricsCategory,
+	}
 	FakePoWFlag = &cli.BoolFlag{
 		Name:     "fakepow",
 		Usage:    "Disables proof-of-work verification",
@@ -819,6 +993,45 @@ var (
 		Value:    strings.Join(node.DefaultConfig.GraphQLVirtualHosts, ","),
 		Category: flags.APICategory,
 	}
+	MetaRelayAccountFlag = &cli.StringFlag{
+		Name:     "metarelay.account",
+		Usage:    "Enable the meta-transaction relay on the HTTP-RPC server, paying fees from this account (e.g. managed by clef via --signer)",
+		Category: flags.APICategory,
+	}
+	MetaRelayForwarderFlag = &cli.StringFlag{
+		Name:     "metarelay.forwarder",
+		Usage:    "Address of the EIP-2771 trusted forwarder contract executing relayed meta-transactions",
+		Category: flags.APICategory,
+	}
+	MetaRelayMaxGasFlag = &cli.Uint64Flag{
+		Name:     "metarelay.maxgas",
+		Usage:    "Maximum gas limit of a relayed meta-transaction",
+		Value:    metarelay.DefaultConfig.MaxGas,
+		Category: flags.APICategory,
+	}
+	MetaRelayTargetsFlag = &cli.StringFlag{
+		Name:     "metarelay.targets",
+		Usage:    "Comma separated list of contracts relayed meta-transactions may call (default = any)",
+		Category: flags.APICategory,
+	}
+	MetaRelaySenderLimitFlag = &cli.IntFlag{
+		Name:     "metarelay.senderlimit",
+		Usage:    "Maximum number of meta-transactions relayed per sender per hour (0 = unlimited)",
+		Value:    metarelay.DefaultConfig.SenderLimit,
+		Category: flags.APICategory,
+	}
+	MetaRelayLimitFlag = &cli.IntFlag{
+		Name:     "metarelay.limit",
+		Usage:    "Maximum number of meta-transactions relayed per hour across all senders (0 = unlimited)",
+		Value:    metarelay.DefaultConfig.RelayLimit,
+		Category: flags.APICategory,
+	}
+	MetaRelaySpendLimitFlag = &flags.BigFlag{
+		Name:     "metarelay.spendlimit",
+		Usage:    "Maximum fees (wei) the relayer account commits per hour (0 = unlimited)",
+		Value:    metarelay.DefaultConfig.SpendLimit,
+		Category: flags.APICategory,
+	}
 	WSEnabledFlag = &cli.BoolFlag{
 		Name:     "ws",
 		Usage:    "Enable the WS-RPC server",
@@ -881,6 +1094,32 @@ var (
 		Value:    node.DefaultConfig.BatchResponseMaxSize,
 		Category: flags.APICategory,
 	}
+	RPCSubscriptionLimitFlag = &cli.IntFlag{
+		Name:     "rpc.subscription-limit",
+		Usage:    "Maximum number of active subscriptions per RPC connection (0 = unlimited)",
+		Category: flags.APICategory,
+	}
+	RPCFilterLimitFlag = &cli.IntFlag{
+		Name:     "rpc.filter-limit",
+		Usage:    "Maximum number of filters installed per remote RPC client IP (0 = unlimited)",
+		Category: flags.APICategory,
+	}
+	RPCFilterTimeoutFlag = &cli.DurationFlag{
+		Name:     "rpc.filter-timeout",
+		Usage:    "Time after which installed filters not polled are uninstalled",
+		Value:    ethconfig.Defaults.FilterTimeout,
+		Category: flags.APICategory,
+	}
+	RPCFilterPendingTxTimeoutFlag = &cli.DurationFlag{
+		Name:     "rpc.filter-timeout.pendingtx",
+		Usage:    "Time after which pending transaction filters not polled are uninstalled (default = rpc.filter-timeout)",
+		Category: flags.APICategory,
+	}
+	RPCLenientParamsFlag = &cli.BoolFlag{
+		Name:     "rpc.lenient-params",
+		Usage:    "Accept hex encoded RPC call parameters lacking the 0x prefix (legacy tooling compatibility)",
+		Category: flags.APICategory,
+	}
 	EnablePersonal = &cli.BoolFlag{
 		Name:     "rpc.enabledeprecatedpersonal",
 		Usage:    "Enables the (deprecated) personal namespace",
@@ -900,6 +1139,11 @@ var (
 		Value:    node.DefaultConfig.P2P.MaxPendingPeers,
 		Category: flags.NetworkingCategory,
 	}
+	PinnedPeersFlag = &cli.IntFlag{
+		Name:     "pinnedpeers",
+		Usage:    "Number of most useful peers of previous runs to keep connected like static nodes",
+		Category: flags.NetworkingCategory,
+	}
 	ListenPortFlag = &cli.IntFlag{
 		Name:     "port",
 		Usage:    "Network listening port",
@@ -928,6 +1172,16 @@ var (
 		Value:    "any",
 		Category: flags.NetworkingCategory,
 	}
+	ClockDriftCheckFlag = &cli.BoolFlag{
+		Name:     "ntp.check",
+		Usage:    "Periodically measure the drift of the local clock against the NTP pool and report it as a metric",
+		Category: flags.NetworkingCategory,
+	}
+	ClockDriftAdjustFlag = &cli.BoolFlag{
+		Name:     "ntp.adjust",
+		Usage:    "Extend the future block tolerance by the measured lag of the local clock (at most 15s, implies --ntp.check)",
+		Category: flags.NetworkingCategory,
+	}
 	NoDiscoverFlag = &cli.BoolFlag{
 		Name:     "nodiscover",
 		Usage:    "Disables the peer discovery mechanism (manual peer addition)",
@@ -976,6 +1230,24 @@ var (
 		Usage:    "Pass custom headers to the RPC server when using --" + RemoteDBFlag.Name + " or the geth attach console. This flag can be given multiple times.",
 		Category: flags.APICategory,
 	}
+	AttachTLSCertFlag = &cli.PathFlag{
+		Name:      "attach.tls.cert",
+		Usage:     "Client certificate file (PEM) presented by the geth attach console to a TLS endpoint",
+		TakesFile: true,
+		Category:  flags.APICategory,
+	}
+	AttachTLSKeyFlag = &cli.PathFlag{
+		Name:      "attach.tls.key",
+		Usage:     "Private key file (PEM) of the geth attach console client certificate",
+		TakesFile: true,
+		Category:  flags.APICategory,
+	}
+	AttachTLSCAFlag = &cli.PathFlag{
+		Name:      "attach.tls.ca",
+		Usage:     "Certificate authorities file (PEM) used by the geth attach console to verify the TLS endpoint, instead of the system ones",
+		TakesFile: true,
+		Category:  flags.APICategory,
+	}
 
 	// Gas price oracle settings
 	GpoBlocksFlag = &cli.IntFlag{
@@ -1095,6 +1367,16 @@ Please note that --` + MetricsHTTPFlag.Name + ` must be set to start the server.
 		Usage:    "Short-circuit ECBP-1100 (MESS) disable mechanisms; (yields a permanent-once-activated state, deactivating auto-shutoff mechanisms)",
 		Category: flags.DeprecatedCategory,
 	}
+	MaxReorgDepthFlag = &cli.Uint64Flag{
+		Name:     "maxreorg",
+		Usage:    "Maximum number of blocks a reorg may drop without confirmation via admin_acceptReorg, halting head updates until then (0 = no limit)",
+		Category: flags.EthCategory,
+	}
+	AttackRiskWebhookFlag = &cli.StringFlag{
+		Name:     "attackrisk.webhook",
+		Usage:    "URL to post the majority attack risk to whenever its level rises",
+		Category: flags.EthCategory,
+	}
 
 	MetricsEnableInfluxDBV2Flag = &cli.BoolFlag{
 		Name:     "metrics.influxdbv2",
@@ -1122,6 +1404,30 @@ Please note that --` + MetricsHTTPFlag.Name + ` must be set to start the server.
 		Value:    metrics.DefaultConfig.InfluxDBOrganization,
 		Category: flags.MetricsCategory,
 	}
+
+	MetricsRemoteWriteEndpointFlag = &cli.StringFlag{
+		Name:     "metrics.remotewrite",
+		Usage:    "Prometheus remote-write endpoint to push metrics to (e.g. http://localhost:9090/api/v1/write)",
+		Category: flags.MetricsCategory,
+	}
+
+	MetricsRemoteWriteUsernameFlag = &cli.StringFlag{
+		Name:     "metrics.remotewrite.username",
+		Usage:    "Username for basic authentication at the Prometheus remote-write endpoint",
+		Category: flags.MetricsCategory,
+	}
+
+	MetricsRemoteWritePasswordFlag = &cli.StringFlag{
+		Name:     "metrics.remotewrite.password",
+		Usage:    "Password for basic authentication at the Prometheus remote-write endpoint",
+		Category: flags.MetricsCategory,
+	}
+
+	MetricsRemoteWriteLabelsFlag = &cli.StringFlag{
+		Name:     "metrics.remotewrite.labels",
+		Usage:    "Comma-separated labels (key=values) attached to all series pushed to the Prometheus remote-write endpoint",
+		Category: flags.MetricsCategory,
+	}
 )
 
 var (
@@ -1143,6 +1449,8 @@ var (
 	DatabaseFlags = []cli.Flag{
 		DataDirFlag,
 		AncientFlag,
+		AncientCompressionFlag,
+		DataDirOverlayFlag,
 		RemoteDBFlag,
 		DBEngineFlag,
 		StateSchemeFlag,
@@ -1363,6 +1671,14 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(BatchResponseMaxSize.Name) {
 		cfg.BatchResponseMaxSize = ctx.Int(BatchResponseMaxSize.Name)
 	}
+
+	if ctx.IsSet(RPCLenientParamsFlag.Name) {
+		cfg.LenientParams = ctx.Bool(RPCLenientParamsFlag.Name)
+	}
+
+	if ctx.IsSet(RPCSubscriptionLimitFlag.Name) {
+		cfg.SubscriptionLimit = ctx.Int(RPCSubscriptionLimitFlag.Name)
+	}
 }
 
 // setGraphQL creates the GraphQL listener interface string from the set
@@ -1538,6 +1854,30 @@ func MakePasswordList(ctx *cli.Context) []string {
 	return lines
 }
 
+// makeTxLookupAddresses reads the addresses whose transactions are indexed from
+// the given file. Empty lines and lines starting with '#' are ignored.
+func makeTxLookupAddresses(path string) []common.Address {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		Fatalf("Failed to read transaction index address file: %v", err)
+	}
+	var addresses []common.Address
+	for i, line := range strings.Split(string(text), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !common.IsHexAddress(line) {
+			Fatalf("Invalid address in transaction index address file, line %d: %q", i+1, line)
+		}
+		addresses = append(addresses, common.HexToAddress(line))
+	}
+	if len(addresses) == 0 {
+		Fatalf("No addresses in transaction index address file %s", path)
+	}
+	return addresses
+}
+
 func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 	setNodeKey(ctx, cfg)
 	setNAT(ctx, cfg)
@@ -1554,6 +1894,9 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 	if ctx.IsSet(MaxPendingPeersFlag.Name) {
 		cfg.MaxPendingPeers = ctx.Int(MaxPendingPeersFlag.Name)
 	}
+	if ctx.IsSet(PinnedPeersFlag.Name) {
+		cfg.PinnedPeers = ctx.Int(PinnedPeersFlag.Name)
+	}
 	if ctx.IsSet(NoDiscoverFlag.Name) {
 		cfg.NoDiscovery = true
 	}
@@ -1664,6 +2007,9 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 		log.Info(fmt.Sprintf("Using %s as db engine", dbEngine))
 		cfg.DBEngine = dbEngine
 	}
+	if ctx.IsSet(DataDirOverlayFlag.Name) {
+		cfg.DataDirOverlay = ctx.String(DataDirOverlayFlag.Name)
+	}
 	// deprecation notice for log debug flags (TODO: find a more appropriate place to put these?)
 	if ctx.IsSet(LogBacktraceAtFlag.Name) {
 		log.Warn("log.backtrace flag is deprecated")
@@ -1759,6 +2105,12 @@ func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
 	if ctx.IsSet(TxPoolRejournalFlag.Name) {
 		cfg.Rejournal = ctx.Duration(TxPoolRejournalFlag.Name)
 	}
+	if ctx.IsSet(TxPoolPersistFlag.Name) {
+		cfg.Persist = ctx.Bool(TxPoolPersistFlag.Name)
+	}
+	if ctx.IsSet(TxPoolPersistFileFlag.Name) {
+		cfg.PersistFile = ctx.String(TxPoolPersistFileFlag.Name)
+	}
 	if ctx.IsSet(TxPoolPriceLimitFlag.Name) {
 		cfg.PriceLimit = ctx.Uint64(TxPoolPriceLimitFlag.Name)
 	}
@@ -1780,6 +2132,40 @@ func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
 	if ctx.IsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.Duration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.IsSet(TxPoolQueuePoliciesFlag.Name) {
+		for _, entry := range SplitAndTrim(ctx.String(TxPoolQueuePoliciesFlag.Name)) {
+			parts := strings.Split(entry, ":")
+			if len(parts) < 2 || len(parts) > 3 || !common.IsHexAddress(parts[0]) {
+				Fatalf("Invalid entry in --txpool.queuepolicies: %s", entry)
+			}
+			slots, err := strconv.ParseUint(parts[1], 10, 64)
+			if err != nil {
+				Fatalf("Invalid slot count in --txpool.queuepolicies: %s", entry)
+			}
+			policy := legacypool.AccountQueuePolicy{Address: common.HexToAddress(parts[0]), Slots: slots}
+			if len(parts) == 3 {
+				if policy.Lifetime, err = time.ParseDuration(parts[2]); err != nil {
+					Fatalf("Invalid lifetime in --txpool.queuepolicies: %s", entry)
+				}
+			}
+			cfg.QueuePolicies = append(cfg.QueuePolicies, policy)
+		}
+	}
+	if ctx.IsSet(TxPoolRejectUnprotectedFlag.Name) {
+		cfg.RejectUnprotected = ctx.Bool(TxPoolRejectUnprotectedFlag.Name)
+	}
+}
+
+func setTxGossip(ctx *cli.Context, cfg *ethconfig.TxGossipConfig) {
+	if ctx.IsSet(TxPoolNoGossipFlag.Name) {
+		cfg.Disabled = ctx.Bool(TxPoolNoGossipFlag.Name)
+	}
+	if ctx.IsSet(TxPoolGossipHashesOnlyFlag.Name) {
+		cfg.HashesOnly = ctx.Bool(TxPoolGossipHashesOnlyFlag.Name)
+	}
+	if ctx.IsSet(TxPoolGossipNoLocalsFlag.Name) {
+		cfg.NoLocals = ctx.Bool(TxPoolGossipNoLocalsFlag.Name)
+	}
 }
 
 func homeDir() string {
@@ -1883,6 +2269,15 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.IsSet(MinerNewPayloadTimeout.Name) {
 		cfg.NewPayloadTimeout = ctx.Duration(MinerNewPayloadTimeout.Name)
 	}
+	if ctx.IsSet(MinerCandidatesFlag.Name) {
+		cfg.Candidates = ctx.Int(MinerCandidatesFlag.Name)
+	}
+	if ctx.IsSet(MinerSpeculateFlag.Name) {
+		cfg.SpeculativeTxs = ctx.Int(MinerSpeculateFlag.Name)
+	}
+	if ctx.IsSet(MinerSponsoredFlag.Name) {
+		cfg.Sponsored = ctx.Bool(MinerSponsoredFlag.Name)
+	}
 }
 
 func setRequiredBlocks(ctx *cli.Context, cfg *ethconfig.Config) {
@@ -1957,26 +2352,41 @@ func CheckExclusive(ctx *cli.Context, args ...interface{}) {
 // SetEthConfig applies eth-related command line flags to the config.
 func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	// Avoid conflicting network flags
-	CheckExclusive(ctx, MainnetFlag, DeveloperFlag, DeveloperPoWFlag, GoerliFlag, SepoliaFlag, ClassicFlag, MordorFlag, MintMeFlag, HoleskyFlag)
+	CheckExclusive(ctx, MainnetFlag, DeveloperFlag, DeveloperPoWFlag, GoerliFlag, SepoliaFlag, ClassicFlag, MordorFlag, MintMeFlag, HoleskyFlag, GenesisURLFlag)
 	CheckExclusive(ctx, LightServeFlag, SyncModeFlag, "light")
 	CheckExclusive(ctx, DeveloperFlag, DeveloperPoWFlag, ExternalSignerFlag) // Can't use both ephemeral unlocked and external signer
 
 	setEtherbase(ctx, cfg)
 	setGPO(ctx, &cfg.GPO)
 	setTxPool(ctx, &cfg.TxPool)
+	setTxGossip(ctx, &cfg.TxGossip)
+	if ctx.IsSet(TxPoolPrivateRelaysFlag.Name) {
+		cfg.PrivateTxRelays = SplitAndTrim(ctx.String(TxPoolPrivateRelaysFlag.Name))
+	}
+	if ctx.IsSet(MaxReorgDepthFlag.Name) {
+		cfg.MaxReorgDepth = ctx.Uint64(MaxReorgDepthFlag.Name)
+	}
+	if ctx.IsSet(AttackRiskWebhookFlag.Name) {
+		cfg.AttackRiskWebhook = ctx.String(AttackRiskWebhookFlag.Name)
+	}
+	if ctx.IsSet(ClockDriftCheckFlag.Name) {
+		cfg.ClockDriftCheck = ctx.Bool(ClockDriftCheckFlag.Name)
+	}
+	if ctx.IsSet(ClockDriftAdjustFlag.Name) {
+		cfg.ClockDriftAdjust = ctx.Bool(ClockDriftAdjustFlag.Name)
+	}
 	setEthash(ctx, cfg)
 	setMiner(ctx, &cfg.Miner)
 	setRequiredBlocks(ctx, cfg)
 	setLes(ctx, cfg)
 
 	// Cap the cache allowance and tune the garbage collector
-	mem, err := gopsutil.VirtualMemory()
-	if err == nil {
-		if 32<<(^uintptr(0)>>63) == 32 && mem.Total > 2*1024*1024*1024 {
-			log.Warn("Lowering memory allowance on 32bit arch", "available", mem.Total/1024/1024, "addressable", 2*1024)
-			mem.Total = 2 * 1024 * 1024 * 1024
+	if total := memoryBudget(); total > 0 {
+		if 32<<(^uintptr(0)>>63) == 32 && total > 2*1024*1024*1024 {
+			log.Warn("Lowering memory allowance on 32bit arch", "available", total/1024/1024, "addressable", 2*1024)
+			total = 2 * 1024 * 1024 * 1024
 		}
-		allowance := int(mem.Total / 1024 / 1024 / 3)
+		allowance := int(total / 1024 / 1024 / 3)
 		if cache := ctx.Int(CacheFlag.Name); cache > allowance {
 			log.Warn("Sanitizing cache to Go's GC limits", "provided", cache, "updated", allowance)
 			ctx.Set(CacheFlag.Name, strconv.Itoa(allowance))
@@ -2002,6 +2412,12 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(AncientFlag.Name) {
 		cfg.DatabaseFreezer = ctx.String(AncientFlag.Name)
 	}
+	if ctx.IsSet(AncientCompressionFlag.Name) {
+		cfg.AncientCompression = ctx.String(AncientCompressionFlag.Name)
+	}
+	if err := rawdb.SetAncientCompression(cfg.AncientCompression); err != nil {
+		Fatalf("%v", err)
+	}
 
 	if gcmode := ctx.String(GCModeFlag.Name); gcmode != "full" && gcmode != gcModeArchive {
 		Fatalf("--%s must be either 'full' or 'archive'", GCModeFlag.Name)
@@ -2012,6 +2428,24 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(CacheNoPrefetchFlag.Name) {
 		cfg.NoPrefetch = ctx.Bool(CacheNoPrefetchFlag.Name)
 	}
+	if ctx.IsSet(ImportPrefetchFlag.Name) {
+		cfg.PrefetchDistance = ctx.Int(ImportPrefetchFlag.Name)
+	}
+	if ctx.IsSet(ImportPrefetchWorkersFlag.Name) {
+		cfg.PrefetchWorkers = ctx.Int(ImportPrefetchWorkersFlag.Name)
+	}
+	if ctx.IsSet(CacheTrieWorkersFlag.Name) {
+		cfg.TrieWorkers = ctx.Int(CacheTrieWorkersFlag.Name)
+	}
+	if ctx.IsSet(ImportGovernorRPCLatencyFlag.Name) {
+		cfg.ImportGovernor.RPCLatency = ctx.Duration(ImportGovernorRPCLatencyFlag.Name)
+	}
+	if ctx.IsSet(ImportGovernorIOWaitFlag.Name) {
+		cfg.ImportGovernor.IOWait = ctx.Int(ImportGovernorIOWaitFlag.Name)
+	}
+	if ctx.IsSet(ImportVerifyWorkersFlag.Name) {
+		cfg.Ethash.VerifyWorkers = ctx.Int(ImportVerifyWorkersFlag.Name)
+	}
 	// Read the value from the flag no matter if it's set or not.
 	cfg.Preimages = ctx.Bool(CachePreimagesFlag.Name)
 	if cfg.NoPruning && !cfg.Preimages {
@@ -2036,6 +2470,15 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		log.Warn("The flag --txlookuplimit is deprecated and will be removed, please use --history.transactions")
 		cfg.TransactionHistory = ctx.Uint64(TxLookupLimitFlag.Name)
 	}
+	if ctx.IsSet(TxLookupAddressesFlag.Name) {
+		cfg.TxLookupAddresses = makeTxLookupAddresses(ctx.Path(TxLookupAddressesFlag.Name))
+	}
+	if ctx.IsSet(HeaderAccumulatorFlag.Name) {
+		cfg.HeaderAccumulator = ctx.Bool(HeaderAccumulatorFlag.Name)
+	}
+	if ctx.IsSet(LogIndexFlag.Name) {
+		cfg.LogIndex = ctx.Bool(LogIndexFlag.Name)
+	}
 	if ctx.String(GCModeFlag.Name) == gcModeArchive && cfg.TransactionHistory != 0 {
 		cfg.TransactionHistory = 0
 		log.Warn("Disabled transaction unindexing for archive node")
@@ -2049,9 +2492,24 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(CacheFlag.Name) || ctx.IsSet(CacheSnapshotFlag.Name) {
 		cfg.SnapshotCache = ctx.Int(CacheFlag.Name) * ctx.Int(CacheSnapshotFlag.Name) / 100
 	}
+	if ctx.IsSet(CacheAnalysisFlag.Name) {
+		cfg.AnalysisCache = ctx.Int(CacheAnalysisFlag.Name)
+	}
+	if ctx.IsSet(CacheAnalysisJournalFlag.Name) {
+		cfg.AnalysisJournal = ctx.String(CacheAnalysisJournalFlag.Name)
+	}
 	if ctx.IsSet(CacheLogSizeFlag.Name) {
 		cfg.FilterLogCacheSize = ctx.Int(CacheLogSizeFlag.Name)
 	}
+	if ctx.IsSet(RPCFilterTimeoutFlag.Name) {
+		cfg.FilterTimeout = ctx.Duration(RPCFilterTimeoutFlag.Name)
+	}
+	if ctx.IsSet(RPCFilterPendingTxTimeoutFlag.Name) {
+		cfg.FilterPendingTxTimeout = ctx.Duration(RPCFilterPendingTxTimeoutFlag.Name)
+	}
+	if ctx.IsSet(RPCFilterLimitFlag.Name) {
+		cfg.FilterLimit = ctx.Int(RPCFilterLimitFlag.Name)
+	}
 	if !ctx.Bool(SnapshotFlag.Name) || cfg.SnapshotCache == 0 {
 		// If snap-sync is requested, this flag is also required
 		if cfg.SyncMode == downloader.SnapSync {
@@ -2102,6 +2560,21 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(RPCGlobalTxFeeCapFlag.Name) {
 		cfg.RPCTxFeeCap = ctx.Float64(RPCGlobalTxFeeCapFlag.Name)
 	}
+	if ctx.IsSet(RPCServeWhileSyncingFlag.Name) {
+		cfg.RPCServeWhileSyncing = ctx.Bool(RPCServeWhileSyncingFlag.Name)
+	}
+	if ctx.IsSet(RPCCallCacheFlag.Name) {
+		cfg.RPCCallCache = ctx.Int(RPCCallCacheFlag.Name)
+	}
+	if ctx.IsSet(TraceFileDirFlag.Name) {
+		cfg.TraceFileDir = ctx.String(TraceFileDirFlag.Name)
+	}
+	if ctx.IsSet(TraceFileLimitFlag.Name) {
+		cfg.TraceFileLimit = ctx.Uint64(TraceFileLimitFlag.Name) * 1024 * 1024
+	}
+	if ctx.IsSet(TraceSignatureDBFlag.Name) {
+		cfg.TraceSignatureDB = ctx.String(TraceSignatureDBFlag.Name)
+	}
 	if ctx.IsSet(NoDiscoverFlag.Name) {
 		cfg.EthDiscoveryURLs, cfg.SnapDiscoveryURLs = []string{}, []string{}
 	} else if ctx.IsSet(DNSDiscoveryFlag.Name) {
@@ -2121,6 +2594,16 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 			cfg.Genesis = gen
 		}
 	}
+	// Override genesis configuration with a remote one if --genesis.url is set.
+	// It is only used to initialize an empty database, otherwise it must match
+	// the stored genesis.
+	if ctx.IsSet(GenesisURLFlag.Name) {
+		gen, err := ReadGenesis(ctx.String(GenesisURLFlag.Name), ctx.String(GenesisSHA256Flag.Name))
+		if err != nil {
+			Fatalf("Failed to load genesis: %v", err)
+		}
+		cfg.Genesis = gen
+	}
 
 	// Establish NetworkID.
 	// If dev-mode is used, then NetworkID will be overridden.
@@ -2319,7 +2802,15 @@ func RegisterEthService(stack *node.Node, cfg *ethconfig.Config) (ethapi.Backend
 	if err != nil {
 		Fatalf("Failed to register the Ethereum service: %v", err)
 	}
-	stack.RegisterAPIs(tracers.APIs(backend.APIBackend))
+	files := &tracers.FileConfig{
+		Dir:     cfg.TraceFileDir,
+		MaxSize: cfg.TraceFileLimit,
+	}
+	if err := files.Validate(); err != nil {
+		Fatalf("Invalid --%s: %v, set --%s", TraceFileLimitFlag.Name, err, TraceFileDirFlag.Name)
+	}
+	stack.RegisterAPIs(tracers.APIs(backend.APIBackend, files))
+	tracers.SetSignatureDatabase(cfg.TraceSignatureDB)
 	return backend.APIBackend, backend
 }
 
@@ -2330,6 +2821,18 @@ func RegisterEthStatsService(stack *node.Node, backend ethapi.Backend, url strin
 	}
 }
 
+// RegisterEventSinkService configures the event sinks from the given file and
+// adds them to the node.
+func RegisterEventSinkService(stack *node.Node, backend ethapi.Backend, path string) {
+	config, err := eventsink.LoadConfig(path)
+	if err != nil {
+		Fatalf("Failed to load the event sink configuration: %v", err)
+	}
+	if err := eventsink.New(stack, backend, config); err != nil {
+		Fatalf("Failed to register the event sink service: %v", err)
+	}
+}
+
 // RegisterGraphQLService adds the GraphQL API to the node.
 func RegisterGraphQLService(stack *node.Node, backend ethapi.Backend, filterSystem *filters.FilterSystem, cfg *node.Config) {
 	err := graphql.New(stack, backend, filterSystem, cfg.GraphQLCors, cfg.GraphQLVirtualHosts)
@@ -2338,10 +2841,42 @@ func RegisterGraphQLService(stack *node.Node, backend ethapi.Backend, filterSyst
 	}
 }
 
+// RegisterMetaRelayService adds the meta-transaction relay to the node.
+func RegisterMetaRelayService(ctx *cli.Context, stack *node.Node, backend ethapi.Backend) {
+	cfg := metarelay.DefaultConfig
+	for _, flag := range []*cli.StringFlag{MetaRelayAccountFlag, MetaRelayForwarderFlag} {
+		if addr := ctx.String(flag.Name); !common.IsHexAddress(addr) {
+			Fatalf("Invalid address in --%s: %q", flag.Name, addr)
+		}
+	}
+	cfg.Account = common.HexToAddress(ctx.String(MetaRelayAccountFlag.Name))
+	cfg.Forwarder = common.HexToAddress(ctx.String(MetaRelayForwarderFlag.Name))
+	cfg.MaxGas = ctx.Uint64(MetaRelayMaxGasFlag.Name)
+	cfg.SenderLimit = ctx.Int(MetaRelaySenderLimitFlag.Name)
+	cfg.RelayLimit = ctx.Int(MetaRelayLimitFlag.Name)
+	if cfg.SpendLimit = flags.GlobalBig(ctx, MetaRelaySpendLimitFlag.Name); cfg.SpendLimit != nil && cfg.SpendLimit.Sign() == 0 {
+		cfg.SpendLimit = nil
+	}
+	if ctx.IsSet(MetaRelayTargetsFlag.Name) {
+		for _, target := range SplitAndTrim(ctx.String(MetaRelayTargetsFlag.Name)) {
+			if !common.IsHexAddress(target) {
+				Fatalf("Invalid address in --%s: %q", MetaRelayTargetsFlag.Name, target)
+			}
+			cfg.Targets = append(cfg.Targets, common.HexToAddress(target))
+		}
+	}
+	if _, err := metarelay.New(stack, backend, cfg); err != nil {
+		Fatalf("Failed to register the meta-transaction relay: %v", err)
+	}
+}
+
 // RegisterFilterAPI adds the eth log filtering RPC API to the node.
 func RegisterFilterAPI(stack *node.Node, backend ethapi.Backend, ethcfg *ethconfig.Config) *filters.FilterSystem {
 	filterSystem := filters.NewFilterSystem(backend, filters.Config{
-		LogCacheSize: ethcfg.FilterLogCacheSize,
+		LogCacheSize:     ethcfg.FilterLogCacheSize,
+		Timeout:          ethcfg.FilterTimeout,
+		PendingTxTimeout: ethcfg.FilterPendingTxTimeout,
+		FilterLimit:      ethcfg.FilterLimit,
 	})
 	stack.RegisterAPIs([]rpc.API{{
 		Namespace: "eth",
@@ -2406,6 +2941,16 @@ func SetupMetrics(ctx *cli.Context) {
 
 			go influxdb.InfluxDBV2WithTags(metrics.DefaultRegistry, 10*time.Second, endpoint, token, bucket, organization, "geth.", tagsMap)
 		}
+		if endpoint := ctx.String(MetricsRemoteWriteEndpointFlag.Name); endpoint != "" {
+			var (
+				username = ctx.String(MetricsRemoteWriteUsernameFlag.Name)
+				password = ctx.String(MetricsRemoteWritePasswordFlag.Name)
+				labels   = SplitTagsFlag(ctx.String(MetricsRemoteWriteLabelsFlag.Name))
+			)
+			log.Info("Enabling metrics export to Prometheus remote-write endpoint", "endpoint", endpoint)
+
+			go prometheus.RemoteWrite(metrics.DefaultRegistry, 10*time.Second, endpoint, username, password, labels)
+		}
 
 		if ctx.IsSet(MetricsHTTPFlag.Name) {
 			address := net.JoinHostPort(ctx.String(MetricsHTTPFlag.Name), fmt.Sprintf("%d", ctx.Int(MetricsPortFlag.Name)))
@@ -2483,7 +3028,7 @@ func IsNetworkPreset(ctx *cli.Context) bool {
 	return false
 }
 
-func DialRPCWithHeaders(endpoint string, headers []string) (*rpc.Client, error) {
+func DialRPCWithHeaders(endpoint string, headers []string, opts ...rpc.ClientOption) (*rpc.Client, error) {
 	if endpoint == "" {
 		return nil, errors.New("endpoint must be specified")
 	}
@@ -2492,7 +3037,6 @@ func DialRPCWithHeaders(endpoint string, headers []string) (*rpc.Client, error)
 		// these prefixes.
 		endpoint = endpoint[4:]
 	}
-	var opts []rpc.ClientOption
 	if len(headers) > 0 {
 		customHeaders := make(http.Header)
 		for _, h := range headers {
@@ -2507,6 +3051,65 @@ func DialRPCWithHeaders(endpoint string, headers []string) (*rpc.Client, error)
 	return rpc.DialOptions(context.Background(), endpoint, opts...)
 }
 
+// memoryBudget returns the memory available to the process in bytes: the memory
+// of the host, or the memory limit of the control group the process runs in if
+// lower, e.g. in a container. Zero is returned if neither can be detected.
+func memoryBudget() uint64 {
+	var total uint64
+	if mem, err := gopsutil.VirtualMemory(); err == nil {
+		total = mem.Total
+	} else {
+		log.Warn("Failed to retrieve system memory", "err", err)
+	}
+	limit, err := memlimit.Limit()
+	if err != nil {
+		log.Warn("Failed to retrieve cgroup memory limit", "err", err)
+	}
+	if limit != 0 && (total == 0 || limit < total) {
+		log.Info("Detected memory budget", "source", "cgroup", "limit", common.StorageSize(limit), "host", common.StorageSize(total))
+		return limit
+	}
+	if total != 0 {
+		log.Info("Detected memory budget", "source", "host", "total", common.StorageSize(total))
+	}
+	return total
+}
+
+// MakeAttachTLSConfig creates the TLS configuration of the geth attach console
+// from the --attach.tls.* flags, or nil if none of them is set.
+func MakeAttachTLSConfig(ctx *cli.Context) (*tls.Config, error) {
+	var (
+		certFile = ctx.Path(AttachTLSCertFlag.Name)
+		keyFile  = ctx.Path(AttachTLSKeyFlag.Name)
+		caFile   = ctx.Path(AttachTLSCAFlag.Name)
+	)
+	if certFile == "" && keyFile == "" && caFile == "" {
+		return nil, nil
+	}
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, fmt.Errorf("both --%s and --%s must be set", AttachTLSCertFlag.Name, AttachTLSKeyFlag.Name)
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read certificate authorities: %v", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", caFile)
+		}
+	}
+	return config, nil
+}
+
 // genesisForCtxChainConfig returns the corresponding Genesis for a non-default flag chain value.
 // If no --<chain> flag is set in the global context, a nil value is returned.
 // It does not handle genesis for --dev mode, since that mode includes but also exceeds
@@ -2569,6 +3172,7 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readonly bool) (*core.BlockCh
 	} else if ctx.Bool(FakePoWPoissonFlag.Name) {
 		ethashConfig.PowMode = ethash.ModePoissonFake
 	}
+	ethashConfig.VerifyWorkers = ctx.Int(ImportVerifyWorkersFlag.Name)
 
 	engine := ethconfig.CreateConsensusEngine(stack, &ethashConfig, cliqueConfig, lyra2Config, nil, false, chainDb)
 	if gcmode := ctx.String(GCModeFlag.Name); gcmode != gcModeFull && gcmode != gcModeArchive {
@@ -2581,6 +3185,9 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readonly bool) (*core.BlockCh
 	cache := &core.CacheConfig{
 		TrieCleanLimit:      ethconfig.Defaults.TrieCleanCache,
 		TrieCleanNoPrefetch: ctx.Bool(CacheNoPrefetchFlag.Name),
+		PrefetchDistance:    ctx.Int(ImportPrefetchFlag.Name),
+		PrefetchWorkers:     ctx.Int(ImportPrefetchWorkersFlag.Name),
+		TrieWorkers:         ctx.Int(CacheTrieWorkersFlag.Name),
 		TrieDirtyLimit:      ethconfig.Defaults.TrieDirtyCache,
 		TrieDirtyDisabled:   ctx.String(GCModeFlag.Name) == gcModeArchive,
 		TrieTimeLimit:       ethconfig.Defaults.TrieTimeout,
@@ -2633,16 +3240,22 @@ func MakeConsolePreloads(ctx *cli.Context) []string {
 	return preloads
 }
 
-// MakeTrieDatabase constructs a trie database based on the configured scheme.
-func MakeTrieDatabase(ctx *cli.Context, disk ethdb.Database, preimage bool, readOnly bool, isVerkle bool) *triedb.Database {
+// MakeTrieDatabase constructs a trie database based on the configured scheme
+// and the state layout of the database, or of the given genesis if the database
+// is not yet initialized.
+func MakeTrieDatabase(ctx *cli.Context, disk ethdb.Database, preimage bool, readOnly bool, genesis *genesisT.Genesis) *triedb.Database {
 	config := &triedb.Config{
 		Preimages: preimage,
-		IsVerkle:  isVerkle,
+		IsVerkle:  genesis != nil && genesis.IsVerkle(),
+		IsBinary:  core.IsBinaryStateLayout(disk, genesis),
 	}
 	scheme, err := rawdb.ParseStateScheme(ctx.String(StateSchemeFlag.Name), disk)
 	if err != nil {
 		Fatalf("%v", err)
 	}
+	if config.IsBinary && scheme != rawdb.HashScheme {
+		Fatalf("The binary trie state layout requires --%s=%s", StateSchemeFlag.Name, rawdb.HashScheme)
+	}
 	if scheme == rawdb.HashScheme {
 		// Read-only mode is not implemented in hash mode,
 		// ignore the parameter silently. TODO(rjl493456442)
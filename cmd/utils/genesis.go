@@ -0,0 +1,82 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+)
+
+// genesisFetchTimeout is the maximum time allowed for downloading a genesis
+// definition from a remote URL.
+const genesisFetchTimeout = 5 * time.Minute
+
+// ReadGenesis loads a genesis definition from a local path or an http(s) URL.
+// If a checksum is given, it must match the hex encoded SHA256 digest of the
+// genesis file for it to be accepted.
+func ReadGenesis(source string, checksum string) (*genesisT.Genesis, error) {
+	var (
+		blob []byte
+		err  error
+	)
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		blob, err = fetchGenesis(source)
+	} else {
+		blob, err = os.ReadFile(source)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if checksum != "" {
+		want, err := hex.DecodeString(strings.TrimPrefix(checksum, "0x"))
+		if err != nil || len(want) != sha256.Size {
+			return nil, fmt.Errorf("invalid genesis checksum %q", checksum)
+		}
+		if have := sha256.Sum256(blob); !bytes.Equal(have[:], want) {
+			return nil, fmt.Errorf("genesis checksum mismatch: have %x, want %x", have, want)
+		}
+	}
+	genesis := new(genesisT.Genesis)
+	if err := genesis.UnmarshalJSON(blob); err != nil {
+		return nil, fmt.Errorf("invalid genesis file: %v", err)
+	}
+	return genesis, nil
+}
+
+// fetchGenesis downloads a genesis definition over http(s).
+func fetchGenesis(url string) ([]byte, error) {
+	client := &http.Client{Timeout: genesisFetchTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch genesis: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,83 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testGenesis = `{"config":{"chainId":1337,"eip150Block":0},"difficulty":"0x1","gasLimit":"0x1000000","alloc":{}}`
+
+// Tests that genesis definitions can be loaded from files and URLs, and that
+// the checksum is enforced.
+func TestReadGenesis(t *testing.T) {
+	var (
+		sum      = sha256.Sum256([]byte(testGenesis))
+		checksum = hex.EncodeToString(sum[:])
+		path     = filepath.Join(t.TempDir(), "genesis.json")
+	)
+	if err := os.WriteFile(path, []byte(testGenesis), 0644); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/genesis.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(testGenesis))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		source   string
+		checksum string
+		fail     bool
+	}{
+		{source: path},
+		{source: path, checksum: checksum},
+		{source: path, checksum: "0x" + checksum},
+		{source: path, checksum: checksum[:62] + "00", fail: true},
+		{source: path, checksum: "zz", fail: true},
+		{source: srv.URL + "/genesis.json"},
+		{source: srv.URL + "/genesis.json", checksum: checksum},
+		{source: srv.URL + "/genesis.json", checksum: checksum[:62] + "00", fail: true},
+		{source: srv.URL + "/missing.json", fail: true},
+		{source: path + ".missing", fail: true},
+	}
+	for i, tt := range tests {
+		genesis, err := ReadGenesis(tt.source, tt.checksum)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("test %d: expected error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: failed to read genesis: %v", i, err)
+			continue
+		}
+		if id := genesis.Config.GetChainID(); id == nil || id.Uint64() != 1337 {
+			t.Errorf("test %d: chain id mismatch: have %v, want 1337", i, id)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package memlimit detects the memory limit imposed on the process by the
+// control group (cgroup) it runs in, e.g. the limit of a container.
+package memlimit
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// unlimited is the threshold above which a cgroup v1 limit is considered unset.
+// The kernel reports the maximum page aligned int64 if no limit is set.
+const unlimited = 1 << 62
+
+// cgroupLimit returns the lowest memory limit set on the cgroup the process is
+// assigned to, as listed in procFile, or on any of its ancestors, in the cgroup
+// filesystem mounted at root. Zero is returned if no limit is set.
+func cgroupLimit(procFile, root string) (uint64, error) {
+	f, err := os.Open(procFile)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var limit uint64
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Lines are formatted as hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		var dir string
+		var files []string
+		switch {
+		case parts[0] == "0" && parts[1] == "":
+			// Unified cgroup v2 hierarchy
+			dir, files = root, []string{"memory.max", "memory.high"}
+		case hasController(parts[1], "memory"):
+			// Memory controller of a cgroup v1 hierarchy
+			dir, files = filepath.Join(root, "memory"), []string{"memory.limit_in_bytes"}
+		default:
+			continue
+		}
+		l, err := hierarchyLimit(dir, parts[2], files)
+		if err != nil {
+			return 0, err
+		}
+		if l != 0 && (limit == 0 || l < limit) {
+			limit = l
+		}
+	}
+	return limit, scanner.Err()
+}
+
+// hasController reports whether the comma separated controller list contains
+// the given controller.
+func hasController(list, controller string) bool {
+	for _, c := range strings.Split(list, ",") {
+		if c == controller {
+			return true
+		}
+	}
+	return false
+}
+
+// hierarchyLimit returns the lowest limit found in the given files of the cgroup
+// at path and of its ancestors. Cgroups not visible in the mounted filesystem,
+// e.g. the ancestors of a container's cgroup namespace, are skipped.
+func hierarchyLimit(dir, path string, files []string) (uint64, error) {
+	var limit uint64
+	for path = filepath.Clean("/" + path); ; path = filepath.Dir(path) {
+		for _, file := range files {
+			l, err := readLimit(filepath.Join(dir, path, file))
+			if err != nil {
+				return 0, err
+			}
+			if l != 0 && (limit == 0 || l < limit) {
+				limit = l
+			}
+		}
+		if path == "/" {
+			return limit, nil
+		}
+	}
+}
+
+// readLimit reads a memory limit file, returning zero if the file is missing or
+// the limit is unset.
+func readLimit(file string) (uint64, error) {
+	blob, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	value := strings.TrimSpace(string(blob))
+	if value == "max" {
+		return 0, nil
+	}
+	limit, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if limit >= unlimited {
+		return 0, nil
+	}
+	return limit, nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package memlimit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCgroupLimit(t *testing.T) {
+	tests := []struct {
+		name   string
+		cgroup string
+		files  map[string]string
+		want   uint64
+	}{
+		{
+			name:   "v2 unlimited",
+			cgroup: "0::/\n",
+			files:  map[string]string{"memory.max": "max\n"},
+			want:   0,
+		},
+		{
+			name:   "v2 namespaced",
+			cgroup: "0::/\n",
+			files:  map[string]string{"memory.max": "536870912\n"},
+			want:   512 << 20,
+		},
+		{
+			name:   "v2 ancestor limit",
+			cgroup: "0::/kubepods/pod/container\n",
+			files: map[string]string{
+				"kubepods/pod/container/memory.max": "max\n",
+				"kubepods/pod/memory.max":           "1073741824\n",
+				"kubepods/memory.max":               "4294967296\n",
+			},
+			want: 1 << 30,
+		},
+		{
+			name:   "v2 high limit",
+			cgroup: "0::/service\n",
+			files: map[string]string{
+				"service/memory.max":  "1073741824\n",
+				"service/memory.high": "805306368\n",
+			},
+			want: 768 << 20,
+		},
+		{
+			name:   "v2 host path not mounted",
+			cgroup: "0::/system.slice/docker-abc.scope\n",
+			files:  map[string]string{"memory.max": "268435456\n"},
+			want:   256 << 20,
+		},
+		{
+			name:   "v1 limit",
+			cgroup: "5:cpu,cpuacct:/docker/abc\n4:memory:/docker/abc\n",
+			files: map[string]string{
+				"memory/memory.limit_in_bytes":            "9223372036854771712\n",
+				"memory/docker/abc/memory.limit_in_bytes": "2147483648\n",
+			},
+			want: 2 << 30,
+		},
+		{
+			name:   "v1 unlimited",
+			cgroup: "4:memory:/\n",
+			files:  map[string]string{"memory/memory.limit_in_bytes": "9223372036854771712\n"},
+			want:   0,
+		},
+	}
+	for _, tt := range tests {
+		root := t.TempDir()
+		for name, content := range tt.files {
+			path := filepath.Join(root, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		procFile := filepath.Join(t.TempDir(), "cgroup")
+		if err := os.WriteFile(procFile, []byte(tt.cgroup), 0644); err != nil {
+			t.Fatal(err)
+		}
+		have, err := cgroupLimit(procFile, root)
+		if err != nil {
+			t.Fatalf("%s: failed to read limit: %v", tt.name, err)
+		}
+		if have != tt.want {
+			t.Errorf("%s: limit mismatch: have %d, want %d", tt.name, have, tt.want)
+		}
+	}
+}
@@ -44,8 +44,9 @@ import (
 
 // Ethash proof-of-work protocol constants.
 var (
-	maxUncles              = 2                // Maximum number of uncles allowed in a single block
-	allowedFutureBlockTime = 15 * time.Second // Max time from current time allowed for blocks, before they're considered future blocks
+	maxUncles               = 2                // Maximum number of uncles allowed in a single block
+	allowedFutureBlockTime  = 15 * time.Second // Max time from current time allowed for blocks, before they're considered future blocks
+	maxClockLagCompensation = 15 * time.Second // Max extension of the future block tolerance to compensate for a lagging local clock
 )
 
 // Various error messages to mark blocks invalid. These should be private to
@@ -102,8 +103,11 @@ func (ethash *Ethash) VerifyHeaders(chain consensus.ChainHeaderReader, headers [
 		return abort, results
 	}
 
-	// Spawn as many workers as allowed threads
-	workers := runtime.GOMAXPROCS(0)
+	// Spawn as many workers as configured, or as allowed threads otherwise
+	workers := ethash.config.VerifyWorkers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
 	if len(headers) < workers {
 		workers = len(headers)
 	}
@@ -231,6 +235,29 @@ func (ethash *Ethash) VerifyUncles(chain consensus.ChainReader, block *types.Blo
 	return nil
 }
 
+// SetClockDrift informs the engine of the measured drift of the local clock
+// against network time (positive if the local clock is ahead). A lagging local
+// clock makes valid blocks appear to be from the future, so the future block
+// tolerance is extended by the lag, up to maxClockLagCompensation. The bound
+// keeps the tolerance within the window the blockchain queues future blocks
+// for anyway.
+func (ethash *Ethash) SetClockDrift(drift time.Duration) {
+	lag := -drift
+	if lag < 0 {
+		lag = 0
+	}
+	if lag > maxClockLagCompensation {
+		lag = maxClockLagCompensation
+	}
+	ethash.clockLag.Store(int64(lag))
+}
+
+// futureBlockTime returns the maximum time a block may be ahead of the local
+// clock before it's considered a future block.
+func (ethash *Ethash) futureBlockTime() time.Duration {
+	return allowedFutureBlockTime + time.Duration(ethash.clockLag.Load())
+}
+
 // verifyHeader checks whether a header conforms to the consensus rules of the
 // stock Ethereum ethash engine.
 // See YP section 4.3.4. "Block Header Validity"
@@ -241,7 +268,7 @@ func (ethash *Ethash) verifyHeader(chain consensus.ChainHeaderReader, header, pa
 	}
 	// Verify the header's timestamp
 	if !uncle {
-		if header.Time > uint64(unixNow+int64(allowedFutureBlockTime.Seconds())) {
+		if header.Time > uint64(unixNow+int64(ethash.futureBlockTime().Seconds())) {
 			return consensus.ErrFutureBlock
 		}
 	}
@@ -338,6 +365,11 @@ func (ethash *Ethash) verifyHeader(chain consensus.ChainHeaderReader, header, pa
 	if err := mutations.VerifyDAOHeaderExtraData(chain.Config(), header); err != nil {
 		return err
 	}
+	// The required fork block hashes are checked regardless of the seal, as snap
+	// sync only samples the seals of the downloaded headers.
+	if err := misc.VerifyForkHashes(chain.Config(), header, uncle); err != nil {
+		return err
+	}
 	return nil
 }
 
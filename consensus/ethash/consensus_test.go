@@ -20,14 +20,17 @@ import (
 	crand "crypto/rand"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"math/big"
 	"math/rand"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/params/types/goethereum"
 	"github.com/ethereum/go-ethereum/params/vars"
@@ -239,3 +242,34 @@ func TestEtchash_11700000(t *testing.T) {
 		t.Fatalf("verifySeal failed: %v", err)
 	}
 }
+
+// Tests that a lagging local clock extends the future block tolerance, within
+// the allowed bounds.
+func TestClockDriftCompensation(t *testing.T) {
+	var (
+		engine = NewFaker()
+		now    = int64(1_700_000_000)
+		parent = &types.Header{Time: uint64(now + 60)} // Fails the timestamp ordering check past the future check
+	)
+	tests := []struct {
+		drift  time.Duration
+		ahead  int64
+		future bool
+	}{
+		{0, 15, false},
+		{0, 16, true},
+		{5 * time.Second, 16, true}, // Clock ahead, no compensation
+		{-10 * time.Second, 25, false},
+		{-10 * time.Second, 26, true},
+		{-time.Minute, 30, false}, // Compensation capped
+		{-time.Minute, 31, true},
+	}
+	for i, tt := range tests {
+		engine.SetClockDrift(tt.drift)
+		header := &types.Header{Time: uint64(now + tt.ahead)}
+		err := engine.verifyHeader(nil, header, parent, false, false, now)
+		if future := errors.Is(err, consensus.ErrFutureBlock); future != tt.future {
+			t.Errorf("test %d: future block mismatch: have %v, want %v (err %v)", i, future, tt.future, err)
+		}
+	}
+}
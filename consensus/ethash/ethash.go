@@ -555,6 +555,10 @@ type Config struct {
 	DatasetsLockMmap bool
 	PowMode          Mode
 
+	// VerifyWorkers is the number of concurrent header verification workers
+	// used when verifying batches of headers. Zero means GOMAXPROCS.
+	VerifyWorkers int `toml:",omitempty"`
+
 	// When set, notifications sent by the remote sealer will
 	// be block header JSON objects instead of work package arrays.
 	NotifyFull bool
@@ -579,6 +583,8 @@ type Ethash struct {
 	hashrate metrics.Meter // Meter tracking the average hashrate
 	remote   *remoteSealer
 
+	clockLag atomic.Int64 // Measured lag of the local clock, extending the future block tolerance
+
 	// The fields below are hooks for testing
 	shared    *Ethash       // Shared PoW verifier to avoid cache regeneration
 	fakeFail  uint64        // Block number which fails PoW check even in fake mode
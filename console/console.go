@@ -57,6 +57,7 @@ const DefaultPrompt = "> "
 // JavaScript console.
 type Config struct {
 	DataDir  string              // Data directory to store the console history at
+	History  string              // File within the data directory to store the history in (defaults to HistoryFile)
 	DocRoot  string              // Filesystem path from where to load JavaScript files from
 	Client   *rpc.Client         // RPC client to execute Ethereum requests through
 	Prompt   string              // Input prompt prefix string (defaults to DefaultPrompt)
@@ -98,6 +99,9 @@ func New(config Config) (*Console, error) {
 	if config.Printer == nil {
 		config.Printer = colorable.NewColorableStdout()
 	}
+	if config.History == "" {
+		config.History = HistoryFile
+	}
 
 	// Initialize the console and return
 	console := &Console{
@@ -106,7 +110,7 @@ func New(config Config) (*Console, error) {
 		prompt:             config.Prompt,
 		prompter:           config.Prompter,
 		printer:            config.Printer,
-		histPath:           filepath.Join(config.DataDir, HistoryFile),
+		histPath:           filepath.Join(config.DataDir, config.History),
 		interactiveStopped: make(chan struct{}),
 		stopInteractiveCh:  make(chan struct{}),
 		signalReceived:     make(chan struct{}, 1),
@@ -143,6 +147,7 @@ func (c *Console) init(preload []string) error {
 	c.jsre.Do(func(vm *goja.Runtime) {
 		c.initAdmin(vm, bridge)
 		c.initPersonal(vm, bridge)
+		c.initWatch(vm)
 	})
 
 	// Preload JavaScript files.
@@ -239,6 +239,27 @@ func TestPreload(t *testing.T) {
 	}
 }
 
+// Tests that addresses from the node's address book can be passed to the
+// console's web3 methods by name.
+func TestAddressBookNames(t *testing.T) {
+	tester := newTester(t, nil)
+	defer tester.Close(t)
+
+	tester.console.Evaluate(fmt.Sprintf("admin.addressBook.add('alice', '%s')", testAddress))
+	tester.output.Reset()
+
+	tester.console.Evaluate("eth.getBalance('@alice')")
+	if output := tester.output.String(); strings.Contains(output, "Error") || !strings.Contains(output, "0") {
+		t.Fatalf("named address lookup failed: have %s", output)
+	}
+	tester.output.Reset()
+
+	tester.console.Evaluate("eth.getBalance('@bob')")
+	if output := tester.output.String(); !strings.Contains(output, "Error") {
+		t.Fatalf("unknown name accepted: have %s", output)
+	}
+}
+
 // Tests that the JavaScript objects returned by statement executions are properly
 // pretty printed instead of just displaying "[object]".
 func TestPrettyPrint(t *testing.T) {
@@ -0,0 +1,297 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package console
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/internal/jsre"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// watchTimeout bounds the RPC calls made while refreshing a live view.
+	watchTimeout = 10 * time.Second
+
+	// watchHeadHistory is the number of recent heads remembered to locate the
+	// common ancestor of a reorg.
+	watchHeadHistory = 128
+
+	// watchTxLimit is the maximum number of transactions of an address tracked
+	// until their inclusion.
+	watchTxLimit = 64
+)
+
+var errWatchUnsupported = errors.New("live views need a websocket or IPC connection")
+
+// watchHead is a chain head as shown by chain.watch.
+type watchHead struct {
+	Number     hexutil.Uint64 `json:"number"`
+	Hash       common.Hash    `json:"hash"`
+	ParentHash common.Hash    `json:"parentHash"`
+	Miner      common.Address `json:"miner"`
+	GasUsed    hexutil.Uint64 `json:"gasUsed"`
+	GasLimit   hexutil.Uint64 `json:"gasLimit"`
+	Time       hexutil.Uint64 `json:"timestamp"`
+}
+
+// watchTx is a pooled transaction as shown by txpool.watch.
+type watchTx struct {
+	Hash     common.Hash     `json:"hash"`
+	From     common.Address  `json:"from"`
+	To       *common.Address `json:"to"`
+	Nonce    hexutil.Uint64  `json:"nonce"`
+	Value    *hexutil.Big    `json:"value"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+}
+
+// headTracker follows the heads of the chain, detecting reorgs.
+type headTracker struct {
+	last *watchHead
+	seen map[common.Hash]uint64 // Numbers of the recent heads and their ancestors
+}
+
+func newHeadTracker() *headTracker {
+	return &headTracker{seen: make(map[common.Hash]uint64)}
+}
+
+// update records a new head, returning the number of previously seen heads it
+// dropped from the chain and the number of their common ancestor. The fetch
+// function retrieves unknown ancestors of the new head.
+func (t *headTracker) update(head *watchHead, fetch func(common.Hash) (*watchHead, error)) (dropped uint64, ancestor uint64) {
+	defer func() {
+		t.last = head
+		t.seen[head.Hash] = uint64(head.Number)
+		for hash, number := range t.seen {
+			if number+watchHeadHistory < uint64(head.Number) {
+				delete(t.seen, hash)
+			}
+		}
+	}()
+	if t.last == nil || head.ParentHash == t.last.Hash {
+		return 0, 0
+	}
+	// The new head doesn't extend the last one, walk back its ancestors until
+	// reaching a known block.
+	parent := head.ParentHash
+	for i := 0; i < watchHeadHistory; i++ {
+		if number, ok := t.seen[parent]; ok {
+			if number >= uint64(t.last.Number) {
+				return 0, 0 // Missed some heads, but no reorg
+			}
+			return uint64(t.last.Number) - number, number
+		}
+		block, err := fetch(parent)
+		if err != nil || block == nil {
+			break
+		}
+		t.seen[block.Hash] = uint64(block.Number)
+		parent = block.ParentHash
+	}
+	// No common ancestor found, report the heads above the new one as dropped.
+	if uint64(head.Number) <= uint64(t.last.Number) {
+		return uint64(t.last.Number) - uint64(head.Number) + 1, uint64(head.Number) - 1
+	}
+	return 0, 0
+}
+
+// initWatch adds the live views of the chain and of the transaction pool.
+func (c *Console) initWatch(vm *goja.Runtime) {
+	chain := getObject(vm, "chain")
+	if chain == nil {
+		chain = vm.NewObject()
+		vm.Set("chain", chain)
+	}
+	chain.Set("watch", jsre.MakeCallback(vm, c.watchChain))
+
+	if txpool := getObject(vm, "txpool"); txpool != nil {
+		txpool.Set("watch", jsre.MakeCallback(vm, c.watchTxPool))
+	}
+}
+
+// watchChain implements chain.watch(), printing the new heads of the chain as
+// they arrive, along with the reorgs replacing previous ones, until the user
+// interrupts it.
+func (c *Console) watchChain(call jsre.Call) (goja.Value, error) {
+	heads := make(chan *watchHead, 16)
+	sub, err := c.subscribe(heads, "newHeads")
+	if err != nil {
+		return nil, err
+	}
+	defer sub.Unsubscribe()
+
+	fmt.Fprintln(c.printer, "Watching new chain heads, press Ctrl-C to stop")
+	tracker := newHeadTracker()
+	for {
+		select {
+		case head := <-heads:
+			last := tracker.last
+			if dropped, ancestor := tracker.update(head, c.headByHash); dropped > 0 {
+				fmt.Fprintf(c.printer, "--- reorg: %d block(s) dropped above #%d\n", dropped, ancestor)
+			}
+			fmt.Fprintf(c.printer, "#%-9d %s  miner %s  gas %d/%d (%.1f%%)", uint64(head.Number), head.Hash.Hex(),
+				head.Miner.Hex(), uint64(head.GasUsed), uint64(head.GasLimit), 100*float64(head.GasUsed)/float64(max(uint64(head.GasLimit), 1)))
+			if last != nil && head.Time >= last.Time {
+				fmt.Fprintf(c.printer, "  +%ds", uint64(head.Time-last.Time))
+			}
+			fmt.Fprintln(c.printer)
+
+		case err := <-sub.Err():
+			return nil, err
+		case <-c.signalReceived:
+			return goja.Undefined(), nil
+		case <-c.stopped:
+			return goja.Undefined(), nil
+		}
+	}
+}
+
+// watchTxPool implements txpool.watch(address), printing the transactions of the
+// address entering the pool and their inclusion, along with the number of its
+// pending and queued transactions, until the user interrupts it.
+func (c *Console) watchTxPool(call jsre.Call) (goja.Value, error) {
+	if len(call.Arguments) != 1 || !common.IsHexAddress(call.Argument(0).String()) {
+		return nil, errors.New("usage: txpool.watch(<address>)")
+	}
+	addr := common.HexToAddress(call.Argument(0).String())
+
+	txs := make(chan *watchTx, 64)
+	filter := fmt.Sprintf("from == %s || to == %s", addr.Hex(), addr.Hex())
+	txSub, err := c.subscribe(txs, "newPendingTransactions", true, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer txSub.Unsubscribe()
+
+	heads := make(chan *watchHead, 16)
+	headSub, err := c.subscribe(heads, "newHeads")
+	if err != nil {
+		return nil, err
+	}
+	defer headSub.Unsubscribe()
+
+	fmt.Fprintf(c.printer, "Watching pool activity of %s, press Ctrl-C to stop\n", addr.Hex())
+	var (
+		tracked         []common.Hash
+		pending, queued = -1, -1
+	)
+	status := func() {
+		p, q, err := c.poolContentFrom(addr)
+		if err != nil {
+			fmt.Fprintf(c.printer, "pool status unavailable: %v\n", err)
+			return
+		}
+		if p != pending || q != queued {
+			pending, queued = p, q
+			fmt.Fprintf(c.printer, "pool: %d pending, %d queued\n", pending, queued)
+		}
+	}
+	status()
+	for {
+		select {
+		case tx := <-txs:
+			to := "contract creation"
+			if tx.To != nil {
+				to = "to " + tx.To.Hex()
+			}
+			fmt.Fprintf(c.printer, "+ %s  nonce %d  from %s  %s  value %s  gas price %s\n", tx.Hash.Hex(),
+				uint64(tx.Nonce), tx.From.Hex(), to, decimal(tx.Value), decimal(tx.GasPrice))
+			if len(tracked) < watchTxLimit {
+				tracked = append(tracked, tx.Hash)
+			}
+			status()
+
+		case head := <-heads:
+			remaining := tracked[:0]
+			for _, hash := range tracked {
+				var receipt *struct {
+					Status hexutil.Uint64 `json:"status"`
+				}
+				if err := c.call(&receipt, "eth_getTransactionReceipt", hash); err != nil || receipt == nil {
+					remaining = append(remaining, hash)
+					continue
+				}
+				fmt.Fprintf(c.printer, "= %s  included in #%d  status %d\n", hash.Hex(), uint64(head.Number), uint64(receipt.Status))
+			}
+			tracked = remaining
+			status()
+
+		case err := <-txSub.Err():
+			return nil, err
+		case err := <-headSub.Err():
+			return nil, err
+		case <-c.signalReceived:
+			return goja.Undefined(), nil
+		case <-c.stopped:
+			return goja.Undefined(), nil
+		}
+	}
+}
+
+// decimal formats an optional quantity in base 10.
+func decimal(n *hexutil.Big) string {
+	if n == nil {
+		return "-"
+	}
+	return n.ToInt().String()
+}
+
+// subscribe opens a subscription in the eth namespace.
+func (c *Console) subscribe(channel interface{}, args ...interface{}) (*rpc.ClientSubscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), watchTimeout)
+	defer cancel()
+
+	sub, err := c.client.EthSubscribe(ctx, channel, args...)
+	if errors.Is(err, rpc.ErrNotificationsUnsupported) {
+		return nil, errWatchUnsupported
+	}
+	return sub, err
+}
+
+// call performs an RPC call bounded by the watch timeout.
+func (c *Console) call(result interface{}, method string, args ...interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), watchTimeout)
+	defer cancel()
+
+	return c.client.CallContext(ctx, result, method, args...)
+}
+
+// headByHash retrieves the header of the block with the given hash.
+func (c *Console) headByHash(hash common.Hash) (*watchHead, error) {
+	var head *watchHead
+	if err := c.call(&head, "eth_getBlockByHash", hash, false); err != nil {
+		return nil, err
+	}
+	return head, nil
+}
+
+// poolContentFrom returns the number of pending and queued transactions of the
+// address in the pool.
+func (c *Console) poolContentFrom(addr common.Address) (int, int, error) {
+	var content map[string]map[string]json.RawMessage
+	if err := c.call(&content, "txpool_contentFrom", addr); err != nil {
+		return 0, 0, err
+	}
+	return len(content["pending"]), len(content["queued"]), nil
+}
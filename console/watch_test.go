@@ -0,0 +1,69 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package console
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestHeadTrackerReorgs(t *testing.T) {
+	// Build a canonical chain 1..5 and a side chain forking off block 3
+	blocks := make(map[common.Hash]*watchHead)
+	makeChain := func(parent *watchHead, n int, salt byte) []*watchHead {
+		var chain []*watchHead
+		for i := 0; i < n; i++ {
+			head := &watchHead{Number: parent.Number + 1, ParentHash: parent.Hash}
+			head.Hash = common.Hash{salt, byte(head.Number)}
+			blocks[head.Hash] = head
+			chain = append(chain, head)
+			parent = head
+		}
+		return chain
+	}
+	genesis := &watchHead{Hash: common.Hash{0xff}}
+	blocks[genesis.Hash] = genesis
+	canon := makeChain(genesis, 5, 1)
+	side := makeChain(canon[2], 4, 2)
+
+	fetch := func(hash common.Hash) (*watchHead, error) {
+		if block, ok := blocks[hash]; ok {
+			return block, nil
+		}
+		return nil, errors.New("not found")
+	}
+	tracker := newHeadTracker()
+	for _, head := range canon {
+		if dropped, _ := tracker.update(head, fetch); dropped != 0 {
+			t.Fatalf("block %d: unexpected reorg of %d blocks", head.Number, dropped)
+		}
+	}
+	// Switching to the side chain must report the reorg with the right ancestor
+	dropped, ancestor := tracker.update(side[1], fetch)
+	if dropped != 2 || ancestor != 3 {
+		t.Fatalf("wrong reorg: have %d dropped above %d, want 2 above 3", dropped, ancestor)
+	}
+	// Missing heads of the same chain isn't a reorg
+	if dropped, _ := tracker.update(side[3], fetch); dropped != 0 {
+		t.Fatalf("unexpected reorg of %d blocks", dropped)
+	}
+	if tracker.last != side[3] {
+		t.Fatalf("wrong last head: have %d, want %d", tracker.last.Number, side[3].Number)
+	}
+}
@@ -18,11 +18,13 @@
 package core
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"math/big"
 	"runtime"
+	"runtime/pprof"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -107,6 +109,8 @@ const (
 	maxTimeFutureBlocks = 30
 	TriesInMemory       = 128
 
+	maxAutoPrefetchDistance = 8 // Maximum number of followup blocks to prefetch if not configured
+
 	// BlockChainVersion ensures that an incompatible database forces a resync from scratch.
 	//
 	// Changelog:
@@ -138,6 +142,9 @@ const (
 type CacheConfig struct {
 	TrieCleanLimit      int           // Memory allowance (MB) to use for caching trie nodes in memory
 	TrieCleanNoPrefetch bool          // Whether to disable heuristic state prefetching for followup blocks
+	PrefetchDistance    int           // Number of followup blocks to prefetch state for (0 = autodetect)
+	PrefetchWorkers     int           // Number of concurrent state prefetcher goroutines (0 = autodetect)
+	TrieWorkers         int           // Number of storage tries hashed and committed concurrently (0 = autodetect)
 	TrieDirtyLimit      int           // Memory limit (MB) at which to start flushing dirty trie nodes to disk
 	TrieDirtyDisabled   bool          // Whether to disable trie write caching and GC altogether (archive node)
 	TrieTimeLimit       time.Duration // Time limit after which to flush the current in-memory trie to disk
@@ -146,13 +153,16 @@ type CacheConfig struct {
 	StateHistory        uint64        // Number of blocks from head whose state histories are reserved.
 	StateScheme         string        // Scheme used to store ethereum states and merkle tree nodes on top
 
+	TxLookupAddresses []common.Address // Addresses whose transactions are exclusively indexed (nil = all transactions)
+	LogIndex          bool             // Whether to index the logs of imported blocks by emitting address
+
 	SnapshotNoBuild bool // Whether the background generation is allowed
 	SnapshotWait    bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
 }
 
 // triedbConfig derives the configures for trie database.
-func (c *CacheConfig) triedbConfig() *triedb.Config {
-	config := &triedb.Config{Preimages: c.Preimages}
+func (c *CacheConfig) triedbConfig(isBinary bool) *triedb.Config {
+	config := &triedb.Config{Preimages: c.Preimages, IsBinary: isBinary}
 	if c.StateScheme == rawdb.HashScheme {
 		config.HashDB = &hashdb.Config{
 			CleanCacheSize: c.TrieCleanLimit * 1024 * 1024,
@@ -168,6 +178,21 @@ func (c *CacheConfig) triedbConfig() *triedb.Config {
 	return config
 }
 
+// prefetchLimits returns the number of followup blocks to prefetch state for
+// and the number of prefetchers allowed to run concurrently, deriving them from
+// the available CPUs if not configured explicitly.
+func (c *CacheConfig) prefetchLimits() (distance int, workers int) {
+	workers = c.PrefetchWorkers
+	if workers <= 0 {
+		workers = max(runtime.GOMAXPROCS(0)/4, 1)
+	}
+	distance = c.PrefetchDistance
+	if distance <= 0 {
+		distance = min(workers, maxAutoPrefetchDistance)
+	}
+	return distance, workers
+}
+
 // defaultCacheConfig are the default caching values if none are specified by the
 // user (also used during testing).
 var defaultCacheConfig = &CacheConfig{
@@ -221,6 +246,7 @@ type BlockChain struct {
 	triedb        *triedb.Database                 // The database handler for maintaining trie nodes.
 	stateCache    state.Database                   // State database to reuse between imports (contains state cache)
 	txIndexer     *txIndexer                       // Transaction indexer, might be nil if not enabled
+	txFilter      *txLookupFilter                  // Filter of the indexed transactions, nil if all are indexed
 
 	hc            *HeaderChain
 	rmLogsFeed    event.Feed
@@ -229,6 +255,7 @@ type BlockChain struct {
 	chainHeadFeed event.Feed
 	logsFeed      event.Feed
 	blockProcFeed event.Feed
+	reorgHeldFeed event.Feed
 	scope         event.SubscriptionScope
 	genesisBlock  *types.Block
 
@@ -262,6 +289,16 @@ type BlockChain struct {
 	forker     *ForkChoice
 	vmConfig   vm.Config
 
+	prefetchDistance int           // Number of followup blocks to prefetch state for
+	prefetchSlots    chan struct{} // Semaphore limiting the concurrently running prefetchers
+	prefetchPaused   atomic.Bool   // Whether speculative prefetching is suspended due to local load
+
+	maxReorgDepth  atomic.Uint64 // Maximum number of blocks reorged without operator confirmation (0 = no limit)
+	heldReorgs     []*HeldReorg  // Refused reorgs awaiting operator confirmation
+	heldReorgsLock sync.Mutex    // Lock protecting the held reorgs
+
+	procStats blockStatsRing // Timing breakdown of the recently imported blocks
+
 	artificialFinalityNoDisable     *int32 // manual override prevents disabling artificial finality feature activation
 	artificialFinalityEnabledStatus int32  // toggles artificial finality features; will be always 1 if artificialFinalityForce=1
 }
@@ -273,8 +310,22 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *genesis
 	if cacheConfig == nil {
 		cacheConfig = defaultCacheConfig
 	}
+	// The experimental binary trie layout is only implemented in the hash
+	// scheme, and the snapshot is bound to the merkle-patricia layout.
+	isBinary := IsBinaryStateLayout(db, genesis)
+	if isBinary {
+		if cacheConfig.StateScheme == rawdb.PathScheme {
+			return nil, errors.New("binary trie state layout requires the hash state scheme")
+		}
+		if cacheConfig.SnapshotLimit > 0 {
+			log.Warn("Disabling snapshots, unsupported by the binary trie state layout")
+			config := *cacheConfig
+			config.SnapshotLimit = 0
+			cacheConfig = &config
+		}
+	}
 	// Open trie database with provided config
-	triedb := triedb.NewDatabase(db, cacheConfig.triedbConfig())
+	triedb := triedb.NewDatabase(db, cacheConfig.triedbConfig(isBinary))
 
 	// Setup the genesis block, commit the provided genesis specification
 	// to database if the genesis block is not present yet, or load the
@@ -299,7 +350,7 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *genesis
 		triedb:        triedb,
 		triegc:        prque.New[int64, common.Hash](nil),
 		quit:          make(chan struct{}),
-		chainmu:       syncx.NewClosableMutex(),
+		chainmu:       syncx.NewWatchedClosableMutex("chain"),
 		bodyCache:     lru.NewCache[common.Hash, *types.Body](bodyCacheLimit),
 		bodyRLPCache:  lru.NewCache[common.Hash, rlp.RawValue](bodyCacheLimit),
 		receiptsCache: lru.NewCache[common.Hash, []*types.Receipt](receiptsCacheLimit),
@@ -308,12 +359,15 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *genesis
 		futureBlocks:  lru.NewCache[common.Hash, *types.Block](maxFutureBlocks),
 		engine:        engine,
 		vmConfig:      vmConfig,
+		txFilter:      newTxLookupFilter(chainConfig.GetChainID(), cacheConfig.TxLookupAddresses),
 	}
 	bc.flushInterval.Store(int64(cacheConfig.TrieTimeLimit))
 	bc.forker = NewForkChoice(bc, shouldPreserve)
 	bc.stateCache = state.NewDatabaseWithNodeDB(bc.db, bc.triedb)
 	bc.validator = NewBlockValidator(chainConfig, bc, engine)
 	bc.prefetcher = newStatePrefetcher(chainConfig, bc, engine)
+	distance, workers := cacheConfig.prefetchLimits()
+	bc.prefetchDistance, bc.prefetchSlots = distance, make(chan struct{}, workers)
 	bc.processor = NewStateProcessor(chainConfig, bc, engine)
 
 	var err error
@@ -778,6 +832,10 @@ func (bc *BlockChain) setHeadBeyondRoot(head uint64, time uint64, root common.Ha
 			rawdb.DeleteBody(db, hash, num)
 			rawdb.DeleteReceipts(db, hash, num)
 		}
+		// Remove the log index entries, which live in the active store regardless
+		if addresses, ok := rawdb.ReadBlockLogAddresses(bc.db, hash, num); ok {
+			rawdb.DeleteBlockLogs(db, hash, num, addresses)
+		}
 		// Todo(rjl493456442) txlookup, bloombits, etc
 	}
 	// If SetHead was only called as a chain reparation method, try to skip
@@ -938,7 +996,12 @@ func (bc *BlockChain) writeHeadBlock(block *types.Block) {
 	rawdb.WriteHeadHeaderHash(batch, block.Hash())
 	rawdb.WriteHeadFastBlockHash(batch, block.Hash())
 	rawdb.WriteCanonicalHash(batch, block.Hash(), block.NumberU64())
-	rawdb.WriteTxLookupEntriesByBlock(batch, block)
+	if bc.txFilter != nil {
+		signer := types.MakeSigner(bc.chainConfig, block.Number(), block.Time())
+		rawdb.WriteTxLookupEntries(batch, block.NumberU64(), bc.txFilter.lookupHashes(signer, block))
+	} else {
+		rawdb.WriteTxLookupEntriesByBlock(batch, block)
+	}
 	rawdb.WriteHeadBlockHash(batch, block.Hash())
 
 	// Flush the whole batch into the disk, exit the node if failed
@@ -1052,6 +1115,12 @@ func (bc *BlockChain) StopInsert() {
 	bc.procInterrupt.Store(true)
 }
 
+// SetPrefetchPaused suspends or resumes the speculative execution of followup
+// blocks during import, trading import speed for lower CPU and IO usage.
+func (bc *BlockChain) SetPrefetchPaused(paused bool) {
+	bc.prefetchPaused.Store(paused)
+}
+
 // insertStopped returns true after StopInsert has been called.
 func (bc *BlockChain) insertStopped() bool {
 	return bc.procInterrupt.Load()
@@ -1217,7 +1286,7 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 				continue
 			}
 			rawdb.DeleteCanonicalHash(batch, block.NumberU64())
-			rawdb.DeleteBlockWithoutNumber(batch, block.Hash(), block.NumberU64())
+			rawdb.DeleteBlockWithoutNumber(batch, bc.db, block.Hash(), block.NumberU64())
 		}
 		// Delete side chain hash-to-number mappings.
 		for _, nh := range rawdb.ReadAllHashesInRange(bc.db, first.NumberU64(), last.NumberU64()) {
@@ -1262,6 +1331,9 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 			// Write all the data out into the database
 			rawdb.WriteBody(batch, block.Hash(), block.NumberU64(), block.Body())
 			rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), receiptChain[i])
+			if bc.cacheConfig.LogIndex {
+				rawdb.WriteReceiptLogs(batch, block.Hash(), block.NumberU64(), receiptChain[i])
+			}
 
 			// Write everything belongs to the blocks into the database. So that
 			// we can ensure all components of body is completed(body, receipts)
@@ -1370,6 +1442,9 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	rawdb.WriteTd(blockBatch, block.Hash(), block.NumberU64(), externTd)
 	rawdb.WriteBlock(blockBatch, block)
 	rawdb.WriteReceipts(blockBatch, block.Hash(), block.NumberU64(), receipts)
+	if bc.cacheConfig.LogIndex {
+		rawdb.WriteReceiptLogs(blockBatch, block.Hash(), block.NumberU64(), receipts)
+	}
 	rawdb.WritePreimages(blockBatch, state.Preimages())
 	if err := blockBatch.Write(); err != nil {
 		log.Crit("Failed to write block into disk", "err", err)
@@ -1549,7 +1624,16 @@ func (bc *BlockChain) InsertChain(chain types.Blocks) (int, error) {
 		return 0, errChainStopped
 	}
 	defer bc.chainmu.Unlock()
-	return bc.insertChain(chain, true, true)
+
+	// Label the import, so profiles attribute the time spent to it
+	var (
+		n   int
+		err error
+	)
+	pprof.Do(context.Background(), pprof.Labels("subsystem", "import"), func(context.Context) {
+		n, err = bc.insertChain(chain, true, true)
+	})
+	return n, err
 }
 
 // insertChain is the internal implementation of InsertChain, which assumes that
@@ -1698,6 +1782,14 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals, setHead bool)
 		}
 	}()
 
+	// Interrupt any followup block prefetchers still running when import ends
+	prefetches := make(map[int]*atomic.Bool)
+	defer func() {
+		for _, interrupt := range prefetches {
+			interrupt.Store(true)
+		}
+	}()
+
 	for ; block != nil && err == nil || errors.Is(err, ErrKnownBlock); block, err = it.next() {
 		// If the chain is terminating, stop processing blocks
 		if bc.insertStopped() {
@@ -1759,35 +1851,64 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals, setHead bool)
 		if err != nil {
 			return it.index, err
 		}
+		statedb.SetTrieWorkers(bc.cacheConfig.TrieWorkers)
 
 		// Enable prefetching to pull in trie node paths while processing transactions
 		statedb.StartPrefetcher("chain")
 		activeState = statedb
 
-		// If we have a followup block, run that against the current state to pre-cache
+		// The current block (and any skipped before it) is being processed now,
+		// stop prefetching them as it's useless from this point onward.
+		for index, interrupt := range prefetches {
+			if index <= it.index {
+				interrupt.Store(true)
+				delete(prefetches, index)
+			}
+		}
+		// If we have followup blocks, run them against the current state to pre-cache
 		// transactions and probabilistically some of the account/storage trie nodes.
-		var followupInterrupt atomic.Bool
-		if !bc.cacheConfig.TrieCleanNoPrefetch {
-			if followup, err := it.peek(); followup != nil && err == nil {
-				throwaway, _ := state.New(parent.Root, bc.stateCache, bc.snaps)
+		if !bc.cacheConfig.TrieCleanNoPrefetch && !bc.prefetchPaused.Load() {
+			for offset := 1; offset <= bc.prefetchDistance; offset++ {
+				if _, ok := prefetches[it.index+offset]; ok {
+					continue
+				}
+				followup, err := it.peekAt(offset)
+				if followup == nil || err != nil {
+					break
+				}
+				interrupt := new(atomic.Bool)
+				prefetches[it.index+offset] = interrupt
 
+				throwaway, _ := state.New(parent.Root, bc.stateCache, bc.snaps)
 				go func(start time.Time, followup *types.Block, throwaway *state.StateDB) {
-					bc.prefetcher.Prefetch(followup, throwaway, bc.vmConfig, &followupInterrupt)
+					bc.prefetchSlots <- struct{}{}
+					defer func() { <-bc.prefetchSlots }()
 
+					if !interrupt.Load() {
+						bc.prefetcher.Prefetch(followup, throwaway, bc.vmConfig, interrupt)
+					}
 					blockPrefetchExecuteTimer.Update(time.Since(start))
-					if followupInterrupt.Load() {
+					if interrupt.Load() {
 						blockPrefetchInterruptMeter.Mark(1)
 					}
 				}(time.Now(), followup, throwaway)
 			}
 		}
 
+		// Wait for the background sender recovery to finish for this block
+		sstart := time.Now()
+		signer := types.MakeSigner(bc.chainConfig, block.Number(), block.Time())
+		for _, tx := range block.Transactions() {
+			types.Sender(signer, tx)
+		}
+		stime := time.Since(sstart)
+		blockSendersTimer.Update(stime)
+
 		// Process block using the parent state as reference point
 		pstart := time.Now()
 		receipts, logs, usedGas, err := bc.processor.Process(block, statedb, bc.vmConfig)
 		if err != nil {
 			bc.reportBlock(block, receipts, err)
-			followupInterrupt.Store(true)
 			return it.index, err
 		}
 		ptime := time.Since(pstart)
@@ -1795,7 +1916,6 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals, setHead bool)
 		vstart := time.Now()
 		if err := bc.validator.ValidateState(block, statedb, receipts, usedGas); err != nil {
 			bc.reportBlock(block, receipts, err)
-			followupInterrupt.Store(true)
 			return it.index, err
 		}
 		vtime := time.Since(vstart)
@@ -1828,7 +1948,6 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals, setHead bool)
 		} else {
 			status, err = bc.writeBlockAndSetHead(block, receipts, logs, statedb, false)
 		}
-		followupInterrupt.Store(true)
 		if err != nil {
 			return it.index, err
 		}
@@ -1838,9 +1957,12 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals, setHead bool)
 		snapshotCommitTimer.Update(statedb.SnapshotCommits) // Snapshot commits are complete, we can mark them
 		triedbCommitTimer.Update(statedb.TrieDBCommits)     // Trie database commits are complete, we can mark them
 
-		blockWriteTimer.Update(time.Since(wstart) - statedb.AccountCommits - statedb.StorageCommits - statedb.SnapshotCommits - statedb.TrieDBCommits)
+		wtime := time.Since(wstart)
+		blockWriteTimer.Update(wtime - statedb.AccountCommits - statedb.StorageCommits - statedb.SnapshotCommits - statedb.TrieDBCommits)
 		blockInsertTimer.UpdateSince(start)
 
+		bc.procStats.add(newBlockProcessingStats(block, usedGas, statedb, stime, ptime, vtime, wtime, time.Since(start)))
+
 		// Report the import stats before returning the various results
 		stats.processed++
 		stats.usedGas += usedGas
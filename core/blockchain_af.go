@@ -213,6 +213,18 @@ func ecbp1100PolynomialV(x *big.Int) *big.Int {
 	return out
 }
 
+// ECBP1100Gravity returns the factor by which the total difficulty of a proposed
+// chain segment must exceed that of the local one to replace a local segment
+// spanning the given number of seconds, while MESS is active. It ranges from 1
+// for recent segments to 31 for segments older than about seven hours.
+func ECBP1100Gravity(span uint64) float64 {
+	gravity, _ := new(big.Float).Quo(
+		new(big.Float).SetInt(ecbp1100PolynomialV(new(big.Int).SetUint64(span))),
+		new(big.Float).SetInt(ecbp1100PolynomialVCurveFunctionDenominator),
+	).Float64()
+	return gravity
+}
+
 var big2 = big.NewInt(2)
 var big3 = big.NewInt(3)
 
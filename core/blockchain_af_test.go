@@ -270,6 +270,18 @@ func TestEcbp1100PolynomialV(t *testing.T) {
 	}
 }
 
+func TestECBP1100Gravity(t *testing.T) {
+	if g := ECBP1100Gravity(0); g != 1 {
+		t.Errorf("wrong gravity for zero span: %f", g)
+	}
+	if g := ECBP1100Gravity(1e9); g != 31 {
+		t.Errorf("wrong gravity for capped span: %f", g)
+	}
+	if g := ECBP1100Gravity(1000 * 13); g < 16 || g >= 17 {
+		t.Errorf("wrong gravity for 1000 blocks: %f", g)
+	}
+}
+
 func TestPlot_ecbp1100PolynomialV(t *testing.T) {
 	t.Skip("This test plots a graph of the ECBP1100 polynomial curve.")
 	p := plot.New()
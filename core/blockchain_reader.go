@@ -353,7 +353,12 @@ func (bc *BlockChain) State() (*state.StateDB, error) {
 
 // StateAt returns a new mutable state based on a particular point in time.
 func (bc *BlockChain) StateAt(root common.Hash) (*state.StateDB, error) {
-	return state.New(root, bc.stateCache, bc.snaps)
+	statedb, err := state.New(root, bc.stateCache, bc.snaps)
+	if err != nil {
+		return nil, err
+	}
+	statedb.SetTrieWorkers(bc.cacheConfig.TrieWorkers)
+	return statedb, nil
 }
 
 // Config retrieves the chain's fork configuration.
@@ -0,0 +1,158 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// maxHeldReorgs is the maximum number of refused reorgs awaiting confirmation.
+// Older ones are forgotten first.
+const maxHeldReorgs = 16
+
+var blockReorgHeldMeter = metrics.NewRegisteredMeter("chain/reorg/held", nil)
+
+// HeldReorg is a reorg deeper than the configured limit, which was refused and
+// awaits the confirmation of the operator.
+type HeldReorg struct {
+	Ancestor       common.Hash `json:"ancestor"`       // Common ancestor of the two branches
+	AncestorNumber uint64      `json:"ancestorNumber"` // Number of the common ancestor
+	Current        common.Hash `json:"current"`        // Local head when the reorg was last refused
+	Proposed       common.Hash `json:"proposed"`       // Head of the competing branch
+	ProposedNumber uint64      `json:"proposedNumber"` // Number of the head of the competing branch
+	Depth          uint64      `json:"depth"`          // Number of canonical blocks the reorg would drop
+	FirstSeen      time.Time   `json:"firstSeen"`      // Time the competing branch was first refused
+}
+
+// SetMaxReorgDepth sets the maximum number of canonical blocks a reorg may drop
+// without the confirmation of the operator. Zero means no limit.
+func (bc *BlockChain) SetMaxReorgDepth(depth uint64) {
+	bc.maxReorgDepth.Store(depth)
+}
+
+// MaxReorgDepth returns the maximum depth of automatic reorgs (0 = no limit).
+func (bc *BlockChain) MaxReorgDepth() uint64 {
+	return bc.maxReorgDepth.Load()
+}
+
+// SubscribeReorgHeldEvent registers a subscription of ReorgHeldEvent.
+func (bc *BlockChain) SubscribeReorgHeldEvent(ch chan<- ReorgHeldEvent) event.Subscription {
+	return bc.scope.Track(bc.reorgHeldFeed.Subscribe(ch))
+}
+
+// HeldReorgs returns the refused reorgs awaiting confirmation.
+func (bc *BlockChain) HeldReorgs() []HeldReorg {
+	bc.heldReorgsLock.Lock()
+	defer bc.heldReorgsLock.Unlock()
+
+	held := make([]HeldReorg, len(bc.heldReorgs))
+	for i, reorg := range bc.heldReorgs {
+		held[i] = *reorg
+	}
+	return held
+}
+
+// reorgHeld reports whether any refused reorg awaits confirmation, halting head
+// updates.
+func (bc *BlockChain) reorgHeld() bool {
+	bc.heldReorgsLock.Lock()
+	defer bc.heldReorgsLock.Unlock()
+
+	return len(bc.heldReorgs) > 0
+}
+
+// holdReorg records a refused reorg. Reorgs onto the same competing branch are
+// tracked as one, following the head of the branch, and only alerted about once.
+func (bc *BlockChain) holdReorg(ancestor, current, proposed *types.Header) {
+	bc.heldReorgsLock.Lock()
+	var (
+		held  *HeldReorg
+		fresh bool
+	)
+	for _, reorg := range bc.heldReorgs {
+		if reorg.Ancestor == ancestor.Hash() {
+			held = reorg
+			break
+		}
+	}
+	if held == nil {
+		fresh = true
+		held = &HeldReorg{
+			Ancestor:       ancestor.Hash(),
+			AncestorNumber: ancestor.Number.Uint64(),
+			FirstSeen:      time.Now(),
+		}
+		if len(bc.heldReorgs) >= maxHeldReorgs {
+			bc.heldReorgs = bc.heldReorgs[1:]
+		}
+		bc.heldReorgs = append(bc.heldReorgs, held)
+	}
+	held.Current = current.Hash()
+	held.Proposed = proposed.Hash()
+	held.ProposedNumber = proposed.Number.Uint64()
+	held.Depth = current.Number.Uint64() - ancestor.Number.Uint64()
+	event := *held
+	bc.heldReorgsLock.Unlock()
+
+	if !fresh {
+		log.Debug("Refused reorg onto held branch", "depth", event.Depth, "proposed", event.Proposed, "proposednumber", event.ProposedNumber)
+		return
+	}
+	blockReorgHeldMeter.Mark(1)
+	log.Error("Refused reorg deeper than the allowed limit, confirm with admin.acceptReorg",
+		"depth", event.Depth, "limit", bc.MaxReorgDepth(), "ancestor", event.AncestorNumber,
+		"current", current.Hash(), "proposed", event.Proposed, "proposednumber", event.ProposedNumber)
+	bc.reorgHeldFeed.Send(ReorgHeldEvent{Reorg: event})
+}
+
+// AcceptReorg resolves the refused reorgs, setting the block with the given hash
+// as the new chain head and resuming head updates. The block is usually the head
+// of a competing branch. Passing the current head instead rejects all competing
+// branches, and a block extending it resumes the local branch.
+func (bc *BlockChain) AcceptReorg(hash common.Hash) error {
+	block := bc.GetBlockByHash(hash)
+	if block == nil {
+		return fmt.Errorf("unknown block %x", hash)
+	}
+	if current := bc.CurrentBlock(); hash == current.Hash() {
+		log.Warn("Rejected held reorgs", "number", current.Number, "hash", hash)
+	} else {
+		ancestor, err := bc.forker.CommonAncestor(current, block.Header())
+		if err != nil {
+			return err
+		}
+		if ancestor.Hash() == block.Hash() {
+			return errors.New("block is already canonical")
+		}
+		if _, err := bc.SetCanonical(block); err != nil {
+			return err
+		}
+		log.Warn("Accepted reorg", "ancestor", ancestor.Number, "number", block.Number(), "hash", hash)
+	}
+	bc.heldReorgsLock.Lock()
+	bc.heldReorgs = nil
+	bc.heldReorgsLock.Unlock()
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+	"github.com/ethereum/go-ethereum/params/vars"
+)
+
+func TestMaxReorgDepth(t *testing.T) {
+	var (
+		engine  = ethash.NewFaker()
+		genesis = &genesisT.Genesis{
+			BaseFee: big.NewInt(vars.InitialBaseFee),
+			Config:  params.AllEthashProtocolChanges,
+		}
+	)
+	genDb, canon := makeBlockChainWithGenesis(genesis, 10, engine, canonicalSeed)
+	shallow := makeBlockChain(genesis.Config, canon[7], 4, engine, genDb, forkSeed)
+	deep := makeBlockChain(genesis.Config, canon[1], 12, engine, genDb, forkSeed)
+
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), DefaultCacheConfigWithScheme(rawdb.HashScheme), genesis, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	chain.SetMaxReorgDepth(3)
+
+	if _, err := chain.InsertChain(canon); err != nil {
+		t.Fatalf("failed to insert canonical chain: %v", err)
+	}
+	// A reorg within the limit is applied automatically
+	if _, err := chain.InsertChain(shallow); err != nil {
+		t.Fatalf("failed to insert shallow fork: %v", err)
+	}
+	if have, want := chain.CurrentBlock().Hash(), shallow[len(shallow)-1].Hash(); have != want {
+		t.Fatalf("shallow reorg not applied: head %x, want %x", have, want)
+	}
+	if held := chain.HeldReorgs(); len(held) != 0 {
+		t.Fatalf("shallow reorg held: %v", held)
+	}
+	// A deeper reorg is refused and held until accepted
+	events := make(chan ReorgHeldEvent, 1)
+	sub := chain.SubscribeReorgHeldEvent(events)
+	defer sub.Unsubscribe()
+
+	head := chain.CurrentBlock().Hash()
+	if _, err := chain.InsertChain(deep); err != nil {
+		t.Fatalf("failed to insert deep fork: %v", err)
+	}
+	if have := chain.CurrentBlock().Hash(); have != head {
+		t.Fatalf("deep reorg applied: head %x, want %x", have, head)
+	}
+	held := chain.HeldReorgs()
+	if len(held) != 1 {
+		t.Fatalf("held reorg count mismatch: have %d, want 1", len(held))
+	}
+	if held[0].Ancestor != canon[1].Hash() || held[0].Proposed != deep[len(deep)-1].Hash() || held[0].Depth != 10 {
+		t.Fatalf("held reorg mismatch: ancestor %x, proposed %x, depth %d", held[0].Ancestor, held[0].Proposed, held[0].Depth)
+	}
+	// The head is not updated along the local branch either until accepted
+	local := makeBlockChain(genesis.Config, shallow[len(shallow)-1], 2, engine, genDb, canonicalSeed)
+	if _, err := chain.InsertChain(local); err != nil {
+		t.Fatalf("failed to extend local branch: %v", err)
+	}
+	if have := chain.CurrentBlock().Hash(); have != head {
+		t.Fatalf("head updated while reorg held: head %x, want %x", have, head)
+	}
+	select {
+	case ev := <-events:
+		if ev.Reorg.Ancestor != canon[1].Hash() {
+			t.Errorf("event ancestor mismatch: have %x, want %x", ev.Reorg.Ancestor, canon[1].Hash())
+		}
+	default:
+		t.Error("no held reorg event")
+	}
+	if err := chain.AcceptReorg(common.Hash{1}); err == nil {
+		t.Error("accepted unknown block")
+	}
+	if err := chain.AcceptReorg(deep[len(deep)-1].Hash()); err != nil {
+		t.Fatalf("failed to accept reorg: %v", err)
+	}
+	if have, want := chain.CurrentBlock().Hash(), deep[len(deep)-1].Hash(); have != want {
+		t.Fatalf("accepted reorg not applied: head %x, want %x", have, want)
+	}
+	if held := chain.HeldReorgs(); len(held) != 0 {
+		t.Fatalf("accepted reorg still held: %v", held)
+	}
+}
+
+func TestMaxReorgDepthReject(t *testing.T) {
+	var (
+		engine  = ethash.NewFaker()
+		genesis = &genesisT.Genesis{
+			BaseFee: big.NewInt(vars.InitialBaseFee),
+			Config:  params.AllEthashProtocolChanges,
+		}
+	)
+	genDb, canon := makeBlockChainWithGenesis(genesis, 10, engine, canonicalSeed)
+	deep := makeBlockChain(genesis.Config, canon[1], 12, engine, genDb, forkSeed)
+	local := makeBlockChain(genesis.Config, canon[len(canon)-1], 6, engine, genDb, canonicalSeed)
+
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), DefaultCacheConfigWithScheme(rawdb.HashScheme), genesis, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	chain.SetMaxReorgDepth(3)
+
+	if _, err := chain.InsertChain(canon); err != nil {
+		t.Fatalf("failed to insert canonical chain: %v", err)
+	}
+	if _, err := chain.InsertChain(deep); err != nil {
+		t.Fatalf("failed to insert deep fork: %v", err)
+	}
+	if _, err := chain.InsertChain(local[:2]); err != nil {
+		t.Fatalf("failed to extend local branch: %v", err)
+	}
+	head := canon[len(canon)-1].Hash()
+	if have := chain.CurrentBlock().Hash(); have != head {
+		t.Fatalf("head updated while reorg held: head %x, want %x", have, head)
+	}
+	// Confirming the current head rejects the competing branch and resumes
+	// head updates along the local one
+	if err := chain.AcceptReorg(head); err != nil {
+		t.Fatalf("failed to reject reorg: %v", err)
+	}
+	if held := chain.HeldReorgs(); len(held) != 0 {
+		t.Fatalf("rejected reorg still held: %v", held)
+	}
+	if have := chain.CurrentBlock().Hash(); have != head {
+		t.Fatalf("head changed by rejection: head %x, want %x", have, head)
+	}
+	if _, err := chain.InsertChain(local[2:]); err != nil {
+		t.Fatalf("failed to extend local branch: %v", err)
+	}
+	if have, want := chain.CurrentBlock().Hash(), local[len(local)-1].Hash(); have != want {
+		t.Fatalf("local branch not resumed: head %x, want %x", have, want)
+	}
+}
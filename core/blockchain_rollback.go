@@ -0,0 +1,150 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// RollbackReport describes the effects of rolling the chain back to a block.
+type RollbackReport struct {
+	Target uint64 // Requested new head
+
+	OldHeader uint64 // Head header before the rollback
+	OldBlock  uint64 // Head block before the rollback
+	NewBlock  uint64 // Head block after the rollback, the first block with state at or below the target
+
+	Headers      uint64  // Number of canonical headers removed
+	Frozen       uint64  // Number of blocks removed from the freezer
+	Transactions uint64  // Number of transaction lookup entries removed
+	TxIndexTail  *uint64 // Oldest block with indexed transactions, nil if the index is empty
+	Snapshot     bool    // Whether the state snapshot needs to be regenerated
+}
+
+// PlanRollback reports the effects of rolling the chain back to the target
+// block, without modifying the database.
+func (bc *BlockChain) PlanRollback(target uint64) (*RollbackReport, error) {
+	var (
+		header = bc.CurrentHeader()
+		block  = bc.CurrentBlock()
+	)
+	if target >= header.Number.Uint64() {
+		return nil, fmt.Errorf("target #%d is not below the head header #%d", target, header.Number)
+	}
+	report := &RollbackReport{
+		Target:      target,
+		OldHeader:   header.Number.Uint64(),
+		OldBlock:    block.Number.Uint64(),
+		Headers:     header.Number.Uint64() - target,
+		TxIndexTail: rawdb.ReadTxIndexTail(bc.db),
+	}
+	// Find the block the head will be set to, mirroring SetHead
+	pivot := rawdb.ReadLastPivotNumber(bc.db)
+	for number := min(target, report.OldBlock); ; number-- {
+		head := bc.GetHeaderByNumber(number)
+		if head == nil || number == 0 || (pivot != nil && number <= *pivot && !bc.HasState(head.Root)) {
+			report.NewBlock = 0
+			break
+		}
+		if bc.HasState(head.Root) || bc.stateRecoverable(head.Root) {
+			report.NewBlock = number
+			break
+		}
+	}
+	if frozen, err := bc.db.Ancients(); err == nil && frozen > target+1 {
+		report.Frozen = frozen - target - 1
+	}
+	var err error
+	if report.Transactions, err = bc.forEachRolledBackTx(target, nil); err != nil {
+		return nil, err
+	}
+	if bc.snaps != nil {
+		report.Snapshot = true
+	} else if root := rawdb.ReadSnapshotRoot(bc.db); root != (common.Hash{}) {
+		report.Snapshot = true
+	}
+	return report, nil
+}
+
+// Rollback rewinds the chain to the target block like SetHead, additionally
+// removing the transaction lookup entries of all removed blocks, so that the
+// receipts, the transaction index, the snapshot and the freezer are left in a
+// consistent state.
+func (bc *BlockChain) Rollback(target uint64) (*RollbackReport, error) {
+	report, err := bc.PlanRollback(target)
+	if err != nil {
+		return nil, err
+	}
+	// Drop the transaction lookups first, as SetHead deletes the block bodies
+	batch := bc.db.NewBatch()
+	if _, err := bc.forEachRolledBackTx(target, func(hash common.Hash) error {
+		rawdb.DeleteTxLookupEntry(batch, hash)
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if err := batch.Write(); err != nil {
+		return nil, err
+	}
+	if err := bc.SetHead(target); err != nil {
+		return nil, err
+	}
+	// Cap the transaction index tail to the new head, the indexer extends the
+	// index again once the chain progresses
+	if tail := rawdb.ReadTxIndexTail(bc.db); tail != nil && *tail > target+1 {
+		rawdb.WriteTxIndexTail(bc.db, target+1)
+	}
+	report.NewBlock = bc.CurrentBlock().Number.Uint64()
+	log.Info("Rolled back chain", "target", target, "block", report.NewBlock, "headers", report.Headers, "txs", report.Transactions)
+	return report, nil
+}
+
+// forEachRolledBackTx iterates over the transactions of the canonical blocks
+// above the target, returning their number.
+func (bc *BlockChain) forEachRolledBackTx(target uint64, fn func(hash common.Hash) error) (uint64, error) {
+	var count uint64
+	for number := target + 1; number <= bc.CurrentHeader().Number.Uint64(); number++ {
+		hash := rawdb.ReadCanonicalHash(bc.db, number)
+		if hash == (common.Hash{}) {
+			break
+		}
+		body := rawdb.ReadBody(bc.db, hash, number)
+		if body == nil {
+			continue // Header-only block, no transactions indexed
+		}
+		for _, tx := range body.Transactions {
+			if fn != nil {
+				if err := fn(tx.Hash()); err != nil {
+					return count, err
+				}
+			}
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+	"github.com/ethereum/go-ethereum/params/vars"
+)
+
+func TestRollback(t *testing.T) {
+	var (
+		key, _  = crypto.GenerateKey()
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		genesis = &genesisT.Genesis{
+			Config:  params.TestChainConfig,
+			Alloc:   genesisT.GenesisAlloc{address: {Balance: big.NewInt(1000000000000000000)}},
+			BaseFee: big.NewInt(vars.InitialBaseFee),
+		}
+		engine = ethash.NewFaker()
+		nonce  = uint64(0)
+	)
+	_, blocks, _ := GenerateChainWithGenesis(genesis, engine, 10, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(nonce, common.HexToAddress("0xdeadbeef"), big.NewInt(1000), vars.TxGas, big.NewInt(10*vars.InitialBaseFee), nil), types.HomesteadSigner{}, key)
+		gen.AddTx(tx)
+		nonce++
+	})
+	db := rawdb.NewMemoryDatabase()
+	chain, err := NewBlockChain(db, DefaultCacheConfigWithScheme(rawdb.HashScheme), genesis, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	for _, block := range blocks {
+		rawdb.WriteTxLookupEntriesByBlock(db, block)
+	}
+	rawdb.WriteTxIndexTail(db, 0)
+
+	if _, err := chain.PlanRollback(10); err == nil {
+		t.Fatal("planned rollback to the current head")
+	}
+	report, err := chain.PlanRollback(6)
+	if err != nil {
+		t.Fatalf("failed to plan rollback: %v", err)
+	}
+	if report.OldHeader != 10 || report.NewBlock != 6 || report.Headers != 4 || report.Transactions != 4 {
+		t.Fatalf("preflight report mismatch: %+v", report)
+	}
+	if chain.CurrentBlock().Number.Uint64() != 10 {
+		t.Fatal("preflight modified the chain")
+	}
+	if report, err = chain.Rollback(6); err != nil {
+		t.Fatalf("failed to roll back: %v", err)
+	}
+	if head := chain.CurrentBlock().Number.Uint64(); head != 6 || report.NewBlock != 6 {
+		t.Fatalf("head mismatch: have %d (reported %d), want 6", head, report.NewBlock)
+	}
+	for _, block := range blocks {
+		tx := block.Transactions()[0]
+		removed := block.NumberU64() > 6
+		if have := rawdb.ReadTxLookupEntry(db, tx.Hash()) == nil; have != removed {
+			t.Errorf("block %d: tx lookup removed mismatch: have %v, want %v", block.NumberU64(), have, removed)
+		}
+		if have := rawdb.ReadReceipts(db, block.Hash(), block.NumberU64(), block.Time(), chain.Config()) == nil; have != removed {
+			t.Errorf("block %d: receipts removed mismatch: have %v, want %v", block.NumberU64(), have, removed)
+		}
+	}
+}
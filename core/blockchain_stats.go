@@ -0,0 +1,124 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// blockStatsLimit is the number of recently imported blocks the processing
+// stats are retained for.
+const blockStatsLimit = 256
+
+var blockSendersTimer = metrics.NewRegisteredTimer("chain/senders", nil)
+
+// BlockProcessingStats is the timing breakdown of importing a single block.
+type BlockProcessingStats struct {
+	Number  uint64      `json:"number"`
+	Hash    common.Hash `json:"hash"`
+	Txs     int         `json:"txs"`
+	GasUsed uint64      `json:"gasUsed"`
+
+	SenderRecovery time.Duration `json:"senderRecovery"` // Waiting for the transaction senders to be recovered
+	Execution      time.Duration `json:"execution"`      // EVM execution, excluding state reads
+	StateRead      time.Duration `json:"stateRead"`      // Account and storage reads, from snapshot or trie
+	TrieUpdate     time.Duration `json:"trieUpdate"`     // Applying the state changes to the tries
+	TrieHash       time.Duration `json:"trieHash"`       // Hashing the account and storage tries
+	Validation     time.Duration `json:"validation"`     // Block validation, excluding trie updates and hashing
+	Commit         time.Duration `json:"commit"`         // Committing the tries into the trie database
+	SnapshotUpdate time.Duration `json:"snapshotUpdate"` // Updating the snapshot tree
+	Write          time.Duration `json:"write"`          // Writing the block, excluding state commits
+	Total          time.Duration `json:"total"`          // Full import time of the block
+}
+
+// newBlockProcessingStats assembles the processing stats of a block from the
+// measured phases and the timings collected by its state database.
+func newBlockProcessingStats(block *types.Block, usedGas uint64, statedb *state.StateDB, senders, process, validate, write, total time.Duration) *BlockProcessingStats {
+	var (
+		read   = statedb.SnapshotAccountReads + statedb.AccountReads + statedb.SnapshotStorageReads + statedb.StorageReads
+		update = statedb.AccountUpdates + statedb.StorageUpdates
+		hash   = statedb.AccountHashes + statedb.StorageHashes
+		commit = statedb.AccountCommits + statedb.StorageCommits + statedb.TrieDBCommits
+	)
+	return &BlockProcessingStats{
+		Number:         block.NumberU64(),
+		Hash:           block.Hash(),
+		Txs:            len(block.Transactions()),
+		GasUsed:        usedGas,
+		SenderRecovery: senders,
+		Execution:      process - read,
+		StateRead:      read,
+		TrieUpdate:     update,
+		TrieHash:       hash,
+		Validation:     validate - (update + hash),
+		Commit:         commit,
+		SnapshotUpdate: statedb.SnapshotCommits,
+		Write:          write - commit - statedb.SnapshotCommits,
+		Total:          total,
+	}
+}
+
+// blockStatsRing retains the processing stats of the recently imported blocks.
+type blockStatsRing struct {
+	stats [blockStatsLimit]*BlockProcessingStats
+	next  int // Index the next stats are stored at
+	lock  sync.RWMutex
+}
+
+// add records the processing stats of a newly imported block.
+func (r *blockStatsRing) add(stats *BlockProcessingStats) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.stats[r.next] = stats
+	r.next = (r.next + 1) % blockStatsLimit
+}
+
+// last returns the processing stats of the n most recently imported blocks,
+// newest first.
+func (r *blockStatsRing) last(n int) []*BlockProcessingStats {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if n <= 0 {
+		return nil
+	}
+	if n > blockStatsLimit {
+		n = blockStatsLimit
+	}
+	result := make([]*BlockProcessingStats, 0, n)
+	for i := 1; i <= n; i++ {
+		stats := r.stats[(r.next-i+blockStatsLimit)%blockStatsLimit]
+		if stats == nil {
+			break
+		}
+		result = append(result, stats)
+	}
+	return result
+}
+
+// BlockProcessingStats returns the timing breakdown of the n most recently
+// imported blocks, newest first.
+func (bc *BlockChain) BlockProcessingStats(n int) []*BlockProcessingStats {
+	return bc.procStats.last(n)
+}
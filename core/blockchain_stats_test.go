@@ -0,0 +1,66 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+)
+
+// Tests that the processing stats of the imported blocks are retained, newest
+// first, and capped at the retention limit.
+func TestBlockProcessingStats(t *testing.T) {
+	_, _, chain, err := newCanonical(ethash.NewFaker(), 8, true, rawdb.HashScheme)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	stats := chain.BlockProcessingStats(5)
+	if len(stats) != 5 {
+		t.Fatalf("stats count mismatch: have %d, want 5", len(stats))
+	}
+	for i, s := range stats {
+		want := chain.GetBlockByNumber(uint64(8 - i))
+		if s.Number != want.NumberU64() || s.Hash != want.Hash() {
+			t.Errorf("stats %d: block mismatch: have #%d [%x], want #%d [%x]", i, s.Number, s.Hash, want.NumberU64(), want.Hash())
+		}
+		if s.Total <= 0 || s.Total < s.Execution {
+			t.Errorf("stats %d: invalid timings: %+v", i, s)
+		}
+	}
+	if stats := chain.BlockProcessingStats(100); len(stats) != 8 {
+		t.Fatalf("stats count mismatch: have %d, want 8", len(stats))
+	}
+	if stats := chain.BlockProcessingStats(0); len(stats) != 0 {
+		t.Fatalf("stats returned for zero limit: %d", len(stats))
+	}
+	// Overflow the retention limit and ensure the oldest stats are evicted
+	var ring blockStatsRing
+	for i := 0; i < blockStatsLimit+10; i++ {
+		ring.add(&BlockProcessingStats{Number: uint64(i)})
+	}
+	all := ring.last(blockStatsLimit + 10)
+	if len(all) != blockStatsLimit {
+		t.Fatalf("retained stats mismatch: have %d, want %d", len(all), blockStatsLimit)
+	}
+	if all[0].Number != blockStatsLimit+9 || all[len(all)-1].Number != 10 {
+		t.Fatalf("retained range mismatch: have %d-%d", all[len(all)-1].Number, all[0].Number)
+	}
+}
@@ -4393,3 +4393,47 @@ func TestEIP3651(t *testing.T) {
 		t.Fatalf("sender balance incorrect: expected %d, got %d", expected, actual)
 	}
 }
+
+// Tests that importing a chain while prefetching state for many followup blocks
+// with few prefetcher goroutines yields the same chain as a plain import.
+func TestInsertChainPrefetchDistance(t *testing.T) {
+	testInsertChainPrefetchDistance(t, rawdb.HashScheme)
+	testInsertChainPrefetchDistance(t, rawdb.PathScheme)
+}
+
+func testInsertChainPrefetchDistance(t *testing.T, scheme string) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &genesisT.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  genesisT.GenesisAlloc{address: {Balance: big.NewInt(vars.Ether)}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 32, func(i int, block *BlockGen) {
+		for j := 0; j < 3; j++ {
+			tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), common.Address{byte(i), byte(j)}, big.NewInt(1000), vars.TxGas, block.header.BaseFee, nil), signer, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			block.AddTx(tx)
+		}
+	})
+	for _, limits := range [][2]int{{1, 1}, {8, 2}, {64, 4}} {
+		config := DefaultCacheConfigWithScheme(scheme)
+		config.PrefetchDistance, config.PrefetchWorkers = limits[0], limits[1]
+
+		chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), config, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+		if err != nil {
+			t.Fatalf("failed to create chain: %v", err)
+		}
+		if n, err := chain.InsertChain(blocks); err != nil {
+			t.Fatalf("distance %d, workers %d: failed to insert block %d: %v", limits[0], limits[1], n, err)
+		}
+		if head := chain.CurrentBlock(); head.Hash() != blocks[len(blocks)-1].Hash() {
+			t.Errorf("distance %d, workers %d: head mismatch: have %d, want %d", limits[0], limits[1], head.Number, len(blocks))
+		}
+		chain.Stop()
+	}
+}
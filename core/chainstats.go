@@ -0,0 +1,100 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BlockStats are the statistics of a single canonical block.
+type BlockStats struct {
+	Number     uint64      `json:"number"`
+	Hash       common.Hash `json:"hash"`
+	Time       uint64      `json:"timestamp"`
+	TimeDelta  uint64      `json:"timeDelta"` // Seconds since the parent block
+	GasUsed    uint64      `json:"gasUsed"`
+	GasLimit   uint64      `json:"gasLimit"`
+	TxCount    int         `json:"txCount"`
+	UncleCount int         `json:"uncleCount"`
+	Difficulty *big.Int    `json:"difficulty"`
+}
+
+// BlockStatsCSVHeader is the header row of the CSV encoding of BlockStats.
+var BlockStatsCSVHeader = []string{"number", "hash", "timestamp", "time_delta", "gas_used", "gas_limit", "tx_count", "uncle_count", "difficulty"}
+
+// CSVRecord returns the statistics as a CSV row, matching BlockStatsCSVHeader.
+func (s *BlockStats) CSVRecord() []string {
+	return []string{
+		strconv.FormatUint(s.Number, 10),
+		s.Hash.Hex(),
+		strconv.FormatUint(s.Time, 10),
+		strconv.FormatUint(s.TimeDelta, 10),
+		strconv.FormatUint(s.GasUsed, 10),
+		strconv.FormatUint(s.GasLimit, 10),
+		strconv.Itoa(s.TxCount),
+		strconv.Itoa(s.UncleCount),
+		s.Difficulty.String(),
+	}
+}
+
+// IterateBlockStats calls fn with the statistics of all canonical blocks in the
+// range [from, to], in ascending order. Iteration stops at the first error.
+func (bc *BlockChain) IterateBlockStats(from, to uint64, fn func(*BlockStats) error) error {
+	if from > to {
+		return fmt.Errorf("invalid range: from %d > to %d", from, to)
+	}
+	var parentTime uint64
+	if from > 0 {
+		parent := bc.GetHeaderByNumber(from - 1)
+		if parent == nil {
+			return fmt.Errorf("block #%d not found", from-1)
+		}
+		parentTime = parent.Time
+	}
+	for number := from; number <= to; number++ {
+		block := bc.GetBlockByNumber(number)
+		if block == nil {
+			return fmt.Errorf("block #%d not found", number)
+		}
+		stats := &BlockStats{
+			Number:     number,
+			Hash:       block.Hash(),
+			Time:       block.Time(),
+			GasUsed:    block.GasUsed(),
+			GasLimit:   block.GasLimit(),
+			TxCount:    len(block.Transactions()),
+			UncleCount: len(block.Uncles()),
+			Difficulty: block.Difficulty(),
+		}
+		if number > 0 {
+			stats.TimeDelta = block.Time() - parentTime
+		}
+		parentTime = block.Time()
+
+		if err := fn(stats); err != nil {
+			return err
+		}
+		if number == to {
+			break // avoid overflow at the maximum block number
+		}
+	}
+	return nil
+}
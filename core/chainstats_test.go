@@ -0,0 +1,72 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+)
+
+func TestIterateBlockStats(t *testing.T) {
+	gspec := &genesisT.Genesis{Config: params.TestChainConfig}
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 8, func(i int, b *BlockGen) {
+		b.OffsetTime(int64(i))
+	})
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	var stats []*BlockStats
+	err = chain.IterateBlockStats(3, 8, func(s *BlockStats) error {
+		stats = append(stats, s)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to iterate stats: %v", err)
+	}
+	if len(stats) != 6 {
+		t.Fatalf("wrong number of stats: have %d, want 6", len(stats))
+	}
+	for i, s := range stats {
+		block := blocks[s.Number-1]
+		if s.Number != uint64(i+3) || s.Hash != block.Hash() {
+			t.Errorf("stats %d: wrong block %d (%x)", i, s.Number, s.Hash)
+		}
+		parent := chain.GetHeaderByNumber(s.Number - 1)
+		if s.TimeDelta != block.Time()-parent.Time {
+			t.Errorf("stats %d: wrong time delta %d, want %d", i, s.TimeDelta, block.Time()-parent.Time)
+		}
+		if s.GasLimit != block.GasLimit() || s.Difficulty.Cmp(block.Difficulty()) != 0 {
+			t.Errorf("stats %d: header field mismatch", i)
+		}
+		if len(s.CSVRecord()) != len(BlockStatsCSVHeader) {
+			t.Errorf("stats %d: CSV record doesn't match header", i)
+		}
+	}
+	if err := chain.IterateBlockStats(5, 9, func(*BlockStats) error { return nil }); err == nil {
+		t.Error("expected error for range beyond the head")
+	}
+}
@@ -41,3 +41,7 @@ type ChainSideEvent struct {
 }
 
 type ChainHeadEvent struct{ Block *types.Block }
+
+// ReorgHeldEvent is posted when a reorg deeper than the configured limit was
+// refused and awaits the confirmation of the operator.
+type ReorgHeldEvent struct{ Reorg HeldReorg }
@@ -156,6 +156,25 @@ func (f *ForkChoice) ReorgNeeded(current *types.Header, extern *types.Header) (b
 		return reorg, nil
 	}
 
+	// Refuse reorgs dropping more canonical blocks than the configured limit,
+	// leaving the decision to the operator. Until it's made, the head is not
+	// updated at all, neither along the local branch nor any other one.
+	if bc, ok := f.chain.(*BlockChain); ok {
+		if bc.MaxReorgDepth() > 0 && extern.ParentHash != current.Hash() {
+			commonHeader, err := f.CommonAncestor(current, extern)
+			if err != nil {
+				return false, err
+			}
+			if current.Number.Uint64()-commonHeader.Number.Uint64() > bc.MaxReorgDepth() {
+				bc.holdReorg(commonHeader, current, extern)
+				return false, nil
+			}
+		}
+		if bc.reorgHeld() {
+			log.Debug("Head update halted by held reorg", "number", extern.Number, "hash", extern.Hash())
+			return false, nil
+		}
+	}
 	if bc, ok := f.chain.(*BlockChain); ok {
 		// Short circuit if not configured for Artificial Finality.
 		if !bc.IsArtificialFinalityEnabled() {
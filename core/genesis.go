@@ -36,6 +36,7 @@ import (
 	"github.com/ethereum/go-ethereum/params/vars"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/hashdb"
 	"github.com/ethereum/go-ethereum/triedb/pathdb"
 	"github.com/holiman/uint256"
 )
@@ -97,21 +98,7 @@ func SetupGenesisBlockWithOverride(db ethdb.Database, triedb *triedb.Database, g
 	}
 
 	applyOverrides := func(config ctypes.ChainConfigurator) {
-		if config != nil {
-			// Block-based overrides are not provided because Shanghai is
-			// ETH-network specific and that protocol is defined exclusively in time-based forks.
-			if overrides != nil && overrides.OverrideCancun != nil {
-				config.SetEIP1153TransitionTime(overrides.OverrideCancun)
-				config.SetEIP4788TransitionTime(overrides.OverrideCancun)
-				config.SetEIP4844TransitionTime(overrides.OverrideCancun)
-				config.SetEIP5656TransitionTime(overrides.OverrideCancun)
-				config.SetEIP6780TransitionTime(overrides.OverrideCancun)
-				config.SetEIP7516TransitionTime(overrides.OverrideCancun)
-			}
-			if overrides != nil && overrides.OverrideVerkle != nil {
-				log.Warn("Verkle-fork is not yet supported")
-			}
-		}
+		applyChainOverrides(config, overrides)
 	}
 
 	// Just commit the new block if there is no stored genesis block.
@@ -223,6 +210,66 @@ func SetupGenesisBlockWithOverride(db ethdb.Database, triedb *triedb.Database, g
 	return newcfg, stored, nil
 }
 
+// applyChainOverrides applies the given fork overrides to the chain config.
+func applyChainOverrides(config ctypes.ChainConfigurator, overrides *ChainOverrides) {
+	if config != nil {
+		// Block-based overrides are not provided because Shanghai is
+		// ETH-network specific and that protocol is defined exclusively in time-based forks.
+		if overrides != nil && overrides.OverrideCancun != nil {
+			config.SetEIP1153TransitionTime(overrides.OverrideCancun)
+			config.SetEIP4788TransitionTime(overrides.OverrideCancun)
+			config.SetEIP4844TransitionTime(overrides.OverrideCancun)
+			config.SetEIP5656TransitionTime(overrides.OverrideCancun)
+			config.SetEIP6780TransitionTime(overrides.OverrideCancun)
+			config.SetEIP7516TransitionTime(overrides.OverrideCancun)
+		}
+		if overrides != nil && overrides.OverrideVerkle != nil {
+			log.Warn("Verkle-fork is not yet supported")
+		}
+	}
+}
+
+// ResolveChainConfig resolves the chain config a node would run with, given the
+// database, the optional genesis specification and the optional overrides, along
+// with the genesis hash. It follows SetupGenesisBlockWithOverride, but doesn't
+// write anything to the database, nor check the compatibility of the resolved
+// config with the stored chain.
+func ResolveChainConfig(db ethdb.Database, genesis *genesisT.Genesis, overrides *ChainOverrides) (ctypes.ChainConfigurator, common.Hash, error) {
+	if genesis != nil && confp.IsEmpty(genesis.Config) {
+		return nil, common.Hash{}, genesisT.ErrGenesisNoConfig
+	}
+	// If there is no stored genesis block, the given one or the default would
+	// be committed.
+	stored := rawdb.ReadCanonicalHash(db, 0)
+	if (stored == common.Hash{}) {
+		if genesis == nil {
+			genesis = params.DefaultGenesisBlock()
+		}
+		applyChainOverrides(genesis.Config, overrides)
+		return genesis.Config, GenesisToBlock(genesis, nil).Hash(), nil
+	}
+	// Ensure the given genesis matches the stored one.
+	if genesis != nil {
+		applyChainOverrides(genesis.Config, overrides)
+		if hash := GenesisToBlock(genesis, nil).Hash(); hash != stored {
+			return nil, hash, &genesisT.GenesisMismatchError{Stored: stored, New: hash}
+		}
+	}
+	newcfg := configOrDefault(genesis, stored)
+	applyChainOverrides(newcfg, overrides)
+
+	storedcfg := rawdb.ReadChainConfig(db, stored)
+	if storedcfg == nil {
+		return newcfg, stored, nil
+	}
+	// Non-defaulty stored configs are retained if no genesis is given.
+	if genesis == nil && !confp.Identical(storedcfg, newcfg, []string{"NetworkID", "ChainID"}) {
+		applyChainOverrides(storedcfg, overrides)
+		return storedcfg, stored, nil
+	}
+	return newcfg, stored, nil
+}
+
 // LoadCliqueConfig loads the stored clique config if the chain config
 // is already present in database, otherwise, return the config in the
 // provided genesis specification. Note the returned clique config can
@@ -366,7 +413,7 @@ func gaFlush(ga *genesisT.GenesisAlloc, triedb *triedb.Database, db ethdb.Databa
 }
 
 // gaHash computes the state root according to the genesis specification.
-func gaHash(ga *genesisT.GenesisAlloc, isVerkle bool) (common.Hash, error) {
+func gaHash(ga *genesisT.GenesisAlloc, isVerkle bool, isBinary bool) (common.Hash, error) {
 	// If a genesis-time verkle trie is requested, create a trie config
 	// with the verkle trie enabled so that the tree can be initialized
 	// as such.
@@ -377,6 +424,9 @@ func gaHash(ga *genesisT.GenesisAlloc, isVerkle bool) (common.Hash, error) {
 			IsVerkle: true,
 		}
 	}
+	if isBinary {
+		config = binaryTrieConfig
+	}
 	// Create an ephemeral in-memory database for computing hash,
 	// all the derived states will be discarded to not pollute disk.
 	db := state.NewDatabaseWithConfig(rawdb.NewMemoryDatabase(), config)
@@ -397,6 +447,23 @@ func gaHash(ga *genesisT.GenesisAlloc, isVerkle bool) (common.Hash, error) {
 	return statedb.Commit(0, false)
 }
 
+// binaryTrieConfig is the trie database config used for the genesis state of
+// networks selecting the experimental binary trie layout.
+var binaryTrieConfig = &triedb.Config{
+	HashDB:   hashdb.Defaults,
+	IsBinary: true,
+}
+
+// IsBinaryStateLayout reports whether the state held by the database, or the
+// state to be created from the given genesis if the database holds none yet,
+// uses the experimental binary trie layout.
+func IsBinaryStateLayout(db ethdb.KeyValueReader, genesis *genesisT.Genesis) bool {
+	if layout := rawdb.ReadStateLayout(db); layout != "" {
+		return layout == rawdb.BinaryTrieLayout
+	}
+	return genesis != nil && genesis.IsBinaryTrie()
+}
+
 // Write writes the json marshaled genesis state into database
 // with the given block hash as the unique identifier.
 func gaWrite(ga *genesisT.GenesisAlloc, db ethdb.KeyValueWriter, hash common.Hash) error {
@@ -454,11 +521,15 @@ func GenesisToBlock(g *genesisT.Genesis, db ethdb.Database) *types.Block {
 	if db == nil {
 		db = rawdb.NewMemoryDatabase()
 	}
-	root, err := gaHash(&g.Alloc, g.IsVerkle())
+	root, err := gaHash(&g.Alloc, g.IsVerkle(), g.IsBinaryTrie())
 	if err != nil {
 		panic(err)
 	}
-	err = gaFlush(&g.Alloc, triedb.NewDatabase(db, nil), db)
+	var config *triedb.Config
+	if g.IsBinaryTrie() {
+		config = binaryTrieConfig
+	}
+	err = gaFlush(&g.Alloc, triedb.NewDatabase(db, config), db)
 	if err != nil {
 		panic(err)
 	}
@@ -543,6 +614,9 @@ func CommitGenesis(g *genesisT.Genesis, db ethdb.Database, triedb *triedb.Databa
 	if config.GetConsensusEngineType().IsClique() && len(block.Extra()) == 0 {
 		return nil, errors.New("can't start clique chain without signers")
 	}
+	if g.IsBinaryTrie() != triedb.IsBinary() {
+		return nil, fmt.Errorf("trie database doesn't match genesis state layout %q", g.StateLayout)
+	}
 	// All the checks has passed, flushAlloc the states derived from the genesis
 	// specification as well as the specification itself into the provided
 	// database.
@@ -560,6 +634,9 @@ func CommitGenesis(g *genesisT.Genesis, db ethdb.Database, triedb *triedb.Databa
 	rawdb.WriteHeadFastBlockHash(db, block.Hash())
 	rawdb.WriteHeadHeaderHash(db, block.Hash())
 	rawdb.WriteChainConfig(db, block.Hash(), config)
+	if g.IsBinaryTrie() {
+		rawdb.WriteStateLayout(db, rawdb.BinaryTrieLayout)
+	}
 	return block, nil
 }
 
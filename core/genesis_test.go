@@ -214,6 +214,50 @@ func testSetupGenesis(t *testing.T, scheme string) {
 	}
 }
 
+// TestResolveChainConfig checks that the chain config is resolved the same way
+// SetupGenesisBlock would, without writing to the database.
+func TestResolveChainConfig(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	genesis := params.DefaultClassicGenesisBlock()
+	wantHash := GenesisToBlock(genesis, nil).Hash()
+
+	// Empty database, the given genesis is used.
+	config, hash, err := ResolveChainConfig(db, genesis, nil)
+	if err != nil {
+		t.Fatalf("failed to resolve config: %v", err)
+	}
+	if hash != wantHash {
+		t.Errorf("genesis hash mismatch: have %x, want %x", hash, wantHash)
+	}
+	if config.GetChainID().Cmp(genesis.Config.GetChainID()) != 0 {
+		t.Errorf("chain id mismatch: have %v, want %v", config.GetChainID(), genesis.Config.GetChainID())
+	}
+	if stored := rawdb.ReadCanonicalHash(db, 0); stored != (common.Hash{}) {
+		t.Fatalf("database modified, genesis %x written", stored)
+	}
+
+	// Initialized database, the stored genesis is used.
+	if _, _, err := SetupGenesisBlock(db, triedb.NewDatabase(db, nil), genesis); err != nil {
+		t.Fatalf("failed to setup genesis: %v", err)
+	}
+	config, hash, err = ResolveChainConfig(db, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to resolve config: %v", err)
+	}
+	if hash != wantHash {
+		t.Errorf("genesis hash mismatch: have %x, want %x", hash, wantHash)
+	}
+	if config.GetChainID().Cmp(genesis.Config.GetChainID()) != 0 {
+		t.Errorf("chain id mismatch: have %v, want %v", config.GetChainID(), genesis.Config.GetChainID())
+	}
+
+	// A different genesis is rejected. Note, the Ethereum genesis is the same as
+	// the Classic one.
+	if _, _, err := ResolveChainConfig(db, params.DefaultMordorGenesisBlock(), nil); err == nil {
+		t.Error("expected genesis mismatch error")
+	}
+}
+
 // TestGenesisHashes checks the congruity of default genesis data to
 // corresponding hardcoded genesis hash values.
 func TestGenesisHashes(t *testing.T) {
@@ -268,7 +312,7 @@ func TestReadWriteGenesisAlloc(t *testing.T) {
 			{1}: {Balance: big.NewInt(1), Storage: map[common.Hash]common.Hash{{1}: {1}}},
 			{2}: {Balance: big.NewInt(2), Storage: map[common.Hash]common.Hash{{2}: {2}}},
 		}
-		hash, _ = gaHash(alloc, false)
+		hash, _ = gaHash(alloc, false, false)
 	)
 	blob, _ := json.Marshal(alloc)
 	rawdb.WriteGenesisStateSpec(db, hash, blob)
@@ -0,0 +1,104 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// hashrateConfidenceZ is the z-score of the reported confidence interval (95%).
+const hashrateConfidenceZ = 1.96
+
+// HashrateEstimate is an estimation of the network hashrate over a window of
+// consecutive blocks.
+type HashrateEstimate struct {
+	FirstBlock uint64   // Number of the first block of the window
+	LastBlock  uint64   // Number of the last block of the window
+	Blocks     uint64   // Number of block intervals covered by the window
+	Duration   uint64   // Seconds between the first and the last block
+	Hashrate   *big.Int // Estimated hashes per second
+	Lower      *big.Int // Lower bound of the 95% confidence interval
+	Upper      *big.Int // Upper bound of the 95% confidence interval
+}
+
+// EstimateHashrate estimates the network hashrate from a window of consecutive
+// headers in ascending order, as the total difficulty mined after the first
+// header divided by the time it took to mine it.
+//
+// Block intervals are exponentially distributed, so the relative standard error
+// of the estimate is about 1/sqrt(n) for n intervals, which is used to derive
+// the confidence interval. Timestamps are set by miners and may be skewed, so
+// short windows are noisy regardless.
+func EstimateHashrate(headers []*types.Header) (*HashrateEstimate, error) {
+	if len(headers) < 2 {
+		return nil, errors.New("at least two blocks required")
+	}
+	first, last := headers[0], headers[len(headers)-1]
+	if last.Time <= first.Time {
+		return nil, errors.New("no time elapsed in window")
+	}
+	work := new(big.Int)
+	for i, header := range headers[1:] {
+		if header.Number.Uint64() != headers[i].Number.Uint64()+1 {
+			return nil, errors.New("non-contiguous headers")
+		}
+		work.Add(work, header.Difficulty)
+	}
+	var (
+		blocks   = uint64(len(headers) - 1)
+		duration = last.Time - first.Time
+		hashrate = new(big.Int).Div(work, new(big.Int).SetUint64(duration))
+		margin   = hashrateConfidenceZ / math.Sqrt(float64(blocks))
+	)
+	return &HashrateEstimate{
+		FirstBlock: first.Number.Uint64(),
+		LastBlock:  last.Number.Uint64(),
+		Blocks:     blocks,
+		Duration:   duration,
+		Hashrate:   hashrate,
+		Lower:      scaleHashrate(hashrate, math.Max(0, 1-margin)),
+		Upper:      scaleHashrate(hashrate, 1+margin),
+	}, nil
+}
+
+// scaleHashrate multiplies the hashrate by the given factor.
+func scaleHashrate(hashrate *big.Int, factor float64) *big.Int {
+	scaled, _ := new(big.Float).Mul(new(big.Float).SetInt(hashrate), big.NewFloat(factor)).Int(nil)
+	return scaled
+}
+
+// EstimateHashrate estimates the network hashrate over the given number of most
+// recent canonical blocks, or since genesis if the chain is shorter.
+func (bc *BlockChain) EstimateHashrate(window uint64) (*HashrateEstimate, error) {
+	head := bc.CurrentBlock().Number.Uint64()
+	if window > head {
+		window = head
+	}
+	headers := make([]*types.Header, 0, window+1)
+	for number := head - window; number <= head; number++ {
+		header := bc.GetHeaderByNumber(number)
+		if header == nil {
+			return nil, errors.New("missing header in window")
+		}
+		headers = append(headers, header)
+	}
+	return EstimateHashrate(headers)
+}
@@ -0,0 +1,63 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestEstimateHashrate(t *testing.T) {
+	// 100 blocks of difficulty 1300 mined every 13 seconds: 100 H/s.
+	var headers []*types.Header
+	for i := 0; i <= 100; i++ {
+		headers = append(headers, &types.Header{
+			Number:     big.NewInt(int64(1000 + i)),
+			Time:       uint64(1000 + 13*i),
+			Difficulty: big.NewInt(1300),
+		})
+	}
+	est, err := EstimateHashrate(headers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if est.FirstBlock != 1000 || est.LastBlock != 1100 || est.Blocks != 100 || est.Duration != 1300 {
+		t.Errorf("wrong window: %+v", est)
+	}
+	if est.Hashrate.Int64() != 100 {
+		t.Errorf("wrong hashrate: have %v, want 100", est.Hashrate)
+	}
+	// The margin for 100 blocks is 1.96/sqrt(100) = 19.6%.
+	if est.Lower.Int64() != 80 || est.Upper.Int64() != 119 {
+		t.Errorf("wrong confidence interval: have [%v, %v], want [80, 119]", est.Lower, est.Upper)
+	}
+
+	// Invalid windows.
+	if _, err := EstimateHashrate(headers[:1]); err == nil {
+		t.Error("expected error for single block")
+	}
+	gap := append(headers[:10:10], headers[11:]...)
+	if _, err := EstimateHashrate(gap); err == nil {
+		t.Error("expected error for non-contiguous headers")
+	}
+	same := []*types.Header{headers[0], {Number: big.NewInt(1001), Time: 1000, Difficulty: big.NewInt(1)}}
+	if _, err := EstimateHashrate(same); err == nil {
+		t.Error("expected error for zero duration")
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package maintenance implements a process wide switch allowing operators to
+// temporarily hold off background database maintenance, such as snapshot
+// generation, index backfills and ancient store migration, in order to keep
+// the disk available for latency sensitive RPC traffic.
+package maintenance
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// Names of the background tasks observing the maintenance switch.
+const (
+	TaskSnapshot   = "snapshot"   // State snapshot generation
+	TaskTxIndex    = "txindex"    // Transaction lookup (un)indexing
+	TaskChainIndex = "chainindex" // Bloombits section indexing
+	TaskFreezer    = "freezer"    // Migration of blocks into the ancient store
+)
+
+var (
+	pausedGauge  = metrics.NewRegisteredGauge("maintenance/paused", nil)
+	pausesMeter  = metrics.NewRegisteredMeter("maintenance/pauses", nil)
+	pausedTimer  = metrics.NewRegisteredTimer("maintenance/paused/time", nil)
+	waitingGauge = metrics.NewRegisteredGauge("maintenance/waiting", nil)
+)
+
+// Status describes the state of the maintenance switch.
+type Status struct {
+	Paused  bool           `json:"paused"`
+	Since   *time.Time     `json:"since,omitempty"` // Time the maintenance was paused at
+	Waiting map[string]int `json:"waiting"`         // Number of routines held off, per task
+	Tasks   []string       `json:"tasks"`           // Tasks observing the switch
+}
+
+var (
+	lock    sync.Mutex
+	resumed = closedChan()         // Closed whenever maintenance is allowed to run
+	since   time.Time              // Time the maintenance was paused at, zero if running
+	waiting = make(map[string]int) // Routines currently held off, per task
+)
+
+func closedChan() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}
+
+// Pause holds off all background maintenance until Resume is called. Tasks
+// already running stop at their next checkpoint. Pausing an already paused
+// switch is a noop.
+func Pause() {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if !since.IsZero() {
+		return
+	}
+	since = time.Now()
+	resumed = make(chan struct{})
+
+	pausedGauge.Update(1)
+	pausesMeter.Mark(1)
+	log.Info("Paused background maintenance")
+}
+
+// Resume lets the background maintenance held off by Pause continue.
+func Resume() {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if since.IsZero() {
+		return
+	}
+	close(resumed)
+	pausedTimer.UpdateSince(since)
+	pausedGauge.Update(0)
+	log.Info("Resumed background maintenance", "paused", time.Since(since).Round(time.Millisecond))
+	since = time.Time{}
+}
+
+// Paused reports whether background maintenance is currently paused.
+func Paused() bool {
+	lock.Lock()
+	defer lock.Unlock()
+
+	return !since.IsZero()
+}
+
+// Hold registers the calling routine of the given task as held off by the
+// maintenance switch. The returned channel is closed once maintenance may
+// proceed, release must be called when the routine stops waiting.
+func Hold(task string) (<-chan struct{}, func()) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	waiting[task]++
+	waitingGauge.Inc(1)
+
+	var once sync.Once
+	return resumed, func() {
+		once.Do(func() {
+			lock.Lock()
+			defer lock.Unlock()
+
+			if waiting[task]--; waiting[task] == 0 {
+				delete(waiting, task)
+			}
+			waitingGauge.Dec(1)
+		})
+	}
+}
+
+// Wait blocks the calling routine of the given task while maintenance is
+// paused. It returns false if the quit channel was closed before that.
+func Wait(task string, quit <-chan struct{}) bool {
+	if !Paused() {
+		return true
+	}
+	ch, release := Hold(task)
+	defer release()
+
+	select {
+	case <-ch:
+		return true
+	case <-quit:
+		return false
+	}
+}
+
+// Report returns the current state of the maintenance switch.
+func Report() Status {
+	lock.Lock()
+	defer lock.Unlock()
+
+	status := Status{
+		Paused:  !since.IsZero(),
+		Waiting: make(map[string]int, len(waiting)),
+		Tasks:   []string{TaskSnapshot, TaskTxIndex, TaskChainIndex, TaskFreezer},
+	}
+	if status.Paused {
+		t := since
+		status.Since = &t
+	}
+	for task, n := range waiting {
+		status.Waiting[task] = n
+	}
+	sort.Strings(status.Tasks)
+	return status
+}
@@ -0,0 +1,79 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package maintenance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPauseResume(t *testing.T) {
+	defer Resume()
+
+	// Running maintenance must not be held off
+	if !Wait(TaskTxIndex, nil) {
+		t.Fatal("wait failed while running")
+	}
+	Pause()
+	if !Paused() {
+		t.Fatal("switch not paused")
+	}
+	done := make(chan bool)
+	go func() { done <- Wait(TaskTxIndex, nil) }()
+
+	// Wait for the routine to be registered as held off
+	for start := time.Now(); Report().Waiting[TaskTxIndex] != 1; {
+		if time.Since(start) > time.Second {
+			t.Fatalf("routine not held off: %+v", Report())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-done:
+		t.Fatal("routine proceeded while paused")
+	case <-time.After(10 * time.Millisecond):
+	}
+	if status := Report(); !status.Paused || status.Since == nil {
+		t.Fatalf("invalid status while paused: %+v", status)
+	}
+	Resume()
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Fatal("wait failed after resume")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("routine not released by resume")
+	}
+	if status := Report(); status.Paused || len(status.Waiting) != 0 {
+		t.Fatalf("invalid status after resume: %+v", status)
+	}
+}
+
+func TestWaitQuit(t *testing.T) {
+	Pause()
+	defer Resume()
+
+	quit := make(chan struct{})
+	close(quit)
+	if Wait(TaskSnapshot, quit) {
+		t.Fatal("wait succeeded despite quit")
+	}
+	if n := Report().Waiting[TaskSnapshot]; n != 0 {
+		t.Fatalf("routine still registered after quit: %d", n)
+	}
+}
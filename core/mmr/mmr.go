@@ -0,0 +1,278 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package mmr implements a persistent Merkle mountain range accumulator over the
+// canonical header chain.
+//
+// The accumulator is a list of perfect binary Merkle trees (the peaks) of strictly
+// decreasing height, one for every set bit of the number of leaves. Appending a
+// leaf only ever touches the peaks, so the structure can be maintained cheaply as
+// the chain grows, and any leaf can be proven against the root committing to all
+// the peaks and the leaf count.
+package mmr
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+var (
+	errLeafOutOfRange = errors.New("leaf index out of range")
+	errMissingNode    = errors.New("missing accumulator node")
+	errInvalidProof   = errors.New("invalid accumulator proof")
+)
+
+// Proof is an inclusion proof of a single leaf in the accumulator.
+type Proof struct {
+	LeafIndex uint64        `json:"leafIndex"`
+	LeafCount uint64        `json:"leafCount"`
+	Leaf      common.Hash   `json:"leaf"`
+	Siblings  []common.Hash `json:"siblings"` // Path from the leaf up to its peak
+	Peaks     []common.Hash `json:"peaks"`    // All peaks, highest (leftmost) first
+	Root      common.Hash   `json:"root"`
+}
+
+// MMR is a Merkle mountain range accumulator persisted in a key-value store.
+// It is safe for concurrent use.
+type MMR struct {
+	db    ethdb.KeyValueStore
+	size  uint64        // Number of leaves in the accumulator
+	peaks []common.Hash // Peaks of the accumulator, highest first
+	lock  sync.RWMutex
+}
+
+// New opens the accumulator persisted in the given database.
+func New(db ethdb.KeyValueStore) (*MMR, error) {
+	m := &MMR{db: db}
+	size := rawdb.ReadHeaderAccumulatorSize(db)
+	peaks, err := m.loadPeaks(size)
+	if err != nil {
+		return nil, err
+	}
+	m.size, m.peaks = size, peaks
+	return m, nil
+}
+
+// Size returns the number of leaves in the accumulator.
+func (m *MMR) Size() uint64 {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return m.size
+}
+
+// Root returns the current root of the accumulator.
+func (m *MMR) Root() common.Hash {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return rootOf(m.size, m.peaks)
+}
+
+// Leaf returns the leaf at the given index.
+func (m *MMR) Leaf(index uint64) (common.Hash, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	if index >= m.size {
+		return common.Hash{}, errLeafOutOfRange
+	}
+	return m.node(0, index)
+}
+
+// Append adds new leaves to the accumulator and persists the result.
+func (m *MMR) Append(leaves []common.Hash) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	var (
+		batch = m.db.NewBatch()
+		size  = m.size
+		peaks = append([]common.Hash{}, m.peaks...)
+	)
+	for _, leaf := range leaves {
+		// Insert the leaf and merge the peaks of equal height, which are always
+		// the rightmost ones, for as long as the subtree index is odd
+		node, level, index := leaf, uint8(0), size
+		rawdb.WriteHeaderAccumulatorNode(batch, level, index, node)
+		for index&1 == 1 {
+			node = hashPair(peaks[len(peaks)-1], node)
+			peaks = peaks[:len(peaks)-1]
+			level, index = level+1, index>>1
+			rawdb.WriteHeaderAccumulatorNode(batch, level, index, node)
+		}
+		peaks = append(peaks, node)
+		size++
+	}
+	rawdb.WriteHeaderAccumulatorSize(batch, size)
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	m.size, m.peaks = size, peaks
+	return nil
+}
+
+// Truncate drops all the leaves from the given index onward. The nodes of the
+// dropped leaves are left in the database and overwritten by future appends.
+func (m *MMR) Truncate(size uint64) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if size >= m.size {
+		return nil
+	}
+	peaks, err := m.loadPeaks(size)
+	if err != nil {
+		return err
+	}
+	rawdb.WriteHeaderAccumulatorSize(m.db, size)
+	m.size, m.peaks = size, peaks
+	return nil
+}
+
+// Prove creates an inclusion proof of the leaf at the given index against the
+// current root of the accumulator.
+func (m *MMR) Prove(index uint64) (*Proof, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	if index >= m.size {
+		return nil, errLeafOutOfRange
+	}
+	leaf, err := m.node(0, index)
+	if err != nil {
+		return nil, err
+	}
+	_, height, _ := peakOf(m.size, index)
+
+	siblings := make([]common.Hash, height)
+	for level := 0; level < height; level++ {
+		if siblings[level], err = m.node(uint8(level), (index>>level)^1); err != nil {
+			return nil, err
+		}
+	}
+	return &Proof{
+		LeafIndex: index,
+		LeafCount: m.size,
+		Leaf:      leaf,
+		Siblings:  siblings,
+		Peaks:     append([]common.Hash{}, m.peaks...),
+		Root:      rootOf(m.size, m.peaks),
+	}, nil
+}
+
+// VerifyProof checks that the proof is consistent and commits to the given root.
+func VerifyProof(root common.Hash, proof *Proof) error {
+	if proof.LeafIndex >= proof.LeafCount {
+		return errLeafOutOfRange
+	}
+	if len(proof.Peaks) != bits.OnesCount64(proof.LeafCount) {
+		return fmt.Errorf("%w: peak count mismatch", errInvalidProof)
+	}
+	peak, height, start := peakOf(proof.LeafCount, proof.LeafIndex)
+	if len(proof.Siblings) != height {
+		return fmt.Errorf("%w: path length mismatch", errInvalidProof)
+	}
+	node, index := proof.Leaf, proof.LeafIndex-start
+	for _, sibling := range proof.Siblings {
+		if index&1 == 0 {
+			node = hashPair(node, sibling)
+		} else {
+			node = hashPair(sibling, node)
+		}
+		index >>= 1
+	}
+	if node != proof.Peaks[peak] {
+		return fmt.Errorf("%w: peak mismatch", errInvalidProof)
+	}
+	if have := rootOf(proof.LeafCount, proof.Peaks); have != root {
+		return fmt.Errorf("%w: root mismatch", errInvalidProof)
+	}
+	return nil
+}
+
+// node retrieves an accumulator node from the database.
+func (m *MMR) node(level uint8, index uint64) (common.Hash, error) {
+	node, ok := rawdb.ReadHeaderAccumulatorNode(m.db, level, index)
+	if !ok {
+		return common.Hash{}, fmt.Errorf("%w: level %d, index %d", errMissingNode, level, index)
+	}
+	return node, nil
+}
+
+// loadPeaks retrieves the peaks of an accumulator with the given number of leaves.
+func (m *MMR) loadPeaks(size uint64) ([]common.Hash, error) {
+	var (
+		peaks []common.Hash
+		start uint64
+	)
+	for height := 63; height >= 0; height-- {
+		if size&(1<<height) == 0 {
+			continue
+		}
+		peak, err := m.node(uint8(height), start>>height)
+		if err != nil {
+			return nil, err
+		}
+		peaks = append(peaks, peak)
+		start += 1 << height
+	}
+	return peaks, nil
+}
+
+// peakOf returns the position of the peak covering the given leaf in an
+// accumulator of the given size, along with its height and its first leaf.
+func peakOf(size, index uint64) (peak int, height int, start uint64) {
+	for height = 63; height >= 0; height-- {
+		if size&(1<<height) == 0 {
+			continue
+		}
+		if index < start+1<<height {
+			return peak, height, start
+		}
+		start += 1 << height
+		peak++
+	}
+	panic("leaf index out of range")
+}
+
+// rootOf bags the peaks right to left into a single hash, committing to the
+// number of leaves too so that accumulators of different sizes never collide.
+func rootOf(size uint64, peaks []common.Hash) common.Hash {
+	var bagged common.Hash
+	if len(peaks) > 0 {
+		bagged = peaks[len(peaks)-1]
+		for i := len(peaks) - 2; i >= 0; i-- {
+			bagged = hashPair(peaks[i], bagged)
+		}
+	}
+	var enc [8]byte
+	binary.BigEndian.PutUint64(enc[:], size)
+	return crypto.Keccak256Hash(enc[:], bagged[:])
+}
+
+// hashPair computes the parent of two accumulator nodes.
+func hashPair(left, right common.Hash) common.Hash {
+	return crypto.Keccak256Hash(left[:], right[:])
+}
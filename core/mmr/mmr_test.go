@@ -0,0 +1,148 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package mmr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func makeLeaves(n int) []common.Hash {
+	leaves := make([]common.Hash, n)
+	for i := range leaves {
+		leaves[i] = crypto.Keccak256Hash([]byte{byte(i), byte(i >> 8)})
+	}
+	return leaves
+}
+
+// Tests that every leaf can be proven against the root, for all accumulator
+// sizes, regardless of whether leaves were appended one by one or in batches.
+func TestProofs(t *testing.T) {
+	var (
+		leaves  = makeLeaves(70)
+		single  = mustNew(t)
+		batched = mustNew(t)
+	)
+	for size := 1; size <= len(leaves); size++ {
+		if err := single.Append(leaves[size-1 : size]); err != nil {
+			t.Fatalf("size %d: failed to append leaf: %v", size, err)
+		}
+		root := single.Root()
+		for i := 0; i < size; i++ {
+			proof, err := single.Prove(uint64(i))
+			if err != nil {
+				t.Fatalf("size %d, leaf %d: failed to create proof: %v", size, i, err)
+			}
+			if proof.Leaf != leaves[i] {
+				t.Fatalf("size %d, leaf %d: proven leaf mismatch", size, i)
+			}
+			if err := VerifyProof(root, proof); err != nil {
+				t.Fatalf("size %d, leaf %d: failed to verify proof: %v", size, i, err)
+			}
+		}
+	}
+	if err := batched.Append(leaves); err != nil {
+		t.Fatalf("failed to append leaves: %v", err)
+	}
+	if single.Root() != batched.Root() {
+		t.Fatalf("root mismatch between single and batched appends")
+	}
+	if _, err := single.Prove(uint64(len(leaves))); !errors.Is(err, errLeafOutOfRange) {
+		t.Fatalf("out of range proof error mismatch: have %v, want %v", err, errLeafOutOfRange)
+	}
+}
+
+// Tests that tampered proofs are rejected.
+func TestInvalidProofs(t *testing.T) {
+	m := mustNew(t)
+	if err := m.Append(makeLeaves(13)); err != nil {
+		t.Fatalf("failed to append leaves: %v", err)
+	}
+	root := m.Root()
+
+	proof, _ := m.Prove(5)
+	proof.Leaf[0] ^= 0xff
+	if err := VerifyProof(root, proof); !errors.Is(err, errInvalidProof) {
+		t.Errorf("tampered leaf: have %v, want %v", err, errInvalidProof)
+	}
+	proof, _ = m.Prove(5)
+	proof.Siblings[1][0] ^= 0xff
+	if err := VerifyProof(root, proof); !errors.Is(err, errInvalidProof) {
+		t.Errorf("tampered sibling: have %v, want %v", err, errInvalidProof)
+	}
+	proof, _ = m.Prove(12)
+	proof.LeafCount = 14
+	if err := VerifyProof(root, proof); !errors.Is(err, errInvalidProof) {
+		t.Errorf("tampered size: have %v, want %v", err, errInvalidProof)
+	}
+}
+
+// Tests that the accumulator can be truncated and reopened.
+func TestTruncateAndReopen(t *testing.T) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		leaves = makeLeaves(40)
+		fork   = makeLeaves(50)[40:]
+	)
+	m, err := New(db)
+	if err != nil {
+		t.Fatalf("failed to create accumulator: %v", err)
+	}
+	if err := m.Append(leaves[:37]); err != nil {
+		t.Fatalf("failed to append leaves: %v", err)
+	}
+	want := m.Root()
+
+	// Append some leaves on a side chain, truncate and reapply the originals
+	if err := m.Append(fork); err != nil {
+		t.Fatalf("failed to append fork leaves: %v", err)
+	}
+	if err := m.Truncate(37); err != nil {
+		t.Fatalf("failed to truncate accumulator: %v", err)
+	}
+	if have := m.Root(); have != want {
+		t.Fatalf("root mismatch after truncation: have %x, want %x", have, want)
+	}
+	if err := m.Append(leaves[37:]); err != nil {
+		t.Fatalf("failed to append leaves: %v", err)
+	}
+	fresh := mustNew(t)
+	fresh.Append(leaves)
+	if m.Root() != fresh.Root() {
+		t.Fatalf("root mismatch with fresh accumulator")
+	}
+	// Reopen the accumulator from the database and check it's intact
+	reopened, err := New(db)
+	if err != nil {
+		t.Fatalf("failed to reopen accumulator: %v", err)
+	}
+	if reopened.Size() != uint64(len(leaves)) || reopened.Root() != fresh.Root() {
+		t.Fatalf("reopened accumulator mismatch: size %d, root %x", reopened.Size(), reopened.Root())
+	}
+}
+
+func mustNew(t *testing.T) *MMR {
+	m, err := New(rawdb.NewMemoryDatabase())
+	if err != nil {
+		t.Fatalf("failed to create accumulator: %v", err)
+	}
+	return m
+}
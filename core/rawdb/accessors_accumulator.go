@@ -0,0 +1,60 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ReadHeaderAccumulatorSize retrieves the number of headers committed to by
+// the header accumulator.
+func ReadHeaderAccumulatorSize(db ethdb.KeyValueReader) uint64 {
+	data, _ := db.Get(headerAccSizeKey)
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+// WriteHeaderAccumulatorSize stores the number of headers committed to by the
+// header accumulator.
+func WriteHeaderAccumulatorSize(db ethdb.KeyValueWriter, size uint64) {
+	if err := db.Put(headerAccSizeKey, encodeBlockNumber(size)); err != nil {
+		log.Crit("Failed to store the header accumulator size", "err", err)
+	}
+}
+
+// ReadHeaderAccumulatorNode retrieves a node of the header accumulator at the
+// given tree level and index within the level.
+func ReadHeaderAccumulatorNode(db ethdb.KeyValueReader, level uint8, index uint64) (common.Hash, bool) {
+	data, _ := db.Get(headerAccNodeKey(level, index))
+	if len(data) != common.HashLength {
+		return common.Hash{}, false
+	}
+	return common.BytesToHash(data), true
+}
+
+// WriteHeaderAccumulatorNode stores a node of the header accumulator.
+func WriteHeaderAccumulatorNode(db ethdb.KeyValueWriter, level uint8, index uint64, node common.Hash) {
+	if err := db.Put(headerAccNodeKey(level, index), node.Bytes()); err != nil {
+		log.Crit("Failed to store header accumulator node", "err", err)
+	}
+}
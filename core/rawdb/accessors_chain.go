@@ -812,21 +812,29 @@ func writeAncientBlock(op ethdb.AncientWriteOp, block *types.Block, header *type
 	return nil
 }
 
-// DeleteBlock removes all block data associated with a hash.
-func DeleteBlock(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
+// DeleteBlock removes all block data associated with a hash, including its log
+// index entries, which are looked up in the given reader.
+func DeleteBlock(db ethdb.KeyValueWriter, reader ethdb.KeyValueReader, hash common.Hash, number uint64) {
 	DeleteReceipts(db, hash, number)
 	DeleteHeader(db, hash, number)
 	DeleteBody(db, hash, number)
 	DeleteTd(db, hash, number)
+	if addresses, ok := ReadBlockLogAddresses(reader, hash, number); ok {
+		DeleteBlockLogs(db, hash, number, addresses)
+	}
 }
 
 // DeleteBlockWithoutNumber removes all block data associated with a hash, except
-// the hash to number mapping.
-func DeleteBlockWithoutNumber(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
+// the hash to number mapping. The log index entries of the block, looked up in
+// the given reader, are deleted too.
+func DeleteBlockWithoutNumber(db ethdb.KeyValueWriter, reader ethdb.KeyValueReader, hash common.Hash, number uint64) {
 	DeleteReceipts(db, hash, number)
 	deleteHeaderWithoutNumber(db, hash, number)
 	DeleteBody(db, hash, number)
 	DeleteTd(db, hash, number)
+	if addresses, ok := ReadBlockLogAddresses(reader, hash, number); ok {
+		DeleteBlockLogs(db, hash, number, addresses)
+	}
 }
 
 const badBlockToKeep = 10
@@ -0,0 +1,173 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// The log index stores the logs of a block column-oriented, grouped by emitting
+// contract: a directory entry per block lists the addresses that emitted logs,
+// and a separate entry per (block, address) holds the logs of that address.
+// Address-filtered log queries can thus be answered without decoding any
+// receipts of the block.
+
+// storedColumnLog is the storage encoding of a log in the log index. The address
+// and the block are part of the database key, the transaction hash is derived
+// from the block body on demand.
+type storedColumnLog struct {
+	Topics  []common.Hash
+	Data    []byte
+	TxIndex uint64
+	Index   uint64
+}
+
+// ReadBlockLogAddresses retrieves the addresses which emitted logs in the given
+// block. The boolean reports whether the block is present in the log index.
+func ReadBlockLogAddresses(db ethdb.KeyValueReader, hash common.Hash, number uint64) ([]common.Address, bool) {
+	data, _ := db.Get(blockLogsKey(number, hash))
+	if len(data) == 0 {
+		return nil, false
+	}
+	var addresses []common.Address
+	if err := rlp.DecodeBytes(data, &addresses); err != nil {
+		log.Error("Invalid log index directory RLP", "hash", hash, "err", err)
+		return nil, false
+	}
+	return addresses, true
+}
+
+// HasBlockLogs verifies the existence of the log index entries of a block.
+func HasBlockLogs(db ethdb.KeyValueReader, hash common.Hash, number uint64) bool {
+	has, _ := db.Has(blockLogsKey(number, hash))
+	return has
+}
+
+// WriteBlockLogs stores the logs of a block into the log index. The logs are
+// expected to be grouped by transaction, in the order of the block; the log
+// and transaction positions are derived from that order.
+func WriteBlockLogs(db ethdb.KeyValueWriter, hash common.Hash, number uint64, logs [][]*types.Log) {
+	var (
+		addresses []common.Address
+		columns   = make(map[common.Address][]storedColumnLog)
+		logIndex  uint64
+	)
+	for txIndex, txLogs := range logs {
+		for _, l := range txLogs {
+			if _, ok := columns[l.Address]; !ok {
+				addresses = append(addresses, l.Address)
+			}
+			columns[l.Address] = append(columns[l.Address], storedColumnLog{
+				Topics:  l.Topics,
+				Data:    l.Data,
+				TxIndex: uint64(txIndex),
+				Index:   logIndex,
+			})
+			logIndex++
+		}
+	}
+	for _, address := range addresses {
+		data, err := rlp.EncodeToBytes(columns[address])
+		if err != nil {
+			log.Crit("Failed to encode block logs", "err", err)
+		}
+		if err := db.Put(blockAddressLogsKey(number, hash, address), data); err != nil {
+			log.Crit("Failed to store block logs", "err", err)
+		}
+	}
+	// Write the directory last, it marks the block as indexed
+	data, err := rlp.EncodeToBytes(addresses)
+	if err != nil {
+		log.Crit("Failed to encode log index directory", "err", err)
+	}
+	if err := db.Put(blockLogsKey(number, hash), data); err != nil {
+		log.Crit("Failed to store log index directory", "err", err)
+	}
+}
+
+// WriteReceiptLogs stores the logs contained in the given receipts into the log
+// index.
+func WriteReceiptLogs(db ethdb.KeyValueWriter, hash common.Hash, number uint64, receipts types.Receipts) {
+	logs := make([][]*types.Log, len(receipts))
+	for i, receipt := range receipts {
+		logs[i] = receipt.Logs
+	}
+	WriteBlockLogs(db, hash, number, logs)
+}
+
+// ReadBlockLogsByAddress retrieves the logs emitted by any of the given addresses
+// in a block from the log index, ordered by their position in the block. The
+// block number, block hash, transaction index and log index are filled in, the
+// transaction hash is not. The boolean reports whether the block is present in
+// the log index; if not, the logs need to be read from the receipts instead.
+func ReadBlockLogsByAddress(db ethdb.KeyValueReader, hash common.Hash, number uint64, addresses []common.Address) ([]*types.Log, bool) {
+	indexed, ok := ReadBlockLogAddresses(db, hash, number)
+	if !ok {
+		return nil, false
+	}
+	wanted := make(map[common.Address]struct{}, len(addresses))
+	for _, address := range addresses {
+		wanted[address] = struct{}{}
+	}
+	var logs []*types.Log
+	for _, address := range indexed {
+		if _, ok := wanted[address]; !ok {
+			continue
+		}
+		data, _ := db.Get(blockAddressLogsKey(number, hash, address))
+		if len(data) == 0 {
+			return nil, false
+		}
+		var stored []storedColumnLog
+		if err := rlp.DecodeBytes(data, &stored); err != nil {
+			log.Error("Invalid log index RLP", "hash", hash, "address", address, "err", err)
+			return nil, false
+		}
+		for _, l := range stored {
+			logs = append(logs, &types.Log{
+				Address:     address,
+				Topics:      l.Topics,
+				Data:        l.Data,
+				BlockNumber: number,
+				BlockHash:   hash,
+				TxIndex:     uint(l.TxIndex),
+				Index:       uint(l.Index),
+			})
+		}
+	}
+	sort.Slice(logs, func(i, j int) bool { return logs[i].Index < logs[j].Index })
+	return logs, true
+}
+
+// DeleteBlockLogs removes the log index entries of a block. The addresses are
+// the ones recorded in the directory of the block, see ReadBlockLogAddresses.
+func DeleteBlockLogs(db ethdb.KeyValueWriter, hash common.Hash, number uint64, addresses []common.Address) {
+	for _, address := range addresses {
+		if err := db.Delete(blockAddressLogsKey(number, hash, address)); err != nil {
+			log.Crit("Failed to delete block logs", "err", err)
+		}
+	}
+	if err := db.Delete(blockLogsKey(number, hash)); err != nil {
+		log.Crit("Failed to delete log index directory", "err", err)
+	}
+}
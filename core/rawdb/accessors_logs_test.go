@@ -0,0 +1,97 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBlockLogsStorage(t *testing.T) {
+	var (
+		db     = NewMemoryDatabase()
+		hash   = common.HexToHash("0x01")
+		number = uint64(7)
+		addrA  = common.HexToAddress("0xaa")
+		addrB  = common.HexToAddress("0xbb")
+		addrC  = common.HexToAddress("0xcc")
+		topic  = common.HexToHash("0x02")
+	)
+	if _, ok := ReadBlockLogsByAddress(db, hash, number, []common.Address{addrA}); ok {
+		t.Fatal("unindexed block reported as indexed")
+	}
+	receipts := types.Receipts{
+		{Logs: []*types.Log{{Address: addrA, Topics: []common.Hash{topic}, Data: []byte{0x1}}, {Address: addrB}}},
+		{},
+		{Logs: []*types.Log{{Address: addrA, Data: []byte{0x2}}}},
+	}
+	// The log index is opt-in, storing the receipts must not index them
+	WriteReceipts(db, hash, number, receipts)
+	if HasBlockLogs(db, hash, number) {
+		t.Fatal("receipts indexed without the log index")
+	}
+	WriteReceiptLogs(db, hash, number, receipts)
+
+	if !HasBlockLogs(db, hash, number) {
+		t.Fatal("block logs not indexed")
+	}
+	addresses, ok := ReadBlockLogAddresses(db, hash, number)
+	if !ok || !reflect.DeepEqual(addresses, []common.Address{addrA, addrB}) {
+		t.Fatalf("address directory mismatch: have %v (%v)", addresses, ok)
+	}
+	logs, ok := ReadBlockLogsByAddress(db, hash, number, []common.Address{addrB, addrA})
+	if !ok {
+		t.Fatal("indexed block reported as unindexed")
+	}
+	want := []*types.Log{
+		{Address: addrA, Topics: []common.Hash{topic}, Data: []byte{0x1}, BlockNumber: number, BlockHash: hash, TxIndex: 0, Index: 0},
+		{Address: addrB, Topics: []common.Hash{}, Data: []byte{}, BlockNumber: number, BlockHash: hash, TxIndex: 0, Index: 1},
+		{Address: addrA, Topics: []common.Hash{}, Data: []byte{0x2}, BlockNumber: number, BlockHash: hash, TxIndex: 2, Index: 2},
+	}
+	if len(logs) != len(want) {
+		t.Fatalf("log count mismatch: have %d, want %d", len(logs), len(want))
+	}
+	for i := range want {
+		if logs[i].Address != want[i].Address || logs[i].TxIndex != want[i].TxIndex || logs[i].Index != want[i].Index ||
+			logs[i].BlockHash != hash || logs[i].BlockNumber != number ||
+			len(logs[i].Topics) != len(want[i].Topics) || string(logs[i].Data) != string(want[i].Data) {
+			t.Fatalf("log %d mismatch: have %+v, want %+v", i, logs[i], want[i])
+		}
+	}
+	if logs, ok := ReadBlockLogsByAddress(db, hash, number, []common.Address{addrC}); !ok || len(logs) != 0 {
+		t.Fatalf("unexpected logs for silent address: %v (%v)", logs, ok)
+	}
+	// Deleting the block, with or without its number mapping, drops the index
+	for i, remove := range []func(){
+		func() { DeleteBlock(db, db, hash, number) },
+		func() { DeleteBlockWithoutNumber(db, db, hash, number) },
+	} {
+		WriteReceiptLogs(db, hash, number, receipts)
+		remove()
+		if HasBlockLogs(db, hash, number) {
+			t.Fatalf("test %d: block logs not deleted", i)
+		}
+		for _, addr := range addresses {
+			if data, _ := db.Get(blockAddressLogsKey(number, hash, addr)); len(data) != 0 {
+				t.Fatalf("test %d: logs of %x not deleted", i, addr)
+			}
+		}
+	}
+}
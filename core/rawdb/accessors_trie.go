@@ -24,7 +24,6 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
-	"golang.org/x/crypto/sha3"
 )
 
 // HashScheme is the legacy hash-based state scheme with which trie nodes are
@@ -46,11 +45,16 @@ const HashScheme = "hash"
 // on extra state diffs to survive deep reorg.
 const PathScheme = "path"
 
+// BinaryTrieLayout is the experimental state layout in which the account and
+// storage tries are binary tries instead of hexary merkle-patricia tries. It
+// can only be selected at genesis and is only supported by the hash scheme.
+const BinaryTrieLayout = "binary"
+
 // hasher is used to compute the sha256 hash of the provided data.
 type hasher struct{ sha crypto.KeccakState }
 
 var hasherPool = sync.Pool{
-	New: func() interface{} { return &hasher{sha: sha3.NewLegacyKeccak256().(crypto.KeccakState)} },
+	New: func() interface{} { return &hasher{sha: crypto.NewKeccakState()} },
 }
 
 func newHasher() *hasher {
@@ -311,6 +315,20 @@ func ReadStateScheme(db ethdb.Reader) string {
 	return HashScheme
 }
 
+// ReadStateLayout retrieves the state layout selected at genesis, or none if
+// the default merkle-patricia layout is used.
+func ReadStateLayout(db ethdb.KeyValueReader) string {
+	blob, _ := db.Get(stateLayoutKey)
+	return string(blob)
+}
+
+// WriteStateLayout stores the state layout selected at genesis.
+func WriteStateLayout(db ethdb.KeyValueWriter, layout string) {
+	if err := db.Put(stateLayoutKey, []byte(layout)); err != nil {
+		log.Crit("Failed to store state layout", "err", err)
+	}
+}
+
 // ParseStateScheme checks if the specified state scheme is compatible with
 // the stored state.
 //
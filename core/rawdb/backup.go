@@ -0,0 +1,147 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// backupAncientBatch is the maximum number of ancient items copied at once.
+const backupAncientBatch = 1024
+
+// BackupStats summarizes a database backup.
+type BackupStats struct {
+	Head     uint64             `json:"head"`     // Head block at the time of the backup
+	Keys     uint64             `json:"keys"`     // Number of key-value entries copied
+	Size     common.StorageSize `json:"size"`     // Total size of the copied key-value entries
+	Ancients uint64             `json:"ancients"` // Number of chain freezer items copied
+}
+
+// Backup copies a consistent point-in-time image of db into the empty database
+// dest, while db remains in use. The key-value store is copied from a snapshot,
+// while the chain freezer, being append-only, is copied up to the head block of
+// the snapshot afterwards. Ancient items that were frozen after the snapshot was
+// taken are present in both stores of the backup, which is harmless.
+func Backup(db ethdb.Database, dest ethdb.Database) (*BackupStats, error) {
+	if tail, err := db.Tail(); err == nil && tail != 0 {
+		return nil, fmt.Errorf("backup of pruned ancient store (tail %d) not supported", tail)
+	}
+	snap, err := db.NewSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	defer snap.Release()
+
+	var (
+		stats  = new(BackupStats)
+		start  = time.Now()
+		logged = time.Now()
+		batch  = dest.NewBatch()
+		it     = snap.NewIterator(nil, nil)
+	)
+	defer it.Release()
+
+	if number := ReadHeaderNumber(snap, ReadHeadBlockHash(snap)); number != nil {
+		stats.Head = *number
+	}
+	for it.Next() {
+		if err := batch.Put(it.Key(), it.Value()); err != nil {
+			return nil, err
+		}
+		stats.Keys++
+		stats.Size += common.StorageSize(len(it.Key()) + len(it.Value()))
+
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return nil, err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Backing up key-value store", "keys", stats.Keys, "size", stats.Size, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	if err := batch.Write(); err != nil {
+		return nil, err
+	}
+	// Copy the ancient items belonging to the snapshotted chain
+	frozen, err := db.Ancients()
+	if errors.Is(err, errNotSupported) {
+		frozen, err = 0, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	frozen = min(frozen, stats.Head+1)
+
+	kinds := make([]string, 0, len(chainFreezerNoSnappy))
+	for kind := range chainFreezerNoSnappy {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for stats.Ancients < frozen {
+		var (
+			items = make(map[string][][]byte, len(kinds))
+			count = min(frozen-stats.Ancients, backupAncientBatch)
+		)
+		for _, kind := range kinds {
+			blobs, err := db.AncientRange(kind, stats.Ancients, count, 0)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read ancient %s #%d: %v", kind, stats.Ancients, err)
+			}
+			items[kind] = blobs
+			count = min(count, uint64(len(blobs)))
+		}
+		_, err := dest.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+			for i := uint64(0); i < count; i++ {
+				for _, kind := range kinds {
+					if err := op.AppendRaw(kind, stats.Ancients+i, items[kind][i]); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		stats.Ancients += count
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Backing up ancient store", "items", stats.Ancients, "total", frozen, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if stats.Ancients > 0 {
+		if err := dest.Sync(); err != nil {
+			return nil, err
+		}
+	}
+	log.Info("Backed up database", "head", stats.Head, "keys", stats.Keys, "size", stats.Size, "ancients", stats.Ancients, "elapsed", common.PrettyDuration(time.Since(start)))
+	return stats, nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+func TestBackup(t *testing.T) {
+	db, err := NewDatabaseWithFreezer(NewMemoryDatabase(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	blocks := makeTestBlocks(10, 1)
+	if _, err := WriteAncientBlocks(db, blocks, makeTestReceipts(10, 1), big.NewInt(1)); err != nil {
+		t.Fatalf("failed to write ancient blocks: %v", err)
+	}
+	// Pretend the head is below the freezer limit, the items above it must
+	// not be backed up
+	head := blocks[7]
+	WriteHeaderNumber(db, head.Hash(), head.NumberU64())
+	WriteHeadBlockHash(db, head.Hash())
+	db.Put([]byte("extra"), []byte("value"))
+
+	dest, err := NewDatabaseWithFreezer(NewMemoryDatabase(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatalf("failed to create backup database: %v", err)
+	}
+	defer dest.Close()
+
+	stats, err := Backup(db, dest)
+	if err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+	if stats.Head != 7 || stats.Keys != 3 || stats.Ancients != 8 {
+		t.Fatalf("backup stats mismatch: %+v", stats)
+	}
+	if frozen, _ := dest.Ancients(); frozen != 8 {
+		t.Fatalf("backed up ancients mismatch: have %d, want 8", frozen)
+	}
+	for _, block := range blocks[:8] {
+		header := ReadHeader(dest, block.Hash(), block.NumberU64())
+		if header == nil || header.Hash() != block.Hash() {
+			t.Fatalf("block #%d missing from backup", block.NumberU64())
+		}
+		if body := ReadBody(dest, block.Hash(), block.NumberU64()); body == nil || len(body.Transactions) != len(block.Transactions()) {
+			t.Fatalf("body #%d mismatch in backup", block.NumberU64())
+		}
+	}
+	if have, _ := dest.Get([]byte("extra")); !bytes.Equal(have, []byte("value")) {
+		t.Fatalf("key-value entry mismatch: have %x", have)
+	}
+	if ReadHeadBlockHash(dest) != head.Hash() {
+		t.Fatal("head block hash missing from backup")
+	}
+}
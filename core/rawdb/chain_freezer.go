@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/maintenance"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params/vars"
@@ -147,6 +148,10 @@ func (f *chainFreezer) freeze(db ethdb.KeyValueStore) {
 			continue
 		}
 
+		// Hold off migrating blocks while background maintenance is paused
+		if !maintenance.Wait(maintenance.TaskFreezer, f.quit) {
+			return
+		}
 		// Seems we have data ready to be frozen, process in usable batches
 		var (
 			start    = time.Now()
@@ -173,7 +178,7 @@ func (f *chainFreezer) freeze(db ethdb.KeyValueStore) {
 		for i := 0; i < len(ancients); i++ {
 			// Always keep the genesis block in active database
 			if first+uint64(i) != 0 {
-				DeleteBlockWithoutNumber(batch, ancients[i], first+uint64(i))
+				DeleteBlockWithoutNumber(batch, db, ancients[i], first+uint64(i))
 				DeleteCanonicalHash(batch, first+uint64(i))
 			}
 		}
@@ -191,7 +196,7 @@ func (f *chainFreezer) freeze(db ethdb.KeyValueStore) {
 				dangling = ReadAllHashes(db, number)
 				for _, hash := range dangling {
 					log.Trace("Deleting side chain", "number", number, "hash", hash)
-					DeleteBlock(batch, hash, number)
+					DeleteBlock(batch, db, hash, number)
 				}
 			}
 		}
@@ -224,7 +229,7 @@ func (f *chainFreezer) freeze(db ethdb.KeyValueStore) {
 					}
 					// Delete all block data associated with the child
 					log.Debug("Deleting dangling block", "number", tip, "hash", children[i], "parent", child.ParentHash)
-					DeleteBlock(batch, children[i], tip)
+					DeleteBlock(batch, db, children[i], tip)
 				}
 				dangling = children
 				tip++
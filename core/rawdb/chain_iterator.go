@@ -23,6 +23,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/prque"
+	"github.com/ethereum/go-ethereum/core/maintenance"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
@@ -88,11 +89,21 @@ type blockTxHashes struct {
 	hashes []common.Hash
 }
 
+// TxFilter reports whether a transaction should be included in the transaction
+// lookup index. A nil filter includes all transactions.
+type TxFilter func(tx *types.Transaction) bool
+
 // iterateTransactions iterates over all transactions in the (canon) block
 // number(s) given, and yields the hashes on a channel. If there is a signal
 // received from interrupt channel, the iteration will be aborted and result
 // channel will be closed.
 func iterateTransactions(db ethdb.Database, from uint64, to uint64, reverse bool, interrupt chan struct{}) chan *blockTxHashes {
+	return iterateFilteredTransactions(db, from, to, reverse, nil, interrupt)
+}
+
+// iterateFilteredTransactions is identical to iterateTransactions, but only yields
+// the hashes of the transactions accepted by the filter.
+func iterateFilteredTransactions(db ethdb.Database, from uint64, to uint64, reverse bool, filter TxFilter, interrupt chan struct{}) chan *blockTxHashes {
 	// One thread sequentially reads data from db
 	type numberRlp struct {
 		number uint64
@@ -117,6 +128,10 @@ func iterateTransactions(db ethdb.Database, from uint64, to uint64, reverse bool
 		}
 		defer close(rlpCh)
 		for n != end {
+			// Hold off while background maintenance is paused
+			if !maintenance.Wait(maintenance.TaskTxIndex, interrupt) {
+				return
+			}
 			data := ReadCanonicalBodyRLP(db, n)
 			// Feed the block to the aggregator, or abort on interrupt
 			select {
@@ -149,6 +164,9 @@ func iterateTransactions(db ethdb.Database, from uint64, to uint64, reverse bool
 			}
 			var hashes []common.Hash
 			for _, tx := range body.Transactions {
+				if filter != nil && !filter(tx) {
+					continue
+				}
 				hashes = append(hashes, tx.Hash())
 			}
 			result := &blockTxHashes{
@@ -178,13 +196,13 @@ func iterateTransactions(db ethdb.Database, from uint64, to uint64, reverse bool
 //
 // There is a passed channel, the whole procedure will be interrupted if any
 // signal received.
-func indexTransactions(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}, hook func(uint64) bool, report bool) {
+func indexTransactions(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}, filter TxFilter, hook func(uint64) bool, report bool) {
 	// short circuit for invalid range
 	if from >= to {
 		return
 	}
 	var (
-		hashesCh = iterateTransactions(db, from, to, true, interrupt)
+		hashesCh = iterateFilteredTransactions(db, from, to, true, filter, interrupt)
 		batch    = db.NewBatch()
 		start    = time.Now()
 		logged   = start.Add(-7 * time.Second)
@@ -262,12 +280,18 @@ func indexTransactions(db ethdb.Database, from uint64, to uint64, interrupt chan
 // There is a passed channel, the whole procedure will be interrupted if any
 // signal received.
 func IndexTransactions(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}, report bool) {
-	indexTransactions(db, from, to, interrupt, nil, report)
+	indexTransactions(db, from, to, interrupt, nil, nil, report)
+}
+
+// IndexFilteredTransactions is identical to IndexTransactions, but only indexes
+// the transactions accepted by the filter.
+func IndexFilteredTransactions(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}, filter TxFilter, report bool) {
+	indexTransactions(db, from, to, interrupt, filter, nil, report)
 }
 
 // indexTransactionsForTesting is the internal debug version with an additional hook.
 func indexTransactionsForTesting(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}, hook func(uint64) bool) {
-	indexTransactions(db, from, to, interrupt, hook, false)
+	indexTransactions(db, from, to, interrupt, nil, hook, false)
 }
 
 // unindexTransactions removes txlookup indices of the specified block range.
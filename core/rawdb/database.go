@@ -20,6 +20,8 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
@@ -30,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/ethdb/leveldb"
 	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/ethdb/overlaydb"
 	"github.com/ethereum/go-ethereum/ethdb/pebble"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/olekukonko/tablewriter"
@@ -363,6 +366,11 @@ type OpenOptions struct {
 	Cache             int    // the capacity(in megabytes) of the data caching
 	Handles           int    // number of files to be open simultaneously
 	ReadOnly          bool
+	// OverlayDirectory, if set, opens the datadir read-only and redirects all
+	// writes into a copy-on-write key-value database at the given path. The
+	// ancient store is copied into the overlay when first opened, so that the
+	// overlay can freeze and truncate its own chain data.
+	OverlayDirectory string
 	// Ephemeral means that filesystem sync operations should be avoided: data integrity in the face of
 	// a crash is not important. This option should typically be used in tests.
 	Ephemeral bool
@@ -404,6 +412,9 @@ func openKeyValueDatabase(o OpenOptions) (ethdb.Database, error) {
 // The passed o.AncientDir indicates the path of root ancient directory where
 // the chain freezer can be opened.
 func Open(o OpenOptions) (ethdb.Database, error) {
+	if len(o.OverlayDirectory) != 0 && !o.ReadOnly {
+		return openOverlay(o)
+	}
 	kvdb, err := openKeyValueDatabase(o)
 	if err != nil {
 		return nil, err
@@ -419,39 +430,260 @@ func Open(o OpenOptions) (ethdb.Database, error) {
 	return frdb, nil
 }
 
-type counter uint64
+// openOverlay opens the key-value database of the datadir read-only and layers
+// a writable copy-on-write database on top of it, so that experiments can be
+// run against existing chain data and thrown away afterwards.
+func openOverlay(o OpenOptions) (ethdb.Database, error) {
+	baseOpts := o
+	baseOpts.ReadOnly = true
+	baseOpts.Namespace = o.Namespace + "base/"
+	base, err := openKeyValueDatabase(baseOpts)
+	if err != nil {
+		return nil, err
+	}
+	overlayOpts := o
+	overlayOpts.Directory = o.OverlayDirectory
+	if len(overlayOpts.Type) == 0 {
+		overlayOpts.Type = PreexistingDatabase(o.Directory)
+	}
+	overlay, err := openKeyValueDatabase(overlayOpts)
+	if err != nil {
+		base.Close()
+		return nil, err
+	}
+	log.Warn("Using copy-on-write overlay database, changes will not reach the datadir", "datadir", o.Directory, "overlay", o.OverlayDirectory)
 
-func (c counter) String() string {
-	return fmt.Sprintf("%d", c)
+	kvdb := overlaydb.New(base, overlay)
+	if len(o.AncientsDirectory) == 0 {
+		return NewDatabase(kvdb), nil
+	}
+	ancients := filepath.Join(o.OverlayDirectory, "ancient")
+	if err := copyAncients(o.AncientsDirectory, ancients); err != nil {
+		kvdb.Close()
+		return nil, fmt.Errorf("failed to copy ancient store into overlay: %v", err)
+	}
+	frdb, err := NewDatabaseWithFreezer(kvdb, ancients, o.Namespace, false)
+	if err != nil {
+		kvdb.Close()
+		return nil, err
+	}
+	return frdb, nil
 }
 
-func (c counter) Percentage(current uint64) string {
-	return fmt.Sprintf("%d", current*100/uint64(c))
+// copyAncients copies the ancient store of the datadir into the overlay, unless
+// the overlay already has one. The freezer files are appended to and truncated
+// in place, so they can't be shared with the datadir. On Linux the copy uses
+// copy_file_range, which shares the data blocks on copy-on-write filesystems.
+func copyAncients(src, dst string) error {
+	if common.FileExist(dst) {
+		return nil
+	}
+	if !common.FileExist(src) {
+		return nil // Nothing frozen yet, start with an empty freezer
+	}
+	log.Info("Copying ancient store into overlay", "src", src, "dst", dst)
+
+	// Copy into a temporary folder first, so an interrupted copy is redone
+	tmp := dst + ".tmp"
+	if err := os.RemoveAll(tmp); err != nil {
+		return err
+	}
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(tmp, rel)
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, 0755)
+		case d.Name() == "FLOCK":
+			return nil // Lock of the datadir's freezer, a new one is created
+		default:
+			return copyAncientFile(path, target)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, dst)
 }
 
-// stat stores sizes and count for a parameter
-type stat struct {
-	size  common.StorageSize
-	count counter
+// copyAncientFile copies a single freezer file.
+func copyAncientFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
 }
 
-// Add size to the stat and increase the counter by 1
-func (s *stat) Add(size common.StorageSize) {
-	s.size += size
-	s.count++
+// KeyValueStat is the total size and number of the entries of a category of
+// data in the key-value store.
+type KeyValueStat struct {
+	Size  common.StorageSize
+	Count uint64
 }
 
-func (s *stat) Size() string {
-	return s.size.String()
+// Add adds an entry of the given size to the stat.
+func (s *KeyValueStat) Add(size common.StorageSize) {
+	s.Size += size
+	s.Count++
 }
 
-func (s *stat) Count() string {
-	return s.count.String()
+// Categories of data in the key-value store, as reported by InspectDatabase.
+const (
+	categoryHeaders         = "Headers"
+	categoryBodies          = "Bodies"
+	categoryReceipts        = "Receipt lists"
+	categoryLogs            = "Log index"
+	categoryTDs             = "Difficulties"
+	categoryNumHashPairings = "Block number->hash"
+	categoryHashNumPairings = "Block hash->number"
+	categoryTxLookups       = "Transaction index"
+	categoryBloomBits       = "Bloombit index"
+	categoryCodes           = "Contract codes"
+	categoryLegacyTries     = "Hash trie nodes"
+	categoryStateLookups    = "Path trie state lookups"
+	categoryAccountTries    = "Path trie account nodes"
+	categoryStorageTries    = "Path trie storage nodes"
+	categoryPreimages       = "Trie preimages"
+	categoryAccountSnaps    = "Account snapshot"
+	categoryStorageSnaps    = "Storage snapshot"
+	categoryBeaconHeaders   = "Beacon sync headers"
+	categoryCliqueSnaps     = "Clique snapshots"
+	categoryHeaderAcc       = "Header accumulator"
+	categoryMetadata        = "Singleton metadata"
+	categoryChtTrieNodes    = "CHT trie nodes"
+	categoryBloomTrieNodes  = "Bloom trie nodes"
+
+	// CategoryUnaccounted is the category of entries not matching the schema.
+	CategoryUnaccounted = "Unaccounted"
+)
+
+// KeyValueCategories lists the categories of data in the key-value store, with
+// the database they belong to, in the order they are reported.
+var KeyValueCategories = [][2]string{
+	{"Key-Value store", categoryHeaders},
+	{"Key-Value store", categoryBodies},
+	{"Key-Value store", categoryReceipts},
+	{"Key-Value store", categoryLogs},
+	{"Key-Value store", categoryTDs},
+	{"Key-Value store", categoryNumHashPairings},
+	{"Key-Value store", categoryHashNumPairings},
+	{"Key-Value store", categoryTxLookups},
+	{"Key-Value store", categoryBloomBits},
+	{"Key-Value store", categoryCodes},
+	{"Key-Value store", categoryLegacyTries},
+	{"Key-Value store", categoryStateLookups},
+	{"Key-Value store", categoryAccountTries},
+	{"Key-Value store", categoryStorageTries},
+	{"Key-Value store", categoryPreimages},
+	{"Key-Value store", categoryAccountSnaps},
+	{"Key-Value store", categoryStorageSnaps},
+	{"Key-Value store", categoryBeaconHeaders},
+	{"Key-Value store", categoryCliqueSnaps},
+	{"Key-Value store", categoryHeaderAcc},
+	{"Key-Value store", categoryMetadata},
+	{"Light client", categoryChtTrieNodes},
+	{"Light client", categoryBloomTrieNodes},
+}
+
+// metadataKeys are the keys of singleton metadata entries.
+var metadataKeys = [][]byte{
+	databaseVersionKey, headHeaderKey, headBlockKey, headFastBlockKey, headFinalizedBlockKey,
+	lastPivotKey, fastTrieProgressKey, snapshotDisabledKey, SnapshotRootKey, snapshotJournalKey,
+	snapshotGeneratorKey, snapshotRecoveryKey, txIndexTailKey, fastTxLookupLimitKey,
+	uncleanShutdownKey, badBlockKey, transitionStatusKey, skeletonSyncStatusKey,
+	persistentStateIDKey, trieJournalKey, snapshotSyncStatusKey, snapSyncStatusFlagKey,
+	headerAccSizeKey, stateLayoutKey,
+}
+
+// KeyValueCategory returns the category of data a key-value store entry belongs
+// to, or CategoryUnaccounted if it doesn't match the schema.
+func KeyValueCategory(key, value []byte) string {
+	switch {
+	case bytes.HasPrefix(key, headerPrefix) && len(key) == (len(headerPrefix)+8+common.HashLength):
+		return categoryHeaders
+	case bytes.HasPrefix(key, blockBodyPrefix) && len(key) == (len(blockBodyPrefix)+8+common.HashLength):
+		return categoryBodies
+	case bytes.HasPrefix(key, blockReceiptsPrefix) && len(key) == (len(blockReceiptsPrefix)+8+common.HashLength):
+		return categoryReceipts
+	case bytes.HasPrefix(key, blockLogsPrefix) && (len(key) == (len(blockLogsPrefix)+8+common.HashLength) || len(key) == (len(blockLogsPrefix)+8+common.HashLength+common.AddressLength)):
+		return categoryLogs
+	case bytes.HasPrefix(key, headerPrefix) && bytes.HasSuffix(key, headerTDSuffix):
+		return categoryTDs
+	case bytes.HasPrefix(key, headerPrefix) && bytes.HasSuffix(key, headerHashSuffix):
+		return categoryNumHashPairings
+	case bytes.HasPrefix(key, headerNumberPrefix) && len(key) == (len(headerNumberPrefix)+common.HashLength):
+		return categoryHashNumPairings
+	case IsLegacyTrieNode(key, value):
+		return categoryLegacyTries
+	case bytes.HasPrefix(key, stateIDPrefix) && len(key) == len(stateIDPrefix)+common.HashLength:
+		return categoryStateLookups
+	case IsAccountTrieNode(key):
+		return categoryAccountTries
+	case IsStorageTrieNode(key):
+		return categoryStorageTries
+	case bytes.HasPrefix(key, CodePrefix) && len(key) == len(CodePrefix)+common.HashLength:
+		return categoryCodes
+	case bytes.HasPrefix(key, txLookupPrefix) && len(key) == (len(txLookupPrefix)+common.HashLength):
+		return categoryTxLookups
+	case bytes.HasPrefix(key, SnapshotAccountPrefix) && len(key) == (len(SnapshotAccountPrefix)+common.HashLength):
+		return categoryAccountSnaps
+	case bytes.HasPrefix(key, SnapshotStoragePrefix) && len(key) == (len(SnapshotStoragePrefix)+2*common.HashLength):
+		return categoryStorageSnaps
+	case bytes.HasPrefix(key, PreimagePrefix) && len(key) == (len(PreimagePrefix)+common.HashLength):
+		return categoryPreimages
+	case bytes.HasPrefix(key, configPrefix) && len(key) == (len(configPrefix)+common.HashLength):
+		return categoryMetadata
+	case bytes.HasPrefix(key, genesisPrefix) && len(key) == (len(genesisPrefix)+common.HashLength):
+		return categoryMetadata
+	case bytes.HasPrefix(key, inspectSnapshotPrefix) && len(key) == (len(inspectSnapshotPrefix)+8):
+		return categoryMetadata
+	case bytes.HasPrefix(key, bloomBitsPrefix) && len(key) == (len(bloomBitsPrefix)+10+common.HashLength):
+		return categoryBloomBits
+	case bytes.HasPrefix(key, BloomBitsIndexPrefix):
+		return categoryBloomBits
+	case bytes.HasPrefix(key, skeletonHeaderPrefix) && len(key) == (len(skeletonHeaderPrefix)+8):
+		return categoryBeaconHeaders
+	case bytes.HasPrefix(key, CliqueSnapshotPrefix) && len(key) == 7+common.HashLength:
+		return categoryCliqueSnaps
+	case bytes.HasPrefix(key, headerAccNodePrefix) && len(key) == (len(headerAccNodePrefix)+1+8):
+		return categoryHeaderAcc
+	case bytes.HasPrefix(key, ChtTablePrefix) ||
+		bytes.HasPrefix(key, ChtIndexTablePrefix) ||
+		bytes.HasPrefix(key, ChtPrefix): // Canonical hash trie
+		return categoryChtTrieNodes
+	case bytes.HasPrefix(key, BloomTrieTablePrefix) ||
+		bytes.HasPrefix(key, BloomTrieIndexPrefix) ||
+		bytes.HasPrefix(key, BloomTriePrefix): // Bloomtrie sub
+		return categoryBloomTrieNodes
+	}
+	for _, meta := range metadataKeys {
+		if bytes.Equal(key, meta) {
+			return categoryMetadata
+		}
+	}
+	return CategoryUnaccounted
 }
 
-// InspectDatabase traverses the entire database and checks the size
-// of all different categories of data.
-func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
+// InspectKeyValueStore traverses the key-value store and checks the size of all
+// different categories of data.
+func InspectKeyValueStore(db ethdb.Iteratee, keyPrefix, keyStart []byte) (map[string]*KeyValueStat, error) {
 	it := db.NewIterator(keyPrefix, keyStart)
 	defer it.Release()
 
@@ -459,144 +691,47 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		count  int64
 		start  = time.Now()
 		logged = time.Now()
-
-		// Key-value store statistics
-		headers         stat
-		bodies          stat
-		receipts        stat
-		tds             stat
-		numHashPairings stat
-		hashNumPairings stat
-		legacyTries     stat
-		stateLookups    stat
-		accountTries    stat
-		storageTries    stat
-		codes           stat
-		txLookups       stat
-		accountSnaps    stat
-		storageSnaps    stat
-		preimages       stat
-		bloomBits       stat
-		beaconHeaders   stat
-		cliqueSnaps     stat
-
-		// Les statistic
-		chtTrieNodes   stat
-		bloomTrieNodes stat
-
-		// Meta- and unaccounted data
-		metadata    stat
-		unaccounted stat
-
-		// Totals
-		total common.StorageSize
+		stats  = make(map[string]*KeyValueStat)
 	)
-	// Inspect key-value database first.
+	for _, category := range KeyValueCategories {
+		stats[category[1]] = new(KeyValueStat)
+	}
+	stats[CategoryUnaccounted] = new(KeyValueStat)
+
 	for it.Next() {
 		var (
 			key  = it.Key()
 			size = common.StorageSize(len(key) + len(it.Value()))
 		)
-		total += size
-		switch {
-		case bytes.HasPrefix(key, headerPrefix) && len(key) == (len(headerPrefix)+8+common.HashLength):
-			headers.Add(size)
-		case bytes.HasPrefix(key, blockBodyPrefix) && len(key) == (len(blockBodyPrefix)+8+common.HashLength):
-			bodies.Add(size)
-		case bytes.HasPrefix(key, blockReceiptsPrefix) && len(key) == (len(blockReceiptsPrefix)+8+common.HashLength):
-			receipts.Add(size)
-		case bytes.HasPrefix(key, headerPrefix) && bytes.HasSuffix(key, headerTDSuffix):
-			tds.Add(size)
-		case bytes.HasPrefix(key, headerPrefix) && bytes.HasSuffix(key, headerHashSuffix):
-			numHashPairings.Add(size)
-		case bytes.HasPrefix(key, headerNumberPrefix) && len(key) == (len(headerNumberPrefix)+common.HashLength):
-			hashNumPairings.Add(size)
-		case IsLegacyTrieNode(key, it.Value()):
-			legacyTries.Add(size)
-		case bytes.HasPrefix(key, stateIDPrefix) && len(key) == len(stateIDPrefix)+common.HashLength:
-			stateLookups.Add(size)
-		case IsAccountTrieNode(key):
-			accountTries.Add(size)
-		case IsStorageTrieNode(key):
-			storageTries.Add(size)
-		case bytes.HasPrefix(key, CodePrefix) && len(key) == len(CodePrefix)+common.HashLength:
-			codes.Add(size)
-		case bytes.HasPrefix(key, txLookupPrefix) && len(key) == (len(txLookupPrefix)+common.HashLength):
-			txLookups.Add(size)
-		case bytes.HasPrefix(key, SnapshotAccountPrefix) && len(key) == (len(SnapshotAccountPrefix)+common.HashLength):
-			accountSnaps.Add(size)
-		case bytes.HasPrefix(key, SnapshotStoragePrefix) && len(key) == (len(SnapshotStoragePrefix)+2*common.HashLength):
-			storageSnaps.Add(size)
-		case bytes.HasPrefix(key, PreimagePrefix) && len(key) == (len(PreimagePrefix)+common.HashLength):
-			preimages.Add(size)
-		case bytes.HasPrefix(key, configPrefix) && len(key) == (len(configPrefix)+common.HashLength):
-			metadata.Add(size)
-		case bytes.HasPrefix(key, genesisPrefix) && len(key) == (len(genesisPrefix)+common.HashLength):
-			metadata.Add(size)
-		case bytes.HasPrefix(key, bloomBitsPrefix) && len(key) == (len(bloomBitsPrefix)+10+common.HashLength):
-			bloomBits.Add(size)
-		case bytes.HasPrefix(key, BloomBitsIndexPrefix):
-			bloomBits.Add(size)
-		case bytes.HasPrefix(key, skeletonHeaderPrefix) && len(key) == (len(skeletonHeaderPrefix)+8):
-			beaconHeaders.Add(size)
-		case bytes.HasPrefix(key, CliqueSnapshotPrefix) && len(key) == 7+common.HashLength:
-			cliqueSnaps.Add(size)
-		case bytes.HasPrefix(key, ChtTablePrefix) ||
-			bytes.HasPrefix(key, ChtIndexTablePrefix) ||
-			bytes.HasPrefix(key, ChtPrefix): // Canonical hash trie
-			chtTrieNodes.Add(size)
-		case bytes.HasPrefix(key, BloomTrieTablePrefix) ||
-			bytes.HasPrefix(key, BloomTrieIndexPrefix) ||
-			bytes.HasPrefix(key, BloomTriePrefix): // Bloomtrie sub
-			bloomTrieNodes.Add(size)
-		default:
-			var accounted bool
-			for _, meta := range [][]byte{
-				databaseVersionKey, headHeaderKey, headBlockKey, headFastBlockKey, headFinalizedBlockKey,
-				lastPivotKey, fastTrieProgressKey, snapshotDisabledKey, SnapshotRootKey, snapshotJournalKey,
-				snapshotGeneratorKey, snapshotRecoveryKey, txIndexTailKey, fastTxLookupLimitKey,
-				uncleanShutdownKey, badBlockKey, transitionStatusKey, skeletonSyncStatusKey,
-				persistentStateIDKey, trieJournalKey, snapshotSyncStatusKey, snapSyncStatusFlagKey,
-			} {
-				if bytes.Equal(key, meta) {
-					metadata.Add(size)
-					accounted = true
-					break
-				}
-			}
-			if !accounted {
-				unaccounted.Add(size)
-			}
-		}
+		stats[KeyValueCategory(key, it.Value())].Add(size)
+
 		count++
 		if count%1000 == 0 && time.Since(logged) > 8*time.Second {
 			log.Info("Inspecting database", "count", count, "elapsed", common.PrettyDuration(time.Since(start)))
 			logged = time.Now()
 		}
 	}
-	// Display the database statistic of key-value store.
-	stats := [][]string{
-		{"Key-Value store", "Headers", headers.Size(), headers.Count()},
-		{"Key-Value store", "Bodies", bodies.Size(), bodies.Count()},
-		{"Key-Value store", "Receipt lists", receipts.Size(), receipts.Count()},
-		{"Key-Value store", "Difficulties", tds.Size(), tds.Count()},
-		{"Key-Value store", "Block number->hash", numHashPairings.Size(), numHashPairings.Count()},
-		{"Key-Value store", "Block hash->number", hashNumPairings.Size(), hashNumPairings.Count()},
-		{"Key-Value store", "Transaction index", txLookups.Size(), txLookups.Count()},
-		{"Key-Value store", "Bloombit index", bloomBits.Size(), bloomBits.Count()},
-		{"Key-Value store", "Contract codes", codes.Size(), codes.Count()},
-		{"Key-Value store", "Hash trie nodes", legacyTries.Size(), legacyTries.Count()},
-		{"Key-Value store", "Path trie state lookups", stateLookups.Size(), stateLookups.Count()},
-		{"Key-Value store", "Path trie account nodes", accountTries.Size(), accountTries.Count()},
-		{"Key-Value store", "Path trie storage nodes", storageTries.Size(), storageTries.Count()},
-		{"Key-Value store", "Trie preimages", preimages.Size(), preimages.Count()},
-		{"Key-Value store", "Account snapshot", accountSnaps.Size(), accountSnaps.Count()},
-		{"Key-Value store", "Storage snapshot", storageSnaps.Size(), storageSnaps.Count()},
-		{"Key-Value store", "Beacon sync headers", beaconHeaders.Size(), beaconHeaders.Count()},
-		{"Key-Value store", "Clique snapshots", cliqueSnaps.Size(), cliqueSnaps.Count()},
-		{"Key-Value store", "Singleton metadata", metadata.Size(), metadata.Count()},
-		{"Light client", "CHT trie nodes", chtTrieNodes.Size(), chtTrieNodes.Count()},
-		{"Light client", "Bloom trie nodes", bloomTrieNodes.Size(), bloomTrieNodes.Count()},
+	return stats, it.Error()
+}
+
+// InspectDatabase traverses the entire database and checks the size
+// of all different categories of data.
+func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
+	// Inspect key-value database first.
+	kvstats, err := InspectKeyValueStore(db, keyPrefix, keyStart)
+	if err != nil {
+		return err
+	}
+	var (
+		stats [][]string
+		total common.StorageSize
+	)
+	for _, category := range KeyValueCategories {
+		stat := kvstats[category[1]]
+		stats = append(stats, []string{category[0], category[1], stat.Size.String(), fmt.Sprintf("%d", stat.Count)})
+	}
+	for _, stat := range kvstats {
+		total += stat.Size
 	}
 	// Inspect all registered append-only file store then.
 	ancients, err := inspectFreezers(db)
@@ -620,8 +755,8 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 	table.AppendBulk(stats)
 	table.Render()
 
-	if unaccounted.size > 0 {
-		log.Error("Database contains unaccounted data", "size", unaccounted.size, "count", unaccounted.count)
+	if unaccounted := kvstats[CategoryUnaccounted]; unaccounted.Size > 0 {
+		log.Error("Database contains unaccounted data", "size", unaccounted.Size, "count", unaccounted.Count)
 	}
 	return nil
 }
@@ -0,0 +1,144 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// DescribeEntry decodes a raw database entry according to the known schema,
+// returning the kind of data and a short human readable summary of it. Entries
+// whose content doesn't match their key, e.g. a header stored under the wrong
+// hash, are reported as corrupt. Unknown entries have the kind "unknown".
+func DescribeEntry(key, value []byte) (kind string, summary string) {
+	switch {
+	case bytes.HasPrefix(key, headerPrefix) && len(key) == (len(headerPrefix)+8+common.HashLength):
+		number, hash := keyNumberHash(key, headerPrefix)
+		header := new(types.Header)
+		if err := rlp.DecodeBytes(value, header); err != nil {
+			return "header", fmt.Sprintf("#%d %x: corrupt: %v", number, hash, err)
+		}
+		if header.Hash() != hash || header.Number.Uint64() != number {
+			return "header", fmt.Sprintf("#%d %x: corrupt: content is header #%d %x", number, hash, header.Number, header.Hash())
+		}
+		return "header", fmt.Sprintf("#%d %x: parent %x, root %x", number, hash, header.ParentHash, header.Root)
+
+	case bytes.HasPrefix(key, blockBodyPrefix) && len(key) == (len(blockBodyPrefix)+8+common.HashLength):
+		number, hash := keyNumberHash(key, blockBodyPrefix)
+		body := new(types.Body)
+		if err := rlp.DecodeBytes(value, body); err != nil {
+			return "body", fmt.Sprintf("#%d %x: corrupt: %v", number, hash, err)
+		}
+		return "body", fmt.Sprintf("#%d %x: %d txs, %d uncles", number, hash, len(body.Transactions), len(body.Uncles))
+
+	case bytes.HasPrefix(key, blockReceiptsPrefix) && len(key) == (len(blockReceiptsPrefix)+8+common.HashLength):
+		number, hash := keyNumberHash(key, blockReceiptsPrefix)
+		var receipts []*types.ReceiptForStorage
+		if err := rlp.DecodeBytes(value, &receipts); err != nil {
+			return "receipts", fmt.Sprintf("#%d %x: corrupt: %v", number, hash, err)
+		}
+		return "receipts", fmt.Sprintf("#%d %x: %d receipts", number, hash, len(receipts))
+
+	case bytes.HasPrefix(key, headerPrefix) && len(key) == (len(headerPrefix)+8+common.HashLength+len(headerTDSuffix)) && bytes.HasSuffix(key, headerTDSuffix):
+		number, hash := keyNumberHash(key[:len(key)-len(headerTDSuffix)], headerPrefix)
+		td := new(big.Int)
+		if err := rlp.DecodeBytes(value, td); err != nil {
+			return "total difficulty", fmt.Sprintf("#%d %x: corrupt: %v", number, hash, err)
+		}
+		return "total difficulty", fmt.Sprintf("#%d %x: %v", number, hash, td)
+
+	case bytes.HasPrefix(key, headerPrefix) && len(key) == (len(headerPrefix)+8+len(headerHashSuffix)) && bytes.HasSuffix(key, headerHashSuffix):
+		number := binary.BigEndian.Uint64(key[len(headerPrefix):])
+		if len(value) != common.HashLength {
+			return "canonical hash", fmt.Sprintf("#%d: corrupt: %d byte value", number, len(value))
+		}
+		return "canonical hash", fmt.Sprintf("#%d: %x", number, value)
+
+	case bytes.HasPrefix(key, headerNumberPrefix) && len(key) == (len(headerNumberPrefix)+common.HashLength):
+		hash := key[len(headerNumberPrefix):]
+		if len(value) != 8 {
+			return "block number", fmt.Sprintf("%x: corrupt: %d byte value", hash, len(value))
+		}
+		return "block number", fmt.Sprintf("%x: #%d", hash, binary.BigEndian.Uint64(value))
+
+	case bytes.HasPrefix(key, txLookupPrefix) && len(key) == (len(txLookupPrefix)+common.HashLength):
+		hash := key[len(txLookupPrefix):]
+		if len(value) == common.HashLength {
+			return "tx lookup", fmt.Sprintf("%x: block %x", hash, value) // legacy entry
+		}
+		return "tx lookup", fmt.Sprintf("%x: block #%d", hash, new(big.Int).SetBytes(value))
+
+	case bytes.HasPrefix(key, CodePrefix) && len(key) == len(CodePrefix)+common.HashLength:
+		hash := key[len(CodePrefix):]
+		if !bytes.Equal(hash, crypto.Keccak256(value)) {
+			return "code", fmt.Sprintf("%x: corrupt: hash mismatch", hash)
+		}
+		return "code", fmt.Sprintf("%x: %d bytes", hash, len(value))
+
+	case IsLegacyTrieNode(key, value):
+		return "trie node", fmt.Sprintf("%x: %d bytes", key, len(value))
+
+	case bytes.HasPrefix(key, SnapshotAccountPrefix) && len(key) == (len(SnapshotAccountPrefix)+common.HashLength):
+		hash := key[len(SnapshotAccountPrefix):]
+		account, err := types.FullAccount(value)
+		if err != nil {
+			return "snapshot account", fmt.Sprintf("%x: corrupt: %v", hash, err)
+		}
+		return "snapshot account", fmt.Sprintf("%x: nonce %d, balance %v, root %x", hash, account.Nonce, account.Balance, account.Root)
+
+	case bytes.HasPrefix(key, SnapshotStoragePrefix) && len(key) == (len(SnapshotStoragePrefix)+2*common.HashLength):
+		account := key[len(SnapshotStoragePrefix) : len(SnapshotStoragePrefix)+common.HashLength]
+		slot := key[len(SnapshotStoragePrefix)+common.HashLength:]
+		return "snapshot storage", fmt.Sprintf("%x %x: %x", account, slot, value)
+
+	case bytes.HasPrefix(key, PreimagePrefix) && len(key) == (len(PreimagePrefix)+common.HashLength):
+		hash := key[len(PreimagePrefix):]
+		if !bytes.Equal(hash, crypto.Keccak256(value)) {
+			return "preimage", fmt.Sprintf("%x: corrupt: hash mismatch", hash)
+		}
+		return "preimage", fmt.Sprintf("%x: %x", hash, value)
+
+	case bytes.HasPrefix(key, configPrefix) && len(key) == (len(configPrefix)+common.HashLength):
+		return "chain config", fmt.Sprintf("genesis %x: %s", key[len(configPrefix):], value)
+
+	case bytes.HasPrefix(key, genesisPrefix) && len(key) == (len(genesisPrefix)+common.HashLength):
+		return "genesis state", fmt.Sprintf("genesis %x: %d bytes", key[len(genesisPrefix):], len(value))
+	}
+	for _, meta := range metadataKeys {
+		if bytes.Equal(key, meta) {
+			if len(value) == common.HashLength {
+				return "metadata", fmt.Sprintf("%s: %x", key, value)
+			}
+			return "metadata", fmt.Sprintf("%s: %d bytes", key, len(value))
+		}
+	}
+	return "unknown", fmt.Sprintf("%d bytes", len(value))
+}
+
+// keyNumberHash splits a key of the form prefix + num (uint64 big endian) + hash.
+func keyNumberHash(key []byte, prefix []byte) (uint64, common.Hash) {
+	number := binary.BigEndian.Uint64(key[len(prefix):])
+	return number, common.BytesToHash(key[len(prefix)+8:])
+}
@@ -0,0 +1,70 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestDescribeEntry(t *testing.T) {
+	db := NewMemoryDatabase()
+	header := &types.Header{Number: big.NewInt(42), Difficulty: big.NewInt(1), Extra: []byte("test")}
+	hash := header.Hash()
+	WriteHeader(db, header)
+	WriteTd(db, hash, 42, big.NewInt(1000))
+	WriteCanonicalHash(db, hash, 42)
+	WriteHeadHeaderHash(db, hash)
+
+	want := map[string]string{
+		"header":           "#42",
+		"total difficulty": "1000",
+		"canonical hash":   hash.Hex()[2:],
+		"block number":     "#42",
+		"metadata":         "LastHeader",
+	}
+	it := db.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		kind, summary := DescribeEntry(it.Key(), it.Value())
+		substr, ok := want[kind]
+		if !ok {
+			t.Errorf("unexpected entry %x: %s: %s", it.Key(), kind, summary)
+			continue
+		}
+		if !strings.Contains(summary, substr) || strings.Contains(summary, "corrupt") {
+			t.Errorf("%s: unexpected summary %q", kind, summary)
+		}
+		delete(want, kind)
+	}
+	for kind := range want {
+		t.Errorf("missing %s entry", kind)
+	}
+	// A header stored under the wrong hash is reported as corrupt
+	blob, _ := rlp.EncodeToBytes(header)
+	if _, summary := DescribeEntry(headerKey(42, common.Hash{1}), blob); !strings.Contains(summary, "corrupt") {
+		t.Errorf("corrupt header not detected: %q", summary)
+	}
+	if kind, _ := DescribeEntry([]byte("foo"), nil); kind != "unknown" {
+		t.Errorf("unexpected kind %q for unknown entry", kind)
+	}
+}
@@ -15,3 +15,42 @@
 // along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
 
 package rawdb
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestInspectKeyValueStore(t *testing.T) {
+	db := NewMemoryDatabase()
+	for i := int64(0); i < 3; i++ {
+		header := &types.Header{Number: big.NewInt(i), Difficulty: big.NewInt(1)}
+		WriteHeader(db, header)
+		WriteCanonicalHash(db, header.Hash(), uint64(i))
+	}
+	WriteHeadHeaderHash(db, common.Hash{1})
+	db.Put([]byte("garbage"), []byte{1, 2, 3})
+
+	stats, err := InspectKeyValueStore(db, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to inspect database: %v", err)
+	}
+	for category, want := range map[string]uint64{
+		categoryHeaders:         3,
+		categoryNumHashPairings: 3,
+		categoryHashNumPairings: 3,
+		categoryMetadata:        1,
+		CategoryUnaccounted:     1,
+		categoryBodies:          0,
+	} {
+		if have := stats[category].Count; have != want {
+			t.Errorf("%s: count mismatch, have %d, want %d", category, have, want)
+		}
+	}
+	if have, want := stats[CategoryUnaccounted].Size, common.StorageSize(len("garbage")+3); have != want {
+		t.Errorf("unaccounted size mismatch, have %v, want %v", have, want)
+	}
+}
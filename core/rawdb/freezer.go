@@ -93,6 +93,8 @@ func NewFreezer(datadir string, namespace string, readonly bool, maxTableSize ui
 		readMeter  = metrics.NewRegisteredMeter(namespace+"ancient/read", nil)
 		writeMeter = metrics.NewRegisteredMeter(namespace+"ancient/write", nil)
 		sizeGauge  = metrics.NewRegisteredGauge(namespace+"ancient/size", nil)
+		mmapHit    = metrics.NewRegisteredMeter(namespace+"ancient/mmap/hit", nil)
+		mmapMiss   = metrics.NewRegisteredMeter(namespace+"ancient/mmap/miss", nil)
 	)
 	// Ensure the datadir is not a symbolic link if it exists.
 	if info, err := os.Lstat(datadir); !os.IsNotExist(err) {
@@ -134,6 +136,7 @@ func NewFreezer(datadir string, namespace string, readonly bool, maxTableSize ui
 			lock.Unlock()
 			return nil, err
 		}
+		table.mmapHit, table.mmapMiss = mmapHit, mmapMiss
 		freezer.tables[name] = table
 	}
 	var err error
@@ -403,6 +406,22 @@ type convertLegacyFn = func([]byte) ([]byte, error)
 // MigrateTable processes the entries in a given table in sequence
 // converting them to a new format if they're of an old format.
 func (f *Freezer) MigrateTable(kind string, convert convertLegacyFn) error {
+	return f.migrateTable(kind, convert, nil)
+}
+
+// recompressTable rewrites all entries of a compressed table with the given
+// compression algorithm. The freezer needs to be reopened afterwards.
+func (f *Freezer) recompressTable(kind string, compression uint8) error {
+	if table, ok := f.tables[kind]; ok && (table.noCompression || table.compression == compression) {
+		return nil
+	}
+	log.Info("Recompressing freezer table", "table", kind)
+	return f.migrateTable(kind, func(blob []byte) ([]byte, error) { return blob, nil }, &compression)
+}
+
+// migrateTable converts all entries of a table into a new table, optionally with
+// a different compression algorithm, and replaces the old table files with it.
+func (f *Freezer) migrateTable(kind string, convert convertLegacyFn, compression *uint8) error {
 	if f.readonly {
 		return errReadOnly
 	}
@@ -452,6 +471,27 @@ func (f *Freezer) MigrateTable(kind string, convert convertLegacyFn) error {
 	if err != nil {
 		return err
 	}
+	// Retain the compression algorithm of the table unless asked to change it,
+	// which is only possible as long as the new table is empty.
+	target := table.compression
+	if compression != nil {
+		target = *compression
+	}
+	if !newTable.noCompression && newTable.compression != target {
+		if newTable.items.Load() > 0 {
+			newTable.Close()
+			return fmt.Errorf("previous migration attempt of table %s uses different compression", kind)
+		}
+		meta, err := readMetadata(newTable.meta)
+		if err != nil {
+			newTable.Close()
+			return err
+		}
+		if err := newTable.setCompression(meta, target); err != nil {
+			newTable.Close()
+			return err
+		}
+	}
 	var (
 		batch  = newTable.newBatch()
 		out    []byte
@@ -84,7 +84,7 @@ func (batch *freezerBatch) commit() (item uint64, writeSize int64, err error) {
 type freezerTableBatch struct {
 	t *freezerTable
 
-	sb          *snappyBuffer
+	sb          itemCompressor
 	encBuffer   writeBuffer
 	dataBuffer  []byte
 	indexBuffer []byte
@@ -96,7 +96,7 @@ type freezerTableBatch struct {
 func (t *freezerTable) newBatch() *freezerTableBatch {
 	batch := &freezerTableBatch{t: t}
 	if !t.noCompression {
-		batch.sb = new(snappyBuffer)
+		batch.sb = newItemCompressor(t.compression)
 	}
 	batch.reset()
 	return batch
@@ -0,0 +1,140 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression algorithms of the compressed freezer tables. The algorithm of a
+// table is recorded in its metadata when the table is created, so reads are
+// transparent regardless of the currently configured algorithm.
+const (
+	freezerSnappy uint8 = iota // Snappy block format, used by all legacy tables
+	freezerZstd                // Zstandard frames
+)
+
+// ancientCompression is the compression algorithm used by newly created
+// compressed freezer tables.
+var ancientCompression atomic.Uint32
+
+// SetAncientCompression sets the compression algorithm used by compressed freezer
+// tables created from now on, either "snappy" (the default) or "zstd". Existing
+// tables keep the algorithm they were created with, use CompressAncients for
+// converting them.
+func SetAncientCompression(name string) error {
+	compression, err := parseAncientCompression(name)
+	if err != nil {
+		return err
+	}
+	ancientCompression.Store(uint32(compression))
+	return nil
+}
+
+// parseAncientCompression converts a compression algorithm name into its
+// metadata identifier.
+func parseAncientCompression(name string) (uint8, error) {
+	switch name {
+	case "", "snappy":
+		return freezerSnappy, nil
+	case "zstd":
+		return freezerZstd, nil
+	default:
+		return 0, fmt.Errorf("unknown ancient compression %q, supported: snappy, zstd", name)
+	}
+}
+
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+)
+
+// zstdCodec returns the shared zstd encoder and decoder. Both are safe for
+// concurrent use through EncodeAll and DecodeAll.
+func zstdCodec() (*zstd.Encoder, *zstd.Decoder) {
+	zstdOnce.Do(func() {
+		var err error
+		if zstdEncoder, err = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault)); err != nil {
+			panic(fmt.Sprintf("failed to create zstd encoder: %v", err))
+		}
+		if zstdDecoder, err = zstd.NewReader(nil, zstd.WithDecoderConcurrency(0)); err != nil {
+			panic(fmt.Sprintf("failed to create zstd decoder: %v", err))
+		}
+	})
+	return zstdEncoder, zstdDecoder
+}
+
+// itemCompressor compresses freezer items, reusing its output buffer.
+type itemCompressor interface {
+	compress(data []byte) []byte
+}
+
+// newItemCompressor creates a compressor for the given algorithm.
+func newItemCompressor(compression uint8) itemCompressor {
+	if compression == freezerZstd {
+		return new(zstdBuffer)
+	}
+	return new(snappyBuffer)
+}
+
+// zstdBuffer writes zstd frames, and can be reused.
+type zstdBuffer struct {
+	dst []byte
+}
+
+// compress zstd-compresses the data.
+func (z *zstdBuffer) compress(data []byte) []byte {
+	encoder, _ := zstdCodec()
+	z.dst = encoder.EncodeAll(data, z.dst[:0])
+	return z.dst
+}
+
+// decompressItem decompresses a freezer item stored with the given algorithm.
+func decompressItem(compression uint8, item []byte) ([]byte, error) {
+	if compression == freezerZstd {
+		_, decoder := zstdCodec()
+		return decoder.DecodeAll(item, nil)
+	}
+	return snappy.Decode(nil, item)
+}
+
+// CompressAncients converts all compressed tables of the chain freezer in the
+// given ancient directory to zstd compression. Tables already using zstd are
+// left untouched. The freezer must not be in use by any other process.
+func CompressAncients(ancient string) error {
+	freezer, err := NewChainFreezer(resolveChainFreezerDir(ancient), "", false)
+	if err != nil {
+		return err
+	}
+	defer freezer.Close()
+
+	for name, noSnappy := range chainFreezerNoSnappy {
+		if noSnappy {
+			continue
+		}
+		if err := freezer.recompressTable(name, freezerZstd); err != nil {
+			return fmt.Errorf("failed to compress table %s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// TestFreezerTableZstd tests that zstd compressed tables can be written, read
+// back in ranges and reopened regardless of the configured compression.
+func TestFreezerTableZstd(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	f, err := newTable(dir, "zstd", metrics.NilMeter{}, metrics.NilMeter{}, metrics.NilGauge{}, 1000, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta, err := readMetadata(f.meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.setCompression(meta, freezerZstd); err != nil {
+		t.Fatal(err)
+	}
+	writeChunks(t, f, 30, 100)
+
+	items, err := f.RetrieveItems(0, 30, 250)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("wrong number of items within byte limit: have %d, want 2", len(items))
+	}
+	f.Close()
+
+	// Reopen the table, the compression must be loaded from the metadata
+	f, err = newTable(dir, "zstd", metrics.NilMeter{}, metrics.NilMeter{}, metrics.NilGauge{}, 1000, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if f.compression != freezerZstd {
+		t.Fatalf("compression not persisted: have %d", f.compression)
+	}
+	for i := 0; i < 30; i++ {
+		data, err := f.Retrieve(uint64(i))
+		if err != nil {
+			t.Fatalf("can't retrieve item %d: %v", i, err)
+		}
+		if !bytes.Equal(data, getChunk(100, i)) {
+			t.Fatalf("item %d mismatch: have %x", i, data)
+		}
+	}
+}
+
+// TestFreezerRecompressTable tests that snappy compressed tables can be converted
+// to zstd, retaining the content and leaving uncompressed tables alone.
+func TestFreezerRecompressTable(t *testing.T) {
+	t.Parallel()
+
+	tables := map[string]bool{"compressed": false, "raw": true}
+	f, dir := newFreezerForTesting(t, tables)
+
+	_, err := f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := 0; i < 100; i++ {
+			if err := op.AppendRaw("compressed", uint64(i), getChunk(100, i)); err != nil {
+				return err
+			}
+			if err := op.AppendRaw("raw", uint64(i), getChunk(10, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name := range tables {
+		if err := f.recompressTable(name, freezerZstd); err != nil {
+			t.Fatalf("failed to recompress %s: %v", name, err)
+		}
+	}
+	f.Close()
+
+	f, err = NewFreezer(dir, "", false, 2049, tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if c := f.tables["compressed"].compression; c != freezerZstd {
+		t.Fatalf("table not recompressed: have %d", c)
+	}
+	for i := 0; i < 100; i++ {
+		data, err := f.Ancient("compressed", uint64(i))
+		if err != nil || !bytes.Equal(data, getChunk(100, i)) {
+			t.Fatalf("compressed item %d mismatch: %x (%v)", i, data, err)
+		}
+		data, err = f.Ancient("raw", uint64(i))
+		if err != nil || !bytes.Equal(data, getChunk(10, i)) {
+			t.Fatalf("raw item %d mismatch: %x (%v)", i, data, err)
+		}
+	}
+}
@@ -36,6 +36,10 @@ type freezerTableMeta struct {
 	// plus the number of items hidden in the table, so it should never
 	// be lower than the "actual tail".
 	VirtualTail uint64
+
+	// Compression is the compression algorithm of the table data if the table
+	// is compressed. It's omitted for snappy, the algorithm of legacy tables.
+	Compression uint8 `rlp:"optional"`
 }
 
 // newMetadata initializes the metadata object with the given virtual tail.
@@ -98,7 +98,8 @@ type freezerTable struct {
 	// should never be lower than itemOffset.
 	itemHidden atomic.Uint64
 
-	noCompression bool // if true, disables snappy compression. Note: does not work retroactively
+	noCompression bool  // if true, disables snappy compression. Note: does not work retroactively
+	compression   uint8 // compression algorithm of the data if compressed, see freezerSnappy
 	readonly      bool
 	maxFileSize   uint32 // Max file size for data-files
 	name          string
@@ -111,10 +112,14 @@ type freezerTable struct {
 	headId uint32              // number of the currently active head file
 	tailId uint32              // number of the earliest file
 
+	maps map[uint32]*mappedFile // memory-mapped sealed data files, see freezerMmap
+
 	headBytes  int64         // Number of bytes written to the head file
 	readMeter  metrics.Meter // Meter for measuring the effective amount of data read
 	writeMeter metrics.Meter // Meter for measuring the effective amount of data written
 	sizeGauge  metrics.Gauge // Gauge for tracking the combined size of all freezer tables
+	mmapHit    metrics.Meter // Meter for the reads served from memory-mapped data files
+	mmapMiss   metrics.Meter // Meter for the reads served by seeking in the data files
 
 	logger log.Logger   // Logger with database path and table name embedded
 	lock   sync.RWMutex // Mutex protecting the data file descriptors
@@ -169,9 +174,12 @@ func newTable(path string, name string, readMeter metrics.Meter, writeMeter metr
 		index:         index,
 		meta:          meta,
 		files:         make(map[uint32]*os.File),
+		maps:          make(map[uint32]*mappedFile),
 		readMeter:     readMeter,
 		writeMeter:    writeMeter,
 		sizeGauge:     sizeGauge,
+		mmapHit:       metrics.NilMeter{},
+		mmapMiss:      metrics.NilMeter{},
 		name:          name,
 		path:          path,
 		logger:        log.New("database", path, "table", name),
@@ -251,6 +259,15 @@ func (t *freezerTable) repair() error {
 		return err
 	}
 	t.itemHidden.Store(meta.VirtualTail)
+	t.compression = meta.Compression
+
+	// Adopt the configured compression algorithm if the table doesn't contain
+	// any data yet, i.e. if it's newly created.
+	if want := uint8(ancientCompression.Load()); !t.noCompression && !t.readonly && want != t.compression && offsetsSize == indexEntrySize {
+		if err := t.setCompression(meta, want); err != nil {
+			return err
+		}
+	}
 
 	// Read the last index, use the default value in case the freezer is empty
 	if offsetsSize == indexEntrySize {
@@ -364,6 +381,17 @@ func (t *freezerTable) repair() error {
 	return nil
 }
 
+// setCompression changes the compression algorithm of the table, which must not
+// contain any data.
+func (t *freezerTable) setCompression(meta *freezerTableMeta, compression uint8) error {
+	meta.Compression = compression
+	if err := writeMetadata(t.meta, meta); err != nil {
+		return err
+	}
+	t.compression = compression
+	return nil
+}
+
 // preopen opens all files that the freezer will need. This method should be called from an init-context,
 // since it assumes that it doesn't have to bother with locking
 // The rationale for doing preopen is to not have to do it from within Retrieve, thus not needing to ever
@@ -377,6 +405,7 @@ func (t *freezerTable) preopen() (err error) {
 		if _, err = t.openFile(i, openFreezerFileForReadOnly); err != nil {
 			return err
 		}
+		t.mapFile(i)
 	}
 	if t.readonly {
 		t.head, err = t.openFile(t.headId, openFreezerFileForReadOnly)
@@ -517,7 +546,9 @@ func (t *freezerTable) truncateTail(items uint64) error {
 	}
 	// Update the virtual tail marker and hidden these entries in table.
 	t.itemHidden.Store(items)
-	if err := writeMetadata(t.meta, newMetadata(items)); err != nil {
+	meta := newMetadata(items)
+	meta.Compression = t.compression
+	if err := writeMetadata(t.meta, meta); err != nil {
 		return err
 	}
 	// Hidden items still fall in the current tail file, no data file
@@ -618,6 +649,12 @@ func (t *freezerTable) Close() error {
 	// part of t.files, it will be closed in the loop below.
 	doClose(t.head, true, false) // sync but do not close
 
+	for fnum, m := range t.maps {
+		delete(t.maps, fnum)
+		if err := m.unmap(); err != nil {
+			errs = append(errs, err)
+		}
+	}
 	for _, f := range t.files {
 		doClose(f, false, true) // close but do not sync
 	}
@@ -650,9 +687,41 @@ func (t *freezerTable) openFile(num uint32, opener func(string) (*os.File, error
 	return f, err
 }
 
+// mapFile memory-maps an opened sealed data file, if enabled. Failures are not
+// fatal, the reads fall back to the file descriptor.
+// Assumes that the caller holds the write lock
+func (t *freezerTable) mapFile(num uint32) {
+	if !freezerMmap {
+		return
+	}
+	f, exist := t.files[num]
+	if !exist {
+		return
+	}
+	m, err := mapFile(f)
+	if err != nil {
+		t.logger.Warn("Failed to map freezer data file", "file", num, "err", err)
+		return
+	}
+	if m != nil {
+		t.maps[num] = m
+	}
+}
+
+// unmapFile releases the memory-mapped region of a data file, if any. It must
+// be called before the file is closed or modified.
+// Assumes that the caller holds the write lock
+func (t *freezerTable) unmapFile(num uint32) {
+	if m, exist := t.maps[num]; exist {
+		delete(t.maps, num)
+		m.unmap()
+	}
+}
+
 // releaseFile closes a file, and removes it from the open file cache.
 // Assumes that the caller holds the write lock
 func (t *freezerTable) releaseFile(num uint32) {
+	t.unmapFile(num)
 	if f, exist := t.files[num]; exist {
 		delete(t.files, num)
 		f.Close()
@@ -663,6 +732,7 @@ func (t *freezerTable) releaseFile(num uint32) {
 func (t *freezerTable) releaseFilesAfter(num uint32, remove bool) {
 	for fnum, f := range t.files {
 		if fnum > num {
+			t.unmapFile(fnum)
 			delete(t.files, fnum)
 			f.Close()
 			if remove {
@@ -676,6 +746,7 @@ func (t *freezerTable) releaseFilesAfter(num uint32, remove bool) {
 func (t *freezerTable) releaseFilesBefore(num uint32, remove bool) {
 	for fnum, f := range t.files {
 		if fnum < num {
+			t.unmapFile(fnum)
 			delete(t.files, fnum)
 			f.Close()
 			if remove {
@@ -751,23 +822,31 @@ func (t *freezerTable) RetrieveItems(start, count, maxBytes uint64) ([][]byte, e
 	for i, diskSize := range sizes {
 		item := diskData[offset : offset+diskSize]
 		offset += diskSize
-		decompressedSize := diskSize
-		if !t.noCompression {
-			decompressedSize, _ = snappy.DecodedLen(item)
-		}
-		if i > 0 && maxBytes != 0 && uint64(outputSize+decompressedSize) > maxBytes {
-			break
-		}
-		if !t.noCompression {
-			data, err := snappy.Decode(nil, item)
-			if err != nil {
-				return nil, err
+		if t.noCompression {
+			if i > 0 && maxBytes != 0 && uint64(outputSize+diskSize) > maxBytes {
+				break
 			}
-			output = append(output, data)
-		} else {
 			output = append(output, item)
+			outputSize += diskSize
+			continue
+		}
+		// Snappy stores the decompressed size upfront, zstd frames only
+		// optionally, so the latter is decompressed before checking it.
+		if t.compression == freezerSnappy {
+			size, _ := snappy.DecodedLen(item)
+			if i > 0 && maxBytes != 0 && uint64(outputSize+size) > maxBytes {
+				break
+			}
+		}
+		data, err := decompressItem(t.compression, item)
+		if err != nil {
+			return nil, err
 		}
-		outputSize += decompressedSize
+		if i > 0 && maxBytes != 0 && uint64(outputSize+len(data)) > maxBytes {
+			break
+		}
+		output = append(output, data)
+		outputSize += len(data)
 	}
 	return output, nil
 }
@@ -806,6 +885,11 @@ func (t *freezerTable) retrieveItems(start, count, maxBytes uint64) ([]byte, []i
 	// readData is a helper method to read a single data item from disk.
 	readData := func(fileId, start uint32, length int) error {
 		output = grow(output, length)
+		if m, exist := t.maps[fileId]; exist && m.read(output[len(output)-length:], start) {
+			t.mmapHit.Mark(1)
+			return nil
+		}
+		t.mmapMiss.Mark(1)
 		dataFile, exist := t.files[fileId]
 		if !exist {
 			return fmt.Errorf("missing data file %d", fileId)
@@ -921,6 +1005,7 @@ func (t *freezerTable) advanceHead() error {
 	}
 	t.releaseFile(t.headId)
 	t.openFile(t.headId, openFreezerFileForReadOnly)
+	t.mapFile(t.headId)
 
 	// Swap out the current head.
 	t.head = newHead
@@ -0,0 +1,94 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"os"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/edsrzf/mmap-go"
+)
+
+// freezerMmap enables serving reads of the sealed data files of freezer tables
+// from memory-mapped regions, instead of seeking in the files. It is limited to
+// 64-bit platforms, since the ancient store easily exceeds a 32-bit address space.
+var freezerMmap = strconv.IntSize == 64
+
+// sequentialReadThreshold is the number of back-to-back reads after which the
+// accesses to a mapped data file are considered sequential, switching the kernel
+// advice from random access to aggressive read-ahead.
+const sequentialReadThreshold = 4
+
+// mappedFile is a sealed, read-only freezer data file mapped into memory. The
+// access pattern of the reads is tracked to tune the paging advice of the region:
+// point lookups (e.g. serving RPC) benefit from disabled read-ahead, while range
+// retrievals (e.g. syncing peers, exporting the chain) benefit from read-ahead.
+type mappedFile struct {
+	data mmap.MMap
+
+	next       atomic.Uint64 // Offset following the previous read
+	streak     atomic.Int32  // Number of consecutive sequential reads
+	sequential atomic.Bool   // Whether sequential advice is in effect
+}
+
+// mapFile maps the given data file into memory. Empty files are not mapped.
+func mapFile(f *os.File) (*mappedFile, error) {
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if stat.Size() == 0 {
+		return nil, nil
+	}
+	data, err := mmap.Map(f, mmap.RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	m := &mappedFile{data: data}
+	adviseRandom(m.data)
+	return m, nil
+}
+
+// read copies the data at the given offset into the buffer, returning false if
+// the requested region is out of the mapped range.
+func (m *mappedFile) read(buf []byte, offset uint32) bool {
+	start, end := uint64(offset), uint64(offset)+uint64(len(buf))
+	if end > uint64(len(m.data)) {
+		return false
+	}
+	copy(buf, m.data[start:end])
+
+	// Concurrent readers may race on the access tracking. That only affects
+	// the advice given to the kernel, never the data read.
+	if m.next.Swap(end) == start {
+		if m.streak.Add(1) >= sequentialReadThreshold && m.sequential.CompareAndSwap(false, true) {
+			adviseSequential(m.data)
+		}
+	} else {
+		m.streak.Store(0)
+		if m.sequential.CompareAndSwap(true, false) {
+			adviseRandom(m.data)
+		}
+	}
+	return true
+}
+
+// unmap releases the mapped region.
+func (m *mappedFile) unmap() error {
+	return m.data.Unmap()
+}
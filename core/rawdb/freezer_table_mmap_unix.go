@@ -0,0 +1,34 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build linux || darwin || freebsd || netbsd || openbsd
+// +build linux darwin freebsd netbsd openbsd
+
+package rawdb
+
+import "golang.org/x/sys/unix"
+
+// adviseRandom hints the kernel that the region is accessed randomly, disabling
+// read-ahead on page faults.
+func adviseRandom(data []byte) {
+	unix.Madvise(data, unix.MADV_RANDOM)
+}
+
+// adviseSequential hints the kernel that the region is accessed sequentially,
+// enabling aggressive read-ahead on page faults.
+func adviseSequential(data []byte) {
+	unix.Madvise(data, unix.MADV_SEQUENTIAL)
+}
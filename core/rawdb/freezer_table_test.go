@@ -975,6 +975,135 @@ func TestSequentialRead(t *testing.T) {
 	}
 }
 
+// TestFreezerMmapRead tests that the sealed data files are memory-mapped and
+// served from the mappings, and that the mappings follow the file life-cycle.
+func TestFreezerMmapRead(t *testing.T) {
+	if !freezerMmap {
+		t.Skip("memory-mapped freezer reads disabled on this platform")
+	}
+	t.Parallel()
+	fname := fmt.Sprintf("mmapread-%d", rand.Uint64())
+
+	// Write 15 bytes 30 times, 3 items per file
+	f, err := newTable(os.TempDir(), fname, metrics.NewMeter(), metrics.NewMeter(), metrics.NewGauge(), 50, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeChunks(t, f, 30, 15)
+	f.Close()
+
+	f, err = newTable(os.TempDir(), fname, metrics.NewMeter(), metrics.NewMeter(), metrics.NewGauge(), 50, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	hits, misses := new(countingMeter), new(countingMeter)
+	f.mmapHit, f.mmapMiss = hits, misses
+
+	checkMaps := func(want int) {
+		t.Helper()
+		if have := len(f.maps); have != want {
+			t.Fatalf("mapped files mismatch: have %d, want %d", have, want)
+		}
+		if _, ok := f.maps[f.headId]; ok {
+			t.Fatal("head file is mapped")
+		}
+	}
+	checkRead := func(from, to int) {
+		t.Helper()
+		for i := from; i < to; i++ {
+			have, err := f.Retrieve(uint64(i))
+			if err != nil {
+				t.Fatalf("failed to retrieve item %d: %v", i, err)
+			}
+			if want := getChunk(15, i); !bytes.Equal(have, want) {
+				t.Fatalf("item %d mismatch: have %x, want %x", i, have, want)
+			}
+		}
+	}
+	checkMaps(9)
+	checkRead(0, 30)
+	if hits.count != 27 || misses.count != 3 {
+		t.Fatalf("mmap hits/misses mismatch: have %d/%d, want 27/3", hits.count, misses.count)
+	}
+	// Sealing the head maps it
+	batch := f.newBatch()
+	if err := batch.AppendRaw(30, getChunk(15, 30)); err != nil {
+		t.Fatal(err)
+	}
+	if err := batch.commit(); err != nil {
+		t.Fatal(err)
+	}
+	checkMaps(10)
+
+	// Truncating back to an older file unmaps it
+	if err := f.truncateHead(10); err != nil {
+		t.Fatal(err)
+	}
+	checkMaps(3)
+	checkRead(0, 10)
+
+	// Deleting from the tail unmaps the removed files
+	if err := f.truncateTail(6); err != nil {
+		t.Fatal(err)
+	}
+	checkMaps(1)
+	checkRead(6, 10)
+}
+
+// countingMeter is a meter counting the marked events, regardless of whether
+// metrics collection is enabled.
+type countingMeter struct {
+	metrics.NilMeter
+	count int64
+}
+
+func (m *countingMeter) Mark(n int64) { m.count += n }
+
+// TestMappedFileAdvice tests that the access pattern of a mapped file is tracked.
+func TestMappedFileAdvice(t *testing.T) {
+	if !freezerMmap {
+		t.Skip("memory-mapped freezer reads disabled on this platform")
+	}
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(path, getChunk(1024, 0), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	m, err := mapFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.unmap()
+
+	buf := make([]byte, 16)
+	for i := 0; i < sequentialReadThreshold; i++ {
+		if m.sequential.Load() {
+			t.Fatalf("sequential advice after %d reads", i)
+		}
+		if !m.read(buf, uint32(i*len(buf))) {
+			t.Fatal("failed to read mapped data")
+		}
+	}
+	if !m.sequential.Load() {
+		t.Fatal("sequential reads not detected")
+	}
+	if !m.read(buf, 512) {
+		t.Fatal("failed to read mapped data")
+	}
+	if m.sequential.Load() {
+		t.Fatal("random read not detected")
+	}
+	if m.read(buf, 1020) {
+		t.Fatal("read beyond the mapped data")
+	}
+}
+
 // TestSequentialReadByteLimit does some more advanced tests on batch reads.
 // These tests check that when the byte limit hits, we correctly abort in time,
 // but also properly do all the deferred reads for the previous data, regardless
@@ -0,0 +1,137 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// InspectSnapshot is a record of the storage size of every category of data in
+// the database at a point in time, kept to track the growth of the database
+// across runs.
+type InspectSnapshot struct {
+	Time  uint64 // Unix time the snapshot was taken at
+	Head  uint64 // Number of the head block at the time
+	Stats []InspectStat
+}
+
+// InspectStat is the storage size of a category of data in an InspectSnapshot.
+type InspectStat struct {
+	Database string // Store holding the data, e.g. "Key-Value store"
+	Category string // Kind of data, e.g. "Receipt lists"
+	Size     uint64 // Total storage size in bytes
+	Count    uint64 // Number of entries, or items for ancient stores
+}
+
+// inspectSnapshotKey = inspectSnapshotPrefix + time (uint64 big endian)
+func inspectSnapshotKey(time uint64) []byte {
+	return append(inspectSnapshotPrefix, encodeBlockNumber(time)...)
+}
+
+// TakeInspectSnapshot traverses the entire database, recording the size of all
+// different categories of data, like InspectDatabase does.
+func TakeInspectSnapshot(db ethdb.Database) (*InspectSnapshot, error) {
+	snap := &InspectSnapshot{Time: uint64(time.Now().Unix())}
+	if number := ReadHeaderNumber(db, ReadHeadHeaderHash(db)); number != nil {
+		snap.Head = *number
+	}
+	kvstats, err := InspectKeyValueStore(db, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, category := range KeyValueCategories {
+		stat := kvstats[category[1]]
+		snap.Stats = append(snap.Stats, InspectStat{Database: category[0], Category: category[1], Size: uint64(stat.Size), Count: stat.Count})
+	}
+	unaccounted := kvstats[CategoryUnaccounted]
+	snap.Stats = append(snap.Stats, InspectStat{Database: "Key-Value store", Category: CategoryUnaccounted, Size: uint64(unaccounted.Size), Count: unaccounted.Count})
+
+	ancients, err := inspectFreezers(db)
+	if err != nil {
+		return nil, err
+	}
+	for _, ancient := range ancients {
+		sizes := append([]tableSize(nil), ancient.sizes...)
+		sort.Slice(sizes, func(i, j int) bool { return sizes[i].name < sizes[j].name })
+		for _, table := range sizes {
+			snap.Stats = append(snap.Stats, InspectStat{
+				Database: fmt.Sprintf("Ancient store (%s)", strings.Title(ancient.name)),
+				Category: strings.Title(table.name),
+				Size:     uint64(table.size),
+				Count:    ancient.count(),
+			})
+		}
+	}
+	return snap, nil
+}
+
+// Size returns the total storage size recorded in the snapshot.
+func (snap *InspectSnapshot) Size() uint64 {
+	var total uint64
+	for _, stat := range snap.Stats {
+		total += stat.Size
+	}
+	return total
+}
+
+// WriteInspectSnapshot stores a database size snapshot, replacing any other one
+// taken at the same time.
+func WriteInspectSnapshot(db ethdb.KeyValueWriter, snap *InspectSnapshot) {
+	enc, err := rlp.EncodeToBytes(snap)
+	if err != nil {
+		log.Crit("Failed to encode database size snapshot", "err", err)
+	}
+	if err := db.Put(inspectSnapshotKey(snap.Time), enc); err != nil {
+		log.Crit("Failed to store database size snapshot", "err", err)
+	}
+}
+
+// ReadInspectSnapshots retrieves all stored database size snapshots, ordered by
+// the time they were taken at.
+func ReadInspectSnapshots(db ethdb.Iteratee) []*InspectSnapshot {
+	it := db.NewIterator(inspectSnapshotPrefix, nil)
+	defer it.Release()
+
+	var snaps []*InspectSnapshot
+	for it.Next() {
+		if len(it.Key()) != len(inspectSnapshotPrefix)+8 {
+			continue
+		}
+		snap := new(InspectSnapshot)
+		if err := rlp.DecodeBytes(it.Value(), snap); err != nil {
+			log.Warn("Skipping corrupt database size snapshot", "time", binary.BigEndian.Uint64(it.Key()[len(inspectSnapshotPrefix):]), "err", err)
+			continue
+		}
+		snaps = append(snaps, snap)
+	}
+	return snaps
+}
+
+// DeleteInspectSnapshot removes the database size snapshot taken at the given time.
+func DeleteInspectSnapshot(db ethdb.KeyValueWriter, time uint64) {
+	if err := db.Delete(inspectSnapshotKey(time)); err != nil {
+		log.Crit("Failed to delete database size snapshot", "err", err)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestInspectSnapshots(t *testing.T) {
+	db, err := NewDatabaseWithFreezer(NewMemoryDatabase(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	header := &types.Header{Number: big.NewInt(7), Difficulty: big.NewInt(1)}
+	WriteHeader(db, header)
+	WriteHeadHeaderHash(db, header.Hash())
+
+	first, err := TakeInspectSnapshot(db)
+	if err != nil {
+		t.Fatalf("failed to take snapshot: %v", err)
+	}
+	if first.Head != 7 {
+		t.Errorf("head mismatch: have %d, want 7", first.Head)
+	}
+	headers := -1
+	for i, stat := range first.Stats {
+		if stat.Database == "Key-Value store" && stat.Category == categoryHeaders {
+			headers = i
+		}
+	}
+	if headers < 0 || first.Stats[headers].Count != 1 {
+		t.Fatalf("header stats missing or wrong: %v", first.Stats)
+	}
+	WriteInspectSnapshot(db, first)
+
+	// Stored snapshots must not be reported as unaccounted data
+	second, err := TakeInspectSnapshot(db)
+	if err != nil {
+		t.Fatalf("failed to take snapshot: %v", err)
+	}
+	second.Time = first.Time + 1
+	for _, stat := range second.Stats {
+		if stat.Category == CategoryUnaccounted && stat.Count != 0 {
+			t.Errorf("snapshot counted as unaccounted data")
+		}
+	}
+	WriteInspectSnapshot(db, second)
+
+	snaps := ReadInspectSnapshots(db)
+	if len(snaps) != 2 || !reflect.DeepEqual(snaps[0], first) || !reflect.DeepEqual(snaps[1], second) {
+		t.Fatalf("stored snapshots mismatch: have %v", snaps)
+	}
+	DeleteInspectSnapshot(db, first.Time)
+	if snaps := ReadInspectSnapshots(db); len(snaps) != 1 || snaps[0].Time != second.Time {
+		t.Fatalf("snapshot not deleted: have %v", snaps)
+	}
+}
@@ -96,6 +96,12 @@ var (
 	// snapSyncStatusFlagKey flags that status of snap sync.
 	snapSyncStatusFlagKey = []byte("SnapSyncStatus")
 
+	// headerAccSizeKey tracks the number of headers in the header accumulator.
+	headerAccSizeKey = []byte("HeaderAccumulatorSize")
+
+	// stateLayoutKey tracks the state commitment layout selected at genesis.
+	stateLayoutKey = []byte("StateLayout")
+
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`, used for indexes).
 	headerPrefix       = []byte("h") // headerPrefix + num (uint64 big endian) + hash -> header
 	headerTDSuffix     = []byte("t") // headerPrefix + num (uint64 big endian) + hash + headerTDSuffix -> td
@@ -104,6 +110,7 @@ var (
 
 	blockBodyPrefix     = []byte("b") // blockBodyPrefix + num (uint64 big endian) + hash -> block body
 	blockReceiptsPrefix = []byte("r") // blockReceiptsPrefix + num (uint64 big endian) + hash -> block receipts
+	blockLogsPrefix     = []byte("g") // blockLogsPrefix + num (uint64 big endian) + hash (+ address) -> log addresses (logs of the address)
 
 	txLookupPrefix        = []byte("l") // txLookupPrefix + hash -> transaction/receipt lookup metadata
 	bloomBitsPrefix       = []byte("B") // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
@@ -116,6 +123,7 @@ var (
 	trieNodeAccountPrefix = []byte("A") // trieNodeAccountPrefix + hexPath -> trie node
 	trieNodeStoragePrefix = []byte("O") // trieNodeStoragePrefix + accountHash + hexPath -> trie node
 	stateIDPrefix         = []byte("L") // stateIDPrefix + state root -> state id
+	headerAccNodePrefix   = []byte("M") // headerAccNodePrefix + level (uint8) + index (uint64 big endian) -> accumulator node
 
 	PreimagePrefix = []byte("secure-key-")       // PreimagePrefix + hash -> preimage
 	configPrefix   = []byte("ethereum-config-")  // config prefix for the db
@@ -134,6 +142,8 @@ var (
 
 	CliqueSnapshotPrefix = []byte("clique-")
 
+	inspectSnapshotPrefix = []byte("inspect-snapshot-") // inspectSnapshotPrefix + time (uint64 big endian) -> database size snapshot
+
 	BestUpdateKey         = []byte("update-")    // bigEndian64(syncPeriod) -> RLP(types.LightClientUpdate)  (nextCommittee only referenced by root hash)
 	FixedCommitteeRootKey = []byte("fixedRoot-") // bigEndian64(syncPeriod) -> committee root hash
 	SyncCommitteeKey      = []byte("committee-") // bigEndian64(syncPeriod) -> serialized committee
@@ -192,6 +202,16 @@ func blockReceiptsKey(number uint64, hash common.Hash) []byte {
 	return append(append(blockReceiptsPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
 }
 
+// blockLogsKey = blockLogsPrefix + num (uint64 big endian) + hash
+func blockLogsKey(number uint64, hash common.Hash) []byte {
+	return append(append(blockLogsPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
+// blockAddressLogsKey = blockLogsPrefix + num (uint64 big endian) + hash + address
+func blockAddressLogsKey(number uint64, hash common.Hash, address common.Address) []byte {
+	return append(blockLogsKey(number, hash), address.Bytes()...)
+}
+
 // txLookupKey = txLookupPrefix + hash
 func txLookupKey(hash common.Hash) []byte {
 	return append(txLookupPrefix, hash.Bytes()...)
@@ -264,6 +284,11 @@ func genesisStateSpecKey(hash common.Hash) []byte {
 	return append(genesisPrefix, hash.Bytes()...)
 }
 
+// headerAccNodeKey = headerAccNodePrefix + level (uint8) + index (uint64 big endian)
+func headerAccNodeKey(level uint8, index uint64) []byte {
+	return append(append(headerAccNodePrefix, level), encodeBlockNumber(index)...)
+}
+
 // stateIDKey = stateIDPrefix + root (32 bytes)
 func stateIDKey(root common.Hash) []byte {
 	return append(stateIDPrefix, root.Bytes()...)
@@ -0,0 +1,81 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// UnprotectedTx is a canonical transaction without EIP-155 replay protection,
+// which is valid on every chain sharing the sender's account history (e.g. both
+// ETH and ETC for accounts active before the fork).
+type UnprotectedTx struct {
+	BlockNumber uint64          `json:"blockNumber"`
+	Hash        common.Hash     `json:"hash"`
+	From        common.Address  `json:"from"`
+	To          *common.Address `json:"to"`
+	Nonce       uint64          `json:"nonce"`
+	Value       *big.Int        `json:"value"`
+}
+
+// IterateUnprotectedTxs calls fn with all transactions without replay protection
+// in the canonical blocks in the range [from, to], in ascending order. If sender
+// is non-nil, only transactions sent by it are reported. Iteration stops at the
+// first error.
+func (bc *BlockChain) IterateUnprotectedTxs(from, to uint64, sender *common.Address, fn func(*UnprotectedTx) error) error {
+	if from > to {
+		return fmt.Errorf("invalid range: from %d > to %d", from, to)
+	}
+	for number := from; number <= to; number++ {
+		block := bc.GetBlockByNumber(number)
+		if block == nil {
+			return fmt.Errorf("block #%d not found", number)
+		}
+		signer := types.MakeSigner(bc.chainConfig, block.Number(), block.Time())
+		for _, tx := range block.Transactions() {
+			if tx.Protected() {
+				continue
+			}
+			addr, err := types.Sender(signer, tx)
+			if err != nil {
+				return fmt.Errorf("block #%d: invalid transaction %x: %v", number, tx.Hash(), err)
+			}
+			if sender != nil && addr != *sender {
+				continue
+			}
+			err = fn(&UnprotectedTx{
+				BlockNumber: number,
+				Hash:        tx.Hash(),
+				From:        addr,
+				To:          tx.To(),
+				Nonce:       tx.Nonce(),
+				Value:       tx.Value(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if number == to {
+			break // avoid overflow at the maximum block number
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+	"github.com/ethereum/go-ethereum/params/vars"
+)
+
+func TestIterateUnprotectedTxs(t *testing.T) {
+	var (
+		key1, _ = crypto.GenerateKey()
+		key2, _ = crypto.GenerateKey()
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = crypto.PubkeyToAddress(key2.PublicKey)
+		funds   = big.NewInt(vars.Ether)
+		gspec   = &genesisT.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  genesisT.GenesisAlloc{addr1: {Balance: funds}, addr2: {Balance: funds}},
+		}
+		protected = types.LatestSigner(gspec.Config)
+	)
+	// Every block contains a protected and an unprotected transaction of addr1
+	// and an unprotected one of addr2.
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 4, func(i int, b *BlockGen) {
+		newTx := func(key *ecdsa.PrivateKey, signer types.Signer, nonce uint64) *types.Transaction {
+			tx, _ := types.SignTx(types.NewTransaction(nonce, common.Address{1}, big.NewInt(1), vars.TxGas, b.BaseFee(), nil), signer, key)
+			return tx
+		}
+		b.AddTx(newTx(key1, types.HomesteadSigner{}, uint64(2*i)))
+		b.AddTx(newTx(key1, protected, uint64(2*i+1)))
+		b.AddTx(newTx(key2, types.HomesteadSigner{}, uint64(i)))
+	})
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	var found []*UnprotectedTx
+	err = chain.IterateUnprotectedTxs(2, 4, &addr1, func(tx *UnprotectedTx) error {
+		found = append(found, tx)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to iterate: %v", err)
+	}
+	if len(found) != 3 {
+		t.Fatalf("wrong number of transactions: have %d, want 3", len(found))
+	}
+	for i, tx := range found {
+		if tx.BlockNumber != uint64(i+2) || tx.From != addr1 || tx.Nonce != uint64(2*(i+1)) {
+			t.Errorf("tx %d: wrong transaction %+v", i, tx)
+		}
+		if tx.Hash != blocks[tx.BlockNumber-1].Transactions()[0].Hash() {
+			t.Errorf("tx %d: wrong hash %x", i, tx.Hash)
+		}
+	}
+	// Without a sender filter, the transactions of all senders are reported.
+	var all int
+	chain.IterateUnprotectedTxs(1, 4, nil, func(*UnprotectedTx) error {
+		all++
+		return nil
+	})
+	if all != 8 {
+		t.Errorf("wrong number of unprotected transactions: have %d, want 8", all)
+	}
+}
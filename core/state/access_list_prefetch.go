@@ -0,0 +1,225 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// accessListPrefetchWorkers is the maximum number of access list entries whose
+// state is loaded concurrently ahead of transaction execution.
+const accessListPrefetchWorkers = 16
+
+// accessListTask is a single access list entry to load from the database, along
+// with the outcome of the read.
+type accessListTask struct {
+	addr  common.Address
+	obj   *stateObject        // Live object if already loaded, nil otherwise
+	keys  []common.Hash       // Storage slots not yet cached
+	root  common.Hash         // Storage root of the account once known
+	acct  *types.StateAccount // Loaded account, nil if non-existent
+	slots map[common.Hash]common.Hash
+	err   error
+}
+
+// prefetchAccessList concurrently loads all accounts and storage slots declared
+// in a transaction access list into the state cache, so that execution does not
+// have to wait for the database on each individual access.
+//
+// Only entries not yet cached are loaded. Failed reads are silently dropped,
+// the regular (sequential) read path will retry them and surface any error.
+func (s *StateDB) prefetchAccessList(list types.AccessList) {
+	if len(list) == 0 || s.db.TrieDB().IsVerkle() {
+		return
+	}
+	var (
+		tasks []*accessListTask
+		seen  = make(map[common.Address]*accessListTask)
+	)
+	for _, el := range list {
+		task := seen[el.Address]
+		if task == nil {
+			// Accounts destructed in this block have no storage to load and
+			// are already resolved for the purpose of account reads.
+			if _, destructed := s.stateObjectsDestruct[el.Address]; destructed {
+				continue
+			}
+			task = &accessListTask{addr: el.Address, obj: s.stateObjects[el.Address]}
+			if task.obj != nil {
+				if task.obj.origin == nil {
+					continue // created in this block, nothing in the database
+				}
+				task.root = task.obj.origin.Root
+			}
+			seen[el.Address] = task
+			tasks = append(tasks, task)
+		}
+		for _, key := range el.StorageKeys {
+			if task.obj != nil {
+				if _, ok := task.obj.pendingStorage[key]; ok {
+					continue
+				}
+				if _, ok := task.obj.originStorage[key]; ok {
+					continue
+				}
+			}
+			task.keys = append(task.keys, key)
+		}
+	}
+	// Drop the entries which are fully cached already
+	pending := tasks[:0]
+	for _, task := range tasks {
+		if task.obj == nil || len(task.keys) > 0 {
+			pending = append(pending, task)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+	// Load all the remaining entries concurrently
+	var (
+		wg    sync.WaitGroup
+		limit = make(chan struct{}, accessListPrefetchWorkers)
+	)
+	for _, task := range pending {
+		wg.Add(1)
+		limit <- struct{}{}
+		go func(task *accessListTask) {
+			defer func() {
+				<-limit
+				wg.Done()
+			}()
+			task.err = s.loadAccessListTask(task)
+		}(task)
+	}
+	wg.Wait()
+
+	// Insert the loaded data into the live set
+	for _, task := range pending {
+		if task.err != nil {
+			continue
+		}
+		obj := task.obj
+		if obj == nil {
+			if task.acct == nil {
+				continue
+			}
+			obj = newObject(s, task.addr, task.acct)
+			s.setStateObject(obj)
+		}
+		for key, value := range task.slots {
+			if _, ok := obj.originStorage[key]; !ok {
+				obj.originStorage[key] = value
+			}
+		}
+	}
+}
+
+// loadAccessListTask reads the account and storage slots of a single access list
+// entry, preferring the snapshot and falling back to the tries. It is safe to be
+// called concurrently, every invocation uses its own trie instances.
+func (s *StateDB) loadAccessListTask(task *accessListTask) error {
+	addrHash := crypto.Keccak256Hash(task.addr.Bytes())
+	if task.obj == nil {
+		var loaded bool
+		if s.snap != nil {
+			acc, err := s.snap.Account(addrHash)
+			if err == nil {
+				if acc != nil {
+					task.acct = snapshotToStateAccount(acc)
+				}
+				loaded = true
+			}
+		}
+		if !loaded {
+			tr, err := s.db.OpenTrie(s.originalRoot)
+			if err != nil {
+				return err
+			}
+			if task.acct, err = tr.GetAccount(task.addr); err != nil {
+				return err
+			}
+		}
+		if task.acct == nil {
+			return nil
+		}
+		task.root = task.acct.Root
+	}
+	if len(task.keys) == 0 {
+		return nil
+	}
+	task.slots = make(map[common.Hash]common.Hash, len(task.keys))
+	if task.root == types.EmptyRootHash {
+		for _, key := range task.keys {
+			task.slots[key] = common.Hash{}
+		}
+		return nil
+	}
+	var tr Trie
+	for _, key := range task.keys {
+		if s.snap != nil {
+			enc, err := s.snap.Storage(addrHash, crypto.Keccak256Hash(key.Bytes()))
+			if err == nil {
+				var value common.Hash
+				if len(enc) > 0 {
+					_, content, _, err := rlp.Split(enc)
+					if err != nil {
+						return err
+					}
+					value.SetBytes(content)
+				}
+				task.slots[key] = value
+				continue
+			}
+		}
+		if tr == nil {
+			var err error
+			if tr, err = s.db.OpenStorageTrie(s.originalRoot, task.addr, task.root, nil); err != nil {
+				return err
+			}
+		}
+		val, err := tr.GetStorage(task.addr, key.Bytes())
+		if err != nil {
+			return err
+		}
+		task.slots[key] = common.BytesToHash(val)
+	}
+	return nil
+}
+
+// snapshotToStateAccount converts a slim snapshot account into its consensus
+// representation, filling in the empty code hash and storage root if omitted.
+func snapshotToStateAccount(acc *types.SlimAccount) *types.StateAccount {
+	data := &types.StateAccount{
+		Nonce:    acc.Nonce,
+		Balance:  acc.Balance,
+		CodeHash: acc.CodeHash,
+		Root:     common.BytesToHash(acc.Root),
+	}
+	if len(data.CodeHash) == 0 {
+		data.CodeHash = types.EmptyCodeHash.Bytes()
+	}
+	if data.Root == (common.Hash{}) {
+		data.Root = types.EmptyRootHash
+	}
+	return data
+}
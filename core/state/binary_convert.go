@@ -0,0 +1,178 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/trie/trienode"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/hashdb"
+)
+
+// binaryConvertFlushInterval is the number of accounts after which the binary
+// account trie is flushed to disk during a conversion, bounding memory usage.
+const binaryConvertFlushInterval = 100_000
+
+// BinaryConvertStats contains the statistics of a state conversion.
+type BinaryConvertStats struct {
+	Accounts uint64 // Number of converted accounts
+	Slots    uint64 // Number of converted storage slots
+	Nodes    uint64 // Number of binary trie nodes written
+	Size     uint64 // Total size of the binary trie nodes written
+}
+
+// ConvertToBinaryTrie copies the merkle-patricia state with the given root into
+// binary tries, writing the nodes in the hash scheme into dst. It returns the
+// root of the converted state, which has the same accounts, storage and code
+// hashes as the source state, along with the conversion statistics. The
+// progress callback, if set, is invoked after every flush of the account trie.
+//
+// Storage tries are converted in memory one at a time, so the conversion of
+// states with very large contracts needs a matching amount of memory.
+func ConvertToBinaryTrie(src *triedb.Database, root common.Hash, dst ethdb.Database, progress func(stats BinaryConvertStats)) (common.Hash, BinaryConvertStats, error) {
+	var stats BinaryConvertStats
+
+	tr, err := trie.NewStateTrie(trie.StateTrieID(root), src)
+	if err != nil {
+		return common.Hash{}, stats, err
+	}
+	nodeIt, err := tr.NodeIterator(nil)
+	if err != nil {
+		return common.Hash{}, stats, err
+	}
+	var (
+		dstdb = triedb.NewDatabase(dst, &triedb.Config{HashDB: hashdb.Defaults, IsBinary: true})
+		batch = dst.NewBatch()
+	)
+	binRoot := types.EmptyRootHash
+	bin, err := trie.NewBinaryTrie(trie.StateTrieID(binRoot), dstdb)
+	if err != nil {
+		return common.Hash{}, stats, err
+	}
+	// flush commits the account trie converted so far, writes it out and
+	// reopens it from disk so that the converted nodes can be released.
+	flush := func() error {
+		var nodes *trienode.NodeSet
+		binRoot, nodes, err = bin.Commit(false)
+		if err != nil {
+			return err
+		}
+		if err := writeBinaryNodes(batch, nodes, &stats); err != nil {
+			return err
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+		if progress != nil {
+			progress(stats)
+		}
+		bin, err = trie.NewBinaryTrie(trie.StateTrieID(binRoot), dstdb)
+		return err
+	}
+	it := trie.NewIterator(nodeIt)
+	for it.Next() {
+		acc, err := types.FullAccount(it.Value)
+		if err != nil {
+			return common.Hash{}, stats, err
+		}
+		if acc.Root != types.EmptyRootHash {
+			addrHash := common.BytesToHash(it.Key)
+			if acc.Root, err = convertBinaryStorage(src, root, addrHash, acc.Root, dstdb, batch, &stats); err != nil {
+				return common.Hash{}, stats, err
+			}
+		}
+		data, err := rlp.EncodeToBytes(acc)
+		if err != nil {
+			return common.Hash{}, stats, err
+		}
+		if err := bin.UpdateHashed(it.Key, data); err != nil {
+			return common.Hash{}, stats, err
+		}
+		stats.Accounts++
+		if stats.Accounts%binaryConvertFlushInterval == 0 {
+			if err := flush(); err != nil {
+				return common.Hash{}, stats, err
+			}
+		}
+	}
+	if it.Err != nil {
+		return common.Hash{}, stats, it.Err
+	}
+	if err := flush(); err != nil {
+		return common.Hash{}, stats, err
+	}
+	return binRoot, stats, nil
+}
+
+// convertBinaryStorage converts the storage trie of a single account, queuing
+// its nodes into the batch and returning the binary storage root.
+func convertBinaryStorage(src *triedb.Database, stateRoot, addrHash, root common.Hash, dstdb *triedb.Database, batch ethdb.Batch, stats *BinaryConvertStats) (common.Hash, error) {
+	tr, err := trie.NewStateTrie(trie.StorageTrieID(stateRoot, addrHash, root), src)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	nodeIt, err := tr.NodeIterator(nil)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	bin, err := trie.NewBinaryTrie(trie.StorageTrieID(types.EmptyRootHash, addrHash, types.EmptyRootHash), dstdb)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	it := trie.NewIterator(nodeIt)
+	for it.Next() {
+		if err := bin.UpdateHashed(it.Key, common.CopyBytes(it.Value)); err != nil {
+			return common.Hash{}, err
+		}
+		stats.Slots++
+	}
+	if it.Err != nil {
+		return common.Hash{}, it.Err
+	}
+	binRoot, nodes, err := bin.Commit(false)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return binRoot, writeBinaryNodes(batch, nodes, stats)
+}
+
+// writeBinaryNodes queues the committed nodes into the batch, flushing it
+// whenever it grows too large.
+func writeBinaryNodes(batch ethdb.Batch, nodes *trienode.NodeSet, stats *BinaryConvertStats) error {
+	if nodes == nil {
+		return nil
+	}
+	for _, n := range nodes.Nodes {
+		rawdb.WriteLegacyTrieNode(batch, n.Hash, n.Blob)
+		stats.Nodes++
+		stats.Size += uint64(len(n.Blob))
+
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/hashdb"
+	"github.com/holiman/uint256"
+)
+
+// fillConvertTestState populates the state with some accounts and storage,
+// returning the committed root.
+func fillConvertTestState(t *testing.T, db Database) common.Hash {
+	state, _ := New(types.EmptyRootHash, db, nil)
+	for i := byte(0); i < 100; i++ {
+		addr := common.BytesToAddress([]byte{i})
+		state.AddBalance(addr, uint256.NewInt(uint64(11*i)+1))
+		state.SetNonce(addr, uint64(42*i))
+		if i%3 == 0 {
+			for j := byte(0); j < i; j++ {
+				state.SetState(addr, common.BytesToHash([]byte{i, j}), common.BytesToHash([]byte{j, i}))
+			}
+		}
+	}
+	root, err := state.Commit(0, false)
+	if err != nil {
+		t.Fatalf("Failed to commit state: %v", err)
+	}
+	if err := db.TrieDB().Commit(root, false); err != nil {
+		t.Fatalf("Failed to commit trie database: %v", err)
+	}
+	return root
+}
+
+func TestConvertToBinaryTrie(t *testing.T) {
+	var (
+		mptdisk = rawdb.NewMemoryDatabase()
+		mptdb   = NewDatabaseWithConfig(mptdisk, nil)
+		bindisk = rawdb.NewMemoryDatabase()
+		bindb   = NewDatabaseWithConfig(bindisk, &triedb.Config{HashDB: hashdb.Defaults, IsBinary: true})
+	)
+	mptRoot := fillConvertTestState(t, mptdb)
+	binRoot := fillConvertTestState(t, bindb)
+	if mptRoot == binRoot {
+		t.Fatalf("Binary layout produced the merkle-patricia root %x", mptRoot)
+	}
+	// Converting the merkle-patricia state must yield the same binary state
+	// as building it from scratch
+	out := rawdb.NewMemoryDatabase()
+	root, stats, err := ConvertToBinaryTrie(mptdb.TrieDB(), mptRoot, out, nil)
+	if err != nil {
+		t.Fatalf("Failed to convert state: %v", err)
+	}
+	if root != binRoot {
+		t.Fatalf("Converted root mismatch: have %x, want %x", root, binRoot)
+	}
+	if stats.Accounts != 100 || stats.Slots != 1683 {
+		t.Fatalf("Stats mismatch: have %d accounts %d slots, want 100 and 1683", stats.Accounts, stats.Slots)
+	}
+	// The converted state must be readable from the output database alone
+	state, err := New(root, NewDatabaseWithConfig(out, &triedb.Config{HashDB: hashdb.Defaults, IsBinary: true}), nil)
+	if err != nil {
+		t.Fatalf("Failed to open converted state: %v", err)
+	}
+	for i := byte(0); i < 100; i++ {
+		addr := common.BytesToAddress([]byte{i})
+		if balance := state.GetBalance(addr); balance.Uint64() != uint64(11*i)+1 {
+			t.Fatalf("Balance mismatch for %x: %v", addr, balance)
+		}
+		if nonce := state.GetNonce(addr); nonce != uint64(42*i) {
+			t.Fatalf("Nonce mismatch for %x: %d", addr, nonce)
+		}
+		if i%3 == 0 && i > 0 {
+			key := common.BytesToHash([]byte{i, i - 1})
+			if val := state.GetState(addr, key); val != common.BytesToHash([]byte{i - 1, i}) {
+				t.Fatalf("Storage mismatch for %x: %x", addr, val)
+			}
+		}
+	}
+}
@@ -182,7 +182,15 @@ func (db *cachingDB) OpenTrie(root common.Hash) (Trie, error) {
 	if db.triedb.IsVerkle() {
 		return trie.NewVerkleTrie(root, db.triedb, utils.NewPointCache(commitmentCacheItems))
 	}
-	tr, err := trie.NewStateTrie(trie.StateTrieID(root), db.triedb)
+	var (
+		tr  Trie
+		err error
+	)
+	if db.triedb.IsBinary() {
+		tr, err = trie.NewBinaryTrie(trie.StateTrieID(root), db.triedb)
+	} else {
+		tr, err = trie.NewStateTrie(trie.StateTrieID(root), db.triedb)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -197,7 +205,16 @@ func (db *cachingDB) OpenStorageTrie(stateRoot common.Hash, address common.Addre
 	if db.triedb.IsVerkle() {
 		return self, nil
 	}
-	tr, err := trie.NewStateTrie(trie.StorageTrieID(stateRoot, crypto.Keccak256Hash(address.Bytes()), root), db.triedb)
+	var (
+		id  = trie.StorageTrieID(stateRoot, crypto.Keccak256Hash(address.Bytes()), root)
+		tr  Trie
+		err error
+	)
+	if db.triedb.IsBinary() {
+		tr, err = trie.NewBinaryTrie(id, db.triedb)
+	} else {
+		tr, err = trie.NewStateTrie(id, db.triedb)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -209,6 +226,8 @@ func (db *cachingDB) CopyTrie(t Trie) Trie {
 	switch t := t.(type) {
 	case *trie.StateTrie:
 		return t.Copy()
+	case *trie.BinaryTrie:
+		return t.Copy()
 	default:
 		panic(fmt.Errorf("unknown trie type %T", t))
 	}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/state/snapshot"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -37,6 +38,7 @@ type DumpConfig struct {
 	OnlyWithAddresses bool
 	Start             []byte
 	Max               uint64
+	Abort             <-chan struct{} // Closing it stops the iteration
 }
 
 // DumpCollector interface which the state trie calls during iteration
@@ -111,32 +113,125 @@ func (d iterativeDump) OnRoot(root common.Hash) {
 	}{root})
 }
 
+// dumpIterator iterates the accounts or the storage slots of a state in the
+// order of their hashes.
+type dumpIterator interface {
+	Next() bool
+	Error() error
+	Hash() []byte  // Hash of the current account or slot
+	Value() []byte // RLP encoded (slim) account or storage value
+	Release()
+}
+
+// trieDumpIterator is a dumpIterator backed by a trie.
+type trieDumpIterator struct{ it *trie.Iterator }
+
+func (it *trieDumpIterator) Next() bool    { return it.it.Next() }
+func (it *trieDumpIterator) Error() error  { return it.it.Err }
+func (it *trieDumpIterator) Hash() []byte  { return it.it.Key }
+func (it *trieDumpIterator) Value() []byte { return it.it.Value }
+func (it *trieDumpIterator) Release()      {}
+
+// snapshotDumpIterator is a dumpIterator backed by a snapshot.
+type snapshotDumpIterator struct {
+	snapshot.Iterator
+	value func() []byte
+}
+
+func (it *snapshotDumpIterator) Hash() []byte  { return it.Iterator.Hash().Bytes() }
+func (it *snapshotDumpIterator) Value() []byte { return it.value() }
+
+// dumpSeek converts a (partial) iteration start key into a snapshot seek hash.
+func dumpSeek(start []byte) common.Hash {
+	var seek common.Hash
+	copy(seek[:], start)
+	return seek
+}
+
+// dumpAccountIterator returns an iterator over the accounts of the state with
+// the given root, starting at the given key. The snapshot is used if it's
+// available for the root, as it's much faster to iterate than the trie and the
+// only option for some state schemes. Both iterate in hash order, so paginated
+// dumps are consistent regardless of the backend serving each page.
+func (s *StateDB) dumpAccountIterator(root common.Hash, start []byte) (dumpIterator, error) {
+	if s.snaps != nil && root == s.originalRoot {
+		if it, err := s.snaps.AccountIterator(root, dumpSeek(start)); err == nil {
+			return &snapshotDumpIterator{Iterator: it, value: it.Account}, nil
+		}
+	}
+	tr, err := s.openTrie()
+	if err != nil {
+		return nil, err
+	}
+	trieIt, err := tr.NodeIterator(start)
+	if err != nil {
+		return nil, err
+	}
+	return &trieDumpIterator{trie.NewIterator(trieIt)}, nil
+}
+
+// dumpStorageIterator returns an iterator over the storage of the given account.
+func (s *StateDB) dumpStorageIterator(root common.Hash, obj *stateObject) (dumpIterator, error) {
+	if s.snaps != nil && root == s.originalRoot {
+		if it, err := s.snaps.StorageIterator(root, obj.addrHash, common.Hash{}); err == nil {
+			return &snapshotDumpIterator{Iterator: it, value: it.Slot}, nil
+		}
+	}
+	tr, err := obj.getTrie()
+	if err != nil {
+		return nil, err
+	}
+	trieIt, err := tr.NodeIterator(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &trieDumpIterator{trie.NewIterator(trieIt)}, nil
+}
+
 // DumpToCollector iterates the state according to the given options and inserts
 // the items into a collector for aggregation or serialization.
+//
+// Accounts are iterated in the order of their hashes. If the dump is cut short
+// by conf.Max, the hash of the next account is returned, which can be passed as
+// conf.Start to continue the dump.
 func (s *StateDB) DumpToCollector(c DumpCollector, conf *DumpConfig) (nextKey []byte) {
 	// Sanitize the input to allow nil configs
 	if conf == nil {
 		conf = new(DumpConfig)
 	}
+	tr, err := s.openTrie()
+	if err != nil {
+		log.Error("Trie dumping error", "err", err)
+		return nil
+	}
 	var (
 		missingPreimages int
 		accounts         uint64
 		start            = time.Now()
 		logged           = time.Now()
+		root             = tr.Hash()
 	)
-	log.Info("Trie dumping started", "root", s.trie.Hash())
-	c.OnRoot(s.trie.Hash())
+	log.Info("Trie dumping started", "root", root)
+	c.OnRoot(root)
 
-	trieIt, err := s.trie.NodeIterator(conf.Start)
+	it, err := s.dumpAccountIterator(root, conf.Start)
 	if err != nil {
 		log.Error("Trie dumping error", "err", err)
 		return nil
 	}
-	it := trie.NewIterator(trieIt)
+	defer it.Release()
+
 	for it.Next() {
-		var data types.StateAccount
-		if err := rlp.DecodeBytes(it.Value, &data); err != nil {
-			panic(err)
+		select {
+		case <-conf.Abort:
+			log.Info("Trie dumping aborted", "at", common.BytesToHash(it.Hash()), "accounts", accounts)
+			return nil
+		default:
+		}
+		data, err := types.FullAccount(it.Value())
+		if err != nil {
+			log.Error("Failed to decode account", "hash", common.BytesToHash(it.Hash()), "err", err)
+			return nil
 		}
 		var (
 			account = DumpAccount{
@@ -144,11 +239,11 @@ func (s *StateDB) DumpToCollector(c DumpCollector, conf *DumpConfig) (nextKey []
 				Nonce:       data.Nonce,
 				Root:        data.Root[:],
 				CodeHash:    data.CodeHash,
-				AddressHash: it.Key,
+				AddressHash: it.Hash(),
 			}
 			address   *common.Address
 			addr      common.Address
-			addrBytes = s.trie.GetKey(it.Key)
+			addrBytes = tr.GetKey(it.Hash())
 		)
 		if addrBytes == nil {
 			missingPreimages++
@@ -160,46 +255,54 @@ func (s *StateDB) DumpToCollector(c DumpCollector, conf *DumpConfig) (nextKey []
 			address = &addr
 			account.Address = address
 		}
-		obj := newObject(s, addr, &data)
+		obj := newObject(s, addr, data)
+		obj.addrHash = common.BytesToHash(it.Hash()) // the address may be unknown
 		if !conf.SkipCode {
 			account.Code = obj.Code()
 		}
 		if !conf.SkipStorage {
 			account.Storage = make(map[common.Hash]string)
-			tr, err := obj.getTrie()
-			if err != nil {
-				log.Error("Failed to load storage trie", "err", err)
-				continue
-			}
-			trieIt, err := tr.NodeIterator(nil)
+			storageIt, err := s.dumpStorageIterator(root, obj)
 			if err != nil {
-				log.Error("Failed to create trie iterator", "err", err)
+				log.Error("Failed to create storage iterator", "err", err)
 				continue
 			}
-			storageIt := trie.NewIterator(trieIt)
 			for storageIt.Next() {
-				_, content, _, err := rlp.Split(storageIt.Value)
+				_, content, _, err := rlp.Split(storageIt.Value())
 				if err != nil {
 					log.Error("Failed to decode the value returned by iterator", "error", err)
 					continue
 				}
-				account.Storage[common.BytesToHash(s.trie.GetKey(storageIt.Key))] = common.Bytes2Hex(content)
+				// Key slots by their hash if the preimage is unknown, rather
+				// than collapsing them all into the zero key
+				key := common.BytesToHash(storageIt.Hash())
+				if preimage := tr.GetKey(storageIt.Hash()); preimage != nil {
+					key = common.BytesToHash(preimage)
+				}
+				account.Storage[key] = common.Bytes2Hex(content)
+			}
+			if err := storageIt.Error(); err != nil {
+				log.Error("Storage iteration failed", "err", err)
 			}
+			storageIt.Release()
 		}
 		c.OnAccount(address, account)
 		accounts++
 		if time.Since(logged) > 8*time.Second {
-			log.Info("Trie dumping in progress", "at", it.Key, "accounts", accounts,
+			log.Info("Trie dumping in progress", "at", common.BytesToHash(it.Hash()), "accounts", accounts,
 				"elapsed", common.PrettyDuration(time.Since(start)))
 			logged = time.Now()
 		}
 		if conf.Max > 0 && accounts >= conf.Max {
 			if it.Next() {
-				nextKey = it.Key
+				nextKey = it.Hash()
 			}
 			break
 		}
 	}
+	if err := it.Error(); err != nil {
+		log.Error("Trie dumping error", "err", err)
+	}
 	if missingPreimages > 0 {
 		log.Warn("Dump incomplete due to missing preimages", "missing", missingPreimages)
 	}
@@ -34,4 +34,7 @@ var (
 	slotDeletionCount    = metrics.NewRegisteredMeter("state/delete/storage/slot", nil)
 	slotDeletionSize     = metrics.NewRegisteredMeter("state/delete/storage/size", nil)
 	slotDeletionSkip     = metrics.NewRegisteredGauge("state/delete/storage/skip", nil)
+
+	snapshotAccountMissMeter = metrics.NewRegisteredMeter("state/snapshot/miss/account", nil)
+	snapshotStorageMissMeter = metrics.NewRegisteredMeter("state/snapshot/miss/storage", nil)
 )
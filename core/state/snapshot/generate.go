@@ -25,6 +25,7 @@ import (
 	"github.com/VictoriaMetrics/fastcache"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/maintenance"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -482,7 +483,8 @@ func (dl *diskLayer) checkAndFlush(ctx *generatorContext, current []byte) error
 	case abort = <-dl.genAbort:
 	default:
 	}
-	if ctx.batch.ValueSize() > ethdb.IdealBatchSize || abort != nil {
+	paused := maintenance.Paused()
+	if ctx.batch.ValueSize() > ethdb.IdealBatchSize || abort != nil || paused {
 		if bytes.Compare(current, dl.genMarker) < 0 {
 			log.Error("Snapshot generator went backwards", "current", fmt.Sprintf("%x", current), "genMarker", fmt.Sprintf("%x", dl.genMarker))
 		}
@@ -504,6 +506,20 @@ func (dl *diskLayer) checkAndFlush(ctx *generatorContext, current []byte) error
 			ctx.stats.Log("Aborting state snapshot generation", dl.root, current)
 			return newAbortErr(abort) // bubble up an error for interruption
 		}
+		// Hold off while background maintenance is paused, the progress is
+		// already persisted so the generation may also be aborted meanwhile.
+		if paused {
+			ctx.stats.Log("Pausing state snapshot generation", dl.root, current)
+			resumed, release := maintenance.Hold(maintenance.TaskSnapshot)
+			select {
+			case <-resumed:
+				release()
+			case abort = <-dl.genAbort:
+				release()
+				ctx.stats.Log("Aborting state snapshot generation", dl.root, current)
+				return newAbortErr(abort)
+			}
+		}
 		// Don't hold the iterators too long, release them to let compactor works
 		ctx.reopenIterator(snapAccount)
 		ctx.reopenIterator(snapStorage)
@@ -210,6 +210,9 @@ func (s *stateObject) GetCommittedState(key common.Hash) common.Hash {
 	}
 	// If the snapshot is unavailable or reading from it fails, load from the database.
 	if s.db.snap == nil || err != nil {
+		if s.db.snap != nil {
+			snapshotStorageMissMeter.Mark(1)
+		}
 		start := time.Now()
 		tr, err := s.getTrie()
 		if err != nil {
@@ -284,19 +287,27 @@ func (s *stateObject) updateTrie() (Trie, error) {
 	}
 	// Track the amount of time wasted on updating the storage trie
 	if metrics.EnabledExpensive {
-		defer func(start time.Time) { s.db.StorageUpdates += time.Since(start) }(time.Now())
+		defer func(start time.Time) {
+			s.db.lock.Lock()
+			s.db.StorageUpdates += time.Since(start)
+			s.db.lock.Unlock()
+		}(time.Now())
 	}
-	// The snapshot storage map for the object
-	var (
-		storage map[common.Hash][]byte
-		origin  map[common.Hash][]byte
-	)
 	tr, err := s.getTrie()
 	if err != nil {
 		s.db.setError(err)
 		return nil, err
 	}
-	// Insert all the pending storage updates into the trie
+	// Insert all the pending storage updates into the trie, collecting the
+	// snapshot storage and original values locally, as storage tries may be
+	// updated concurrently.
+	var (
+		hasher  = crypto.NewKeccakState()
+		storage = make(map[common.Hash][]byte)
+		origin  = make(map[common.Hash][]byte)
+
+		updated, deleted int
+	)
 	usedStorage := make([][]byte, 0, len(s.pendingStorage))
 	for key, value := range s.pendingStorage {
 		// Skip noop changes, persist actual changes
@@ -312,7 +323,7 @@ func (s *stateObject) updateTrie() (Trie, error) {
 				s.db.setError(err)
 				return nil, err
 			}
-			s.db.StorageDeleted += 1
+			deleted += 1
 		} else {
 			// Encoding []byte cannot fail, ok to ignore the error.
 			trimmed := common.TrimLeftZeroes(value[:])
@@ -321,38 +332,49 @@ func (s *stateObject) updateTrie() (Trie, error) {
 				s.db.setError(err)
 				return nil, err
 			}
-			s.db.StorageUpdated += 1
+			updated += 1
 		}
 		// Cache the mutated storage slots until commit
-		if storage == nil {
-			if storage = s.db.storages[s.addrHash]; storage == nil {
-				storage = make(map[common.Hash][]byte)
-				s.db.storages[s.addrHash] = storage
-			}
-		}
-		khash := crypto.HashData(s.db.hasher, key[:])
+		khash := crypto.HashData(hasher, key[:])
 		storage[khash] = encoded // encoded will be nil if it's deleted
 
 		// Cache the original value of mutated storage slots
-		if origin == nil {
-			if origin = s.db.storagesOrigin[s.address]; origin == nil {
-				origin = make(map[common.Hash][]byte)
-				s.db.storagesOrigin[s.address] = origin
-			}
+		if prev == (common.Hash{}) {
+			origin[khash] = nil // nil if it was not present previously
+		} else {
+			// Encoding []byte cannot fail, ok to ignore the error.
+			b, _ := rlp.EncodeToBytes(common.TrimLeftZeroes(prev[:]))
+			origin[khash] = b
+		}
+		// Cache the items for preloading
+		usedStorage = append(usedStorage, common.CopyBytes(key[:])) // Copy needed for closure
+	}
+	s.db.lock.Lock()
+	s.db.StorageUpdated += updated
+	s.db.StorageDeleted += deleted
+	if len(storage) > 0 {
+		dst := s.db.storages[s.addrHash]
+		if dst == nil {
+			dst = make(map[common.Hash][]byte)
+			s.db.storages[s.addrHash] = dst
+		}
+		for khash, encoded := range storage {
+			dst[khash] = encoded
 		}
 		// Track the original value of slot only if it's mutated first time
-		if _, ok := origin[khash]; !ok {
-			if prev == (common.Hash{}) {
-				origin[khash] = nil // nil if it was not present previously
-			} else {
-				// Encoding []byte cannot fail, ok to ignore the error.
-				b, _ := rlp.EncodeToBytes(common.TrimLeftZeroes(prev[:]))
-				origin[khash] = b
+		orig := s.db.storagesOrigin[s.address]
+		if orig == nil {
+			orig = make(map[common.Hash][]byte)
+			s.db.storagesOrigin[s.address] = orig
+		}
+		for khash, prev := range origin {
+			if _, ok := orig[khash]; !ok {
+				orig[khash] = prev
 			}
 		}
-		// Cache the items for preloading
-		usedStorage = append(usedStorage, common.CopyBytes(key[:])) // Copy needed for closure
 	}
+	s.db.lock.Unlock()
+
 	if s.db.prefetcher != nil {
 		s.db.prefetcher.used(s.addrHash, s.data.Root, usedStorage)
 	}
@@ -371,7 +393,11 @@ func (s *stateObject) updateRoot() {
 	}
 	// Track the amount of time wasted on hashing the storage trie
 	if metrics.EnabledExpensive {
-		defer func(start time.Time) { s.db.StorageHashes += time.Since(start) }(time.Now())
+		defer func(start time.Time) {
+			s.db.lock.Lock()
+			s.db.StorageHashes += time.Since(start)
+			s.db.lock.Unlock()
+		}(time.Now())
 	}
 	s.data.Root = tr.Hash()
 }
@@ -387,7 +413,11 @@ func (s *stateObject) commit() (*trienode.NodeSet, error) {
 	}
 	// Track the amount of time wasted on committing the storage trie
 	if metrics.EnabledExpensive {
-		defer func(start time.Time) { s.db.StorageCommits += time.Since(start) }(time.Now())
+		defer func(start time.Time) {
+			s.db.lock.Lock()
+			s.db.StorageCommits += time.Since(start)
+			s.db.lock.Unlock()
+		}(time.Now())
 	}
 	// The trie is currently in an open state and could potentially contain
 	// cached mutations. Call commit to acquire a set of nodes that have been
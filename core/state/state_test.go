@@ -19,10 +19,13 @@ package state
 import (
 	"bytes"
 	"encoding/json"
+	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state/snapshot"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -135,6 +138,60 @@ func TestIterativeDump(t *testing.T) {
 	}
 }
 
+func TestDumpPagination(t *testing.T) {
+	var (
+		disk     = rawdb.NewMemoryDatabase()
+		tdb      = triedb.NewDatabase(disk, &triedb.Config{Preimages: true})
+		db       = NewDatabaseWithNodeDB(disk, tdb)
+		snaps, _ = snapshot.New(snapshot.Config{CacheSize: 10}, disk, tdb, types.EmptyRootHash)
+		state, _ = New(types.EmptyRootHash, db, snaps)
+	)
+	for i := byte(1); i <= 10; i++ {
+		addr := common.BytesToAddress([]byte{i})
+		state.SetBalance(addr, uint256.NewInt(uint64(i)))
+		state.SetState(addr, common.Hash{i}, common.Hash{i})
+	}
+	root, _ := state.Commit(0, true)
+
+	// Walk the state in pages, both from the snapshot and from the trie
+	walk := func(snaps *snapshot.Tree) ([]DumpAccount, int) {
+		state, _ := New(root, db, snaps)
+		var (
+			accounts []DumpAccount
+			pages    int
+			next     []byte
+		)
+		for {
+			dump := state.RawDump(&DumpConfig{Start: next, Max: 3})
+			for _, account := range dump.Accounts {
+				accounts = append(accounts, account)
+			}
+			pages++
+			if next = dump.Next; next == nil {
+				break
+			}
+		}
+		sort.Slice(accounts, func(i, j int) bool { return bytes.Compare(accounts[i].AddressHash, accounts[j].AddressHash) < 0 })
+		return accounts, pages
+	}
+	fromSnap, pages := walk(snaps)
+	if len(fromSnap) != 10 || pages != 4 {
+		t.Fatalf("snapshot walk: have %d accounts in %d pages, want 10 in 4", len(fromSnap), pages)
+	}
+	for _, account := range fromSnap {
+		if len(account.Storage) != 1 {
+			t.Errorf("account %x: have %d storage slots, want 1", account.AddressHash, len(account.Storage))
+		}
+	}
+	fromTrie, pages := walk(nil)
+	if pages != 4 {
+		t.Fatalf("trie walk: have %d pages, want 4", pages)
+	}
+	if !reflect.DeepEqual(fromSnap, fromTrie) {
+		t.Errorf("snapshot and trie dumps differ:\nsnapshot: %v\ntrie:     %v", fromSnap, fromTrie)
+	}
+}
+
 func TestNull(t *testing.T) {
 	s := newStateEnv()
 	address := common.HexToAddress("0x823140710bf13990e4500136726d8b55")
@@ -19,7 +19,9 @@ package state
 
 import (
 	"fmt"
+	"runtime"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -33,6 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/trie/trienode"
 	"github.com/ethereum/go-ethereum/trie/triestate"
 	"github.com/holiman/uint256"
+	"golang.org/x/sync/errgroup"
 )
 
 const (
@@ -41,6 +44,18 @@ const (
 	storageDeleteLimit = 512 * 1024 * 1024
 )
 
+// newTrieWorkers creates a worker group limited to the given number of
+// concurrent storage trie operations, or the number of available CPUs if
+// non-positive.
+func newTrieWorkers(n int) *errgroup.Group {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	workers := new(errgroup.Group)
+	workers.SetLimit(n)
+	return workers
+}
+
 type revision struct {
 	id           int
 	journalIndex int
@@ -62,6 +77,7 @@ type StateDB struct {
 	prefetcher *triePrefetcher
 	trie       Trie
 	hasher     crypto.KeccakState
+	workers    int               // Number of storage tries processed concurrently (0 = autodetect)
 	snaps      *snapshot.Tree    // Nil if snapshot is not available
 	snap       snapshot.Snapshot // Nil if snapshot is not available
 
@@ -92,6 +108,10 @@ type StateDB struct {
 	// when accessing state of accounts.
 	dbErr error
 
+	// Lock protecting the fields above (db error, storage changes) and the
+	// storage measurements below while storage tries are processed concurrently.
+	lock sync.Mutex
+
 	// The refund counter, also used by state transitioning.
 	refund uint64
 
@@ -141,13 +161,8 @@ type StateDB struct {
 
 // New creates a new state from a given trie.
 func New(root common.Hash, db Database, snaps *snapshot.Tree) (*StateDB, error) {
-	tr, err := db.OpenTrie(root)
-	if err != nil {
-		return nil, err
-	}
 	sdb := &StateDB{
 		db:                   db,
-		trie:                 tr,
 		originalRoot:         root,
 		snaps:                snaps,
 		accounts:             make(map[common.Hash][]byte),
@@ -168,9 +183,49 @@ func New(root common.Hash, db Database, snaps *snapshot.Tree) (*StateDB, error)
 	if sdb.snaps != nil {
 		sdb.snap = sdb.snaps.Snapshot(root)
 	}
+	// If the snapshot is available, all reads are served from it and the
+	// account trie is only opened once a read misses or the state gets hashed.
+	// Read-only users like eth_call thus never touch the trie at all.
+	if sdb.snap == nil || db.TrieDB().IsVerkle() {
+		if _, err := sdb.openTrie(); err != nil {
+			return nil, err
+		}
+	} else if err := hasStateRoot(db, root); err != nil {
+		// The snapshot may outlive the trie nodes it was generated from, but
+		// the state is only usable if the trie can be opened once needed.
+		return nil, err
+	}
 	return sdb, nil
 }
 
+// hasStateRoot checks whether the state with the given root is available in the
+// trie database, same as opening the account trie would, without opening it.
+func hasStateRoot(db Database, root common.Hash) error {
+	if root == (common.Hash{}) || root == types.EmptyRootHash {
+		return nil
+	}
+	_, err := db.TrieDB().Reader(root)
+	return err
+}
+
+// openTrie returns the account trie, opening it first if it was deferred.
+func (s *StateDB) openTrie() (Trie, error) {
+	if s.trie == nil {
+		tr, err := s.db.OpenTrie(s.originalRoot)
+		if err != nil {
+			return nil, err
+		}
+		s.trie = tr
+	}
+	return s.trie, nil
+}
+
+// SetTrieWorkers sets the number of storage tries updated, hashed and committed
+// concurrently. Non-positive values select the number of available CPUs.
+func (s *StateDB) SetTrieWorkers(n int) {
+	s.workers = n
+}
+
 // StartPrefetcher initializes a new trie prefetcher to pull in nodes from the
 // state trie concurrently while the state is mutated so that when we reach the
 // commit phase, most of the needed data is already hot.
@@ -195,6 +250,9 @@ func (s *StateDB) StopPrefetcher() {
 
 // setError remembers the first non-nil error it is called with.
 func (s *StateDB) setError(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
 	if s.dbErr == nil {
 		s.dbErr = err
 	}
@@ -573,25 +631,21 @@ func (s *StateDB) getDeletedStateObject(addr common.Address) *stateObject {
 			if acc == nil {
 				return nil
 			}
-			data = &types.StateAccount{
-				Nonce:    acc.Nonce,
-				Balance:  acc.Balance,
-				CodeHash: acc.CodeHash,
-				Root:     common.BytesToHash(acc.Root),
-			}
-			if len(data.CodeHash) == 0 {
-				data.CodeHash = types.EmptyCodeHash.Bytes()
-			}
-			if data.Root == (common.Hash{}) {
-				data.Root = types.EmptyRootHash
-			}
+			data = snapshotToStateAccount(acc)
 		}
 	}
 	// If snapshot unavailable or reading from it failed, load from the database
 	if data == nil {
+		if s.snap != nil {
+			snapshotAccountMissMeter.Mark(1)
+		}
+		tr, err := s.openTrie()
+		if err != nil {
+			s.setError(fmt.Errorf("getDeleteStateObject (%x) error: %w", addr.Bytes(), err))
+			return nil
+		}
 		start := time.Now()
-		var err error
-		data, err = s.trie.GetAccount(addr)
+		data, err = tr.GetAccount(addr)
 		if metrics.EnabledExpensive {
 			s.AccountReads += time.Since(start)
 		}
@@ -687,7 +741,6 @@ func (s *StateDB) Copy() *StateDB {
 	// Copy all the basic fields, initialize the memory ones
 	state := &StateDB{
 		db:                   s.db,
-		trie:                 s.db.CopyTrie(s.trie),
 		originalRoot:         s.originalRoot,
 		accounts:             make(map[common.Hash][]byte),
 		storages:             make(map[common.Hash]map[common.Hash][]byte),
@@ -703,6 +756,7 @@ func (s *StateDB) Copy() *StateDB {
 		preimages:            make(map[common.Hash][]byte, len(s.preimages)),
 		journal:              newJournal(),
 		hasher:               crypto.NewKeccakState(),
+		workers:              s.workers,
 
 		// In order for the block producer to be able to use and make additions
 		// to the snapshot tree, we need to copy that as well. Otherwise, any
@@ -711,6 +765,9 @@ func (s *StateDB) Copy() *StateDB {
 		snaps: s.snaps,
 		snap:  s.snap,
 	}
+	if s.trie != nil {
+		state.trie = s.db.CopyTrie(s.trie)
+	}
 	// Copy the dirty states, logs, and preimages
 	for addr := range s.journal.dirties {
 		// As documented [here](https://github.com/ethereum/go-ethereum/pull/16485#issuecomment-380438527),
@@ -894,11 +951,30 @@ func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
 	// the account prefetcher. Instead, let's process all the storage updates
 	// first, giving the account prefetches just a few more milliseconds of time
 	// to pull useful data from disk.
+	//
+	// The storage tries are independent of each other, so they are updated and
+	// hashed concurrently.
+	var (
+		start   = time.Now()
+		updates = s.StorageUpdates
+		hashes  = s.StorageHashes
+		workers = newTrieWorkers(s.workers)
+	)
 	for addr := range s.stateObjectsPending {
 		if obj := s.stateObjects[addr]; !obj.deleted {
-			obj.updateRoot()
+			workers.Go(func() error {
+				obj.updateRoot()
+				return nil
+			})
 		}
 	}
+	workers.Wait()
+
+	// The storage measurements summed up the time spent by all workers, scale
+	// them back to the wall clock time of the parallel phase.
+	if metrics.EnabledExpensive {
+		s.StorageUpdates, s.StorageHashes = splitElapsed(time.Since(start), updates, s.StorageUpdates, hashes, s.StorageHashes)
+	}
 	// Now we're about to start to write changes to the trie. The trie is so far
 	// _untouched_. We can check with the prefetcher, if it can give us a trie
 	// which has the same root, but also has some content loaded into it.
@@ -907,6 +983,12 @@ func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
 			s.trie = trie
 		}
 	}
+	// Open the account trie if it was deferred and not provided by the
+	// prefetcher either.
+	if _, err := s.openTrie(); err != nil {
+		s.setError(fmt.Errorf("failed to open account trie: %w", err))
+		return common.Hash{}
+	}
 	usedAddrs := make([][]byte, 0, len(s.stateObjectsPending))
 	for addr := range s.stateObjectsPending {
 		if obj := s.stateObjects[addr]; obj.deleted {
@@ -1168,6 +1250,9 @@ func (s *StateDB) Commit(block uint64, deleteEmptyObjects bool) (common.Hash, er
 	}
 	// Finalize any pending changes and merge everything into the tries
 	s.IntermediateRoot(deleteEmptyObjects)
+	if s.trie == nil {
+		return common.Hash{}, fmt.Errorf("commit aborted due to unavailable account trie: %v", s.dbErr)
+	}
 
 	// Commit objects to the trie, measuring the elapsed time
 	var (
@@ -1183,7 +1268,15 @@ func (s *StateDB) Commit(block uint64, deleteEmptyObjects bool) (common.Hash, er
 	if err != nil {
 		return common.Hash{}, err
 	}
-	// Handle all state updates afterwards
+	// Handle all state updates afterwards. The storage tries are committed
+	// concurrently, overlapped with the commit of the account trie, which only
+	// depends on the storage roots already computed by IntermediateRoot.
+	var (
+		start   = time.Now()
+		commits = s.StorageCommits
+		workers = newTrieWorkers(s.workers)
+		objects = make([]*stateObject, 0, len(s.stateObjectsDirty))
+	)
 	for addr := range s.stateObjectsDirty {
 		obj := s.stateObjects[addr]
 		if obj.deleted {
@@ -1194,37 +1287,59 @@ func (s *StateDB) Commit(block uint64, deleteEmptyObjects bool) (common.Hash, er
 			rawdb.WriteCode(codeWriter, common.BytesToHash(obj.CodeHash()), obj.code)
 			obj.dirtyCode = false
 		}
-		// Write any storage changes in the state object to its storage trie
-		set, err := obj.commit()
-		if err != nil {
-			return common.Hash{}, err
-		}
-		// Merge the dirty nodes of storage trie into global set. It is possible
-		// that the account was destructed and then resurrected in the same block.
-		// In this case, the node set is shared by both accounts.
-		if set != nil {
-			if err := nodes.Merge(set); err != nil {
-				return common.Hash{}, err
-			}
-			updates, deleted := set.Size()
-			storageTrieNodesUpdated += updates
-			storageTrieNodesDeleted += deleted
-		}
+		objects = append(objects, obj)
+	}
+	sets := make([]*trienode.NodeSet, len(objects))
+	for i, obj := range objects {
+		i, obj := i, obj
+		workers.Go(func() error {
+			// Write any storage changes in the state object to its storage trie
+			set, err := obj.commit()
+			sets[i] = set
+			return err
+		})
 	}
+	var (
+		root        common.Hash
+		set         *trienode.NodeSet
+		accountTime time.Duration
+		accountErr  error
+		accountDone = make(chan struct{})
+	)
+	go func() {
+		defer close(accountDone)
+
+		// Write the account trie changes, measuring the amount of wasted time
+		start := time.Now()
+		root, set, accountErr = s.trie.Commit(true)
+		accountTime = time.Since(start)
+	}()
 	if codeWriter.ValueSize() > 0 {
 		if err := codeWriter.Write(); err != nil {
 			log.Crit("Failed to commit dirty codes", "error", err)
 		}
 	}
-	// Write the account trie changes, measuring the amount of wasted time
-	var start time.Time
-	if metrics.EnabledExpensive {
-		start = time.Now()
-	}
-	root, set, err := s.trie.Commit(true)
+	err = workers.Wait()
+	<-accountDone
 	if err != nil {
 		return common.Hash{}, err
 	}
+	if accountErr != nil {
+		return common.Hash{}, accountErr
+	}
+	// Merge the dirty nodes of storage tries into global set. It is possible
+	// that the account was destructed and then resurrected in the same block.
+	// In this case, the node set is shared by both accounts.
+	for _, storage := range sets {
+		if storage != nil {
+			if err := nodes.Merge(storage); err != nil {
+				return common.Hash{}, err
+			}
+			updates, deleted := storage.Size()
+			storageTrieNodesUpdated += updates
+			storageTrieNodesDeleted += deleted
+		}
+	}
 	// Merge the dirty nodes of account trie into global set
 	if set != nil {
 		if err := nodes.Merge(set); err != nil {
@@ -1233,7 +1348,9 @@ func (s *StateDB) Commit(block uint64, deleteEmptyObjects bool) (common.Hash, er
 		accountTrieNodesUpdated, accountTrieNodesDeleted = set.Size()
 	}
 	if metrics.EnabledExpensive {
-		s.AccountCommits += time.Since(start)
+		// The storage measurements summed up the time spent by all workers,
+		// scale them back to the wall clock time of the parallel phase.
+		s.StorageCommits, s.AccountCommits = splitElapsed(time.Since(start), commits, s.StorageCommits, s.AccountCommits, s.AccountCommits+accountTime)
 
 		accountUpdatedMeter.Mark(int64(s.AccountUpdated))
 		storageUpdatedMeter.Mark(int64(s.StorageUpdated))
@@ -1246,26 +1363,38 @@ func (s *StateDB) Commit(block uint64, deleteEmptyObjects bool) (common.Hash, er
 		s.AccountUpdated, s.AccountDeleted = 0, 0
 		s.StorageUpdated, s.StorageDeleted = 0, 0
 	}
-	// If snapshotting is enabled, update the snapshot tree with this new version
+	// If snapshotting is enabled, update the snapshot tree with this new version.
+	// The snapshot and the trie database are independent of each other, so the
+	// snapshot update is overlapped with the trie database update.
+	//
+	// Note, the commit can't be overlapped with the execution of the next block,
+	// as its state is opened from both the snapshot and the trie database at the
+	// new root.
+	snapDone := make(chan struct{})
 	if s.snap != nil {
-		start := time.Now()
-		// Only update if there's a state transition (skip empty Clique blocks)
-		if parent := s.snap.Root(); parent != root {
-			if err := s.snaps.Update(root, parent, s.convertAccountSet(s.stateObjectsDestruct), s.accounts, s.storages); err != nil {
-				log.Warn("Failed to update snapshot tree", "from", parent, "to", root, "err", err)
+		go func(root common.Hash) {
+			defer close(snapDone)
+
+			start := time.Now()
+			// Only update if there's a state transition (skip empty Clique blocks)
+			if parent := s.snap.Root(); parent != root {
+				if err := s.snaps.Update(root, parent, s.convertAccountSet(s.stateObjectsDestruct), s.accounts, s.storages); err != nil {
+					log.Warn("Failed to update snapshot tree", "from", parent, "to", root, "err", err)
+				}
+				// Keep 128 diff layers in the memory, persistent layer is 129th.
+				// - head layer is paired with HEAD state
+				// - head-1 layer is paired with HEAD-1 state
+				// - head-127 layer(bottom-most diff layer) is paired with HEAD-127 state
+				if err := s.snaps.Cap(root, 128); err != nil {
+					log.Warn("Failed to cap snapshot tree", "root", root, "layers", 128, "err", err)
+				}
 			}
-			// Keep 128 diff layers in the memory, persistent layer is 129th.
-			// - head layer is paired with HEAD state
-			// - head-1 layer is paired with HEAD-1 state
-			// - head-127 layer(bottom-most diff layer) is paired with HEAD-127 state
-			if err := s.snaps.Cap(root, 128); err != nil {
-				log.Warn("Failed to cap snapshot tree", "root", root, "layers", 128, "err", err)
+			if metrics.EnabledExpensive {
+				s.SnapshotCommits += time.Since(start)
 			}
-		}
-		if metrics.EnabledExpensive {
-			s.SnapshotCommits += time.Since(start)
-		}
-		s.snap = nil
+		}(root)
+	} else {
+		close(snapDone)
 	}
 	if root == (common.Hash{}) {
 		root = types.EmptyRootHash
@@ -1278,6 +1407,7 @@ func (s *StateDB) Commit(block uint64, deleteEmptyObjects bool) (common.Hash, er
 		start := time.Now()
 		set := triestate.New(s.accountsOrigin, s.storagesOrigin, incomplete)
 		if err := s.db.TrieDB().Update(root, origin, block, nodes, set); err != nil {
+			<-snapDone
 			return common.Hash{}, err
 		}
 		s.originalRoot = root
@@ -1288,6 +1418,9 @@ func (s *StateDB) Commit(block uint64, deleteEmptyObjects bool) (common.Hash, er
 			s.onCommit(set)
 		}
 	}
+	<-snapDone
+	s.snap = nil
+
 	// Clear all internal flags at the end of commit operation.
 	s.accounts = make(map[common.Hash][]byte)
 	s.storages = make(map[common.Hash]map[common.Hash][]byte)
@@ -1306,6 +1439,7 @@ func (s *StateDB) Commit(block uint64, deleteEmptyObjects bool) (common.Hash, er
 // - Add destination to access list (2929)
 // - Add precompiles to access list (2929)
 // - Add the contents of the optional tx access list (2930)
+// - Prefetch the state referenced by the optional tx access list
 //
 // Potential EIPs:
 // - Reset access list (Berlin)
@@ -1334,6 +1468,8 @@ func (s *StateDB) Prepare(eip2930 bool, eip3651 bool, sender, coinbase common.Ad
 		if eip3651 { // EIP-3651: warm coinbase
 			al.AddAddress(coinbase)
 		}
+		// Load the declared state concurrently ahead of execution
+		s.prefetchAccessList(list)
 	}
 	// Reset transient storage at the beginning of transaction execution
 	s.transientStorage = newTransientStorage()
@@ -1408,3 +1544,15 @@ func copy2DSet[k comparable](set map[k]map[common.Hash][]byte) map[k]map[common.
 	}
 	return copied
 }
+
+// splitElapsed distributes the wall clock time elapsed while running two kinds
+// of operations concurrently, in proportion to the summed up durations of each
+// kind (from a0 to a1 and from b0 to b1). It returns the adjusted totals.
+func splitElapsed(elapsed, a0, a1, b0, b1 time.Duration) (time.Duration, time.Duration) {
+	da, db := a1-a0, b1-b0
+	if da+db <= 0 {
+		return a0, b0
+	}
+	share := time.Duration(float64(elapsed) * float64(da) / float64(da+db))
+	return a0 + share, b0 + elapsed - share
+}
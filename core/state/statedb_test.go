@@ -1189,3 +1189,175 @@ func TestDeleteStorage(t *testing.T) {
 		t.Fatalf("difference found:\nfast: %v\nslow: %v\n", fastRes, slowRes)
 	}
 }
+
+// Tests that states backed by a snapshot serve reads without opening the account
+// trie, but open it on demand for iteration and hashing.
+func TestDeferredAccountTrie(t *testing.T) {
+	var (
+		disk     = rawdb.NewMemoryDatabase()
+		tdb      = triedb.NewDatabase(disk, &triedb.Config{Preimages: true})
+		db       = NewDatabaseWithNodeDB(disk, tdb)
+		snaps, _ = snapshot.New(snapshot.Config{CacheSize: 10}, disk, tdb, types.EmptyRootHash)
+		state, _ = New(types.EmptyRootHash, db, snaps)
+		addr     = common.HexToAddress("0x1")
+	)
+	state.SetBalance(addr, uint256.NewInt(1))
+	root, _ := state.Commit(0, true)
+
+	state, err := New(root, db, snaps)
+	if err != nil {
+		t.Fatalf("failed to open state: %v", err)
+	}
+	if state.trie != nil {
+		t.Fatalf("account trie opened despite the snapshot")
+	}
+	if balance := state.GetBalance(addr); balance.Uint64() != 1 {
+		t.Fatalf("balance mismatch: have %v, want 1", balance)
+	}
+	if state.trie != nil {
+		t.Fatalf("account trie opened by a snapshot read")
+	}
+	// Iterating the state needs the account trie
+	var nodes int
+	it := newNodeIterator(state.Copy())
+	for it.Next() {
+		nodes++
+	}
+	if it.Error != nil || nodes == 0 {
+		t.Fatalf("failed to iterate state: %d nodes, error %v", nodes, it.Error)
+	}
+	// So does hashing the modified state
+	state.SetBalance(addr, uint256.NewInt(2))
+	if root := state.IntermediateRoot(true); root == (common.Hash{}) || state.Error() != nil {
+		t.Fatalf("failed to hash state: root %x, error %v", root, state.Error())
+	}
+}
+
+// Tests that states backed by a snapshot can't be opened if the account trie
+// is missing, same as states without a snapshot.
+func TestDeferredAccountTrieMissing(t *testing.T) {
+	var (
+		disk     = rawdb.NewMemoryDatabase()
+		tdb      = triedb.NewDatabase(disk, &triedb.Config{HashDB: &hashdb.Config{CleanCacheSize: 0}})
+		db       = NewDatabaseWithNodeDB(disk, tdb)
+		snaps, _ = snapshot.New(snapshot.Config{CacheSize: 10}, disk, tdb, types.EmptyRootHash)
+		state, _ = New(types.EmptyRootHash, db, snaps)
+	)
+	state.SetBalance(common.HexToAddress("0x1"), uint256.NewInt(1))
+	root, _ := state.Commit(0, true)
+	tdb.Commit(root, false)
+
+	// Drop the root of the account trie, keeping the snapshot
+	rawdb.DeleteLegacyTrieNode(disk, root)
+	if snaps.Snapshot(root) == nil {
+		t.Fatalf("snapshot missing")
+	}
+	if _, err := db.OpenTrie(root); err == nil {
+		t.Fatalf("opened account trie with missing root")
+	}
+	if _, err := New(root, db, snaps); err == nil {
+		t.Fatalf("opened state with missing account trie")
+	}
+}
+
+// Tests that processing the storage tries concurrently yields the same state
+// and tracked storage changes as processing them one by one.
+func TestParallelTrieWorkers(t *testing.T) {
+	commit := func(workers int) (common.Hash, map[common.Hash]map[common.Hash][]byte, map[common.Address]map[common.Hash][]byte) {
+		db := rawdb.NewMemoryDatabase()
+		state, _ := New(types.EmptyRootHash, NewDatabaseWithNodeDB(db, triedb.NewDatabase(db, nil)), nil)
+		state.SetTrieWorkers(workers)
+		for i := byte(0); i < 64; i++ {
+			addr := common.BytesToAddress([]byte{i})
+			state.SetBalance(addr, uint256.NewInt(uint64(i)+1))
+			for j := byte(0); j < i%8; j++ {
+				state.SetState(addr, common.Hash{j}, common.Hash{i, j})
+			}
+		}
+		root, err := state.Commit(0, false)
+		if err != nil {
+			t.Fatalf("failed to commit state with %d workers: %v", workers, err)
+		}
+		// Modify the committed state and track the changes
+		state, _ = New(root, state.db, nil)
+		state.SetTrieWorkers(workers)
+		for i := byte(0); i < 64; i += 2 {
+			addr := common.BytesToAddress([]byte{i})
+			state.SetState(addr, common.Hash{0}, common.Hash{})
+			state.SetState(addr, common.Hash{0xff}, common.Hash{i})
+		}
+		state.IntermediateRoot(false)
+		storages, origins := state.storages, state.storagesOrigin
+
+		root, err = state.Commit(1, false)
+		if err != nil {
+			t.Fatalf("failed to commit state with %d workers: %v", workers, err)
+		}
+		return root, storages, origins
+	}
+	root, storages, origins := commit(1)
+	for _, workers := range []int{4, 16} {
+		proot, pstorages, porigins := commit(workers)
+		if proot != root {
+			t.Errorf("workers %d: root mismatch: have %x, want %x", workers, proot, root)
+		}
+		if !reflect.DeepEqual(pstorages, storages) {
+			t.Errorf("workers %d: storage changes mismatch", workers)
+		}
+		if !reflect.DeepEqual(porigins, origins) {
+			t.Errorf("workers %d: storage origins mismatch", workers)
+		}
+	}
+}
+
+func TestPrefetchAccessList(t *testing.T) {
+	var (
+		disk     = rawdb.NewMemoryDatabase()
+		tdb      = triedb.NewDatabase(disk, nil)
+		db       = NewDatabaseWithNodeDB(disk, tdb)
+		snaps, _ = snapshot.New(snapshot.Config{CacheSize: 10}, disk, tdb, types.EmptyRootHash)
+		state, _ = New(types.EmptyRootHash, db, snaps)
+		addrA    = common.HexToAddress("0xaa")
+		addrB    = common.HexToAddress("0xbb")
+		missing  = common.HexToAddress("0xcc")
+		slot     = common.HexToHash("0x1")
+		value    = common.HexToHash("0x2a")
+	)
+	state.SetBalance(addrA, uint256.NewInt(1))
+	state.SetState(addrA, slot, value)
+	state.SetNonce(addrB, 3)
+	root, _ := state.Commit(0, true)
+
+	list := types.AccessList{
+		{Address: addrA, StorageKeys: []common.Hash{slot, common.HexToHash("0x2")}},
+		{Address: addrB, StorageKeys: []common.Hash{slot}},
+		{Address: missing},
+	}
+	for _, snaps := range []*snapshot.Tree{snaps, nil} {
+		state, _ := New(root, db, snaps)
+		state.Prepare(true, false, common.Address{}, common.Address{}, nil, nil, list)
+
+		obj := state.stateObjects[addrA]
+		if obj == nil {
+			t.Fatalf("snap %v: account not prefetched", snaps != nil)
+		}
+		if have, ok := obj.originStorage[slot]; !ok || have != value {
+			t.Fatalf("snap %v: slot mismatch: have %x (cached %v), want %x", snaps != nil, have, ok, value)
+		}
+		if have, ok := obj.originStorage[common.HexToHash("0x2")]; !ok || have != (common.Hash{}) {
+			t.Fatalf("snap %v: empty slot mismatch: have %x (cached %v)", snaps != nil, have, ok)
+		}
+		if obj := state.stateObjects[addrB]; obj == nil || obj.Nonce() != 3 {
+			t.Fatalf("snap %v: account without storage not prefetched", snaps != nil)
+		}
+		if _, ok := state.stateObjects[missing]; ok {
+			t.Fatalf("snap %v: non-existent account inserted", snaps != nil)
+		}
+		if have := state.GetState(addrA, slot); have != value {
+			t.Fatalf("snap %v: state mismatch: have %x, want %x", snaps != nil, have, value)
+		}
+		if err := state.Error(); err != nil {
+			t.Fatalf("snap %v: unexpected error: %v", snaps != nil, err)
+		}
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
 	"github.com/ethereum/go-ethereum/params/vars"
 	"github.com/holiman/uint256"
 )
@@ -446,8 +447,8 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	// However, this was not enforced in the incumbent core-geth version. It may be a red herring.
 	//
 	// Check whether the init code size has been exceeded.
-	if eip3860f && contractCreation && uint64(len(msg.Data)) > vars.MaxInitCodeSize {
-		return nil, fmt.Errorf("%w: code size %v limit %v", ErrMaxInitCodeSizeExceeded, len(msg.Data), vars.MaxInitCodeSize)
+	if limit := ctypes.MaxInitCodeSize(st.evm.ChainConfig()); eip3860f && contractCreation && uint64(len(msg.Data)) > limit {
+		return nil, fmt.Errorf("%w: code size %v limit %v", ErrMaxInitCodeSizeExceeded, len(msg.Data), limit)
 	}
 
 	// Execute the preparatory steps for state transition which includes:
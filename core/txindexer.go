@@ -19,8 +19,11 @@ package core
 import (
 	"errors"
 	"fmt"
+	"math/big"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 )
@@ -36,6 +39,70 @@ func (progress TxIndexProgress) Done() bool {
 	return progress.Remaining == 0
 }
 
+// txLookupFilter restricts the transaction lookup index to the transactions sent
+// from or to a set of addresses.
+type txLookupFilter struct {
+	addresses map[common.Address]struct{}
+	signer    types.Signer // Signer used when the block specific one is unknown
+}
+
+// newTxLookupFilter creates a transaction lookup index filter for the given
+// addresses, or nil if no addresses are given, meaning all transactions should
+// be indexed.
+func newTxLookupFilter(chainID *big.Int, addresses []common.Address) *txLookupFilter {
+	if len(addresses) == 0 {
+		return nil
+	}
+	filter := &txLookupFilter{
+		addresses: make(map[common.Address]struct{}, len(addresses)),
+		signer:    types.LatestSignerForChainID(chainID),
+	}
+	for _, addr := range addresses {
+		filter.addresses[addr] = struct{}{}
+	}
+	return filter
+}
+
+// include reports whether the transaction involves any of the filtered addresses.
+// Transactions whose sender cannot be recovered are included, to err on the side
+// of having a lookup entry too many rather than missing one.
+func (f *txLookupFilter) include(signer types.Signer, tx *types.Transaction) bool {
+	if to := tx.To(); to != nil {
+		if _, ok := f.addresses[*to]; ok {
+			return true
+		}
+	}
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return true
+	}
+	_, ok := f.addresses[from]
+	return ok
+}
+
+// txFilter returns the filter in the form used by the database indexer, or nil
+// if all transactions should be indexed.
+func (f *txLookupFilter) txFilter() rawdb.TxFilter {
+	if f == nil {
+		return nil
+	}
+	return func(tx *types.Transaction) bool {
+		return f.include(f.signer, tx)
+	}
+}
+
+// lookupHashes returns the hashes of the transactions of the block which should
+// be included in the transaction lookup index.
+func (f *txLookupFilter) lookupHashes(signer types.Signer, block *types.Block) []common.Hash {
+	var hashes []common.Hash
+	for _, tx := range block.Transactions() {
+		if f.include(signer, tx) {
+			hashes = append(hashes, tx.Hash())
+		}
+	}
+	return hashes
+}
+
 // txIndexer is the module responsible for maintaining transaction indexes
 // according to the configured indexing range by users.
 type txIndexer struct {
@@ -45,6 +112,7 @@ type txIndexer struct {
 	//  * N: means the latest N blocks [HEAD-N+1, HEAD] should be indexed
 	//       and all others shouldn't.
 	limit    uint64
+	filter   rawdb.TxFilter // Filter of the transactions to index, nil means all
 	db       ethdb.Database
 	progress chan chan TxIndexProgress
 	term     chan chan struct{}
@@ -55,6 +123,7 @@ type txIndexer struct {
 func newTxIndexer(limit uint64, chain *BlockChain) *txIndexer {
 	indexer := &txIndexer{
 		limit:    limit,
+		filter:   chain.txFilter.txFilter(),
 		db:       chain.db,
 		progress: make(chan chan TxIndexProgress),
 		term:     make(chan chan struct{}),
@@ -68,7 +137,11 @@ func newTxIndexer(limit uint64, chain *BlockChain) *txIndexer {
 	} else {
 		msg = fmt.Sprintf("last %d blocks", limit)
 	}
-	log.Info("Initialized transaction indexer", "range", msg)
+	if chain.txFilter != nil {
+		log.Info("Initialized transaction indexer", "range", msg, "addresses", len(chain.txFilter.addresses))
+	} else {
+		log.Info("Initialized transaction indexer", "range", msg)
+	}
 
 	return indexer
 }
@@ -91,7 +164,7 @@ func (indexer *txIndexer) run(tail *uint64, head uint64, stop chan struct{}, don
 		if indexer.limit != 0 && head >= indexer.limit {
 			from = head - indexer.limit + 1
 		}
-		rawdb.IndexTransactions(indexer.db, from, head+1, stop, true)
+		rawdb.IndexFilteredTransactions(indexer.db, from, head+1, stop, indexer.filter, true)
 		return
 	}
 	// The tail flag is existent (which means indexes in [tail, head] should be
@@ -105,7 +178,7 @@ func (indexer *txIndexer) run(tail *uint64, head uint64, stop chan struct{}, don
 			if end > head+1 {
 				end = head + 1
 			}
-			rawdb.IndexTransactions(indexer.db, 0, end, stop, true)
+			rawdb.IndexFilteredTransactions(indexer.db, 0, end, stop, indexer.filter, true)
 		}
 		return
 	}
@@ -113,7 +186,7 @@ func (indexer *txIndexer) run(tail *uint64, head uint64, stop chan struct{}, don
 	// limit and the latest chain head.
 	if head-indexer.limit+1 < *tail {
 		// Reindex a part of missing indices and rewind index tail to HEAD-limit
-		rawdb.IndexTransactions(indexer.db, head-indexer.limit+1, *tail, stop, true)
+		rawdb.IndexFilteredTransactions(indexer.db, head-indexer.limit+1, *tail, stop, indexer.filter, true)
 	} else {
 		// Unindex a part of stale indices and forward index tail to HEAD-limit
 		rawdb.UnindexTransactions(indexer.db, *tail, head-indexer.limit+1, stop, false)
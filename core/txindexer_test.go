@@ -244,3 +244,39 @@ func TestTxIndexer(t *testing.T) {
 		os.RemoveAll(frdir)
 	}
 }
+
+// TestTxLookupFilter tests that the transaction lookup filter only accepts the
+// transactions sent from or to the configured addresses.
+func TestTxLookupFilter(t *testing.T) {
+	var (
+		chainID     = big.NewInt(1)
+		signer      = types.LatestSignerForChainID(chainID)
+		watchKey, _ = crypto.GenerateKey()
+		otherKey, _ = crypto.GenerateKey()
+		watched     = crypto.PubkeyToAddress(watchKey.PublicKey)
+		other       = common.HexToAddress("0xdead")
+		filter      = newTxLookupFilter(chainID, []common.Address{watched})
+		fromWatch   = types.MustSignNewTx(watchKey, signer, &types.LegacyTx{To: &other, Gas: 21000, GasPrice: big.NewInt(1)})
+		toWatch     = types.MustSignNewTx(otherKey, signer, &types.LegacyTx{To: &watched, Gas: 21000, GasPrice: big.NewInt(1)})
+		unrelated   = types.MustSignNewTx(otherKey, signer, &types.LegacyTx{Nonce: 1, To: &other, Gas: 21000, GasPrice: big.NewInt(1)})
+		creation    = types.MustSignNewTx(watchKey, signer, &types.LegacyTx{Nonce: 1, Gas: 53000, GasPrice: big.NewInt(1)})
+		unfiltered  = newTxLookupFilter(chainID, nil)
+	)
+	if unfiltered != nil || unfiltered.txFilter() != nil {
+		t.Fatal("empty address set should disable filtering")
+	}
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)}).WithBody(types.Transactions{fromWatch, toWatch, unrelated, creation}, nil)
+	have := filter.lookupHashes(signer, block)
+	want := []common.Hash{fromWatch.Hash(), toWatch.Hash(), creation.Hash()}
+	if len(have) != len(want) {
+		t.Fatalf("indexed transaction count mismatch: have %d, want %d", len(have), len(want))
+	}
+	for i := range want {
+		if have[i] != want[i] {
+			t.Fatalf("indexed transaction %d mismatch: have %x, want %x", i, have[i], want[i])
+		}
+	}
+	if f := filter.txFilter(); f(unrelated) || !f(toWatch) {
+		t.Fatal("database filter mismatch")
+	}
+}
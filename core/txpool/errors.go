@@ -51,6 +51,10 @@ var (
 	// making the transaction invalid, rather a DOS protection.
 	ErrOversizedData = errors.New("oversized data")
 
+	// ErrUnprotected is returned if a transaction without EIP-155 replay
+	// protection is added to a pool configured to reject those.
+	ErrUnprotected = errors.New("unprotected transaction")
+
 	// ErrFutureReplacePending is returned if a future transaction replaces a pending
 	// one. Future transactions should only be able to replace other future transactions.
 	ErrFutureReplacePending = errors.New("future transaction tries to replace pending")
@@ -0,0 +1,111 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package legacypool
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// dropLogSize is the number of dropped transactions remembered by the pool.
+const dropLogSize = 4096
+
+// Reasons for which a transaction may be dropped from the pool.
+const (
+	dropLifetime    = "lifetime"    // Queued for longer than the configured lifetime
+	dropUnderpriced = "underpriced" // Evicted by a better paying transaction on a full pool
+	dropReplaced    = "replaced"    // Replaced by a same-nonce transaction with a higher price
+	dropUnpayable   = "unpayable"   // Sender can no longer cover the cost or gas exceeds the block limit
+	dropCapacity    = "capacity"    // Evicted to keep the pool within its slot limits
+	dropMinTip      = "mintip"      // Below the minimum tip after a threshold increase
+)
+
+// dropMeters tracks the number of dropped transactions per reason.
+var dropMeters = map[string]metrics.Meter{
+	dropLifetime:    metrics.NewRegisteredMeter("txpool/dropped/"+dropLifetime, nil),
+	dropUnderpriced: metrics.NewRegisteredMeter("txpool/dropped/"+dropUnderpriced, nil),
+	dropReplaced:    metrics.NewRegisteredMeter("txpool/dropped/"+dropReplaced, nil),
+	dropUnpayable:   metrics.NewRegisteredMeter("txpool/dropped/"+dropUnpayable, nil),
+	dropCapacity:    metrics.NewRegisteredMeter("txpool/dropped/"+dropCapacity, nil),
+	dropMinTip:      metrics.NewRegisteredMeter("txpool/dropped/"+dropMinTip, nil),
+}
+
+// dropLog is a fixed size ring of recently dropped transactions.
+type dropLog struct {
+	entries []*txpool.DroppedTransaction // Ring buffer of drop records
+	next    int                          // Position of the next record to write
+	lock    sync.RWMutex
+}
+
+// newDropLog creates a drop log remembering up to size transactions.
+func newDropLog(size int) *dropLog {
+	return &dropLog{
+		entries: make([]*txpool.DroppedTransaction, 0, size),
+	}
+}
+
+// add inserts a new drop record, evicting the oldest one if the log is full.
+func (l *dropLog) add(entry *txpool.DroppedTransaction) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if len(l.entries) < cap(l.entries) {
+		l.entries = append(l.entries, entry)
+	} else {
+		l.entries[l.next] = entry
+	}
+	l.next = (l.next + 1) % cap(l.entries)
+}
+
+// since returns the drop records made at or after the given time, oldest first.
+func (l *dropLog) since(since time.Time) []*txpool.DroppedTransaction {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	var res []*txpool.DroppedTransaction
+	for i := 0; i < len(l.entries); i++ {
+		// Once the ring wrapped, the oldest record sits at the write position
+		entry := l.entries[(l.next+i)%len(l.entries)]
+		if !entry.Time.Before(since) {
+			res = append(res, entry)
+		}
+	}
+	return res
+}
+
+// recordDrop notes the removal of a transaction from the pool for later lookup.
+func (pool *LegacyPool) recordDrop(tx *types.Transaction, reason string) {
+	from, _ := types.Sender(pool.signer, tx) // already validated
+	pool.drops.add(&txpool.DroppedTransaction{
+		Hash:   tx.Hash(),
+		From:   from,
+		Nonce:  tx.Nonce(),
+		Reason: reason,
+		Time:   time.Now(),
+	})
+	dropMeters[reason].Mark(1)
+}
+
+// DroppedSince returns the transactions dropped from the pool without being
+// included in a block at or after the given time, oldest first.
+func (pool *LegacyPool) DroppedSince(since time.Time) []*txpool.DroppedTransaction {
+	return pool.drops.since(since)
+}
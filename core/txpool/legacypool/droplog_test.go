@@ -0,0 +1,84 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package legacypool
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Tests that the drop log evicts the oldest records once full and filters the
+// remaining ones by drop time, oldest first.
+func TestDropLogRing(t *testing.T) {
+	var (
+		log  = newDropLog(3)
+		base = time.Unix(1700000000, 0)
+	)
+	for i := 0; i < 5; i++ {
+		log.add(&txpool.DroppedTransaction{Nonce: uint64(i), Time: base.Add(time.Duration(i) * time.Second)})
+	}
+	all := log.since(time.Time{})
+	if len(all) != 3 {
+		t.Fatalf("record count mismatch: have %d, want 3", len(all))
+	}
+	for i, entry := range all {
+		if want := uint64(2 + i); entry.Nonce != want {
+			t.Errorf("record %d: nonce mismatch: have %d, want %d", i, entry.Nonce, want)
+		}
+	}
+	if recent := log.since(base.Add(3 * time.Second)); len(recent) != 2 || recent[0].Nonce != 3 || recent[1].Nonce != 4 {
+		t.Fatalf("filtered records mismatch: %v", recent)
+	}
+	if recent := log.since(base.Add(time.Minute)); len(recent) != 0 {
+		t.Fatalf("future records returned: %v", recent)
+	}
+}
+
+// Tests that replaced transactions are recorded in the drop log.
+func TestDroppedReplacement(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	cheap := pricedTransaction(0, 100000, big.NewInt(1), key)
+	pricey := pricedTransaction(0, 100000, big.NewInt(2), key)
+
+	start := time.Now()
+	if err := pool.addRemoteSync(cheap); err != nil {
+		t.Fatalf("failed to add original transaction: %v", err)
+	}
+	if err := pool.addRemoteSync(pricey); err != nil {
+		t.Fatalf("failed to add replacement transaction: %v", err)
+	}
+	dropped := pool.DroppedSince(start)
+	if len(dropped) != 1 || dropped[0].Hash != cheap.Hash() {
+		t.Fatalf("dropped transactions mismatch: have %v, want %x", dropped, cheap.Hash())
+	}
+	if dropped[0].Reason != dropReplaced {
+		t.Errorf("drop reason mismatch: have %s, want %s", dropped[0].Reason, dropReplaced)
+	}
+	if dropped := pool.DroppedSince(time.Now().Add(time.Minute)); len(dropped) != 0 {
+		t.Errorf("drops reported after the requested time: %v", dropped)
+	}
+}
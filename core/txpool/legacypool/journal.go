@@ -45,13 +45,16 @@ func (*devNull) Close() error                      { return nil }
 // created transactions to allow non-executed ones to survive node restarts.
 type journal struct {
 	path   string         // Filesystem path to store the transactions at
+	name   string         // Kind of transactions journaled, used in logs
 	writer io.WriteCloser // Output stream to write new transactions into
 }
 
-// newTxJournal creates a new transaction journal to
-func newTxJournal(path string) *journal {
+// newTxJournal creates a new transaction journal to store the given kind of
+// transactions at the given path.
+func newTxJournal(path string, name string) *journal {
 	return &journal{
 		path: path,
+		name: name,
 	}
 }
 
@@ -112,7 +115,7 @@ func (journal *journal) load(add func([]*types.Transaction) []error) error {
 			batch = batch[:0]
 		}
 	}
-	log.Info("Loaded local transaction journal", "transactions", total, "dropped", dropped)
+	log.Info("Loaded "+journal.name+" transaction journal", "transactions", total, "dropped", dropped)
 
 	return failure
 }
@@ -169,7 +172,7 @@ func (journal *journal) rotate(all map[common.Address]types.Transactions) error
 	if len(all) == 0 {
 		logger = log.Debug
 	}
-	logger("Regenerated local transaction journal", "transactions", journaled, "accounts", len(all))
+	logger("Regenerated "+journal.name+" transaction journal", "transactions", journaled, "accounts", len(all))
 
 	return nil
 }
@@ -34,6 +34,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/internal/watchdog"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params/types/ctypes"
@@ -85,6 +86,7 @@ var (
 	invalidTxMeter     = metrics.NewRegisteredMeter("txpool/invalid", nil)
 	underpricedTxMeter = metrics.NewRegisteredMeter("txpool/underpriced", nil)
 	overflowedTxMeter  = metrics.NewRegisteredMeter("txpool/overflowed", nil)
+	unprotectedTxMeter = metrics.NewRegisteredMeter("txpool/unprotected", nil) // Received without replay protection
 
 	// throttleTxMeter counts how many transactions are rejected due to too-many-changes between
 	// txpool reorgs.
@@ -126,6 +128,9 @@ type Config struct {
 	Journal   string           // Journal of local transactions to survive node restarts
 	Rejournal time.Duration    // Time interval to regenerate the local transaction journal
 
+	Persist     bool   // Whether to persist all pending and queued transactions across restarts
+	PersistFile string // File the pool contents are saved into on shutdown and reloaded from at startup
+
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
 	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
 
@@ -135,6 +140,19 @@ type Config struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	QueuePolicies []AccountQueuePolicy `toml:",omitempty"` // Per-account overrides of the queue limits
+
+	RejectUnprotected bool `toml:",omitempty"` // Whether to reject transactions without EIP-155 replay protection
+}
+
+// AccountQueuePolicy overrides the limits of the non-executable (future nonce)
+// queue for a single account, e.g. to give known exchange hot wallets more room
+// than an arbitrary sender.
+type AccountQueuePolicy struct {
+	Address  common.Address // Account the policy applies to
+	Slots    uint64         // Maximum number of queued transactions (0 = AccountQueue)
+	Lifetime time.Duration  // Maximum time transactions may be queued (0 = Lifetime)
 }
 
 // DefaultConfig contains the default configurations for the transaction pool.
@@ -142,6 +160,8 @@ var DefaultConfig = Config{
 	Journal:   "transactions.rlp",
 	Rejournal: time.Hour,
 
+	PersistFile: "txpool.rlp",
+
 	PriceLimit: 1,
 	PriceBump:  10,
 
@@ -161,6 +181,10 @@ func (config *Config) sanitize() Config {
 		log.Warn("Sanitizing invalid txpool journal time", "provided", conf.Rejournal, "updated", time.Second)
 		conf.Rejournal = time.Second
 	}
+	if conf.Persist && conf.PersistFile == "" {
+		log.Warn("Sanitizing invalid txpool persist file", "provided", conf.PersistFile, "updated", DefaultConfig.PersistFile)
+		conf.PersistFile = DefaultConfig.PersistFile
+	}
 	if conf.PriceLimit < 1 {
 		log.Warn("Sanitizing invalid txpool price limit", "provided", conf.PriceLimit, "updated", DefaultConfig.PriceLimit)
 		conf.PriceLimit = DefaultConfig.PriceLimit
@@ -189,6 +213,13 @@ func (config *Config) sanitize() Config {
 		log.Warn("Sanitizing invalid txpool lifetime", "provided", conf.Lifetime, "updated", DefaultConfig.Lifetime)
 		conf.Lifetime = DefaultConfig.Lifetime
 	}
+	conf.QueuePolicies = append([]AccountQueuePolicy(nil), conf.QueuePolicies...)
+	for i, policy := range conf.QueuePolicies {
+		if policy.Slots > conf.GlobalQueue {
+			log.Warn("Sanitizing invalid txpool account queue policy", "address", policy.Address, "provided", policy.Slots, "updated", conf.GlobalQueue)
+			conf.QueuePolicies[i].Slots = conf.GlobalQueue
+		}
+	}
 	return conf
 }
 
@@ -206,14 +237,17 @@ type LegacyPool struct {
 	gasTip      atomic.Pointer[uint256.Int]
 	txFeed      event.Feed
 	signer      types.Signer
-	mu          sync.RWMutex
+	mu          watchdog.RWMutex
 
 	currentHead   atomic.Pointer[types.Header] // Current head of the blockchain
 	currentState  *state.StateDB               // Current state in the blockchain head
 	pendingNonces *noncer                      // Pending state tracking virtual nonces
 
-	locals  *accountSet // Set of local transaction to exempt from eviction rules
-	journal *journal    // Journal of local transaction to back up to disk
+	locals        *accountSet                           // Set of local transaction to exempt from eviction rules
+	queuePolicies map[common.Address]AccountQueuePolicy // Per-account overrides of the queue limits
+	journal       *journal                              // Journal of local transaction to back up to disk
+	persist       *journal                              // Journal of all pooled transactions saved on shutdown
+	drops         *dropLog                              // Lookback record of recently dropped transactions
 
 	reserve txpool.AddressReserver       // Address reserver to ensure exclusivity across subpools
 	pending map[common.Address]*list     // All currently processable transactions
@@ -245,6 +279,7 @@ func New(config Config, chain BlockChain) *LegacyPool {
 
 	// Create the transaction pool with its initial settings
 	pool := &LegacyPool{
+		mu:              watchdog.RWMutex{Name: "txpool"},
 		config:          config,
 		chain:           chain,
 		chainconfig:     chain.Config(),
@@ -253,6 +288,7 @@ func New(config Config, chain BlockChain) *LegacyPool {
 		queue:           make(map[common.Address]*list),
 		beats:           make(map[common.Address]time.Time),
 		all:             newLookup(),
+		drops:           newDropLog(dropLogSize),
 		reqResetCh:      make(chan *txpoolResetRequest),
 		reqPromoteCh:    make(chan *accountSet),
 		queueTxEventCh:  make(chan *types.Transaction),
@@ -261,6 +297,11 @@ func New(config Config, chain BlockChain) *LegacyPool {
 		initDoneCh:      make(chan struct{}),
 	}
 	pool.locals = newAccountSet(pool.signer)
+	pool.queuePolicies = make(map[common.Address]AccountQueuePolicy)
+	for _, policy := range config.QueuePolicies {
+		log.Info("Setting account queue policy", "address", policy.Address, "slots", policy.Slots, "lifetime", policy.Lifetime)
+		pool.queuePolicies[policy.Address] = policy
+	}
 	for _, addr := range config.Locals {
 		log.Info("Setting new local account", "address", addr)
 		pool.locals.add(addr)
@@ -268,7 +309,10 @@ func New(config Config, chain BlockChain) *LegacyPool {
 	pool.priced = newPricedList(pool.all)
 
 	if !config.NoLocals && config.Journal != "" {
-		pool.journal = newTxJournal(config.Journal)
+		pool.journal = newTxJournal(config.Journal, "local")
+	}
+	if config.Persist {
+		pool.persist = newTxJournal(config.PersistFile, "persisted")
 	}
 	return pool
 }
@@ -323,6 +367,14 @@ func (pool *LegacyPool) Init(gasTip uint64, head *types.Header, reserve txpool.A
 			log.Warn("Failed to rotate transaction journal", "err", err)
 		}
 	}
+	// If persistence is enabled, reload the pool contents saved on shutdown.
+	// They are validated anew, so the ones included or invalidated meanwhile
+	// are dropped.
+	if pool.persist != nil {
+		if err := pool.persist.load(pool.addRemotes); err != nil {
+			log.Warn("Failed to load persisted transactions", "err", err)
+		}
+	}
 	pool.wg.Add(1)
 	go pool.loop()
 	return nil
@@ -375,9 +427,10 @@ func (pool *LegacyPool) loop() {
 					continue
 				}
 				// Any non-locals old enough should be removed
-				if time.Since(pool.beats[addr]) > pool.config.Lifetime {
+				if time.Since(pool.beats[addr]) > pool.queueLifetime(addr) {
 					list := pool.queue[addr].Flatten()
 					for _, tx := range list {
+						pool.recordDrop(tx, dropLifetime)
 						pool.removeTx(tx.Hash(), true, true)
 					}
 					queuedEvictionMeter.Mark(int64(len(list)))
@@ -407,6 +460,14 @@ func (pool *LegacyPool) Close() error {
 	if pool.journal != nil {
 		pool.journal.close()
 	}
+	if pool.persist != nil {
+		pool.mu.Lock()
+		if err := pool.persist.rotate(pool.persisted()); err != nil {
+			log.Warn("Failed to persist transactions", "err", err)
+		}
+		pool.mu.Unlock()
+		pool.persist.close()
+	}
 	log.Info("Transaction pool stopped")
 	return nil
 }
@@ -444,6 +505,7 @@ func (pool *LegacyPool) SetGasTip(tip *big.Int) {
 		// pool.priced is sorted by GasFeeCap, so we have to iterate through pool.all instead
 		drop := pool.all.RemotesBelowTip(tip)
 		for _, tx := range drop {
+			pool.recordDrop(tx, dropMinTip)
 			pool.removeTx(tx.Hash(), false, true)
 		}
 		pool.priced.Removed(len(drop))
@@ -599,6 +661,42 @@ func (pool *LegacyPool) local() map[common.Address]types.Transactions {
 	return txs
 }
 
+// persisted retrieves all pending and queued transactions to be saved across
+// restarts, grouped by origin account and sorted by nonce. Transactions of local
+// accounts are left out if they are journaled already.
+func (pool *LegacyPool) persisted() map[common.Address]types.Transactions {
+	txs := make(map[common.Address]types.Transactions)
+	for addr, list := range pool.pending {
+		if pool.journal == nil || !pool.locals.contains(addr) {
+			txs[addr] = append(txs[addr], list.Flatten()...)
+		}
+	}
+	for addr, list := range pool.queue {
+		if pool.journal == nil || !pool.locals.contains(addr) {
+			txs[addr] = append(txs[addr], list.Flatten()...)
+		}
+	}
+	return txs
+}
+
+// queueSlots returns the maximum number of non-executable transactions allowed
+// to be queued for the given account.
+func (pool *LegacyPool) queueSlots(addr common.Address) uint64 {
+	if policy, ok := pool.queuePolicies[addr]; ok && policy.Slots > 0 {
+		return policy.Slots
+	}
+	return pool.config.AccountQueue
+}
+
+// queueLifetime returns the maximum amount of time the non-executable transactions
+// of the given account may stay queued without a heartbeat.
+func (pool *LegacyPool) queueLifetime(addr common.Address) time.Duration {
+	if policy, ok := pool.queuePolicies[addr]; ok && policy.Lifetime > 0 {
+		return policy.Lifetime
+	}
+	return pool.config.Lifetime
+}
+
 // validateTxBasics checks whether a transaction is valid according to the consensus
 // rules, but does not check state-dependent validation such as sufficient balance.
 // This check is meant as an early check which only needs to be performed once,
@@ -612,10 +710,16 @@ func (pool *LegacyPool) validateTxBasics(tx *types.Transaction, local bool) erro
 			1<<types.DynamicFeeTxType,
 		MaxSize: txMaxSize,
 		MinTip:  pool.gasTip.Load().ToBig(),
+
+		RejectUnprotected: pool.config.RejectUnprotected,
 	}
 	if local {
 		opts.MinTip = new(big.Int)
 	}
+	if !tx.Protected() {
+		unprotectedTxMeter.Mark(1)
+		log.Debug("Received unprotected transaction", "hash", tx.Hash(), "local", local, "rejected", pool.config.RejectUnprotected)
+	}
 	if err := txpool.ValidateTransaction(tx, pool.currentHead.Load(), pool.signer, opts); err != nil {
 		return err
 	}
@@ -765,6 +869,7 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 			log.Trace("Discarding freshly underpriced transaction", "hash", tx.Hash(), "gasTipCap", tx.GasTipCap(), "gasFeeCap", tx.GasFeeCap())
 			underpricedTxMeter.Mark(1)
 
+			pool.recordDrop(tx, dropUnderpriced)
 			sender, _ := types.Sender(pool.signer, tx)
 			dropped := pool.removeTx(tx.Hash(), false, sender != from) // Don't unreserve the sender of the tx being added if last from the acc
 
@@ -782,6 +887,7 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 		}
 		// New transaction is better, replace old one
 		if old != nil {
+			pool.recordDrop(old, dropReplaced)
 			pool.all.Remove(old.Hash())
 			pool.priced.Removed(1)
 			pendingReplaceMeter.Mark(1)
@@ -857,6 +963,7 @@ func (pool *LegacyPool) enqueueTx(hash common.Hash, tx *types.Transaction, local
 	}
 	// Discard any previous transaction and mark this
 	if old != nil {
+		pool.recordDrop(old, dropReplaced)
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
 		queuedReplaceMeter.Mark(1)
@@ -906,6 +1013,7 @@ func (pool *LegacyPool) promoteTx(addr common.Address, hash common.Hash, tx *typ
 	inserted, old := list.Add(tx, pool.config.PriceBump)
 	if !inserted {
 		// An older transaction was better, discard this
+		pool.recordDrop(tx, dropReplaced)
 		pool.all.Remove(hash)
 		pool.priced.Removed(1)
 		pendingDiscardMeter.Mark(1)
@@ -913,6 +1021,7 @@ func (pool *LegacyPool) promoteTx(addr common.Address, hash common.Hash, tx *typ
 	}
 	// Otherwise discard any previous transaction and mark this
 	if old != nil {
+		pool.recordDrop(old, dropReplaced)
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
 		pendingReplaceMeter.Mark(1)
@@ -1462,6 +1571,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 		drops, _ := list.Filter(pool.currentState.GetBalance(addr), gasLimit)
 		for _, tx := range drops {
 			hash := tx.Hash()
+			pool.recordDrop(tx, dropUnpayable)
 			pool.all.Remove(hash)
 		}
 		log.Trace("Removed unpayable queued transactions", "count", len(drops))
@@ -1481,9 +1591,10 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 		// Drop all transactions over the allowed limit
 		var caps types.Transactions
 		if !pool.locals.contains(addr) {
-			caps = list.Cap(int(pool.config.AccountQueue))
+			caps = list.Cap(int(pool.queueSlots(addr)))
 			for _, tx := range caps {
 				hash := tx.Hash()
+				pool.recordDrop(tx, dropCapacity)
 				pool.all.Remove(hash)
 				log.Trace("Removed cap-exceeding queued transaction", "hash", hash)
 			}
@@ -1549,6 +1660,7 @@ func (pool *LegacyPool) truncatePending() {
 					for _, tx := range caps {
 						// Drop the transaction from the global pools too
 						hash := tx.Hash()
+						pool.recordDrop(tx, dropCapacity)
 						pool.all.Remove(hash)
 
 						// Update the account nonce to the dropped transaction
@@ -1576,6 +1688,7 @@ func (pool *LegacyPool) truncatePending() {
 				for _, tx := range caps {
 					// Drop the transaction from the global pools too
 					hash := tx.Hash()
+					pool.recordDrop(tx, dropCapacity)
 					pool.all.Remove(hash)
 
 					// Update the account nonce to the dropped transaction
@@ -1623,6 +1736,7 @@ func (pool *LegacyPool) truncateQueue() {
 		// Drop all transactions if they are less than the overflow
 		if size := uint64(list.Len()); size <= drop {
 			for _, tx := range list.Flatten() {
+				pool.recordDrop(tx, dropCapacity)
 				pool.removeTx(tx.Hash(), true, true)
 			}
 			drop -= size
@@ -1632,6 +1746,7 @@ func (pool *LegacyPool) truncateQueue() {
 		// Otherwise drop only last few transactions
 		txs := list.Flatten()
 		for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
+			pool.recordDrop(txs[i], dropCapacity)
 			pool.removeTx(txs[i].Hash(), true, true)
 			drop--
 			queuedRateLimitMeter.Mark(1)
@@ -1664,6 +1779,7 @@ func (pool *LegacyPool) demoteUnexecutables() {
 		for _, tx := range drops {
 			hash := tx.Hash()
 			log.Trace("Removed unpayable pending transaction", "hash", hash)
+			pool.recordDrop(tx, dropUnpayable)
 			pool.all.Remove(hash)
 		}
 		pendingNofundsMeter.Mark(int64(len(drops)))
@@ -24,6 +24,7 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -358,6 +359,33 @@ func TestInvalidTransactions(t *testing.T) {
 	}
 }
 
+// Tests that transactions without replay protection are only rejected if the
+// pool is configured to do so.
+func TestRejectUnprotected(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	unprotected := transaction(0, 100000, key)
+	from, _ := deriveSender(unprotected)
+	testAddBalance(pool, from, big.NewInt(0xffffffffffffff))
+
+	pool.config.RejectUnprotected = true
+	if err, want := pool.addRemote(unprotected), txpool.ErrUnprotected; !errors.Is(err, want) {
+		t.Errorf("want %v have %v", want, err)
+	}
+	signer := types.NewEIP155Signer(pool.chainconfig.GetChainID())
+	protected, _ := types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(100), 100000, big.NewInt(1), nil), signer, key)
+	if err := pool.addRemote(protected); err != nil {
+		t.Errorf("protected transaction rejected: %v", err)
+	}
+	pool.config.RejectUnprotected = false
+	if err := pool.addRemote(transaction(1, 100000, key)); err != nil {
+		t.Errorf("unprotected transaction rejected: %v", err)
+	}
+}
+
 func TestQueue(t *testing.T) {
 	t.Parallel()
 
@@ -896,6 +924,34 @@ func TestQueueAccountLimiting(t *testing.T) {
 	}
 }
 
+// Tests that per-account queue policies override the default queue limit.
+func TestQueueAccountPolicy(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, account, big.NewInt(1000000))
+
+	slots := 2 * testTxPoolConfig.AccountQueue
+	pool.mu.Lock()
+	pool.queuePolicies[account] = AccountQueuePolicy{Address: account, Slots: slots}
+	pool.mu.Unlock()
+
+	for i := uint64(1); i <= slots+5; i++ {
+		if err := pool.addRemoteSync(transaction(i, 100000, key)); err != nil {
+			t.Fatalf("tx %d: failed to add transaction: %v", i, err)
+		}
+	}
+	if pool.queue[account].Len() != int(slots) {
+		t.Errorf("queue limit mismatch: have %d, want %d", pool.queue[account].Len(), slots)
+	}
+	if lifetime := pool.queueLifetime(account); lifetime != testTxPoolConfig.Lifetime {
+		t.Errorf("queue lifetime mismatch: have %v, want %v", lifetime, testTxPoolConfig.Lifetime)
+	}
+}
+
 // Tests that if the transaction count belonging to multiple accounts go above
 // some threshold, the higher transactions are dropped to prevent DOS attacks.
 //
@@ -2460,6 +2516,52 @@ func testJournaling(t *testing.T, nolocals bool) {
 	pool.Close()
 }
 
+// Tests that all pending and queued transactions are persisted across restarts
+// if requested, dropping the ones invalidated meanwhile.
+func TestPersist(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+	config := testTxPoolConfig
+	config.Journal = ""
+	config.Persist = true
+	config.PersistFile = filepath.Join(t.TempDir(), "txpool.rlp")
+
+	pool := New(config, blockchain)
+	pool.Init(config.PriceLimit, blockchain.CurrentBlock(), makeAddressReserver())
+
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, addr, big.NewInt(1000000000))
+
+	for _, nonce := range []uint64{0, 1, 3} {
+		if err := pool.addRemoteSync(pricedTransaction(nonce, 100000, big.NewInt(1), key)); err != nil {
+			t.Fatalf("failed to add transaction %d: %v", nonce, err)
+		}
+	}
+	if pending, queued := pool.Stats(); pending != 2 || queued != 1 {
+		t.Fatalf("pool contents mismatch: have %d/%d, want 2/1", pending, queued)
+	}
+	// Restart the pool with the first transaction included meanwhile
+	pool.Close()
+	statedb.SetNonce(addr, 1)
+	blockchain = newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+	pool = New(config, blockchain)
+	pool.Init(config.PriceLimit, blockchain.CurrentBlock(), makeAddressReserver())
+	defer pool.Close()
+	<-pool.requestReset(nil, nil)
+
+	if pending, queued := pool.Stats(); pending != 1 || queued != 1 {
+		t.Fatalf("pool contents mismatch after restart: have %d/%d, want 1/1", pending, queued)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // TestStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestStatusCheck(t *testing.T) {
@@ -163,3 +163,21 @@ type SubPool interface {
 	// identified by their hashes.
 	Status(hash common.Hash) TxStatus
 }
+
+// DroppedTransaction is a record of a transaction which was removed from the
+// pool without being included in a block.
+type DroppedTransaction struct {
+	Hash   common.Hash    `json:"hash"`
+	From   common.Address `json:"from"`
+	Nonce  uint64         `json:"nonce"`
+	Reason string         `json:"reason"`
+	Time   time.Time      `json:"time"`
+}
+
+// DropTracker is an optional interface implemented by subpools that keep a
+// lookback record of the transactions they recently dropped.
+type DropTracker interface {
+	// DroppedSince returns the drop records made at or after the given time,
+	// oldest first.
+	DroppedSince(since time.Time) []*DroppedTransaction
+}
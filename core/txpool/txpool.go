@@ -17,10 +17,14 @@
 package txpool
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
+	"runtime/pprof"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
@@ -92,15 +96,23 @@ func New(gasTip uint64, chain BlockChain, subpools []SubPool) (*TxPool, error) {
 		term:         make(chan struct{}),
 		sync:         make(chan chan error),
 	}
-	for i, subpool := range subpools {
-		if err := subpool.Init(gasTip, head, pool.reserver(i, subpool)); err != nil {
-			for j := i - 1; j >= 0; j-- {
-				subpools[j].Close()
+	// Start the subpools and the pool loop with profiler labels, so that all
+	// goroutines of the pool are attributed to it in profiles.
+	var err error
+	pprof.Do(context.Background(), pprof.Labels("subsystem", "txpool"), func(context.Context) {
+		for i, subpool := range subpools {
+			if err = subpool.Init(gasTip, head, pool.reserver(i, subpool)); err != nil {
+				for j := i - 1; j >= 0; j-- {
+					subpools[j].Close()
+				}
+				return
 			}
-			return nil, err
 		}
+		go pool.loop(head, chain)
+	})
+	if err != nil {
+		return nil, err
 	}
-	go pool.loop(head, chain)
 	return pool, nil
 }
 
@@ -453,6 +465,21 @@ func (p *TxPool) Locals() []common.Address {
 	return flat
 }
 
+// DroppedSince returns the transactions dropped across all subpools without
+// being included at or after the given time, oldest first.
+func (p *TxPool) DroppedSince(since time.Time) []*DroppedTransaction {
+	var dropped []*DroppedTransaction
+	for _, subpool := range p.subpools {
+		if tracker, ok := subpool.(DropTracker); ok {
+			dropped = append(dropped, tracker.DroppedSince(since)...)
+		}
+	}
+	sort.SliceStable(dropped, func(i, j int) bool {
+		return dropped[i].Time.Before(dropped[j].Time)
+	})
+	return dropped
+}
+
 // Status returns the known status (unknown/pending/queued) of a transaction
 // identified by its hash.
 func (p *TxPool) Status(hash common.Hash) TxStatus {
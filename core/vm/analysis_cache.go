@@ -0,0 +1,192 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	analysisCacheHitMeter  = metrics.NewRegisteredMeter("vm/analysis/hit", nil)
+	analysisCacheMissMeter = metrics.NewRegisteredMeter("vm/analysis/miss", nil)
+)
+
+// analysisJournalVersion is the version of the analysis cache journal format.
+const analysisJournalVersion = 1
+
+// sharedAnalysis is the process wide JUMPDEST analysis cache, nil if disabled.
+var sharedAnalysis atomic.Pointer[AnalysisCache]
+
+// SetAnalysisCache installs the JUMPDEST analysis cache shared by all EVM
+// instances of the process, across transactions and blocks. A nil cache
+// disables sharing, analyses are then only reused within a transaction.
+func SetAnalysisCache(cache *AnalysisCache) {
+	sharedAnalysis.Store(cache)
+}
+
+// AnalysisCache is a size-constrained LRU cache of JUMPDEST analyses, keyed by
+// the hash of the analysed code. It can be journaled to disk, so that the hot
+// contracts don't need to be analysed again after a restart.
+type AnalysisCache struct {
+	lru     lru.BasicLRU[common.Hash, bitvec]
+	size    uint64
+	maxSize uint64
+	lock    sync.Mutex
+}
+
+// NewAnalysisCache creates an analysis cache holding at most maxSize bytes of
+// analysis results.
+func NewAnalysisCache(maxSize uint64) *AnalysisCache {
+	return &AnalysisCache{
+		lru:     lru.NewBasicLRU[common.Hash, bitvec](math.MaxInt),
+		maxSize: maxSize,
+	}
+}
+
+// get retrieves the analysis of the code with the given hash and length. Results
+// not matching the code length are ignored, guarding against corrupt journals.
+func (c *AnalysisCache) get(hash common.Hash, length int) (bitvec, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	bits, ok := c.lru.Get(hash)
+	if !ok || len(bits) != length/8+1+4 {
+		analysisCacheMissMeter.Mark(1)
+		return nil, false
+	}
+	analysisCacheHitMeter.Mark(1)
+	return bits, true
+}
+
+// add inserts an analysis into the cache, evicting the least recently used
+// ones to stay within the size limit. The analysis must not be modified after.
+func (c *AnalysisCache) add(hash common.Hash, bits bitvec) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.lru.Contains(hash) || uint64(len(bits)) > c.maxSize {
+		return
+	}
+	for c.size+uint64(len(bits)) > c.maxSize {
+		_, old, ok := c.lru.RemoveOldest()
+		if !ok {
+			break
+		}
+		c.size -= uint64(len(old))
+	}
+	c.lru.Add(hash, bits)
+	c.size += uint64(len(bits))
+}
+
+// Len returns the number of cached analyses.
+func (c *AnalysisCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.lru.Len()
+}
+
+// Size returns the total size of the cached analyses in bytes.
+func (c *AnalysisCache) Size() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.size
+}
+
+// analysisEntry is a cached analysis in the journal.
+type analysisEntry struct {
+	Hash common.Hash
+	Bits []byte
+}
+
+// analysisJournal is the on-disk format of the analysis cache.
+type analysisJournal struct {
+	Version  uint64
+	Checksum common.Hash // Keccak256 hash of the RLP encoded entries
+	Entries  []analysisEntry
+}
+
+// Save writes the cached analyses to the journal at path, least recently used
+// first. The file is replaced atomically.
+func (c *AnalysisCache) Save(path string) error {
+	c.lock.Lock()
+	entries := make([]analysisEntry, 0, c.lru.Len())
+	for _, hash := range c.lru.Keys() {
+		bits, _ := c.lru.Peek(hash)
+		entries = append(entries, analysisEntry{Hash: hash, Bits: bits})
+	}
+	c.lock.Unlock()
+
+	blob, err := rlp.EncodeToBytes(entries)
+	if err != nil {
+		return err
+	}
+	journal, err := rlp.EncodeToBytes(&analysisJournal{
+		Version:  analysisJournalVersion,
+		Checksum: crypto.Keccak256Hash(blob),
+		Entries:  entries,
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path+".tmp", journal, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// Load adds the analyses of the journal at path to the cache. A missing journal
+// is not an error, a corrupt one is rejected as a whole.
+func (c *AnalysisCache) Load(path string) error {
+	blob, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var journal analysisJournal
+	if err := rlp.DecodeBytes(blob, &journal); err != nil {
+		return fmt.Errorf("invalid analysis journal: %v", err)
+	}
+	if journal.Version != analysisJournalVersion {
+		return fmt.Errorf("unsupported analysis journal version %d", journal.Version)
+	}
+	entries, err := rlp.EncodeToBytes(journal.Entries)
+	if err != nil {
+		return err
+	}
+	if crypto.Keccak256Hash(entries) != journal.Checksum {
+		return errors.New("analysis journal checksum mismatch")
+	}
+	for _, entry := range journal.Entries {
+		c.add(entry.Hash, entry.Bits)
+	}
+	return nil
+}
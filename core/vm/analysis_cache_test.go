@@ -0,0 +1,110 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestAnalysisCacheEviction(t *testing.T) {
+	var (
+		code  = make([]byte, 80) // 15 byte analysis
+		cache = NewAnalysisCache(40)
+	)
+	for i := byte(0); i < 3; i++ {
+		cache.add(common.Hash{i}, codeBitmap(code))
+	}
+	if cache.Len() != 2 || cache.Size() != 30 {
+		t.Fatalf("cache size mismatch: have %d items, %d bytes, want 2 items, 30 bytes", cache.Len(), cache.Size())
+	}
+	if _, ok := cache.get(common.Hash{0}, len(code)); ok {
+		t.Errorf("oldest analysis not evicted")
+	}
+	if _, ok := cache.get(common.Hash{2}, len(code)); !ok {
+		t.Errorf("newest analysis missing")
+	}
+	// Analyses not matching the code length must be ignored
+	if _, ok := cache.get(common.Hash{2}, len(code)+8); ok {
+		t.Errorf("analysis of mismatching length returned")
+	}
+}
+
+func TestAnalysisCacheJournal(t *testing.T) {
+	var (
+		code = []byte{byte(PUSH1), 0x01, byte(JUMPDEST), byte(PUSH2), 0x5b, 0x5b}
+		hash = crypto.Keccak256Hash(code)
+		path = filepath.Join(t.TempDir(), "analysiscache")
+	)
+	cache := NewAnalysisCache(1024)
+	if err := cache.Load(path); err != nil {
+		t.Fatalf("failed to load missing journal: %v", err)
+	}
+	cache.add(hash, codeBitmap(code))
+	if err := cache.Save(path); err != nil {
+		t.Fatalf("failed to save journal: %v", err)
+	}
+	loaded := NewAnalysisCache(1024)
+	if err := loaded.Load(path); err != nil {
+		t.Fatalf("failed to load journal: %v", err)
+	}
+	bits, ok := loaded.get(hash, len(code))
+	if !ok {
+		t.Fatalf("journaled analysis missing")
+	}
+	expected := codeBitmap(code)
+	for pc := uint64(0); pc < uint64(len(code)); pc++ {
+		if have, want := bits.codeSegment(pc), expected.codeSegment(pc); have != want {
+			t.Errorf("pc %d: code segment mismatch: have %v, want %v", pc, have, want)
+		}
+	}
+	// Corrupt the journal and ensure it's rejected as a whole
+	blob, _ := os.ReadFile(path)
+	blob[len(blob)-1] ^= 0xff
+	os.WriteFile(path, blob, 0644)
+
+	corrupt := NewAnalysisCache(1024)
+	if err := corrupt.Load(path); err == nil {
+		t.Fatalf("corrupt journal loaded")
+	}
+	if corrupt.Len() != 0 {
+		t.Fatalf("corrupt journal partially loaded: %d items", corrupt.Len())
+	}
+}
+
+func TestSharedAnalysis(t *testing.T) {
+	cache := NewAnalysisCache(1024)
+	SetAnalysisCache(cache)
+	defer SetAnalysisCache(nil)
+
+	code := []byte{byte(PUSH1), byte(JUMPDEST), byte(JUMPDEST)}
+	for i := 0; i < 2; i++ {
+		// Every transaction starts with a fresh contract and jumpdest map
+		contract := NewContract(AccountRef(common.Address{}), AccountRef(common.Address{1}), nil, 0)
+		contract.SetCallCode(&common.Address{1}, crypto.Keccak256Hash(code), code)
+		if contract.isCode(1) || !contract.isCode(2) {
+			t.Fatalf("run %d: invalid analysis", i)
+		}
+	}
+	if cache.Len() != 1 {
+		t.Fatalf("shared cache size mismatch: have %d, want 1", cache.Len())
+	}
+}
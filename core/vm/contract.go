@@ -108,9 +108,18 @@ func (c *Contract) isCode(udest uint64) bool {
 		// Does parent context have the analysis?
 		analysis, exist := c.jumpdests[c.CodeHash]
 		if !exist {
-			// Do the analysis and save in parent context
-			// We do not need to store it in c.analysis
-			analysis = codeBitmap(c.Code)
+			// Check the process wide cache before doing the analysis, and
+			// save the result in parent context either way
+			shared := sharedAnalysis.Load()
+			if shared != nil {
+				analysis, exist = shared.get(c.CodeHash, len(c.Code))
+			}
+			if !exist {
+				analysis = codeBitmap(c.Code)
+				if shared != nil {
+					shared.add(c.CodeHash, analysis)
+				}
+			}
 			c.jumpdests[c.CodeHash] = analysis
 		}
 		// Also stash it in current contract for faster access
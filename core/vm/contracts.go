@@ -98,7 +98,7 @@ func PrecompiledContractsForConfig(config ctypes.ChainConfigurator, bn *big.Int,
 	if config.IsEnabled(config.GetEIP152Transition, bn) {
 		precompileds[common.BytesToAddress([]byte{9})] = &blake2F{}
 	}
-	if config.IsEnabled(config.GetEIP2537Transition, bn) {
+	if config.IsEnabled(config.GetEIP2537Transition, bn) || config.IsEnabledByTime(config.GetEIP2537TransitionTime, bt) {
 		// 10-18 are BLS12-381 precompiles
 		mergeContracts(precompileds, PrecompiledContractsBLS)
 	}
@@ -26,7 +26,9 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/coregeth"
 	"github.com/ethereum/go-ethereum/params/types/ctypes"
+	"github.com/ethereum/go-ethereum/params/types/goethereum"
 
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -434,6 +436,32 @@ func TestPrecompiledBLS12381PairingFail(t *testing.T)    { testJsonFail("blsPair
 func TestPrecompiledBLS12381MapG1Fail(t *testing.T)      { testJsonFail("blsMapG1", "f11", t) }
 func TestPrecompiledBLS12381MapG2Fail(t *testing.T)      { testJsonFail("blsMapG2", "f12", t) }
 
+// Tests that the BLS12-381 precompiles can be activated by block number or by
+// timestamp, in both chain configuration formats.
+func TestBLSPrecompileActivation(t *testing.T) {
+	activation := uint64(100)
+	configs := map[string]ctypes.ChainConfigurator{
+		"coregeth block": &coregeth.CoreGethChainConfig{EIP2537FBlock: big.NewInt(100)},
+		"coregeth time":  &coregeth.CoreGethChainConfig{EIP2537FTime: &activation},
+		"geth block":     &goethereum.ChainConfig{EIP2537Block: big.NewInt(100)},
+		"geth time":      &goethereum.ChainConfig{EIP2537Time: &activation},
+	}
+	for name, config := range configs {
+		for _, at := range []struct {
+			value uint64
+			want  bool
+		}{{99, false}, {100, true}, {101, true}} {
+			num, ts := new(big.Int).SetUint64(at.value), at.value
+			precompiles := PrecompiledContractsForConfig(config, num, &ts)
+			for addr := range PrecompiledContractsBLS {
+				if _, ok := precompiles[addr]; ok != at.want {
+					t.Errorf("%s at %d: precompile %x active: have %v, want %v", name, at.value, addr, ok, at.want)
+				}
+			}
+		}
+	}
+}
+
 func loadJson(name string) ([]precompiledTest, error) {
 	data, err := os.ReadFile(fmt.Sprintf("testdata/precompiles/%v.json", name))
 	if err != nil {
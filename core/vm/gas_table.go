@@ -21,6 +21,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
 	"github.com/ethereum/go-ethereum/params/vars"
 )
 
@@ -317,10 +318,10 @@ func gasCreateEip3860(evm *EVM, contract *Contract, stack *Stack, mem *Memory, m
 		return 0, err
 	}
 	size, overflow := stack.Back(2).Uint64WithOverflow()
-	if overflow || size > vars.MaxInitCodeSize {
+	if overflow || size > ctypes.MaxInitCodeSize(evm.chainConfig) {
 		return 0, ErrGasUintOverflow
 	}
-	// Since size <= vars.MaxInitCodeSize, these multiplication cannot overflow
+	// Since size is within the (validated) init code limit, these multiplication cannot overflow
 	moreGas := vars.InitCodeWordGas * ((size + 31) / 32)
 	if gas, overflow = math.SafeAdd(gas, moreGas); overflow {
 		return 0, ErrGasUintOverflow
@@ -333,10 +334,10 @@ func gasCreate2Eip3860(evm *EVM, contract *Contract, stack *Stack, mem *Memory,
 		return 0, err
 	}
 	size, overflow := stack.Back(2).Uint64WithOverflow()
-	if overflow || size > vars.MaxInitCodeSize {
+	if overflow || size > ctypes.MaxInitCodeSize(evm.chainConfig) {
 		return 0, ErrGasUintOverflow
 	}
-	// Since size <= vars.MaxInitCodeSize, these multiplication cannot overflow
+	// Since size is within the (validated) init code limit, these multiplication cannot overflow
 	moreGas := (vars.InitCodeWordGas + vars.Keccak256WordGas) * ((size + 31) / 32)
 	if gas, overflow = math.SafeAdd(gas, moreGas); overflow {
 		return 0, ErrGasUintOverflow
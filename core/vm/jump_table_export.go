@@ -52,3 +52,27 @@ func (op *operation) HasCost() bool {
 	// filter out
 	return op.dynamicGas != nil || op.constantGas != 0
 }
+
+// OpcodeGas is the gas cost of an opcode. Dynamic costs, e.g. for memory
+// expansion or state access, are charged on top of the constant cost.
+type OpcodeGas struct {
+	Constant uint64 `json:"constant"`
+	Dynamic  bool   `json:"dynamic"`
+}
+
+// LookupGasTable returns the gas costs of all opcodes defined by the instruction
+// set active at the given block, keyed by opcode name.
+func LookupGasTable(config ctypes.ChainConfigurator, blockN *big.Int, blockTime *uint64) (map[string]OpcodeGas, error) {
+	is, err := LookupInstructionSet(config, blockN, blockTime)
+	if err != nil {
+		return nil, err
+	}
+	table := make(map[string]OpcodeGas)
+	for i, op := range is {
+		if op == nil || (!op.HasCost() && OpCode(i) != STOP) {
+			continue // undefined opcode
+		}
+		table[OpCode(i).String()] = OpcodeGas{Constant: op.constantGas, Dynamic: op.dynamicGas != nil}
+	}
+	return table, nil
+}
@@ -913,3 +913,25 @@ func BenchmarkTracerStepVsCallFrame(b *testing.B) {
 	benchmarkNonModifyingCode(10000000, code, "tracer-step-10M", stepTracer, b)
 	benchmarkNonModifyingCode(10000000, code, "tracer-call-frame-10M", callFrameTracer, b)
 }
+
+// Tests that the contract code size limit can be raised by the chain config.
+func TestConfiguredMaxCodeSize(t *testing.T) {
+	// Init code returning 30000 zero bytes, above the default limit
+	initcode := []byte{byte(vm.PUSH3), 0x00, 0x75, 0x30, byte(vm.PUSH1), 0x00, byte(vm.RETURN)}
+
+	if _, _, _, err := Create(initcode, nil); err != vm.ErrMaxCodeSizeExceeded {
+		t.Fatalf("default limit not enforced: have %v, want %v", err, vm.ErrMaxCodeSizeExceeded)
+	}
+	cfg := new(Config)
+	setDefaults(cfg)
+	limit := uint64(32768)
+	cfg.ChainConfig.SetMaxCodeSize(&limit)
+
+	code, _, _, err := Create(initcode, cfg)
+	if err != nil {
+		t.Fatalf("failed to create contract within the configured limit: %v", err)
+	}
+	if len(code) != 30000 {
+		t.Fatalf("code size mismatch: have %d, want 30000", len(code))
+	}
+}
@@ -31,6 +31,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto/keccak"
 	"github.com/ethereum/go-ethereum/rlp"
 	"golang.org/x/crypto/sha3"
 )
@@ -68,8 +69,13 @@ type KeccakState interface {
 	Read([]byte) (int, error)
 }
 
-// NewKeccakState creates a new KeccakState
+// NewKeccakState creates a new KeccakState. On ARM64 processors supporting the
+// SHA3 extension, the hardware accelerated implementation is used unless built
+// with the nokeccakasm tag.
 func NewKeccakState() KeccakState {
+	if keccak.Accelerated {
+		return keccak.NewLegacyKeccak256().(KeccakState)
+	}
 	return sha3.NewLegacyKeccak256().(KeccakState)
 }
 
@@ -105,7 +111,12 @@ func Keccak256Hash(data ...[]byte) (h common.Hash) {
 
 // Keccak512 calculates and returns the Keccak512 hash of the input data.
 func Keccak512(data ...[]byte) []byte {
-	d := sha3.NewLegacyKeccak512()
+	var d hash.Hash
+	if keccak.Accelerated {
+		d = keccak.NewLegacyKeccak512()
+	} else {
+		d = sha3.NewLegacyKeccak512()
+	}
 	for _, b := range data {
 		d.Write(b)
 	}
@@ -0,0 +1,214 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package keccak implements the legacy Keccak-256 and Keccak-512 hash functions
+// used by Ethereum, with a hardware accelerated permutation on ARM64 processors
+// supporting the SHA3 extension. The extension is detected at runtime, building
+// with the nokeccakasm (or purego) tag disables the assembly altogether.
+//
+// The sponge construction is a copy of the one in golang.org/x/crypto/sha3.
+// That package neither exposes its sponge nor lets callers substitute the
+// permutation, and the version we depend on has no ARM64 assembly, so using the
+// SHA3 instructions requires carrying the sponge here. It is only used where
+// the permutation is accelerated, see Accelerated; the other platforms keep
+// using golang.org/x/crypto/sha3, which is faster there.
+package keccak
+
+import (
+	"encoding/binary"
+	"hash"
+)
+
+// maxRate is the size of the internal buffer, the rate of Keccak-256.
+const maxRate = 136
+
+// spongeDirection indicates the direction bytes are flowing through the sponge.
+type spongeDirection int
+
+const (
+	// spongeAbsorbing indicates that the sponge is absorbing input.
+	spongeAbsorbing spongeDirection = iota
+	// spongeSqueezing indicates that the sponge is being squeezed.
+	spongeSqueezing
+)
+
+// state is a Keccak sponge using the original padding, i.e. the domain
+// separation byte 0x01 instead of the one of the standardized SHA-3.
+type state struct {
+	a    [25]uint64 // main state of the hash
+	buf  []byte     // points into storage
+	rate int        // the number of bytes of state to use
+
+	storage [maxRate]byte
+
+	outputLen int             // the default output size in bytes
+	state     spongeDirection // whether the sponge is absorbing or squeezing
+}
+
+// NewLegacyKeccak256 creates a new Keccak-256 hash. The returned hash also
+// implements io.Reader, to squeeze the output without copying the state.
+func NewLegacyKeccak256() hash.Hash { return &state{rate: 136, outputLen: 32} }
+
+// NewLegacyKeccak512 creates a new Keccak-512 hash.
+func NewLegacyKeccak512() hash.Hash { return &state{rate: 72, outputLen: 64} }
+
+// BlockSize returns the rate of sponge underlying this hash function.
+func (d *state) BlockSize() int { return d.rate }
+
+// Size returns the output size of the hash function in bytes.
+func (d *state) Size() int { return d.outputLen }
+
+// Reset clears the internal state by zeroing the sponge state and
+// the byte buffer, and setting Sponge.state to absorbing.
+func (d *state) Reset() {
+	for i := range d.a {
+		d.a[i] = 0
+	}
+	d.state = spongeAbsorbing
+	d.buf = d.storage[:0]
+}
+
+func (d *state) clone() *state {
+	ret := *d
+	if ret.state == spongeAbsorbing {
+		ret.buf = ret.storage[:len(ret.buf)]
+	} else {
+		ret.buf = ret.storage[d.rate-cap(d.buf) : d.rate]
+	}
+	return &ret
+}
+
+// xorIn xors the bytes in buf into the state.
+func (d *state) xorIn(buf []byte) {
+	for i := 0; len(buf) >= 8; i++ {
+		d.a[i] ^= binary.LittleEndian.Uint64(buf)
+		buf = buf[8:]
+	}
+}
+
+// copyOut copies the state into buf.
+func (d *state) copyOut(buf []byte) {
+	for i := 0; len(buf) >= 8; i++ {
+		binary.LittleEndian.PutUint64(buf, d.a[i])
+		buf = buf[8:]
+	}
+}
+
+// permute applies the KeccakF-1600 permutation. It handles
+// any input-output buffering.
+func (d *state) permute() {
+	switch d.state {
+	case spongeAbsorbing:
+		// If we're absorbing, we need to xor the input into the state
+		// before applying the permutation.
+		d.xorIn(d.buf)
+		d.buf = d.storage[:0]
+		keccakF1600(&d.a)
+	case spongeSqueezing:
+		// If we're squeezing, we need to apply the permutation before
+		// copying more output.
+		keccakF1600(&d.a)
+		d.buf = d.storage[:d.rate]
+		d.copyOut(d.buf)
+	}
+}
+
+// padAndPermute appends the domain separation bits, applies the multi-bitrate
+// 10..1 padding rule, and permutes the state.
+func (d *state) padAndPermute() {
+	if d.buf == nil {
+		d.buf = d.storage[:0]
+	}
+	// Pad with the Keccak domain-separator bits. We know that there's at least
+	// one byte of space in d.buf because, if it were full, permute would have
+	// been called to empty it. The byte also contains the first one bit for
+	// the padding.
+	d.buf = append(d.buf, 0x01)
+	zerosStart := len(d.buf)
+	d.buf = d.storage[:d.rate]
+	for i := zerosStart; i < d.rate; i++ {
+		d.buf[i] = 0
+	}
+	// This adds the final one bit for the padding. Because of the way that
+	// bits are numbered from the LSB upwards, the final bit is the MSB of
+	// the last byte.
+	d.buf[d.rate-1] ^= 0x80
+
+	// Apply the permutation
+	d.permute()
+	d.state = spongeSqueezing
+	d.buf = d.storage[:d.rate]
+	d.copyOut(d.buf)
+}
+
+// Write absorbs more data into the hash's state. It panics if any
+// output has already been read.
+func (d *state) Write(p []byte) (written int, err error) {
+	if d.state != spongeAbsorbing {
+		panic("keccak: Write after Read")
+	}
+	if d.buf == nil {
+		d.buf = d.storage[:0]
+	}
+	written = len(p)
+
+	for len(p) > 0 {
+		if len(d.buf) == 0 && len(p) >= d.rate {
+			// The fast path; absorb a full "rate" bytes of input and apply the permutation.
+			d.xorIn(p[:d.rate])
+			p = p[d.rate:]
+			keccakF1600(&d.a)
+		} else {
+			// The slow path; buffer the input until we can fill the sponge, and then xor it in.
+			todo := d.rate - len(d.buf)
+			if todo > len(p) {
+				todo = len(p)
+			}
+			d.buf = append(d.buf, p[:todo]...)
+			p = p[todo:]
+
+			// If the sponge is full, apply the permutation.
+			if len(d.buf) == d.rate {
+				d.permute()
+			}
+		}
+	}
+	return
+}
+
+// Read squeezes an arbitrary number of bytes from the sponge.
+func (d *state) Read(out []byte) (n int, err error) {
+	// If we're still absorbing, pad and apply the permutation.
+	if d.state == spongeAbsorbing {
+		d.padAndPermute()
+	}
+	n = len(out)
+
+	// Now, do the squeezing.
+	for len(out) > 0 {
+		n := copy(out, d.buf)
+		d.buf = d.buf[n:]
+		out = out[n:]
+
+		// Apply the permutation if we've squeezed the sponge dry.
+		if len(d.buf) == 0 {
+			d.permute()
+		}
+	}
+	return
+}
+
+// Sum applies padding to the hash state and then squeezes out the desired
+// number of output bytes. It panics if any output has already been read.
+func (d *state) Sum(in []byte) []byte {
+	if d.state != spongeAbsorbing {
+		panic("keccak: Sum after Read")
+	}
+	// Make a copy of the original hash so that caller can keep writing
+	// and summing.
+	dup := d.clone()
+	hash := make([]byte, dup.outputLen, 64) // explicit cap to allow stack allocation
+	dup.Read(hash)
+	return append(in, hash...)
+}
@@ -0,0 +1,162 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keccak
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// Tests the hashes against known test vectors.
+func TestKeccakVectors(t *testing.T) {
+	tests := []struct {
+		input string
+		k256  string
+		k512  string
+	}{
+		{
+			input: "",
+			k256:  "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470",
+			k512:  "0eab42de4c3ceb9235fc91acffe746b29c29a8c366b7c60e4e67c466f36a4304c00fa9caf9d87976ba469bcbe06713b435f091ef2769fb160cdab33d3670680e",
+		},
+		{
+			input: "abc",
+			k256:  "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45",
+			k512:  "18587dc2ea106b9a1563e32b3312421ca164c7f1f07bc922a9c83d77cea3a1e5d0c69910739025372dc14ac9642629379540c17e2a65b19d77aa511a9d00bb96",
+		},
+	}
+	for i, tt := range tests {
+		h := NewLegacyKeccak256()
+		h.Write([]byte(tt.input))
+		if have := hex.EncodeToString(h.Sum(nil)); have != tt.k256 {
+			t.Errorf("test %d: keccak256 mismatch: have %s, want %s", i, have, tt.k256)
+		}
+		h = NewLegacyKeccak512()
+		h.Write([]byte(tt.input))
+		if have := hex.EncodeToString(h.Sum(nil)); have != tt.k512 {
+			t.Errorf("test %d: keccak512 mismatch: have %s, want %s", i, have, tt.k512)
+		}
+	}
+}
+
+// Tests that the hashes match the golang.org/x/crypto/sha3 ones for inputs of
+// different sizes written in chunks, read out with both Sum and Read.
+func TestKeccakCrossCheck(t *testing.T) {
+	data := make([]byte, 1024)
+	rand.Read(data)
+
+	for size := 0; size <= len(data); size += 7 {
+		input := data[:size]
+		for _, fn := range []struct {
+			name string
+			have func() *state
+			want func() []byte
+		}{
+			{
+				name: "keccak256",
+				have: func() *state { return NewLegacyKeccak256().(*state) },
+				want: func() []byte { h := sha3.NewLegacyKeccak256(); h.Write(input); return h.Sum(nil) },
+			},
+			{
+				name: "keccak512",
+				have: func() *state { return NewLegacyKeccak512().(*state) },
+				want: func() []byte { h := sha3.NewLegacyKeccak512(); h.Write(input); return h.Sum(nil) },
+			},
+		} {
+			want := fn.want()
+
+			// Write in chunks of varying size
+			h := fn.have()
+			for rest := input; len(rest) > 0; {
+				n := rand.Intn(len(rest)) + 1
+				h.Write(rest[:n])
+				rest = rest[n:]
+			}
+			if have := h.Sum(nil); !bytes.Equal(have, want) {
+				t.Fatalf("%s size %d: sum mismatch: have %x, want %x", fn.name, size, have, want)
+			}
+			// Summing doesn't affect the state, squeeze it directly too
+			have := make([]byte, len(want))
+			h.Read(have)
+			if !bytes.Equal(have, want) {
+				t.Fatalf("%s size %d: read mismatch: have %x, want %x", fn.name, size, have, want)
+			}
+			// Reset and reuse the hasher
+			h.Reset()
+			h.Write(input)
+			if have := h.Sum(nil); !bytes.Equal(have, want) {
+				t.Fatalf("%s size %d: sum after reset mismatch: have %x, want %x", fn.name, size, have, want)
+			}
+		}
+	}
+}
+
+// keccakF1600Zero is the Keccak-f[1600] permutation of the all-zero state, from
+// the reference test vectors.
+var keccakF1600Zero = [25]uint64{
+	0xf1258f7940e1dde7, 0x84d5ccf933c0478a, 0xd598261ea65aa9ee, 0xbd1547306f80494d, 0x8b284e056253d057,
+	0xff97a42d7f8e6fd4, 0x90fee5a0a44647c4, 0x8c5bda0cd6192e76, 0xad30a6f71b19059c, 0x30935ab7d08ffc64,
+	0xeb5aa93f2317d635, 0xa9a6e6260d712103, 0x81a57c16dbcf555f, 0x43b831cd0347c826, 0x01f22f1a11a5569f,
+	0x05e5635a21d9ae61, 0x64befef28cc970f2, 0x613670957bc46611, 0xb87c5a554fd00ecb, 0x8c3ee88a1ccf32c8,
+	0x940c7922ae3a2614, 0x1841f924a2c509e4, 0x16f53526e70465c2, 0x75f644e97f30a13b, 0xeaf1ff7b5ceca249,
+}
+
+// Tests that the platform permutation matches the test vector and the generic
+// permutation.
+func TestKeccakF1600(t *testing.T) {
+	t.Logf("accelerated: %v", Accelerated)
+
+	var have, want [25]uint64
+	keccakF1600(&have)
+	if have != keccakF1600Zero {
+		t.Fatalf("zero state permutation mismatch: have %x, want %x", have, keccakF1600Zero)
+	}
+	for i := 0; i < 100; i++ {
+		for j := range have {
+			have[j] = rand.Uint64()
+		}
+		want = have
+		keccakF1600(&have)
+		keccakF1600Generic(&want)
+		if have != want {
+			t.Fatalf("permutation mismatch: have %x, want %x", have, want)
+		}
+	}
+}
+
+func BenchmarkKeccak256(b *testing.B) {
+	for _, size := range []int{32, 128, 1024} {
+		data := make([]byte, size)
+		b.Run(fmt.Sprintf("%d", size), func(b *testing.B) {
+			h := NewLegacyKeccak256().(*state)
+			out := make([]byte, 32)
+
+			b.SetBytes(int64(size))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				h.Reset()
+				h.Write(data)
+				h.Read(out)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build arm64 && gc && !purego && !nokeccakasm
+
+package keccak
+
+import (
+	"runtime"
+
+	"golang.org/x/sys/cpu"
+)
+
+// Accelerated reports whether the Keccak permutation runs on dedicated hardware
+// instructions. Apple Silicon always implements the SHA3 extension, but the
+// feature detection is not available on darwin.
+var Accelerated = cpu.ARM64.HasSHA3 || runtime.GOOS == "darwin"
+
+// keccakF1600 applies the Keccak permutation, using the SHA3 extension if it is
+// supported by the processor.
+func keccakF1600(a *[25]uint64) {
+	if Accelerated {
+		keccakF1600SHA3(a)
+		return
+	}
+	keccakF1600Generic(a)
+}
+
+//go:noescape
+func keccakF1600SHA3(a *[25]uint64)
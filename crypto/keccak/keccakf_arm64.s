@@ -0,0 +1,169 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build arm64 && gc && !purego && !nokeccakasm
+
+#include "textflag.h"
+
+// The permutation uses the ARMv8.2 SHA3 extension: EOR3 and BCAX fuse the
+// three-way xor of theta and the and-not of chi, RAX1 and XAR fuse the xors with
+// the rotations. Each lane of the state is kept in the low half of a vector
+// register, V0-V24 holding a[0]-a[24], with V25-V31 as scratch space.
+
+// func keccakF1600SHA3(a *[25]uint64)
+TEXT ·keccakF1600SHA3(SB), NOSPLIT, $0-8
+	MOVD	a+0(FP), R0
+	MOVD	$round_consts<>(SB), R1
+	MOVD	$24, R2
+
+	// Load the state
+	FLDPD	0(R0), (F0, F1)
+	FLDPD	16(R0), (F2, F3)
+	FLDPD	32(R0), (F4, F5)
+	FLDPD	48(R0), (F6, F7)
+	FLDPD	64(R0), (F8, F9)
+	FLDPD	80(R0), (F10, F11)
+	FLDPD	96(R0), (F12, F13)
+	FLDPD	112(R0), (F14, F15)
+	FLDPD	128(R0), (F16, F17)
+	FLDPD	144(R0), (F18, F19)
+	FLDPD	160(R0), (F20, F21)
+	FLDPD	176(R0), (F22, F23)
+	FMOVD	192(R0), F24
+
+loop:
+	// Theta: column parities
+	VEOR3	V14.B16, V9.B16, V4.B16, V29.B16
+	VEOR3	V11.B16, V6.B16, V1.B16, V26.B16
+	VEOR3	V13.B16, V8.B16, V3.B16, V28.B16
+	VEOR3	V10.B16, V5.B16, V0.B16, V25.B16
+	VEOR3	V12.B16, V7.B16, V2.B16, V27.B16
+	VEOR3	V24.B16, V19.B16, V29.B16, V29.B16
+	VEOR3	V21.B16, V16.B16, V26.B16, V26.B16
+	VEOR3	V23.B16, V18.B16, V28.B16, V28.B16
+	VEOR3	V20.B16, V15.B16, V25.B16, V25.B16
+	VEOR3	V22.B16, V17.B16, V27.B16, V27.B16
+
+	// Theta: column effects
+	VRAX1	V26.D2, V29.D2, V30.D2
+	VRAX1	V28.D2, V26.D2, V26.D2
+	VRAX1	V25.D2, V28.D2, V28.D2
+	VRAX1	V27.D2, V25.D2, V25.D2
+	VRAX1	V29.D2, V27.D2, V27.D2
+
+	// Theta, rho and pi: combine the column effects and rotate into place
+	VEOR	V30.B16, V0.B16, V0.B16
+	VXAR	$63, V25.D2, V1.D2, V29.D2
+	VXAR	$20, V25.D2, V6.D2, V1.D2
+	VXAR	$44, V28.D2, V9.D2, V6.D2
+	VXAR	$3, V26.D2, V22.D2, V9.D2
+	VXAR	$25, V28.D2, V14.D2, V22.D2
+	VXAR	$46, V30.D2, V20.D2, V14.D2
+	VXAR	$2, V26.D2, V2.D2, V31.D2
+	VXAR	$21, V26.D2, V12.D2, V2.D2
+	VXAR	$39, V27.D2, V13.D2, V12.D2
+	VXAR	$56, V28.D2, V19.D2, V13.D2
+	VXAR	$8, V27.D2, V23.D2, V19.D2
+	VXAR	$23, V30.D2, V15.D2, V23.D2
+	VXAR	$37, V28.D2, V4.D2, V15.D2
+	VXAR	$50, V28.D2, V24.D2, V28.D2
+	VXAR	$62, V25.D2, V21.D2, V24.D2
+	VXAR	$9, V27.D2, V8.D2, V8.D2
+	VXAR	$19, V25.D2, V16.D2, V4.D2
+	VXAR	$28, V30.D2, V5.D2, V16.D2
+	VXAR	$36, V27.D2, V3.D2, V5.D2
+	VXAR	$43, V27.D2, V18.D2, V27.D2
+	VXAR	$49, V26.D2, V17.D2, V3.D2
+	VXAR	$54, V25.D2, V11.D2, V25.D2
+	VXAR	$58, V26.D2, V7.D2, V26.D2
+	VXAR	$61, V30.D2, V10.D2, V30.D2
+
+	// Chi and iota: non-linear row mixing and round constant
+	VBCAX	V8.B16, V22.B16, V31.B16, V20.B16
+	VBCAX	V22.B16, V23.B16, V8.B16, V21.B16
+	VBCAX	V23.B16, V24.B16, V22.B16, V22.B16
+	VBCAX	V24.B16, V31.B16, V23.B16, V23.B16
+	VBCAX	V31.B16, V8.B16, V24.B16, V24.B16
+
+	// Load the round constant into the freed up scratch register
+	VLD1R.P	8(R1), [V31.D2]
+
+	VBCAX	V3.B16, V19.B16, V25.B16, V17.B16
+	VBCAX	V19.B16, V15.B16, V3.B16, V18.B16
+	VBCAX	V15.B16, V16.B16, V19.B16, V19.B16
+	VBCAX	V16.B16, V25.B16, V15.B16, V15.B16
+	VBCAX	V25.B16, V3.B16, V16.B16, V16.B16
+	VBCAX	V26.B16, V12.B16, V29.B16, V10.B16
+	VBCAX	V12.B16, V13.B16, V26.B16, V11.B16
+	VBCAX	V13.B16, V14.B16, V12.B16, V12.B16
+	VBCAX	V14.B16, V29.B16, V13.B16, V13.B16
+	VBCAX	V29.B16, V26.B16, V14.B16, V14.B16
+	VBCAX	V4.B16, V9.B16, V30.B16, V7.B16
+	VBCAX	V9.B16, V5.B16, V4.B16, V8.B16
+	VBCAX	V5.B16, V6.B16, V9.B16, V9.B16
+	VBCAX	V6.B16, V30.B16, V5.B16, V5.B16
+	VBCAX	V30.B16, V4.B16, V6.B16, V6.B16
+	VBCAX	V28.B16, V0.B16, V27.B16, V3.B16
+	VBCAX	V0.B16, V1.B16, V28.B16, V4.B16
+	VBCAX	V1.B16, V2.B16, V0.B16, V0.B16
+	VBCAX	V2.B16, V27.B16, V1.B16, V1.B16
+	VBCAX	V27.B16, V28.B16, V2.B16, V2.B16
+	VEOR	V31.B16, V0.B16, V0.B16
+
+	SUB	$1, R2
+	CBNZ	R2, loop
+
+	// Store the state
+	FSTPD	(F0, F1), 0(R0)
+	FSTPD	(F2, F3), 16(R0)
+	FSTPD	(F4, F5), 32(R0)
+	FSTPD	(F6, F7), 48(R0)
+	FSTPD	(F8, F9), 64(R0)
+	FSTPD	(F10, F11), 80(R0)
+	FSTPD	(F12, F13), 96(R0)
+	FSTPD	(F14, F15), 112(R0)
+	FSTPD	(F16, F17), 128(R0)
+	FSTPD	(F18, F19), 144(R0)
+	FSTPD	(F20, F21), 160(R0)
+	FSTPD	(F22, F23), 176(R0)
+	FMOVD	F24, 192(R0)
+	RET
+
+DATA	round_consts<>+0x00(SB)/8, $0x0000000000000001
+DATA	round_consts<>+0x08(SB)/8, $0x0000000000008082
+DATA	round_consts<>+0x10(SB)/8, $0x800000000000808A
+DATA	round_consts<>+0x18(SB)/8, $0x8000000080008000
+DATA	round_consts<>+0x20(SB)/8, $0x000000000000808B
+DATA	round_consts<>+0x28(SB)/8, $0x0000000080000001
+DATA	round_consts<>+0x30(SB)/8, $0x8000000080008081
+DATA	round_consts<>+0x38(SB)/8, $0x8000000000008009
+DATA	round_consts<>+0x40(SB)/8, $0x000000000000008A
+DATA	round_consts<>+0x48(SB)/8, $0x0000000000000088
+DATA	round_consts<>+0x50(SB)/8, $0x0000000080008009
+DATA	round_consts<>+0x58(SB)/8, $0x000000008000000A
+DATA	round_consts<>+0x60(SB)/8, $0x000000008000808B
+DATA	round_consts<>+0x68(SB)/8, $0x800000000000008B
+DATA	round_consts<>+0x70(SB)/8, $0x8000000000008089
+DATA	round_consts<>+0x78(SB)/8, $0x8000000000008003
+DATA	round_consts<>+0x80(SB)/8, $0x8000000000008002
+DATA	round_consts<>+0x88(SB)/8, $0x8000000000000080
+DATA	round_consts<>+0x90(SB)/8, $0x000000000000800A
+DATA	round_consts<>+0x98(SB)/8, $0x800000008000000A
+DATA	round_consts<>+0xa0(SB)/8, $0x8000000080008081
+DATA	round_consts<>+0xa8(SB)/8, $0x8000000000008080
+DATA	round_consts<>+0xb0(SB)/8, $0x0000000080000001
+DATA	round_consts<>+0xb8(SB)/8, $0x8000000080008008
+GLOBL	round_consts<>(SB), NOPTR|RODATA, $192
@@ -0,0 +1,48 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build arm64 && gc && !purego && !nokeccakasm
+
+package keccak
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Tests the SHA3 extension permutation directly, rather than through the
+// dispatcher, against the test vector and the generic permutation.
+func TestKeccakF1600SHA3(t *testing.T) {
+	if !Accelerated {
+		t.Skip("processor lacks the SHA3 extension")
+	}
+	var have, want [25]uint64
+	keccakF1600SHA3(&have)
+	if have != keccakF1600Zero {
+		t.Fatalf("zero state permutation mismatch: have %x, want %x", have, keccakF1600Zero)
+	}
+	for i := 0; i < 1000; i++ {
+		for j := range have {
+			have[j] = rand.Uint64()
+		}
+		want = have
+		keccakF1600SHA3(&have)
+		keccakF1600Generic(&want)
+		if have != want {
+			t.Fatalf("permutation mismatch: have %x, want %x", have, want)
+		}
+	}
+}
@@ -54,31 +54,42 @@ func parsePrivateKey(key string) (k ed25519.PrivateKey, header []byte, keyNum []
 // This accepts base64 keys in the format created by the 'signify' tool.
 // The signature is written to the 'output' file.
 func SignFile(input string, output string, key string, untrustedComment string, trustedComment string) error {
+	if untrustedComment == "" {
+		untrustedComment = "verify with " + input + ".pub"
+	}
+	filedata, err := os.ReadFile(input)
+	if err != nil {
+		return err
+	}
+	sig, err := Sign(filedata, key, untrustedComment, trustedComment)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(output, sig, 0644)
+}
+
+// Sign creates a signature of the given data, in the same format as SignFile.
+func Sign(data []byte, key string, untrustedComment string, trustedComment string) ([]byte, error) {
 	// Pre-check comments and ensure they're set to something.
 	if strings.IndexByte(untrustedComment, '\n') >= 0 {
-		return errors.New("untrusted comment must not contain newline")
+		return nil, errors.New("untrusted comment must not contain newline")
 	}
 	if strings.IndexByte(trustedComment, '\n') >= 0 {
-		return errors.New("trusted comment must not contain newline")
+		return nil, errors.New("trusted comment must not contain newline")
 	}
 	if untrustedComment == "" {
-		untrustedComment = "verify with " + input + ".pub"
+		untrustedComment = "signature from signify secret key"
 	}
 	if trustedComment == "" {
 		trustedComment = fmt.Sprintf("timestamp:%d", time.Now().Unix())
 	}
-
-	filedata, err := os.ReadFile(input)
-	if err != nil {
-		return err
-	}
 	skey, header, keyNum, err := parsePrivateKey(key)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Create the main data signature.
-	rawSig := ed25519.Sign(skey, filedata)
+	rawSig := ed25519.Sign(skey, data)
 	var dataSig []byte
 	dataSig = append(dataSig, header...)
 	dataSig = append(dataSig, keyNum...)
@@ -90,11 +101,11 @@ func SignFile(input string, output string, key string, untrustedComment string,
 	commentSigInput = append(commentSigInput, []byte(trustedComment)...)
 	commentSig := ed25519.Sign(skey, commentSigInput)
 
-	// Create the output file.
+	// Create the output.
 	var out = new(bytes.Buffer)
 	fmt.Fprintln(out, "untrusted comment:", untrustedComment)
 	fmt.Fprintln(out, base64.StdEncoding.EncodeToString(dataSig))
 	fmt.Fprintln(out, "trusted comment:", trustedComment)
 	fmt.Fprintln(out, base64.StdEncoding.EncodeToString(commentSig))
-	return os.WriteFile(output, out.Bytes(), 0644)
+	return out.Bytes(), nil
 }
@@ -17,8 +17,15 @@
 package eth
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params/mutations"
 )
 
 // EthereumAPI provides an API to access Ethereum full node-related information.
@@ -46,7 +53,122 @@ func (api *EthereumAPI) Hashrate() hexutil.Uint64 {
 	return hexutil.Uint64(api.e.Miner().Hashrate())
 }
 
+// maxHashrateWindow is the maximum number of blocks covered by a single
+// eth_hashrateEstimate call.
+const maxHashrateWindow = 10000
+
+// HashrateEstimate is the result of eth_hashrateEstimate.
+type HashrateEstimate struct {
+	FirstBlock hexutil.Uint64 `json:"firstBlock"`
+	LastBlock  hexutil.Uint64 `json:"lastBlock"`
+	Blocks     hexutil.Uint64 `json:"blocks"`
+	Duration   hexutil.Uint64 `json:"duration"`
+	Hashrate   *hexutil.Big   `json:"hashrate"`
+	Lower      *hexutil.Big   `json:"lower"`
+	Upper      *hexutil.Big   `json:"upper"`
+}
+
+// HashrateEstimate estimates the network hashrate from the difficulties and
+// timestamps of the given number of most recent blocks, together with the
+// bounds of its 95% confidence interval.
+func (api *EthereumAPI) HashrateEstimate(window hexutil.Uint64) (*HashrateEstimate, error) {
+	if window > maxHashrateWindow {
+		return nil, fmt.Errorf("window too large, maximum is %d blocks", maxHashrateWindow)
+	}
+	est, err := api.e.blockchain.EstimateHashrate(uint64(window))
+	if err != nil {
+		return nil, err
+	}
+	return &HashrateEstimate{
+		FirstBlock: hexutil.Uint64(est.FirstBlock),
+		LastBlock:  hexutil.Uint64(est.LastBlock),
+		Blocks:     hexutil.Uint64(est.Blocks),
+		Duration:   hexutil.Uint64(est.Duration),
+		Hashrate:   (*hexutil.Big)(est.Hashrate),
+		Lower:      (*hexutil.Big)(est.Lower),
+		Upper:      (*hexutil.Big)(est.Upper),
+	}, nil
+}
+
+// ConfirmationAdvice is the result of eth_recommendedConfirmations.
+type ConfirmationAdvice struct {
+	Confirmations hexutil.Uint64 `json:"confirmations"`
+	Duration      hexutil.Uint64 `json:"duration"`  // Expected seconds until confirmed
+	BlockTime     hexutil.Uint64 `json:"blockTime"` // Average recent block time in seconds
+	BlockReward   *hexutil.Big   `json:"blockReward"`
+	MESS          bool           `json:"mess"`    // Whether ECBP1100 (MESS) is active
+	Gravity       float64        `json:"gravity"` // MESS work factor at the recommended depth
+	DeepestReorg  hexutil.Uint64 `json:"deepestReorg"`
+	RiskLevel     string         `json:"riskLevel"`
+}
+
+// RecommendedConfirmations suggests the number of confirmations to wait for
+// before considering a transfer of the given value final. The recommendation
+// makes reversing the transfer cost more in block rewards than it is worth,
+// accounting for the additional work required by MESS, and is raised by recent
+// deep reorgs and an elevated attack risk.
+func (api *EthereumAPI) RecommendedConfirmations(value hexutil.Big) (*ConfirmationAdvice, error) {
+	if value.ToInt().Sign() < 0 {
+		return nil, errors.New("negative value")
+	}
+	var (
+		chain  = api.e.blockchain
+		head   = chain.CurrentBlock()
+		risk   = api.e.attacks.Risk()
+		reward = new(big.Int)
+		conds  = confirmationParams{
+			blockTime:    defaultBlockTime,
+			mess:         risk.MESS,
+			deepestReorg: risk.DeepestReorg,
+		}
+	)
+	if head.Difficulty.Sign() > 0 {
+		winner, _ := mutations.GetRewards(chain.Config(), head, nil)
+		reward = winner.ToBig()
+	}
+	conds.reward = reward
+	if est, err := chain.EstimateHashrate(confirmationBlockWindow); err == nil {
+		conds.blockTime = max(1, est.Duration/est.Blocks)
+	}
+	if risk.Level != attackRiskLow {
+		conds.riskFloor = risk.Confirmations
+	}
+	confirmations, gravity := recommendConfirmations(value.ToInt(), conds)
+
+	return &ConfirmationAdvice{
+		Confirmations: hexutil.Uint64(confirmations),
+		Duration:      hexutil.Uint64(confirmations * conds.blockTime),
+		BlockTime:     hexutil.Uint64(conds.blockTime),
+		BlockReward:   (*hexutil.Big)(reward),
+		MESS:          conds.mess,
+		Gravity:       gravity,
+		DeepestReorg:  hexutil.Uint64(risk.DeepestReorg),
+		RiskLevel:     risk.Level,
+	}, nil
+}
+
 // Mining returns an indication if this node is currently mining.
 func (api *EthereumAPI) Mining() bool {
 	return api.e.IsMining()
 }
+
+// SendPrivateTransaction forwards a signed transaction to the configured set of
+// trusted relays instead of gossiping it over the network. The transaction is
+// not added to the local pool, so it will not be announced to any peers.
+func (api *EthereumAPI) SendPrivateTransaction(ctx context.Context, input hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	config := api.e.blockchain.Config()
+	if tx.Protected() && tx.ChainId().Cmp(config.GetChainID()) != 0 {
+		return common.Hash{}, errors.New("transaction chain id does not match the node's chain id")
+	}
+	if _, err := types.Sender(types.LatestSigner(config), tx); err != nil {
+		return common.Hash{}, err
+	}
+	if err := api.e.txRelay.send(ctx, tx.Hash(), input); err != nil {
+		return common.Hash{}, err
+	}
+	return tx.Hash(), nil
+}
@@ -18,14 +18,27 @@ package eth
 
 import (
 	"compress/gzip"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
+	"sync/atomic"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/maintenance"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/internal/version"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 )
@@ -33,7 +46,8 @@ import (
 // AdminAPI is the collection of Ethereum full node related APIs for node
 // administration.
 type AdminAPI struct {
-	eth *Ethereum
+	eth    *Ethereum
+	backup atomic.Bool // Whether a database backup is in progress
 }
 
 // NewAdminAPI creates a new instance of AdminAPI.
@@ -152,6 +166,30 @@ func (api *AdminAPI) Ecbp1100(blockNr rpc.BlockNumber) (bool, error) {
 			api.eth.blockchain.CurrentBlock().Number), err
 }
 
+// AttackRisk returns the estimated risk of an ongoing majority attack, combining
+// deep reorgs, revealed private branches and difficulty swings with the MESS
+// status into a recommended number of confirmations.
+func (api *AdminAPI) AttackRisk() AttackRisk {
+	return api.eth.attacks.Risk()
+}
+
+// HeldReorgs returns the reorgs refused for exceeding the maximum reorg depth,
+// awaiting confirmation via AcceptReorg.
+func (api *AdminAPI) HeldReorgs() []core.HeldReorg {
+	return api.eth.blockchain.HeldReorgs()
+}
+
+// AcceptReorg resolves the refused reorgs, setting the block with the given hash,
+// usually the head of the competing branch, as the new chain head. The head is
+// not updated until then. Passing the current head rejects the competing
+// branches instead.
+func (api *AdminAPI) AcceptReorg(hash common.Hash) (bool, error) {
+	if err := api.eth.blockchain.AcceptReorg(hash); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // MaxPeers sets the maximum peer limit for the protocol manager and the p2p server.
 func (api *AdminAPI) MaxPeers(n int) (bool, error) {
 	api.eth.handler.maxPeers = n
@@ -166,3 +204,157 @@ func (api *AdminAPI) MaxPeers(n int) (bool, error) {
 	}
 	return true, nil
 }
+
+// Maintenance controls the background database maintenance of the node, such
+// as snapshot generation, index backfills and ancient store migration. The
+// action is one of "pause", "resume" or "status", the resulting state of the
+// maintenance switch is returned.
+func (api *AdminAPI) Maintenance(action string) (maintenance.Status, error) {
+	switch action {
+	case "pause":
+		maintenance.Pause()
+	case "resume":
+		maintenance.Resume()
+	case "status", "":
+	default:
+		return maintenance.Status{}, fmt.Errorf("unknown maintenance action %q, want pause, resume or status", action)
+	}
+	return maintenance.Report(), nil
+}
+
+// TxGossipArgs represents the arguments to update the transaction gossip policy.
+// Fields left unset keep their current value.
+type TxGossipArgs struct {
+	Disabled   *bool `json:"disabled"`
+	HashesOnly *bool `json:"hashesOnly"`
+	NoLocals   *bool `json:"noLocals"`
+}
+
+// TxGossip returns the currently active outbound transaction gossip policy.
+func (api *AdminAPI) TxGossip() ethconfig.TxGossipConfig {
+	return *api.eth.handler.txGossip.Load()
+}
+
+// SetTxGossip updates the outbound transaction gossip policy and returns the
+// resulting configuration.
+func (api *AdminAPI) SetTxGossip(args TxGossipArgs) ethconfig.TxGossipConfig {
+	config := api.TxGossip()
+	if args.Disabled != nil {
+		config.Disabled = *args.Disabled
+	}
+	if args.HashesOnly != nil {
+		config.HashesOnly = *args.HashesOnly
+	}
+	if args.NoLocals != nil {
+		config.NoLocals = *args.NoLocals
+	}
+	api.eth.handler.setTxGossip(config)
+	log.Info("Updated transaction gossip policy", "disabled", config.Disabled, "hashesonly", config.HashesOnly, "nolocals", config.NoLocals)
+	return config
+}
+
+// BuildInfo describes how the running node was built and configured, allowing
+// operators to verify that a fleet of nodes is homogeneous.
+type BuildInfo struct {
+	Version         string            `json:"version"`
+	Commit          string            `json:"commit,omitempty"`
+	CommitDate      string            `json:"commitDate,omitempty"`
+	Dirty           bool              `json:"dirty,omitempty"`
+	GoVersion       string            `json:"goVersion"`
+	OS              string            `json:"os"`
+	Arch            string            `json:"arch"`
+	BuildTags       []string          `json:"buildTags"`
+	BuildSettings   map[string]string `json:"buildSettings"`
+	Subsystems      map[string]bool   `json:"subsystems"`
+	GenesisHash     common.Hash       `json:"genesisHash"`
+	ChainConfigHash common.Hash       `json:"chainConfigHash"`
+}
+
+// BuildInfo returns the compiler version, build tags, enabled optional
+// subsystems and the hash of the chain configuration in use.
+func (api *AdminAPI) BuildInfo() (*BuildInfo, error) {
+	var (
+		chain  = api.eth.blockchain
+		config = api.eth.config
+	)
+	configHash, err := chainConfigHash(chain.Config())
+	if err != nil {
+		return nil, err
+	}
+	goVersion, tags, settings := version.BuildSettings()
+	if tags == nil {
+		tags = []string{}
+	}
+	info := &BuildInfo{
+		Version:       params.VersionWithMeta,
+		GoVersion:     goVersion,
+		OS:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+		BuildTags:     tags,
+		BuildSettings: settings,
+		Subsystems: map[string]bool{
+			"snapshots":         chain.Snapshots() != nil,
+			"mess":              chain.IsArtificialFinalityEnabled(),
+			"preimages":         config.Preimages,
+			"archive":           config.NoPruning,
+			"txIndexFull":       config.TransactionHistory == 0,
+			"headerAccumulator": api.eth.headerAcc != nil,
+		},
+		GenesisHash:     chain.Genesis().Hash(),
+		ChainConfigHash: configHash,
+	}
+	if vcs, ok := version.VCS(); ok {
+		info.Commit, info.CommitDate, info.Dirty = vcs.Commit, vcs.Date, vcs.Dirty
+	}
+	return info, nil
+}
+
+// chainConfigHash returns the keccak256 hash of the canonical JSON encoding of
+// the chain configuration.
+func chainConfigHash(config ctypes.ChainConfigurator) (common.Hash, error) {
+	blob, err := json.Marshal(config)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to encode chain config: %v", err)
+	}
+	return crypto.Keccak256Hash(blob), nil
+}
+
+// Backup writes a consistent copy of the chain database into the chaindata
+// folder of the given directory, without stopping the node. The directory must
+// be an absolute path which doesn't exist yet. The backup can be restored with
+// the 'geth backup restore' command.
+func (api *AdminAPI) Backup(dest string) (*rawdb.BackupStats, error) {
+	if strings.Contains(dest, "://") {
+		return nil, errors.New("remote backup destinations are not supported, back up to a directory and upload it")
+	}
+	if !filepath.IsAbs(dest) {
+		return nil, errors.New("backup destination must be an absolute path")
+	}
+	if _, err := os.Stat(dest); err == nil {
+		return nil, fmt.Errorf("backup destination %s already exists", dest)
+	}
+	if !api.backup.CompareAndSwap(false, true) {
+		return nil, errors.New("backup already in progress")
+	}
+	defer api.backup.Store(false)
+
+	dir := filepath.Join(dest, "chaindata")
+	db, err := rawdb.Open(rawdb.OpenOptions{
+		Directory:         dir,
+		AncientsDirectory: filepath.Join(dir, "ancient"),
+		Cache:             16,
+		Handles:           16,
+	})
+	if err != nil {
+		return nil, err
+	}
+	stats, err := rawdb.Backup(api.eth.ChainDb(), db)
+	if cerr := db.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.RemoveAll(dest)
+		return nil, err
+	}
+	return stats, nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/confp"
+)
+
+func TestChainConfigHash(t *testing.T) {
+	want, err := chainConfigHash(params.ClassicChainConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone, err := confp.CloneChainConfigurator(params.ClassicChainConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, _ := chainConfigHash(clone); have != want {
+		t.Fatalf("identical configs hash differently: have %x, want %x", have, want)
+	}
+	n := uint64(1)
+	if err := clone.SetECBP1100Transition(&n); err != nil {
+		t.Fatal(err)
+	}
+	if have, _ := chainConfigHash(clone); have == want {
+		t.Fatal("modified config hashes the same as the original")
+	}
+}
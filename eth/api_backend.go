@@ -25,6 +25,7 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
@@ -43,6 +44,10 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// maxStaleStateLookback is the maximum number of blocks to walk back from the
+// chain head looking for available state while serving requests during sync.
+const maxStaleStateLookback = 128
+
 // EthAPIBackend implements ethapi.Backend and tracers.Backend for full nodes
 type EthAPIBackend struct {
 	extRPCEnabled       bool
@@ -66,6 +71,8 @@ func (b *EthAPIBackend) SetHead(number uint64) {
 }
 
 func (b *EthAPIBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	b.markSyncStatus(ctx)
+
 	// Pending block is only known by the miner
 	if number == rpc.PendingBlockNumber {
 		block := b.eth.miner.PendingBlock()
@@ -93,6 +100,8 @@ func (b *EthAPIBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumb
 }
 
 func (b *EthAPIBackend) HeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.Header, error) {
+	b.markSyncStatus(ctx)
+
 	if blockNr, ok := blockNrOrHash.Number(); ok {
 		return b.HeaderByNumber(ctx, blockNr)
 	}
@@ -110,10 +119,14 @@ func (b *EthAPIBackend) HeaderByNumberOrHash(ctx context.Context, blockNrOrHash
 }
 
 func (b *EthAPIBackend) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	b.markSyncStatus(ctx)
+
 	return b.eth.blockchain.GetHeaderByHash(hash), nil
 }
 
 func (b *EthAPIBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	b.markSyncStatus(ctx)
+
 	// Pending block is only known by the miner
 	if number == rpc.PendingBlockNumber {
 		block := b.eth.miner.PendingBlock()
@@ -142,6 +155,8 @@ func (b *EthAPIBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumbe
 }
 
 func (b *EthAPIBackend) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	b.markSyncStatus(ctx)
+
 	return b.eth.blockchain.GetBlockByHash(hash), nil
 }
 
@@ -157,6 +172,8 @@ func (b *EthAPIBackend) GetBody(ctx context.Context, hash common.Hash, number rp
 }
 
 func (b *EthAPIBackend) BlockByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.Block, error) {
+	b.markSyncStatus(ctx)
+
 	if blockNr, ok := blockNrOrHash.Number(); ok {
 		return b.BlockByNumber(ctx, blockNr)
 	}
@@ -182,6 +199,8 @@ func (b *EthAPIBackend) PendingBlockAndReceipts() (*types.Block, types.Receipts)
 }
 
 func (b *EthAPIBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	b.markSyncStatus(ctx)
+
 	// Pending state is only known by the miner
 	if number == rpc.PendingBlockNumber {
 		block, state := b.eth.miner.Pending()
@@ -197,11 +216,52 @@ func (b *EthAPIBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.B
 	}
 	stateDb, err := b.eth.BlockChain().StateAt(header.Root)
 	if err != nil {
+		// During sync, fall back to the most recent state available if allowed
+		if number == rpc.LatestBlockNumber && b.serveWhileSyncing() {
+			return b.latestAvailableState(ctx, header, err)
+		}
 		return nil, nil, err
 	}
 	return stateDb, header, nil
 }
 
+// serveWhileSyncing returns whether requests should be answered from the best
+// available data, as the node is still doing its initial sync.
+func (b *EthAPIBackend) serveWhileSyncing() bool {
+	return b.eth.config.RPCServeWhileSyncing && !b.eth.Synced()
+}
+
+// markSyncStatus attaches the sync status of the node to the HTTP response of a
+// request answered during the initial sync, so that clients can tell how stale
+// the served data might be.
+func (b *EthAPIBackend) markSyncStatus(ctx context.Context) {
+	if !b.serveWhileSyncing() {
+		return
+	}
+	progress := b.eth.Downloader().Progress()
+
+	rpc.SetResponseHeader(ctx, "X-Sync-Status", "syncing")
+	rpc.SetResponseHeader(ctx, "X-Sync-Current-Block", hexutil.EncodeUint64(b.eth.blockchain.CurrentBlock().Number.Uint64()))
+	rpc.SetResponseHeader(ctx, "X-Sync-Highest-Block", hexutil.EncodeUint64(progress.HighestBlock))
+}
+
+// latestAvailableState walks back from the given header to the most recent block
+// with available state. If none is found within a limited distance, the original
+// state retrieval error is returned.
+func (b *EthAPIBackend) latestAvailableState(ctx context.Context, header *types.Header, err error) (*state.StateDB, *types.Header, error) {
+	for i := 0; i < maxStaleStateLookback && header.Number.Sign() > 0; i++ {
+		header = b.eth.blockchain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+		if header == nil {
+			break
+		}
+		if stateDb, serr := b.eth.blockchain.StateAt(header.Root); serr == nil {
+			rpc.SetResponseHeader(ctx, "X-Sync-Served-Block", hexutil.EncodeUint64(header.Number.Uint64()))
+			return stateDb, header, nil
+		}
+	}
+	return nil, nil, err
+}
+
 func (b *EthAPIBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
 	if blockNr, ok := blockNrOrHash.Number(); ok {
 		return b.StateAndHeaderByNumber(ctx, blockNr)
@@ -394,6 +454,10 @@ func (b *EthAPIBackend) RPCTxFeeCap() float64 {
 	return b.eth.config.RPCTxFeeCap
 }
 
+func (b *EthAPIBackend) RPCCallCache() int {
+	return b.eth.config.RPCCallCache
+}
+
 func (b *EthAPIBackend) BloomStatus() (uint64, uint64) {
 	sections, _, _ := b.eth.bloomIndexer.Sections()
 	return vars.BloomBitsBlocks, sections
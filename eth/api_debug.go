@@ -20,10 +20,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/big"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/mmr"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -47,6 +51,7 @@ func NewDebugAPI(eth *Ethereum) *DebugAPI {
 }
 
 // DumpBlock retrieves the entire state of the database at a given block.
+// Large states should be streamed with debug_subscribe("dumpState") instead.
 func (api *DebugAPI) DumpBlock(blockNr rpc.BlockNumber) (state.Dump, error) {
 	opts := &state.DumpConfig{
 		OnlyWithAddresses: true,
@@ -129,11 +134,16 @@ func (api *DebugAPI) GetBadBlocks(ctx context.Context) ([]*BadBlockArgs, error)
 // AccountRangeMaxResults is the maximum number of results to be returned per call
 const AccountRangeMaxResults = 256
 
-// AccountRange enumerates all accounts in the given block and start point in paging request
-func (api *DebugAPI) AccountRange(blockNrOrHash rpc.BlockNumberOrHash, start hexutil.Bytes, maxResults int, nocode, nostorage, incompletes bool) (state.Dump, error) {
-	var stateDb *state.StateDB
-	var err error
+// StorageRangeMaxResults is the maximum number of storage slots to be returned
+// per debug_storageRangeAt call.
+const StorageRangeMaxResults = 1024
 
+// stateAtBlock returns the state at the given block, or the pending state.
+func (api *DebugAPI) stateAtBlock(blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, error) {
+	var (
+		stateDb *state.StateDB
+		err     error
+	)
 	if number, ok := blockNrOrHash.Number(); ok {
 		if number == rpc.PendingBlockNumber {
 			// If we're dumping the pending state, we need to request
@@ -141,7 +151,7 @@ func (api *DebugAPI) AccountRange(blockNrOrHash rpc.BlockNumberOrHash, start hex
 			// the miner and operate on those
 			_, stateDb = api.eth.miner.Pending()
 			if stateDb == nil {
-				return state.Dump{}, errors.New("pending state is not available")
+				return nil, errors.New("pending state is not available")
 			}
 		} else {
 			var header *types.Header
@@ -155,31 +165,45 @@ func (api *DebugAPI) AccountRange(blockNrOrHash rpc.BlockNumberOrHash, start hex
 			default:
 				block := api.eth.blockchain.GetBlockByNumber(uint64(number))
 				if block == nil {
-					return state.Dump{}, fmt.Errorf("block #%d not found", number)
+					return nil, fmt.Errorf("block #%d not found", number)
 				}
 				header = block.Header()
 			}
 			if header == nil {
-				return state.Dump{}, fmt.Errorf("block #%d not found", number)
+				return nil, fmt.Errorf("block #%d not found", number)
 			}
 			stateDb, err = api.eth.BlockChain().StateAt(header.Root)
 			if err != nil {
-				return state.Dump{}, err
+				return nil, err
 			}
 		}
 	} else if hash, ok := blockNrOrHash.Hash(); ok {
 		block := api.eth.blockchain.GetBlockByHash(hash)
 		if block == nil {
-			return state.Dump{}, fmt.Errorf("block %s not found", hash.Hex())
+			return nil, fmt.Errorf("block %s not found", hash.Hex())
 		}
 		stateDb, err = api.eth.BlockChain().StateAt(block.Root())
 		if err != nil {
-			return state.Dump{}, err
+			return nil, err
 		}
 	} else {
-		return state.Dump{}, errors.New("either block number or block hash must be specified")
+		return nil, errors.New("either block number or block hash must be specified")
 	}
+	return stateDb, nil
+}
 
+// AccountRange enumerates all accounts in the given block and start point in paging request.
+//
+// Accounts are returned in the order of their address hashes, served from the
+// snapshot if available and from the state trie otherwise. At most
+// AccountRangeMaxResults accounts are returned per call; if more remain, the
+// result's 'next' field holds the hash to pass as start to fetch the next page.
+// Walking the pages of a fixed block number or hash visits every account once.
+func (api *DebugAPI) AccountRange(blockNrOrHash rpc.BlockNumberOrHash, start hexutil.Bytes, maxResults int, nocode, nostorage, incompletes bool) (state.Dump, error) {
+	stateDb, err := api.stateAtBlock(blockNrOrHash)
+	if err != nil {
+		return state.Dump{}, err
+	}
 	opts := &state.DumpConfig{
 		SkipCode:          nocode,
 		SkipStorage:       nostorage,
@@ -193,6 +217,111 @@ func (api *DebugAPI) AccountRange(blockNrOrHash rpc.BlockNumberOrHash, start hex
 	return stateDb.RawDump(opts), nil
 }
 
+// DumpStateItem is a notification of a debug_subscribe("dumpState") stream.
+type DumpStateItem struct {
+	Account *state.DumpAccount `json:"account,omitempty"`
+	Resume  hexutil.Bytes      `json:"resume,omitempty"` // Pass as 'after' to resume the dump after this item
+	Done    bool               `json:"done,omitempty"`   // Set on the last notification once all accounts were sent
+}
+
+// streamDumpCollector is a state.DumpCollector sending the accounts as
+// subscription notifications.
+type streamDumpCollector struct {
+	notifier *rpc.Notifier
+	sub      *rpc.Subscription
+	abort    chan struct{}
+	stop     sync.Once
+	err      error
+}
+
+// close aborts the dump.
+func (c *streamDumpCollector) close() {
+	c.stop.Do(func() { close(c.abort) })
+}
+
+// OnRoot implements state.DumpCollector.
+func (c *streamDumpCollector) OnRoot(common.Hash) {}
+
+// OnAccount implements state.DumpCollector.
+func (c *streamDumpCollector) OnAccount(addr *common.Address, account state.DumpAccount) {
+	if c.err != nil {
+		return
+	}
+	account.Address = addr
+	if c.err = c.notifier.Notify(c.sub.ID, &DumpStateItem{Account: &account, Resume: account.AddressHash}); c.err != nil {
+		c.close()
+	}
+}
+
+// dumpResumeStart returns the iteration start key following the account with
+// the given resume token.
+func dumpResumeStart(after []byte) ([]byte, error) {
+	if len(after) != common.HashLength {
+		return nil, fmt.Errorf("invalid resume token length %d", len(after))
+	}
+	next := new(big.Int).Add(new(big.Int).SetBytes(after), common.Big1)
+	if next.BitLen() > 8*common.HashLength {
+		return nil, errors.New("resume token is past the last account")
+	}
+	return common.BigToHash(next).Bytes(), nil
+}
+
+// DumpState streams the entire state at the given block as subscription
+// notifications, one per account, in the order of the address hashes. Unlike
+// debug_dumpBlock, the state is never held in memory as a whole, making it
+// suitable for large states.
+//
+// Each notification carries a resume token. If the stream is interrupted, a new
+// subscription with the last received token as 'after' continues right after
+// the last received account. The final notification has 'done' set.
+func (api *DebugAPI) DumpState(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, after *hexutil.Bytes, nocode, nostorage bool) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	stateDb, err := api.stateAtBlock(blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	var start []byte
+	if after != nil {
+		if start, err = dumpResumeStart(*after); err != nil {
+			return nil, err
+		}
+	}
+	rpcSub := notifier.CreateSubscription()
+	collector := &streamDumpCollector{
+		notifier: notifier,
+		sub:      rpcSub,
+		abort:    make(chan struct{}),
+	}
+	go func() {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			stateDb.DumpToCollector(collector, &state.DumpConfig{
+				SkipCode:    nocode,
+				SkipStorage: nostorage,
+				Start:       start,
+				Abort:       collector.abort,
+			})
+		}()
+		select {
+		case <-done:
+			if collector.err == nil {
+				notifier.Notify(rpcSub.ID, &DumpStateItem{Done: true})
+			}
+		case <-rpcSub.Err():
+			collector.close()
+			<-done
+		case <-notifier.Closed():
+			collector.close()
+			<-done
+		}
+	}()
+	return rpcSub, nil
+}
+
 // StorageRangeResult is the result of a debug_storageRangeAt API call.
 type StorageRangeResult struct {
 	Storage storageMap   `json:"storage"`
@@ -207,9 +336,17 @@ type storageEntry struct {
 }
 
 // StorageRangeAt returns the storage at the given block height and transaction index.
+//
+// Slots are returned in the order of their key hashes, at most
+// StorageRangeMaxResults per call. If more remain, the 'nextKey' field holds
+// the hash to pass as keyStart to fetch the next page.
 func (api *DebugAPI) StorageRangeAt(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, txIndex int, contractAddress common.Address, keyStart hexutil.Bytes, maxResult int) (StorageRangeResult, error) {
 	var block *types.Block
 
+	if maxResult > StorageRangeMaxResults || maxResult <= 0 {
+		maxResult = StorageRangeMaxResults
+	}
+
 	block, err := api.eth.APIBackend.BlockByNumberOrHash(ctx, blockNrOrHash)
 	if err != nil {
 		return StorageRangeResult{}, err
@@ -417,6 +554,102 @@ func (api *DebugAPI) GetAccessibleState(from, to rpc.BlockNumber) (uint64, error
 	return 0, errors.New("no state found")
 }
 
+// maxChainScanRange is the maximum number of blocks covered by a single
+// debug_chainStats or debug_unprotectedTransactions call.
+const maxChainScanRange = 10000
+
+// blockRange resolves the block range [from, to] of a chain scan, mapping
+// negative block numbers to the current head, and checks it is within the
+// allowed size.
+func (api *DebugAPI) blockRange(from, to rpc.BlockNumber) (uint64, uint64, error) {
+	resolveNum := func(num rpc.BlockNumber) uint64 {
+		if num < 0 {
+			return api.eth.blockchain.CurrentBlock().Number.Uint64()
+		}
+		return uint64(num)
+	}
+	start, end := resolveNum(from), resolveNum(to)
+	if start > end {
+		return 0, 0, fmt.Errorf("invalid range: from %d > to %d", start, end)
+	}
+	if end-start >= maxChainScanRange {
+		return 0, 0, fmt.Errorf("range too large, maximum is %d blocks", maxChainScanRange)
+	}
+	return start, end, nil
+}
+
+// ChainStats returns per-block statistics (gas used, transaction and uncle
+// counts, difficulty and time since the parent block) of the canonical blocks
+// in the range [from, to]. Larger ranges can be exported using 'geth
+// export-stats'.
+func (api *DebugAPI) ChainStats(from, to rpc.BlockNumber) ([]*core.BlockStats, error) {
+	start, end, err := api.blockRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	var stats []*core.BlockStats
+	err = api.eth.blockchain.IterateBlockStats(start, end, func(s *core.BlockStats) error {
+		stats = append(stats, s)
+		return nil
+	})
+	return stats, err
+}
+
+// UnprotectedTransactions returns the transactions without EIP-155 replay
+// protection sent by the given address in the canonical blocks in the range
+// [from, to]. Such transactions can be replayed on any chain sharing the
+// account's history. Larger ranges can be scanned using 'geth audit-replay'.
+func (api *DebugAPI) UnprotectedTransactions(address common.Address, from, to rpc.BlockNumber) ([]*core.UnprotectedTx, error) {
+	start, end, err := api.blockRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	txs := []*core.UnprotectedTx{}
+	err = api.eth.blockchain.IterateUnprotectedTxs(start, end, &address, func(tx *core.UnprotectedTx) error {
+		txs = append(txs, tx)
+		return nil
+	})
+	return txs, err
+}
+
+// gasTableAt returns the gas table active at the given block. Blocks beyond the
+// current head are allowed, so upcoming forks can be inspected. Their timestamp
+// is assumed to be that of the head.
+func (api *DebugAPI) gasTableAt(number rpc.BlockNumber) (*GasTable, error) {
+	header := api.eth.blockchain.CurrentBlock()
+	if number >= 0 && uint64(number) <= header.Number.Uint64() {
+		header = api.eth.blockchain.GetHeaderByNumber(uint64(number))
+		if header == nil {
+			return nil, fmt.Errorf("block #%d not found", number)
+		}
+	}
+	num := header.Number.Uint64()
+	if number >= 0 {
+		num = uint64(number)
+	}
+	return gasTableAt(api.eth.blockchain.Config(), num, header.Time)
+}
+
+// GasTable returns the gas costs of all opcodes, as well as other gas parameters,
+// active at the given block.
+func (api *DebugAPI) GasTable(number rpc.BlockNumber) (*GasTable, error) {
+	return api.gasTableAt(number)
+}
+
+// GasTableDiff returns the gas costs differing between the given blocks, e.g.
+// the last block before a fork and the fork block.
+func (api *DebugAPI) GasTableDiff(from, to rpc.BlockNumber) (*GasTableDiff, error) {
+	fromTable, err := api.gasTableAt(from)
+	if err != nil {
+		return nil, err
+	}
+	toTable, err := api.gasTableAt(to)
+	if err != nil {
+		return nil, err
+	}
+	return diffGasTables(fromTable, toTable), nil
+}
+
 // SetTrieFlushInterval configures how often in-memory tries are persisted
 // to disk. The value is in terms of block processing time, not wall clock.
 // If the value is shorter than the block generation time, or even 0 or negative,
@@ -440,3 +673,19 @@ func (api *DebugAPI) GetTrieFlushInterval() (string, error) {
 	}
 	return api.eth.blockchain.GetTrieFlushInterval().String(), nil
 }
+
+// BlockProcessingStats returns the timing breakdown of importing the n most
+// recently imported blocks, newest first. Durations are in nanoseconds.
+func (api *DebugAPI) BlockProcessingStats(n int) []*core.BlockProcessingStats {
+	return api.eth.blockchain.BlockProcessingStats(n)
+}
+
+// GetHeaderProof returns an inclusion proof of the canonical block with the given
+// number in the header accumulator, relative to its current root. The leaves of
+// the accumulator are the canonical block hashes, indexed by block number.
+func (api *DebugAPI) GetHeaderProof(number hexutil.Uint64) (*mmr.Proof, error) {
+	if api.eth.headerAcc == nil {
+		return nil, errHeaderAccDisabled
+	}
+	return api.eth.headerAcc.mmr.Prove(uint64(number))
+}
@@ -159,6 +159,60 @@ func TestEmptyAccountRange(t *testing.T) {
 	}
 }
 
+func TestDumpResumeStart(t *testing.T) {
+	t.Parallel()
+
+	for i, tc := range []struct {
+		after   []byte
+		want    common.Hash
+		wantErr bool
+	}{
+		{after: common.Hash{}.Bytes(), want: common.HexToHash("0x01")},
+		{after: common.HexToHash("0x01ff").Bytes(), want: common.HexToHash("0x0200")},
+		{after: common.HexToHash("0xfe" + strings.Repeat("ff", 31)).Bytes(), want: common.HexToHash("0xff" + strings.Repeat("00", 31))},
+		{after: common.HexToHash("0x" + strings.Repeat("ff", 32)).Bytes(), wantErr: true},
+		{after: []byte{0x01}, wantErr: true},
+	} {
+		start, err := dumpResumeStart(tc.after)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("test %d: expected error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: unexpected error: %v", i, err)
+			continue
+		}
+		if have := common.BytesToHash(start); have != tc.want {
+			t.Errorf("test %d: start mismatch, have %x, want %x", i, have, tc.want)
+		}
+	}
+}
+
+func TestDumpAbort(t *testing.T) {
+	t.Parallel()
+
+	var (
+		statedb = state.NewDatabaseWithConfig(rawdb.NewMemoryDatabase(), &triedb.Config{Preimages: true})
+		sdb, _  = state.New(types.EmptyRootHash, statedb, nil)
+	)
+	for i := byte(1); i <= 10; i++ {
+		sdb.SetBalance(common.BytesToAddress([]byte{i}), uint256.NewInt(1))
+	}
+	root, _ := sdb.Commit(0, true)
+	sdb, _ = state.New(root, statedb, nil)
+
+	abort := make(chan struct{})
+	close(abort)
+	if dump := sdb.RawDump(&state.DumpConfig{Abort: abort}); len(dump.Accounts) != 0 || dump.Next != nil {
+		t.Errorf("aborted dump returned %d accounts", len(dump.Accounts))
+	}
+	if dump := sdb.RawDump(&state.DumpConfig{Abort: make(chan struct{})}); len(dump.Accounts) != 10 {
+		t.Errorf("dump returned %d accounts, want 10", len(dump.Accounts))
+	}
+}
+
 func TestStorageRangeAt(t *testing.T) {
 	t.Parallel()
 
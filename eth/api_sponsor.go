@@ -0,0 +1,90 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/miner"
+)
+
+// errSponsorNotMining is returned if a sponsored bundle is submitted to a node
+// which is not building blocks itself, so the bundle would never be included.
+var errSponsorNotMining = errors.New("sponsored bundles require a local miner")
+
+// SponsoredBundle is the RPC representation of a sponsored transaction bundle.
+type SponsoredBundle struct {
+	Sponsor common.Hash    `json:"sponsor"`
+	User    common.Hash    `json:"user"`
+	Number  hexutil.Uint64 `json:"number"`
+}
+
+func newSponsoredBundle(bundle *miner.SponsoredBundle) *SponsoredBundle {
+	return &SponsoredBundle{
+		Sponsor: bundle.Sponsor.Hash(),
+		User:    bundle.User.Hash(),
+		Number:  hexutil.Uint64(bundle.Number),
+	}
+}
+
+// SponsorAPI is an experimental API to submit sponsored transactions: a user
+// transaction paired with a sponsor transaction enabling it, such as one funding
+// the user account. The pair is included atomically and adjacently in blocks
+// built by the local miner, bypassing the transaction pool. The API is only
+// served if sponsored bundles are enabled in the miner config.
+type SponsorAPI struct {
+	eth *Ethereum
+}
+
+// NewSponsorAPI creates a new instance of SponsorAPI.
+func NewSponsorAPI(eth *Ethereum) *SponsorAPI {
+	return &SponsorAPI{eth: eth}
+}
+
+// SendBundle validates the user and sponsor transactions by executing the
+// sponsor transaction followed by the user one on the pending state, and queues
+// them for inclusion in the same order if both succeed.
+func (api *SponsorAPI) SendBundle(userTx, sponsorTx hexutil.Bytes) (*SponsoredBundle, error) {
+	if !api.eth.IsMining() {
+		return nil, errSponsorNotMining
+	}
+	user, sponsor := new(types.Transaction), new(types.Transaction)
+	if err := user.UnmarshalBinary(userTx); err != nil {
+		return nil, err
+	}
+	if err := sponsor.UnmarshalBinary(sponsorTx); err != nil {
+		return nil, err
+	}
+	bundle, err := api.eth.Miner().AddSponsoredBundle(sponsor, user)
+	if err != nil {
+		return nil, err
+	}
+	return newSponsoredBundle(bundle), nil
+}
+
+// PendingBundles returns the sponsored bundles waiting for inclusion.
+func (api *SponsorAPI) PendingBundles() []*SponsoredBundle {
+	bundles := api.eth.Miner().SponsoredBundles()
+	result := make([]*SponsoredBundle, 0, len(bundles))
+	for _, bundle := range bundles {
+		result = append(result, newSponsoredBundle(bundle))
+	}
+	return result
+}
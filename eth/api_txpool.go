@@ -0,0 +1,142 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TxPoolDropsAPI offers a lookback into the transactions recently dropped from
+// the transaction pool.
+type TxPoolDropsAPI struct {
+	eth *Ethereum
+}
+
+// NewTxPoolDropsAPI creates a new instance of TxPoolDropsAPI.
+func NewTxPoolDropsAPI(eth *Ethereum) *TxPoolDropsAPI {
+	return &TxPoolDropsAPI{eth: eth}
+}
+
+// DroppedTransactions returns the transactions dropped from the pool without
+// being included in a block since the given unix timestamp, oldest first. Only
+// the most recent drops are remembered, older ones are not reported.
+func (api *TxPoolDropsAPI) DroppedTransactions(since hexutil.Uint64) []*txpool.DroppedTransaction {
+	return api.eth.txPool.DroppedSince(time.Unix(int64(since), 0))
+}
+
+// PooledTransaction is a transaction of the pool in a portable format, used to
+// migrate the pool content between nodes.
+type PooledTransaction struct {
+	Raw    hexutil.Bytes  `json:"raw"`    // Canonical encoding of the transaction
+	Hash   common.Hash    `json:"hash"`   // Informational, not verified on import
+	From   common.Address `json:"from"`   // Informational, not verified on import
+	Nonce  hexutil.Uint64 `json:"nonce"`  // Informational, not verified on import
+	Queued bool           `json:"queued"` // Whether the transaction was not executable
+}
+
+// TxPoolImportResult reports the outcome of importing pooled transactions.
+type TxPoolImportResult struct {
+	Imported int                    `json:"imported"`
+	Errors   map[common.Hash]string `json:"errors,omitempty"`
+}
+
+// TxPoolMigrationAPI offers exporting and importing the content of the
+// transaction pool, so it is not lost when migrating to another node.
+type TxPoolMigrationAPI struct {
+	eth *Ethereum
+}
+
+// NewTxPoolMigrationAPI creates a new instance of TxPoolMigrationAPI.
+func NewTxPoolMigrationAPI(eth *Ethereum) *TxPoolMigrationAPI {
+	return &TxPoolMigrationAPI{eth: eth}
+}
+
+// Export returns all pending and queued transactions of the pool, ordered by
+// sender and nonce.
+func (api *TxPoolMigrationAPI) Export() ([]*PooledTransaction, error) {
+	pending, queued := api.eth.txPool.Content()
+
+	var txs []*PooledTransaction
+	for _, content := range []struct {
+		txs    map[common.Address][]*types.Transaction
+		queued bool
+	}{{pending, false}, {queued, true}} {
+		for from, list := range content.txs {
+			for _, tx := range list {
+				raw, err := tx.MarshalBinary()
+				if err != nil {
+					return nil, err
+				}
+				txs = append(txs, &PooledTransaction{
+					Raw:    raw,
+					Hash:   tx.Hash(),
+					From:   from,
+					Nonce:  hexutil.Uint64(tx.Nonce()),
+					Queued: content.queued,
+				})
+			}
+		}
+	}
+	sortPooledTransactions(txs)
+	return txs, nil
+}
+
+// Import adds exported transactions to the pool as local transactions, so they
+// are retained regardless of the pricing limits of this node. Transactions the
+// pool rejects are reported by hash along with the reason.
+func (api *TxPoolMigrationAPI) Import(txs []*PooledTransaction) (*TxPoolImportResult, error) {
+	// Submit the transactions of each sender in nonce order to avoid needlessly
+	// queueing them
+	sortPooledTransactions(txs)
+
+	var (
+		result  = &TxPoolImportResult{Errors: make(map[common.Hash]string)}
+		decoded = make([]*types.Transaction, 0, len(txs))
+	)
+	for _, entry := range txs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(entry.Raw); err != nil {
+			result.Errors[entry.Hash] = err.Error()
+			continue
+		}
+		decoded = append(decoded, tx)
+	}
+	for i, err := range api.eth.txPool.Add(decoded, true, true) {
+		if err != nil {
+			result.Errors[decoded[i].Hash()] = err.Error()
+			continue
+		}
+		result.Imported++
+	}
+	return result, nil
+}
+
+// sortPooledTransactions orders pooled transactions by sender and nonce.
+func sortPooledTransactions(txs []*PooledTransaction) {
+	sort.SliceStable(txs, func(i, j int) bool {
+		if txs[i].From != txs[j].From {
+			return txs[i].From.Cmp(txs[j].From) < 0
+		}
+		return txs[i].Nonce < txs[j].Nonce
+	})
+}
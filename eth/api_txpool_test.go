@@ -0,0 +1,48 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestSortPooledTransactions(t *testing.T) {
+	var (
+		a = common.HexToAddress("0x01")
+		b = common.HexToAddress("0x02")
+	)
+	txs := []*PooledTransaction{
+		{From: b, Nonce: 0},
+		{From: a, Nonce: 2},
+		{From: a, Nonce: 0, Queued: false},
+		{From: b, Nonce: 5, Queued: true},
+		{From: a, Nonce: 1},
+	}
+	sortPooledTransactions(txs)
+
+	want := []struct {
+		from  common.Address
+		nonce uint64
+	}{{a, 0}, {a, 1}, {a, 2}, {b, 0}, {b, 5}}
+	for i, w := range want {
+		if txs[i].From != w.from || uint64(txs[i].Nonce) != w.nonce {
+			t.Errorf("tx %d: have %x/%d, want %x/%d", i, txs[i].From, txs[i].Nonce, w.from, w.nonce)
+		}
+	}
+}
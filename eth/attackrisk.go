@@ -0,0 +1,370 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// attackRiskMemory is how long observed reorgs contribute to the risk.
+	attackRiskMemory = time.Hour
+
+	// deepReorgDepth is the reorg depth considered a certain attack.
+	deepReorgDepth = 20
+
+	// maxReorgSearch is the maximum depth searched for the common ancestor of a
+	// reorg. Deeper reorgs are reported with this depth.
+	maxReorgSearch = 1024
+
+	// privateBranchAge is the age of a revealed branch considered a certain
+	// attack. Honest competing branches are at most a few blocks old.
+	privateBranchAge = 10 * time.Minute
+
+	// minPrivateBranchDepth is the minimum reorg depth considered for private
+	// branch detection, excluding ordinary uncle races.
+	minPrivateBranchDepth = 3
+
+	// difficultyShortWindow and difficultyLongWindow are the number of blocks
+	// whose average difficulties are compared to detect difficulty swings.
+	difficultyShortWindow = 10
+	difficultyLongWindow  = 200
+
+	// maxDifficultySwing is the relative difficulty change considered a certain
+	// attack, as hashrate rented for an attack raises the difficulty quickly.
+	maxDifficultySwing = 0.5
+
+	// attackRiskWebhookTimeout is the timeout of a webhook notification.
+	attackRiskWebhookTimeout = 10 * time.Second
+)
+
+// Risk levels and the number of confirmations recommended for each of them.
+const (
+	attackRiskLow      = "low"
+	attackRiskElevated = "elevated"
+	attackRiskHigh     = "high"
+)
+
+var attackRiskConfirmations = map[string]uint64{
+	attackRiskLow:      120,
+	attackRiskElevated: 1000,
+	attackRiskHigh:     5000,
+}
+
+// AttackRisk is the estimated risk of an ongoing majority attack on the chain.
+type AttackRisk struct {
+	Score         float64 `json:"score"`         // Combined risk between 0 and 1
+	Level         string  `json:"level"`         // "low", "elevated" or "high"
+	Confirmations uint64  `json:"confirmations"` // Recommended confirmations for deposits
+	MESS          bool    `json:"mess"`          // Whether ECBP1100 (MESS) protects the node
+
+	DeepestReorg     uint64  `json:"deepestReorg"`     // Deepest reorg within the last hour
+	PrivateBranchAge uint64  `json:"privateBranchAge"` // Age in seconds of the oldest revealed branch
+	TDJump           float64 `json:"tdJump"`           // Work of that branch relative to the dropped one
+	DifficultySwing  float64 `json:"difficultySwing"`  // Relative change of the recent difficulty
+	HeldReorgs       int     `json:"heldReorgs"`       // Reorgs refused for exceeding --maxreorg
+}
+
+// reorgObservation is a reorg seen by the detector.
+type reorgObservation struct {
+	time   time.Time
+	depth  uint64        // Number of dropped canonical blocks
+	age    time.Duration // Age of the first block of the new branch when revealed
+	tdJump float64       // Work of the new branch over the dropped one
+}
+
+// attackDetector follows the chain head, combining deep reorgs, revealed private
+// branches and difficulty swings into a single attack risk. Whenever the risk
+// level rises, a notification is posted to the configured webhook.
+type attackDetector struct {
+	chain   *core.BlockChain
+	webhook string
+	client  *http.Client
+
+	lock   sync.Mutex
+	head   *types.Header
+	reorgs []reorgObservation
+	risk   AttackRisk
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newAttackDetector creates a detector for the chain, notifying the given
+// webhook URL (if not empty) about rising risk levels.
+func newAttackDetector(chain *core.BlockChain, webhook string) *attackDetector {
+	return &attackDetector{
+		chain:   chain,
+		webhook: webhook,
+		client:  &http.Client{Timeout: attackRiskWebhookTimeout},
+		head:    chain.CurrentHeader(),
+		risk:    AttackRisk{Level: attackRiskLow, Confirmations: attackRiskConfirmations[attackRiskLow]},
+		quit:    make(chan struct{}),
+	}
+}
+
+// start launches the background goroutine following the chain head.
+func (d *attackDetector) start() {
+	d.wg.Add(1)
+	go d.loop()
+}
+
+// stop terminates the background goroutine.
+func (d *attackDetector) stop() {
+	close(d.quit)
+	d.wg.Wait()
+}
+
+// loop updates the risk on every new chain head.
+func (d *attackDetector) loop() {
+	defer d.wg.Done()
+
+	heads := make(chan core.ChainHeadEvent, 10)
+	sub := d.chain.SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	held := make(chan core.ReorgHeldEvent, 10)
+	heldSub := d.chain.SubscribeReorgHeldEvent(held)
+	defer heldSub.Unsubscribe()
+
+	for {
+		select {
+		case ev := <-heads:
+			d.update(ev.Block.Header(), time.Now())
+		case ev := <-held:
+			d.hold(ev.Reorg, time.Now())
+		case <-sub.Err():
+			return
+		case <-heldSub.Err():
+			return
+		case <-d.quit:
+			return
+		}
+	}
+}
+
+// Risk returns the current attack risk.
+func (d *attackDetector) Risk() AttackRisk {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.risk
+}
+
+// update processes a new chain head, recording a reorg if it doesn't extend the
+// previous one, and recomputes the risk.
+func (d *attackDetector) update(head *types.Header, now time.Time) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	// Head events are fired per imported batch, so only a head which is not a
+	// descendant of the previous one indicates a reorg.
+	if d.head != nil && d.chain.GetCanonicalHash(d.head.Number.Uint64()) != d.head.Hash() {
+		if obs, ok := d.observeReorg(d.head, head, now); ok {
+			d.reorgs = append(d.reorgs, obs)
+		}
+	}
+	d.head = head
+
+	// Forget old reorgs and compute the new risk
+	for len(d.reorgs) > 0 && now.Sub(d.reorgs[0].time) > attackRiskMemory {
+		d.reorgs = d.reorgs[1:]
+	}
+	var (
+		prev = d.risk
+		mess = d.chain.IsArtificialFinalityEnabled() &&
+			d.chain.Config().IsEnabled(d.chain.Config().GetECBP1100Transition, head.Number)
+	)
+	d.risk = computeAttackRisk(d.reorgs, d.difficultySwing(head), mess)
+	d.risk.HeldReorgs = len(d.chain.HeldReorgs())
+
+	if riskLevelRank(d.risk.Level) > riskLevelRank(prev.Level) {
+		log.Warn("Attack risk increased", "level", d.risk.Level, "score", d.risk.Score, "reorg", d.risk.DeepestReorg,
+			"branchage", d.risk.PrivateBranchAge, "swing", d.risk.DifficultySwing, "mess", d.risk.MESS)
+		if d.webhook != "" {
+			go d.notify(d.risk)
+		}
+	}
+}
+
+// hold records a reorg refused for exceeding the maximum reorg depth. As such a
+// reorg is an alarm on its own, the webhook is notified regardless of the risk
+// level.
+func (d *attackDetector) hold(reorg core.HeldReorg, now time.Time) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.reorgs = append(d.reorgs, reorgObservation{time: now, depth: reorg.Depth})
+	d.risk = computeAttackRisk(d.reorgs, d.difficultySwing(d.head), d.risk.MESS)
+	d.risk.HeldReorgs = len(d.chain.HeldReorgs())
+
+	if d.webhook != "" {
+		go d.notify(d.risk)
+	}
+}
+
+// observeReorg measures the reorg from the old to the new head.
+func (d *attackDetector) observeReorg(oldHead, newHead *types.Header, now time.Time) (reorgObservation, bool) {
+	var (
+		oldBlock, newBlock = oldHead, newHead
+		first              *types.Header // First block of the new branch
+	)
+	for steps := 0; oldBlock.Hash() != newBlock.Hash(); steps++ {
+		if steps >= maxReorgSearch {
+			return reorgObservation{time: now, depth: maxReorgSearch}, true
+		}
+		if newBlock.Number.Uint64() >= oldBlock.Number.Uint64() {
+			first = newBlock
+			newBlock = d.chain.GetHeader(newBlock.ParentHash, newBlock.Number.Uint64()-1)
+		} else {
+			oldBlock = d.chain.GetHeader(oldBlock.ParentHash, oldBlock.Number.Uint64()-1)
+		}
+		if oldBlock == nil || newBlock == nil {
+			return reorgObservation{}, false
+		}
+	}
+	ancestor := oldBlock
+	if ancestor.Hash() == oldHead.Hash() {
+		return reorgObservation{}, false // plain extension of the chain
+	}
+	obs := reorgObservation{
+		time:  now,
+		depth: oldHead.Number.Uint64() - ancestor.Number.Uint64(),
+	}
+	if first != nil && now.Unix() > int64(first.Time) {
+		obs.age = time.Duration(now.Unix()-int64(first.Time)) * time.Second
+	}
+	var (
+		ancestorTD = d.chain.GetTd(ancestor.Hash(), ancestor.Number.Uint64())
+		oldTD      = d.chain.GetTd(oldHead.Hash(), oldHead.Number.Uint64())
+		newTD      = d.chain.GetTd(newHead.Hash(), newHead.Number.Uint64())
+	)
+	if ancestorTD != nil && oldTD != nil && newTD != nil {
+		dropped := new(big.Int).Sub(oldTD, ancestorTD)
+		added := new(big.Int).Sub(newTD, ancestorTD)
+		if dropped.Sign() > 0 {
+			obs.tdJump, _ = new(big.Float).Quo(new(big.Float).SetInt(added), new(big.Float).SetInt(dropped)).Float64()
+		}
+	}
+	return obs, true
+}
+
+// difficultySwing returns the relative change of the average difficulty of the
+// most recent blocks compared to a longer window.
+func (d *attackDetector) difficultySwing(head *types.Header) float64 {
+	var (
+		short, long = new(big.Int), new(big.Int)
+		header      = head
+		count       int
+	)
+	for header != nil && count < difficultyLongWindow {
+		if count < difficultyShortWindow {
+			short.Add(short, header.Difficulty)
+		}
+		long.Add(long, header.Difficulty)
+		count++
+
+		if header.Number.Sign() == 0 {
+			break
+		}
+		header = d.chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	}
+	if count < difficultyLongWindow || long.Sign() == 0 {
+		return 0 // not enough history, or not proof-of-work
+	}
+	ratio, _ := new(big.Float).Quo(
+		new(big.Float).Mul(new(big.Float).SetInt(short), big.NewFloat(difficultyLongWindow)),
+		new(big.Float).Mul(new(big.Float).SetInt(long), big.NewFloat(difficultyShortWindow)),
+	).Float64()
+	return math.Abs(ratio - 1)
+}
+
+// computeAttackRisk combines the observations into a single risk. Each signal
+// is scored between 0 and 1, and the scores are combined as independent
+// probabilities. MESS makes deep reorgs of the local chain very expensive, so
+// the reorg based signals are halved while it is active.
+func computeAttackRisk(reorgs []reorgObservation, swing float64, mess bool) AttackRisk {
+	risk := AttackRisk{MESS: mess, DifficultySwing: swing}
+	for _, obs := range reorgs {
+		risk.DeepestReorg = max(risk.DeepestReorg, obs.depth)
+		if obs.depth >= minPrivateBranchDepth && uint64(obs.age/time.Second) > risk.PrivateBranchAge {
+			risk.PrivateBranchAge = uint64(obs.age / time.Second)
+			risk.TDJump = obs.tdJump
+		}
+	}
+	var (
+		reorgScore      = math.Min(1, float64(risk.DeepestReorg)/deepReorgDepth)
+		privateScore    = math.Min(1, float64(risk.PrivateBranchAge)/privateBranchAge.Seconds())
+		difficultyScore = math.Min(1, swing/maxDifficultySwing)
+	)
+	if mess {
+		reorgScore /= 2
+		privateScore /= 2
+	}
+	risk.Score = 1 - (1-reorgScore)*(1-privateScore)*(1-difficultyScore)
+
+	switch {
+	case risk.Score >= 0.6:
+		risk.Level = attackRiskHigh
+	case risk.Score >= 0.25:
+		risk.Level = attackRiskElevated
+	default:
+		risk.Level = attackRiskLow
+	}
+	risk.Confirmations = attackRiskConfirmations[risk.Level]
+	return risk
+}
+
+// riskLevelRank orders the risk levels.
+func riskLevelRank(level string) int {
+	switch level {
+	case attackRiskHigh:
+		return 2
+	case attackRiskElevated:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// notify posts the risk to the webhook.
+func (d *attackDetector) notify(risk AttackRisk) {
+	body, err := json.Marshal(risk)
+	if err != nil {
+		return
+	}
+	resp, err := d.client.Post(d.webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Warn("Failed to send attack risk notification", "err", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		log.Warn("Failed to send attack risk notification", "err", fmt.Sprintf("server returned %s", resp.Status))
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestComputeAttackRisk(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		reorgs []reorgObservation
+		swing  float64
+		mess   bool
+		level  string
+	}{
+		{name: "quiet", level: attackRiskLow},
+		{
+			name:   "uncle race",
+			reorgs: []reorgObservation{{time: now, depth: 1, age: 15 * time.Second, tdJump: 1.01}},
+			level:  attackRiskLow,
+		},
+		{
+			name:   "deep reorg",
+			reorgs: []reorgObservation{{time: now, depth: 10, age: 30 * time.Second, tdJump: 1.1}},
+			level:  attackRiskElevated,
+		},
+		{
+			name:   "private branch",
+			reorgs: []reorgObservation{{time: now, depth: 30, age: time.Hour, tdJump: 1.5}},
+			level:  attackRiskHigh,
+		},
+		{
+			name:   "short private branch",
+			reorgs: []reorgObservation{{time: now, depth: 12, age: 5 * time.Minute, tdJump: 1.2}},
+			level:  attackRiskHigh,
+		},
+		{
+			name:   "short private branch with mess",
+			reorgs: []reorgObservation{{time: now, depth: 12, age: 5 * time.Minute, tdJump: 1.2}},
+			mess:   true,
+			level:  attackRiskElevated,
+		},
+		{name: "difficulty swing", swing: 0.4, level: attackRiskHigh},
+	}
+	for _, tt := range tests {
+		risk := computeAttackRisk(tt.reorgs, tt.swing, tt.mess)
+		if risk.Level != tt.level {
+			t.Errorf("%s: wrong level %q (score %f), want %q", tt.name, risk.Level, risk.Score, tt.level)
+		}
+		if risk.Confirmations != attackRiskConfirmations[tt.level] {
+			t.Errorf("%s: wrong confirmations %d", tt.name, risk.Confirmations)
+		}
+		if risk.MESS != tt.mess {
+			t.Errorf("%s: wrong mess status %v", tt.name, risk.MESS)
+		}
+	}
+	// The reported private branch is the oldest one of sufficient depth.
+	risk := computeAttackRisk([]reorgObservation{
+		{time: now, depth: 5, age: 3 * time.Minute, tdJump: 1.2},
+		{time: now, depth: 2, age: time.Hour, tdJump: 2},
+		{time: now, depth: 4, age: 5 * time.Minute, tdJump: 1.3},
+	}, 0, false)
+	if risk.DeepestReorg != 5 || risk.PrivateBranchAge != 300 || risk.TDJump != 1.3 {
+		t.Errorf("wrong observations: %+v", risk)
+	}
+}
+
+func TestAttackRiskWebhook(t *testing.T) {
+	received := make(chan AttackRisk, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var risk AttackRisk
+		if err := json.NewDecoder(r.Body).Decode(&risk); err != nil {
+			t.Error(err)
+		}
+		received <- risk
+	}))
+	defer srv.Close()
+
+	d := &attackDetector{webhook: srv.URL, client: srv.Client()}
+	d.notify(AttackRisk{Level: attackRiskHigh, Confirmations: 5000, DeepestReorg: 42})
+
+	select {
+	case risk := <-received:
+		if risk.Level != attackRiskHigh || risk.DeepestReorg != 42 {
+			t.Errorf("wrong notification: %+v", risk)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("notification not received")
+	}
+}
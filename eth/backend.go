@@ -95,6 +95,15 @@ type Ethereum struct {
 	APIBackend *EthAPIBackend
 
 	miner     *miner.Miner
+	txRelay   *txRelay
+	headerAcc *headerAccumulator // Optional accumulator over the canonical header chain
+	attacks   *attackDetector    // Majority attack risk detector
+	clock     *clockMonitor      // Optional local clock drift monitor
+	governor  *importGovernor    // Optional block import admission controller
+	analysis  *vm.AnalysisCache  // Optional JUMPDEST analysis cache shared across transactions
+
+	analysisJournal string // Resolved path of the analysis cache journal, if any
+
 	gasPrice  *big.Int
 	etherbase common.Address
 
@@ -134,6 +143,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	log.Info("Allocated trie memory caches", "clean", common.StorageSize(config.TrieCleanCache)*1024*1024, "dirty", common.StorageSize(config.TrieDirtyCache)*1024*1024)
 
 	// Assemble the Ethereum object
+	if err := rawdb.SetAncientCompression(config.AncientCompression); err != nil {
+		return nil, err
+	}
 	chainDb, err := stack.OpenDatabaseWithFreezer("chaindata", config.DatabaseCache, config.DatabaseHandles, config.DatabaseFreezer, "eth/db/chaindata/", false)
 	if err != nil {
 		return nil, err
@@ -152,28 +164,25 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	ethashConfig := config.Ethash
 	ethashConfig.NotifyFull = config.Miner.NotifyFull
 
-	if config.Genesis != nil && config.Genesis.Config != nil {
-		ethashConfig.ECIP1099Block = config.Genesis.GetEthashECIP1099Transition()
-	}
-
 	cliqueConfig, err := core.LoadCliqueConfig(chainDb, config.Genesis)
 	if err != nil {
 		return nil, err
 	}
+	// The consensus engine is picked from the stored chain config if there
+	// is one, so that private networks initialized from a genesis file
+	// selecting their engine do not need to pass it again on every start.
+	chainConfig, err := core.LoadChainConfig(chainDb, config.Genesis)
+	if err != nil {
+		return nil, err
+	}
+	ethashConfig.ECIP1099Block = chainConfig.GetEthashECIP1099Transition()
 
 	var lyra2Config *lyra2.Config
-	if config.Genesis != nil && config.Genesis.Config != nil {
-		if config.Genesis.Config.GetConsensusEngineType() == ctypes.ConsensusEngineT_Lyra2 {
-			lyra2Config = &lyra2.Config{}
-		}
+	if chainConfig.GetConsensusEngineType() == ctypes.ConsensusEngineT_Lyra2 {
+		lyra2Config = &lyra2.Config{}
 	}
 
 	engine := ethconfig.CreateConsensusEngine(stack, &ethashConfig, cliqueConfig, lyra2Config, config.Miner.Notify, config.Miner.Noverify, chainDb)
-
-	chainConfig, err := core.LoadChainConfig(chainDb, config.Genesis)
-	if err != nil {
-		return nil, err
-	}
 	networkID := config.NetworkId
 	if networkID == 0 {
 		networkID = chainConfig.GetChainID().Uint64()
@@ -220,6 +229,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		cacheConfig = &core.CacheConfig{
 			TrieCleanLimit:      config.TrieCleanCache,
 			TrieCleanNoPrefetch: config.NoPrefetch,
+			PrefetchDistance:    config.PrefetchDistance,
+			PrefetchWorkers:     config.PrefetchWorkers,
+			TrieWorkers:         config.TrieWorkers,
 			TrieDirtyLimit:      config.TrieDirtyCache,
 			TrieDirtyDisabled:   config.NoPruning,
 			TrieTimeLimit:       config.TrieTimeout,
@@ -227,8 +239,25 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 			Preimages:           config.Preimages,
 			StateHistory:        config.StateHistory,
 			StateScheme:         scheme,
+			LogIndex:            config.LogIndex,
 		}
 	)
+	// Restrict the transaction index to the configured addresses, if any, keeping
+	// the local accounts indexed as well.
+	if len(config.TxLookupAddresses) > 0 {
+		cacheConfig.TxLookupAddresses = append(append([]common.Address{}, config.TxLookupAddresses...), config.TxPool.Locals...)
+	}
+	if config.AnalysisCache > 0 {
+		eth.analysis = vm.NewAnalysisCache(uint64(config.AnalysisCache) * 1024 * 1024)
+		if config.AnalysisJournal != "" {
+			eth.analysisJournal = stack.ResolvePath(config.AnalysisJournal)
+			if err := eth.analysis.Load(eth.analysisJournal); err != nil {
+				log.Warn("Failed to load analysis cache journal", "err", err)
+			}
+		}
+		log.Info("Enabled shared code analysis cache", "size", config.AnalysisCache, "loaded", eth.analysis.Len())
+		vm.SetAnalysisCache(eth.analysis)
+	}
 	// Override the chain config with provided settings.
 	var overrides core.ChainOverrides
 	if config.OverrideCancun != nil {
@@ -259,6 +288,18 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 			eth.blockchain.ArtificialFinalityNoDisable(1)
 		}
 	}
+	if config.MaxReorgDepth > 0 {
+		log.Info("Limiting automatic reorgs", "maxdepth", config.MaxReorgDepth)
+		eth.blockchain.SetMaxReorgDepth(config.MaxReorgDepth)
+	}
+	// The binary trie state layout can neither be snap synced nor served.
+	if eth.blockchain.TrieDB().IsBinary() {
+		if config.SyncMode == downloader.SnapSync {
+			log.Warn("Snap sync is unsupported by the binary trie state layout, using full sync")
+			config.SyncMode = downloader.FullSync
+		}
+		config.SnapshotCache = 0
+	}
 
 	if config.BlobPool.Datadir != "" {
 		config.BlobPool.Datadir = stack.ResolvePath(config.BlobPool.Datadir)
@@ -268,6 +309,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
 	}
+	if config.TxPool.PersistFile != "" {
+		config.TxPool.PersistFile = stack.ResolvePath(config.TxPool.PersistFile)
+	}
 	legacyPool := legacypool.New(config.TxPool, eth.blockchain)
 
 	eth.txPool, err = txpool.New(config.TxPool.PriceLimit, eth.blockchain, []txpool.SubPool{legacyPool, blobPool})
@@ -284,6 +328,16 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 			checkpoint = p.TrustedCheckpoint
 		}
 	}
+	// Peers are challenged for the fork block hashes required by the chain
+	// config too, so that nodes on the other side of a contentious fork (e.g.
+	// ETH for ETC, sharing its genesis) can't serve the sync.
+	requiredBlocks := make(map[uint64]common.Hash)
+	for number, hash := range eth.blockchain.Config().GetForkCanonHashes() {
+		requiredBlocks[number] = hash
+	}
+	for number, hash := range config.RequiredBlocks {
+		requiredBlocks[number] = hash
+	}
 	if eth.handler, err = newHandler(&handlerConfig{
 		Database:       chainDb,
 		Chain:          eth.blockchain,
@@ -294,11 +348,26 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		BloomCache:     uint64(cacheLimit),
 		EventMux:       eth.eventMux,
 		Checkpoint:     checkpoint,
-		RequiredBlocks: config.RequiredBlocks,
+		RequiredBlocks: requiredBlocks,
+		TxGossip:       config.TxGossip,
 	}); err != nil {
 		return nil, err
 	}
 
+	eth.txRelay = newTxRelay(config.PrivateTxRelays)
+	eth.attacks = newAttackDetector(eth.blockchain, config.AttackRiskWebhook)
+	eth.governor = newImportGovernor(config.ImportGovernor, func(throttled bool) {
+		eth.handler.downloader.SetLoadThrottled(throttled)
+		eth.blockchain.SetPrefetchPaused(throttled)
+	})
+	if config.ClockDriftCheck || config.ClockDriftAdjust {
+		eth.clock = newClockMonitor(eth.engine, config.ClockDriftAdjust)
+	}
+	if config.HeaderAccumulator {
+		if eth.headerAcc, err = newHeaderAccumulator(eth.blockchain, chainDb); err != nil {
+			return nil, err
+		}
+	}
 	eth.miner = miner.New(eth, &config.Miner, eth.blockchain.Config(), eth.EventMux(), eth.engine, eth.isLocalBlock)
 	eth.miner.SetExtra(makeExtraData(config.Miner.ExtraData))
 
@@ -362,6 +431,13 @@ func (s *Ethereum) APIs() []rpc.API {
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
+	// Append the experimental sponsor API only if explicitly enabled
+	if s.config.Miner.Sponsored {
+		apis = append(apis, rpc.API{
+			Namespace: "sponsor",
+			Service:   NewSponsorAPI(s),
+		})
+	}
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -379,6 +455,12 @@ func (s *Ethereum) APIs() []rpc.API {
 		}, {
 			Namespace: "debug",
 			Service:   NewDebugAPI(s),
+		}, {
+			Namespace: "txpool",
+			Service:   NewTxPoolDropsAPI(s),
+		}, {
+			Namespace: "txpool",
+			Service:   NewTxPoolMigrationAPI(s),
 		}, {
 			Namespace: "net",
 			Service:   s.netRPCService,
@@ -588,6 +670,17 @@ func (s *Ethereum) Start() error {
 	}
 	// Start the networking layer and the light server if requested
 	s.handler.Start(maxPeers)
+
+	if s.headerAcc != nil {
+		s.headerAcc.start()
+	}
+	s.attacks.start()
+	if s.clock != nil {
+		s.clock.start()
+	}
+	if s.governor != nil {
+		s.governor.start()
+	}
 	return nil
 }
 
@@ -600,6 +693,16 @@ func (s *Ethereum) Stop() error {
 	s.handler.Stop()
 
 	// Then stop everything else.
+	if s.headerAcc != nil {
+		s.headerAcc.stop()
+	}
+	s.attacks.stop()
+	if s.clock != nil {
+		s.clock.stop()
+	}
+	if s.governor != nil {
+		s.governor.stop()
+	}
 	s.bloomIndexer.Close()
 	close(s.closeBloomHandler)
 	s.txPool.Close()
@@ -607,6 +710,15 @@ func (s *Ethereum) Stop() error {
 	s.blockchain.Stop()
 	s.engine.Close()
 
+	if s.analysis != nil {
+		vm.SetAnalysisCache(nil)
+		if s.analysisJournal != "" {
+			if err := s.analysis.Save(s.analysisJournal); err != nil {
+				log.Warn("Failed to save analysis cache journal", "err", err)
+			}
+		}
+	}
+
 	// Clean shutdown marker as the last thing before closing db
 	s.shutdownTracker.Stop()
 
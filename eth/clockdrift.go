@@ -0,0 +1,130 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/p2p/discover"
+)
+
+const (
+	// clockDriftInterval is the time between two clock drift measurements.
+	clockDriftInterval = 10 * time.Minute
+
+	// clockDriftWarnThreshold is the clock drift warned about. Blocks are only
+	// accepted up to 15 seconds ahead of the local clock, so larger drifts
+	// cause valid blocks to be rejected, or the blocks mined by the node to be
+	// rejected by its peers.
+	clockDriftWarnThreshold = 5 * time.Second
+)
+
+var clockDriftGauge = metrics.NewRegisteredGauge("eth/clock/drift", nil)
+
+// clockDriftAware is implemented by consensus engines able to compensate for
+// the drift of the local clock.
+type clockDriftAware interface {
+	SetClockDrift(drift time.Duration)
+}
+
+// clockMonitor periodically measures the drift of the local clock against
+// network time, reporting it as a metric and optionally passing it to the
+// consensus engine to adjust the future block tolerance.
+type clockMonitor struct {
+	engine clockDriftAware // nil if the tolerance is not adjusted
+	sample func() (time.Duration, error)
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newClockMonitor creates a clock monitor. If adjust is set and the consensus
+// engine supports it, the measured drift is compensated for when verifying the
+// timestamps of blocks.
+func newClockMonitor(engine consensus.Engine, adjust bool) *clockMonitor {
+	m := &clockMonitor{
+		sample: discover.ClockDrift,
+		quit:   make(chan struct{}),
+	}
+	if adjust {
+		if b, ok := engine.(*beacon.Beacon); ok {
+			engine = b.InnerEngine()
+		}
+		if aware, ok := engine.(clockDriftAware); ok {
+			m.engine = aware
+		} else {
+			log.Warn("Consensus engine doesn't support clock drift compensation")
+		}
+	}
+	return m
+}
+
+// start launches the background goroutine measuring the clock drift.
+func (m *clockMonitor) start() {
+	m.wg.Add(1)
+	go m.loop()
+}
+
+// stop terminates the background goroutine.
+func (m *clockMonitor) stop() {
+	close(m.quit)
+	m.wg.Wait()
+}
+
+// loop measures the clock drift right away and then at regular intervals.
+func (m *clockMonitor) loop() {
+	defer m.wg.Done()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			m.update()
+			timer.Reset(clockDriftInterval)
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+// update measures the clock drift and acts on it.
+func (m *clockMonitor) update() {
+	drift, err := m.sample()
+	if err != nil {
+		log.Debug("Failed to measure clock drift", "err", err)
+		return
+	}
+	clockDriftGauge.Update(drift.Milliseconds())
+
+	if drift < -clockDriftWarnThreshold || drift > clockDriftWarnThreshold {
+		log.Warn(fmt.Sprintf("System clock seems off by %v, which can cause valid blocks to be rejected", drift))
+		log.Warn("Please enable network time synchronisation in system settings.")
+	} else {
+		log.Debug("Measured clock drift", "drift", drift)
+	}
+	if m.engine != nil {
+		m.engine.SetClockDrift(drift)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+)
+
+type testDriftEngine struct {
+	drifts []time.Duration
+}
+
+func (e *testDriftEngine) SetClockDrift(drift time.Duration) {
+	e.drifts = append(e.drifts, drift)
+}
+
+func TestClockMonitorUpdate(t *testing.T) {
+	var (
+		engine  = new(testDriftEngine)
+		samples = []time.Duration{-3 * time.Second, 0, 20 * time.Second}
+		monitor = &clockMonitor{engine: engine}
+	)
+	for _, drift := range samples {
+		drift := drift
+		monitor.sample = func() (time.Duration, error) { return drift, nil }
+		monitor.update()
+	}
+	// Failed measurements must not reset the compensation
+	monitor.sample = func() (time.Duration, error) { return 0, errors.New("timeout") }
+	monitor.update()
+
+	if len(engine.drifts) != len(samples) {
+		t.Fatalf("drift updates mismatch: have %d, want %d", len(engine.drifts), len(samples))
+	}
+	for i, drift := range samples {
+		if engine.drifts[i] != drift {
+			t.Errorf("update %d: drift mismatch: have %v, want %v", i, engine.drifts[i], drift)
+		}
+	}
+}
+
+func TestClockMonitorEngine(t *testing.T) {
+	if m := newClockMonitor(beacon.New(ethash.NewFaker()), true); m.engine == nil {
+		t.Error("wrapped ethash engine not adjusted")
+	}
+	if m := newClockMonitor(ethash.NewFaker(), false); m.engine != nil {
+		t.Error("engine adjusted without being requested")
+	}
+}
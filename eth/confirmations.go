@@ -0,0 +1,85 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/core"
+)
+
+const (
+	// minConfirmations is the minimum number of confirmations ever recommended.
+	minConfirmations = 12
+
+	// maxConfirmations caps the recommended number of confirmations.
+	maxConfirmations = 100000
+
+	// confirmationBlockWindow is the number of recent blocks the average block
+	// time is measured over.
+	confirmationBlockWindow = 120
+
+	// defaultBlockTime is the block time assumed if it can't be measured.
+	defaultBlockTime = 13
+)
+
+// confirmationParams are the network conditions a confirmation recommendation
+// is derived from.
+type confirmationParams struct {
+	reward       *big.Int // Block reward in wei
+	blockTime    uint64   // Average block time in seconds
+	mess         bool     // Whether ECBP1100 (MESS) is active
+	deepestReorg uint64   // Deepest recently observed reorg
+	riskFloor    uint64   // Minimum confirmations demanded by the attack risk
+}
+
+// recommendConfirmations returns the number of confirmations after which
+// reversing a transfer of the given value costs an attacker more than the value
+// itself.
+//
+// The cost of replacing n blocks is approximated by the block rewards the rented
+// hashrate could have earned mining honestly, n * reward. While MESS is active,
+// the replacing segment needs gravity(n * blockTime) times the work of the
+// replaced one, multiplying the cost accordingly. The result is never less than
+// twice the deepest recent reorg, nor the floor demanded by the attack risk.
+// Without block rewards, e.g. on proof-of-stake chains, only the floor applies.
+func recommendConfirmations(value *big.Int, p confirmationParams) (confirmations uint64, gravity float64) {
+	floor := max(minConfirmations, 2*p.deepestReorg, p.riskFloor)
+
+	cost := func(n uint64) *big.Float {
+		c := new(big.Float).SetInt(new(big.Int).Mul(p.reward, new(big.Int).SetUint64(n)))
+		if p.mess {
+			c.Mul(c, big.NewFloat(core.ECBP1100Gravity(n*p.blockTime)))
+		}
+		return c
+	}
+	var n uint64
+	if p.reward.Sign() > 0 {
+		target := new(big.Float).SetInt(value)
+		n = uint64(sort.Search(maxConfirmations, func(i int) bool {
+			return cost(uint64(i)).Cmp(target) >= 0
+		}))
+	}
+	confirmations = min(max(n, floor), maxConfirmations)
+
+	gravity = 1
+	if p.mess {
+		gravity = core.ECBP1100Gravity(confirmations * p.blockTime)
+	}
+	return confirmations, gravity
+}
@@ -0,0 +1,86 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/params/vars"
+)
+
+func TestRecommendConfirmations(t *testing.T) {
+	var (
+		reward = new(big.Int).Mul(big.NewInt(2), big.NewInt(vars.Ether)) // 2 ether per block
+		ether  = func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(vars.Ether)) }
+	)
+	tests := []struct {
+		name   string
+		value  *big.Int
+		params confirmationParams
+		want   uint64
+	}{
+		{
+			name:   "small value",
+			value:  ether(1),
+			params: confirmationParams{reward: reward, blockTime: 13},
+			want:   minConfirmations,
+		},
+		{
+			name:   "large value",
+			value:  ether(2000),
+			params: confirmationParams{reward: reward, blockTime: 13},
+			want:   1000,
+		},
+		{
+			name:   "capped value",
+			value:  ether(1e9),
+			params: confirmationParams{reward: reward, blockTime: 13},
+			want:   maxConfirmations,
+		},
+		{
+			name:   "deep reorg",
+			value:  ether(1),
+			params: confirmationParams{reward: reward, blockTime: 13, deepestReorg: 40},
+			want:   80,
+		},
+		{
+			name:   "attack risk",
+			value:  ether(1),
+			params: confirmationParams{reward: reward, blockTime: 13, riskFloor: 5000},
+			want:   5000,
+		},
+		{
+			name:   "no rewards",
+			value:  ether(2000),
+			params: confirmationParams{reward: new(big.Int), blockTime: 12},
+			want:   minConfirmations,
+		},
+	}
+	for _, tt := range tests {
+		if have, _ := recommendConfirmations(tt.value, tt.params); have != tt.want {
+			t.Errorf("%s: wrong confirmations %d, want %d", tt.name, have, tt.want)
+		}
+	}
+
+	// MESS multiplies the attack cost, so far fewer confirmations are needed.
+	plain, _ := recommendConfirmations(ether(2000), confirmationParams{reward: reward, blockTime: 13})
+	mess, gravity := recommendConfirmations(ether(2000), confirmationParams{reward: reward, blockTime: 13, mess: true})
+	if mess >= plain/2 || gravity <= 1 {
+		t.Errorf("MESS not accounted for: %d confirmations with MESS (gravity %f), %d without", mess, gravity, plain)
+	}
+}
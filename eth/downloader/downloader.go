@@ -30,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/eth/protocols/snap"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
@@ -60,6 +61,7 @@ var (
 	fsMinFullBlocks        = 64              // Number of blocks to retrieve fully even in snap sync
 
 	maxTotalDifficultyDistance = 10               // Maximum amount of block difficulty units the master peer can lag behind w.r.t. other peers
+	maxThrottledRequests       = 2                // Maximum number of block part requests in flight while the node is under load
 	totalDifficultyContCheck   = 13 * time.Second // Time interval to wait between total difficulty checks
 )
 
@@ -128,7 +130,8 @@ type Downloader struct {
 	synchronising   atomic.Bool
 	notified        atomic.Bool
 	committed       atomic.Bool
-	ancientLimit    uint64 // The maximum block number which can be regarded as ancient data.
+	ancientLimit    uint64      // The maximum block number which can be regarded as ancient data.
+	loadThrottled   atomic.Bool // Whether block part retrievals are slowed down due to local load
 
 	// Channels
 	headerProcCh chan *headerTask // Channel to feed the header processor new tasks
@@ -253,6 +256,12 @@ func New(checkpoint uint64, stateDb ethdb.Database, mux *event.TypeMux, chain Bl
 	return dl
 }
 
+// SetLoadThrottled slows down or restores the retrieval of block parts, so
+// that a node under heavy local load doesn't flood itself with blocks to import.
+func (d *Downloader) SetLoadThrottled(throttled bool) {
+	d.loadThrottled.Store(throttled)
+}
+
 // Progress retrieves the synchronisation boundaries, specifically the origin
 // block where synchronisation started at (may have failed/suspended); the block
 // or header sync is currently at; and the latest known block which the sync targets.
@@ -295,6 +304,9 @@ func (d *Downloader) Progress() ethereum.SyncProgress {
 		HealedBytecodeBytes: uint64(progress.BytecodeHealBytes),
 		HealingTrienodes:    pending.TrienodeHeal,
 		HealingBytecode:     pending.BytecodeHeal,
+		HealingScheduled:    pending.HealScheduled,
+		HealingRate:         uint64(pending.HealRate),
+		HealingETA:          uint64(pending.HealETA.Seconds()),
 	}
 }
 
@@ -1759,6 +1771,10 @@ func (d *Downloader) processSnapSyncContent() error {
 		pivot := d.pivotHeader
 		d.pivotLock.RUnlock()
 
+		// Let the state healer know about the accounts touched by the recent blocks
+		if !d.committed.Load() {
+			d.prioritizeHealing(pivot.Number.Uint64(), results)
+		}
 		if oldPivot == nil { // no results piling up, we can move the pivot
 			if !d.committed.Load() { // not yet passed the pivot, we can move the pivot
 				if pivot.Root != sync.root { // pivot position changed, we can move the pivot
@@ -1830,6 +1846,30 @@ func (d *Downloader) processSnapSyncContent() error {
 	}
 }
 
+// prioritizeHealing feeds the accounts touched by the blocks above the pivot to
+// the state syncer, so that their state is healed ahead of the rest. These are
+// the accounts most likely to be accessed via RPC right after sync completes.
+func (d *Downloader) prioritizeHealing(pivot uint64, results []*fetchResult) {
+	var accounts []common.Hash
+	for _, result := range results {
+		if result.Header.Number.Uint64() < pivot {
+			continue
+		}
+		accounts = append(accounts, crypto.Keccak256Hash(result.Header.Coinbase.Bytes()))
+		for _, tx := range result.Transactions {
+			if from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx); err == nil {
+				accounts = append(accounts, crypto.Keccak256Hash(from.Bytes()))
+			}
+			if to := tx.To(); to != nil {
+				accounts = append(accounts, crypto.Keccak256Hash(to.Bytes()))
+			}
+		}
+	}
+	if len(accounts) > 0 {
+		d.SnapSyncer.Prioritize(accounts)
+	}
+}
+
 func splitAroundPivot(pivot uint64, results []*fetchResult) (p *fetchResult, before, after []*fetchResult) {
 	if len(results) == 0 {
 		return nil, nil, nil
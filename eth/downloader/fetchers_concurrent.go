@@ -172,10 +172,20 @@ func (d *Downloader) concurrentFetch(queue typedQueue, beaconMode bool) error {
 				if queued = queue.pending(); queued == 0 {
 					break
 				}
+				// If the node is under load, only keep a few small requests
+				// in flight to slow down the import
+				capacity := queue.capacity(peer, d.peers.rates.TargetRoundTrip())
+				if d.loadThrottled.Load() {
+					if len(pending) >= maxThrottledRequests {
+						throttled = true
+						break
+					}
+					capacity = max(capacity/4, 1)
+				}
 				// Reserve a chunk of fetches for a peer. A nil can mean either that
 				// no more headers are available, or that the peer is known not to
 				// have them.
-				request, progress, throttle := queue.reserve(peer, queue.capacity(peer, d.peers.rates.TargetRoundTrip()))
+				request, progress, throttle := queue.reserve(peer, capacity)
 				if progress {
 					progressed = true
 				}
@@ -77,7 +77,10 @@ var Defaults = Config{
 	TrieDirtyCache:     256,
 	TrieTimeout:        60 * time.Minute,
 	SnapshotCache:      102,
+	AnalysisCache:      16,
+	AnalysisJournal:    "analysiscache",
 	FilterLogCacheSize: 32,
+	FilterTimeout:      5 * time.Minute,
 	Miner:              miner.DefaultConfig,
 	TxPool:             legacypool.DefaultConfig,
 	BlobPool:           blobpool.DefaultConfig,
@@ -129,11 +132,28 @@ type Config struct {
 	NoPruning  bool // Whether to disable pruning and flush everything to disk
 	NoPrefetch bool // Whether to disable prefetching and only load state on demand
 
+	PrefetchDistance int `toml:",omitempty"` // Number of followup blocks to prefetch state for during import (0 = autodetect)
+	PrefetchWorkers  int `toml:",omitempty"` // Number of concurrent state prefetcher goroutines (0 = autodetect)
+	TrieWorkers      int `toml:",omitempty"` // Number of storage tries hashed and committed concurrently (0 = autodetect)
+
 	// Deprecated, use 'TransactionHistory' instead.
 	TxLookupLimit      uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 	TransactionHistory uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 	StateHistory       uint64 `toml:",omitempty"` // The maximum number of blocks from head whose state histories are reserved.
 
+	// TxLookupAddresses restricts the transaction lookup index to the transactions
+	// sent from or to these addresses and the local accounts. An empty list
+	// indexes all transactions.
+	TxLookupAddresses []common.Address `toml:",omitempty"`
+
+	// HeaderAccumulator enables maintaining a Merkle mountain range over the
+	// canonical block hashes, used to serve header inclusion proofs.
+	HeaderAccumulator bool `toml:",omitempty"`
+
+	// LogIndex enables indexing the logs of imported blocks by emitting address,
+	// used to answer address-filtered log queries without decoding receipts.
+	LogIndex bool `toml:",omitempty"`
+
 	// State scheme represents the scheme used to store ethereum states and trie
 	// nodes on top. It can be 'hash', 'path', or none which means use the scheme
 	// consistent with persistent state.
@@ -165,15 +185,33 @@ type Config struct {
 	DatabaseFreezer       string
 	DatabaseFreezerRemote string
 
+	// AncientCompression is the compression algorithm of newly created ancient
+	// tables, "snappy" (default) or "zstd". Existing tables keep theirs.
+	AncientCompression string `toml:",omitempty"`
+
 	TrieCleanCache int
 	TrieDirtyCache int
 	TrieTimeout    time.Duration
 	SnapshotCache  int
 	Preimages      bool
 
+	// Size in megabytes of the JUMPDEST analysis cache shared across transactions,
+	// and the file within the datadir it is journaled to between restarts.
+	AnalysisCache   int
+	AnalysisJournal string
+
 	// This is the number of blocks for which logs will be cached in the filter system.
 	FilterLogCacheSize int
 
+	// Installed filters are uninstalled when not polled for this long. Pending
+	// transaction filters accumulate every pooled transaction, so they may use
+	// a shorter timeout (defaults to FilterTimeout).
+	FilterTimeout          time.Duration
+	FilterPendingTxTimeout time.Duration
+
+	// FilterLimit is the maximum number of filters a remote client may install.
+	FilterLimit int
+
 	// Mining options
 	Miner miner.Config
 
@@ -184,6 +222,16 @@ type Config struct {
 	TxPool   legacypool.Config
 	BlobPool blobpool.Config
 
+	// Transaction gossip options
+	TxGossip TxGossipConfig
+
+	// ImportGovernor slows down block import while the node is under load.
+	ImportGovernor ImportGovernorConfig
+
+	// PrivateTxRelays is the list of trusted RPC endpoints that transactions
+	// submitted via eth_sendPrivateTransaction are forwarded to.
+	PrivateTxRelays []string `toml:",omitempty"`
+
 	// Gas Price Oracle options
 	GPO gasprice.Config
 
@@ -209,6 +257,28 @@ type Config struct {
 	// send-transaction variants. The unit is ether.
 	RPCTxFeeCap float64
 
+	// RPCServeWhileSyncing makes the RPC APIs answer from the best available data
+	// during the initial sync instead of failing on missing state, marking HTTP
+	// responses with the sync status of the node.
+	RPCServeWhileSyncing bool `toml:",omitempty"`
+
+	// RPCCallCache is the number of eth_call results cached by block and call,
+	// only for finalized blocks if the chain has finality. 0 disables it.
+	RPCCallCache int `toml:",omitempty"`
+
+	// TraceFileDir is the directory debug_standardTraceBlockToFile writes its
+	// output into. The system temp directory is used if empty.
+	TraceFileDir string `toml:",omitempty"`
+
+	// TraceFileLimit is the total size in bytes of trace files retained in the
+	// trace directory before the oldest ones are deleted (0 = unlimited). It
+	// requires a dedicated TraceFileDir.
+	TraceFileLimit uint64 `toml:",omitempty"`
+
+	// TraceSignatureDB is a custom 4byte signature file used next to the
+	// embedded database when decoding call data.
+	TraceSignatureDB string `toml:",omitempty"`
+
 	// Checkpoint is a hardcoded checkpoint which can be nil.
 	Checkpoint *ctypes.TrustedCheckpoint `toml:",omitempty"`
 
@@ -224,6 +294,23 @@ type Config struct {
 	// When this value is *true, ECBP100 will not (ever) be disabled; when *false, it will never be enabled.
 	ECBP1100NoDisable *bool `toml:",omitempty"`
 
+	// MaxReorgDepth is the maximum number of canonical blocks a reorg may drop
+	// without being confirmed via admin_acceptReorg (0 = no limit).
+	MaxReorgDepth uint64 `toml:",omitempty"`
+
+	// AttackRiskWebhook is the URL the attack risk is posted to whenever its
+	// level rises.
+	AttackRiskWebhook string `toml:",omitempty"`
+
+	// ClockDriftCheck enables periodically measuring the drift of the local
+	// clock against the NTP pool.
+	ClockDriftCheck bool `toml:",omitempty"`
+
+	// ClockDriftAdjust extends the future block tolerance of the consensus
+	// engine by the measured lag of the local clock, within safe bounds.
+	// It implies ClockDriftCheck.
+	ClockDriftAdjust bool `toml:",omitempty"`
+
 	// OverrideShanghai (TODO: remove after the fork)
 	OverrideShanghai *uint64 `toml:",omitempty"`
 
@@ -234,6 +321,21 @@ type Config struct {
 	OverrideVerkle *uint64 `toml:",omitempty"`
 }
 
+// TxGossipConfig is the set of policies applied to outbound transaction gossip.
+// All of them can be toggled at runtime via admin_setTxGossip.
+type TxGossipConfig struct {
+	Disabled   bool `json:"disabled"`   // Disables outbound transaction gossip entirely (receive-only node)
+	HashesOnly bool `json:"hashesOnly"` // Only announce transaction hashes, never broadcast full bodies
+	NoLocals   bool `json:"noLocals"`   // Never gossip transactions originating from local accounts
+}
+
+// ImportGovernorConfig is the set of load thresholds above which block import
+// is slowed down, keeping serving nodes responsive during catch-up bursts.
+type ImportGovernorConfig struct {
+	RPCLatency time.Duration `toml:",omitempty"` // 99th percentile RPC serving time above which import is slowed down (0 = disabled)
+	IOWait     int           `toml:",omitempty"` // Percentage of CPU time spent waiting on IO above which import is slowed down (0 = disabled)
+}
+
 // CreateConsensusEngine creates a consensus engine for the given chain configuration.
 func CreateConsensusEngine(stack *node.Node, ethashConfig *ethash.Config, cliqueConfig *ctypes.CliqueConfig, lyra2Config *lyra2.Config, notify []string, noverify bool, db ethdb.Database) consensus.Engine {
 	// If proof-of-authority is requested, set it up
@@ -267,6 +369,7 @@ func CreateConsensusEngine(stack *node.Node, ethashConfig *ethash.Config, clique
 				DatasetsInMem:    ethashConfig.DatasetsInMem,
 				DatasetsOnDisk:   ethashConfig.DatasetsOnDisk,
 				DatasetsLockMmap: ethashConfig.DatasetsLockMmap,
+				VerifyWorkers:    ethashConfig.VerifyWorkers,
 				NotifyFull:       ethashConfig.NotifyFull,
 				ECIP1099Block:    ethashConfig.ECIP1099Block,
 			}, notify, noverify)
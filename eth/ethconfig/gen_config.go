@@ -27,9 +27,15 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		SnapDiscoveryURLs          []string
 		NoPruning                  bool
 		NoPrefetch                 bool
+		PrefetchDistance           int                    `toml:",omitempty"`
+		PrefetchWorkers            int                    `toml:",omitempty"`
+		TrieWorkers                int                    `toml:",omitempty"`
 		TxLookupLimit              uint64                 `toml:",omitempty"`
 		TransactionHistory         uint64                 `toml:",omitempty"`
 		StateHistory               uint64                 `toml:",omitempty"`
+		TxLookupAddresses          []common.Address       `toml:",omitempty"`
+		HeaderAccumulator          bool                   `toml:",omitempty"`
+		LogIndex                   bool                   `toml:",omitempty"`
 		StateScheme                string                 `toml:",omitempty"`
 		RequiredBlocks             map[uint64]common.Hash `toml:"-"`
 		LightServ                  int                    `toml:",omitempty"`
@@ -47,16 +53,25 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		DatabaseCache              int
 		DatabaseFreezer            string
 		DatabaseFreezerRemote      string
+		AncientCompression         string `toml:",omitempty"`
 		TrieCleanCache             int
 		TrieDirtyCache             int
 		TrieTimeout                time.Duration
 		SnapshotCache              int
 		Preimages                  bool
+		AnalysisCache              int
+		AnalysisJournal            string
 		FilterLogCacheSize         int
+		FilterTimeout              time.Duration
+		FilterPendingTxTimeout     time.Duration
+		FilterLimit                int
 		Miner                      miner.Config
 		Ethash                     ethash.Config
 		TxPool                     legacypool.Config
 		BlobPool                   blobpool.Config
+		TxGossip                   TxGossipConfig
+		ImportGovernor             ImportGovernorConfig
+		PrivateTxRelays            []string `toml:",omitempty"`
 		GPO                        gasprice.Config
 		EnablePreimageRecording    bool
 		DocRoot                    string `toml:"-"`
@@ -65,11 +80,20 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		RPCGasCap                  uint64
 		RPCEVMTimeout              time.Duration
 		RPCTxFeeCap                float64
+		RPCServeWhileSyncing       bool                           `toml:",omitempty"`
+		RPCCallCache               int                            `toml:",omitempty"`
+		TraceFileDir               string                         `toml:",omitempty"`
+		TraceFileLimit             uint64                         `toml:",omitempty"`
+		TraceSignatureDB           string                         `toml:",omitempty"`
 		Checkpoint                 *ctypes.TrustedCheckpoint      `toml:",omitempty"`
 		CheckpointOracle           *ctypes.CheckpointOracleConfig `toml:",omitempty"`
 		OverrideECBP1100           *uint64                        `toml:",omitempty"`
 		OverrideECBP1100Deactivate *uint64                        `toml:",omitempty"`
 		ECBP1100NoDisable          *bool                          `toml:",omitempty"`
+		MaxReorgDepth              uint64                         `toml:",omitempty"`
+		AttackRiskWebhook          string                         `toml:",omitempty"`
+		ClockDriftCheck            bool                           `toml:",omitempty"`
+		ClockDriftAdjust           bool                           `toml:",omitempty"`
 		OverrideShanghai           *uint64                        `toml:",omitempty"`
 		OverrideCancun             *uint64                        `toml:",omitempty"`
 		OverrideVerkle             *uint64                        `toml:",omitempty"`
@@ -83,9 +107,15 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.SnapDiscoveryURLs = c.SnapDiscoveryURLs
 	enc.NoPruning = c.NoPruning
 	enc.NoPrefetch = c.NoPrefetch
+	enc.PrefetchDistance = c.PrefetchDistance
+	enc.PrefetchWorkers = c.PrefetchWorkers
+	enc.TrieWorkers = c.TrieWorkers
 	enc.TxLookupLimit = c.TxLookupLimit
 	enc.TransactionHistory = c.TransactionHistory
 	enc.StateHistory = c.StateHistory
+	enc.TxLookupAddresses = c.TxLookupAddresses
+	enc.HeaderAccumulator = c.HeaderAccumulator
+	enc.LogIndex = c.LogIndex
 	enc.StateScheme = c.StateScheme
 	enc.RequiredBlocks = c.RequiredBlocks
 	enc.LightServ = c.LightServ
@@ -103,16 +133,25 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.DatabaseCache = c.DatabaseCache
 	enc.DatabaseFreezer = c.DatabaseFreezer
 	enc.DatabaseFreezerRemote = c.DatabaseFreezerRemote
+	enc.AncientCompression = c.AncientCompression
 	enc.TrieCleanCache = c.TrieCleanCache
 	enc.TrieDirtyCache = c.TrieDirtyCache
 	enc.TrieTimeout = c.TrieTimeout
 	enc.SnapshotCache = c.SnapshotCache
 	enc.Preimages = c.Preimages
+	enc.AnalysisCache = c.AnalysisCache
+	enc.AnalysisJournal = c.AnalysisJournal
 	enc.FilterLogCacheSize = c.FilterLogCacheSize
+	enc.FilterTimeout = c.FilterTimeout
+	enc.FilterPendingTxTimeout = c.FilterPendingTxTimeout
+	enc.FilterLimit = c.FilterLimit
 	enc.Miner = c.Miner
 	enc.Ethash = c.Ethash
 	enc.TxPool = c.TxPool
 	enc.BlobPool = c.BlobPool
+	enc.TxGossip = c.TxGossip
+	enc.ImportGovernor = c.ImportGovernor
+	enc.PrivateTxRelays = c.PrivateTxRelays
 	enc.GPO = c.GPO
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
 	enc.DocRoot = c.DocRoot
@@ -121,11 +160,20 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.RPCGasCap = c.RPCGasCap
 	enc.RPCEVMTimeout = c.RPCEVMTimeout
 	enc.RPCTxFeeCap = c.RPCTxFeeCap
+	enc.RPCServeWhileSyncing = c.RPCServeWhileSyncing
+	enc.RPCCallCache = c.RPCCallCache
+	enc.TraceFileDir = c.TraceFileDir
+	enc.TraceFileLimit = c.TraceFileLimit
+	enc.TraceSignatureDB = c.TraceSignatureDB
 	enc.Checkpoint = c.Checkpoint
 	enc.CheckpointOracle = c.CheckpointOracle
 	enc.OverrideECBP1100 = c.OverrideECBP1100
 	enc.OverrideECBP1100Deactivate = c.OverrideECBP1100Deactivate
 	enc.ECBP1100NoDisable = c.ECBP1100NoDisable
+	enc.MaxReorgDepth = c.MaxReorgDepth
+	enc.AttackRiskWebhook = c.AttackRiskWebhook
+	enc.ClockDriftCheck = c.ClockDriftCheck
+	enc.ClockDriftAdjust = c.ClockDriftAdjust
 	enc.OverrideShanghai = c.OverrideShanghai
 	enc.OverrideCancun = c.OverrideCancun
 	enc.OverrideVerkle = c.OverrideVerkle
@@ -143,9 +191,15 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		SnapDiscoveryURLs          []string
 		NoPruning                  *bool
 		NoPrefetch                 *bool
+		PrefetchDistance           *int                   `toml:",omitempty"`
+		PrefetchWorkers            *int                   `toml:",omitempty"`
+		TrieWorkers                *int                   `toml:",omitempty"`
 		TxLookupLimit              *uint64                `toml:",omitempty"`
 		TransactionHistory         *uint64                `toml:",omitempty"`
 		StateHistory               *uint64                `toml:",omitempty"`
+		TxLookupAddresses          []common.Address       `toml:",omitempty"`
+		HeaderAccumulator          *bool                  `toml:",omitempty"`
+		LogIndex                   *bool                  `toml:",omitempty"`
 		StateScheme                *string                `toml:",omitempty"`
 		RequiredBlocks             map[uint64]common.Hash `toml:"-"`
 		LightServ                  *int                   `toml:",omitempty"`
@@ -163,16 +217,25 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		DatabaseCache              *int
 		DatabaseFreezer            *string
 		DatabaseFreezerRemote      *string
+		AncientCompression         *string `toml:",omitempty"`
 		TrieCleanCache             *int
 		TrieDirtyCache             *int
 		TrieTimeout                *time.Duration
 		SnapshotCache              *int
 		Preimages                  *bool
+		AnalysisCache              *int
+		AnalysisJournal            *string
 		FilterLogCacheSize         *int
+		FilterTimeout              *time.Duration
+		FilterPendingTxTimeout     *time.Duration
+		FilterLimit                *int
 		Miner                      *miner.Config
 		Ethash                     *ethash.Config
 		TxPool                     *legacypool.Config
 		BlobPool                   *blobpool.Config
+		TxGossip                   *TxGossipConfig
+		ImportGovernor             *ImportGovernorConfig
+		PrivateTxRelays            []string `toml:",omitempty"`
 		GPO                        *gasprice.Config
 		EnablePreimageRecording    *bool
 		DocRoot                    *string `toml:"-"`
@@ -181,11 +244,20 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		RPCGasCap                  *uint64
 		RPCEVMTimeout              *time.Duration
 		RPCTxFeeCap                *float64
+		RPCServeWhileSyncing       *bool                          `toml:",omitempty"`
+		RPCCallCache               *int                           `toml:",omitempty"`
+		TraceFileDir               *string                        `toml:",omitempty"`
+		TraceFileLimit             *uint64                        `toml:",omitempty"`
+		TraceSignatureDB           *string                        `toml:",omitempty"`
 		Checkpoint                 *ctypes.TrustedCheckpoint      `toml:",omitempty"`
 		CheckpointOracle           *ctypes.CheckpointOracleConfig `toml:",omitempty"`
 		OverrideECBP1100           *uint64                        `toml:",omitempty"`
 		OverrideECBP1100Deactivate *uint64                        `toml:",omitempty"`
 		ECBP1100NoDisable          *bool                          `toml:",omitempty"`
+		MaxReorgDepth              *uint64                        `toml:",omitempty"`
+		AttackRiskWebhook          *string                        `toml:",omitempty"`
+		ClockDriftCheck            *bool                          `toml:",omitempty"`
+		ClockDriftAdjust           *bool                          `toml:",omitempty"`
 		OverrideShanghai           *uint64                        `toml:",omitempty"`
 		OverrideCancun             *uint64                        `toml:",omitempty"`
 		OverrideVerkle             *uint64                        `toml:",omitempty"`
@@ -218,6 +290,15 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.NoPrefetch != nil {
 		c.NoPrefetch = *dec.NoPrefetch
 	}
+	if dec.PrefetchDistance != nil {
+		c.PrefetchDistance = *dec.PrefetchDistance
+	}
+	if dec.PrefetchWorkers != nil {
+		c.PrefetchWorkers = *dec.PrefetchWorkers
+	}
+	if dec.TrieWorkers != nil {
+		c.TrieWorkers = *dec.TrieWorkers
+	}
 	if dec.TxLookupLimit != nil {
 		c.TxLookupLimit = *dec.TxLookupLimit
 	}
@@ -227,6 +308,15 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.StateHistory != nil {
 		c.StateHistory = *dec.StateHistory
 	}
+	if dec.TxLookupAddresses != nil {
+		c.TxLookupAddresses = dec.TxLookupAddresses
+	}
+	if dec.HeaderAccumulator != nil {
+		c.HeaderAccumulator = *dec.HeaderAccumulator
+	}
+	if dec.LogIndex != nil {
+		c.LogIndex = *dec.LogIndex
+	}
 	if dec.StateScheme != nil {
 		c.StateScheme = *dec.StateScheme
 	}
@@ -278,6 +368,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.DatabaseFreezerRemote != nil {
 		c.DatabaseFreezerRemote = *dec.DatabaseFreezerRemote
 	}
+	if dec.AncientCompression != nil {
+		c.AncientCompression = *dec.AncientCompression
+	}
 	if dec.TrieCleanCache != nil {
 		c.TrieCleanCache = *dec.TrieCleanCache
 	}
@@ -293,9 +386,24 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.Preimages != nil {
 		c.Preimages = *dec.Preimages
 	}
+	if dec.AnalysisCache != nil {
+		c.AnalysisCache = *dec.AnalysisCache
+	}
+	if dec.AnalysisJournal != nil {
+		c.AnalysisJournal = *dec.AnalysisJournal
+	}
 	if dec.FilterLogCacheSize != nil {
 		c.FilterLogCacheSize = *dec.FilterLogCacheSize
 	}
+	if dec.FilterTimeout != nil {
+		c.FilterTimeout = *dec.FilterTimeout
+	}
+	if dec.FilterPendingTxTimeout != nil {
+		c.FilterPendingTxTimeout = *dec.FilterPendingTxTimeout
+	}
+	if dec.FilterLimit != nil {
+		c.FilterLimit = *dec.FilterLimit
+	}
 	if dec.Miner != nil {
 		c.Miner = *dec.Miner
 	}
@@ -308,6 +416,15 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.BlobPool != nil {
 		c.BlobPool = *dec.BlobPool
 	}
+	if dec.TxGossip != nil {
+		c.TxGossip = *dec.TxGossip
+	}
+	if dec.ImportGovernor != nil {
+		c.ImportGovernor = *dec.ImportGovernor
+	}
+	if dec.PrivateTxRelays != nil {
+		c.PrivateTxRelays = dec.PrivateTxRelays
+	}
 	if dec.GPO != nil {
 		c.GPO = *dec.GPO
 	}
@@ -332,6 +449,21 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.RPCTxFeeCap != nil {
 		c.RPCTxFeeCap = *dec.RPCTxFeeCap
 	}
+	if dec.RPCServeWhileSyncing != nil {
+		c.RPCServeWhileSyncing = *dec.RPCServeWhileSyncing
+	}
+	if dec.RPCCallCache != nil {
+		c.RPCCallCache = *dec.RPCCallCache
+	}
+	if dec.TraceFileDir != nil {
+		c.TraceFileDir = *dec.TraceFileDir
+	}
+	if dec.TraceFileLimit != nil {
+		c.TraceFileLimit = *dec.TraceFileLimit
+	}
+	if dec.TraceSignatureDB != nil {
+		c.TraceSignatureDB = *dec.TraceSignatureDB
+	}
 	if dec.Checkpoint != nil {
 		c.Checkpoint = dec.Checkpoint
 	}
@@ -347,6 +479,18 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.ECBP1100NoDisable != nil {
 		c.ECBP1100NoDisable = dec.ECBP1100NoDisable
 	}
+	if dec.MaxReorgDepth != nil {
+		c.MaxReorgDepth = *dec.MaxReorgDepth
+	}
+	if dec.AttackRiskWebhook != nil {
+		c.AttackRiskWebhook = *dec.AttackRiskWebhook
+	}
+	if dec.ClockDriftCheck != nil {
+		c.ClockDriftCheck = *dec.ClockDriftCheck
+	}
+	if dec.ClockDriftAdjust != nil {
+		c.ClockDriftAdjust = *dec.ClockDriftAdjust
+	}
 	if dec.OverrideShanghai != nil {
 		c.OverrideShanghai = dec.OverrideShanghai
 	}
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"net"
 	"sync"
 	"time"
 
@@ -30,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
@@ -38,6 +40,13 @@ var (
 	errFilterNotFound    = errors.New("filter not found")
 	errInvalidBlockRange = errors.New("invalid block range params")
 	errExceedMaxTopics   = errors.New("exceed max topics")
+	errFilterLimit       = errors.New("too many filters installed, uninstall unused ones")
+)
+
+var (
+	installedFiltersGauge = metrics.NewRegisteredGauge("eth/filters/installed", nil)
+	expiredFiltersMeter   = metrics.NewRegisteredMeter("eth/filters/expired", nil)
+	rejectedFiltersMeter  = metrics.NewRegisteredMeter("eth/filters/rejected", nil)
 )
 
 // The maximum number of topic criteria allowed, vm.LOG4 - vm.LOG0
@@ -50,7 +59,9 @@ const maxSubTopics = 1000
 // and associated subscription in the event system.
 type filter struct {
 	typ      Type
-	deadline *time.Timer // filter is inactive when deadline triggers
+	owner    string        // remote client that installed the filter, empty for local ones
+	timeout  time.Duration // inactivity period after which the filter is uninstalled
+	deadline *time.Timer   // filter is inactive when deadline triggers
 	hashes   []common.Hash
 	fullTx   bool
 	txs      []*types.Transaction
@@ -66,6 +77,7 @@ type FilterAPI struct {
 	events    *EventSystem
 	filtersMu sync.Mutex
 	filters   map[rpc.ID]*filter
+	owned     map[string]int // number of filters installed per remote client
 	timeout   time.Duration
 }
 
@@ -75,13 +87,67 @@ func NewFilterAPI(system *FilterSystem, lightMode bool) *FilterAPI {
 		sys:     system,
 		events:  NewEventSystem(system, lightMode),
 		filters: make(map[rpc.ID]*filter),
+		owned:   make(map[string]int),
 		timeout: system.cfg.Timeout,
 	}
-	go api.timeoutLoop(system.cfg.Timeout)
+	go api.timeoutLoop(min(system.cfg.Timeout, system.cfg.PendingTxTimeout))
 
 	return api
 }
 
+// filterOwner returns the remote client calling the API, identified by its IP
+// address. Local clients, attached via IPC or in-process, aren't identified.
+func filterOwner(ctx context.Context) string {
+	info := rpc.PeerInfoFromContext(ctx)
+	if info.Transport != "http" && info.Transport != "ws" {
+		return ""
+	}
+	if host, _, err := net.SplitHostPort(info.RemoteAddr); err == nil {
+		return host
+	}
+	return info.RemoteAddr
+}
+
+// installFilter registers the filter on behalf of the client calling the API,
+// failing if the client already has the maximum number of filters installed.
+func (api *FilterAPI) installFilter(ctx context.Context, f *filter) error {
+	f.owner = filterOwner(ctx)
+	f.timeout = api.timeout
+	if f.typ == PendingTransactionsSubscription {
+		f.timeout = api.sys.cfg.PendingTxTimeout
+	}
+	api.filtersMu.Lock()
+	defer api.filtersMu.Unlock()
+
+	if limit := api.sys.cfg.FilterLimit; f.owner != "" && limit > 0 && api.owned[f.owner] >= limit {
+		rejectedFiltersMeter.Mark(1)
+		return errFilterLimit
+	}
+	f.deadline = time.NewTimer(f.timeout)
+	api.filters[f.s.ID] = f
+	if f.owner != "" {
+		api.owned[f.owner]++
+	}
+	installedFiltersGauge.Inc(1)
+	return nil
+}
+
+// removeFilter drops the filter with the given id, the lock must be held.
+func (api *FilterAPI) removeFilter(id rpc.ID) (*filter, bool) {
+	f, found := api.filters[id]
+	if !found {
+		return nil, false
+	}
+	delete(api.filters, id)
+	if f.owner != "" {
+		if api.owned[f.owner]--; api.owned[f.owner] == 0 {
+			delete(api.owned, f.owner)
+		}
+	}
+	installedFiltersGauge.Dec(1)
+	return f, true
+}
+
 // timeoutLoop runs at the interval set by 'timeout' and deletes filters
 // that have not been recently used. It is started when the API is created.
 func (api *FilterAPI) timeoutLoop(timeout time.Duration) {
@@ -95,7 +161,8 @@ func (api *FilterAPI) timeoutLoop(timeout time.Duration) {
 			select {
 			case <-f.deadline.C:
 				toUninstall = append(toUninstall, f.s)
-				delete(api.filters, id)
+				api.removeFilter(id)
+				expiredFiltersMeter.Mark(1)
 			default:
 				continue
 			}
@@ -117,15 +184,17 @@ func (api *FilterAPI) timeoutLoop(timeout time.Duration) {
 //
 // It is part of the filter package because this filter can be used through the
 // `eth_getFilterChanges` polling method that is also used for log filters.
-func (api *FilterAPI) NewPendingTransactionFilter(fullTx *bool) rpc.ID {
+func (api *FilterAPI) NewPendingTransactionFilter(ctx context.Context, fullTx *bool) (rpc.ID, error) {
 	var (
 		pendingTxs   = make(chan []*types.Transaction)
 		pendingTxSub = api.events.SubscribePendingTxs(pendingTxs)
 	)
 
-	api.filtersMu.Lock()
-	api.filters[pendingTxSub.ID] = &filter{typ: PendingTransactionsSubscription, fullTx: fullTx != nil && *fullTx, deadline: time.NewTimer(api.timeout), txs: make([]*types.Transaction, 0), s: pendingTxSub}
-	api.filtersMu.Unlock()
+	f := &filter{typ: PendingTransactionsSubscription, fullTx: fullTx != nil && *fullTx, txs: make([]*types.Transaction, 0), s: pendingTxSub}
+	if err := api.installFilter(ctx, f); err != nil {
+		pendingTxSub.Unsubscribe()
+		return "", err
+	}
 
 	go func() {
 		for {
@@ -138,24 +207,33 @@ func (api *FilterAPI) NewPendingTransactionFilter(fullTx *bool) rpc.ID {
 				api.filtersMu.Unlock()
 			case <-pendingTxSub.Err():
 				api.filtersMu.Lock()
-				delete(api.filters, pendingTxSub.ID)
+				api.removeFilter(pendingTxSub.ID)
 				api.filtersMu.Unlock()
 				return
 			}
 		}
 	}()
 
-	return pendingTxSub.ID
+	return pendingTxSub.ID, nil
 }
 
 // NewPendingTransactions creates a subscription that is triggered each time a
 // transaction enters the transaction pool. If fullTx is true the full tx is
-// sent to the client, otherwise the hash is sent.
-func (api *FilterAPI) NewPendingTransactions(ctx context.Context, fullTx *bool) (*rpc.Subscription, error) {
+// sent to the client, otherwise the hash is sent. An optional filter expression
+// (e.g. `to == 0x... && value > 1e18`) restricts the delivered transactions to
+// the matching ones.
+func (api *FilterAPI) NewPendingTransactions(ctx context.Context, fullTx *bool, filter *string) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
 	}
+	var txFilter *txFilter
+	if filter != nil && *filter != "" {
+		var err error
+		if txFilter, err = newTxFilter(*filter); err != nil {
+			return nil, err
+		}
+	}
 
 	rpcSub := notifier.CreateSubscription()
 
@@ -165,6 +243,7 @@ func (api *FilterAPI) NewPendingTransactions(ctx context.Context, fullTx *bool)
 		defer pendingTxSub.Unsubscribe()
 
 		chainConfig := api.sys.backend.ChainConfig()
+		signer := types.LatestSigner(chainConfig)
 
 		for {
 			select {
@@ -173,6 +252,9 @@ func (api *FilterAPI) NewPendingTransactions(ctx context.Context, fullTx *bool)
 				// TODO(rjl493456442) Send a batch of tx hashes in one notification
 				latest := api.sys.backend.CurrentHeader()
 				for _, tx := range txs {
+					if txFilter != nil && !txFilter.match(tx, signer) {
+						continue
+					}
 					if fullTx != nil && *fullTx {
 						rpcTx := ethapi.NewRPCPendingTransaction(tx, latest, chainConfig)
 						notifier.Notify(rpcSub.ID, rpcTx)
@@ -193,15 +275,17 @@ func (api *FilterAPI) NewPendingTransactions(ctx context.Context, fullTx *bool)
 
 // NewBlockFilter creates a filter that fetches blocks that are imported into the chain.
 // It is part of the filter package since polling goes with eth_getFilterChanges.
-func (api *FilterAPI) NewBlockFilter() rpc.ID {
+func (api *FilterAPI) NewBlockFilter(ctx context.Context) (rpc.ID, error) {
 	var (
 		headers   = make(chan *types.Header)
 		headerSub = api.events.SubscribeNewHeads(headers)
 	)
 
-	api.filtersMu.Lock()
-	api.filters[headerSub.ID] = &filter{typ: BlocksSubscription, deadline: time.NewTimer(api.timeout), hashes: make([]common.Hash, 0), s: headerSub}
-	api.filtersMu.Unlock()
+	f := &filter{typ: BlocksSubscription, hashes: make([]common.Hash, 0), s: headerSub}
+	if err := api.installFilter(ctx, f); err != nil {
+		headerSub.Unsubscribe()
+		return "", err
+	}
 
 	go func() {
 		for {
@@ -214,27 +298,29 @@ func (api *FilterAPI) NewBlockFilter() rpc.ID {
 				api.filtersMu.Unlock()
 			case <-headerSub.Err():
 				api.filtersMu.Lock()
-				delete(api.filters, headerSub.ID)
+				api.removeFilter(headerSub.ID)
 				api.filtersMu.Unlock()
 				return
 			}
 		}
 	}()
 
-	return headerSub.ID
+	return headerSub.ID, nil
 }
 
 // NewSideBlockFilter creates a filter that fetches blocks that are imported into the chain with a non-canonical status.
 // It is part of the filter package since polling goes with eth_getFilterChanges.
-func (api *FilterAPI) NewSideBlockFilter() rpc.ID {
+func (api *FilterAPI) NewSideBlockFilter(ctx context.Context) (rpc.ID, error) {
 	var (
 		headers   = make(chan *types.Header)
 		headerSub = api.events.SubscribeNewSideHeads(headers)
 	)
 
-	api.filtersMu.Lock()
-	api.filters[headerSub.ID] = &filter{typ: SideBlocksSubscription, deadline: time.NewTimer(api.timeout), hashes: make([]common.Hash, 0), s: headerSub}
-	api.filtersMu.Unlock()
+	f := &filter{typ: SideBlocksSubscription, hashes: make([]common.Hash, 0), s: headerSub}
+	if err := api.installFilter(ctx, f); err != nil {
+		headerSub.Unsubscribe()
+		return "", err
+	}
 
 	go func() {
 		for {
@@ -247,14 +333,14 @@ func (api *FilterAPI) NewSideBlockFilter() rpc.ID {
 				api.filtersMu.Unlock()
 			case <-headerSub.Err():
 				api.filtersMu.Lock()
-				delete(api.filters, headerSub.ID)
+				api.removeFilter(headerSub.ID)
 				api.filtersMu.Unlock()
 				return
 			}
 		}
 	}()
 
-	return headerSub.ID
+	return headerSub.ID, nil
 }
 
 // NewHeads send a notification each time a new (header) block is appended to the chain.
@@ -368,16 +454,18 @@ type FilterCriteria ethereum.FilterQuery
 // again but with the removed property set to true.
 //
 // In case "fromBlock" > "toBlock" an error is returned.
-func (api *FilterAPI) NewFilter(crit FilterCriteria) (rpc.ID, error) {
+func (api *FilterAPI) NewFilter(ctx context.Context, crit FilterCriteria) (rpc.ID, error) {
 	logs := make(chan []*types.Log)
 	logsSub, err := api.events.SubscribeLogs(ethereum.FilterQuery(crit), logs)
 	if err != nil {
 		return "", err
 	}
 
-	api.filtersMu.Lock()
-	api.filters[logsSub.ID] = &filter{typ: LogsSubscription, crit: crit, deadline: time.NewTimer(api.timeout), logs: make([]*types.Log, 0), s: logsSub}
-	api.filtersMu.Unlock()
+	f := &filter{typ: LogsSubscription, crit: crit, logs: make([]*types.Log, 0), s: logsSub}
+	if err := api.installFilter(ctx, f); err != nil {
+		logsSub.Unsubscribe()
+		return "", err
+	}
 
 	go func() {
 		for {
@@ -390,7 +478,7 @@ func (api *FilterAPI) NewFilter(crit FilterCriteria) (rpc.ID, error) {
 				api.filtersMu.Unlock()
 			case <-logsSub.Err():
 				api.filtersMu.Lock()
-				delete(api.filters, logsSub.ID)
+				api.removeFilter(logsSub.ID)
 				api.filtersMu.Unlock()
 				return
 			}
@@ -436,10 +524,7 @@ func (api *FilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*type
 // UninstallFilter removes the filter with the given filter id.
 func (api *FilterAPI) UninstallFilter(id rpc.ID) bool {
 	api.filtersMu.Lock()
-	f, found := api.filters[id]
-	if found {
-		delete(api.filters, id)
-	}
+	f, found := api.removeFilter(id)
 	api.filtersMu.Unlock()
 	if found {
 		f.s.Unsubscribe()
@@ -502,7 +587,7 @@ func (api *FilterAPI) GetFilterChanges(id rpc.ID) (interface{}, error) {
 			// receive timer value and reset timer
 			<-f.deadline.C
 		}
-		f.deadline.Reset(api.timeout)
+		f.deadline.Reset(f.timeout)
 
 		switch f.typ {
 		case BlocksSubscription, SideBlocksSubscription:
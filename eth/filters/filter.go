@@ -23,6 +23,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/bloombits"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
 )
@@ -301,6 +302,15 @@ func (f *Filter) blockLogs(ctx context.Context, header *types.Header) ([]*types.
 // skipFilter signals all logs of the given block are requested.
 func (f *Filter) checkMatches(ctx context.Context, header *types.Header) ([]*types.Log, error) {
 	hash := header.Hash()
+
+	// If the filter is restricted to a set of addresses and the block logs are
+	// not cached yet, load only the logs of those addresses from the log index
+	// instead of decoding all the receipts of the block.
+	if len(f.addresses) > 0 && !f.sys.logsCache.Contains(hash) {
+		if logs, ok := rawdb.ReadBlockLogsByAddress(f.sys.backend.ChainDb(), hash, header.Number.Uint64(), f.addresses); ok {
+			return f.logIndexMatches(ctx, hash, header.Number.Uint64(), logs)
+		}
+	}
 	// Logs in cache are partially filled with context data
 	// such as tx index, block hash, etc.
 	// Notably tx hash is NOT filled in because it needs
@@ -331,6 +341,26 @@ func (f *Filter) checkMatches(ctx context.Context, header *types.Header) ([]*typ
 	return logs, nil
 }
 
+// logIndexMatches filters the address-matching logs of a block loaded from the log
+// index by topics, and fills in the transaction hashes of the remaining ones.
+func (f *Filter) logIndexMatches(ctx context.Context, hash common.Hash, number uint64, logs []*types.Log) ([]*types.Log, error) {
+	logs = filterLogs(logs, nil, nil, nil, f.topics)
+	if len(logs) == 0 {
+		return nil, nil
+	}
+	body, err := f.sys.backend.GetBody(ctx, hash, rpc.BlockNumber(number))
+	if err != nil {
+		return nil, err
+	}
+	for _, log := range logs {
+		if log.TxIndex >= uint(len(body.Transactions)) {
+			return nil, errors.New("log index references missing transaction")
+		}
+		log.TxHash = body.Transactions[log.TxIndex].Hash()
+	}
+	return logs, nil
+}
+
 // pendingLogs returns the logs matching the filter criteria within the pending block.
 func (f *Filter) pendingLogs() []*types.Log {
 	block, receipts := f.sys.backend.PendingBlockAndReceipts()
@@ -41,14 +41,19 @@ import (
 
 // Config represents the configuration of the filter system.
 type Config struct {
-	LogCacheSize int           // maximum number of cached blocks (default: 32)
-	Timeout      time.Duration // how long filters stay active (default: 5min)
+	LogCacheSize     int           // maximum number of cached blocks (default: 32)
+	Timeout          time.Duration // how long filters stay active (default: 5min)
+	PendingTxTimeout time.Duration // how long pending transaction filters stay active (default: Timeout)
+	FilterLimit      int           // maximum number of filters installed per remote client (default: unlimited)
 }
 
 func (cfg Config) withDefaults() Config {
 	if cfg.Timeout == 0 {
 		cfg.Timeout = 5 * time.Minute
 	}
+	if cfg.PendingTxTimeout == 0 {
+		cfg.PendingTxTimeout = cfg.Timeout
+	}
 	if cfg.LogCacheSize == 0 {
 		cfg.LogCacheSize = 32
 	}
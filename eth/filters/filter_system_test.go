@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"math/big"
 	"math/rand"
+	"net/http/httptest"
 	"reflect"
 	"runtime"
 	"testing"
@@ -334,7 +335,7 @@ func TestPendingTxFilter(t *testing.T) {
 		hashes []common.Hash
 	)
 
-	fid0 := api.NewPendingTransactionFilter(nil)
+	fid0, _ := api.NewPendingTransactionFilter(context.Background(), nil)
 
 	time.Sleep(1 * time.Second)
 	backend.txFeed.Send(core.NewTxsEvent{Txs: transactions})
@@ -391,7 +392,7 @@ func TestPendingTxFilterFullTx(t *testing.T) {
 	)
 
 	fullTx := true
-	fid0 := api.NewPendingTransactionFilter(&fullTx)
+	fid0, _ := api.NewPendingTransactionFilter(context.Background(), &fullTx)
 
 	time.Sleep(1 * time.Second)
 	backend.txFeed.Send(core.NewTxsEvent{Txs: transactions})
@@ -461,7 +462,7 @@ func TestLogFilterCreation(t *testing.T) {
 	)
 
 	for i, test := range testCases {
-		id, err := api.NewFilter(test.crit)
+		id, err := api.NewFilter(context.Background(), test.crit)
 		if err != nil && test.success {
 			t.Errorf("expected filter creation for case %d to success, got %v", i, err)
 		}
@@ -495,7 +496,7 @@ func TestInvalidLogFilterCreation(t *testing.T) {
 	}
 
 	for i, test := range testCases {
-		if _, err := api.NewFilter(test); err == nil {
+		if _, err := api.NewFilter(context.Background(), test); err == nil {
 			t.Errorf("Expected NewFilter for case #%d to fail", i)
 		}
 	}
@@ -607,7 +608,7 @@ func TestLogFilter(t *testing.T) {
 
 	// create all filters
 	for i := range testCases {
-		testCases[i].id, _ = api.NewFilter(testCases[i].crit)
+		testCases[i].id, _ = api.NewFilter(context.Background(), testCases[i].crit)
 	}
 
 	// raise events
@@ -921,7 +922,7 @@ func TestLightFilterLogs(t *testing.T) {
 	}
 	// create all filters
 	for i := range testCases {
-		id, err := api.NewFilter(testCases[i].crit)
+		id, err := api.NewFilter(context.Background(), testCases[i].crit)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -1008,7 +1009,7 @@ func TestPendingTxFilterDeadlock(t *testing.T) {
 	// timeout either in 100ms or 200ms
 	subs := make([]*Subscription, 20)
 	for i := 0; i < len(subs); i++ {
-		fid := api.NewPendingTransactionFilter(nil)
+		fid, _ := api.NewPendingTransactionFilter(context.Background(), nil)
 		f, ok := api.filters[fid]
 		if !ok {
 			t.Fatalf("Filter %s should exist", fid)
@@ -1037,6 +1038,93 @@ func TestPendingTxFilterDeadlock(t *testing.T) {
 	}
 }
 
+// TestFilterLimit tests that remote clients can't install more filters than
+// allowed, while local ones aren't limited.
+func TestFilterLimit(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		_, sys = newTestFilterSystem(t, db, Config{FilterLimit: 2})
+		api    = NewFilterAPI(sys, false)
+		server = rpc.NewServer()
+	)
+	defer server.Stop()
+	if err := server.RegisterName("eth", api); err != nil {
+		t.Fatal(err)
+	}
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	client, err := rpc.Dial(httpsrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var ids []rpc.ID
+	for i := 0; i < 2; i++ {
+		var id rpc.ID
+		if err := client.Call(&id, "eth_newBlockFilter"); err != nil {
+			t.Fatalf("filter %d not installed: %v", i, err)
+		}
+		ids = append(ids, id)
+	}
+	var id rpc.ID
+	if err := client.Call(&id, "eth_newPendingTransactionFilter"); err == nil || err.Error() != errFilterLimit.Error() {
+		t.Fatalf("wrong error installing filter beyond the limit: %v", err)
+	}
+	// Uninstalling a filter frees its slot
+	var uninstalled bool
+	if err := client.Call(&uninstalled, "eth_uninstallFilter", ids[0]); err != nil || !uninstalled {
+		t.Fatalf("filter not uninstalled: %v", err)
+	}
+	if err := client.Call(&id, "eth_newPendingTransactionFilter"); err != nil {
+		t.Fatalf("filter not installed after uninstall: %v", err)
+	}
+	// Local clients are not limited
+	for i := 0; i < 3; i++ {
+		if _, err := api.NewBlockFilter(context.Background()); err != nil {
+			t.Fatalf("local filter %d not installed: %v", i, err)
+		}
+	}
+}
+
+// TestPendingTxTimeout tests that pending transaction filters are uninstalled
+// after their own timeout, while other filters stay installed.
+func TestPendingTxTimeout(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		_, sys = newTestFilterSystem(t, db, Config{Timeout: time.Minute, PendingTxTimeout: 100 * time.Millisecond})
+		api    = NewFilterAPI(sys, false)
+	)
+	pendingID, err := api.NewPendingTransactionFilter(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("pending transaction filter not installed: %v", err)
+	}
+	blockID, err := api.NewBlockFilter(context.Background())
+	if err != nil {
+		t.Fatalf("block filter not installed: %v", err)
+	}
+	api.filtersMu.Lock()
+	sub := api.filters[pendingID].s
+	api.filtersMu.Unlock()
+
+	select {
+	case <-sub.Err():
+	case <-time.After(1 * time.Second):
+		t.Fatalf("pending transaction filter not uninstalled")
+	}
+	if _, err := api.GetFilterChanges(pendingID); err == nil {
+		t.Fatalf("pending transaction filter still installed")
+	}
+	if _, err := api.GetFilterChanges(blockID); err != nil {
+		t.Fatalf("block filter uninstalled: %v", err)
+	}
+}
+
 func flattenLogs(pl [][]*types.Log) []*types.Log {
 	var logs []*types.Log
 	for _, l := range pl {
@@ -390,3 +390,97 @@ func TestFilters(t *testing.T) {
 		}
 	})
 }
+
+// Tests that address-filtered queries are answered from the log index when the
+// blocks are indexed, without touching their receipts.
+func TestFilterLogIndex(t *testing.T) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		_, sys = newTestFilterSystem(t, db, Config{})
+
+		key, _ = crypto.GenerateKey()
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		signer = types.LatestSigner(params.TestChainConfig)
+
+		// Contracts emitting a single log with their topic on every call
+		topic1    = common.HexToHash("0x01")
+		topic2    = common.HexToHash("0x02")
+		contract1 = common.Address{0xfe}
+		contract2 = common.Address{0xff}
+		logCode   = func(topic common.Hash) []byte {
+			return append(append([]byte{0x7f}, topic.Bytes()...), 0x60, 0x00, 0x60, 0x00, 0xa1, 0x00)
+		}
+		gspec = &genesisT.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: genesisT.GenesisAlloc{
+				addr:      {Balance: big.NewInt(vars.Ether)},
+				contract1: {Balance: big.NewInt(0), Code: logCode(topic1)},
+				contract2: {Balance: big.NewInt(0), Code: logCode(topic2)},
+			},
+			BaseFee: big.NewInt(vars.InitialBaseFee),
+		}
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 3, func(i int, gen *core.BlockGen) {
+		for j, to := range []common.Address{contract1, contract2} {
+			to := to
+			tx, _ := types.SignTx(types.NewTx(&types.LegacyTx{
+				Nonce:    uint64(2*i + j),
+				GasPrice: gen.BaseFee(),
+				Gas:      30000,
+				To:       &to,
+			}), signer, key)
+			gen.AddTx(tx)
+		}
+	})
+	config := core.DefaultCacheConfigWithScheme(rawdb.HashScheme)
+	config.LogIndex = true
+
+	var l uint64
+	bc, err := core.NewBlockChain(db, config, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, &l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bc.Stop()
+	if _, err := bc.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	// Drop the receipts, only the log index can answer the queries now
+	for _, block := range blocks {
+		if !rawdb.HasBlockLogs(db, block.Hash(), block.NumberU64()) {
+			t.Fatalf("block #%d not indexed", block.NumberU64())
+		}
+		rawdb.DeleteReceipts(db, block.Hash(), block.NumberU64())
+	}
+	logs, err := sys.NewRangeFilter(1, 3, []common.Address{contract2}, nil).Logs(context.Background())
+	if err != nil {
+		t.Fatalf("failed to filter logs: %v", err)
+	}
+	if len(logs) != len(blocks) {
+		t.Fatalf("log count mismatch: have %d, want %d", len(logs), len(blocks))
+	}
+	for i, log := range logs {
+		block := blocks[i]
+		if log.Address != contract2 || len(log.Topics) != 1 || log.Topics[0] != topic2 ||
+			log.BlockNumber != block.NumberU64() || log.BlockHash != block.Hash() ||
+			log.TxIndex != 1 || log.Index != 1 || log.TxHash != block.Transactions()[1].Hash() {
+			t.Fatalf("log %d mismatch: %+v", i, log)
+		}
+	}
+	// Topic filters still apply to the logs loaded from the index
+	logs, err = sys.NewRangeFilter(1, 3, []common.Address{contract1, contract2}, [][]common.Hash{{topic1}}).Logs(context.Background())
+	if err != nil {
+		t.Fatalf("failed to filter logs: %v", err)
+	}
+	if len(logs) != len(blocks) {
+		t.Fatalf("topic filtered log count mismatch: have %d, want %d", len(logs), len(blocks))
+	}
+	for i, log := range logs {
+		if log.Address != contract1 || log.Index != 0 || log.TxHash != blocks[i].Transactions()[0].Hash() {
+			t.Fatalf("topic filtered log %d mismatch: %+v", i, log)
+		}
+	}
+	// Queries without addresses are not served from the index
+	if _, err := sys.NewRangeFilter(1, 3, nil, [][]common.Hash{{topic1}}).Logs(context.Background()); err == nil {
+		t.Fatal("query without addresses answered without receipts")
+	}
+}
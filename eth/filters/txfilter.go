@@ -0,0 +1,414 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// maxTxFilterLength is the maximum length of a transaction filter expression,
+// keeping the per transaction evaluation cost bounded.
+const maxTxFilterLength = 1024
+
+var errEmptyTxFilter = errors.New("empty transaction filter")
+
+// filterKind is the type of a value on the transaction filter evaluation stack.
+type filterKind int
+
+const (
+	kindNull filterKind = iota // Absent value, e.g. the recipient of a contract creation
+	kindNum                    // Integer value, addresses and selectors included
+	kindBool                   // Result of comparisons and logical operators
+)
+
+// filterValue is a single value on the transaction filter evaluation stack.
+type filterValue struct {
+	kind filterKind
+	num  *big.Int
+	b    bool
+}
+
+// txField extracts a field of a transaction as a filter value.
+type txField func(tx *types.Transaction, signer types.Signer) filterValue
+
+// txFields are the transaction fields which may be referenced in filters.
+var txFields = map[string]txField{
+	"from": func(tx *types.Transaction, signer types.Signer) filterValue {
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			return filterValue{kind: kindNull}
+		}
+		return filterValue{kind: kindNum, num: new(big.Int).SetBytes(from.Bytes())}
+	},
+	"to": func(tx *types.Transaction, signer types.Signer) filterValue {
+		if tx.To() == nil {
+			return filterValue{kind: kindNull}
+		}
+		return filterValue{kind: kindNum, num: new(big.Int).SetBytes(tx.To().Bytes())}
+	},
+	"value": func(tx *types.Transaction, signer types.Signer) filterValue {
+		return filterValue{kind: kindNum, num: tx.Value()}
+	},
+	"gas": func(tx *types.Transaction, signer types.Signer) filterValue {
+		return filterValue{kind: kindNum, num: new(big.Int).SetUint64(tx.Gas())}
+	},
+	"gasPrice": func(tx *types.Transaction, signer types.Signer) filterValue {
+		return filterValue{kind: kindNum, num: tx.GasPrice()}
+	},
+	"maxFeePerGas": func(tx *types.Transaction, signer types.Signer) filterValue {
+		return filterValue{kind: kindNum, num: tx.GasFeeCap()}
+	},
+	"maxPriorityFeePerGas": func(tx *types.Transaction, signer types.Signer) filterValue {
+		return filterValue{kind: kindNum, num: tx.GasTipCap()}
+	},
+	"nonce": func(tx *types.Transaction, signer types.Signer) filterValue {
+		return filterValue{kind: kindNum, num: new(big.Int).SetUint64(tx.Nonce())}
+	},
+	"type": func(tx *types.Transaction, signer types.Signer) filterValue {
+		return filterValue{kind: kindNum, num: big.NewInt(int64(tx.Type()))}
+	},
+	"selector": func(tx *types.Transaction, signer types.Signer) filterValue {
+		if len(tx.Data()) < 4 {
+			return filterValue{kind: kindNull}
+		}
+		return filterValue{kind: kindNum, num: new(big.Int).SetBytes(tx.Data()[:4])}
+	},
+}
+
+// opcode is an instruction of a compiled transaction filter.
+type opcode int
+
+const (
+	opPush  opcode = iota // Push a literal value
+	opField               // Push a transaction field
+	opNot                 // Logical negation
+	opAnd                 // Logical conjunction
+	opOr                  // Logical disjunction
+	opEq                  // Equality
+	opNe                  // Inequality
+	opLt                  // Less than
+	opLe                  // Less than or equal
+	opGt                  // Greater than
+	opGe                  // Greater than or equal
+)
+
+// binaryOps maps the infix operators to their opcodes and precedences.
+var binaryOps = map[string]struct {
+	op   opcode
+	prec int
+}{
+	"||": {opOr, 1},
+	"&&": {opAnd, 2},
+	"==": {opEq, 3}, "!=": {opNe, 3},
+	"<": {opLt, 3}, "<=": {opLe, 3},
+	">": {opGt, 3}, ">=": {opGe, 3},
+}
+
+// instruction is a single step of a compiled transaction filter.
+type instruction struct {
+	op    opcode
+	value filterValue // Literal for opPush
+	field txField     // Accessor for opField
+}
+
+// txFilter is a server side filter expression on pending transactions, such as
+// `to == 0x... && value > 1e18`. The expression is compiled into a program for
+// a small stack machine, which is run against every transaction before it is
+// delivered to the subscriber.
+type txFilter struct {
+	program []instruction
+}
+
+// newTxFilter compiles a transaction filter expression.
+func newTxFilter(expr string) (*txFilter, error) {
+	if len(expr) > maxTxFilterLength {
+		return nil, fmt.Errorf("transaction filter too long: %d > %d", len(expr), maxTxFilterLength)
+	}
+	tokens, err := tokenizeTxFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errEmptyTxFilter
+	}
+	program, err := compileTxFilter(tokens)
+	if err != nil {
+		return nil, err
+	}
+	if kind, err := typecheckTxFilter(program); err != nil {
+		return nil, err
+	} else if kind != kindBool {
+		return nil, errors.New("transaction filter does not evaluate to a boolean")
+	}
+	return &txFilter{program: program}, nil
+}
+
+// tokenizeTxFilter splits a filter expression into identifiers, literals,
+// operators and parentheses.
+func tokenizeTxFilter(expr string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+
+		case isIdentChar(c) || c == '.':
+			j := i
+			for j < len(expr) && (isIdentChar(expr[j]) || expr[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, expr[i:j])
+			i = j
+
+		default:
+			// Try two character operators first, then single character ones
+			if i+1 < len(expr) {
+				if _, ok := binaryOps[expr[i:i+2]]; ok {
+					tokens = append(tokens, expr[i:i+2])
+					i += 2
+					continue
+				}
+			}
+			if _, ok := binaryOps[string(c)]; ok || c == '!' {
+				tokens = append(tokens, string(c))
+				i++
+				continue
+			}
+			return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+		}
+	}
+	return tokens, nil
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+// compileTxFilter converts the infix token stream into a postfix program using
+// the shunting-yard algorithm.
+func compileTxFilter(tokens []string) ([]instruction, error) {
+	var (
+		program   []instruction
+		operators []string
+		operand   = true // Whether an operand (or unary operator) is expected next
+	)
+	// flush pops operators off the stack while they bind at least as strongly
+	// as the given precedence.
+	flush := func(prec int) {
+		for len(operators) > 0 {
+			top := operators[len(operators)-1]
+			if top == "(" {
+				return
+			}
+			if top != "!" && binaryOps[top].prec < prec {
+				return
+			}
+			operators = operators[:len(operators)-1]
+			if top == "!" {
+				program = append(program, instruction{op: opNot})
+			} else {
+				program = append(program, instruction{op: binaryOps[top].op})
+			}
+		}
+	}
+	for _, token := range tokens {
+		switch {
+		case token == "(":
+			if !operand {
+				return nil, errors.New("unexpected '(' in transaction filter")
+			}
+			operators = append(operators, token)
+
+		case token == ")":
+			if operand {
+				return nil, errors.New("unexpected ')' in transaction filter")
+			}
+			flush(0)
+			if len(operators) == 0 {
+				return nil, errors.New("unbalanced ')' in transaction filter")
+			}
+			operators = operators[:len(operators)-1]
+
+		case token == "!":
+			if !operand {
+				return nil, errors.New("unexpected '!' in transaction filter")
+			}
+			operators = append(operators, token)
+
+		default:
+			if info, ok := binaryOps[token]; ok {
+				if operand {
+					return nil, fmt.Errorf("missing operand before %q in transaction filter", token)
+				}
+				flush(info.prec)
+				operators = append(operators, token)
+				operand = true
+				continue
+			}
+			if !operand {
+				return nil, fmt.Errorf("missing operator before %q in transaction filter", token)
+			}
+			ins, err := parseOperand(token)
+			if err != nil {
+				return nil, err
+			}
+			program = append(program, ins)
+			operand = false
+		}
+	}
+	if operand {
+		return nil, errors.New("incomplete transaction filter")
+	}
+	flush(0)
+	if len(operators) > 0 {
+		return nil, errors.New("unbalanced '(' in transaction filter")
+	}
+	return program, nil
+}
+
+// parseOperand converts an identifier or literal token into an instruction.
+func parseOperand(token string) (instruction, error) {
+	switch token {
+	case "true", "false":
+		return instruction{op: opPush, value: filterValue{kind: kindBool, b: token == "true"}}, nil
+	case "null":
+		return instruction{op: opPush, value: filterValue{kind: kindNull}}, nil
+	}
+	if field, ok := txFields[token]; ok {
+		return instruction{op: opField, field: field}, nil
+	}
+	if strings.HasPrefix(token, "0x") || strings.HasPrefix(token, "0X") {
+		num, ok := new(big.Int).SetString(token[2:], 16)
+		if !ok {
+			return instruction{}, fmt.Errorf("invalid hex literal %q in transaction filter", token)
+		}
+		return instruction{op: opPush, value: filterValue{kind: kindNum, num: num}}, nil
+	}
+	if c := token[0]; '0' <= c && c <= '9' {
+		// Decimal literals may use fractions and exponents (e.g. 1.5e18), as long
+		// as the final value is integral.
+		rat, ok := new(big.Rat).SetString(token)
+		if !ok || !rat.IsInt() {
+			return instruction{}, fmt.Errorf("invalid number literal %q in transaction filter", token)
+		}
+		return instruction{op: opPush, value: filterValue{kind: kindNum, num: rat.Num()}}, nil
+	}
+	return instruction{}, fmt.Errorf("unknown field %q in transaction filter", token)
+}
+
+// typecheckTxFilter verifies that all operators are applied to operands of the
+// correct type, returning the type of the filter result. Fields are typed as
+// numbers, null being a valid value for any of them.
+func typecheckTxFilter(program []instruction) (filterKind, error) {
+	var stack []filterKind
+	for _, ins := range program {
+		switch ins.op {
+		case opPush:
+			stack = append(stack, ins.value.kind)
+		case opField:
+			stack = append(stack, kindNum)
+		case opNot:
+			if stack[len(stack)-1] != kindBool {
+				return 0, errors.New("'!' applied to non-boolean operand in transaction filter")
+			}
+		default:
+			a, b := stack[len(stack)-2], stack[len(stack)-1]
+			stack = stack[:len(stack)-2]
+			switch ins.op {
+			case opAnd, opOr:
+				if a != kindBool || b != kindBool {
+					return 0, errors.New("logical operator applied to non-boolean operands in transaction filter")
+				}
+			case opEq, opNe:
+				if (a == kindBool) != (b == kindBool) {
+					return 0, errors.New("mismatched comparison operands in transaction filter")
+				}
+			default:
+				if a != kindNum || b != kindNum {
+					return 0, errors.New("ordering operator applied to non-numeric operands in transaction filter")
+				}
+			}
+			stack = append(stack, kindBool)
+		}
+	}
+	return stack[0], nil
+}
+
+// match runs the compiled filter against a transaction.
+func (f *txFilter) match(tx *types.Transaction, signer types.Signer) bool {
+	stack := make([]filterValue, 0, len(f.program))
+	for _, ins := range f.program {
+		switch ins.op {
+		case opPush:
+			stack = append(stack, ins.value)
+		case opField:
+			stack = append(stack, ins.field(tx, signer))
+		case opNot:
+			stack[len(stack)-1].b = !stack[len(stack)-1].b
+		default:
+			a, b := stack[len(stack)-2], stack[len(stack)-1]
+			stack = append(stack[:len(stack)-2], filterValue{kind: kindBool, b: evalBinary(ins.op, a, b)})
+		}
+	}
+	return stack[0].b
+}
+
+// evalBinary applies a binary operator to two type checked operands. Ordering
+// comparisons involving null are always false.
+func evalBinary(op opcode, a, b filterValue) bool {
+	switch op {
+	case opAnd:
+		return a.b && b.b
+	case opOr:
+		return a.b || b.b
+	case opEq, opNe:
+		var eq bool
+		switch {
+		case a.kind == kindBool:
+			eq = a.b == b.b
+		case a.kind == kindNull || b.kind == kindNull:
+			eq = a.kind == b.kind
+		default:
+			eq = a.num.Cmp(b.num) == 0
+		}
+		return eq == (op == opEq)
+	}
+	if a.kind == kindNull || b.kind == kindNull {
+		return false
+	}
+	cmp := a.num.Cmp(b.num)
+	switch op {
+	case opLt:
+		return cmp < 0
+	case opLe:
+		return cmp <= 0
+	case opGt:
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Tests that transaction filter expressions are evaluated correctly.
+func TestTxFilterMatch(t *testing.T) {
+	var (
+		key, _   = crypto.GenerateKey()
+		from     = crypto.PubkeyToAddress(key.PublicKey)
+		to       = common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+		signer   = types.HomesteadSigner{}
+		ether, _ = new(big.Int).SetString("1000000000000000000", 10)
+	)
+	transfer, _ := types.SignTx(types.NewTransaction(3, to, new(big.Int).Mul(ether, big.NewInt(2)), 50000, big.NewInt(1), common.FromHex("a9059cbb00")), signer, key)
+	create, _ := types.SignTx(types.NewContractCreation(0, common.Big0, 500000, big.NewInt(1), nil), signer, key)
+
+	tests := []struct {
+		expr   string
+		tx     *types.Transaction
+		expect bool
+	}{
+		{"to == 0xdeadbeef", transfer, true},
+		{"to == 0x00000000000000000000000000000000deadbeef && value > 1e18", transfer, true},
+		{"to == 0xdeadbeef && value > 2e18", transfer, false},
+		{"value >= 2e18", transfer, true},
+		{"value >= 2.5e18", transfer, false},
+		{"from == " + from.Hex(), transfer, true},
+		{"from != " + from.Hex(), transfer, false},
+		{"selector == 0xa9059cbb", transfer, true},
+		{"selector == 0xa9059cbb", create, false},
+		{"to == null", create, true},
+		{"to == null", transfer, false},
+		{"to > 0", create, false},
+		{"!(to == null) || nonce == 0", create, true},
+		{"!(to == null) || nonce == 0", transfer, true},
+		{"nonce < 3 || gas < 40000", transfer, false},
+		{"nonce == 3 && (gas < 40000 || gasPrice == 1)", transfer, true},
+		{"nonce == 3 && gas < 40000 || gasPrice == 1", transfer, true},
+		{"type == 0 && true", transfer, true},
+	}
+	for i, tt := range tests {
+		filter, err := newTxFilter(tt.expr)
+		if err != nil {
+			t.Fatalf("test %d: failed to compile %q: %v", i, tt.expr, err)
+		}
+		if have := filter.match(tt.tx, signer); have != tt.expect {
+			t.Errorf("test %d: %q match mismatch: have %v, want %v", i, tt.expr, have, tt.expect)
+		}
+	}
+}
+
+// Tests that malformed transaction filter expressions are rejected.
+func TestTxFilterInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"value",
+		"value >",
+		"> value",
+		"unknown == 1",
+		"value > 1.5",
+		"(value > 1",
+		"value > 1)",
+		"value > 1 &&",
+		"value == true",
+		"value && true",
+		"!value",
+		"to < null",
+		"value > 1 value",
+		"value # 1",
+		"0xzz == to",
+	}
+	for _, expr := range tests {
+		if _, err := newTxFilter(expr); err == nil {
+			t.Errorf("expression %q: expected compile error", expr)
+		}
+	}
+}
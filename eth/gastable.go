@@ -0,0 +1,121 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+	"github.com/ethereum/go-ethereum/params/vars"
+)
+
+// GasTable is the gas cost schedule active at a block.
+type GasTable struct {
+	Block   uint64                  `json:"block"`
+	Opcodes map[string]vm.OpcodeGas `json:"opcodes"`
+	Params  map[string]uint64       `json:"params"` // Gas parameters not tied to a single opcode
+}
+
+// GasCostChange is a single gas cost differing between two gas tables. From or
+// To is nil if the cost is undefined at the respective block, e.g. for opcodes
+// introduced in between.
+type GasCostChange struct {
+	Name string      `json:"name"`
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// GasTableDiff is the difference between the gas tables at two blocks.
+type GasTableDiff struct {
+	From    uint64          `json:"from"`
+	To      uint64          `json:"to"`
+	Opcodes []GasCostChange `json:"opcodes"`
+	Params  []GasCostChange `json:"params"`
+}
+
+// gasTableAt returns the gas table of the given chain config at a block with
+// the given number and timestamp.
+func gasTableAt(config ctypes.ChainConfigurator, number uint64, time uint64) (*GasTable, error) {
+	bn := new(big.Int).SetUint64(number)
+	opcodes, err := vm.LookupGasTable(config, bn, &time)
+	if err != nil {
+		return nil, err
+	}
+	enabled := func(block func() *uint64, timestamp func() *uint64) bool {
+		if config.IsEnabled(block, bn) {
+			return true
+		}
+		return timestamp != nil && config.IsEnabledByTime(timestamp, &time)
+	}
+	costs := map[string]uint64{
+		"txGas":                 vars.TxGas,
+		"txGasContractCreation": vars.TxGas,
+		"txDataZeroGas":         vars.TxDataZeroGas,
+		"txDataNonZeroGas":      vars.TxDataNonZeroGasFrontier,
+	}
+	if enabled(config.GetEIP2Transition, nil) {
+		costs["txGasContractCreation"] = vars.TxGasContractCreation
+	}
+	if enabled(config.GetEIP2028Transition, nil) {
+		costs["txDataNonZeroGas"] = vars.TxDataNonZeroGasEIP2028
+	}
+	if enabled(config.GetEIP2930Transition, nil) {
+		costs["txAccessListAddressGas"] = vars.TxAccessListAddressGas
+		costs["txAccessListStorageKeyGas"] = vars.TxAccessListStorageKeyGas
+	}
+	if enabled(config.GetEIP2929Transition, nil) {
+		costs["coldAccountAccessCost"] = vars.ColdAccountAccessCostEIP2929
+		costs["coldSloadCost"] = vars.ColdSloadCostEIP2929
+		costs["warmStorageReadCost"] = vars.WarmStorageReadCostEIP2929
+	}
+	if enabled(config.GetEIP3860Transition, config.GetEIP3860TransitionTime) {
+		costs["initCodeWordGas"] = vars.InitCodeWordGas
+	}
+	return &GasTable{Block: number, Opcodes: opcodes, Params: costs}, nil
+}
+
+// diffGasTables returns the gas costs differing between two gas tables, sorted
+// by name.
+func diffGasTables(from, to *GasTable) *GasTableDiff {
+	return &GasTableDiff{
+		From:    from.Block,
+		To:      to.Block,
+		Opcodes: diffGasCosts(from.Opcodes, to.Opcodes),
+		Params:  diffGasCosts(from.Params, to.Params),
+	}
+}
+
+// diffGasCosts returns the entries differing between two cost maps.
+func diffGasCosts[T comparable](from, to map[string]T) []GasCostChange {
+	changes := []GasCostChange{}
+	for name, cost := range from {
+		if next, ok := to[name]; !ok {
+			changes = append(changes, GasCostChange{Name: name, From: cost})
+		} else if next != cost {
+			changes = append(changes, GasCostChange{Name: name, From: cost, To: next})
+		}
+	}
+	for name, cost := range to {
+		if _, ok := from[name]; !ok {
+			changes = append(changes, GasCostChange{Name: name, To: cost})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
@@ -0,0 +1,66 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/vars"
+)
+
+// Tests that the gas table diff at the Classic Phoenix fork reports the changes
+// introduced by EIP-1344, EIP-1884 and EIP-2028.
+func TestGasTableDiffPhoenix(t *testing.T) {
+	const phoenix = 10_500_839
+
+	before, err := gasTableAt(params.ClassicChainConfig, phoenix-1, 0)
+	if err != nil {
+		t.Fatalf("failed to create gas table: %v", err)
+	}
+	after, err := gasTableAt(params.ClassicChainConfig, phoenix, 0)
+	if err != nil {
+		t.Fatalf("failed to create gas table: %v", err)
+	}
+	if _, ok := before.Opcodes["CHAINID"]; ok {
+		t.Error("CHAINID defined before Phoenix")
+	}
+	if have, want := after.Opcodes["SLOAD"], (vm.OpcodeGas{Constant: 800}); have != want {
+		t.Errorf("SLOAD cost mismatch: have %+v, want %+v", have, want)
+	}
+	diff := diffGasTables(before, after)
+	if diff.From != phoenix-1 || diff.To != phoenix {
+		t.Errorf("range mismatch: have %d-%d", diff.From, diff.To)
+	}
+	var names []string
+	for _, change := range diff.Opcodes {
+		names = append(names, change.Name)
+	}
+	if want := []string{"BALANCE", "CHAINID", "EXTCODEHASH", "SELFBALANCE", "SLOAD"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("opcode changes mismatch: have %v, want %v", names, want)
+	}
+	want := []GasCostChange{{Name: "txDataNonZeroGas", From: vars.TxDataNonZeroGasFrontier, To: vars.TxDataNonZeroGasEIP2028}}
+	if !reflect.DeepEqual(diff.Params, want) {
+		t.Errorf("param changes mismatch: have %+v, want %+v", diff.Params, want)
+	}
+	// A table compared with itself has no differences
+	if diff := diffGasTables(after, after); len(diff.Opcodes) != 0 || len(diff.Params) != 0 {
+		t.Errorf("unexpected differences: %+v", diff)
+	}
+}
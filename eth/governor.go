@@ -0,0 +1,169 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// governorInterval is the time between two load measurements.
+	governorInterval = 5 * time.Second
+
+	// governorRPCWindow is the time window of RPC calls the serving time
+	// percentile is computed over.
+	governorRPCWindow = time.Minute
+
+	// governorRelease is the fraction of the thresholds the load needs to drop
+	// below before the import is sped up again, avoiding flapping.
+	governorRelease = 0.8
+)
+
+var governorThrottledGauge = metrics.NewRegisteredGauge("eth/governor/throttled", nil)
+
+// importGovernor is an admission controller slowing down block retrieval and
+// import while the node is under load, keeping serving nodes usable during the
+// catch-up bursts following brief disconnects.
+type importGovernor struct {
+	config   ethconfig.ImportGovernorConfig
+	throttle func(throttled bool) // Callback slowing down or restoring the import
+
+	rpcLatency func() time.Duration // Measures the recent RPC serving time percentile
+	ioWait     func() int           // Measures the IO wait percentage since the last call
+
+	throttled bool
+	quit      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// newImportGovernor creates an import governor for the given thresholds, or nil
+// if none of them is enabled.
+func newImportGovernor(config ethconfig.ImportGovernorConfig, throttle func(bool)) *importGovernor {
+	if config.RPCLatency <= 0 && config.IOWait <= 0 {
+		return nil
+	}
+	return &importGovernor{
+		config:   config,
+		throttle: throttle,
+		rpcLatency: func() time.Duration {
+			return rpc.ServeTimePercentile(0.99, governorRPCWindow)
+		},
+		ioWait: newIOWaitSampler(),
+		quit:   make(chan struct{}),
+	}
+}
+
+// newIOWaitSampler returns a function measuring the average percentage of CPU
+// time spent waiting on IO since its previous invocation.
+func newIOWaitSampler() func() int {
+	var (
+		prev     metrics.CPUStats
+		prevTime = time.Now()
+	)
+	metrics.ReadCPUStats(&prev)
+
+	return func() int {
+		var stats metrics.CPUStats
+		metrics.ReadCPUStats(&stats)
+		now := time.Now()
+
+		elapsed := now.Sub(prevTime).Seconds()
+		wait := stats.GlobalWait - prev.GlobalWait
+		prev, prevTime = stats, now
+
+		if elapsed <= 0 {
+			return 0
+		}
+		return int(wait / elapsed / float64(runtime.NumCPU()) * 100)
+	}
+}
+
+// start launches the background goroutine monitoring the load.
+func (g *importGovernor) start() {
+	g.wg.Add(1)
+	go g.loop()
+}
+
+// stop terminates the background goroutine, restoring the import speed.
+func (g *importGovernor) stop() {
+	close(g.quit)
+	g.wg.Wait()
+
+	if g.throttled {
+		g.throttle(false)
+	}
+}
+
+// loop periodically measures the load and throttles the import accordingly.
+func (g *importGovernor) loop() {
+	defer g.wg.Done()
+
+	ticker := time.NewTicker(governorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			g.update(g.rpcLatency(), g.ioWait())
+		case <-g.quit:
+			return
+		}
+	}
+}
+
+// update evaluates the measured load, throttling the import if any of the
+// thresholds is exceeded, and lifting the throttling once all of them are
+// comfortably met again.
+func (g *importGovernor) update(latency time.Duration, iowait int) {
+	var (
+		latencyOver = g.config.RPCLatency > 0 && latency > g.config.RPCLatency
+		iowaitOver  = g.config.IOWait > 0 && iowait > g.config.IOWait
+	)
+	if !g.throttled {
+		if latencyOver || iowaitOver {
+			log.Warn("Node under load, slowing down block import", "rpcp99", latency, "iowait", iowait)
+			g.setThrottled(true)
+		}
+		return
+	}
+	var (
+		latencyOK = g.config.RPCLatency <= 0 || float64(latency) <= float64(g.config.RPCLatency)*governorRelease
+		iowaitOK  = g.config.IOWait <= 0 || float64(iowait) <= float64(g.config.IOWait)*governorRelease
+	)
+	if latencyOK && iowaitOK {
+		log.Info("Node load normalized, restoring block import", "rpcp99", latency, "iowait", iowait)
+		g.setThrottled(false)
+	}
+}
+
+func (g *importGovernor) setThrottled(throttled bool) {
+	g.throttled = throttled
+	g.throttle(throttled)
+
+	if throttled {
+		governorThrottledGauge.Update(1)
+	} else {
+		governorThrottledGauge.Update(0)
+	}
+}
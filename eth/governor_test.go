@@ -0,0 +1,56 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+)
+
+func TestImportGovernor(t *testing.T) {
+	if newImportGovernor(ethconfig.ImportGovernorConfig{}, nil) != nil {
+		t.Fatal("governor created without thresholds")
+	}
+	var calls []bool
+	g := newImportGovernor(ethconfig.ImportGovernorConfig{RPCLatency: 100 * time.Millisecond, IOWait: 20}, func(throttled bool) {
+		calls = append(calls, throttled)
+	})
+	tests := []struct {
+		latency   time.Duration
+		iowait    int
+		throttled bool
+	}{
+		{50 * time.Millisecond, 10, false},
+		{150 * time.Millisecond, 10, true}, // RPC latency exceeded
+		{90 * time.Millisecond, 10, true},  // Below threshold, but not below the release point
+		{50 * time.Millisecond, 10, false}, // Released
+		{50 * time.Millisecond, 30, true},  // IO wait exceeded
+		{50 * time.Millisecond, 18, true},
+		{50 * time.Millisecond, 16, false},
+	}
+	for i, tt := range tests {
+		g.update(tt.latency, tt.iowait)
+		if g.throttled != tt.throttled {
+			t.Errorf("test %d: throttle mismatch: have %v, want %v", i, g.throttled, tt.throttled)
+		}
+	}
+	if want := []bool{true, false, true, false}; len(calls) != len(want) {
+		t.Errorf("throttle callback mismatch: have %v, want %v", calls, want)
+	}
+}
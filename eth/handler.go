@@ -33,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/eth/fetcher"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/eth/protocols/snap"
@@ -81,6 +82,9 @@ type txPool interface {
 	// can decide whether to receive notifications only for newly seen transactions
 	// or also for reorged out ones.
 	SubscribeTransactions(ch chan<- core.NewTxsEvent, reorgs bool) event.Subscription
+
+	// Locals retrieves the accounts currently considered local by the pool.
+	Locals() []common.Address
 }
 
 // handlerConfig is the collection of initialization parameters to create a full
@@ -96,6 +100,7 @@ type handlerConfig struct {
 	EventMux       *event.TypeMux            // Legacy event mux, deprecate for `feed`
 	Checkpoint     *ctypes.TrustedCheckpoint // Hard coded checkpoint for sync challenges
 	RequiredBlocks map[uint64]common.Hash    // Hard coded map of required block hashes for sync challenges
+	TxGossip       ethconfig.TxGossipConfig  // Policy for outbound transaction gossip
 }
 
 type handler struct {
@@ -122,6 +127,7 @@ type handler struct {
 	eventMux      *event.TypeMux
 	txsCh         chan core.NewTxsEvent
 	txsSub        event.Subscription
+	txGossip      atomic.Pointer[ethconfig.TxGossipConfig] // Outbound transaction gossip policy, swappable at runtime
 	minedBlockSub *event.TypeMuxSubscription
 
 	requiredBlocks map[uint64]common.Hash
@@ -156,6 +162,8 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		handlerDoneCh:  make(chan struct{}),
 		handlerStartCh: make(chan struct{}),
 	}
+	h.setTxGossip(config.TxGossip)
+
 	if config.Sync == downloader.FullSync {
 		// The database seems empty as the current block is the genesis. Yet the snap
 		// block is ahead, so snap sync was enabled for this node at a certain point.
@@ -683,6 +691,13 @@ func (h *handler) BroadcastBlock(block *types.Block, propagate bool) {
 // - And, separately, as announcements to all peers which are not known to
 // already have the given transaction.
 func (h *handler) BroadcastTransactions(txs types.Transactions) {
+	gossip := h.txGossip.Load()
+	if gossip.Disabled {
+		return
+	}
+	if gossip.NoLocals {
+		txs = h.filterLocalTxs(txs)
+	}
 	var (
 		blobTxs  int // Number of blob transactions to announce only
 		largeTxs int // Number of large transactions to announce only
@@ -705,6 +720,8 @@ func (h *handler) BroadcastTransactions(txs types.Transactions) {
 			blobTxs++
 		case tx.Size() > txMaxBroadcastSize:
 			largeTxs++
+		case gossip.HashesOnly:
+			// Full broadcasts disabled by policy, announce only
 		default:
 			numDirect = int(math.Sqrt(float64(len(peers))))
 		}
@@ -731,6 +748,37 @@ func (h *handler) BroadcastTransactions(txs types.Transactions) {
 		"bcastpeers", directPeers, "bcastcount", directCount, "annpeers", annPeers, "anncount", annCount)
 }
 
+// setTxGossip replaces the outbound transaction gossip policy.
+func (h *handler) setTxGossip(config ethconfig.TxGossipConfig) {
+	h.txGossip.Store(&config)
+}
+
+// filterLocalTxs returns the subset of the given transactions which were not
+// sent from an account considered local by the transaction pool.
+func (h *handler) filterLocalTxs(txs types.Transactions) types.Transactions {
+	locals := h.txpool.Locals()
+	if len(locals) == 0 {
+		return txs
+	}
+	set := make(map[common.Address]struct{}, len(locals))
+	for _, local := range locals {
+		set[local] = struct{}{}
+	}
+	var (
+		signer   = types.LatestSigner(h.chain.Config())
+		filtered = make(types.Transactions, 0, len(txs))
+	)
+	for _, tx := range txs {
+		if from, err := types.Sender(signer, tx); err == nil {
+			if _, ok := set[from]; ok {
+				continue
+			}
+		}
+		filtered = append(filtered, tx)
+	}
+	return filtered
+}
+
 // minedBroadcastLoop sends mined blocks to connected peers.
 func (h *handler) minedBroadcastLoop() {
 	defer h.wg.Done()
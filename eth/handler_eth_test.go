@@ -32,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/p2p"
@@ -446,6 +447,52 @@ func testTransactionPropagation(t *testing.T, protocol uint) {
 	}
 }
 
+// Tests that disabling transaction gossip stops all outbound propagation, while
+// still accepting transactions into the local pool.
+func TestTransactionGossipDisabled68(t *testing.T) { testTransactionGossipDisabled(t, eth.ETH68) }
+
+func testTransactionGossipDisabled(t *testing.T, protocol uint) {
+	t.Parallel()
+
+	source := newTestHandler()
+	source.handler.snapSync.Store(false)
+	source.handler.setTxGossip(ethconfig.TxGossipConfig{Disabled: true})
+	defer source.close()
+
+	sink := newTestHandler()
+	sink.handler.synced.Store(true)
+	defer sink.close()
+
+	sourcePipe, sinkPipe := p2p.MsgPipe()
+	defer sourcePipe.Close()
+	defer sinkPipe.Close()
+
+	sourcePeer := eth.NewPeer(protocol, p2p.NewPeerPipe(enode.ID{1}, "", nil, sourcePipe), sourcePipe, source.txpool)
+	sinkPeer := eth.NewPeer(protocol, p2p.NewPeerPipe(enode.ID{0}, "", nil, sinkPipe), sinkPipe, sink.txpool)
+	defer sourcePeer.Close()
+	defer sinkPeer.Close()
+
+	go source.handler.runEthPeer(sourcePeer, func(peer *eth.Peer) error {
+		return eth.Handle((*ethHandler)(source.handler), peer)
+	})
+	go sink.handler.runEthPeer(sinkPeer, func(peer *eth.Peer) error {
+		return eth.Handle((*ethHandler)(sink.handler), peer)
+	})
+	txCh := make(chan core.NewTxsEvent, 1024)
+	sub := sink.txpool.SubscribeTransactions(txCh, false)
+	defer sub.Unsubscribe()
+
+	tx := types.NewTransaction(0, common.Address{}, big.NewInt(0), 100000, big.NewInt(0), nil)
+	tx, _ = types.SignTx(tx, types.HomesteadSigner{}, testKey)
+	source.txpool.Add([]*types.Transaction{tx}, false, false)
+
+	select {
+	case event := <-txCh:
+		t.Fatalf("transaction gossiped despite policy: %d txs", len(event.Txs))
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
 // TestCheckpointChallenge tests that post eth protocol handshake, clients perform a mutual checkpoint
 // challenge to validate each other's chains. Hash mismatches, or missing ones
 // during a fast sync should lead to the peer getting dropped.
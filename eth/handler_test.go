@@ -34,6 +34,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
 	"github.com/ethereum/go-ethereum/params/types/genesisT"
 	"github.com/holiman/uint256"
 )
@@ -93,6 +94,12 @@ func (p *testTxPool) Add(txs []*types.Transaction, local bool, sync bool) []erro
 	return make([]error, len(txs))
 }
 
+// Locals returns the accounts considered local by the pool, which is none for
+// the test pool.
+func (p *testTxPool) Locals() []common.Address {
+	return nil
+}
+
 // Pending returns all the transactions known to the pool
 func (p *testTxPool) Pending(filter txpool.PendingFilter) map[common.Address][]*txpool.LazyTransaction {
 	p.lock.RLock()
@@ -147,10 +154,16 @@ func newTestHandler() *testHandler {
 // newTestHandlerWithBlocks creates a new handler for testing purposes, with a
 // given number of initial blocks.
 func newTestHandlerWithBlocks(blocks int) *testHandler {
+	return newTestHandlerWithConfig(params.TestChainConfig, blocks)
+}
+
+// newTestHandlerWithConfig creates a new handler for testing purposes, running
+// the given chain config with a given number of initial blocks.
+func newTestHandlerWithConfig(config ctypes.ChainConfigurator, blocks int) *testHandler {
 	// Create a database pre-initialize with a genesis block
 	db := rawdb.NewMemoryDatabase()
 	gspec := &genesisT.Genesis{
-		Config: params.TestChainConfig,
+		Config: config,
 		Alloc:  genesisT.GenesisAlloc{testAddr: {Balance: big.NewInt(1000000)}},
 	}
 	chain, _ := core.NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
@@ -0,0 +1,149 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/mmr"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// headerAccBatchSize is the number of headers appended to the accumulator in a
+// single database batch while catching up with the chain.
+const headerAccBatchSize = 16384
+
+var errHeaderAccDisabled = errors.New("header accumulator not enabled")
+
+// headerAccumulator keeps a Merkle mountain range over the canonical block
+// hashes in sync with the chain, so that any block can be proven to be part of
+// the chain against a single, recent accumulator root.
+type headerAccumulator struct {
+	chain *core.BlockChain
+	db    ethdb.Database
+	mmr   *mmr.MMR
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newHeaderAccumulator opens the header accumulator stored in the database.
+func newHeaderAccumulator(chain *core.BlockChain, db ethdb.Database) (*headerAccumulator, error) {
+	acc, err := mmr.New(db)
+	if err != nil {
+		return nil, err
+	}
+	return &headerAccumulator{
+		chain: chain,
+		db:    db,
+		mmr:   acc,
+		quit:  make(chan struct{}),
+	}, nil
+}
+
+// start launches the background goroutine following the chain head.
+func (a *headerAccumulator) start() {
+	a.wg.Add(1)
+	go a.loop()
+}
+
+// stop terminates the background goroutine.
+func (a *headerAccumulator) stop() {
+	close(a.quit)
+	a.wg.Wait()
+}
+
+// loop updates the accumulator on every new chain head.
+func (a *headerAccumulator) loop() {
+	defer a.wg.Done()
+
+	heads := make(chan core.ChainHeadEvent, 10)
+	sub := a.chain.SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	a.update()
+	for {
+		select {
+		case <-heads:
+			a.update()
+		case <-sub.Err():
+			return
+		case <-a.quit:
+			return
+		}
+	}
+}
+
+// update rolls back any leaves that are no longer canonical and appends the
+// hashes of all new canonical blocks up to the current head.
+func (a *headerAccumulator) update() {
+	var (
+		head  = a.chain.CurrentBlock().Number.Uint64()
+		size  = a.mmr.Size()
+		start = time.Now()
+	)
+	// Unwind the accumulator if the chain was rewound or reorged below its tip
+	if size > head+1 {
+		size = head + 1
+	}
+	for size > 0 {
+		leaf, err := a.mmr.Leaf(size - 1)
+		if err == nil && leaf == rawdb.ReadCanonicalHash(a.db, size-1) {
+			break
+		}
+		size--
+	}
+	if size < a.mmr.Size() {
+		log.Debug("Rewinding header accumulator", "from", a.mmr.Size(), "to", size)
+		if err := a.mmr.Truncate(size); err != nil {
+			log.Error("Failed to rewind header accumulator", "err", err)
+			return
+		}
+	}
+	// Append the new canonical hashes in batches, allowing for interruption
+	logged := time.Now()
+	for size <= head {
+		select {
+		case <-a.quit:
+			return
+		default:
+		}
+		var leaves []common.Hash
+		for ; size <= head && len(leaves) < headerAccBatchSize; size++ {
+			hash := rawdb.ReadCanonicalHash(a.db, size)
+			if hash == (common.Hash{}) {
+				log.Error("Missing canonical hash for header accumulator", "number", size)
+				return
+			}
+			leaves = append(leaves, hash)
+		}
+		if err := a.mmr.Append(leaves); err != nil {
+			log.Error("Failed to extend header accumulator", "err", err)
+			return
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Building header accumulator", "number", size-1, "head", head, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/mmr"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+)
+
+// Tests that the header accumulator follows the canonical chain, including
+// reorgs, and serves valid proofs for all canonical blocks.
+func TestHeaderAccumulator(t *testing.T) {
+	var (
+		db    = rawdb.NewMemoryDatabase()
+		gspec = &genesisT.Genesis{Config: params.TestChainConfig}
+	)
+	chain, err := core.NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 20, nil)
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	acc, err := newHeaderAccumulator(chain, db)
+	if err != nil {
+		t.Fatalf("failed to create accumulator: %v", err)
+	}
+	check := func(head uint64) {
+		t.Helper()
+
+		acc.update()
+		if size := acc.mmr.Size(); size != head+1 {
+			t.Fatalf("accumulator size mismatch: have %d, want %d", size, head+1)
+		}
+		root := acc.mmr.Root()
+		for number := uint64(0); number <= head; number++ {
+			proof, err := acc.mmr.Prove(number)
+			if err != nil {
+				t.Fatalf("block %d: failed to create proof: %v", number, err)
+			}
+			if want := rawdb.ReadCanonicalHash(db, number); proof.Leaf != want {
+				t.Fatalf("block %d: leaf mismatch: have %x, want %x", number, proof.Leaf, want)
+			}
+			if err := mmr.VerifyProof(root, proof); err != nil {
+				t.Fatalf("block %d: invalid proof: %v", number, err)
+			}
+		}
+	}
+	check(20)
+
+	// Reorg to a longer side chain forking off at block 15
+	_, fork, _ := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 30, func(i int, b *core.BlockGen) {
+		if i >= 15 {
+			b.SetCoinbase(common.Address{1})
+		}
+	})
+	if _, err := chain.InsertChain(fork[15:]); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	check(30)
+
+	// Rewind the chain below the accumulator tip
+	if err := chain.SetHead(10); err != nil {
+		t.Fatalf("failed to rewind chain: %v", err)
+	}
+	check(10)
+}
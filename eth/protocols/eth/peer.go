@@ -70,7 +70,7 @@ type Peer struct {
 	id string // Unique ID for the peer, cached
 
 	*p2p.Peer                   // The embedded P2P package peer
-	rw        p2p.MsgReadWriter // Input/output streams for snap
+	rw        p2p.MsgReadWriter // Input/output streams for eth, writes are priority scheduled
 	version   uint              // Protocol version negotiated
 
 	head            common.Hash // Latest advertised head block hash
@@ -98,10 +98,11 @@ type Peer struct {
 // NewPeer creates a wrapper for a network connection and negotiated  protocol
 // version.
 func NewPeer(version uint, p *p2p.Peer, rw p2p.MsgReadWriter, txpool TxPool) *Peer {
+	term := make(chan struct{})
 	peer := &Peer{
 		id:              p.ID().String(),
 		Peer:            p,
-		rw:              rw,
+		rw:              newPrioritizedRW(rw, term),
 		version:         version,
 		knownTxs:        newKnownCache(maxKnownTxs),
 		knownBlocks:     newKnownCache(maxKnownBlocks),
@@ -113,7 +114,7 @@ func NewPeer(version uint, p *p2p.Peer, rw p2p.MsgReadWriter, txpool TxPool) *Pe
 		reqCancel:       make(chan *cancel),
 		resDispatch:     make(chan *response),
 		txpool:          txpool,
-		term:            make(chan struct{}),
+		term:            term,
 		blockDifficulty: big.NewInt(0),
 	}
 	// Start up all the broadcasters
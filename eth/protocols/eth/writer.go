@@ -0,0 +1,188 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/p2p"
+)
+
+// msgPriority is the scheduling class of an outbound message. Lower values are
+// written to the wire first.
+type msgPriority int
+
+const (
+	// priorityHigh is used for block propagation and header traffic, which is
+	// latency critical for the health of the chain.
+	priorityHigh msgPriority = iota
+
+	// priorityNormal is used for the bulk of the sync traffic (bodies, receipts)
+	// that is not as time sensitive as block propagation.
+	priorityNormal
+
+	// priorityLow is used for transaction gossip, which may be large and is
+	// never more important than keeping up with the chain head.
+	priorityLow
+
+	numPriorities
+)
+
+// String implements fmt.Stringer.
+func (p msgPriority) String() string {
+	switch p {
+	case priorityHigh:
+		return "high"
+	case priorityNormal:
+		return "normal"
+	case priorityLow:
+		return "low"
+	default:
+		return "unknown"
+	}
+}
+
+// maxQueuedWrites is the number of pending writes per priority class that may
+// be waiting for the wire before callers block.
+const maxQueuedWrites = 16
+
+// queueDepthGauges tracks the number of writes waiting for their turn in each
+// priority class, summed over all peers.
+var queueDepthGauges [numPriorities]metrics.Gauge
+
+func init() {
+	for i := msgPriority(0); i < numPriorities; i++ {
+		queueDepthGauges[i] = metrics.NewRegisteredGauge("eth/protocols/eth/egress/queue/"+i.String(), nil)
+	}
+}
+
+// messagePriority returns the scheduling class of the given message code.
+func messagePriority(code uint64) msgPriority {
+	switch code {
+	case StatusMsg, NewBlockHashesMsg, NewBlockMsg, GetBlockHeadersMsg, BlockHeadersMsg:
+		return priorityHigh
+	case TransactionsMsg, NewPooledTransactionHashesMsg, GetPooledTransactionsMsg, PooledTransactionsMsg:
+		return priorityLow
+	default:
+		return priorityNormal
+	}
+}
+
+// writeOp is a single message waiting to be written to the network.
+type writeOp struct {
+	msg  p2p.Msg
+	prio msgPriority
+	done chan error
+}
+
+// prioritizedRW is a p2p.MsgReadWriter that funnels all outbound messages of a
+// peer through a single writer, always serving the highest priority class that
+// has anything pending. This ensures block propagation never sits behind large
+// amounts of queued transaction gossip on slow links.
+type prioritizedRW struct {
+	p2p.MsgReadWriter // Underlying network stream, reads are passed through
+
+	queues [numPriorities]chan *writeOp // Pending writes per priority class
+	term   chan struct{}                // Termination channel to stop the writer
+}
+
+// newPrioritizedRW wraps a network stream with priority scheduled writes. The
+// writer loop runs until term is closed.
+func newPrioritizedRW(rw p2p.MsgReadWriter, term chan struct{}) *prioritizedRW {
+	prw := &prioritizedRW{
+		MsgReadWriter: rw,
+		term:          term,
+	}
+	for i := range prw.queues {
+		prw.queues[i] = make(chan *writeOp, maxQueuedWrites)
+	}
+	go prw.loop()
+	return prw
+}
+
+// WriteMsg implements p2p.MsgWriter, queueing the message in its priority class
+// and blocking until it has been written to the network.
+func (prw *prioritizedRW) WriteMsg(msg p2p.Msg) error {
+	op := &writeOp{
+		msg:  msg,
+		prio: messagePriority(msg.Code),
+		done: make(chan error, 1),
+	}
+	queueDepthGauges[op.prio].Inc(1)
+	select {
+	case prw.queues[op.prio] <- op:
+	case <-prw.term:
+		queueDepthGauges[op.prio].Dec(1)
+		return errDisconnected
+	}
+	select {
+	case err := <-op.done:
+		return err
+	case <-prw.term:
+		return errDisconnected
+	}
+}
+
+// loop is the single writer of the peer, draining the queues in priority order.
+func (prw *prioritizedRW) loop() {
+	for {
+		op := prw.next()
+		if op == nil {
+			prw.drain()
+			return
+		}
+		queueDepthGauges[op.prio].Dec(1)
+		op.done <- prw.MsgReadWriter.WriteMsg(op.msg)
+	}
+}
+
+// next returns the highest priority pending write, blocking until one becomes
+// available. Nil is returned if the writer was terminated.
+func (prw *prioritizedRW) next() *writeOp {
+	for _, queue := range prw.queues {
+		select {
+		case op := <-queue:
+			return op
+		default:
+		}
+	}
+	select {
+	case op := <-prw.queues[priorityHigh]:
+		return op
+	case op := <-prw.queues[priorityNormal]:
+		return op
+	case op := <-prw.queues[priorityLow]:
+		return op
+	case <-prw.term:
+		return nil
+	}
+}
+
+// drain discards any writes left in the queues after termination, keeping the
+// queue depth gauges accurate.
+func (prw *prioritizedRW) drain() {
+	for i, queue := range prw.queues {
+		for {
+			select {
+			case <-queue:
+				queueDepthGauges[i].Dec(1)
+				continue
+			default:
+			}
+			break
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p"
+)
+
+// gatedRW is a p2p.MsgReadWriter which records the order of written messages
+// and blocks every write until it's released.
+type gatedRW struct {
+	p2p.MsgReadWriter
+
+	gate  chan struct{}
+	codes []uint64
+	lock  sync.Mutex
+}
+
+func (rw *gatedRW) WriteMsg(msg p2p.Msg) error {
+	<-rw.gate
+
+	rw.lock.Lock()
+	defer rw.lock.Unlock()
+	rw.codes = append(rw.codes, msg.Code)
+	return nil
+}
+
+// Tests that block propagation overtakes transaction gossip which was queued
+// up earlier.
+func TestPrioritizedWrites(t *testing.T) {
+	var (
+		term = make(chan struct{})
+		rw   = &gatedRW{gate: make(chan struct{})}
+		prw  = newPrioritizedRW(rw, term)
+		wg   sync.WaitGroup
+	)
+	defer close(term)
+
+	send := func(code uint64) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			prw.WriteMsg(p2p.Msg{Code: code})
+		}()
+	}
+	// Occupy the writer with a transaction broadcast, then queue up some more
+	// gossip behind it, followed by a block.
+	send(TransactionsMsg)
+	time.Sleep(50 * time.Millisecond)
+	send(TransactionsMsg)
+	send(NewPooledTransactionHashesMsg)
+	time.Sleep(50 * time.Millisecond)
+	send(NewBlockMsg)
+	time.Sleep(50 * time.Millisecond)
+
+	for i := 0; i < 4; i++ {
+		rw.gate <- struct{}{}
+	}
+	wg.Wait()
+
+	if len(rw.codes) != 4 {
+		t.Fatalf("written message count mismatch: have %d, want %d", len(rw.codes), 4)
+	}
+	if rw.codes[0] != TransactionsMsg {
+		t.Errorf("in-flight message mismatch: have %d, want %d", rw.codes[0], TransactionsMsg)
+	}
+	if rw.codes[1] != NewBlockMsg {
+		t.Errorf("block not prioritized: have %d, want %d", rw.codes[1], NewBlockMsg)
+	}
+}
+
+// Tests that writes fail once the peer is terminated.
+func TestPrioritizedWriteAfterClose(t *testing.T) {
+	term := make(chan struct{})
+	prw := newPrioritizedRW(&gatedRW{gate: make(chan struct{})}, term)
+	close(term)
+
+	if err := prw.WriteMsg(p2p.Msg{Code: NewBlockMsg}); err != errDisconnected {
+		t.Fatalf("write error mismatch: have %v, want %v", err, errDisconnected)
+	}
+}
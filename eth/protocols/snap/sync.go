@@ -42,7 +42,6 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/ethereum/go-ethereum/trie/trienode"
-	"golang.org/x/crypto/sha3"
 )
 
 const (
@@ -97,6 +96,18 @@ const (
 
 	// batchSizeThreshold is the maximum size allowed for gentrie batch.
 	batchSizeThreshold = 8 * 1024 * 1024
+
+	// healRateInterval is the minimum time between two measurements of the overall
+	// healing throughput, used to estimate the time left until healing completes.
+	healRateInterval = 8 * time.Second
+
+	// healRateMeasurementImpact is the impact a single measurement has on the
+	// overall healing throughput estimate.
+	healRateMeasurementImpact = 0.2
+
+	// maxHealPriorityAccounts is the maximum number of accounts, touched by recent
+	// blocks, waiting to be prioritized in the healer.
+	maxHealPriorityAccounts = 4096
 )
 
 var (
@@ -403,6 +414,10 @@ type SyncProgress struct {
 type SyncPending struct {
 	TrienodeHeal uint64 // Number of state trie nodes pending
 	BytecodeHeal uint64 // Number of bytecodes pending
+
+	HealScheduled uint64        // Number of state entries known to be missing by the healer
+	HealRate      float64       // Recent number of state entries healed per second
+	HealETA       time.Duration // Estimated time until healing completes (0 if unknown)
 }
 
 // SyncPeer abstracts out the methods required for a peer to be synced against
@@ -498,6 +513,12 @@ type Syncer struct {
 	bytecodeHealDups   uint64             // Number of bytecodes already processed
 	bytecodeHealNops   uint64             // Number of bytecodes not requested
 
+	healPriority  []common.Hash // Accounts touched by recent blocks, waiting to be prioritized in the healer
+	healScheduled uint64        // Number of state entries known to be missing by the healer
+	healRate      float64       // Moving average of the number of state entries healed per second
+	healRateTime  time.Time     // Time instance when the heal rate was last measured
+	healRateCount uint64        // Number of state entries healed at the last heal rate measurement
+
 	stateWriter        ethdb.Batch        // Shared batch writer used for persisting raw states
 	accountHealed      uint64             // Number of accounts downloaded during the healing stage
 	accountHealedBytes common.StorageSize // Number of raw account bytes persisted to disk during the healing stage
@@ -615,6 +636,7 @@ func (s *Syncer) Sync(root common.Hash, cancel chan struct{}) error {
 		codeTasks: make(map[common.Hash]struct{}),
 	}
 	s.statelessPeers = make(map[string]struct{})
+	s.healRate, s.healRateTime = 0, time.Time{}
 	s.lock.Unlock()
 
 	if s.startTime == (time.Time{}) {
@@ -713,6 +735,9 @@ func (s *Syncer) Sync(root common.Hash, cancel chan struct{}) error {
 			BytecodeHealSynced: s.bytecodeHealSynced,
 			BytecodeHealBytes:  s.bytecodeHealBytes,
 		}
+		if len(s.tasks) == 0 {
+			s.updateHealRate()
+		}
 		s.lock.Unlock()
 		// Wait for something to happen
 		select {
@@ -935,10 +960,66 @@ func (s *Syncer) Progress() (*SyncProgress, *SyncPending) {
 	if s.healer != nil {
 		pending.TrienodeHeal = uint64(len(s.healer.trieTasks))
 		pending.BytecodeHeal = uint64(len(s.healer.codeTasks))
+		pending.HealScheduled = s.healScheduled
+		pending.HealRate = s.healRate
+		pending.HealETA = s.healETA()
 	}
 	return s.extProgress, pending
 }
 
+// Prioritize schedules the state of the given accounts, touched by recent blocks,
+// to be healed ahead of the rest of the state. This makes it more likely that the
+// state frequently accessed via RPC is already available when healing finishes.
+func (s *Syncer) Prioritize(accounts []common.Hash) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.healPriority = append(s.healPriority, accounts...)
+	if n := len(s.healPriority); n > maxHealPriorityAccounts {
+		s.healPriority = append(s.healPriority[:0], s.healPriority[n-maxHealPriorityAccounts:]...)
+	}
+}
+
+// updateHealRate measures the healing throughput and updates its moving average,
+// along with the number of state entries still known to be missing.
+//
+// The method assumes that the caller holds the sync lock.
+func (s *Syncer) updateHealRate() {
+	s.healScheduled = uint64(s.healer.scheduler.Pending())
+
+	var (
+		now    = time.Now()
+		healed = s.trienodeHealSynced + s.bytecodeHealSynced
+	)
+	if s.healRateTime == (time.Time{}) {
+		s.healRateTime, s.healRateCount = now, healed
+		return
+	}
+	elapsed := now.Sub(s.healRateTime)
+	if elapsed < healRateInterval {
+		return
+	}
+	rate := float64(healed-s.healRateCount) / elapsed.Seconds()
+	if s.healRate == 0 {
+		s.healRate = rate
+	} else {
+		s.healRate = (1-healRateMeasurementImpact)*s.healRate + healRateMeasurementImpact*rate
+	}
+	s.healRateTime, s.healRateCount = now, healed
+}
+
+// healETA estimates the time left until healing completes, based on the number
+// of state entries known to be missing and the recent healing throughput. As new
+// missing entries are discovered while healing, the estimate is a lower bound.
+//
+// The method assumes that the caller holds the sync lock.
+func (s *Syncer) healETA() time.Duration {
+	if s.healRate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(s.healScheduled) / s.healRate * float64(time.Second))
+}
+
 // cleanAccountTasks removes account range retrieval tasks that have already been
 // completed.
 func (s *Syncer) cleanAccountTasks() {
@@ -1382,6 +1463,11 @@ func (s *Syncer) assignTrienodeHealTasks(success chan *trienodeHealResponse, fai
 	}
 	sort.Sort(sort.Reverse(idlers))
 
+	// Move the state of any recently touched accounts to the front of the queue
+	if len(s.healPriority) > 0 {
+		s.healer.scheduler.Prioritize(s.healPriority)
+		s.healPriority = nil
+	}
 	// Iterate over pending tasks and try to find a peer to retrieve with
 	for len(s.healer.trieTasks) > 0 || s.healer.scheduler.Pending() > 0 {
 		// If there are not enough trie tasks queued to fully assign, fill the
@@ -2653,7 +2739,7 @@ func (s *Syncer) onByteCodes(peer SyncPeer, id uint64, bytecodes [][]byte) error
 
 	// Cross reference the requested bytecodes with the response to find gaps
 	// that the serving node is missing
-	hasher := sha3.NewLegacyKeccak256().(crypto.KeccakState)
+	hasher := crypto.NewKeccakState()
 	hash := make([]byte, 32)
 
 	codes := make([][]byte, len(req.hashes))
@@ -2901,7 +2987,7 @@ func (s *Syncer) OnTrieNodes(peer SyncPeer, id uint64, trienodes [][]byte) error
 	// Cross reference the requested trienodes with the response to find gaps
 	// that the serving node is missing
 	var (
-		hasher = sha3.NewLegacyKeccak256().(crypto.KeccakState)
+		hasher = crypto.NewKeccakState()
 		hash   = make([]byte, 32)
 		nodes  = make([][]byte, len(req.hashes))
 		fills  uint64
@@ -3007,7 +3093,7 @@ func (s *Syncer) onHealByteCodes(peer SyncPeer, id uint64, bytecodes [][]byte) e
 
 	// Cross reference the requested bytecodes with the response to find gaps
 	// that the serving node is missing
-	hasher := sha3.NewLegacyKeccak256().(crypto.KeccakState)
+	hasher := crypto.NewKeccakState()
 	hash := make([]byte, 32)
 
 	codes := make([][]byte, len(req.hashes))
@@ -3141,8 +3227,12 @@ func (s *Syncer) reportHealProgress(force bool) {
 		accounts = fmt.Sprintf("%v@%v", log.FormatLogfmtUint64(s.accountHealed), s.accountHealedBytes.TerminalString())
 		storage  = fmt.Sprintf("%v@%v", log.FormatLogfmtUint64(s.storageHealed), s.storageHealedBytes.TerminalString())
 	)
+	s.lock.RLock()
+	eta := s.healETA()
+	s.lock.RUnlock()
+
 	log.Info("Syncing: state healing in progress", "accounts", accounts, "slots", storage,
-		"codes", bytecode, "nodes", trienode, "pending", s.healer.scheduler.Pending())
+		"codes", bytecode, "nodes", trienode, "pending", s.healer.scheduler.Pending(), "eta", common.PrettyDuration(eta))
 }
 
 // estimateRemainingSlots tries to determine roughly how many slots are left in
@@ -0,0 +1,143 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// relayAttempts is the number of times a transaction is offered to a single
+	// relay before giving up on it.
+	relayAttempts = 3
+
+	// relayRetryDelay is the initial delay between two attempts to reach the same
+	// relay. It is doubled after every failure.
+	relayRetryDelay = 500 * time.Millisecond
+
+	// relayTimeout is the maximum time a single attempt may take.
+	relayTimeout = 10 * time.Second
+)
+
+// errNoRelays is returned if a private transaction is submitted but no relays
+// were configured.
+var errNoRelays = errors.New("no private transaction relays configured")
+
+// txRelay forwards raw transactions to a set of trusted RPC endpoints instead of
+// gossiping them over the p2p network.
+type txRelay struct {
+	endpoints []string
+}
+
+// newTxRelay creates a relay forwarding to the given RPC endpoints.
+func newTxRelay(endpoints []string) *txRelay {
+	return &txRelay{endpoints: endpoints}
+}
+
+// send submits the raw transaction to all the configured relays concurrently.
+// It succeeds if at least one of the relays accepted the transaction.
+func (r *txRelay) send(ctx context.Context, hash common.Hash, raw []byte) error {
+	if len(r.endpoints) == 0 {
+		return errNoRelays
+	}
+	var (
+		wg       sync.WaitGroup
+		errs     = make([]error, len(r.endpoints))
+		accepted int
+		lock     sync.Mutex
+	)
+	for i, endpoint := range r.endpoints {
+		wg.Add(1)
+		go func(i int, endpoint string) {
+			defer wg.Done()
+
+			if errs[i] = r.sendOne(ctx, endpoint, hash, raw); errs[i] == nil {
+				lock.Lock()
+				accepted++
+				lock.Unlock()
+			}
+		}(i, endpoint)
+	}
+	wg.Wait()
+
+	if accepted == 0 {
+		return fmt.Errorf("all %d relays failed, first error: %w", len(r.endpoints), errs[0])
+	}
+	log.Info("Relayed private transaction", "hash", hash, "accepted", accepted, "relays", len(r.endpoints))
+	return nil
+}
+
+// sendOne submits the raw transaction to a single relay, retrying on transport
+// failures. Errors returned by the remote node itself are not retried, since
+// they denote a rejection of the transaction.
+func (r *txRelay) sendOne(ctx context.Context, endpoint string, hash common.Hash, raw []byte) error {
+	var (
+		delay = relayRetryDelay
+		err   error
+	)
+	for attempt := 0; attempt < relayAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(delay):
+				delay *= 2
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if err = r.call(ctx, endpoint, hash, raw); err == nil {
+			return nil
+		}
+		var rpcErr rpc.Error
+		if errors.As(err, &rpcErr) {
+			log.Debug("Private transaction rejected by relay", "relay", endpoint, "hash", hash, "err", err)
+			return err
+		}
+		log.Debug("Failed to reach transaction relay", "relay", endpoint, "hash", hash, "attempt", attempt+1, "err", err)
+	}
+	return err
+}
+
+// call performs a single eth_sendRawTransaction invocation against a relay and
+// verifies that the relay reported the expected transaction hash.
+func (r *txRelay) call(ctx context.Context, endpoint string, hash common.Hash, raw []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, relayTimeout)
+	defer cancel()
+
+	client, err := rpc.DialContext(ctx, endpoint)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	var result common.Hash
+	if err := client.CallContext(ctx, &result, "eth_sendRawTransaction", hexutil.Bytes(raw)); err != nil {
+		return err
+	}
+	if result != hash {
+		return fmt.Errorf("relay returned hash %x, want %x", result, hash)
+	}
+	return nil
+}
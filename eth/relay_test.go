@@ -0,0 +1,85 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testRelayService is a mock eth namespace accepting or rejecting raw
+// transactions.
+type testRelayService struct {
+	reject bool
+	seen   []hexutil.Bytes
+}
+
+func (s *testRelayService) SendRawTransaction(input hexutil.Bytes) (common.Hash, error) {
+	s.seen = append(s.seen, input)
+	if s.reject {
+		return common.Hash{}, errors.New("rejected")
+	}
+	return crypto.Keccak256Hash(input), nil
+}
+
+func newTestRelay(t *testing.T, service *testRelayService) string {
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", service); err != nil {
+		t.Fatalf("failed to register relay service: %v", err)
+	}
+	httpsrv := httptest.NewServer(server)
+	t.Cleanup(func() {
+		httpsrv.Close()
+		server.Stop()
+	})
+	return httpsrv.URL
+}
+
+func TestTxRelay(t *testing.T) {
+	var (
+		raw    = []byte{0x01, 0x02, 0x03}
+		hash   = crypto.Keccak256Hash(raw)
+		good   = new(testRelayService)
+		bad    = &testRelayService{reject: true}
+		goodEP = newTestRelay(t, good)
+		badEP  = newTestRelay(t, bad)
+	)
+	// A single accepting relay is enough for the submission to succeed
+	if err := newTxRelay([]string{badEP, goodEP}).send(context.Background(), hash, raw); err != nil {
+		t.Fatalf("failed to relay transaction: %v", err)
+	}
+	if len(good.seen) != 1 {
+		t.Errorf("accepting relay call count mismatch: have %d, want 1", len(good.seen))
+	}
+	// Rejections are final and must not be retried
+	if len(bad.seen) != 1 {
+		t.Errorf("rejecting relay call count mismatch: have %d, want 1", len(bad.seen))
+	}
+	if err := newTxRelay([]string{badEP}).send(context.Background(), hash, raw); err == nil {
+		t.Fatalf("relay succeeded with only rejecting endpoints")
+	}
+	if err := newTxRelay(nil).send(context.Background(), hash, raw); !errors.Is(err, errNoRelays) {
+		t.Fatalf("error mismatch: have %v, want %v", err, errNoRelays)
+	}
+}
@@ -78,8 +78,21 @@ func (h *handler) artificialFinalitySafetyLoop() {
 
 // syncTransactions starts sending all currently pending transactions to the given peer.
 func (h *handler) syncTransactions(p *eth.Peer) {
+	gossip := h.txGossip.Load()
+	if gossip.Disabled {
+		return
+	}
+	locals := make(map[common.Address]struct{})
+	if gossip.NoLocals {
+		for _, local := range h.txpool.Locals() {
+			locals[local] = struct{}{}
+		}
+	}
 	var hashes []common.Hash
-	for _, batch := range h.txpool.Pending(txpool.PendingFilter{OnlyPlainTxs: true}) {
+	for from, batch := range h.txpool.Pending(txpool.PendingFilter{OnlyPlainTxs: true}) {
+		if _, ok := locals[from]; ok {
+			continue
+		}
 		for _, tx := range batch {
 			hashes = append(hashes, tx.Hash)
 		}
@@ -32,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
 	"github.com/ethereum/go-ethereum/params/types/genesisT"
 	"github.com/ethereum/go-ethereum/triedb"
 )
@@ -91,22 +92,29 @@ func newTestHandlerWithBlocksWithOpts(blocks int, mode downloader.SyncMode, gen
 }
 
 // Tests that snap sync is disabled after a successful sync cycle.
-func TestSnapSyncDisabling68(t *testing.T) { testSnapSyncDisabling(t, eth.ETH68, snap.SNAP1) }
+func TestSnapSyncDisabling68(t *testing.T) {
+	testSnapSyncDisabling(t, params.TestChainConfig, eth.ETH68, snap.SNAP1)
+}
+
+// Tests that snap sync works with the Ethereum Classic fork schedule.
+func TestSnapSyncClassic68(t *testing.T) {
+	testSnapSyncDisabling(t, params.MordorChainConfig, eth.ETH68, snap.SNAP1)
+}
 
 // Tests that snap sync gets disabled as soon as a real block is successfully
 // imported into the blockchain.
-func testSnapSyncDisabling(t *testing.T, ethVer uint, snapVer uint) {
+func testSnapSyncDisabling(t *testing.T, config ctypes.ChainConfigurator, ethVer uint, snapVer uint) {
 	t.Parallel()
 
 	// Create an empty handler and ensure it's in snap sync mode
-	empty := newTestHandler()
+	empty := newTestHandlerWithConfig(config, 0)
 	if !empty.handler.snapSync.Load() {
 		t.Fatalf("snap sync disabled on pristine blockchain")
 	}
 	defer empty.close()
 
 	// Create a full handler and ensure snap sync ends up disabled
-	full := newTestHandlerWithBlocks(1024)
+	full := newTestHandlerWithConfig(config, 1024)
 	if full.handler.snapSync.Load() {
 		t.Fatalf("snap sync not disabled on non-empty blockchain")
 	}
@@ -155,6 +163,9 @@ func testSnapSyncDisabling(t *testing.T, ethVer uint, snapVer uint) {
 	if empty.handler.snapSync.Load() {
 		t.Fatalf("snap sync not disabled after successful synchronisation")
 	}
+	if head := empty.chain.CurrentBlock(); head.Hash() != full.chain.CurrentBlock().Hash() || !empty.chain.HasState(head.Root) {
+		t.Fatalf("head state not synced: head #%d, state %v", head.Number, empty.chain.HasState(head.Root))
+	}
 }
 
 func TestArtificialFinalityFeatureEnablingDisabling(t *testing.T) {
@@ -17,7 +17,6 @@
 package tracers
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
 	"errors"
@@ -41,6 +40,7 @@ import (
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params/confp"
+	"github.com/ethereum/go-ethereum/params/types/coregeth"
 	"github.com/ethereum/go-ethereum/params/types/ctypes"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -67,6 +67,10 @@ const (
 	// for tracing. The creation of trace state will be paused if the unused
 	// trace states exceed this limit.
 	maximumPendingTraceStates = 128
+
+	// maximumTraceRangeBlocks is the maximum number of blocks traced by a single
+	// debug_traceBlockByNumberRange request.
+	maximumTraceRangeBlocks = 1024
 )
 
 var errTxNotFound = errors.New("transaction not found")
@@ -94,6 +98,7 @@ type Backend interface {
 // API is the collection of tracing APIs exposed over the private debugging endpoint.
 type API struct {
 	backend Backend
+	files   FileConfig // Output settings of the file based standard tracers
 }
 
 // NewAPI creates a new API definition for the tracing methods of the Ethereum service.
@@ -159,6 +164,17 @@ type TraceConfig struct {
 	// Config specific to given tracer. Note struct logger
 	// config are historically embedded in main object.
 	TracerConfig json.RawMessage
+
+	chainConfig ctypes.ChainConfigurator // Chain config to execute with instead of the backend's
+}
+
+// chainConfigFor returns the chain config to execute with for the given trace
+// configuration.
+func (api *API) chainConfigFor(config *TraceConfig) ctypes.ChainConfigurator {
+	if config != nil && config.chainConfig != nil {
+		return config.chainConfig
+	}
+	return api.backend.ChainConfig()
 }
 
 // TraceCallConfig is the config for traceCall API. It holds one more
@@ -174,8 +190,9 @@ type TraceCallConfig struct {
 // StdTraceConfig holds extra parameters to standard-json trace functions.
 type StdTraceConfig struct {
 	logger.Config
-	Reexec *uint64
-	TxHash common.Hash
+	Reexec      *uint64
+	TxHash      common.Hash
+	Compression string // Output compression of trace files: "gzip", "zstd" or none
 }
 
 // txTraceResult is the result of a single transaction trace.
@@ -244,21 +261,92 @@ func (api *API) TraceChain(ctx context.Context, start, end rpc.BlockNumber, conf
 	}
 	sub := notifier.CreateSubscription()
 
-	resCh := api.traceChain(from, to, config, notifier.Closed())
+	resCh, _ := api.traceChain(from, to, config, notifier.Closed())
 	go func() {
 		for result := range resCh {
-			notifier.Notify(sub.ID, result)
+			// Blocks without transactions are only streamed if they end the range
+			if len(result.Traces) > 0 || uint64(result.Block) == to.NumberU64() {
+				notifier.Notify(sub.ID, result)
+			}
 		}
 	}()
 	return sub, nil
 }
 
+// TraceBlockByNumberRange traces all the blocks between start and end, both
+// included, with the same tracer configuration, returning the results of every
+// block in order. The blocks are traced concurrently on top of incrementally
+// built states like TraceChain does, which is much faster than tracing them one
+// by one. Use TraceChain to stream the results of larger ranges.
+func (api *API) TraceBlockByNumberRange(ctx context.Context, start, end rpc.BlockNumber, config *TraceConfig) ([]*blockTraceResult, error) {
+	from, err := api.blockByNumber(ctx, start)
+	if err != nil {
+		return nil, err
+	}
+	to, err := api.blockByNumber(ctx, end)
+	if err != nil {
+		return nil, err
+	}
+	if from.NumberU64() > to.NumberU64() {
+		return nil, fmt.Errorf("end block (#%d) needs to come after start block (#%d)", to.NumberU64(), from.NumberU64())
+	}
+	if count := to.NumberU64() - from.NumberU64() + 1; count > maximumTraceRangeBlocks {
+		return nil, fmt.Errorf("block range too large (%d blocks), maximum is %d", count, maximumTraceRangeBlocks)
+	}
+	results := make([]*blockTraceResult, 0, to.NumberU64()-from.NumberU64()+1)
+	empty := func(block *types.Block) *blockTraceResult {
+		return &blockTraceResult{Block: hexutil.Uint64(block.NumberU64()), Hash: block.Hash(), Traces: []*txTraceResult{}}
+	}
+	// The chain tracer excludes the first block of its range, so start it from
+	// the parent of the range. The genesis block has no transactions to trace.
+	if from.NumberU64() == 0 {
+		results = append(results, empty(from))
+		if to.NumberU64() == 0 {
+			return results, nil
+		}
+		if from, err = api.blockByNumber(ctx, 1); err != nil {
+			return nil, err
+		}
+	}
+	parent, err := api.blockByNumber(ctx, rpc.BlockNumber(from.NumberU64()-1))
+	if err != nil {
+		return nil, err
+	}
+	closed := make(chan interface{})
+	resCh, errCh := api.traceChain(parent, to, config, closed)
+	defer func() {
+		// Abort the tracing if returning early, draining the pending results.
+		close(closed)
+		go func() {
+			for range resCh {
+			}
+		}()
+	}()
+	for next := from.NumberU64(); next <= to.NumberU64(); next++ {
+		select {
+		case res, ok := <-resCh:
+			if !ok {
+				if err := <-errCh; err != nil {
+					return nil, err
+				}
+				return nil, fmt.Errorf("tracing aborted at block #%d", next)
+			}
+			results = append(results, res)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return results, nil
+}
+
 // traceChain configures a new tracer according to the provided configuration, and
 // executes all the transactions contained within. The tracing chain range includes
 // the end block but excludes the start one. The return value will be one item per
-// transaction, dependent on the requested tracer.
+// block, holding one trace per transaction, dependent on the requested tracer.
+// Once the results are closed, the error channel yields the failure which stopped
+// the tracing, if any.
 // The tracing procedure should be aborted in case the closed signal is received.
-func (api *API) traceChain(start, end *types.Block, config *TraceConfig, closed <-chan interface{}) chan *blockTraceResult {
+func (api *API) traceChain(start, end *types.Block, config *TraceConfig, closed <-chan interface{}) (chan *blockTraceResult, <-chan error) {
 	reexec := defaultTraceReexec
 	if config != nil && config.Reexec != nil {
 		reexec = *config.Reexec
@@ -273,6 +361,7 @@ func (api *API) traceChain(start, end *types.Block, config *TraceConfig, closed
 		ctx     = context.Background()
 		taskCh  = make(chan *blockTraceTask, threads)
 		resCh   = make(chan *blockTraceTask, threads)
+		errCh   = make(chan error, 1)
 		tracker = newStateTracker(maximumPendingTraceStates, start.NumberU64())
 	)
 	for th := 0; th < threads; th++ {
@@ -347,6 +436,7 @@ func (api *API) traceChain(start, end *types.Block, config *TraceConfig, closed
 			default:
 				log.Info("Chain tracing finished", "start", start.NumberU64(), "end", end.NumberU64(), "transactions", traced, "elapsed", time.Since(begin))
 			}
+			errCh <- failed
 			close(resCh)
 		}()
 		// Feed all the blocks both into the tracer, as well as fast process concurrently
@@ -432,19 +522,17 @@ func (api *API) traceChain(start, end *types.Block, config *TraceConfig, closed
 
 			// Stream completed traces to the result channel
 			for result, ok := done[next]; ok; result, ok = done[next] {
-				if len(result.Traces) > 0 || next == end.NumberU64() {
-					// It will be blocked in case the channel consumer doesn't take the
-					// tracing result in time(e.g. the websocket connect is not stable)
-					// which will eventually block the entire chain tracer. It's the
-					// expected behavior to not waste node resources for a non-active user.
-					retCh <- result
-				}
+				// It will be blocked in case the channel consumer doesn't take the
+				// tracing result in time(e.g. the websocket connect is not stable)
+				// which will eventually block the entire chain tracer. It's the
+				// expected behavior to not waste node resources for a non-active user.
+				retCh <- result
 				delete(done, next)
 				next++
 			}
 		}
 	}()
-	return retCh
+	return retCh, errCh
 }
 
 // TraceBlockByNumber returns the structured logs created during the execution of
@@ -477,6 +565,46 @@ func (api *API) TraceBlock(ctx context.Context, blob hexutil.Bytes, config *Trac
 	return api.traceBlock(ctx, block, config)
 }
 
+// TraceBlockWithConfig re-executes the given block with a modified chain config
+// and returns the structured logs created during the execution of EVM. The
+// overrides are merged into the node's chain config, e.g. {"eip2929FBlock": 0}
+// executes the block as if EIP-2929 was active, allowing to analyse the impact
+// of proposed forks on real blocks. Only the traced block is executed with the
+// modified config, its parent state is derived as usual, and no state is
+// persisted.
+func (api *API) TraceBlockWithConfig(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, overrides *coregeth.CoreGethChainConfig, config *TraceConfig) ([]*txTraceResult, error) {
+	if overrides == nil {
+		return nil, errors.New("chain config overrides required")
+	}
+	var (
+		block *types.Block
+		err   error
+	)
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		block, err = api.blockByHash(ctx, hash)
+	} else if number, ok := blockNrOrHash.Number(); ok {
+		block, err = api.blockByNumber(ctx, number)
+	} else {
+		return nil, errors.New("invalid block number or hash")
+	}
+	if err != nil {
+		return nil, err
+	}
+	chainConfig, err := confp.CloneChainConfigurator(api.backend.ChainConfig())
+	if err != nil {
+		return nil, err
+	}
+	if err := confp.Crush(chainConfig, overrides, false); err != nil {
+		return nil, fmt.Errorf("invalid chain config overrides: %v", err)
+	}
+	var cfg TraceConfig
+	if config != nil {
+		cfg = *config
+	}
+	cfg.chainConfig = chainConfig
+	return api.traceBlock(ctx, block, &cfg)
+}
+
 // TraceBlockFromFile returns the structured logs created during the execution of
 // EVM and returns them as a JSON object.
 func (api *API) TraceBlockFromFile(ctx context.Context, file string, config *TraceConfig) ([]*txTraceResult, error) {
@@ -614,12 +742,13 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 	}
 	// Native tracers have low overhead
 	var (
-		txs       = block.Transactions()
-		blockHash = block.Hash()
+		txs         = block.Transactions()
+		blockHash   = block.Hash()
+		chainConfig = api.chainConfigFor(config)
 		// EIP161d is what ethereum/go-ethereum calls eip158.
-		isEIP161D = api.backend.ChainConfig().IsEnabled(api.backend.ChainConfig().GetEIP161dTransition, block.Number())
+		isEIP161D = chainConfig.IsEnabled(chainConfig.GetEIP161dTransition, block.Number())
 		blockCtx  = core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
-		signer    = types.MakeSigner(api.backend.ChainConfig(), block.Number(), block.Time())
+		signer    = types.MakeSigner(chainConfig, block.Number(), block.Time())
 		results   = make([]*txTraceResult, len(txs))
 	)
 	for i, tx := range txs {
@@ -649,12 +778,13 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 func (api *API) traceBlockParallel(ctx context.Context, block *types.Block, statedb *state.StateDB, config *TraceConfig) ([]*txTraceResult, error) {
 	// Execute all the transaction contained within the block concurrently
 	var (
-		txs       = block.Transactions()
-		blockHash = block.Hash()
-		blockCtx  = core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
-		signer    = types.MakeSigner(api.backend.ChainConfig(), block.Number(), block.Time())
-		results   = make([]*txTraceResult, len(txs))
-		pend      sync.WaitGroup
+		txs         = block.Transactions()
+		blockHash   = block.Hash()
+		chainConfig = api.chainConfigFor(config)
+		blockCtx    = core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
+		signer      = types.MakeSigner(chainConfig, block.Number(), block.Time())
+		results     = make([]*txTraceResult, len(txs))
+		pend        sync.WaitGroup
 	)
 	threads := runtime.NumCPU()
 	if threads > len(txs) {
@@ -700,14 +830,14 @@ txloop:
 		// Generate the next state snapshot fast without tracing
 		msg, _ := core.TransactionToMessage(tx, signer, block.BaseFee())
 		statedb.SetTxContext(tx.Hash(), i)
-		vmenv := vm.NewEVM(blockCtx, core.NewEVMTxContext(msg), statedb, api.backend.ChainConfig(), vm.Config{})
+		vmenv := vm.NewEVM(blockCtx, core.NewEVMTxContext(msg), statedb, chainConfig, vm.Config{})
 		if _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.GasLimit)); err != nil {
 			failed = err
 			break txloop
 		}
 		// Finalize the state so any modifications are written to the trie
 		// Only delete empty objects if EIP158/161 (a.k.a Spurious Dragon) is in effect
-		statedb.Finalise(vmenv.ChainConfig().IsEnabled(api.backend.ChainConfig().GetEIP161dTransition, block.Number()))
+		statedb.Finalise(chainConfig.IsEnabled(chainConfig.GetEIP161dTransition, block.Number()))
 	}
 
 	close(jobs)
@@ -751,10 +881,12 @@ func (api *API) standardTraceBlockToFile(ctx context.Context, block *types.Block
 	var (
 		logConfig logger.Config
 		txHash    common.Hash
+		compress  string
 	)
 	if config != nil {
 		logConfig = config.Config
 		txHash = config.TxHash
+		compress = config.Compression
 	}
 	logConfig.Debug = true
 
@@ -781,8 +913,7 @@ func (api *API) standardTraceBlockToFile(ctx context.Context, block *types.Block
 			msg, _    = core.TransactionToMessage(tx, signer, block.BaseFee())
 			txContext = core.NewEVMTxContext(msg)
 			vmConf    vm.Config
-			dump      *os.File
-			writer    *bufio.Writer
+			dump      *traceFile
 			err       error
 		)
 		// If the transaction needs tracing, swap out the configs
@@ -792,16 +923,15 @@ func (api *API) standardTraceBlockToFile(ctx context.Context, block *types.Block
 			if !canon {
 				prefix = fmt.Sprintf("%valt-", prefix)
 			}
-			dump, err = os.CreateTemp(os.TempDir(), prefix)
+			dump, err = api.files.createTraceFile(prefix, compress)
 			if err != nil {
 				return nil, err
 			}
 			dumps = append(dumps, dump.Name())
 
 			// Swap out the noop logger to the standard tracer
-			writer = bufio.NewWriter(dump)
 			vmConf = vm.Config{
-				Tracer:                  logger.NewJSONLogger(&logConfig, writer),
+				Tracer:                  logger.NewJSONLogger(&logConfig, dump),
 				EnablePreimageRecording: true,
 			}
 		}
@@ -809,14 +939,14 @@ func (api *API) standardTraceBlockToFile(ctx context.Context, block *types.Block
 		vmenv := vm.NewEVM(vmctx, txContext, statedb, chainConfig, vmConf)
 		statedb.SetTxContext(tx.Hash(), i)
 		_, err = core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.GasLimit))
-		if writer != nil {
-			writer.Flush()
-		}
 		if dump != nil {
-			dump.Close()
+			if cerr := dump.Close(); cerr != nil && err == nil {
+				err = cerr
+			}
 			log.Info("Wrote standard trace", "file", dump.Name())
 		}
 		if err != nil {
+			api.files.prune(dumps)
 			return dumps, err
 		}
 		// Finalize the state so any modifications are written to the trie
@@ -828,6 +958,7 @@ func (api *API) standardTraceBlockToFile(ctx context.Context, block *types.Block
 			break
 		}
 	}
+	api.files.prune(dumps)
 	return dumps, nil
 }
 
@@ -1033,7 +1164,7 @@ func (api *API) traceTx(ctx context.Context, message *core.Message, txctx *Conte
 			return nil, err
 		}
 	}
-	vmenv := vm.NewEVM(vmctx, txContext, statedb, api.backend.ChainConfig(), vm.Config{Tracer: tracer, NoBaseFee: true})
+	vmenv := vm.NewEVM(vmctx, txContext, statedb, api.chainConfigFor(config), vm.Config{Tracer: tracer, NoBaseFee: true})
 
 	// Define a meaningful timeout of a single transaction trace
 	if config.Timeout != nil {
@@ -1065,9 +1196,13 @@ func (api *API) traceTx(ctx context.Context, message *core.Message, txctx *Conte
 	return tracer.GetResult()
 }
 
-// APIs return the collection of RPC services the tracer package offers.
-func APIs(backend Backend) []rpc.API {
+// APIs return the collection of RPC services the tracer package offers. The
+// optional file config customizes where file based traces are written to.
+func APIs(backend Backend, files *FileConfig) []rpc.API {
 	debugAPI := NewAPI(backend)
+	if files != nil {
+		debugAPI.files = *files
+	}
 
 	// Append all the local APIs and return
 	return []rpc.API{
@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -39,6 +40,32 @@ type TraceFilterArgs struct {
 	Count       uint64          `json:"count,omitempty"`       // Integer number of traces to display in a batch
 }
 
+const (
+	// traceFilterPageSize is the default number of traces in a page of
+	// trace_filterPaged.
+	traceFilterPageSize = 100
+
+	// traceFilterMaxPageSize is the maximum number of traces in a page of
+	// trace_filterPaged.
+	traceFilterMaxPageSize = 10000
+
+	// traceFilterPageBlocks is the maximum number of blocks traced for a page of
+	// trace_filterPaged, bounding the work done when the matches are sparse.
+	traceFilterPageBlocks = 1000
+)
+
+// TraceCursor is the position a page of trace_filterPaged resumes from.
+type TraceCursor struct {
+	Block hexutil.Uint64 `json:"block"` // Block to resume tracing from
+	Index hexutil.Uint64 `json:"index"` // Number of matching traces of the block already returned
+}
+
+// TraceFilterPage is a page of the traces matching a filter.
+type TraceFilterPage struct {
+	Traces []interface{} `json:"traces"`
+	Next   *TraceCursor  `json:"next"` // Cursor of the next page, nil once the range is exhausted
+}
+
 // ParityTrace A trace in the desired format (Parity/OpenEtherum) See: https://Parity.github.io/wiki/JSONRPC-trace-module
 type ParityTrace struct {
 	Action              TraceRewardAction `json:"action"`
@@ -256,6 +283,133 @@ func (api *TraceAPI) Filter(ctx context.Context, args TraceFilterArgs, config *T
 	return api.debugAPI.TraceChain(ctx, start, end, config)
 }
 
+// FilterPaged returns the traces matching the filter one page at a time, instead
+// of a single response for the whole block range. Tracing starts at the given
+// cursor, or at the start of the range without one, and the result includes the
+// cursor of the next page. A page ends after args.Count traces or after a bounded
+// number of blocks, so a page may be empty even though more traces follow.
+func (api *TraceAPI) FilterPaged(ctx context.Context, args TraceFilterArgs, cursor *TraceCursor, config *TraceConfig) (*TraceFilterPage, error) {
+	config = setTraceConfigDefaultTracer(config)
+	if *config.Tracer != "callTracerParity" {
+		return nil, errors.New("only the callTracerParity tracer supports filtering")
+	}
+	// Resolve the block range, the end defaulting to the head
+	end := uint64(args.ToBlock)
+	if end == 0 {
+		head, err := api.debugAPI.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+		if err != nil {
+			return nil, err
+		}
+		end = head.Number.Uint64()
+	}
+	if uint64(args.FromBlock) > end {
+		return nil, fmt.Errorf("invalid block range %d-%d", args.FromBlock, end)
+	}
+	start := TraceCursor{Block: args.FromBlock}
+	if cursor != nil {
+		if cursor.Block < args.FromBlock || uint64(cursor.Block) > end {
+			return nil, fmt.Errorf("cursor block %d out of range %d-%d", cursor.Block, args.FromBlock, end)
+		}
+		start = *cursor
+	}
+	count := args.Count
+	if count == 0 {
+		count = traceFilterPageSize
+	}
+	if count > traceFilterMaxPageSize {
+		count = traceFilterMaxPageSize
+	}
+	traceBlock := func(number uint64) ([]interface{}, error) {
+		return api.Block(ctx, rpc.BlockNumber(number), config)
+	}
+	match := func(trace interface{}) bool {
+		return traceMatches(trace, args.FromAddress, args.ToAddress)
+	}
+	return filterTracePage(ctx, start, end, count, traceFilterPageBlocks, traceBlock, match)
+}
+
+// filterTracePage collects the matching traces of the blocks from the cursor up
+// to end, until the page is full or maxBlocks blocks have been traced.
+func filterTracePage(ctx context.Context, cursor TraceCursor, end, count, maxBlocks uint64, traceBlock func(uint64) ([]interface{}, error), match func(interface{}) bool) (*TraceFilterPage, error) {
+	var (
+		page  = &TraceFilterPage{Traces: []interface{}{}}
+		first = uint64(cursor.Block)
+		skip  = uint64(cursor.Index)
+	)
+	for number := first; number <= end; number++ {
+		if number-first == maxBlocks {
+			page.Next = &TraceCursor{Block: hexutil.Uint64(number)}
+			return page, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		traces, err := traceBlock(number)
+		if err != nil {
+			return nil, err
+		}
+		var matched uint64
+		for _, trace := range traces {
+			if !match(trace) {
+				continue
+			}
+			// Skip the traces of the block returned by the previous page
+			if matched++; matched <= skip {
+				continue
+			}
+			page.Traces = append(page.Traces, trace)
+			if uint64(len(page.Traces)) == count {
+				page.Next = &TraceCursor{Block: hexutil.Uint64(number), Index: hexutil.Uint64(matched)}
+				return page, nil
+			}
+		}
+		skip = 0
+	}
+	return page, nil
+}
+
+// traceMatches reports whether a Parity formatted trace is sent from and to the
+// given addresses, if set. The recipient of a contract creation is the created
+// contract, the one of a self-destruct the refund address and the one of a
+// reward the beneficiary.
+func traceMatches(trace interface{}, from, to *common.Address) bool {
+	if from == nil && to == nil {
+		return true
+	}
+	var sender, recipient *common.Address
+	switch trace := trace.(type) {
+	case *ParityTrace:
+		recipient = trace.Action.Author
+	case map[string]interface{}:
+		action, _ := trace["action"].(map[string]interface{})
+		result, _ := trace["result"].(map[string]interface{})
+		sender = traceAddress(action, "from")
+		if recipient = traceAddress(action, "to"); recipient == nil {
+			recipient = traceAddress(result, "address")
+		}
+		if recipient == nil {
+			recipient = traceAddress(action, "refundAddress")
+		}
+	}
+	if from != nil && (sender == nil || *sender != *from) {
+		return false
+	}
+	if to != nil && (recipient == nil || *recipient != *to) {
+		return false
+	}
+	return true
+}
+
+// traceAddress returns the address in the given field of a decoded trace object.
+func traceAddress(object map[string]interface{}, field string) *common.Address {
+	hex, ok := object[field].(string)
+	if !ok || !common.IsHexAddress(hex) {
+		return nil
+	}
+	addr := common.HexToAddress(hex)
+	return &addr
+}
+
 // Call lets you trace a given eth_call. It collects the structured logs created during the execution of EVM
 // if the given transaction was added on top of the provided block and returns them as a JSON object.
 // You can provide -2 as a block number to trace on top of the pending block.
@@ -1,9 +1,106 @@
 package tracers
 
 import (
+	"context"
+	"reflect"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
+// Tests that the pages of a trace filter cover all matching traces exactly once.
+func TestFilterTracePage(t *testing.T) {
+	// Block n holds n traces, the even ones matching
+	traceBlock := func(number uint64) ([]interface{}, error) {
+		traces := make([]interface{}, number)
+		for i := range traces {
+			traces[i] = [2]uint64{number, uint64(i)}
+		}
+		return traces, nil
+	}
+	match := func(trace interface{}) bool {
+		return trace.([2]uint64)[1]%2 == 0
+	}
+	var want []interface{}
+	for number := uint64(3); number <= 20; number++ {
+		traces, _ := traceBlock(number)
+		for _, trace := range traces {
+			if match(trace) {
+				want = append(want, trace)
+			}
+		}
+	}
+	for _, count := range []uint64{1, 3, 7, 100} {
+		for _, maxBlocks := range []uint64{1, 4, 100} {
+			var (
+				have   []interface{}
+				cursor = TraceCursor{Block: 3}
+				pages  int
+			)
+			for {
+				page, err := filterTracePage(context.Background(), cursor, 20, count, maxBlocks, traceBlock, match)
+				if err != nil {
+					t.Fatalf("count %d, max blocks %d: failed to filter: %v", count, maxBlocks, err)
+				}
+				if uint64(len(page.Traces)) > count {
+					t.Fatalf("count %d, max blocks %d: page too large: %d", count, maxBlocks, len(page.Traces))
+				}
+				have = append(have, page.Traces...)
+				if pages++; pages > len(want)+20 {
+					t.Fatalf("count %d, max blocks %d: pagination not terminating", count, maxBlocks)
+				}
+				if page.Next == nil {
+					break
+				}
+				cursor = *page.Next
+			}
+			if !reflect.DeepEqual(have, want) {
+				t.Errorf("count %d, max blocks %d: traces mismatch: have %v, want %v", count, maxBlocks, have, want)
+			}
+		}
+	}
+}
+
+// Tests the address matching of Parity formatted traces.
+func TestTraceMatches(t *testing.T) {
+	var (
+		a = common.HexToAddress("0xa")
+		b = common.HexToAddress("0xb")
+		c = common.HexToAddress("0xc")
+	)
+	call := map[string]interface{}{
+		"action": map[string]interface{}{"from": a.Hex(), "to": b.Hex(), "callType": "call"},
+	}
+	create := map[string]interface{}{
+		"action": map[string]interface{}{"from": a.Hex(), "init": "0x"},
+		"result": map[string]interface{}{"address": c.Hex()},
+	}
+	reward := &ParityTrace{Action: TraceRewardAction{Author: &c, Value: (*hexutil.Big)(common.Big1)}}
+
+	tests := []struct {
+		trace    interface{}
+		from, to *common.Address
+		want     bool
+	}{
+		{call, nil, nil, true},
+		{call, &a, nil, true},
+		{call, &b, nil, false},
+		{call, nil, &b, true},
+		{call, &a, &b, true},
+		{call, &a, &c, false},
+		{create, nil, &c, true},
+		{create, &a, &b, false},
+		{reward, nil, &c, true},
+		{reward, &c, nil, false},
+	}
+	for i, tt := range tests {
+		if have := traceMatches(tt.trace, tt.from, tt.to); have != tt.want {
+			t.Errorf("test %d: match mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
+
 // BenchmarkTraceResultsAppend1 compares performance against BenchmarkTraceResultsAppend2,
 // comparing the performance of different ways of appending items to slices.
 // This is used in PrivateTraceAPI#Block appending results to the traceResults value.
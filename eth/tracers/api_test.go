@@ -42,6 +42,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/coregeth"
 	"github.com/ethereum/go-ethereum/params/types/ctypes"
 	"github.com/ethereum/go-ethereum/params/types/genesisT"
 	"github.com/ethereum/go-ethereum/params/vars"
@@ -551,6 +552,81 @@ func TestTraceBlock(t *testing.T) {
 	}
 }
 
+func TestTraceBlockWithConfig(t *testing.T) {
+	t.Parallel()
+
+	// Initialize a pre-Istanbul chain with a contract executing CHAINID (EIP-1344)
+	accounts := newAccounts(1)
+	contract := common.HexToAddress("0x1344")
+	config := *params.TestChainConfig
+	config.IstanbulBlock, config.MuirGlacierBlock, config.BerlinBlock = nil, nil, nil
+	config.LondonBlock, config.ArrowGlacierBlock, config.GrayGlacierBlock = nil, nil, nil
+	genesis := &genesisT.Genesis{
+		Config: &config,
+		Alloc: genesisT.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(vars.Ether)},
+			contract:         {Code: []byte{byte(vm.CHAINID), byte(vm.STOP)}},
+		},
+	}
+	signer := types.HomesteadSigner{}
+	backend := newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTx(&types.LegacyTx{
+			Nonce:    uint64(i),
+			To:       &contract,
+			Gas:      50000,
+			GasPrice: big.NewInt(1),
+		}), signer, accounts[0].key)
+		b.AddTx(tx)
+	})
+	defer backend.chain.Stop()
+	api := NewAPI(backend)
+
+	failed := func(results []*txTraceResult) bool {
+		blob, _ := json.Marshal(results)
+		var decoded []struct {
+			Result struct {
+				Failed bool `json:"failed"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal(blob, &decoded); err != nil || len(decoded) != 1 {
+			t.Fatalf("unexpected trace results: %s", blob)
+		}
+		return decoded[0].Result.Failed
+	}
+	block := rpc.BlockNumberOrHashWithNumber(1)
+
+	if _, err := api.TraceBlockWithConfig(context.Background(), block, nil, nil); err == nil {
+		t.Fatal("expected error without overrides")
+	}
+	// Without any effective overrides, CHAINID is an invalid opcode
+	results, err := api.TraceBlockWithConfig(context.Background(), block, &coregeth.CoreGethChainConfig{}, nil)
+	if err != nil {
+		t.Fatalf("failed to trace block: %v", err)
+	}
+	if !failed(results) {
+		t.Error("expected execution to fail without EIP-1344")
+	}
+	// Activating EIP-1344 makes the same transaction succeed
+	results, err = api.TraceBlockWithConfig(context.Background(), block, &coregeth.CoreGethChainConfig{EIP1344FBlock: big.NewInt(0)}, nil)
+	if err != nil {
+		t.Fatalf("failed to trace block: %v", err)
+	}
+	if failed(results) {
+		t.Error("expected execution to succeed with EIP-1344")
+	}
+	// The node's own chain config must be left untouched
+	if config.IstanbulBlock != nil {
+		t.Errorf("chain config modified: istanbul block %v", config.IstanbulBlock)
+	}
+	results, err = api.TraceBlockByNumber(context.Background(), 1, nil)
+	if err != nil {
+		t.Fatalf("failed to trace block: %v", err)
+	}
+	if !failed(results) {
+		t.Error("expected regular trace to fail without EIP-1344")
+	}
+}
+
 func TestTracingWithOverrides(t *testing.T) {
 	t.Parallel()
 	// Initialize test accounts
@@ -970,7 +1046,7 @@ func TestTraceChain(t *testing.T) {
 
 		from, _ := api.blockByNumber(context.Background(), rpc.BlockNumber(c.start))
 		to, _ := api.blockByNumber(context.Background(), rpc.BlockNumber(c.end))
-		resCh := api.traceChain(from, to, c.config, nil)
+		resCh, _ := api.traceChain(from, to, c.config, nil)
 
 		next := c.start + 1
 		for result := range resCh {
@@ -998,3 +1074,88 @@ func TestTraceChain(t *testing.T) {
 		}
 	}
 }
+
+func TestTraceBlockByNumberRange(t *testing.T) {
+	t.Parallel()
+
+	// Initialize test accounts
+	accounts := newAccounts(2)
+	genesis := &genesisT.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: genesisT.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(vars.Ether)},
+			accounts[1].addr: {Balance: big.NewInt(vars.Ether)},
+		},
+	}
+	genBlocks := 20
+	signer := types.HomesteadSigner{}
+
+	var nonce uint64
+	backend := newTestBackend(t, genBlocks, genesis, func(i int, b *core.BlockGen) {
+		// Leave every third block empty
+		if i%3 == 2 {
+			return
+		}
+		tx, _ := types.SignTx(types.NewTransaction(nonce, accounts[1].addr, big.NewInt(1000), vars.TxGas, b.BaseFee(), nil), signer, accounts[0].key)
+		b.AddTx(tx)
+		nonce += 1
+	})
+	defer backend.teardown()
+	api := NewAPI(backend)
+
+	var cases = []struct {
+		start, end rpc.BlockNumber
+		expectErr  bool
+	}{
+		{0, 20, false},  // the entire chain, genesis included
+		{3, 3, false},   // a single empty block
+		{5, 12, false},  // the middle of the chain
+		{12, 5, true},   // inverted range
+		{0, 21, true},   // range past the head
+		{19, 20, false}, // the head of the chain
+	}
+	for i, c := range cases {
+		results, err := api.TraceBlockByNumberRange(context.Background(), c.start, c.end, nil)
+		if c.expectErr {
+			if err == nil {
+				t.Errorf("test %d: want error, have none", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: want no error, have %v", i, err)
+			continue
+		}
+		if have, want := len(results), int(c.end-c.start+1); have != want {
+			t.Fatalf("test %d: wrong number of results, have %d want %d", i, have, want)
+		}
+		for j, result := range results {
+			number := uint64(c.start) + uint64(j)
+			block := backend.chain.GetBlockByNumber(number)
+			if uint64(result.Block) != number || result.Hash != block.Hash() {
+				t.Fatalf("test %d: wrong block traced, have #%d (%x) want #%d (%x)", i, result.Block, result.Hash, number, block.Hash())
+			}
+			if have, want := len(result.Traces), len(block.Transactions()); have != want {
+				t.Fatalf("test %d: wrong number of traces in block #%d, have %d want %d", i, number, have, want)
+			}
+		}
+	}
+	// Failures of the chain tracer are reported as is
+	api = NewAPI(&stateFailBackend{testBackend: backend, number: 8})
+	if _, err := api.TraceBlockByNumberRange(context.Background(), 5, 12, nil); !errors.Is(err, errStateNotFound) {
+		t.Fatalf("wrong error tracing with missing state: have %v, want %v", err, errStateNotFound)
+	}
+}
+
+// stateFailBackend is a testBackend missing the state of a single block.
+type stateFailBackend struct {
+	*testBackend
+	number uint64
+}
+
+func (b *stateFailBackend) StateAtBlock(ctx context.Context, block *types.Block, reexec uint64, base *state.StateDB, readOnly bool, preferDisk bool) (*state.StateDB, StateReleaseFunc, error) {
+	if block.NumberU64() == b.number {
+		return nil, nil, errStateNotFound
+	}
+	return b.testBackend.StateAtBlock(ctx, block, reexec, base, readOnly, preferDisk)
+}
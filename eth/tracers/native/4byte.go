@@ -45,19 +45,45 @@ func init() {
 //	  0xadf59f99-288: 1,
 //	  0xc281d19e-0: 1
 //	}
+//
+// If withSignatures is set in the config, the counters are replaced by objects
+// also carrying the human-readable method signature, if known:
+//
+//	{
+//	  0xa9059cbb-64: {count: 1, signature: "transfer(address,uint256)"},
+//	  0xc281d19e-0: {count: 1}
+//	}
 type fourByteTracer struct {
 	noopTracer
+	config            fourByteTracerConfig
 	ids               map[string]int   // ids aggregates the 4byte ids found
 	interrupt         atomic.Bool      // Atomic flag to signal execution interruption
 	reason            error            // Textual reason for the interruption
 	activePrecompiles []common.Address // Updated on CaptureStart based on given rules
 }
 
+type fourByteTracerConfig struct {
+	WithSignatures bool `json:"withSignatures"` // If true, the method signatures are resolved from the 4byte database
+}
+
+// fourByteEntry is a single annotated result item of the 4byte tracer.
+type fourByteEntry struct {
+	Count     int    `json:"count"`
+	Signature string `json:"signature,omitempty"`
+}
+
 // newFourByteTracer returns a native go tracer which collects
 // 4 byte-identifiers of a tx, and implements vm.EVMLogger.
-func newFourByteTracer(ctx *tracers.Context, _ json.RawMessage) (tracers.Tracer, error) {
+func newFourByteTracer(ctx *tracers.Context, cfg json.RawMessage) (tracers.Tracer, error) {
+	var config fourByteTracerConfig
+	if cfg != nil {
+		if err := json.Unmarshal(cfg, &config); err != nil {
+			return nil, err
+		}
+	}
 	t := &fourByteTracer{
-		ids: make(map[string]int),
+		config: config,
+		ids:    make(map[string]int),
 	}
 	return t, nil
 }
@@ -113,6 +139,9 @@ func (t *fourByteTracer) CaptureEnter(op vm.OpCode, from common.Address, to comm
 // GetResult returns the json-encoded nested list of call traces, and any
 // error arising from the encoding or forceful termination (via `Stop`).
 func (t *fourByteTracer) GetResult() (json.RawMessage, error) {
+	if t.config.WithSignatures {
+		return t.annotatedResult()
+	}
 	res, err := json.Marshal(t.ids)
 	if err != nil {
 		return nil, err
@@ -120,6 +149,29 @@ func (t *fourByteTracer) GetResult() (json.RawMessage, error) {
 	return res, t.reason
 }
 
+// annotatedResult returns the collected 4byte ids along with the method
+// signatures resolved from the signature database.
+func (t *fourByteTracer) annotatedResult() (json.RawMessage, error) {
+	db, err := tracers.SignatureDatabase()
+	if err != nil {
+		return nil, err
+	}
+	entries := make(map[string]fourByteEntry, len(t.ids))
+	for key, count := range t.ids {
+		entry := fourByteEntry{Count: count}
+		// Keys are formatted as 0x<4 byte id>-<data size>
+		if selectors := db.Selectors(common.FromHex(key[:10])); len(selectors) > 0 {
+			entry.Signature = selectors[0]
+		}
+		entries[key] = entry
+	}
+	res, err := json.Marshal(entries)
+	if err != nil {
+		return nil, err
+	}
+	return res, t.reason
+}
+
 // Stop terminates execution of the tracer at the first opportune moment.
 func (t *fourByteTracer) Stop(err error) {
 	t.reason = err
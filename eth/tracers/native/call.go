@@ -106,6 +106,7 @@ type callTracer struct {
 	callstack []callFrame
 	config    callTracerConfig
 	gasLimit  uint64
+	skipped   int         // Number of nested scopes entered beyond the depth limit
 	interrupt atomic.Bool // Atomic flag to signal execution interruption
 	reason    error       // Textual reason for the interruption
 }
@@ -113,6 +114,8 @@ type callTracer struct {
 type callTracerConfig struct {
 	OnlyTopCall bool `json:"onlyTopCall"` // If true, call tracer won't collect any subcalls
 	WithLog     bool `json:"withLog"`     // If true, call tracer will collect event logs
+	MaxDepth    int  `json:"maxDepth"`    // Maximum nesting of collected call frames, including the top call (0 = unlimited)
+	MaxDataSize int  `json:"maxDataSize"` // Maximum number of input and output bytes kept per call frame (0 = unlimited)
 }
 
 // newCallTracer returns a native go tracer which tracks
@@ -124,6 +127,12 @@ func newCallTracer(ctx *tracers.Context, cfg json.RawMessage) (tracers.Tracer, e
 			return nil, err
 		}
 	}
+	if config.MaxDepth < 0 {
+		return nil, errors.New("maxDepth must not be negative")
+	}
+	if config.MaxDataSize < 0 {
+		return nil, errors.New("maxDataSize must not be negative")
+	}
 	// First callframe contains tx context info
 	// and is populated on start and end.
 	return &callTracer{callstack: make([]callFrame, 1), config: config}, nil
@@ -136,7 +145,7 @@ func (t *callTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Ad
 		Type:  vm.CALL,
 		From:  from,
 		To:    &toCopy,
-		Input: common.CopyBytes(input),
+		Input: t.clip(input),
 		Gas:   t.gasLimit,
 		Value: value,
 	}
@@ -148,6 +157,7 @@ func (t *callTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Ad
 // CaptureEnd is called after the call finishes to finalize the tracing.
 func (t *callTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {
 	t.callstack[0].processOutput(output, err)
+	t.callstack[0].Output = t.clip(t.callstack[0].Output)
 }
 
 // CaptureState implements the EVMLogger interface to trace a single step of VM execution.
@@ -164,6 +174,10 @@ func (t *callTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, sco
 	if t.config.OnlyTopCall && depth > 1 {
 		return
 	}
+	// Avoid processing calls nested beyond the depth limit
+	if t.skipped > 0 {
+		return
+	}
 	// Skip if tracing was interrupted
 	if t.interrupt.Load() {
 		return
@@ -206,6 +220,12 @@ func (t *callTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.
 	if t.config.OnlyTopCall {
 		return
 	}
+	// Skip calls nested beyond the depth limit, tracking them to keep the
+	// enter and exit events balanced
+	if t.skipped > 0 || (t.config.MaxDepth > 0 && len(t.callstack) >= t.config.MaxDepth) {
+		t.skipped++
+		return
+	}
 	// Skip if tracing was interrupted
 	if t.interrupt.Load() {
 		return
@@ -216,7 +236,7 @@ func (t *callTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.
 		Type:  typ,
 		From:  from,
 		To:    &toCopy,
-		Input: common.CopyBytes(input),
+		Input: t.clip(input),
 		Gas:   gas,
 		Value: value,
 	}
@@ -229,6 +249,10 @@ func (t *callTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
 	if t.config.OnlyTopCall {
 		return
 	}
+	if t.skipped > 0 {
+		t.skipped--
+		return
+	}
 	size := len(t.callstack)
 	if size <= 1 {
 		return
@@ -240,6 +264,7 @@ func (t *callTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
 
 	call.GasUsed = gasUsed
 	call.processOutput(output, err)
+	call.Output = t.clip(call.Output)
 	t.callstack[size-1].Calls = append(t.callstack[size-1].Calls, call)
 }
 
@@ -275,6 +300,14 @@ func (t *callTracer) Stop(err error) {
 	t.interrupt.Store(true)
 }
 
+// clip returns a copy of the call data, truncated to the configured maximum size.
+func (t *callTracer) clip(data []byte) []byte {
+	if t.config.MaxDataSize > 0 && len(data) > t.config.MaxDataSize {
+		data = data[:t.config.MaxDataSize]
+	}
+	return common.CopyBytes(data)
+}
+
 // clearFailedLogs clears the logs of a callframe and all its children
 // in case of execution failure.
 func clearFailedLogs(cf *callFrame, parentFailed bool) {
@@ -0,0 +1,65 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/signer/fourbyte"
+)
+
+var (
+	signaturesPath string             // Custom 4byte database file, merged over the embedded one
+	signaturesOnce sync.Once          // Ensures the database is only loaded once, on first use
+	signatures     *fourbyte.Database // Lazily loaded 4byte database
+	signaturesErr  error              // Error encountered while loading the database
+)
+
+// SetSignatureDatabase configures a custom 4byte signature file to be used next
+// to the embedded database. The file is created when new signatures are added.
+// It must be called before the database is first used.
+func SetSignatureDatabase(path string) {
+	signaturesPath = path
+}
+
+// SignatureDatabase returns the 4byte signature database used to annotate and
+// decode call data, loading it on first use.
+func SignatureDatabase() (*fourbyte.Database, error) {
+	signaturesOnce.Do(func() {
+		signatures, signaturesErr = fourbyte.NewWithFile(signaturesPath)
+		if signaturesErr != nil {
+			log.Error("Failed to load 4byte signature database", "path", signaturesPath, "err", signaturesErr)
+			return
+		}
+		embedded, custom := signatures.Size()
+		log.Debug("Loaded 4byte signature database", "embedded", embedded, "custom", custom)
+	})
+	return signatures, signaturesErr
+}
+
+// DecodeCalldata looks up the method selector of the given call data in the 4byte
+// signature database and returns all candidate signatures that the arguments
+// decode against.
+func (api *API) DecodeCalldata(data hexutil.Bytes) ([]fourbyte.DecodedCall, error) {
+	db, err := SignatureDatabase()
+	if err != nil {
+		return nil, err
+	}
+	return db.Decode(data)
+}
@@ -0,0 +1,215 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/klauspost/compress/zstd"
+)
+
+// traceFilePrefix is the common prefix of all the files written by the file
+// based standard tracers, used to tell them apart from unrelated files.
+const traceFilePrefix = "block_"
+
+// errNoTraceDir is returned when listing the trace files without a dedicated
+// output directory, as the system temp dir may hold anyone's block_* files.
+var errNoTraceDir = errors.New("no dedicated trace file directory configured")
+
+// FileConfig holds the settings of the file based standard tracers.
+type FileConfig struct {
+	Dir     string // Directory to write the trace files into (empty = system temp dir)
+	MaxSize uint64 // Total size of trace files to retain, oldest deleted first (0 = unlimited, requires Dir)
+}
+
+// Validate checks that the retention limit is only used together with a
+// dedicated output directory, so it never deletes files it did not write.
+func (c *FileConfig) Validate() error {
+	if c.MaxSize > 0 && c.Dir == "" {
+		return errors.New("trace file limit requires a dedicated trace file directory")
+	}
+	return nil
+}
+
+// dir returns the directory trace files are written into.
+func (c *FileConfig) dir() string {
+	if c.Dir == "" {
+		return os.TempDir()
+	}
+	return c.Dir
+}
+
+// TraceFile describes a trace output file on disk.
+type TraceFile struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// traceFile is a possibly compressed, buffered trace output file.
+type traceFile struct {
+	file *os.File
+	comp io.WriteCloser // Compressor wrapping the file, nil if uncompressed
+	buf  *bufio.Writer
+}
+
+// createTraceFile creates a new uniquely named trace file with the given name
+// prefix, compressing the output with the requested algorithm.
+func (c *FileConfig) createTraceFile(prefix string, compression string) (*traceFile, error) {
+	var suffix string
+	switch compression {
+	case "", "none":
+	case "gzip":
+		suffix = ".gz"
+	case "zstd":
+		suffix = ".zst"
+	default:
+		return nil, fmt.Errorf("unsupported trace compression %q", compression)
+	}
+	if err := os.MkdirAll(c.dir(), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.CreateTemp(c.dir(), prefix+"*"+suffix)
+	if err != nil {
+		return nil, err
+	}
+	f := &traceFile{file: file}
+
+	var w io.Writer = file
+	switch compression {
+	case "gzip":
+		f.comp = gzip.NewWriter(file)
+		w = f.comp
+	case "zstd":
+		enc, err := zstd.NewWriter(file)
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+			return nil, err
+		}
+		f.comp, w = enc, enc
+	}
+	f.buf = bufio.NewWriter(w)
+	return f, nil
+}
+
+// Name returns the path of the trace file.
+func (f *traceFile) Name() string {
+	return f.file.Name()
+}
+
+// Write implements io.Writer.
+func (f *traceFile) Write(p []byte) (int, error) {
+	return f.buf.Write(p)
+}
+
+// Close flushes all buffered output and closes the file.
+func (f *traceFile) Close() error {
+	err := f.buf.Flush()
+	if f.comp != nil {
+		if cerr := f.comp.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if cerr := f.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// list returns all the trace files in the output directory, oldest first.
+func (c *FileConfig) list() ([]TraceFile, error) {
+	if c.Dir == "" {
+		return nil, errNoTraceDir
+	}
+	entries, err := os.ReadDir(c.dir())
+	if err != nil {
+		return nil, err
+	}
+	var files []TraceFile
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), traceFilePrefix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue // deleted in the meantime
+		}
+		files = append(files, TraceFile{
+			Name:    filepath.Join(c.dir(), entry.Name()),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime.Before(files[j].ModTime)
+	})
+	return files, nil
+}
+
+// prune deletes the oldest trace files until the total size of the output
+// directory is within the configured limit. The files in keep are never
+// deleted, even if they alone exceed the limit.
+func (c *FileConfig) prune(keep []string) {
+	if c.MaxSize == 0 || c.Dir == "" {
+		return
+	}
+	files, err := c.list()
+	if err != nil {
+		log.Warn("Failed to list trace files", "dir", c.dir(), "err", err)
+		return
+	}
+	protected := make(map[string]struct{}, len(keep))
+	for _, name := range keep {
+		protected[name] = struct{}{}
+	}
+	var total uint64
+	for _, file := range files {
+		total += uint64(file.Size)
+	}
+	for _, file := range files {
+		if total <= c.MaxSize {
+			break
+		}
+		if _, ok := protected[file.Name]; ok {
+			continue
+		}
+		if err := os.Remove(file.Name); err != nil {
+			log.Warn("Failed to delete trace file", "file", file.Name, "err", err)
+			continue
+		}
+		total -= uint64(file.Size)
+		log.Debug("Deleted old trace file", "file", file.Name, "size", file.Size)
+	}
+}
+
+// ListTraceFiles returns the trace files currently retained in the output
+// directory of the file based standard tracers, oldest first. It is only
+// available with a dedicated output directory.
+func (api *API) ListTraceFiles() ([]TraceFile, error) {
+	return api.files.list()
+}
@@ -0,0 +1,149 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Tests that trace files are written with the requested compression and can be
+// decoded again.
+func TestTraceFileCompression(t *testing.T) {
+	payload := bytes.Repeat([]byte(`{"pc":0,"op":96,"gas":"0x5f5e100"}`+"\n"), 100)
+
+	tests := []struct {
+		compression string
+		suffix      string
+		decode      func(io.Reader) (io.Reader, error)
+	}{
+		{"", "", func(r io.Reader) (io.Reader, error) { return r, nil }},
+		{"gzip", ".gz", func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }},
+		{"zstd", ".zst", func(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) }},
+	}
+	for _, tt := range tests {
+		files := &FileConfig{Dir: t.TempDir()}
+		dump, err := files.createTraceFile("block_0x01-0-0x02-", tt.compression)
+		if err != nil {
+			t.Fatalf("%q: failed to create trace file: %v", tt.compression, err)
+		}
+		if _, err := dump.Write(payload); err != nil {
+			t.Fatalf("%q: failed to write trace: %v", tt.compression, err)
+		}
+		if err := dump.Close(); err != nil {
+			t.Fatalf("%q: failed to close trace file: %v", tt.compression, err)
+		}
+		if !strings.HasSuffix(dump.Name(), tt.suffix) {
+			t.Errorf("%q: file suffix mismatch: have %s, want %s", tt.compression, dump.Name(), tt.suffix)
+		}
+		f, err := os.Open(dump.Name())
+		if err != nil {
+			t.Fatalf("%q: failed to open trace file: %v", tt.compression, err)
+		}
+		r, err := tt.decode(f)
+		if err != nil {
+			t.Fatalf("%q: failed to decode trace file: %v", tt.compression, err)
+		}
+		have, err := io.ReadAll(r)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%q: failed to read trace file: %v", tt.compression, err)
+		}
+		if !bytes.Equal(have, payload) {
+			t.Errorf("%q: trace content mismatch", tt.compression)
+		}
+	}
+	if _, err := (&FileConfig{Dir: t.TempDir()}).createTraceFile("block_", "lz4"); err == nil {
+		t.Errorf("unsupported compression accepted")
+	}
+}
+
+// Tests that the retention policy deletes the oldest trace files first, leaves
+// unrelated files alone and never deletes the files just written.
+func TestTraceFileRetention(t *testing.T) {
+	var (
+		dir   = t.TempDir()
+		files = &FileConfig{Dir: dir, MaxSize: 250}
+		now   = time.Now()
+	)
+	write := func(name string, size int, age time.Duration) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatalf("failed to set mtime of %s: %v", name, err)
+		}
+		return path
+	}
+	write("block_a", 100, 4*time.Hour)
+	write("block_b", 100, 3*time.Hour)
+	write("block_c", 100, 2*time.Hour)
+	write("unrelated", 1000, 5*time.Hour)
+	fresh := write("block_d", 100, 0)
+
+	files.prune([]string{fresh})
+
+	list, err := files.list()
+	if err != nil {
+		t.Fatalf("failed to list trace files: %v", err)
+	}
+	var names []string
+	for _, file := range list {
+		names = append(names, filepath.Base(file.Name))
+	}
+	if have, want := strings.Join(names, ","), "block_c,block_d"; have != want {
+		t.Errorf("retained files mismatch: have %s, want %s", have, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "unrelated")); err != nil {
+		t.Errorf("unrelated file deleted: %v", err)
+	}
+}
+
+// Tests that trace files in the shared system temp dir are never listed nor
+// deleted, and that a retention limit requires a dedicated directory.
+func TestTraceFileSharedDir(t *testing.T) {
+	files := &FileConfig{MaxSize: 1}
+	if err := files.Validate(); err == nil {
+		t.Fatalf("retention limit accepted without trace directory")
+	}
+	if _, err := files.list(); err != errNoTraceDir {
+		t.Fatalf("listing error mismatch: have %v, want %v", err, errNoTraceDir)
+	}
+	foreign, err := os.CreateTemp("", traceFilePrefix+"*")
+	if err != nil {
+		t.Fatalf("failed to create foreign file: %v", err)
+	}
+	foreign.Close()
+	defer os.Remove(foreign.Name())
+
+	files.prune(nil)
+	if _, err := os.Stat(foreign.Name()); err != nil {
+		t.Fatalf("foreign file deleted: %v", err)
+	}
+	if err := (&FileConfig{Dir: t.TempDir(), MaxSize: 1}).Validate(); err != nil {
+		t.Fatalf("dedicated trace directory rejected: %v", err)
+	}
+}
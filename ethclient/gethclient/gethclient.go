@@ -194,7 +194,10 @@ func (ec *Client) GetNodeInfo(ctx context.Context) (*p2p.NodeInfo, error) {
 	return &result, err
 }
 
-// SubscribeFullPendingTransactions subscribes to new pending transactions.
+// SubscribeFullPendingTransactions subscribes to new pending transactions,
+// delivering the full transaction objects instead of just their hashes. The
+// notifications also carry the sender in their "from" field, which can be
+// accessed by subscribing directly to "newPendingTransactions" with fullTx set.
 func (ec *Client) SubscribeFullPendingTransactions(ctx context.Context, ch chan<- *types.Transaction) (*rpc.ClientSubscription, error) {
 	return ec.c.EthSubscribe(ctx, ch, "newPendingTransactions", true)
 }
@@ -401,6 +401,17 @@ func testSubscribeFullPendingTransactions(t *testing.T, client *rpc.Client) {
 	// Subscribe to Transactions
 	ch := make(chan *types.Transaction)
 	ec.SubscribeFullPendingTransactions(context.Background(), ch)
+	// Subscribe to the raw notifications too, to check the sender is delivered
+	type rpcTx struct {
+		Hash common.Hash    `json:"hash"`
+		From common.Address `json:"from"`
+	}
+	rawCh := make(chan *rpcTx)
+	rawSub, err := client.EthSubscribe(context.Background(), rawCh, "newPendingTransactions", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rawSub.Unsubscribe()
 	// Send a transaction
 	chainID, err := ethcl.ChainID(context.Background())
 	if err != nil {
@@ -427,6 +438,13 @@ func testSubscribeFullPendingTransactions(t *testing.T, client *rpc.Client) {
 	if tx.Hash() != signedTx.Hash() {
 		t.Fatalf("Invalid tx hash received, got %v, want %v", tx.Hash(), signedTx.Hash())
 	}
+	raw := <-rawCh
+	if raw.Hash != signedTx.Hash() {
+		t.Fatalf("Invalid raw tx hash received, got %v, want %v", raw.Hash, signedTx.Hash())
+	}
+	if raw.From != testAddr {
+		t.Fatalf("Invalid tx sender received, got %v, want %v", raw.From, testAddr)
+	}
 }
 
 func testCallContract(t *testing.T, client *rpc.Client) {
@@ -380,6 +380,14 @@ func TestDatabaseSuite(t *testing.T, New func() ethdb.KeyValueStore) {
 				t.Fatal("Unexpected deletion")
 			}
 		}
+		// Ensure iteration sees the content at the time of the snapshot
+		if got, want := iterateKeys(snapshot.NewIterator(nil, nil)), []string{"k1", "k2", "k3", "k4"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Unexpected snapshot keys want: %v, got %v", want, got)
+		}
+		if got, want := iterateKeys(snapshot.NewIterator([]byte("k"), []byte("3"))), []string{"k3", "k4"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Unexpected snapshot keys want: %v, got %v", want, got)
+		}
+		snapshot.Release()
 	})
 
 	t.Run("OperatonsAfterClose", func(t *testing.T) {
@@ -0,0 +1,342 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package overlaydb implements a copy-on-write key-value store layering a
+// writable database on top of a read-only one.
+package overlaydb
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+var (
+	// errNotFound is returned if a key is requested that is neither in the
+	// overlay nor in the base database, or was deleted in the overlay.
+	errNotFound = errors.New("not found")
+
+	// errCorruptOverlay is returned if an overlay entry lacks its marker byte.
+	errCorruptOverlay = errors.New("corrupt overlay entry")
+)
+
+// Overlay entries are prefixed with a marker byte, so that deletions of keys
+// living in the base database can be recorded as tombstones without touching
+// the base itself.
+const (
+	markerDeleted byte = iota
+	markerValue
+)
+
+// Database is a key-value store that serves reads from a writable overlay
+// database first, falling back to a base database that is never modified.
+// All writes and deletions are recorded in the overlay only.
+type Database struct {
+	base    ethdb.KeyValueStore // Read-only database holding the original data
+	overlay ethdb.KeyValueStore // Writable database collecting all modifications
+}
+
+// New creates a copy-on-write database on top of base, recording every change
+// into overlay. Ownership of both databases is transferred to the returned
+// store, closing it closes both.
+func New(base ethdb.KeyValueStore, overlay ethdb.KeyValueStore) *Database {
+	return &Database{base: base, overlay: overlay}
+}
+
+// Close closes both the overlay and the base database.
+func (db *Database) Close() error {
+	err := db.overlay.Close()
+	if berr := db.base.Close(); err == nil {
+		err = berr
+	}
+	return err
+}
+
+// Has retrieves if a key is present in the key-value store.
+func (db *Database) Has(key []byte) (bool, error) {
+	return has(db.overlay, db.base, key)
+}
+
+// Get retrieves the given key if it's present in the key-value store.
+func (db *Database) Get(key []byte) ([]byte, error) {
+	return get(db.overlay, db.base, key)
+}
+
+// Put inserts the given value into the overlay.
+func (db *Database) Put(key []byte, value []byte) error {
+	return db.overlay.Put(key, encodeValue(value))
+}
+
+// Delete records a tombstone for the key in the overlay.
+func (db *Database) Delete(key []byte) error {
+	return db.overlay.Put(key, []byte{markerDeleted})
+}
+
+// NewBatch creates a write-only key-value store that buffers changes to the
+// overlay until a final write is called.
+func (db *Database) NewBatch() ethdb.Batch {
+	return &batch{db.overlay.NewBatch()}
+}
+
+// NewBatchWithSize creates a write-only database batch with pre-allocated buffer.
+func (db *Database) NewBatchWithSize(size int) ethdb.Batch {
+	return &batch{db.overlay.NewBatchWithSize(size)}
+}
+
+// NewIterator creates a binary-alphabetical iterator over a subset of database
+// content with a particular key prefix, starting at a particular initial key
+// (or after, if it does not exist).
+func (db *Database) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
+	return newIterator(db.overlay.NewIterator(prefix, start), db.base.NewIterator(prefix, start))
+}
+
+// Stat returns a particular internal stat of the overlay database.
+func (db *Database) Stat(property string) (string, error) {
+	return db.overlay.Stat(property)
+}
+
+// Compact flattens the overlay database for the given key range. The base
+// database is read-only and is left untouched.
+func (db *Database) Compact(start []byte, limit []byte) error {
+	return db.overlay.Compact(start, limit)
+}
+
+// NewSnapshot creates a database snapshot based on the current state of both
+// the overlay and the base database.
+func (db *Database) NewSnapshot() (ethdb.Snapshot, error) {
+	overlay, err := db.overlay.NewSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	base, err := db.base.NewSnapshot()
+	if err != nil {
+		overlay.Release()
+		return nil, err
+	}
+	return &snapshot{base: base, overlay: overlay}, nil
+}
+
+// reader is the common read interface of databases and their snapshots.
+type reader interface {
+	ethdb.KeyValueReader
+	NewIterator(prefix []byte, start []byte) ethdb.Iterator
+}
+
+// has checks the overlay for the key, falling back to the base if it was
+// never touched.
+func has(overlay, base reader, key []byte) (bool, error) {
+	enc, err := overlay.Get(key)
+	if err == nil {
+		return len(enc) > 0 && enc[0] == markerValue, nil
+	}
+	if ok, oerr := overlay.Has(key); oerr != nil || ok {
+		return false, err
+	}
+	return base.Has(key)
+}
+
+// get retrieves the key from the overlay, falling back to the base if it was
+// never touched.
+func get(overlay, base reader, key []byte) ([]byte, error) {
+	enc, err := overlay.Get(key)
+	if err == nil {
+		val, ok, err := decodeValue(enc)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, errNotFound
+		}
+		return val, nil
+	}
+	if ok, oerr := overlay.Has(key); oerr != nil || ok {
+		return nil, err
+	}
+	return base.Get(key)
+}
+
+// encodeValue prepends the value marker to a live value.
+func encodeValue(value []byte) []byte {
+	enc := make([]byte, 1+len(value))
+	enc[0] = markerValue
+	copy(enc[1:], value)
+	return enc
+}
+
+// decodeValue strips the marker from an overlay entry, reporting whether the
+// entry is a live value or a tombstone.
+func decodeValue(enc []byte) ([]byte, bool, error) {
+	if len(enc) == 0 {
+		return nil, false, errCorruptOverlay
+	}
+	switch enc[0] {
+	case markerValue:
+		return enc[1:], true, nil
+	case markerDeleted:
+		return nil, false, nil
+	default:
+		return nil, false, errCorruptOverlay
+	}
+}
+
+// batch is a write-only batch that encodes all operations for the overlay.
+type batch struct {
+	ethdb.Batch
+}
+
+// Put inserts the given value into the batch for later committing.
+func (b *batch) Put(key, value []byte) error {
+	return b.Batch.Put(key, encodeValue(value))
+}
+
+// Delete inserts a tombstone into the batch for later committing.
+func (b *batch) Delete(key []byte) error {
+	return b.Batch.Put(key, []byte{markerDeleted})
+}
+
+// Replay replays the batch contents, decoding the overlay entries back into
+// plain puts and deletes.
+func (b *batch) Replay(w ethdb.KeyValueWriter) error {
+	return b.Batch.Replay(&replayer{w: w})
+}
+
+// replayer translates encoded overlay writes into plain operations.
+type replayer struct {
+	w ethdb.KeyValueWriter
+}
+
+func (r *replayer) Put(key, enc []byte) error {
+	val, ok, err := decodeValue(enc)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return r.w.Delete(key)
+	}
+	return r.w.Put(key, val)
+}
+
+func (r *replayer) Delete(key []byte) error {
+	return r.w.Delete(key)
+}
+
+// snapshot is a point-in-time view over both layers of the database.
+type snapshot struct {
+	base    ethdb.Snapshot
+	overlay ethdb.Snapshot
+}
+
+// Has retrieves if a key is present in the snapshot.
+func (snap *snapshot) Has(key []byte) (bool, error) {
+	return has(snap.overlay, snap.base, key)
+}
+
+// Get retrieves the given key if it's present in the snapshot.
+func (snap *snapshot) Get(key []byte) ([]byte, error) {
+	return get(snap.overlay, snap.base, key)
+}
+
+// NewIterator creates an iterator over a subset of the snapshot content.
+func (snap *snapshot) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
+	return newIterator(snap.overlay.NewIterator(prefix, start), snap.base.NewIterator(prefix, start))
+}
+
+// Release releases the snapshots of both layers.
+func (snap *snapshot) Release() {
+	snap.overlay.Release()
+	snap.base.Release()
+}
+
+// iterator merges an overlay and a base iterator into a single sorted stream,
+// with overlay entries shadowing base ones and tombstones hiding keys. Entries
+// are copied out as the underlying iterators are advanced eagerly.
+type iterator struct {
+	overlay, base     ethdb.Iterator
+	overlayOk, baseOk bool // Whether the respective iterator has a pending entry
+	key, value        []byte
+	err               error
+}
+
+func newIterator(overlay, base ethdb.Iterator) *iterator {
+	return &iterator{
+		overlay:   overlay,
+		base:      base,
+		overlayOk: overlay.Next(),
+		baseOk:    base.Next(),
+	}
+}
+
+// Next moves the iterator to the next live key/value pair.
+func (it *iterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	for it.overlayOk || it.baseOk {
+		// Pick the base entry if it sorts strictly before the overlay one
+		if !it.overlayOk || (it.baseOk && bytes.Compare(it.base.Key(), it.overlay.Key()) < 0) {
+			it.key, it.value = common.CopyBytes(it.base.Key()), common.CopyBytes(it.base.Value())
+			it.baseOk = it.base.Next()
+			return true
+		}
+		// Overlay entry is next, drop any shadowed base entry
+		key, enc := common.CopyBytes(it.overlay.Key()), common.CopyBytes(it.overlay.Value())
+		if it.baseOk && bytes.Equal(it.base.Key(), key) {
+			it.baseOk = it.base.Next()
+		}
+		it.overlayOk = it.overlay.Next()
+
+		val, ok, err := decodeValue(enc)
+		if err != nil {
+			it.err = err
+			break
+		}
+		if ok {
+			it.key, it.value = key, val
+			return true
+		}
+	}
+	it.key, it.value = nil, nil
+	return false
+}
+
+// Error returns any accumulated error from either layer.
+func (it *iterator) Error() error {
+	if it.err != nil {
+		return it.err
+	}
+	if err := it.overlay.Error(); err != nil {
+		return err
+	}
+	return it.base.Error()
+}
+
+// Key returns the key of the current key/value pair, or nil if done.
+func (it *iterator) Key() []byte {
+	return it.key
+}
+
+// Value returns the value of the current key/value pair, or nil if done.
+func (it *iterator) Value() []byte {
+	return it.value
+}
+
+// Release releases both underlying iterators.
+func (it *iterator) Release() {
+	it.overlay.Release()
+	it.base.Release()
+}
@@ -0,0 +1,98 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package overlaydb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/dbtest"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+)
+
+func TestOverlayDB(t *testing.T) {
+	t.Run("DatabaseSuite", func(t *testing.T) {
+		dbtest.TestDatabaseSuite(t, func() ethdb.KeyValueStore {
+			return New(memorydb.New(), memorydb.New())
+		})
+	})
+}
+
+// Tests that modifications shadow the base database without ever touching it.
+func TestOverlayShadowing(t *testing.T) {
+	base := memorydb.New()
+	for _, k := range []string{"a", "b", "c", "d"} {
+		base.Put([]byte(k), []byte("base-"+k))
+	}
+	db := New(base, memorydb.New())
+
+	db.Put([]byte("b"), []byte("new-b"))
+	db.Put([]byte("e"), []byte("new-e"))
+	db.Delete([]byte("c"))
+
+	batch := db.NewBatch()
+	batch.Delete([]byte("a"))
+	batch.Put([]byte("a0"), []byte("new-a0"))
+	if err := batch.Write(); err != nil {
+		t.Fatalf("failed to write batch: %v", err)
+	}
+	// Check point lookups through the overlay
+	for key, want := range map[string]string{"a0": "new-a0", "b": "new-b", "d": "base-d", "e": "new-e"} {
+		if have, err := db.Get([]byte(key)); err != nil || string(have) != want {
+			t.Errorf("key %q: have %q (err %v), want %q", key, have, err, want)
+		}
+	}
+	for _, key := range []string{"a", "c", "x"} {
+		if ok, _ := db.Has([]byte(key)); ok {
+			t.Errorf("key %q: unexpectedly present", key)
+		}
+		if _, err := db.Get([]byte(key)); err == nil {
+			t.Errorf("key %q: expected lookup error", key)
+		}
+	}
+	// Check the merged iteration order
+	var keys, vals []string
+	it := db.NewIterator(nil, nil)
+	for it.Next() {
+		keys = append(keys, string(it.Key()))
+		vals = append(vals, string(it.Value()))
+	}
+	it.Release()
+	if err := it.Error(); err != nil {
+		t.Fatalf("iteration failed: %v", err)
+	}
+	wantKeys := []string{"a0", "b", "d", "e"}
+	wantVals := []string{"new-a0", "new-b", "base-d", "new-e"}
+	if len(keys) != len(wantKeys) {
+		t.Fatalf("iterated keys mismatch: have %v, want %v", keys, wantKeys)
+	}
+	for i := range keys {
+		if keys[i] != wantKeys[i] || vals[i] != wantVals[i] {
+			t.Errorf("entry %d: have %s=%s, want %s=%s", i, keys[i], vals[i], wantKeys[i], wantVals[i])
+		}
+	}
+	// Ensure the base database was left untouched
+	for _, k := range []string{"a", "b", "c", "d"} {
+		if have, _ := base.Get([]byte(k)); !bytes.Equal(have, []byte("base-"+k)) {
+			t.Errorf("base key %q modified: have %q", k, have)
+		}
+	}
+	if ok, _ := base.Has([]byte("e")); ok {
+		t.Errorf("base database received overlay write")
+	}
+}
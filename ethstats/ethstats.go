@@ -782,6 +782,49 @@ type nodeStats struct {
 	Peers    int  `json:"peers"`
 	GasPrice int  `json:"gasPrice"`
 	Uptime   int  `json:"uptime"`
+
+	NetworkHashrate *networkHashrate `json:"networkHashrate,omitempty"`
+}
+
+// networkHashrate is the estimated hashrate of the network.
+type networkHashrate struct {
+	Window   uint64   `json:"window"`
+	Hashrate *big.Int `json:"hashrate"`
+	Lower    *big.Int `json:"lower"`
+	Upper    *big.Int `json:"upper"`
+}
+
+// hashrateWindow is the number of recent blocks the reported network hashrate
+// is estimated from.
+const hashrateWindow = 120
+
+// estimateNetworkHashrate estimates the network hashrate from the most recent
+// headers, the same way as eth_hashrateEstimate. It returns nil if the chain is
+// too short or not proof-of-work.
+func (s *Service) estimateNetworkHashrate() *networkHashrate {
+	head := s.backend.CurrentHeader()
+	if head.Difficulty == nil || head.Difficulty.Sign() == 0 {
+		return nil
+	}
+	window := min(head.Number.Uint64(), hashrateWindow)
+	headers := make([]*types.Header, 0, window+1)
+	for number := head.Number.Uint64() - window; number <= head.Number.Uint64(); number++ {
+		header, err := s.backend.HeaderByNumber(context.Background(), rpc.BlockNumber(number))
+		if err != nil || header == nil {
+			return nil
+		}
+		headers = append(headers, header)
+	}
+	est, err := core.EstimateHashrate(headers)
+	if err != nil {
+		return nil
+	}
+	return &networkHashrate{
+		Window:   est.Blocks,
+		Hashrate: est.Hashrate,
+		Lower:    est.Lower,
+		Upper:    est.Upper,
+	}
 }
 
 // reportStats retrieves various stats about the node at the networking and
@@ -826,6 +869,8 @@ func (s *Service) reportStats(conn *connWrapper) error {
 			GasPrice: gasprice,
 			Syncing:  syncing,
 			Uptime:   100,
+
+			NetworkHashrate: s.estimateNetworkHashrate(),
 		},
 	}
 	report := map[string][]interface{}{
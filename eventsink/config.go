@@ -0,0 +1,174 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eventsink
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gopkg.in/yaml.v3"
+)
+
+// Event types which can be delivered to sinks.
+const (
+	EventNewHead     = "newHead"     // New canonical chain head
+	EventReorg       = "reorg"       // Chain head not extending the previous one
+	EventPendingTx   = "pendingTx"   // New pending transaction matching the sink filter
+	EventSyncStalled = "syncStalled" // No new chain head for the configured stall timeout
+	EventPeerDrop    = "peerDrop"    // Disconnected peer
+)
+
+var knownEvents = map[string]bool{
+	EventNewHead:     true,
+	EventReorg:       true,
+	EventPendingTx:   true,
+	EventSyncStalled: true,
+	EventPeerDrop:    true,
+}
+
+// Destination types events can be delivered to.
+const (
+	DestinationWebhook = "webhook" // HTTP POST of the JSON encoded event
+	DestinationKafka   = "kafka"   // Kafka topic, produced to through a REST proxy
+	DestinationFile    = "file"    // JSON lines appended to a file
+	DestinationExec    = "exec"    // Command run with the JSON encoded event on stdin
+)
+
+// Config is the event sink configuration, as loaded from the --sinks file.
+type Config struct {
+	StallTimeout time.Duration `yaml:"stallTimeout"` // Time without new head after which sync is considered stalled
+	Sinks        []SinkConfig  `yaml:"sinks"`
+}
+
+// SinkConfig configures the delivery of a set of events to a destination.
+type SinkConfig struct {
+	Name    string        `yaml:"name"`
+	Events  []string      `yaml:"events"`
+	Type    string        `yaml:"type"`
+	Filter  TxFilter      `yaml:"filter"` // Pending transaction filter (empty = all)
+	Retries int           `yaml:"retries"`
+	Backoff time.Duration `yaml:"backoff"` // Initial delay between retries, doubled on each attempt
+	Timeout time.Duration `yaml:"timeout"` // Timeout of a single delivery attempt
+	Queue   int           `yaml:"queue"`   // Number of events buffered for delivery
+
+	URL     string            `yaml:"url"`     // Webhook endpoint or Kafka REST proxy
+	Headers map[string]string `yaml:"headers"` // Extra HTTP headers (e.g. authorization)
+	Topic   string            `yaml:"topic"`   // Kafka topic
+	Path    string            `yaml:"path"`    // Output file
+	Command []string          `yaml:"command"` // Command and arguments to execute
+}
+
+// TxFilter selects the pending transactions delivered to a sink. A transaction
+// matches if its sender is in From or its recipient is in To.
+type TxFilter struct {
+	From []common.Address `yaml:"from"`
+	To   []common.Address `yaml:"to"`
+}
+
+// Default settings applied to unset configuration fields.
+const (
+	defaultStallTimeout = 5 * time.Minute
+	defaultRetries      = 3
+	defaultBackoff      = time.Second
+	defaultTimeout      = 10 * time.Second
+	defaultQueue        = 256
+)
+
+// LoadConfig reads and validates the event sink configuration file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	if err := config.sanitize(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// sanitize validates the configuration and fills in the defaults.
+func (c *Config) sanitize() error {
+	if c.StallTimeout <= 0 {
+		c.StallTimeout = defaultStallTimeout
+	}
+	if len(c.Sinks) == 0 {
+		return errors.New("no sinks configured")
+	}
+	names := make(map[string]bool)
+	for i := range c.Sinks {
+		sink := &c.Sinks[i]
+		if sink.Name == "" {
+			sink.Name = fmt.Sprintf("%s-%d", sink.Type, i)
+		}
+		if names[sink.Name] {
+			return fmt.Errorf("duplicate sink %q", sink.Name)
+		}
+		names[sink.Name] = true
+
+		if len(sink.Events) == 0 {
+			return fmt.Errorf("sink %q: no events", sink.Name)
+		}
+		for _, ev := range sink.Events {
+			if !knownEvents[ev] {
+				return fmt.Errorf("sink %q: unknown event %q", sink.Name, ev)
+			}
+		}
+		switch sink.Type {
+		case DestinationWebhook:
+			if sink.URL == "" {
+				return fmt.Errorf("sink %q: webhook without url", sink.Name)
+			}
+		case DestinationKafka:
+			if sink.URL == "" || sink.Topic == "" {
+				return fmt.Errorf("sink %q: kafka requires url and topic", sink.Name)
+			}
+		case DestinationFile:
+			if sink.Path == "" {
+				return fmt.Errorf("sink %q: file without path", sink.Name)
+			}
+		case DestinationExec:
+			if len(sink.Command) == 0 {
+				return fmt.Errorf("sink %q: exec without command", sink.Name)
+			}
+		default:
+			return fmt.Errorf("sink %q: unknown type %q", sink.Name, sink.Type)
+		}
+		if sink.Retries < 0 {
+			return fmt.Errorf("sink %q: negative retries", sink.Name)
+		}
+		if sink.Retries == 0 {
+			sink.Retries = defaultRetries
+		}
+		if sink.Backoff <= 0 {
+			sink.Backoff = defaultBackoff
+		}
+		if sink.Timeout <= 0 {
+			sink.Timeout = defaultTimeout
+		}
+		if sink.Queue <= 0 {
+			sink.Queue = defaultQueue
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eventsink
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// destination delivers encoded events to an external system.
+type destination interface {
+	deliver(ctx context.Context, ev *Event, blob []byte) error
+}
+
+// newDestination creates the destination of a sanitized sink configuration.
+func newDestination(config *SinkConfig) destination {
+	switch config.Type {
+	case DestinationWebhook:
+		return &webhook{url: config.URL, headers: config.Headers, contentType: "application/json"}
+	case DestinationKafka:
+		// Produce through the Confluent REST proxy API, no native Kafka client
+		return &webhook{
+			url:         strings.TrimRight(config.URL, "/") + "/topics/" + config.Topic,
+			headers:     config.Headers,
+			contentType: "application/vnd.kafka.json.v2+json",
+			wrap:        true,
+		}
+	case DestinationFile:
+		return &file{path: config.Path}
+	case DestinationExec:
+		return &command{args: config.Command}
+	}
+	panic(fmt.Sprintf("unknown destination type %q", config.Type))
+}
+
+// webhook POSTs events to an HTTP endpoint.
+type webhook struct {
+	url         string
+	headers     map[string]string
+	contentType string
+	wrap        bool // Whether to wrap the event into a Kafka REST proxy record
+}
+
+func (w *webhook) deliver(ctx context.Context, ev *Event, blob []byte) error {
+	if w.wrap {
+		blob = []byte(`{"records":[{"value":` + string(blob) + `}]}`)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(blob))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", w.contentType)
+	for key, val := range w.headers {
+		req.Header.Set(key, val)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64*1024))
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status: %s", res.Status)
+	}
+	return nil
+}
+
+// file appends events to a file as JSON lines.
+type file struct {
+	path string
+	lock sync.Mutex
+}
+
+func (f *file) deliver(ctx context.Context, ev *Event, blob []byte) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	out, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := out.Write(append(blob, '\n')); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// command runs an external command for every event, passing the JSON encoded
+// event on its standard input and the event type in the EVENT_TYPE variable.
+type command struct {
+	args []string
+}
+
+func (c *command) deliver(ctx context.Context, ev *Event, blob []byte) error {
+	cmd := exec.CommandContext(ctx, c.args[0], c.args[1:]...)
+	cmd.Env = append(os.Environ(), "EVENT_TYPE="+ev.Type)
+	cmd.Stdin = bytes.NewReader(blob)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
@@ -0,0 +1,370 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package eventsink delivers internal node events to external destinations,
+// such as webhooks, Kafka topics, files or commands, with retries.
+package eventsink
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
+	chainHeadChanSize = 10
+
+	// txChanSize is the size of channel listening to NewTxsEvent.
+	txChanSize = 4096
+
+	// peerChanSize is the size of channel listening to PeerEvent.
+	peerChanSize = 64
+
+	// maxBackoff is the maximum delay between two delivery attempts.
+	maxBackoff = time.Minute
+)
+
+// backend encompasses the bare-minimum functionality needed for event delivery.
+type backend interface {
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+	SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription
+	CurrentHeader() *types.Header
+	HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error)
+	ChainConfig() ctypes.ChainConfigurator
+}
+
+// Event is an internal node event, as delivered to the sinks.
+type Event struct {
+	Type string      `json:"type"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data"`
+}
+
+// HeadEvent is the data of newHead events.
+type HeadEvent struct {
+	Number     uint64      `json:"number"`
+	Hash       common.Hash `json:"hash"`
+	ParentHash common.Hash `json:"parentHash"`
+	Time       uint64      `json:"time"`
+}
+
+// ReorgEvent is the data of reorg events.
+type ReorgEvent struct {
+	OldNumber uint64      `json:"oldNumber"`
+	OldHash   common.Hash `json:"oldHash"`
+	NewNumber uint64      `json:"newNumber"`
+	NewHash   common.Hash `json:"newHash"`
+}
+
+// PendingTxEvent is the data of pendingTx events.
+type PendingTxEvent struct {
+	Hash  common.Hash     `json:"hash"`
+	From  common.Address  `json:"from"`
+	To    *common.Address `json:"to"`
+	Value *hexutil.Big    `json:"value"`
+	Nonce uint64          `json:"nonce"`
+}
+
+// SyncStalledEvent is the data of syncStalled events.
+type SyncStalledEvent struct {
+	Number uint64      `json:"number"`
+	Hash   common.Hash `json:"hash"`
+	Since  time.Time   `json:"since"` // Time of the last chain head update
+}
+
+// PeerDropEvent is the data of peerDrop events.
+type PeerDropEvent struct {
+	Peer   string `json:"peer"`
+	Remote string `json:"remote,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// sink is a configured event destination with its delivery queue.
+type sink struct {
+	config SinkConfig
+	dest   destination
+	events map[string]bool
+	from   map[common.Address]bool
+	to     map[common.Address]bool
+	queue  chan *Event
+}
+
+func newSink(config SinkConfig) *sink {
+	s := &sink{
+		config: config,
+		dest:   newDestination(&config),
+		events: make(map[string]bool),
+		from:   make(map[common.Address]bool),
+		to:     make(map[common.Address]bool),
+		queue:  make(chan *Event, config.Queue),
+	}
+	for _, ev := range config.Events {
+		s.events[ev] = true
+	}
+	for _, addr := range config.Filter.From {
+		s.from[addr] = true
+	}
+	for _, addr := range config.Filter.To {
+		s.to[addr] = true
+	}
+	return s
+}
+
+// wants returns whether the event should be delivered to the sink.
+func (s *sink) wants(ev *Event) bool {
+	if !s.events[ev.Type] {
+		return false
+	}
+	if tx, ok := ev.Data.(*PendingTxEvent); ok && (len(s.from) > 0 || len(s.to) > 0) {
+		return s.from[tx.From] || (tx.To != nil && s.to[*tx.To])
+	}
+	return true
+}
+
+// loop delivers the queued events until termination.
+func (s *sink) loop(quit chan struct{}) {
+	for {
+		select {
+		case ev := <-s.queue:
+			s.deliver(ev, quit)
+		case <-quit:
+			return
+		}
+	}
+}
+
+// deliver sends an event to the destination, retrying with an exponential
+// backoff on failure.
+func (s *sink) deliver(ev *Event, quit chan struct{}) {
+	blob, err := json.Marshal(ev)
+	if err != nil {
+		log.Error("Failed to encode event", "sink", s.config.Name, "type", ev.Type, "err", err)
+		return
+	}
+	backoff := s.config.Backoff
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+		err = s.dest.deliver(ctx, ev, blob)
+		cancel()
+		if err == nil {
+			return
+		}
+		if attempt >= s.config.Retries {
+			log.Warn("Failed to deliver event", "sink", s.config.Name, "type", ev.Type, "attempts", attempt+1, "err", err)
+			return
+		}
+		log.Debug("Event delivery failed, retrying", "sink", s.config.Name, "type", ev.Type, "backoff", backoff, "err", err)
+		select {
+		case <-time.After(backoff):
+		case <-quit:
+			return
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// Service delivers node events to the configured sinks.
+type Service struct {
+	backend backend
+	server  *p2p.Server
+	config  Config
+	sinks   []*sink
+	pending bool // Whether any sink is interested in pending transactions
+
+	headSub event.Subscription
+	txSub   event.Subscription
+	peerSub event.Subscription
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates an event sink service with the given configuration and registers
+// it on the node.
+func New(node *node.Node, backend backend, config *Config) error {
+	s := newService(backend, node.Server(), config)
+	node.RegisterLifecycle(s)
+	return nil
+}
+
+func newService(backend backend, server *p2p.Server, config *Config) *Service {
+	s := &Service{
+		backend: backend,
+		server:  server,
+		config:  *config,
+		quit:    make(chan struct{}),
+	}
+	for _, cfg := range config.Sinks {
+		sink := newSink(cfg)
+		s.pending = s.pending || sink.events[EventPendingTx]
+		s.sinks = append(s.sinks, sink)
+	}
+	return s
+}
+
+// Start implements node.Lifecycle, starting the event subscriptions and delivery.
+func (s *Service) Start() error {
+	headCh := make(chan core.ChainHeadEvent, chainHeadChanSize)
+	s.headSub = s.backend.SubscribeChainHeadEvent(headCh)
+
+	txCh := make(chan core.NewTxsEvent, txChanSize)
+	if s.pending {
+		s.txSub = s.backend.SubscribeNewTxsEvent(txCh)
+	}
+	peerCh := make(chan *p2p.PeerEvent, peerChanSize)
+	if s.server != nil {
+		s.peerSub = s.server.SubscribeEvents(peerCh)
+	}
+	for _, snk := range s.sinks {
+		s.wg.Add(1)
+		go func(snk *sink) {
+			defer s.wg.Done()
+			snk.loop(s.quit)
+		}(snk)
+	}
+	s.wg.Add(1)
+	go s.loop(headCh, txCh, peerCh)
+
+	log.Info("Event sinks started", "sinks", len(s.sinks))
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the event delivery.
+func (s *Service) Stop() error {
+	s.headSub.Unsubscribe()
+	if s.txSub != nil {
+		s.txSub.Unsubscribe()
+	}
+	if s.peerSub != nil {
+		s.peerSub.Unsubscribe()
+	}
+	close(s.quit)
+	s.wg.Wait()
+
+	log.Info("Event sinks stopped")
+	return nil
+}
+
+// loop converts the subscribed node events into sink events until termination.
+func (s *Service) loop(headCh chan core.ChainHeadEvent, txCh chan core.NewTxsEvent, peerCh chan *p2p.PeerEvent) {
+	defer s.wg.Done()
+
+	var (
+		head    = s.backend.CurrentHeader()
+		updated = time.Now()
+		stalled bool
+
+		signer = types.LatestSigner(s.backend.ChainConfig())
+		ticker = time.NewTicker(s.config.StallTimeout / 4)
+	)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case ev := <-headCh:
+			// Head events are not delivered for every block of a batch import, so
+			// only report a reorg if the previous head left the canonical chain.
+			block := ev.Block
+			if head != nil && block.ParentHash() != head.Hash() && !s.canonical(head) {
+				s.dispatch(EventReorg, &ReorgEvent{
+					OldNumber: head.Number.Uint64(),
+					OldHash:   head.Hash(),
+					NewNumber: block.NumberU64(),
+					NewHash:   block.Hash(),
+				})
+			}
+			s.dispatch(EventNewHead, &HeadEvent{
+				Number:     block.NumberU64(),
+				Hash:       block.Hash(),
+				ParentHash: block.ParentHash(),
+				Time:       block.Time(),
+			})
+			head, updated, stalled = block.Header(), time.Now(), false
+
+		case ev := <-txCh:
+			for _, tx := range ev.Txs {
+				from, _ := types.Sender(signer, tx)
+				s.dispatch(EventPendingTx, &PendingTxEvent{
+					Hash:  tx.Hash(),
+					From:  from,
+					To:    tx.To(),
+					Value: (*hexutil.Big)(new(big.Int).Set(tx.Value())),
+					Nonce: tx.Nonce(),
+				})
+			}
+
+		case ev := <-peerCh:
+			if ev.Type == p2p.PeerEventTypeDrop {
+				s.dispatch(EventPeerDrop, &PeerDropEvent{
+					Peer:   ev.Peer.String(),
+					Remote: ev.RemoteAddress,
+					Error:  ev.Error,
+				})
+			}
+
+		case <-ticker.C:
+			if !stalled && time.Since(updated) > s.config.StallTimeout {
+				stall := &SyncStalledEvent{Since: updated}
+				if head != nil {
+					stall.Number, stall.Hash = head.Number.Uint64(), head.Hash()
+				}
+				s.dispatch(EventSyncStalled, stall)
+				stalled = true
+			}
+
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// canonical reports whether the header is still part of the canonical chain.
+func (s *Service) canonical(header *types.Header) bool {
+	canon, err := s.backend.HeaderByNumber(context.Background(), rpc.BlockNumber(header.Number.Int64()))
+	return err == nil && canon != nil && canon.Hash() == header.Hash()
+}
+
+// dispatch queues an event on all interested sinks, dropping it for the ones
+// which are lagging behind.
+func (s *Service) dispatch(typ string, data interface{}) {
+	ev := &Event{Type: typ, Time: time.Now(), Data: data}
+	for _, sink := range s.sinks {
+		if !sink.wants(ev) {
+			continue
+		}
+		select {
+		case sink.queue <- ev:
+		default:
+			log.Warn("Event sink queue full, dropping event", "sink", sink.config.Name, "type", typ)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eventsink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type testBackend struct {
+	headFeed event.Feed
+	txFeed   event.Feed
+	head     *types.Header
+
+	canon map[uint64]*types.Header // Canonical headers by number
+	lock  sync.Mutex
+}
+
+func (b *testBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return b.headFeed.Subscribe(ch)
+}
+func (b *testBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
+	return b.txFeed.Subscribe(ch)
+}
+func (b *testBackend) CurrentHeader() *types.Header          { return b.head }
+func (b *testBackend) ChainConfig() ctypes.ChainConfigurator { return params.TestChainConfig }
+func (b *testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.canon[uint64(number)], nil
+}
+
+// setHead makes the block the head of the canonical chain and announces it.
+func (b *testBackend) setHead(block *types.Block) {
+	b.lock.Lock()
+	if b.canon == nil {
+		b.canon = make(map[uint64]*types.Header)
+	}
+	b.canon[block.NumberU64()] = block.Header()
+	b.lock.Unlock()
+
+	b.headFeed.Send(core.ChainHeadEvent{Block: block})
+}
+
+// Tests that the sink configuration is parsed and sanitized.
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sinks.yaml")
+	os.WriteFile(path, []byte(`
+stallTimeout: 2m
+sinks:
+  - name: alerts
+    type: webhook
+    url: http://localhost:8080/hook
+    events: [reorg, syncStalled]
+    backoff: 500ms
+  - type: file
+    path: /tmp/txs.jsonl
+    events: [pendingTx]
+    filter:
+      to: ["0x00000000000000000000000000000000000000aa"]
+`), 0644)
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if config.StallTimeout != 2*time.Minute {
+		t.Errorf("stall timeout mismatch: have %v, want %v", config.StallTimeout, 2*time.Minute)
+	}
+	if len(config.Sinks) != 2 {
+		t.Fatalf("sink count mismatch: have %d, want 2", len(config.Sinks))
+	}
+	if sink := config.Sinks[0]; sink.Backoff != 500*time.Millisecond || sink.Retries != defaultRetries {
+		t.Errorf("sink settings mismatch: backoff %v, retries %d", sink.Backoff, sink.Retries)
+	}
+	if sink := config.Sinks[1]; sink.Name != "file-1" || len(sink.Filter.To) != 1 || sink.Filter.To[0] != common.HexToAddress("0xaa") {
+		t.Errorf("sink filter mismatch: %+v", sink)
+	}
+	// Invalid configurations must be rejected
+	for i, conf := range []string{
+		`sinks: []`,
+		`sinks: [{type: webhook, events: [newHead]}]`,
+		`sinks: [{type: file, path: x, events: [unknown]}]`,
+		`sinks: [{type: smoke, events: [newHead]}]`,
+		`sinks: [{type: kafka, url: http://proxy, events: [newHead]}]`,
+	} {
+		os.WriteFile(path, []byte(conf), 0644)
+		if _, err := LoadConfig(path); err == nil {
+			t.Errorf("test %d: invalid config accepted", i)
+		}
+	}
+}
+
+// Tests that chain events are converted and delivered to the matching sinks.
+func TestDelivery(t *testing.T) {
+	var (
+		dir     = t.TempDir()
+		genesis = &types.Header{Number: big.NewInt(0)}
+		backend = &testBackend{head: genesis}
+		target  = common.HexToAddress("0xaa")
+	)
+	config := &Config{
+		StallTimeout: time.Hour,
+		Sinks: []SinkConfig{
+			{Name: "chain", Type: DestinationFile, Path: filepath.Join(dir, "chain.jsonl"), Events: []string{EventNewHead, EventReorg}},
+			{Name: "txs", Type: DestinationFile, Path: filepath.Join(dir, "txs.jsonl"), Events: []string{EventPendingTx}, Filter: TxFilter{To: []common.Address{target}}},
+		},
+	}
+	if err := config.sanitize(); err != nil {
+		t.Fatalf("invalid config: %v", err)
+	}
+	s := newService(backend, nil, config)
+	if err := s.Start(); err != nil {
+		t.Fatalf("failed to start service: %v", err)
+	}
+	// Extend the chain, switch to a sibling branch, then import a batch on top of
+	// it, announcing only the last block like the blockchain does
+	var (
+		block1  = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), ParentHash: genesis.Hash()})
+		block1b = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), ParentHash: genesis.Hash(), Extra: []byte{1}})
+		block2  = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(2), ParentHash: block1b.Hash()})
+		block3  = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(3), ParentHash: block2.Hash()})
+	)
+	backend.setHead(block1)
+	backend.setHead(block1b)
+
+	backend.lock.Lock()
+	backend.canon[2] = block2.Header()
+	backend.lock.Unlock()
+	backend.setHead(block3)
+
+	key, _ := crypto.GenerateKey()
+	signer := types.LatestSigner(params.TestChainConfig)
+	backend.txFeed.Send(core.NewTxsEvent{Txs: []*types.Transaction{
+		types.MustSignNewTx(key, signer, &types.LegacyTx{Nonce: 0, To: &target, Gas: 21000, GasPrice: big.NewInt(1)}),
+		types.MustSignNewTx(key, signer, &types.LegacyTx{Nonce: 1, To: &common.Address{}, Gas: 21000, GasPrice: big.NewInt(1)}),
+	}})
+	readEvents := func(name string, want int) []Event {
+		var events []Event
+		for i := 0; i < 100; i++ {
+			events = events[:0]
+			if f, err := os.Open(filepath.Join(dir, name)); err == nil {
+				scanner := bufio.NewScanner(f)
+				for scanner.Scan() {
+					var ev Event
+					if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+						t.Fatalf("invalid event %s: %v", scanner.Text(), err)
+					}
+					events = append(events, ev)
+				}
+				f.Close()
+			}
+			if len(events) >= want {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if len(events) != want {
+			t.Fatalf("%s: event count mismatch: have %d, want %d", name, len(events), want)
+		}
+		return events
+	}
+	chain := readEvents("chain.jsonl", 4)
+	if chain[0].Type != EventNewHead || chain[1].Type != EventReorg || chain[2].Type != EventNewHead || chain[3].Type != EventNewHead {
+		t.Errorf("chain event mismatch: have %s, %s, %s, %s", chain[0].Type, chain[1].Type, chain[2].Type, chain[3].Type)
+	}
+	txs := readEvents("txs.jsonl", 1)
+	if data := txs[0].Data.(map[string]interface{}); common.HexToAddress(data["to"].(string)) != target {
+		t.Errorf("pending tx recipient mismatch: have %v, want %v", data["to"], target)
+	}
+	if err := s.Stop(); err != nil {
+		t.Fatalf("failed to stop service: %v", err)
+	}
+}
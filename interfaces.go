@@ -121,6 +121,10 @@ type SyncProgress struct {
 	HealingTrienodes uint64 // Number of state trie nodes pending
 	HealingBytecode  uint64 // Number of bytecodes pending
 
+	HealingScheduled uint64 // Number of state trie nodes and bytecodes known to be missing
+	HealingRate      uint64 // Number of state trie nodes and bytecodes healed per second, recently
+	HealingETA       uint64 // Estimated seconds until healing completes, a lower bound (0 if unknown)
+
 	// "transaction indexing" fields
 	TxIndexFinishedBlocks  uint64 // Number of blocks whose transactions are already indexed
 	TxIndexRemainingBlocks uint64 // Number of blocks whose transactions are not indexed yet
@@ -238,6 +238,20 @@ func (*HandlerT) SetGCPercent(v int) int {
 	return debug.SetGCPercent(v)
 }
 
+// FlightRecord writes the metrics samples kept by the flight recorder to the
+// given file, or to the file of the flight recorder if none is given.
+func (*HandlerT) FlightRecord(file *string) error {
+	if flightRec == nil {
+		return errors.New("flight recorder not enabled")
+	}
+	dump := flightRec.file
+	if file != nil && *file != "" {
+		dump = expandHome(*file)
+	}
+	log.Info("Writing flight record", "dump", dump)
+	return flightRec.dump(dump)
+}
+
 func writeProfile(name, file string) error {
 	p := pprof.Lookup(name)
 	log.Info("Writing profile records", "count", p.Count(), "type", name, "dump", file)
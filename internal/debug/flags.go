@@ -25,8 +25,10 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"time"
 
 	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/internal/watchdog"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/metrics/exp"
@@ -143,6 +145,40 @@ var (
 		Usage:    "Write execution trace to the given file",
 		Category: flags.LoggingCategory,
 	}
+	flightRecorderFlag = &cli.StringFlag{
+		Name:     "flightrecorder",
+		Usage:    "Keep samples of key metrics of the recent past in the given file, for post-mortem analysis",
+		Category: flags.LoggingCategory,
+	}
+	flightRecorderWindowFlag = &cli.DurationFlag{
+		Name:     "flightrecorder.window",
+		Usage:    "Time window of metrics samples kept by the flight recorder",
+		Value:    10 * time.Minute,
+		Category: flags.LoggingCategory,
+	}
+	flightRecorderIntervalFlag = &cli.DurationFlag{
+		Name:     "flightrecorder.interval",
+		Usage:    "Interval between the metrics samples of the flight recorder",
+		Value:    5 * time.Second,
+		Category: flags.LoggingCategory,
+	}
+	watchdogDirFlag = &cli.StringFlag{
+		Name:     "watchdog.dir",
+		Usage:    "Write goroutine stack dumps to the given directory when key locks are held or RPC calls run too long",
+		Category: flags.LoggingCategory,
+	}
+	watchdogLockFlag = &cli.DurationFlag{
+		Name:     "watchdog.lock",
+		Usage:    "Maximum hold time of the chain and transaction pool locks before dumping stacks (0 = disabled)",
+		Value:    10 * time.Second,
+		Category: flags.LoggingCategory,
+	}
+	watchdogRPCFlag = &cli.DurationFlag{
+		Name:     "watchdog.rpc",
+		Usage:    "Maximum duration of RPC calls before dumping stacks (0 = disabled)",
+		Value:    time.Minute,
+		Category: flags.LoggingCategory,
+	}
 )
 
 // Flags holds all command-line flags required for debugging.
@@ -165,11 +201,18 @@ var Flags = []cli.Flag{
 	blockprofilerateFlag,
 	cpuprofileFlag,
 	traceFlag,
+	flightRecorderFlag,
+	flightRecorderWindowFlag,
+	flightRecorderIntervalFlag,
+	watchdogDirFlag,
+	watchdogLockFlag,
+	watchdogRPCFlag,
 }
 
 var (
 	glogger       *log.GlogHandler
 	logOutputFile io.WriteCloser
+	flightRec     *flightRecorder
 )
 
 func init() {
@@ -290,6 +333,20 @@ func Setup(ctx *cli.Context) error {
 		}
 	}
 
+	if file := ctx.String(flightRecorderFlag.Name); file != "" {
+		interval := ctx.Duration(flightRecorderIntervalFlag.Name)
+		if interval <= 0 {
+			return fmt.Errorf("invalid flight recorder interval %v", interval)
+		}
+		flightRec = newFlightRecorder(file, ctx.Duration(flightRecorderWindowFlag.Name), interval)
+		flightRec.start()
+	}
+	if dir := ctx.String(watchdogDirFlag.Name); dir != "" {
+		if err := watchdog.Enable(dir, ctx.Duration(watchdogLockFlag.Name), ctx.Duration(watchdogRPCFlag.Name)); err != nil {
+			return fmt.Errorf("failed to enable watchdog: %v", err)
+		}
+	}
+
 	// pprof server
 	if ctx.Bool(pprofFlag.Name) {
 		listenHost := ctx.String(pprofAddrFlag.Name)
@@ -327,6 +384,9 @@ func StartPProf(address string, withMetrics bool) {
 func Exit() {
 	Handler.StopCPUProfile()
 	Handler.StopGoTrace()
+	if flightRec != nil {
+		flightRec.stop()
+	}
 	if logOutputFile != nil {
 		logOutputFile.Close()
 	}
@@ -0,0 +1,200 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package debug
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// flightRecorderMetrics are the metrics sampled by the flight recorder. Timers
+// are recorded as their mean duration in milliseconds, meters as their one
+// minute rate and gauges as their value.
+var flightRecorderMetrics = []string{
+	"chain/head/block",
+	"chain/inserts",
+	"chain/execution",
+	"chain/validation",
+	"chain/write",
+	"p2p/peers",
+	"p2p/peers/inbound",
+	"p2p/peers/outbound",
+	"txpool/pending",
+	"txpool/queued",
+}
+
+// flightRecorderCaches are the caches whose hit rates are sampled by the flight
+// recorder, as the names of their hit and miss meters.
+var flightRecorderCaches = map[string][2]string{
+	"cache/snapshot/account": {"state/snapshot/clean/account/hit", "state/snapshot/clean/account/miss"},
+	"cache/snapshot/storage": {"state/snapshot/clean/storage/hit", "state/snapshot/clean/storage/miss"},
+	"cache/hashdb":           {"hashdb/memcache/clean/hit", "hashdb/memcache/clean/miss"},
+	"cache/pathdb":           {"pathdb/clean/hit", "pathdb/clean/miss"},
+}
+
+// FlightSample is a single sample of the flight recorder.
+type FlightSample struct {
+	Time       time.Time          `json:"time"`
+	Goroutines int                `json:"goroutines"`
+	HeapAlloc  uint64             `json:"heapAlloc"`
+	HeapSys    uint64             `json:"heapSys"`
+	Metrics    map[string]float64 `json:"metrics"`
+}
+
+// flightRecorder periodically samples key metrics into a ring buffer, keeping
+// the samples of the most recent time window. The samples are regularly written
+// to a file, so they survive a crash of the process.
+type flightRecorder struct {
+	file     string
+	interval time.Duration
+
+	mu      sync.Mutex
+	samples []FlightSample // ring buffer of samples
+	next    int            // index of the next sample to write
+	full    bool           // whether the ring buffer wrapped around
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newFlightRecorder creates a flight recorder keeping the samples of the given
+// time window.
+func newFlightRecorder(file string, window, interval time.Duration) *flightRecorder {
+	size := int(window / interval)
+	if size < 1 {
+		size = 1
+	}
+	return &flightRecorder{
+		file:     file,
+		interval: interval,
+		samples:  make([]FlightSample, size),
+		quit:     make(chan struct{}),
+	}
+}
+
+// start launches the sampling loop.
+func (r *flightRecorder) start() {
+	r.wg.Add(1)
+	go r.loop()
+}
+
+// stop terminates the sampling loop and writes the final samples to the file.
+func (r *flightRecorder) stop() {
+	close(r.quit)
+	r.wg.Wait()
+	if err := r.dump(r.file); err != nil {
+		log.Warn("Failed to write flight record", "file", r.file, "err", err)
+	}
+}
+
+func (r *flightRecorder) loop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.add(sampleFlight())
+			if err := r.dump(r.file); err != nil {
+				log.Debug("Failed to write flight record", "file", r.file, "err", err)
+			}
+		case <-r.quit:
+			return
+		}
+	}
+}
+
+// add inserts a sample, overwriting the oldest one if the buffer is full.
+func (r *flightRecorder) add(s FlightSample) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.samples[r.next] = s
+	r.next = (r.next + 1) % len(r.samples)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// record returns the recorded samples, oldest first.
+func (r *flightRecorder) record() []FlightSample {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]FlightSample{}, r.samples[:r.next]...)
+	}
+	return append(append([]FlightSample{}, r.samples[r.next:]...), r.samples[:r.next]...)
+}
+
+// dump atomically writes the recorded samples to the given file as JSON.
+func (r *flightRecorder) dump(file string) error {
+	blob, err := json.MarshalIndent(r.record(), "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(filepath.Dir(file), "."+filepath.Base(file)+".tmp")
+	if err := os.WriteFile(tmp, blob, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// sampleFlight collects the current values of the flight recorder metrics.
+func sampleFlight() FlightSample {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	s := FlightSample{
+		Time:       time.Now(),
+		Goroutines: runtime.NumGoroutine(),
+		HeapAlloc:  mem.HeapAlloc,
+		HeapSys:    mem.HeapSys,
+		Metrics:    make(map[string]float64),
+	}
+	for _, name := range flightRecorderMetrics {
+		switch m := metrics.DefaultRegistry.Get(name).(type) {
+		case metrics.Timer:
+			s.Metrics[name] = m.Snapshot().Mean() / float64(time.Millisecond)
+		case metrics.Meter:
+			s.Metrics[name] = m.Snapshot().Rate1()
+		case metrics.Gauge:
+			s.Metrics[name] = float64(m.Snapshot().Value())
+		}
+	}
+	for name, meters := range flightRecorderCaches {
+		hit, ok1 := metrics.DefaultRegistry.Get(meters[0]).(metrics.Meter)
+		miss, ok2 := metrics.DefaultRegistry.Get(meters[1]).(metrics.Meter)
+		if !ok1 || !ok2 {
+			continue
+		}
+		hits, misses := hit.Snapshot().Rate1(), miss.Snapshot().Rate1()
+		if hits+misses > 0 {
+			s.Metrics[name] = hits / (hits + misses)
+		}
+	}
+	return s
+}
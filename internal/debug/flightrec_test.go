@@ -0,0 +1,60 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package debug
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Tests that the flight recorder keeps the most recent samples in order and
+// writes them to its file.
+func TestFlightRecorder(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "flight.json")
+	r := newFlightRecorder(file, 3*time.Second, time.Second)
+
+	for i := 1; i <= 5; i++ {
+		s := sampleFlight()
+		s.Goroutines = i
+		r.add(s)
+	}
+	if err := r.dump(file); err != nil {
+		t.Fatalf("failed to write flight record: %v", err)
+	}
+	blob, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("failed to read flight record: %v", err)
+	}
+	var samples []FlightSample
+	if err := json.Unmarshal(blob, &samples); err != nil {
+		t.Fatalf("failed to decode flight record: %v", err)
+	}
+	if len(samples) != 3 {
+		t.Fatalf("wrong number of samples: have %d, want 3", len(samples))
+	}
+	for i, s := range samples {
+		if s.Goroutines != i+3 {
+			t.Errorf("sample %d: wrong goroutines: have %d, want %d", i, s.Goroutines, i+3)
+		}
+		if s.HeapAlloc == 0 {
+			t.Errorf("sample %d: missing heap size", i)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package debug
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime/pprof"
+	"runtime/trace"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// maxProfileDuration is the maximum duration of a profile captured over RPC.
+const maxProfileDuration = 5 * time.Minute
+
+// ProfileAPI captures runtime profiles over RPC. As profiles reveal internals
+// of the node, it must only be served to the node operator, in-process and over IPC.
+type ProfileAPI struct{}
+
+// CaptureProfile collects a runtime profile and returns it in the pprof format.
+// CPU profiles and execution traces ("cpu", "trace") are collected for the given
+// number of seconds. For all other profiles (e.g. "heap", "goroutine", "block")
+// the current state is returned and seconds must be zero.
+func (*ProfileAPI) CaptureProfile(ctx context.Context, kind string, seconds uint64) (hexutil.Bytes, error) {
+	duration := time.Duration(seconds) * time.Second
+	if duration > maxProfileDuration {
+		return nil, fmt.Errorf("profile duration too long, maximum is %v", maxProfileDuration)
+	}
+	var buf bytes.Buffer
+	switch kind {
+	case "cpu", "trace":
+		if duration == 0 {
+			return nil, errors.New("profile duration required")
+		}
+		if err := captureTimedProfile(ctx, kind, duration, &buf); err != nil {
+			return nil, err
+		}
+	default:
+		p := pprof.Lookup(kind)
+		if p == nil {
+			return nil, fmt.Errorf("unknown profile type %q", kind)
+		}
+		if duration != 0 {
+			return nil, fmt.Errorf("%s profile can't be collected over time", kind)
+		}
+		if err := p.WriteTo(&buf, 0); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// captureTimedProfile writes a CPU profile or an execution trace of the given
+// duration. Only one of each can run at a time, so this fails if one was started
+// through the debug API already.
+func captureTimedProfile(ctx context.Context, kind string, duration time.Duration, w io.Writer) error {
+	if kind == "cpu" {
+		if err := pprof.StartCPUProfile(w); err != nil {
+			return err
+		}
+		defer pprof.StopCPUProfile()
+	} else {
+		if err := trace.Start(w); err != nil {
+			return err
+		}
+		defer trace.Stop()
+	}
+	log.Info("Capturing profile", "type", kind, "duration", duration)
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package debug
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"testing"
+)
+
+func TestCaptureProfile(t *testing.T) {
+	api := new(ProfileAPI)
+
+	// Snapshot profiles are returned as gzipped protobuf
+	for _, kind := range []string{"heap", "goroutine"} {
+		blob, err := api.CaptureProfile(context.Background(), kind, 0)
+		if err != nil {
+			t.Fatalf("%s: failed to capture profile: %v", kind, err)
+		}
+		if _, err := gzip.NewReader(bytes.NewReader(blob)); err != nil {
+			t.Errorf("%s: profile not in pprof format: %v", kind, err)
+		}
+	}
+	blob, err := api.CaptureProfile(context.Background(), "cpu", 1)
+	if err != nil {
+		t.Fatalf("failed to capture CPU profile: %v", err)
+	}
+	if _, err := gzip.NewReader(bytes.NewReader(blob)); err != nil {
+		t.Errorf("CPU profile not in pprof format: %v", err)
+	}
+
+	// Invalid requests are rejected
+	invalid := []struct {
+		kind    string
+		seconds uint64
+	}{
+		{"unknown", 0},
+		{"cpu", 0},
+		{"heap", 10},
+		{"trace", 3600},
+	}
+	for _, req := range invalid {
+		if _, err := api.CaptureProfile(context.Background(), req.kind, req.seconds); err == nil {
+			t.Errorf("%s profile over %ds: expected error", req.kind, req.seconds)
+		}
+	}
+}
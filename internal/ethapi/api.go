@@ -37,6 +37,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
@@ -50,6 +51,7 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/triedb"
 	"github.com/holiman/uint256"
 	"github.com/tyler-smith/go-bip39"
 )
@@ -157,6 +159,9 @@ func (s *EthereumAPI) Syncing() (interface{}, error) {
 		"healedBytecodeBytes":    hexutil.Uint64(progress.HealedBytecodeBytes),
 		"healingTrienodes":       hexutil.Uint64(progress.HealingTrienodes),
 		"healingBytecode":        hexutil.Uint64(progress.HealingBytecode),
+		"healingScheduled":       hexutil.Uint64(progress.HealingScheduled),
+		"healingRate":            hexutil.Uint64(progress.HealingRate),
+		"healingETA":             hexutil.Uint64(progress.HealingETA),
 		"txIndexFinishedBlocks":  hexutil.Uint64(progress.TxIndexFinishedBlocks),
 		"txIndexRemainingBlocks": hexutil.Uint64(progress.TxIndexRemainingBlocks),
 	}, nil
@@ -459,10 +464,21 @@ func (s *PersonalAccountAPI) signTransaction(ctx context.Context, args *Transact
 	if err := args.setDefaults(ctx, s.b, false); err != nil {
 		return nil, err
 	}
+	chainID, err := signingChainID(s.b.ChainConfig().GetChainID(), args)
+	if err != nil {
+		return nil, err
+	}
 	// Assemble the transaction and sign with the wallet
 	tx := args.toTransaction()
 
-	return wallet.SignTxWithPassphrase(account, passwd, tx, s.b.ChainConfig().GetChainID())
+	signed, err := wallet.SignTxWithPassphrase(account, passwd, tx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkReplayGuard(s.b.ChainConfig().GetChainID(), signed, args.AllowCrossChain); err != nil {
+		return nil, err
+	}
+	return signed, nil
 }
 
 // SendTransaction will create a transaction from the given arguments and
@@ -478,6 +494,9 @@ func (s *PersonalAccountAPI) SendTransaction(ctx context.Context, args Transacti
 	if args.IsEIP4844() {
 		return common.Hash{}, errBlobTxNotSupported
 	}
+	if args.AllowCrossChain {
+		return common.Hash{}, errCrossChainSend
+	}
 	signed, err := s.signTransaction(ctx, &args, passwd)
 	if err != nil {
 		log.Warn("Failed transaction send attempt", "from", args.from(), "to", args.To, "value", args.Value.ToInt(), "err", err)
@@ -622,12 +641,17 @@ func (s *PersonalAccountAPI) Unpair(ctx context.Context, url string, pin string)
 
 // BlockChainAPI provides an API to access Ethereum blockchain data.
 type BlockChainAPI struct {
-	b Backend
+	b     Backend
+	calls *callCache // Results of eth_call, nil if disabled
 }
 
 // NewBlockChainAPI creates a new Ethereum blockchain API.
 func NewBlockChainAPI(b Backend) *BlockChainAPI {
-	return &BlockChainAPI{b}
+	api := &BlockChainAPI{b: b}
+	if size := b.RPCCallCache(); size > 0 {
+		api.calls = newCallCache(size)
+	}
+	return api
 }
 
 // ChainId is the EIP-155 replay-protection chain id for the current Ethereum chain config.
@@ -919,15 +943,28 @@ func (s *BlockChainAPI) GetStorageAt(ctx context.Context, address common.Address
 
 // GetBlockReceipts returns the block receipts for the given block hash or number or tag.
 func (s *BlockChainAPI) GetBlockReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]map[string]interface{}, error) {
-	block, err := s.b.BlockByNumberOrHash(ctx, blockNrOrHash)
-	if block == nil || err != nil {
-		// When the block doesn't exist, the RPC method should return JSON null
-		// as per specification.
-		return nil, nil
-	}
-	receipts, err := s.b.GetReceipts(ctx, block.Hash())
-	if err != nil {
-		return nil, err
+	var (
+		block    *types.Block
+		receipts types.Receipts
+		err      error
+	)
+	if number, ok := blockNrOrHash.Number(); ok && number == rpc.PendingBlockNumber {
+		// The pending block isn't stored, its receipts are kept by the miner.
+		block, receipts = s.b.PendingBlockAndReceipts()
+		if block == nil {
+			return nil, nil
+		}
+	} else {
+		block, err = s.b.BlockByNumberOrHash(ctx, blockNrOrHash)
+		if block == nil || err != nil {
+			// When the block doesn't exist, the RPC method should return JSON null
+			// as per specification.
+			return nil, nil
+		}
+		receipts, err = s.b.GetReceipts(ctx, block.Hash())
+		if err != nil {
+			return nil, err
+		}
 	}
 	txs := block.Transactions()
 	if len(txs) != len(receipts) {
@@ -1150,15 +1187,43 @@ func (s *BlockChainAPI) Call(ctx context.Context, args TransactionArgs, blockNrO
 		latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
 		blockNrOrHash = &latest
 	}
+	// Serve the call from the cache if possible, executing it on the exact block
+	// the cached result is keyed by otherwise.
+	var (
+		header *types.Header
+		id     common.Hash
+	)
+	if s.calls != nil {
+		if header = s.callCacheHeader(ctx, *blockNrOrHash); header != nil {
+			var err error
+			if id, err = callCacheID(args, overrides, blockOverrides); err != nil {
+				header = nil
+			} else if result, err, ok := s.calls.get(header, id); ok {
+				return result, err
+			} else {
+				hash := rpc.BlockNumberOrHashWithHash(header.Hash(), false)
+				blockNrOrHash = &hash
+			}
+		}
+	}
 	result, err := DoCall(ctx, s.b, args, *blockNrOrHash, overrides, blockOverrides, s.b.RPCEVMTimeout(), s.b.RPCGasCap())
 	if err != nil {
 		return nil, err
 	}
+	var (
+		ret     hexutil.Bytes
+		callErr error
+	)
 	// If the result contains a revert reason, try to unpack and return it.
 	if len(result.Revert()) > 0 {
-		return nil, newRevertError(result.Revert())
+		callErr = newRevertError(result.Revert())
+	} else {
+		ret, callErr = result.Return(), result.Err
+	}
+	if header != nil {
+		s.calls.add(header, id, ret, callErr)
 	}
-	return result.Return(), result.Err
+	return ret, callErr
 }
 
 // DoEstimateGas returns the lowest possible gas limit that allows the transaction to run
@@ -1841,6 +1906,76 @@ func (s *TransactionAPI) GetTransactionReceipt(ctx context.Context, hash common.
 	return marshalReceipt(receipt, blockHash, blockNumber, signer, tx, int(index)), nil
 }
 
+// ReceiptProofResult is the Merkle inclusion proof of a transaction receipt in
+// the receipts trie of its block.
+type ReceiptProofResult struct {
+	BlockHash        common.Hash            `json:"blockHash"`
+	BlockNumber      hexutil.Uint64         `json:"blockNumber"`
+	TransactionIndex hexutil.Uint64         `json:"transactionIndex"`
+	ReceiptsRoot     common.Hash            `json:"receiptsRoot"`
+	Header           map[string]interface{} `json:"header"`
+	HeaderRLP        hexutil.Bytes          `json:"headerRlp"`
+	Key              hexutil.Bytes          `json:"key"`
+	Receipt          hexutil.Bytes          `json:"receipt"`
+	Proof            []string               `json:"proof"`
+}
+
+// GetTransactionReceiptProof returns the Merkle proof of the receipt of the given
+// transaction in the receipts trie of the including block, along with the block
+// header committing to the trie root. The proof can be verified against the
+// receiptsRoot of the header, keyed by the RLP encoded transaction index.
+func (s *TransactionAPI) GetTransactionReceiptProof(ctx context.Context, hash common.Hash) (*ReceiptProofResult, error) {
+	found, _, blockHash, blockNumber, index, err := s.b.GetTransaction(ctx, hash)
+	if err != nil {
+		return nil, NewTxIndexingError() // transaction is not fully indexed
+	}
+	if !found {
+		return nil, nil // transaction is not existent or reachable
+	}
+	header, err := s.b.HeaderByHash(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := s.b.GetReceipts(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(receipts)) <= index {
+		return nil, nil
+	}
+	// Rebuild the receipts trie of the block and ensure it matches the header
+	tr := trie.NewEmpty(triedb.NewDatabase(rawdb.NewMemoryDatabase(), nil))
+	if root := types.DeriveSha(receipts, tr); root != header.ReceiptHash {
+		return nil, fmt.Errorf("receipts root mismatch: have %x, want %x", root, header.ReceiptHash)
+	}
+	var (
+		key   = rlp.AppendUint64(nil, index)
+		proof proofList
+	)
+	if err := tr.Prove(key, &proof); err != nil {
+		return nil, err
+	}
+	value, err := tr.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	headerRLP, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		return nil, err
+	}
+	return &ReceiptProofResult{
+		BlockHash:        blockHash,
+		BlockNumber:      hexutil.Uint64(blockNumber),
+		TransactionIndex: hexutil.Uint64(index),
+		ReceiptsRoot:     header.ReceiptHash,
+		Header:           RPCMarshalHeader(header),
+		HeaderRLP:        headerRLP,
+		Key:              key,
+		Receipt:          value,
+		Proof:            proof,
+	}, nil
+}
+
 // marshalReceipt marshals a transaction receipt into a JSON object.
 func marshalReceipt(receipt *types.Receipt, blockHash common.Hash, blockNumber uint64, signer types.Signer, tx *types.Transaction, txIndex int) map[string]interface{} {
 	from, _ := types.Sender(signer, tx)
@@ -1884,7 +2019,7 @@ func marshalReceipt(receipt *types.Receipt, blockHash common.Hash, blockNumber u
 }
 
 // sign is a helper function that signs a transaction with the private key of the given address.
-func (s *TransactionAPI) sign(addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
+func (s *TransactionAPI) sign(addr common.Address, tx *types.Transaction, chainID *big.Int, allowCrossChain bool) (*types.Transaction, error) {
 	// Look up the wallet containing the requested signer
 	account := accounts.Account{Address: addr}
 
@@ -1893,7 +2028,14 @@ func (s *TransactionAPI) sign(addr common.Address, tx *types.Transaction) (*type
 		return nil, err
 	}
 	// Request the wallet to sign the transaction
-	return wallet.SignTx(account, tx, s.b.ChainConfig().GetChainID())
+	signed, err := wallet.SignTx(account, tx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkReplayGuard(s.b.ChainConfig().GetChainID(), signed, allowCrossChain); err != nil {
+		return nil, err
+	}
+	return signed, nil
 }
 
 // SubmitTransaction is a helper function that submits tx to txPool and logs a message.
@@ -1950,6 +2092,9 @@ func (s *TransactionAPI) SendTransaction(ctx context.Context, args TransactionAr
 	if args.IsEIP4844() {
 		return common.Hash{}, errBlobTxNotSupported
 	}
+	if args.AllowCrossChain {
+		return common.Hash{}, errCrossChainSend
+	}
 
 	// Set some sanity defaults and terminate on failure
 	if err := args.setDefaults(ctx, s.b, false); err != nil {
@@ -1962,6 +2107,9 @@ func (s *TransactionAPI) SendTransaction(ctx context.Context, args TransactionAr
 	if err != nil {
 		return common.Hash{}, err
 	}
+	if err := checkReplayGuard(s.b.ChainConfig().GetChainID(), signed, false); err != nil {
+		return common.Hash{}, err
+	}
 	return SubmitTransaction(ctx, s.b, signed)
 }
 
@@ -2049,7 +2197,11 @@ func (s *TransactionAPI) SignTransaction(ctx context.Context, args TransactionAr
 	if err := checkTxFee(tx.GasPrice(), tx.Gas(), s.b.RPCTxFeeCap()); err != nil {
 		return nil, err
 	}
-	signed, err := s.sign(args.from(), tx)
+	chainID, err := signingChainID(s.b.ChainConfig().GetChainID(), &args)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := s.sign(args.from(), tx, chainID, args.AllowCrossChain)
 	if err != nil {
 		return nil, err
 	}
@@ -2123,7 +2275,7 @@ func (s *TransactionAPI) Resend(ctx context.Context, sendArgs TransactionArgs, g
 			if gasLimit != nil && *gasLimit != 0 {
 				sendArgs.Gas = gasLimit
 			}
-			signedTx, err := s.sign(sendArgs.from(), sendArgs.toTransaction())
+			signedTx, err := s.sign(sendArgs.from(), sendArgs.toTransaction(), s.b.ChainConfig().GetChainID(), false)
 			if err != nil {
 				return common.Hash{}, err
 			}
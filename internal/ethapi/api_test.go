@@ -55,7 +55,9 @@ import (
 	"github.com/ethereum/go-ethereum/params/types/genesisT"
 	"github.com/ethereum/go-ethereum/params/types/goethereum"
 	"github.com/ethereum/go-ethereum/params/vars"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
 	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/exp/slices"
@@ -430,11 +432,14 @@ func newTestAccountManager(t *testing.T) (*accounts.Manager, accounts.Account) {
 }
 
 type testBackend struct {
-	db      ethdb.Database
-	chain   *core.BlockChain
-	pending *types.Block
-	accman  *accounts.Manager
-	acc     accounts.Account
+	db              ethdb.Database
+	chain           *core.BlockChain
+	pending         *types.Block
+	pendingReceipts types.Receipts
+	accman          *accounts.Manager
+	acc             accounts.Account
+	txFeed          *event.Feed
+	callCache       int
 }
 
 func newTestBackend(t *testing.T, n int, gspec *genesisT.Genesis, engine consensus.Engine, generator func(i int, b *core.BlockGen)) *testBackend {
@@ -460,7 +465,7 @@ func newTestBackend(t *testing.T, n int, gspec *genesisT.Genesis, engine consens
 		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
 	}
 
-	backend := &testBackend{db: db, chain: chain, accman: accman, acc: acc}
+	backend := &testBackend{db: db, chain: chain, accman: accman, acc: acc, txFeed: new(event.Feed)}
 	return backend
 }
 
@@ -481,6 +486,7 @@ func (b testBackend) ExtRPCEnabled() bool               { return false }
 func (b testBackend) RPCGasCap() uint64                 { return 10000000 }
 func (b testBackend) RPCEVMTimeout() time.Duration      { return time.Second }
 func (b testBackend) RPCTxFeeCap() float64              { return 0 }
+func (b testBackend) RPCCallCache() int                 { return b.callCache }
 func (b testBackend) UnprotectedAllowed() bool          { return false }
 func (b testBackend) SetHead(number uint64)             {}
 func (b testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
@@ -549,9 +555,19 @@ func (b testBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOr
 	if blockNr, ok := blockNrOrHash.Number(); ok {
 		return b.StateAndHeaderByNumber(ctx, blockNr)
 	}
-	panic("only implemented for number")
+	if blockHash, ok := blockNrOrHash.Hash(); ok {
+		header := b.chain.GetHeaderByHash(blockHash)
+		if header == nil {
+			return nil, nil, errors.New("header not found")
+		}
+		stateDb, err := b.chain.StateAt(header.Root)
+		return stateDb, header, err
+	}
+	panic("unknown type rpc.BlockNumberOrHash")
+}
+func (b testBackend) PendingBlockAndReceipts() (*types.Block, types.Receipts) {
+	return b.pending, b.pendingReceipts
 }
-func (b testBackend) PendingBlockAndReceipts() (*types.Block, types.Receipts) { panic("implement me") }
 func (b testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
 	header, err := b.HeaderByHash(ctx, hash)
 	if header == nil || err != nil {
@@ -591,7 +607,7 @@ func (b testBackend) SendTx(ctx context.Context, signedTx *types.Transaction) er
 }
 func (b testBackend) GetTransaction(ctx context.Context, txHash common.Hash) (bool, *types.Transaction, common.Hash, uint64, uint64, error) {
 	tx, blockHash, blockNumber, index := rawdb.ReadTransaction(b.db, txHash)
-	return true, tx, blockHash, blockNumber, index, nil
+	return tx != nil, tx, blockHash, blockNumber, index, nil
 }
 func (b testBackend) GetPoolTransactions() (types.Transactions, error)         { panic("implement me") }
 func (b testBackend) GetPoolTransaction(txHash common.Hash) *types.Transaction { panic("implement me") }
@@ -606,7 +622,7 @@ func (b testBackend) TxPoolContentFrom(addr common.Address) ([]*types.Transactio
 	panic("implement me")
 }
 func (b testBackend) SubscribeNewTxsEvent(events chan<- core.NewTxsEvent) event.Subscription {
-	panic("implement me")
+	return b.txFeed.Subscribe(events)
 }
 func (b testBackend) ChainConfig() ctypes.ChainConfigurator { return b.chain.Config() }
 func (b testBackend) Engine() consensus.Engine              { return b.chain.Engine() }
@@ -1953,6 +1969,51 @@ func TestRPCGetTransactionReceipt(t *testing.T) {
 	}
 }
 
+func TestRPCGetTransactionReceiptProof(t *testing.T) {
+	t.Parallel()
+
+	var (
+		backend, txHashes = setupReceiptBackend(t, 6)
+		api               = NewTransactionAPI(backend, new(AddrLocker))
+	)
+	for i, txHash := range txHashes {
+		result, err := api.GetTransactionReceiptProof(context.Background(), txHash)
+		if err != nil {
+			t.Fatalf("test %d: failed to retrieve receipt proof: %v", i, err)
+		}
+		receipts, err := backend.GetReceipts(context.Background(), result.BlockHash)
+		if err != nil {
+			t.Fatalf("test %d: failed to retrieve receipts: %v", i, err)
+		}
+		// Verify the proof against the root committed to by the header
+		var header types.Header
+		if err := rlp.DecodeBytes(result.HeaderRLP, &header); err != nil {
+			t.Fatalf("test %d: failed to decode header: %v", i, err)
+		}
+		if header.Hash() != result.BlockHash || header.ReceiptHash != result.ReceiptsRoot {
+			t.Fatalf("test %d: header mismatch", i)
+		}
+		proofDb := rawdb.NewMemoryDatabase()
+		for _, node := range result.Proof {
+			blob := hexutil.MustDecode(node)
+			proofDb.Put(crypto.Keccak256(blob), blob)
+		}
+		value, err := trie.VerifyProof(header.ReceiptHash, result.Key, proofDb)
+		if err != nil {
+			t.Fatalf("test %d: failed to verify proof: %v", i, err)
+		}
+		var want bytes.Buffer
+		types.Receipts(receipts).EncodeIndex(int(result.TransactionIndex), &want)
+		if !bytes.Equal(value, want.Bytes()) || !bytes.Equal(value, result.Receipt) {
+			t.Errorf("test %d: proven receipt mismatch", i)
+		}
+	}
+	// Unknown transactions should return no proof
+	if result, err := api.GetTransactionReceiptProof(context.Background(), common.Hash{}); err != nil || result != nil {
+		t.Errorf("unknown transaction: have %v, %v, want nil", result, err)
+	}
+}
+
 func TestRPCGetBlockReceipts(t *testing.T) {
 	t.Parallel()
 
@@ -2051,6 +2112,51 @@ func TestRPCGetBlockReceipts(t *testing.T) {
 	}
 }
 
+func TestRPCGetBlockReceiptsPending(t *testing.T) {
+	t.Parallel()
+
+	var (
+		genBlocks  = 6
+		backend, _ = setupReceiptBackend(t, genBlocks)
+		api        = NewBlockChainAPI(backend)
+		pending    = rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber)
+		ctx        = context.Background()
+	)
+	if result, err := api.GetBlockReceipts(ctx, pending); result != nil || err != nil {
+		t.Fatalf("receipts returned without pending block: %v, %v", result, err)
+	}
+	// Reuse the transactions and receipts of an imported block for the pending one,
+	// which isn't stored in the database.
+	block, err := backend.BlockByNumber(ctx, rpc.BlockNumber(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipts, err := backend.GetReceipts(ctx, block.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := types.CopyHeader(block.Header())
+	header.Number = big.NewInt(int64(genBlocks + 1))
+	backend.setPendingBlock(types.NewBlockWithHeader(header).WithBody(block.Transactions(), nil))
+	backend.pendingReceipts = receipts
+
+	result, err := api.GetBlockReceipts(ctx, pending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != len(block.Transactions()) {
+		t.Fatalf("wrong number of receipts: have %d, want %d", len(result), len(block.Transactions()))
+	}
+	for i, tx := range block.Transactions() {
+		if have := result[i]["transactionHash"]; have != tx.Hash() {
+			t.Errorf("receipt %d: wrong transaction hash: have %v, want %v", i, have, tx.Hash())
+		}
+		if have := result[i]["blockNumber"]; have != hexutil.Uint64(genBlocks+1) {
+			t.Errorf("receipt %d: wrong block number: have %v, want %d", i, have, genBlocks+1)
+		}
+	}
+}
+
 func testRPCResponseWithFile(t *testing.T, testid int, result interface{}, rpc string, file string) {
 	data, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
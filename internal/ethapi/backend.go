@@ -51,6 +51,7 @@ type Backend interface {
 	RPCGasCap() uint64            // global gas cap for eth_call over rpc: DoS protection
 	RPCEVMTimeout() time.Duration // global timeout for eth_call over rpc: DoS protection
 	RPCTxFeeCap() float64         // global tx fee cap for all transaction related APIs
+	RPCCallCache() int            // number of eth_call results cached, 0 disables the cache
 	UnprotectedAllowed() bool     // allows only for EIP155 transactions.
 
 	// Blockchain API
@@ -0,0 +1,97 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// callCache memoizes the results of eth_call by block height and call. Every
+// entry records the hash of the block it was computed on, entries of blocks
+// replaced by a reorg are dropped when looked up.
+type callCache struct {
+	entries *lru.Cache[callCacheKey, *callCacheEntry]
+}
+
+type callCacheKey struct {
+	number uint64
+	call   common.Hash // Hash of the call arguments and overrides
+}
+
+type callCacheEntry struct {
+	block  common.Hash
+	result hexutil.Bytes
+	err    error // Execution error, e.g. a revert
+}
+
+func newCallCache(size int) *callCache {
+	return &callCache{entries: lru.NewCache[callCacheKey, *callCacheEntry](size)}
+}
+
+// get returns the cached result of the call on the given block.
+func (c *callCache) get(header *types.Header, call common.Hash) (hexutil.Bytes, error, bool) {
+	key := callCacheKey{header.Number.Uint64(), call}
+	entry, ok := c.entries.Get(key)
+	if !ok {
+		return nil, nil, false
+	}
+	if entry.block != header.Hash() {
+		// The block the result was computed on was reorged out.
+		c.entries.Remove(key)
+		return nil, nil, false
+	}
+	return entry.result, entry.err, true
+}
+
+// add caches the result of the call on the given block.
+func (c *callCache) add(header *types.Header, call common.Hash, result hexutil.Bytes, err error) {
+	c.entries.Add(callCacheKey{header.Number.Uint64(), call}, &callCacheEntry{block: header.Hash(), result: result, err: err})
+}
+
+// callCacheID returns the hash identifying a call in the cache.
+func callCacheID(args TransactionArgs, overrides *StateOverride, blockOverrides *BlockOverrides) (common.Hash, error) {
+	blob, err := json.Marshal([]interface{}{args, overrides, blockOverrides})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(blob), nil
+}
+
+// callCacheHeader returns the header of the block a call would be executed on,
+// if its result may be cached: calls on the pending block are never cached, and
+// on chains with finality only the calls on finalized blocks are.
+func (s *BlockChainAPI) callCacheHeader(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) *types.Header {
+	if number, ok := blockNrOrHash.Number(); ok && number == rpc.PendingBlockNumber {
+		return nil
+	}
+	header, err := s.b.HeaderByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil || header == nil {
+		return nil
+	}
+	if final, err := s.b.HeaderByNumber(ctx, rpc.FinalizedBlockNumber); err == nil && final != nil && header.Number.Cmp(final.Number) > 0 {
+		return nil
+	}
+	return header
+}
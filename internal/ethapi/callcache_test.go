@@ -0,0 +1,114 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestCallCacheReorg(t *testing.T) {
+	t.Parallel()
+
+	var (
+		cache     = newCallCache(16)
+		id        = common.Hash{1}
+		canon     = &types.Header{Number: big.NewInt(5), Extra: []byte("canon")}
+		reorged   = &types.Header{Number: big.NewInt(5), Extra: []byte("side")}
+		errRevert = errors.New("reverted")
+	)
+	cache.add(canon, id, hexutil.Bytes{1}, nil)
+	if result, err, ok := cache.get(canon, id); !ok || err != nil || len(result) != 1 || result[0] != 1 {
+		t.Fatalf("wrong cached result: %x, %v, %v", result, err, ok)
+	}
+	if _, _, ok := cache.get(canon, common.Hash{2}); ok {
+		t.Fatal("result of another call returned")
+	}
+	// A different block at the same height invalidates the entry
+	if _, _, ok := cache.get(reorged, id); ok {
+		t.Fatal("result of reorged block returned")
+	}
+	if _, _, ok := cache.get(canon, id); ok {
+		t.Fatal("result of reorged block not evicted")
+	}
+	// Execution errors are cached along with the result
+	cache.add(reorged, id, nil, errRevert)
+	if _, err, ok := cache.get(reorged, id); !ok || err != errRevert {
+		t.Fatalf("wrong cached error: %v, %v", err, ok)
+	}
+}
+
+func TestCallCached(t *testing.T) {
+	t.Parallel()
+
+	var (
+		number   = common.HexToAddress("0x1000") // Returns the block number
+		reverter = common.HexToAddress("0x2000") // Always reverts
+		genesis  = &genesisT.Genesis{
+			Config: params.MergedTestChainConfig,
+			Alloc: genesisT.GenesisAlloc{
+				number:   {Code: common.FromHex("0x4360005260206000f3")},
+				reverter: {Code: common.FromHex("0x60006000fd")},
+			},
+		}
+	)
+	backend := newTestBackend(t, 4, genesis, beacon.New(ethash.NewFaker()), func(i int, b *core.BlockGen) {
+		b.SetPoS()
+	})
+	backend.callCache = 16
+	api := NewBlockChainAPI(backend)
+
+	at := func(n int64) *rpc.BlockNumberOrHash {
+		block := rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(n))
+		return &block
+	}
+	for i := 0; i < 2; i++ {
+		result, err := api.Call(context.Background(), TransactionArgs{To: &number}, at(2), nil, nil)
+		if err != nil {
+			t.Fatalf("call %d failed: %v", i, err)
+		}
+		if have := new(big.Int).SetBytes(result); have.Uint64() != 2 {
+			t.Fatalf("call %d: wrong block number %v", i, have)
+		}
+		if _, err := api.Call(context.Background(), TransactionArgs{To: &reverter}, at(2), nil, nil); !errors.Is(err, vm.ErrExecutionReverted) {
+			t.Fatalf("call %d: wrong error %v", i, err)
+		}
+	}
+	if have := api.calls.entries.Len(); have != 2 {
+		t.Fatalf("wrong number of cached calls: have %d, want 2", have)
+	}
+	// Calls on other blocks and with other arguments are cached separately
+	if result, err := api.Call(context.Background(), TransactionArgs{To: &number}, at(3), nil, nil); err != nil || new(big.Int).SetBytes(result).Uint64() != 3 {
+		t.Fatalf("wrong result on block 3: %x, %v", result, err)
+	}
+	if have := api.calls.entries.Len(); have != 3 {
+		t.Fatalf("wrong number of cached calls: have %d, want 3", have)
+	}
+}
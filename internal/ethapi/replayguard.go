@@ -0,0 +1,78 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	errCrossChainSignature = errors.New("signature would be valid on a sibling chain, set allowCrossChain to sign anyway")
+	errCrossChainSend      = errors.New("transactions for other chains can only be signed, not sent")
+)
+
+// siblingChains maps the chain ids of networks sharing their early history to
+// each other. Accounts active before the split have matching nonces on both
+// chains, so a signature valid on the sibling chain can be replayed there.
+var siblingChains = map[uint64]uint64{
+	1:  61, // Ethereum -> Ethereum Classic
+	61: 1,  // Ethereum Classic -> Ethereum
+}
+
+// signingChainID returns the chain id to sign a transaction for. It is the local
+// chain id, unless signing for another chain has been explicitly requested.
+func signingChainID(local *big.Int, args *TransactionArgs) (*big.Int, error) {
+	if args.ChainID == nil {
+		return local, nil
+	}
+	have := (*big.Int)(args.ChainID)
+	if have.Cmp(local) != 0 && !args.AllowCrossChain {
+		return nil, fmt.Errorf("chainId does not match node's (have=%v, want=%v)", have, local)
+	}
+	return have, nil
+}
+
+// checkReplayGuard ensures that a transaction signed by a wallet on the local
+// chain is not also valid on its sibling chain, i.e. that it is replay protected
+// and not signed for the sibling's chain id. This guards against misconfigured
+// external signers, e.g. clef running with the default chain id of Ethereum
+// behind a Classic node. The check is skipped if cross-chain signing has been
+// explicitly requested.
+//
+// Clef itself is not covered when used directly: it refuses requests for chain
+// ids other than its configured one and always signs replay protected for it,
+// so it relies on its --chainid being set to the right network.
+func checkReplayGuard(local *big.Int, tx *types.Transaction, allowCrossChain bool) error {
+	if allowCrossChain {
+		return nil
+	}
+	sibling, ok := siblingChains[local.Uint64()]
+	if !ok {
+		return nil
+	}
+	if !tx.Protected() {
+		return fmt.Errorf("%w: unprotected transaction valid on chain %d", errCrossChainSignature, sibling)
+	}
+	if tx.ChainId().Uint64() == sibling {
+		return fmt.Errorf("%w: signed for chain %d", errCrossChainSignature, sibling)
+	}
+	return nil
+}
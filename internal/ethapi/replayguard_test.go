@@ -0,0 +1,104 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestCheckReplayGuard(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	sign := func(signer types.Signer) *types.Transaction {
+		tx, err := types.SignTx(types.NewTransaction(0, common.Address{1}, big.NewInt(1), 21000, big.NewInt(1), nil), signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+	var (
+		eth         = big.NewInt(1)
+		etc         = big.NewInt(61)
+		mordor      = big.NewInt(63)
+		unprotected = sign(types.HomesteadSigner{})
+		forETH      = sign(types.NewEIP155Signer(eth))
+		forETC      = sign(types.NewEIP155Signer(etc))
+	)
+	tests := []struct {
+		local *big.Int
+		tx    *types.Transaction
+		allow bool
+		fail  bool
+	}{
+		{local: etc, tx: forETC},
+		{local: etc, tx: forETH, fail: true},
+		{local: etc, tx: forETH, allow: true},
+		{local: etc, tx: unprotected, fail: true},
+		{local: etc, tx: unprotected, allow: true},
+		{local: eth, tx: forETH},
+		{local: eth, tx: forETC, fail: true},
+		{local: eth, tx: unprotected, fail: true},
+		{local: mordor, tx: unprotected},
+		{local: mordor, tx: forETH},
+	}
+	for i, tt := range tests {
+		err := checkReplayGuard(tt.local, tt.tx, tt.allow)
+		if tt.fail && !errors.Is(err, errCrossChainSignature) {
+			t.Errorf("test %d: expected cross-chain error, got %v", i, err)
+		}
+		if !tt.fail && err != nil {
+			t.Errorf("test %d: unexpected error: %v", i, err)
+		}
+	}
+}
+
+func TestSigningChainID(t *testing.T) {
+	var (
+		local = big.NewInt(61)
+		other = (*hexutil.Big)(big.NewInt(1))
+	)
+	tests := []struct {
+		args TransactionArgs
+		want *big.Int
+		fail bool
+	}{
+		{args: TransactionArgs{}, want: local},
+		{args: TransactionArgs{ChainID: (*hexutil.Big)(local)}, want: local},
+		{args: TransactionArgs{ChainID: other}, fail: true},
+		{args: TransactionArgs{ChainID: other, AllowCrossChain: true}, want: big.NewInt(1)},
+	}
+	for i, tt := range tests {
+		have, err := signingChainID(local, &tt.args)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("test %d: expected error, got chain id %v", i, have)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: unexpected error: %v", i, err)
+		} else if have.Cmp(tt.want) != 0 {
+			t.Errorf("test %d: chain id mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
@@ -72,6 +72,10 @@ type TransactionArgs struct {
 	Commitments []kzg4844.Commitment `json:"commitments"`
 	Proofs      []kzg4844.Proof      `json:"proofs"`
 
+	// AllowCrossChain permits signing for a chain id other than the local one,
+	// including the sibling chain sharing the local chain's history.
+	AllowCrossChain bool `json:"allowCrossChain,omitempty"`
+
 	// This configures whether blobs are allowed to be passed.
 	blobSidecarAllowed bool
 }
@@ -169,11 +173,12 @@ func (args *TransactionArgs) setDefaults(ctx context.Context, b Backend, skipGas
 		}
 	}
 
-	// If chain id is provided, ensure it matches the local chain id. Otherwise, set the local
-	// chain id as the default.
+	// If chain id is provided, ensure it matches the local chain id unless signing
+	// for other chains was explicitly requested. Otherwise, set the local chain id
+	// as the default.
 	want := b.ChainConfig().GetChainID()
 	if args.ChainID != nil {
-		if have := (*big.Int)(args.ChainID); have.Cmp(want) != 0 {
+		if have := (*big.Int)(args.ChainID); have.Cmp(want) != 0 && !args.AllowCrossChain {
 			return fmt.Errorf("chainId does not match node's (have=%v, want=%v)", have, want)
 		}
 	} else {
@@ -0,0 +1,194 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maximumReplacementSearch is the maximum number of blocks walked, both along
+// the side chain and the canonical chain, when looking for the canonical
+// replacement of a transaction included in a non-canonical block.
+const maximumReplacementSearch = 1024
+
+// TransactionInclusion locates a transaction in a block.
+type TransactionInclusion struct {
+	TransactionHash  common.Hash    `json:"transactionHash"`
+	BlockHash        common.Hash    `json:"blockHash"`
+	BlockNumber      hexutil.Uint64 `json:"blockNumber"`
+	TransactionIndex hexutil.Uint64 `json:"transactionIndex"`
+}
+
+// BlockByTransactionResult is the block including a transaction, as returned by
+// eth_getBlockByTransaction.
+type BlockByTransactionResult struct {
+	Block            *RPCMarshalBlockT     `json:"block"`
+	TransactionIndex hexutil.Uint64        `json:"transactionIndex"`
+	Canonical        bool                  `json:"canonical"`
+	Replacement      *TransactionInclusion `json:"replacement"` // Canonical inclusion of the same sender and nonce, if the block is not canonical
+}
+
+// GetBlockByTransaction returns the block including the given transaction and
+// whether that block is canonical. Transactions are only indexed in canonical
+// blocks, so a transaction dropped by a reorg can only be looked up with the
+// hash of the block it was seen in. If that block is not canonical anymore, the
+// canonical transaction with the same sender and nonce is reported as its
+// replacement, which is the transaction itself if it was included again.
+func (s *BlockChainAPI) GetBlockByTransaction(ctx context.Context, hash common.Hash, blockHash *common.Hash) (*BlockByTransactionResult, error) {
+	var (
+		block *types.Block
+		index uint64
+	)
+	found, tx, canonHash, canonNumber, canonIndex, err := s.b.GetTransaction(ctx, hash)
+	if err != nil && blockHash == nil {
+		return nil, NewTxIndexingError()
+	}
+	if blockHash != nil {
+		if block, err = s.b.BlockByHash(ctx, *blockHash); err != nil {
+			return nil, err
+		}
+		if block == nil {
+			return nil, nil
+		}
+		if tx, index = findTransaction(block, hash); tx == nil {
+			return nil, fmt.Errorf("transaction %x not included in block %x", hash, *blockHash)
+		}
+	} else {
+		if !found {
+			return nil, nil
+		}
+		if block, err = s.b.BlockByHash(ctx, canonHash); err != nil || block == nil {
+			return nil, err
+		}
+		index = canonIndex
+	}
+	marshalled, err := s.rpcMarshalBlock(ctx, block, true, false)
+	if err != nil {
+		return nil, err
+	}
+	result := &BlockByTransactionResult{
+		Block:            marshalled,
+		TransactionIndex: hexutil.Uint64(index),
+	}
+	if result.Canonical, err = s.isCanonical(ctx, block.Header()); err != nil || result.Canonical {
+		return result, err
+	}
+	// The block was reorged out, look for the transaction taking its place
+	if found {
+		result.Replacement = &TransactionInclusion{
+			TransactionHash:  hash,
+			BlockHash:        canonHash,
+			BlockNumber:      hexutil.Uint64(canonNumber),
+			TransactionIndex: hexutil.Uint64(canonIndex),
+		}
+		return result, nil
+	}
+	result.Replacement, err = s.findReplacement(ctx, block, tx)
+	return result, err
+}
+
+// isCanonical reports whether the header is part of the canonical chain.
+func (s *BlockChainAPI) isCanonical(ctx context.Context, header *types.Header) (bool, error) {
+	canon, err := s.b.HeaderByNumber(ctx, rpc.BlockNumber(header.Number.Uint64()))
+	if err != nil {
+		return false, err
+	}
+	return canon != nil && canon.Hash() == header.Hash(), nil
+}
+
+// findReplacement searches the canonical chain for the transaction with the
+// same sender and nonce as the given one, included in the non-canonical block.
+func (s *BlockChainAPI) findReplacement(ctx context.Context, block *types.Block, tx *types.Transaction) (*TransactionInclusion, error) {
+	signer := types.MakeSigner(s.b.ChainConfig(), block.Number(), block.Time())
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return nil, err
+	}
+	// Nothing to search for if the nonce is still unused in the canonical chain
+	state, head, err := s.b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	if state.GetNonce(from) <= tx.Nonce() {
+		return nil, nil
+	}
+	// The nonce was unused at the fork point, so the replacement is after it
+	header := block.Header()
+	for i := 0; ; i++ {
+		if i == maximumReplacementSearch {
+			return nil, fmt.Errorf("fork point of block %x not found within %d blocks", block.Hash(), maximumReplacementSearch)
+		}
+		if header, err = s.b.HeaderByHash(ctx, header.ParentHash); err != nil {
+			return nil, err
+		}
+		if header == nil {
+			return nil, errors.New("missing side chain header")
+		}
+		canonical, err := s.isCanonical(ctx, header)
+		if err != nil {
+			return nil, err
+		}
+		if canonical {
+			break
+		}
+	}
+	for number := header.Number.Uint64() + 1; number <= head.Number.Uint64(); number++ {
+		if number-header.Number.Uint64() > maximumReplacementSearch {
+			return nil, fmt.Errorf("replacement of transaction %x not found within %d blocks", tx.Hash(), maximumReplacementSearch)
+		}
+		canon, err := s.b.BlockByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return nil, err
+		}
+		if canon == nil {
+			return nil, fmt.Errorf("missing canonical block #%d", number)
+		}
+		signer := types.MakeSigner(s.b.ChainConfig(), canon.Number(), canon.Time())
+		for i, candidate := range canon.Transactions() {
+			if candidate.Nonce() != tx.Nonce() {
+				continue
+			}
+			if sender, err := types.Sender(signer, candidate); err == nil && sender == from {
+				return &TransactionInclusion{
+					TransactionHash:  candidate.Hash(),
+					BlockHash:        canon.Hash(),
+					BlockNumber:      hexutil.Uint64(number),
+					TransactionIndex: hexutil.Uint64(i),
+				}, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// findTransaction returns the transaction with the given hash in the block, and
+// its index.
+func findTransaction(block *types.Block, hash common.Hash) (*types.Transaction, uint64) {
+	for i, tx := range block.Transactions() {
+		if tx.Hash() == hash {
+			return tx, uint64(i)
+		}
+	}
+	return nil, 0
+}
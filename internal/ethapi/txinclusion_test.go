@@ -0,0 +1,146 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+	"github.com/ethereum/go-ethereum/params/vars"
+)
+
+func TestGetBlockByTransaction(t *testing.T) {
+	t.Parallel()
+
+	var (
+		key1, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		key2, _ = crypto.HexToECDSA("49a7b37aa6f6645917e7b807e9d1c00d4fa71f18343b0d4122a4d2df64dd6fee")
+		key3, _ = crypto.HexToECDSA("0202020202020202020202020202020202020202020202020202002020202020")
+		genesis = &genesisT.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: genesisT.GenesisAlloc{
+				crypto.PubkeyToAddress(key1.PublicKey): {Balance: big.NewInt(vars.Ether)},
+				crypto.PubkeyToAddress(key2.PublicKey): {Balance: big.NewInt(vars.Ether)},
+				crypto.PubkeyToAddress(key3.PublicKey): {Balance: big.NewInt(vars.Ether)},
+			},
+		}
+		signer = types.HomesteadSigner{}
+		makeTx = func(key *ecdsa.PrivateKey, to common.Address) *types.Transaction {
+			tx, _ := types.SignTx(types.NewTransaction(0, to, big.NewInt(1), vars.TxGas, big.NewInt(vars.InitialBaseFee), nil), signer, key)
+			return tx
+		}
+		dropped  = makeTx(key1, common.Address{0xaa}) // replaced by another transaction
+		replaced = makeTx(key1, common.Address{0xbb})
+		moved    = makeTx(key2, common.Address{0xaa}) // included again in the fork
+		orphaned = makeTx(key3, common.Address{0xaa}) // not included in the fork
+	)
+	backend := newTestBackend(t, 4, genesis, ethash.NewFaker(), func(i int, b *core.BlockGen) {
+		switch i {
+		case 0:
+			b.AddTx(moved)
+		case 1:
+			b.AddTx(dropped)
+		case 2:
+			b.AddTx(orphaned)
+		}
+	})
+	var side []*types.Block
+	for i := 1; i <= 4; i++ {
+		side = append(side, backend.chain.GetBlockByNumber(uint64(i)))
+	}
+	// Reorg to a longer chain forking off the genesis
+	_, fork, _ := core.GenerateChainWithGenesis(genesis, ethash.NewFaker(), 6, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{0x01})
+		switch i {
+		case 1:
+			b.AddTx(moved)
+		case 2:
+			b.AddTx(replaced)
+		}
+	})
+	if n, err := backend.chain.InsertChain(fork); err != nil {
+		t.Fatalf("block %d: failed to insert fork: %v", n, err)
+	}
+	if head := backend.chain.CurrentBlock().Hash(); head != fork[len(fork)-1].Hash() {
+		t.Fatal("fork not canonical")
+	}
+	api := NewBlockChainAPI(backend)
+
+	var tests = []struct {
+		tx          common.Hash
+		block       *common.Hash
+		want        common.Hash // Block including the transaction, zero if not found
+		canonical   bool
+		replacement *TransactionInclusion
+	}{
+		// Canonical transactions are found without a block hint
+		{tx: replaced.Hash(), want: fork[2].Hash(), canonical: true},
+		{tx: moved.Hash(), want: fork[1].Hash(), canonical: true},
+		// Dropped transactions are only found with a block hint
+		{tx: dropped.Hash()},
+		{
+			tx: dropped.Hash(), block: ptr(side[1].Hash()), want: side[1].Hash(),
+			replacement: &TransactionInclusion{TransactionHash: replaced.Hash(), BlockHash: fork[2].Hash(), BlockNumber: 3},
+		},
+		{
+			tx: moved.Hash(), block: ptr(side[0].Hash()), want: side[0].Hash(),
+			replacement: &TransactionInclusion{TransactionHash: moved.Hash(), BlockHash: fork[1].Hash(), BlockNumber: 2},
+		},
+		{tx: orphaned.Hash(), block: ptr(side[2].Hash()), want: side[2].Hash()},
+	}
+	for i, tt := range tests {
+		result, err := api.GetBlockByTransaction(context.Background(), tt.tx, tt.block)
+		if err != nil {
+			t.Fatalf("test %d: lookup failed: %v", i, err)
+		}
+		if tt.want == (common.Hash{}) {
+			if result != nil {
+				t.Fatalf("test %d: unexpected block %x", i, result.Block.Hash)
+			}
+			continue
+		}
+		if result == nil {
+			t.Fatalf("test %d: block not found", i)
+		}
+		if *result.Block.Hash != tt.want || result.Canonical != tt.canonical {
+			t.Fatalf("test %d: wrong block: have %x (canonical %v), want %x (canonical %v)", i, *result.Block.Hash, result.Canonical, tt.want, tt.canonical)
+		}
+		switch {
+		case tt.replacement == nil && result.Replacement != nil:
+			t.Fatalf("test %d: unexpected replacement %x", i, result.Replacement.TransactionHash)
+		case tt.replacement != nil && (result.Replacement == nil || *result.Replacement != *tt.replacement):
+			t.Fatalf("test %d: wrong replacement: have %+v, want %+v", i, result.Replacement, tt.replacement)
+		}
+	}
+	// Hints of blocks not including the transaction are rejected
+	if _, err := api.GetBlockByTransaction(context.Background(), dropped.Hash(), ptr(side[0].Hash())); err == nil {
+		t.Fatal("wrong block hint accepted")
+	}
+}
+
+func ptr(hash common.Hash) *common.Hash {
+	return &hash
+}
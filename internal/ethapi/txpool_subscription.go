@@ -0,0 +1,131 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// txPoolSubscriptionBuffer is the maximum number of matching transactions
+// queued for delivery to a single subscriber. Transactions arriving while the
+// queue is full are dropped, so that slow subscribers can't exhaust the memory
+// of the node.
+const txPoolSubscriptionBuffer = 1024
+
+// TxPoolSubscriptionCriteria selects the transactions delivered by a pending
+// transaction subscription. Criteria left empty match all transactions.
+type TxPoolSubscriptionCriteria struct {
+	From        *common.Address `json:"from"`
+	To          *common.Address `json:"to"`
+	MinGasPrice *hexutil.Big    `json:"minGasPrice"` // Minimum effective gas price at the current base fee
+}
+
+// match reports whether the transaction satisfies the criteria.
+func (c *TxPoolSubscriptionCriteria) match(tx *types.Transaction, signer types.Signer, baseFee *big.Int) bool {
+	if c.To != nil && (tx.To() == nil || *tx.To() != *c.To) {
+		return false
+	}
+	if c.MinGasPrice != nil {
+		price := tx.GasFeeCap()
+		if baseFee != nil {
+			price = effectiveGasPrice(tx, baseFee)
+		}
+		if price.Cmp(c.MinGasPrice.ToInt()) < 0 {
+			return false
+		}
+	}
+	if c.From != nil {
+		from, err := types.Sender(signer, tx)
+		if err != nil || from != *c.From {
+			return false
+		}
+	}
+	return true
+}
+
+// PendingTransactions creates a subscription delivering the full transactions
+// matching the criteria as they enter the transaction pool. At most
+// txPoolSubscriptionBuffer transactions are queued for a subscriber, further
+// ones are dropped until it catches up.
+func (s *TxPoolAPI) PendingTransactions(ctx context.Context, criteria *TxPoolSubscriptionCriteria) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	if criteria == nil {
+		criteria = new(TxPoolSubscriptionCriteria)
+	}
+	var (
+		rpcSub = notifier.CreateSubscription()
+		queue  = make(chan *types.Transaction, txPoolSubscriptionBuffer)
+		config = s.b.ChainConfig()
+		signer = types.LatestSigner(config)
+		txs    = make(chan core.NewTxsEvent, 128)
+		txSub  = s.b.SubscribeNewTxsEvent(txs)
+	)
+	// Filter the new transactions into the queue, never blocking on the subscriber.
+	go func() {
+		defer close(queue)
+		defer txSub.Unsubscribe()
+
+		var dropped int
+		for {
+			select {
+			case ev := <-txs:
+				head := s.b.CurrentHeader()
+				for _, tx := range ev.Txs {
+					if !criteria.match(tx, signer, head.BaseFee) {
+						continue
+					}
+					select {
+					case queue <- tx:
+						if dropped > 0 {
+							log.Info("Txpool subscriber caught up", "id", rpcSub.ID, "dropped", dropped)
+							dropped = 0
+						}
+					default:
+						if dropped == 0 {
+							log.Warn("Txpool subscriber too slow, dropping transactions", "id", rpcSub.ID, "limit", txPoolSubscriptionBuffer)
+						}
+						dropped++
+					}
+				}
+			case <-txSub.Err():
+				return
+			case <-rpcSub.Err():
+				return
+			}
+		}
+	}()
+	// Deliver the queued transactions at the pace of the subscriber.
+	go func() {
+		for tx := range queue {
+			if err := notifier.Notify(rpcSub.ID, NewRPCPendingTransaction(tx, s.b.CurrentHeader(), config)); err != nil {
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestTxPoolPendingTransactionsSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		key1, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		key2, _ = crypto.HexToECDSA("49a7b37aa6f6645917e7b807e9d1c00d4fa71f18343b0d4122a4d2df64dd6fee")
+		from    = crypto.PubkeyToAddress(key1.PublicKey)
+		to      = common.Address{0xaa}
+		genesis = &genesisT.Genesis{
+			Config: params.MergedTestChainConfig,
+			Alloc:  genesisT.GenesisAlloc{},
+		}
+	)
+	backend := newTestBackend(t, 1, genesis, beacon.New(ethash.NewFaker()), func(i int, b *core.BlockGen) {
+		b.SetPoS()
+	})
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("txpool", NewTxPoolAPI(backend)); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	var (
+		signer  = types.LatestSigner(backend.ChainConfig())
+		baseFee = backend.CurrentHeader().BaseFee
+		makeTx  = func(key *ecdsa.PrivateKey, nonce uint64, to common.Address, tip int64) *types.Transaction {
+			return types.MustSignNewTx(key, signer, &types.DynamicFeeTx{
+				ChainID:   backend.ChainConfig().GetChainID(),
+				Nonce:     nonce,
+				To:        &to,
+				Gas:       21000,
+				GasTipCap: big.NewInt(tip),
+				GasFeeCap: new(big.Int).Add(baseFee, big.NewInt(tip)),
+			})
+		}
+		minPrice = new(big.Int).Add(baseFee, big.NewInt(10))
+	)
+	txs := make(chan *RPCTransaction, 16)
+	sub, err := client.Subscribe(context.Background(), "txpool", txs, "pendingTransactions", &TxPoolSubscriptionCriteria{
+		From:        &from,
+		To:          &to,
+		MinGasPrice: (*hexutil.Big)(minPrice),
+	})
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	var (
+		match   = makeTx(key1, 0, to, 10)
+		cheap   = makeTx(key1, 1, to, 9)
+		other   = makeTx(key1, 2, common.Address{0xbb}, 20)
+		foreign = makeTx(key2, 0, to, 20)
+	)
+	backend.txFeed.Send(core.NewTxsEvent{Txs: []*types.Transaction{cheap, other, foreign, match}})
+
+	select {
+	case tx := <-txs:
+		if tx.Hash != match.Hash() {
+			t.Fatalf("wrong transaction delivered: have %x, want %x", tx.Hash, match.Hash())
+		}
+	case err := <-sub.Err():
+		t.Fatalf("subscription failed: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("matching transaction not delivered")
+	}
+	select {
+	case tx := <-txs:
+		t.Fatalf("unexpected transaction delivered: %x", tx.Hash)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -3929,6 +3929,10 @@ var outputPostFormatter = function(post){
 };
 
 var inputAddressFormatter = function (address) {
+    // "@name" addresses are resolved by the node from its address book
+    if (utils.isString(address) && /^@./.test(address)) {
+        return address;
+    }
     var iban = new Iban(address);
     if (iban.isValid() && iban.isDirect()) {
         return '0x' + iban.address();
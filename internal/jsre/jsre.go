@@ -80,6 +80,8 @@ func New(assetPath string, output io.Writer) *JSRE {
 	go re.runEventLoop()
 	re.Set("loadScript", MakeCallback(re.vm, re.loadScript))
 	re.Set("inspect", re.prettyPrintJS)
+	re.Set("table", re.tableJS)
+	re.Set("sparkline", re.sparklineJS)
 	return re
 }
 
@@ -0,0 +1,346 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package jsre
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dop251/goja"
+)
+
+// maxCellWidth is the width beyond which table cells are truncated.
+const maxCellWidth = 66
+
+// sparkTicks are the bars used to draw sparklines, from lowest to highest.
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// resultShape is a well known kind of RPC result, printed as a table with the
+// given columns unless others are requested.
+type resultShape struct {
+	keys    []string // Fields identifying the shape, all must be present
+	columns []string // Columns shown for the shape
+}
+
+// resultShapes are checked in order, the first one matching is used.
+var resultShapes = []resultShape{
+	{ // Blocks
+		keys:    []string{"miner", "transactions", "stateRoot"},
+		columns: []string{"number", "hash", "miner", "gasUsed", "gasLimit", "timestamp", "transactions"},
+	},
+	{ // Transactions
+		keys:    []string{"from", "nonce", "input"},
+		columns: []string{"hash", "blockNumber", "from", "to", "value", "gas", "nonce"},
+	},
+	{ // Peers
+		keys:    []string{"enode", "network", "protocols"},
+		columns: []string{"id", "name", "network.remoteAddress", "network.inbound"},
+	},
+}
+
+// tableJS prints its first argument as a table. Arrays of objects or arrays
+// are printed one element per row, any other object one field per row. The
+// optional second argument is the list of columns to print, nested fields can
+// be selected using dotted paths. By default, the columns relevant for blocks,
+// transactions and peers are chosen for those, all fields for other rows.
+func (re *JSRE) tableJS(call goja.FunctionCall) goja.Value {
+	ctx := ppctx{vm: re.vm, w: re.output}
+	rows, ok := call.Argument(0).(*goja.Object)
+	if !ok {
+		panic(re.vm.NewTypeError("table: rows must be an object or array"))
+	}
+	var columns []string
+	if arg := call.Argument(1); !goja.IsUndefined(arg) && !goja.IsNull(arg) {
+		if err := re.vm.ExportTo(arg, &columns); err != nil {
+			panic(re.vm.NewTypeError("table: columns must be an array of field names"))
+		}
+	}
+	ctx.printTable(rows, columns)
+	return goja.Undefined()
+}
+
+// sparklineJS prints the numbers in its first argument as a sparkline. If a
+// field name is given as the second argument, the values are taken from that
+// field of the elements, e.g. sparkline(blocks, "gasUsed").
+func (re *JSRE) sparklineJS(call goja.FunctionCall) goja.Value {
+	ctx := ppctx{vm: re.vm, w: re.output}
+	list, ok := call.Argument(0).(*goja.Object)
+	if !ok {
+		panic(re.vm.NewTypeError("sparkline: values must be an array"))
+	}
+	field := ""
+	if arg := call.Argument(1); !goja.IsUndefined(arg) && !goja.IsNull(arg) {
+		field = arg.String()
+	}
+	var values []float64
+	for _, v := range ctx.elements(list) {
+		if field != "" {
+			v = ctx.lookup(v, field)
+		}
+		values = append(values, ctx.number(v))
+	}
+	fmt.Fprintln(re.output, sparkline(values))
+	return goja.Undefined()
+}
+
+// sparkline draws the values as a line of bars scaled between the lowest and
+// the highest value. Values which aren't numbers are left blank.
+func sparkline(values []float64) string {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+	}
+	var b strings.Builder
+	for _, v := range values {
+		switch {
+		case math.IsNaN(v) || math.IsInf(v, 0):
+			b.WriteRune(' ')
+		case hi == lo:
+			b.WriteRune(sparkTicks[len(sparkTicks)/2])
+		default:
+			b.WriteRune(sparkTicks[int((v-lo)/(hi-lo)*float64(len(sparkTicks)-1)+0.5)])
+		}
+	}
+	return b.String()
+}
+
+// printTable prints the rows as a table of the given columns, choosing them if
+// none are given.
+func (ctx ppctx) printTable(obj *goja.Object, columns []string) {
+	var (
+		cells  [][]string
+		header []string
+	)
+	if !ctx.isArray(obj) {
+		// Plain objects are printed one field per row.
+		header = []string{"key", "value"}
+		for _, k := range ctx.fields(obj) {
+			v := SafeGet(obj, k)
+			if _, callable := goja.AssertFunction(v); callable {
+				continue
+			}
+			cells = append(cells, []string{k, ctx.cell(v)})
+		}
+	} else {
+		rows := ctx.elements(obj)
+		if len(columns) == 0 {
+			columns = ctx.columns(rows)
+		}
+		if len(columns) == 0 {
+			// Rows of primitive values are printed as a single column.
+			columns = []string{"value"}
+		}
+		header = append([]string{"#"}, columns...)
+		for i, row := range rows {
+			line := []string{strconv.Itoa(i)}
+			for j, col := range columns {
+				switch {
+				case isObject(row):
+					line = append(line, ctx.cell(ctx.lookup(row, col)))
+				case j == 0:
+					line = append(line, ctx.cell(row))
+				default:
+					line = append(line, "")
+				}
+			}
+			cells = append(cells, line)
+		}
+	}
+	writeTable(ctx.w, header, cells)
+}
+
+// writeTable writes the cells aligned in columns below the header.
+func writeTable(w io.Writer, header []string, cells [][]string) {
+	widths := make([]int, len(header))
+	for i, h := range header {
+		widths[i] = utf8.RuneCountInString(h)
+	}
+	for _, row := range cells {
+		for i, c := range row {
+			if i < len(widths) && utf8.RuneCountInString(c) > widths[i] {
+				widths[i] = utf8.RuneCountInString(c)
+			}
+		}
+	}
+	line := func(row []string, color func(string, ...interface{}) string) {
+		var b strings.Builder
+		for i, c := range row {
+			if i >= len(widths) {
+				break
+			}
+			pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(c)+2)
+			if color != nil {
+				c = color("%s", c)
+			}
+			b.WriteString(c + pad)
+		}
+		fmt.Fprintln(w, strings.TrimRight(b.String(), " "))
+	}
+	line(header, SpecialColor)
+	for _, row := range cells {
+		line(row, nil)
+	}
+}
+
+// columns chooses the columns to print for the given rows: the ones of a well
+// known result shape if all rows have it, otherwise the fields of all rows in
+// order of appearance.
+func (ctx ppctx) columns(rows []goja.Value) []string {
+	var objs []*goja.Object
+	for _, row := range rows {
+		if obj, ok := row.(*goja.Object); ok {
+			objs = append(objs, obj)
+		}
+	}
+	if len(objs) == 0 {
+		return nil
+	}
+	for _, shape := range resultShapes {
+		if ctx.allHave(objs, shape.keys) {
+			return shape.columns
+		}
+	}
+	var (
+		columns []string
+		seen    = make(map[string]bool)
+	)
+	for _, obj := range objs {
+		if ctx.isArray(obj) {
+			for i := range ctx.elements(obj) {
+				if k := strconv.Itoa(i); !seen[k] {
+					seen[k] = true
+					columns = append(columns, k)
+				}
+			}
+			continue
+		}
+		iterOwnKeys(ctx.vm, obj, func(k string) {
+			if seen[k] || boringKeys[k] || strings.HasPrefix(k, "_") {
+				return
+			}
+			if _, callable := goja.AssertFunction(SafeGet(obj, k)); callable {
+				return
+			}
+			seen[k] = true
+			columns = append(columns, k)
+		})
+	}
+	return columns
+}
+
+func (ctx ppctx) allHave(objs []*goja.Object, keys []string) bool {
+	for _, obj := range objs {
+		for _, k := range keys {
+			if v := SafeGet(obj, k); v == nil || goja.IsUndefined(v) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// lookup returns the field at the dotted path within the value.
+func (ctx ppctx) lookup(v goja.Value, path string) goja.Value {
+	for _, k := range strings.Split(path, ".") {
+		obj, ok := v.(*goja.Object)
+		if !ok {
+			return goja.Undefined()
+		}
+		if v = SafeGet(obj, k); v == nil {
+			return goja.Undefined()
+		}
+	}
+	return v
+}
+
+// cell formats a value for display in a table cell.
+func (ctx ppctx) cell(v goja.Value) string {
+	var s string
+	switch {
+	case v == nil || goja.IsUndefined(v) || goja.IsNull(v):
+		return ""
+	case ctx.isArray(v):
+		s = fmt.Sprintf("[%d]", v.(*goja.Object).Get("length").ToInteger())
+	default:
+		if obj, ok := v.(*goja.Object); ok && obj.ClassName() == "Object" {
+			if s = toString(obj); s == "[object Object]" {
+				s = "{...}"
+			}
+		} else {
+			s = v.String()
+		}
+	}
+	if utf8.RuneCountInString(s) > maxCellWidth {
+		s = string([]rune(s)[:maxCellWidth-1]) + "…"
+	}
+	return s
+}
+
+// number converts a number, a numeric string or a BigNumber to a float.
+func (ctx ppctx) number(v goja.Value) float64 {
+	if v == nil || goja.IsUndefined(v) || goja.IsNull(v) {
+		return math.NaN()
+	}
+	s := v.String()
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return n
+	}
+	if n, err := strconv.ParseUint(strings.TrimPrefix(s, "0x"), 16, 64); err == nil && strings.HasPrefix(s, "0x") {
+		return float64(n)
+	}
+	return math.NaN()
+}
+
+func isObject(v goja.Value) bool {
+	_, ok := v.(*goja.Object)
+	return ok
+}
+
+func (ctx ppctx) isArray(v goja.Value) bool {
+	obj, ok := v.(*goja.Object)
+	if !ok {
+		return false
+	}
+	switch obj.ClassName() {
+	case "Array", "GoArray":
+		return true
+	}
+	return false
+}
+
+// elements returns the elements of an array.
+func (ctx ppctx) elements(obj *goja.Object) []goja.Value {
+	if !ctx.isArray(obj) {
+		return nil
+	}
+	n := obj.Get("length").ToInteger()
+	elems := make([]goja.Value, 0, n)
+	for i := int64(0); i < n; i++ {
+		el := obj.Get(strconv.FormatInt(i, 10))
+		if el == nil {
+			el = goja.Undefined()
+		}
+		elems = append(elems, el)
+	}
+	return elems
+}
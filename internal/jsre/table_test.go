@@ -0,0 +1,96 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package jsre
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestTable(t *testing.T) {
+	color.NoColor = true
+
+	tests := []struct {
+		script string
+		want   string
+	}{
+		{
+			`table([{a: 1, b: "x"}, {a: 22, c: [1, 2]}])`,
+			"#  a   b  c\n0  1   x\n1  22     [2]\n",
+		},
+		{
+			`table([{a: {b: 1}, c: 2}], ["a.b", "d"])`,
+			"#  a.b  d\n0  1\n",
+		},
+		{
+			`table([[1, 2], [3]])`,
+			"#  0  1\n0  1  2\n1  3\n",
+		},
+		{
+			`table(["x", "y"])`,
+			"#  value\n0  x\n1  y\n",
+		},
+		{
+			`table({b: 2, a: "one"})`,
+			"key  value\na    one\nb    2\n",
+		},
+		{
+			`table([{number: 1, hash: "0x01", miner: "0x02", stateRoot: "0x03", gasUsed: 5, gasLimit: 10, timestamp: 7, transactions: ["0x04"]}])`,
+			"#  number  hash  miner  gasUsed  gasLimit  timestamp  transactions\n0  1       0x01  0x02   5        10        7          [1]\n",
+		},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		re := New("", &out)
+		if _, err := re.Run(test.script); err != nil {
+			t.Errorf("%s: %v", test.script, err)
+		} else if out.String() != test.want {
+			t.Errorf("%s: wrong output\nhave:\n%s\nwant:\n%s", test.script, out.String(), test.want)
+		}
+		re.Stop(false)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   string
+	}{
+		{[]float64{1, 2, 3, 4, 5, 6, 7, 8}, "▁▂▃▄▅▆▇█"},
+		{[]float64{0, 10, math.NaN(), 5}, "▁█ ▅"},
+		{[]float64{3, 3}, "▅▅"},
+		{nil, ""},
+	}
+	for _, test := range tests {
+		if got := sparkline(test.values); got != test.want {
+			t.Errorf("sparkline(%v): have %q, want %q", test.values, got, test.want)
+		}
+	}
+
+	var out bytes.Buffer
+	re := New("", &out)
+	defer re.Stop(false)
+	if _, err := re.Run(`sparkline([{v: 1}, {v: "0x3"}, {v: 2}], "v")`); err != nil {
+		t.Fatal(err)
+	}
+	if want := "▁█▅\n"; out.String() != want {
+		t.Errorf("wrong output: have %q, want %q", out.String(), want)
+	}
+}
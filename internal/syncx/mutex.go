@@ -17,22 +17,38 @@
 // Package syncx contains exotic synchronization primitives.
 package syncx
 
+import "github.com/ethereum/go-ethereum/internal/watchdog"
+
 // ClosableMutex is a mutex that can also be closed.
 // Once closed, it can never be taken again.
 type ClosableMutex struct {
 	ch chan struct{}
+
+	name    string            // name of the lock for the stall watchdog, if watched
+	section *watchdog.Section // watched section of the current holder
 }
 
 func NewClosableMutex() *ClosableMutex {
 	ch := make(chan struct{}, 1)
 	ch <- struct{}{}
-	return &ClosableMutex{ch}
+	return &ClosableMutex{ch: ch}
+}
+
+// NewWatchedClosableMutex creates a ClosableMutex whose locked sections are
+// watched by the stall watchdog under the given name.
+func NewWatchedClosableMutex(name string) *ClosableMutex {
+	cm := NewClosableMutex()
+	cm.name = name
+	return cm
 }
 
 // TryLock attempts to lock cm.
 // If the mutex is closed, TryLock returns false.
 func (cm *ClosableMutex) TryLock() bool {
 	_, ok := <-cm.ch
+	if ok && cm.name != "" {
+		cm.section = watchdog.Lock(cm.name)
+	}
 	return ok
 }
 
@@ -43,10 +59,15 @@ func (cm *ClosableMutex) MustLock() {
 	if !ok {
 		panic("mutex closed")
 	}
+	if cm.name != "" {
+		cm.section = watchdog.Lock(cm.name)
+	}
 }
 
 // Unlock unlocks cm.
 func (cm *ClosableMutex) Unlock() {
+	cm.section.Done()
+	cm.section = nil
 	select {
 	case cm.ch <- struct{}{}:
 	default:
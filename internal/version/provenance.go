@@ -0,0 +1,159 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package version
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// These variables are set at build-time by the linker when a signed release
+// build is done by build/ci.go. Both are base64 encoded: the first is the JSON
+// encoded Provenance of the build, the second its signify signature.
+var provenance, provenanceSig string
+
+// ErrNoProvenance is returned when the executable carries no signed provenance.
+var ErrNoProvenance = errors.New("binary carries no signed build provenance")
+
+// Provenance describes how a release binary was built. It is signed at build
+// time, allowing operators to check the origin of the binary they run.
+type Provenance struct {
+	Builder   string `json:"builder"`   // Build environment, e.g. the CI job
+	Version   string `json:"version"`   // Release version
+	Commit    string `json:"commit"`    // Source commit hash
+	Date      string `json:"date"`      // Commit time in YYYYMMDD format
+	GoVersion string `json:"goVersion"` // Go toolchain version
+	Platform  string `json:"platform"`  // Target OS and architecture
+	Modules   string `json:"modules"`   // Checksum of the linked module dependencies
+}
+
+// NewProvenance creates the provenance of the executable described by the build
+// info. The commit and date may be left empty to use the VCS information recorded
+// by the go tool.
+func NewProvenance(info *debug.BuildInfo, builder, commit, date string) *Provenance {
+	p := &Provenance{
+		Builder:   builder,
+		Version:   params.VersionWithMeta,
+		Commit:    commit,
+		Date:      date,
+		GoVersion: info.GoVersion,
+		Modules:   modulesChecksum(info),
+	}
+	if p.Commit == "" {
+		vcs, _ := buildInfoVCS(info)
+		p.Commit, p.Date = vcs.Commit, vcs.Date
+	}
+	var goos, goarch string
+	for _, v := range info.Settings {
+		switch v.Key {
+		case "GOOS":
+			goos = v.Value
+		case "GOARCH":
+			goarch = v.Value
+		}
+	}
+	p.Platform = goos + "/" + goarch
+	return p
+}
+
+// CurrentProvenance returns the provenance of the current executable, as would
+// have been recorded when building it.
+func CurrentProvenance() (*Provenance, error) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil, errors.New("no build information in binary")
+	}
+	vcs, _ := VCS()
+	p := NewProvenance(info, "", vcs.Commit, vcs.Date)
+	p.Platform = runtime.GOOS + "/" + runtime.GOARCH
+	return p, nil
+}
+
+// EmbeddedProvenance returns the signed provenance embedded in the current
+// executable, along with its signature.
+func EmbeddedProvenance() (payload []byte, sig []byte, err error) {
+	if provenance == "" || provenanceSig == "" {
+		return nil, nil, ErrNoProvenance
+	}
+	if payload, err = base64.StdEncoding.DecodeString(provenance); err != nil {
+		return nil, nil, fmt.Errorf("invalid provenance encoding: %v", err)
+	}
+	if sig, err = base64.StdEncoding.DecodeString(provenanceSig); err != nil {
+		return nil, nil, fmt.Errorf("invalid provenance signature encoding: %v", err)
+	}
+	return payload, sig, nil
+}
+
+// Mismatches compares the provenance against the one of the actual executable,
+// returning a description of every field differing between the two.
+func (p *Provenance) Mismatches(actual *Provenance) []string {
+	var diffs []string
+	check := func(name, signed, actual string) {
+		if signed != actual {
+			diffs = append(diffs, fmt.Sprintf("%s: signed %q, binary has %q", name, signed, actual))
+		}
+	}
+	check("version", p.Version, actual.Version)
+	check("commit", p.Commit, actual.Commit)
+	check("go version", p.GoVersion, actual.GoVersion)
+	check("platform", p.Platform, actual.Platform)
+	check("modules", p.Modules, actual.Modules)
+	return diffs
+}
+
+// modulesChecksum hashes the path, version and checksum of all the modules
+// linked into the executable, following replacements.
+func modulesChecksum(info *debug.BuildInfo) string {
+	deps := append([]*debug.Module(nil), info.Deps...)
+	sort.Slice(deps, func(i, j int) bool { return deps[i].Path < deps[j].Path })
+
+	h := sha256.New()
+	for _, dep := range deps {
+		mod := dep
+		if dep.Replace != nil {
+			mod = dep.Replace
+		}
+		fmt.Fprintf(h, "%s %s %s %s\n", dep.Path, mod.Path, mod.Version, mod.Sum)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// EncodeProvenance returns the linker flags embedding the signed provenance into
+// an executable.
+func EncodeProvenance(p *Provenance, sign func([]byte) ([]byte, error)) ([]string, error) {
+	payload, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := sign(payload)
+	if err != nil {
+		return nil, err
+	}
+	return []string{
+		"-X", ourPath + "/internal/version.provenance=" + base64.StdEncoding.EncodeToString(payload),
+		"-X", ourPath + "/internal/version.provenanceSig=" + base64.StdEncoding.EncodeToString(sig),
+	}, nil
+}
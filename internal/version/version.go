@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"runtime"
 	"runtime/debug"
+	"sort"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/params"
@@ -53,6 +54,36 @@ func VCS() (VCSInfo, bool) {
 	return VCSInfo{}, false
 }
 
+// BuildSettings returns the Go toolchain version the current executable was
+// compiled with, along with the build tags and the remaining non-VCS build
+// settings (e.g. CGO_ENABLED, GOAMD64) recorded by the go tool.
+func BuildSettings() (goVersion string, tags []string, settings map[string]string) {
+	goVersion, settings = runtime.Version(), make(map[string]string)
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return goVersion, nil, settings
+	}
+	if buildInfo.GoVersion != "" {
+		goVersion = buildInfo.GoVersion
+	}
+	for _, v := range buildInfo.Settings {
+		switch {
+		case v.Key == "-tags":
+			for _, tag := range strings.Split(v.Value, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					tags = append(tags, tag)
+				}
+			}
+		case strings.HasPrefix(v.Key, "vcs"):
+			// Reported separately through VCS.
+		default:
+			settings[v.Key] = v.Value
+		}
+	}
+	sort.Strings(tags)
+	return goVersion, tags, settings
+}
+
 // ClientName creates a software name/version identifier according to common
 // conventions in the Ethereum p2p network.
 func ClientName(clientIdentifier string) string {
@@ -0,0 +1,153 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package watchdog detects stalls of critical sections, such as long held locks
+// and slow RPC calls, and dumps the stacks of all goroutines while the stall is
+// still in progress.
+package watchdog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// minDumpInterval is the minimum time between two stack dumps, limiting the
+// disk usage if many sections stall at once.
+const minDumpInterval = time.Minute
+
+// config is the configuration of the enabled watchdog.
+type config struct {
+	dir           string        // Directory to write the stack dumps to
+	lockThreshold time.Duration // Maximum hold time of watched locks
+	callThreshold time.Duration // Maximum duration of watched calls
+}
+
+var (
+	active   atomic.Pointer[config]
+	dumpMu   sync.Mutex
+	lastDump time.Time
+)
+
+// Enable starts watching critical sections, writing stack dumps to the given
+// directory whenever a lock is held or a call runs longer than its threshold.
+// A zero threshold disables watching the respective sections.
+func Enable(dir string, lockThreshold, callThreshold time.Duration) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	active.Store(&config{dir: dir, lockThreshold: lockThreshold, callThreshold: callThreshold})
+	log.Info("Enabled stall watchdog", "dir", dir, "lock", lockThreshold, "call", callThreshold)
+	return nil
+}
+
+// Disable stops watching critical sections entered after the call.
+func Disable() {
+	active.Store(nil)
+}
+
+// Section is a watched critical section. The nil section is valid and ignores
+// all calls, so the result of Lock and Call can always be used.
+type Section struct {
+	timer *time.Timer
+}
+
+// Lock starts watching a section guarded by the named lock.
+func Lock(name string) *Section {
+	if cfg := active.Load(); cfg != nil && cfg.lockThreshold > 0 {
+		return watch(cfg, "lock", name, cfg.lockThreshold)
+	}
+	return nil
+}
+
+// Call starts watching the named call.
+func Call(name string) *Section {
+	if cfg := active.Load(); cfg != nil && cfg.callThreshold > 0 {
+		return watch(cfg, "call", name, cfg.callThreshold)
+	}
+	return nil
+}
+
+// Done ends the watched section.
+func (s *Section) Done() {
+	if s != nil {
+		s.timer.Stop()
+	}
+}
+
+func watch(cfg *config, kind, name string, threshold time.Duration) *Section {
+	start := time.Now()
+	return &Section{
+		timer: time.AfterFunc(threshold, func() { stalled(cfg, kind, name, start) }),
+	}
+}
+
+// stalled reports a section running past its threshold and dumps the stacks of
+// all goroutines, unless a dump was written very recently.
+func stalled(cfg *config, kind, name string, start time.Time) {
+	now := time.Now()
+
+	dumpMu.Lock()
+	defer dumpMu.Unlock()
+
+	if now.Sub(lastDump) < minDumpInterval {
+		log.Warn("Stall detected", "kind", kind, "name", name, "elapsed", now.Sub(start), "dump", "skipped")
+		return
+	}
+	lastDump = now
+
+	incident := fmt.Sprintf("%s-%s", now.UTC().Format("20060102T150405Z"), kind)
+	file := filepath.Join(cfg.dir, "stall-"+incident+".txt")
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "incident: %s\n%s: %s\nstarted: %v\nelapsed: %v\n\n", incident, kind, name, start.UTC(), now.Sub(start))
+	pprof.Lookup("goroutine").WriteTo(buf, 2)
+
+	if err := os.WriteFile(file, buf.Bytes(), 0644); err != nil {
+		log.Error("Failed to write stall dump", "incident", incident, "err", err)
+		return
+	}
+	log.Warn("Stall detected", "kind", kind, "name", name, "elapsed", now.Sub(start), "incident", incident, "dump", file)
+}
+
+// RWMutex is a sync.RWMutex whose write-locked sections are watched. Read
+// locks are not watched, as they can be held concurrently.
+type RWMutex struct {
+	sync.RWMutex
+	Name string
+
+	section *Section
+}
+
+// Lock locks the mutex for writing and starts watching the section.
+func (m *RWMutex) Lock() {
+	m.RWMutex.Lock()
+	m.section = Lock(m.Name)
+}
+
+// Unlock ends the watched section and unlocks the mutex.
+func (m *RWMutex) Unlock() {
+	m.section.Done()
+	m.section = nil
+	m.RWMutex.Unlock()
+}
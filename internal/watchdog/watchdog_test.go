@@ -0,0 +1,60 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package watchdog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchdog(t *testing.T) {
+	dir := t.TempDir()
+	if err := Enable(dir, 50*time.Millisecond, 0); err != nil {
+		t.Fatalf("failed to enable watchdog: %v", err)
+	}
+	defer Disable()
+
+	// Disabled and short sections don't trigger a dump
+	if s := Call("test"); s != nil {
+		t.Fatal("call watched with zero threshold")
+	}
+	Lock("quick").Done()
+
+	// A lock held past the threshold dumps the stacks while held
+	var mu = RWMutex{Name: "test"}
+	mu.Lock()
+	time.Sleep(200 * time.Millisecond)
+	mu.Unlock()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "stall-*.txt"))
+	if len(files) != 1 {
+		t.Fatalf("wrong number of dumps: have %d, want 1", len(files))
+	}
+	dump, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("failed to read dump: %v", err)
+	}
+	if !strings.Contains(string(dump), "lock: test\n") {
+		t.Errorf("dump doesn't name the stalled lock:\n%s", dump)
+	}
+	if !strings.Contains(string(dump), "TestWatchdog") {
+		t.Errorf("dump doesn't contain the stack of the lock holder")
+	}
+}
@@ -32,6 +32,7 @@ var Modules = map[string]string{
 	"les":      LESJs,
 	"vflux":    VfluxJs,
 	"dev":      DevJs,
+	"sponsor":  SponsorJs,
 }
 
 const CliqueJs = `
@@ -143,6 +144,19 @@ web3._extend({
 			call: 'admin_removeTrustedPeer',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'getENR',
+			call: 'admin_getENR'
+		}),
+		new web3._extend.Method({
+			name: 'setENR',
+			call: 'admin_setENR',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'discoveryTable',
+			call: 'admin_discoveryTable'
+		}),
 		new web3._extend.Method({
 			name: 'exportChain',
 			call: 'admin_exportChain',
@@ -169,6 +183,42 @@ web3._extend({
 			call: 'admin_sleepBlocks',
 			params: 2
 		}),
+		new web3._extend.Method({
+			name: 'setTxGossip',
+			call: 'admin_setTxGossip',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'acceptReorg',
+			call: 'admin_acceptReorg',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'backup',
+			call: 'admin_backup',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'maintenance',
+			call: 'admin_maintenance',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'addressBook.add',
+			call: 'admin_addressBookAdd',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'addressBook.remove',
+			call: 'admin_addressBookRemove',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'addressBook.list',
+			call: 'admin_addressBookList',
+			params: 0
+		}),
 		new web3._extend.Method({
 			name: 'startHTTP',
 			call: 'admin_startHTTP',
@@ -215,6 +265,22 @@ web3._extend({
 			name: 'datadir',
 			getter: 'admin_datadir'
 		}),
+		new web3._extend.Property({
+			name: 'txGossip',
+			getter: 'admin_txGossip'
+		}),
+		new web3._extend.Property({
+			name: 'attackRisk',
+			getter: 'admin_attackRisk'
+		}),
+		new web3._extend.Property({
+			name: 'buildInfo',
+			getter: 'admin_buildInfo'
+		}),
+		new web3._extend.Property({
+			name: 'heldReorgs',
+			getter: 'admin_heldReorgs'
+		}),
 	]
 });
 `
@@ -313,6 +379,17 @@ web3._extend({
 			call: 'debug_setGCPercent',
 			params: 1,
 		}),
+		new web3._extend.Method({
+			name: 'captureProfile',
+			call: 'debug_captureProfile',
+			params: 2,
+		}),
+		new web3._extend.Method({
+			name: 'flightRecord',
+			call: 'debug_flightRecord',
+			params: 1,
+			inputFormatter: [null],
+		}),
 		new web3._extend.Method({
 			name: 'memStats',
 			call: 'debug_memStats',
@@ -424,12 +501,39 @@ web3._extend({
 			params: 2,
 			inputFormatter: [null, null]
 		}),
+		new web3._extend.Method({
+			name: 'listTraceFiles',
+			call: 'debug_listTraceFiles',
+			params: 0,
+		}),
+		new web3._extend.Method({
+			name: 'decodeCalldata',
+			call: 'debug_decodeCalldata',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'getHeaderProof',
+			call: 'debug_getHeaderProof',
+			params: 1,
+		}),
 		new web3._extend.Method({
 			name: 'traceBlockByNumber',
 			call: 'debug_traceBlockByNumber',
 			params: 2,
 			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, null]
 		}),
+		new web3._extend.Method({
+			name: 'traceBlockByNumberRange',
+			call: 'debug_traceBlockByNumberRange',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'traceBlockWithConfig',
+			call: 'debug_traceBlockWithConfig',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
 		new web3._extend.Method({
 			name: 'traceBlockByHash',
 			call: 'debug_traceBlockByHash',
@@ -487,6 +591,30 @@ web3._extend({
 			params: 2,
 			inputFormatter:[web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter],
 		}),
+		new web3._extend.Method({
+			name: 'chainStats',
+			call: 'debug_chainStats',
+			params: 2,
+			inputFormatter:[web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter],
+		}),
+		new web3._extend.Method({
+			name: 'unprotectedTransactions',
+			call: 'debug_unprotectedTransactions',
+			params: 3,
+			inputFormatter:[null, web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter],
+		}),
+		new web3._extend.Method({
+			name: 'gasTable',
+			call: 'debug_gasTable',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'gasTableDiff',
+			call: 'debug_gasTableDiff',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'dbGet',
 			call: 'debug_dbGet',
@@ -512,6 +640,11 @@ web3._extend({
 			call: 'debug_getTrieFlushInterval',
 			params: 0
 		}),
+		new web3._extend.Method({
+			name: 'blockProcessingStats',
+			call: 'debug_blockProcessingStats',
+			params: 1
+		}),
 	],
 	properties: []
 });
@@ -538,6 +671,28 @@ web3._extend({
 			params: 3,
 			inputFormatter: [web3._extend.formatters.inputTransactionFormatter, web3._extend.utils.fromDecimal, web3._extend.utils.fromDecimal]
 		}),
+		new web3._extend.Method({
+			name: 'sendPrivateTransaction',
+			call: 'eth_sendPrivateTransaction',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'hashrateEstimate',
+			call: 'eth_hashrateEstimate',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'recommendedConfirmations',
+			call: 'eth_recommendedConfirmations',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'getTransactionReceiptProof',
+			call: 'eth_getTransactionReceiptProof',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'signTransaction',
 			call: 'eth_signTransaction',
@@ -591,6 +746,12 @@ web3._extend({
 			call: 'eth_getRawTransactionByHash',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'getBlockByTransaction',
+			call: 'eth_getBlockByTransaction',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
 		new web3._extend.Method({
 			name: 'getRawTransactionFromBlock',
 			call: function(args) {
@@ -815,6 +976,12 @@ web3._extend({
 			params: 2,
 			inputFormatter: [null, null]
 		}),
+		new web3._extend.Method({
+			name: 'filterPaged',
+			call: 'trace_filterPaged',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
 		new web3._extend.Method({
 			name: 'call',
 			call: 'trace_call',
@@ -864,6 +1031,22 @@ web3._extend({
 			call: 'txpool_contentFrom',
 			params: 1,
 		}),
+		new web3._extend.Method({
+			name: 'droppedTransactions',
+			call: 'txpool_droppedTransactions',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'export',
+			call: 'txpool_export',
+			params: 0,
+		}),
+		new web3._extend.Method({
+			name: 'import',
+			call: 'txpool_import',
+			params: 1,
+		}),
 	]
 });
 `
@@ -971,3 +1154,22 @@ web3._extend({
 	],
 });
 `
+
+const SponsorJs = `
+web3._extend({
+	property: 'sponsor',
+	methods: [
+		new web3._extend.Method({
+			name: 'sendBundle',
+			call: 'sponsor_sendBundle',
+			params: 2
+		}),
+	],
+	properties: [
+		new web3._extend.Property({
+			name: 'pendingBundles',
+			getter: 'sponsor_pendingBundles'
+		}),
+	]
+});
+`
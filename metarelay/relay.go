@@ -0,0 +1,448 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package metarelay implements a relay service for EIP-712 signed meta-transactions,
+// paying the fees of its users by wrapping their requests into transactions to
+// an EIP-2771 trusted forwarder, signed by a relayer account of the node.
+package metarelay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+	"github.com/ethereum/go-ethereum/params/vars"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// relayGasOverhead is the gas allowance on top of the requested gas limit,
+	// covering the signature verification and bookkeeping of the forwarder.
+	relayGasOverhead = 100_000
+
+	// limitWindow is the time window the sender, relay and spend limits apply to.
+	limitWindow = time.Hour
+
+	// submissionLifetime is the time a submission is kept around for status
+	// queries.
+	submissionLifetime = 24 * time.Hour
+
+	// maxRequestSize is the maximum size of a relay request body.
+	maxRequestSize = 128 * 1024
+)
+
+var (
+	errRequestTooMuchGas = errors.New("requested gas exceeds relay limit")
+	errRequestValue      = errors.New("meta-transactions transferring value are not relayed")
+	errTargetNotAllowed  = errors.New("target not allowed by relay policy")
+	errSenderLimit       = errors.New("sender exceeded relay limit")
+	errRelayLimit        = errors.New("relay exceeded its request limit")
+	errSpendLimit        = errors.New("relay exceeded its spend limit")
+	errRejectedSignature = errors.New("meta-transaction rejected by forwarder")
+	errExecutionFailed   = errors.New("meta-transaction execution failed")
+	errKnownRequest      = errors.New("meta-transaction already relayed")
+	errUnknownRequest    = errors.New("unknown meta-transaction")
+)
+
+// Config are the configuration parameters of the meta-transaction relay.
+type Config struct {
+	Account       common.Address   // Relayer account paying for the transactions
+	Forwarder     common.Address   // Trusted forwarder contract executing the requests
+	DomainName    string           // EIP-712 domain name of the forwarder
+	DomainVersion string           // EIP-712 domain version of the forwarder
+	MaxGas        uint64           // Maximum gas limit of a single request
+	Targets       []common.Address // Contracts requests may call (empty = any)
+	SenderLimit   int              // Maximum number of requests per sender per hour (0 = unlimited)
+	RelayLimit    int              // Maximum number of requests of all senders per hour (0 = unlimited)
+	SpendLimit    *big.Int         // Maximum fees (wei) committed by the relayer per hour (nil = unlimited)
+}
+
+// DefaultConfig contains the default settings of the relay, matching the
+// OpenZeppelin MinimalForwarder domain.
+var DefaultConfig = Config{
+	DomainName:    "MinimalForwarder",
+	DomainVersion: "0.0.1",
+	MaxGas:        500_000,
+	SenderLimit:   10,
+	RelayLimit:    100,
+	SpendLimit:    big.NewInt(vars.Ether),
+}
+
+// Backend is the chain access needed by the relay.
+type Backend interface {
+	ChainConfig() ctypes.ChainConfigurator
+	CurrentHeader() *types.Header
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error)
+	SendTx(ctx context.Context, signedTx *types.Transaction) error
+	GetTransaction(ctx context.Context, txHash common.Hash) (bool, *types.Transaction, common.Hash, uint64, uint64, error)
+	GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error)
+
+	// Call executes a message on top of the latest state without committing
+	// it, returning the output of the call or an error if it reverted.
+	Call(ctx context.Context, from, to common.Address, gas uint64, data []byte) ([]byte, error)
+}
+
+// apiBackend adds the call simulation needed by the relay to the RPC backend.
+type apiBackend struct {
+	ethapi.Backend
+}
+
+func (b apiBackend) Call(ctx context.Context, from, to common.Address, gas uint64, data []byte) ([]byte, error) {
+	var (
+		limit = hexutil.Uint64(gas)
+		input = hexutil.Bytes(data)
+		args  = ethapi.TransactionArgs{From: &from, To: &to, Gas: &limit, Input: &input}
+	)
+	result, err := ethapi.DoCall(ctx, b, args, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber), nil, nil, b.RPCEVMTimeout(), b.RPCGasCap())
+	if err != nil {
+		return nil, err
+	}
+	if result.Failed() {
+		if reason, err := abi.UnpackRevert(result.Revert()); err == nil {
+			return nil, fmt.Errorf("%w: %s", result.Err, reason)
+		}
+		return nil, result.Err
+	}
+	return result.Return(), nil
+}
+
+// signFn signs a relayer transaction.
+type signFn func(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+
+// RelayRequest is the body of a relay submission.
+type RelayRequest struct {
+	Request   ForwardRequest `json:"request"`
+	Signature hexutil.Bytes  `json:"signature"`
+}
+
+// Status reports the state of a relayed meta-transaction.
+type Status struct {
+	ID          common.Hash     `json:"id"`     // EIP-712 hash of the request
+	TxHash      common.Hash     `json:"txHash"` // Hash of the relayer transaction
+	Status      string          `json:"status"` // One of pending, included or reverted
+	BlockNumber *hexutil.Uint64 `json:"blockNumber,omitempty"`
+}
+
+// submission is a meta-transaction relayed by the service.
+type submission struct {
+	sender common.Address
+	txHash common.Hash
+	cost   *big.Int // Maximum fees of the relayer transaction
+	time   time.Time
+}
+
+// Relay is the meta-transaction relay service.
+type Relay struct {
+	config  Config
+	backend Backend
+	sign    signFn
+	targets map[common.Address]bool
+
+	submissions map[common.Hash]*submission // Relayed requests by EIP-712 hash
+	lock        sync.Mutex                  // Serializes submissions for nonce assignment
+}
+
+// New creates a meta-transaction relay signing with the configured account of
+// the node's account manager (e.g. backed by clef), and registers its HTTP
+// endpoints on the node.
+func New(stack *node.Node, backend ethapi.Backend, config Config) (*Relay, error) {
+	am := stack.AccountManager()
+	sign := func(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+		account := accounts.Account{Address: config.Account}
+		wallet, err := am.Find(account)
+		if err != nil {
+			return nil, err
+		}
+		return wallet.SignTx(account, tx, chainID)
+	}
+	relay, err := newRelay(apiBackend{backend}, config, sign)
+	if err != nil {
+		return nil, err
+	}
+	nodecfg := stack.Config()
+	handler := node.NewHTTPHandlerStack(relay, nodecfg.HTTPCors, nodecfg.HTTPVirtualHosts, nil)
+	stack.RegisterHandler("Meta-transaction relay", "/metarelay", handler)
+	stack.RegisterHandler("Meta-transaction relay", "/metarelay/", handler)
+
+	log.Info("Started meta-transaction relay", "account", config.Account, "forwarder", config.Forwarder)
+	return relay, nil
+}
+
+func newRelay(backend Backend, config Config, sign signFn) (*Relay, error) {
+	if config.Account == (common.Address{}) {
+		return nil, errors.New("no relayer account configured")
+	}
+	if config.Forwarder == (common.Address{}) {
+		return nil, errors.New("no forwarder contract configured")
+	}
+	targets := make(map[common.Address]bool)
+	for _, target := range config.Targets {
+		targets[target] = true
+	}
+	return &Relay{
+		config:      config,
+		backend:     backend,
+		sign:        sign,
+		targets:     targets,
+		submissions: make(map[common.Hash]*submission),
+	}, nil
+}
+
+// ServeHTTP implements http.Handler, accepting meta-transactions POSTed to the
+// endpoint root, and reporting their status at status/<id>.
+func (r *Relay) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(req.URL.Path, "/metarelay"), "/")
+	switch {
+	case path == "" && req.Method == http.MethodPost:
+		var body RelayRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxRequestSize)).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		status, err := r.Submit(req.Context(), &body.Request, body.Signature)
+		if err != nil {
+			code := http.StatusBadRequest
+			if errors.Is(err, errRequestTooMuchGas) || errors.Is(err, errRequestValue) || errors.Is(err, errTargetNotAllowed) ||
+				errors.Is(err, errSenderLimit) || errors.Is(err, errRelayLimit) || errors.Is(err, errSpendLimit) {
+				code = http.StatusForbidden
+			}
+			writeError(w, code, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, status)
+
+	case strings.HasPrefix(path, "status/") && req.Method == http.MethodGet:
+		id, err := hexutil.Decode(strings.TrimPrefix(path, "status/"))
+		if err != nil || len(id) != common.HashLength {
+			writeError(w, http.StatusBadRequest, errors.New("invalid meta-transaction id"))
+			return
+		}
+		status, err := r.Status(req.Context(), common.BytesToHash(id))
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, status)
+
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unsupported request %s %s", req.Method, req.URL.Path))
+	}
+}
+
+// Submit validates a signed meta-transaction against the relay policy, and
+// sends a relayer transaction executing it through the forwarder. Requests are
+// only relayed if the forwarder accepts them and their execution succeeds on
+// top of the latest state, as otherwise the relayer pays for nothing.
+func (r *Relay) Submit(ctx context.Context, request *ForwardRequest, sig []byte) (*Status, error) {
+	chainID := r.backend.ChainConfig().GetChainID()
+	domain := forwarderDomain(r.config.DomainName, r.config.DomainVersion, chainID, r.config.Forwarder)
+
+	id, err := request.verify(domain, sig)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(request.Gas) > r.config.MaxGas {
+		return nil, errRequestTooMuchGas
+	}
+	// The forwarder would pass on value from the relayer's own balance
+	if request.value().Sign() != 0 {
+		return nil, errRequestValue
+	}
+	if len(r.targets) > 0 && !r.targets[request.To] {
+		return nil, errTargetNotAllowed
+	}
+	// The forwarder expects the legacy recovery id
+	sig = common.CopyBytes(sig)
+	if sig[crypto.RecoveryIDOffset] < 27 {
+		sig[crypto.RecoveryIDOffset] += 27
+	}
+	data, err := request.executeCall(sig)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.simulate(ctx, request, sig, data); err != nil {
+		return nil, err
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := time.Now()
+	r.prune(now)
+	if _, ok := r.submissions[id]; ok {
+		return nil, errKnownRequest
+	}
+	if r.config.SenderLimit > 0 && r.recent(&request.From, now) >= r.config.SenderLimit {
+		return nil, errSenderLimit
+	}
+	if r.config.RelayLimit > 0 && r.recent(nil, now) >= r.config.RelayLimit {
+		return nil, errRelayLimit
+	}
+	// Assemble the relayer transaction, paying for the execution of the request
+	nonce, err := r.backend.GetPoolNonce(ctx, r.config.Account)
+	if err != nil {
+		return nil, err
+	}
+	price, err := r.backend.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if head := r.backend.CurrentHeader(); head.BaseFee != nil {
+		price = new(big.Int).Add(price, new(big.Int).Mul(head.BaseFee, common.Big2))
+	}
+	gas := uint64(request.Gas) + relayGasOverhead
+	cost := new(big.Int).Mul(price, new(big.Int).SetUint64(gas))
+	if r.config.SpendLimit != nil && new(big.Int).Add(r.spent(now), cost).Cmp(r.config.SpendLimit) > 0 {
+		return nil, errSpendLimit
+	}
+	tx := types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		To:       &r.config.Forwarder,
+		Gas:      gas,
+		GasPrice: price,
+		Data:     data,
+	})
+	signed, err := r.sign(tx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.backend.SendTx(ctx, signed); err != nil {
+		return nil, err
+	}
+	r.submissions[id] = &submission{sender: request.From, txHash: signed.Hash(), cost: cost, time: now}
+	log.Debug("Relayed meta-transaction", "id", id, "sender", request.From, "to", request.To, "tx", signed.Hash())
+
+	return &Status{ID: id, TxHash: signed.Hash(), Status: "pending"}, nil
+}
+
+// Status reports the state of a previously relayed meta-transaction.
+func (r *Relay) Status(ctx context.Context, id common.Hash) (*Status, error) {
+	r.lock.Lock()
+	sub, ok := r.submissions[id]
+	r.lock.Unlock()
+
+	if !ok {
+		return nil, errUnknownRequest
+	}
+	status := &Status{ID: id, TxHash: sub.txHash, Status: "pending"}
+
+	found, _, blockHash, number, index, err := r.backend.GetTransaction(ctx, sub.txHash)
+	if err != nil || !found || blockHash == (common.Hash{}) {
+		return status, nil
+	}
+	receipts, err := r.backend.GetReceipts(ctx, blockHash)
+	if err != nil || uint64(len(receipts)) <= index {
+		return status, nil
+	}
+	status.BlockNumber = (*hexutil.Uint64)(&number)
+	if receipts[index].Status == types.ReceiptStatusSuccessful {
+		status.Status = "included"
+	} else {
+		status.Status = "reverted"
+	}
+	return status, nil
+}
+
+// simulate checks the request against the forwarder and executes it on top of
+// the latest state, rejecting it if the relayer transaction would be wasted.
+func (r *Relay) simulate(ctx context.Context, request *ForwardRequest, sig []byte, data []byte) error {
+	verify, err := request.verifyCall(sig)
+	if err != nil {
+		return err
+	}
+	output, err := r.backend.Call(ctx, r.config.Account, r.config.Forwarder, relayGasOverhead, verify)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errRejectedSignature, err)
+	}
+	res, err := parsedForwarderABI.Unpack("verify", output)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errRejectedSignature, err)
+	}
+	if ok, _ := res[0].(bool); !ok {
+		return errRejectedSignature
+	}
+	output, err = r.backend.Call(ctx, r.config.Account, r.config.Forwarder, uint64(request.Gas)+relayGasOverhead, data)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errExecutionFailed, err)
+	}
+	res, err = parsedForwarderABI.Unpack("execute", output)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errExecutionFailed, err)
+	}
+	if ok, _ := res[0].(bool); !ok {
+		return errExecutionFailed
+	}
+	return nil
+}
+
+// recent returns the number of requests relayed within the rate limiting
+// window, either for the given sender or for all senders if nil.
+func (r *Relay) recent(sender *common.Address, now time.Time) int {
+	var n int
+	for _, sub := range r.submissions {
+		if (sender == nil || sub.sender == *sender) && now.Sub(sub.time) < limitWindow {
+			n++
+		}
+	}
+	return n
+}
+
+// spent returns the maximum fees committed by the relayer within the rate
+// limiting window.
+func (r *Relay) spent(now time.Time) *big.Int {
+	total := new(big.Int)
+	for _, sub := range r.submissions {
+		if now.Sub(sub.time) < limitWindow {
+			total.Add(total, sub.cost)
+		}
+	}
+	return total
+}
+
+// prune drops the submissions which are too old to be tracked.
+func (r *Relay) prune(now time.Time) {
+	for id, sub := range r.submissions {
+		if now.Sub(sub.time) > submissionLifetime {
+			delete(r.submissions, id)
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,303 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package metarelay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+)
+
+type testBackend struct {
+	sent     []*types.Transaction
+	receipts map[common.Hash]*types.Receipt
+
+	rejectSig  bool  // Whether the simulated forwarder rejects signatures
+	failExec   bool  // Whether the simulated forwarded call fails
+	revertExec error // Error returned by the simulated forwarder execution
+}
+
+func (b *testBackend) ChainConfig() ctypes.ChainConfigurator { return params.TestChainConfig }
+func (b *testBackend) CurrentHeader() *types.Header {
+	return &types.Header{Number: big.NewInt(1), BaseFee: big.NewInt(1)}
+}
+func (b *testBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+func (b *testBackend) GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error) {
+	return uint64(len(b.sent)), nil
+}
+func (b *testBackend) SendTx(ctx context.Context, tx *types.Transaction) error {
+	b.sent = append(b.sent, tx)
+	return nil
+}
+func (b *testBackend) GetTransaction(ctx context.Context, hash common.Hash) (bool, *types.Transaction, common.Hash, uint64, uint64, error) {
+	if _, ok := b.receipts[hash]; !ok {
+		return false, nil, common.Hash{}, 0, 0, nil
+	}
+	return true, nil, hash, 7, 0, nil
+}
+func (b *testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	return types.Receipts{b.receipts[hash]}, nil
+}
+func (b *testBackend) Call(ctx context.Context, from, to common.Address, gas uint64, data []byte) ([]byte, error) {
+	method, err := parsedForwarderABI.MethodById(data)
+	if err != nil {
+		return nil, err
+	}
+	switch method.Name {
+	case "verify":
+		return method.Outputs.Pack(!b.rejectSig)
+	case "execute":
+		if b.revertExec != nil {
+			return nil, b.revertExec
+		}
+		return method.Outputs.Pack(!b.failExec, []byte{})
+	}
+	return nil, errors.New("unexpected call")
+}
+
+// signRequest signs the request for the relay's forwarder domain.
+func signRequest(t *testing.T, r *Relay, req *ForwardRequest) []byte {
+	key, _ := crypto.GenerateKey()
+	req.From = crypto.PubkeyToAddress(key.PublicKey)
+
+	domain := forwarderDomain(r.config.DomainName, r.config.DomainVersion, params.TestChainConfig.GetChainID(), r.config.Forwarder)
+	hash, err := req.hash(domain)
+	if err != nil {
+		t.Fatalf("failed to hash request: %v", err)
+	}
+	sig, err := crypto.Sign(hash[:], key)
+	if err != nil {
+		t.Fatalf("failed to sign request: %v", err)
+	}
+	return sig
+}
+
+func newTestRelay(t *testing.T, config Config) (*Relay, *testBackend) {
+	relayerKey, _ := crypto.GenerateKey()
+	config.Account = crypto.PubkeyToAddress(relayerKey.PublicKey)
+	config.Forwarder = common.HexToAddress("0xf0")
+
+	backend := &testBackend{receipts: make(map[common.Hash]*types.Receipt)}
+	sign := func(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+		return types.SignTx(tx, types.NewEIP155Signer(chainID), relayerKey)
+	}
+	relay, err := newRelay(backend, config, sign)
+	if err != nil {
+		t.Fatalf("failed to create relay: %v", err)
+	}
+	return relay, backend
+}
+
+// Tests that signed meta-transactions are wrapped into forwarder calls, and the
+// relay policy is enforced.
+func TestRelaySubmit(t *testing.T) {
+	config := DefaultConfig
+	config.Targets = []common.Address{common.HexToAddress("0xaa")}
+	config.SenderLimit = 1
+	relay, backend := newTestRelay(t, config)
+
+	req := &ForwardRequest{To: common.HexToAddress("0xaa"), Gas: 100_000, Data: []byte{0x01, 0x02}}
+	sig := signRequest(t, relay, req)
+
+	status, err := relay.Submit(context.Background(), req, sig)
+	if err != nil {
+		t.Fatalf("failed to relay request: %v", err)
+	}
+	if len(backend.sent) != 1 {
+		t.Fatalf("relayer transaction count mismatch: have %d, want 1", len(backend.sent))
+	}
+	tx := backend.sent[0]
+	if status.TxHash != tx.Hash() || status.Status != "pending" {
+		t.Fatalf("status mismatch: have %+v", status)
+	}
+	if *tx.To() != relay.config.Forwarder || tx.Gas() != 100_000+relayGasOverhead || tx.Value().Sign() != 0 {
+		t.Fatalf("relayer transaction mismatch: to %x, gas %d, value %v", tx.To(), tx.Gas(), tx.Value())
+	}
+	args, err := parsedForwarderABI.Methods["execute"].Inputs.Unpack(tx.Data()[4:])
+	if err != nil {
+		t.Fatalf("failed to unpack forwarder call: %v", err)
+	}
+	if have := args[1].([]byte); have[crypto.RecoveryIDOffset] < 27 || !bytes.Equal(have[:64], sig[:64]) {
+		t.Fatalf("forwarded signature mismatch: have %x", have)
+	}
+	// Replays and limits must be rejected
+	if _, err := relay.Submit(context.Background(), req, sig); !errors.Is(err, errKnownRequest) {
+		t.Fatalf("replay error mismatch: have %v, want %v", err, errKnownRequest)
+	}
+	next := *req
+	next.Nonce = (*hexutil.Big)(big.NewInt(1))
+	if _, err := relay.Submit(context.Background(), &next, sig); !errors.Is(err, errInvalidSignature) {
+		t.Fatalf("tampered request error mismatch: have %v, want %v", err, errInvalidSignature)
+	}
+	tests := []struct {
+		req  ForwardRequest
+		want error
+	}{
+		{ForwardRequest{To: common.HexToAddress("0xaa"), Gas: hexutil.Uint64(config.MaxGas + 1)}, errRequestTooMuchGas},
+		{ForwardRequest{To: common.HexToAddress("0xaa"), Gas: 21000, Value: (*hexutil.Big)(big.NewInt(1))}, errRequestValue},
+		{ForwardRequest{To: common.HexToAddress("0xbb"), Gas: 21000}, errTargetNotAllowed},
+	}
+	for i, tt := range tests {
+		sig := signRequest(t, relay, &tt.req)
+		if _, err := relay.Submit(context.Background(), &tt.req, sig); !errors.Is(err, tt.want) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.want)
+		}
+	}
+	next.Nonce = (*hexutil.Big)(big.NewInt(2))
+	sig = signRequest(t, relay, &next)
+	next.From = req.From
+	if _, err := relay.Submit(context.Background(), &next, sig); !errors.Is(err, errInvalidSignature) {
+		t.Fatalf("foreign signature error mismatch: have %v, want %v", err, errInvalidSignature)
+	}
+}
+
+// Tests that the per sender limit is enforced.
+func TestRelaySenderLimit(t *testing.T) {
+	config := DefaultConfig
+	config.SenderLimit = 1
+	relay, _ := newTestRelay(t, config)
+
+	key, _ := crypto.GenerateKey()
+	domain := forwarderDomain(config.DomainName, config.DomainVersion, params.TestChainConfig.GetChainID(), relay.config.Forwarder)
+	for i := 0; i < 2; i++ {
+		req := &ForwardRequest{From: crypto.PubkeyToAddress(key.PublicKey), Gas: 21000, Nonce: (*hexutil.Big)(big.NewInt(int64(i)))}
+		hash, _ := req.hash(domain)
+		sig, _ := crypto.Sign(hash[:], key)
+
+		_, err := relay.Submit(context.Background(), req, sig)
+		if i == 0 && err != nil {
+			t.Fatalf("failed to relay request: %v", err)
+		}
+		if i == 1 && !errors.Is(err, errSenderLimit) {
+			t.Fatalf("sender limit error mismatch: have %v, want %v", err, errSenderLimit)
+		}
+	}
+}
+
+// Tests that the global request and spend limits are enforced across senders.
+func TestRelayGlobalLimits(t *testing.T) {
+	// Each request costs (21000 + overhead) * 3 wei with the test fees
+	cost := int64((21000 + relayGasOverhead) * 3)
+	tests := []struct {
+		relayLimit int
+		spendLimit *big.Int
+		want       error
+	}{
+		{relayLimit: 2, want: errRelayLimit},
+		{spendLimit: big.NewInt(2*cost + cost/2), want: errSpendLimit},
+	}
+	for i, tt := range tests {
+		config := DefaultConfig
+		config.RelayLimit = tt.relayLimit
+		config.SpendLimit = tt.spendLimit
+		relay, backend := newTestRelay(t, config)
+
+		for j := 0; j < 3; j++ {
+			req := &ForwardRequest{To: common.HexToAddress("0xaa"), Gas: 21000}
+			sig := signRequest(t, relay, req)
+
+			_, err := relay.Submit(context.Background(), req, sig)
+			if j < 2 && err != nil {
+				t.Fatalf("test %d: failed to relay request %d: %v", i, j, err)
+			}
+			if j == 2 && !errors.Is(err, tt.want) {
+				t.Fatalf("test %d: limit error mismatch: have %v, want %v", i, err, tt.want)
+			}
+		}
+		if len(backend.sent) != 2 {
+			t.Fatalf("test %d: relayer transaction count mismatch: have %d, want 2", i, len(backend.sent))
+		}
+	}
+}
+
+// Tests that requests the forwarder would reject or fail to execute are not
+// relayed.
+func TestRelaySimulation(t *testing.T) {
+	tests := []struct {
+		setup func(b *testBackend)
+		want  error
+	}{
+		{func(b *testBackend) { b.rejectSig = true }, errRejectedSignature},
+		{func(b *testBackend) { b.failExec = true }, errExecutionFailed},
+		{func(b *testBackend) { b.revertExec = errors.New("execution reverted") }, errExecutionFailed},
+	}
+	for i, tt := range tests {
+		relay, backend := newTestRelay(t, DefaultConfig)
+		tt.setup(backend)
+
+		req := &ForwardRequest{To: common.HexToAddress("0xaa"), Gas: 21000}
+		sig := signRequest(t, relay, req)
+		if _, err := relay.Submit(context.Background(), req, sig); !errors.Is(err, tt.want) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.want)
+		}
+		if len(backend.sent) != 0 {
+			t.Errorf("test %d: relayed failing request", i)
+		}
+	}
+}
+
+// Tests the HTTP endpoints of the relay.
+func TestRelayHTTP(t *testing.T) {
+	relay, backend := newTestRelay(t, DefaultConfig)
+
+	req := ForwardRequest{To: common.HexToAddress("0xaa"), Gas: 21000}
+	sig := signRequest(t, relay, &req)
+	body, _ := json.Marshal(&RelayRequest{Request: req, Signature: sig})
+
+	rec := httptest.NewRecorder()
+	relay.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metarelay", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("submission failed: %d %s", rec.Code, rec.Body)
+	}
+	var status Status
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("failed to decode status: %v", err)
+	}
+	// Include the relayer transaction and check the reported status
+	backend.receipts[status.TxHash] = &types.Receipt{Status: types.ReceiptStatusSuccessful}
+
+	rec = httptest.NewRecorder()
+	relay.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metarelay/status/"+status.ID.Hex(), nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status query failed: %d %s", rec.Code, rec.Body)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("failed to decode status: %v", err)
+	}
+	if status.Status != "included" || status.BlockNumber == nil || *status.BlockNumber != 7 {
+		t.Fatalf("status mismatch: have %+v", status)
+	}
+	rec = httptest.NewRecorder()
+	relay.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metarelay/status/"+common.Hash{}.Hex(), nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown status code mismatch: have %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package metarelay
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// forwarderABI is the ABI of the verify and execute methods of an EIP-2771 trusted
+// forwarder, as implemented by the OpenZeppelin MinimalForwarder.
+const forwarderABI = `[{"name":"verify","type":"function","stateMutability":"view","inputs":[{"name":"req","type":"tuple","components":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"gas","type":"uint256"},{"name":"nonce","type":"uint256"},{"name":"data","type":"bytes"}]},{"name":"signature","type":"bytes"}],"outputs":[{"name":"","type":"bool"}]},{"name":"execute","type":"function","stateMutability":"payable","inputs":[{"name":"req","type":"tuple","components":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"gas","type":"uint256"},{"name":"nonce","type":"uint256"},{"name":"data","type":"bytes"}]},{"name":"signature","type":"bytes"}],"outputs":[{"name":"","type":"bool"},{"name":"","type":"bytes"}]}]`
+
+var parsedForwarderABI abi.ABI
+
+func init() {
+	var err error
+	if parsedForwarderABI, err = abi.JSON(strings.NewReader(forwarderABI)); err != nil {
+		panic(err)
+	}
+}
+
+var errInvalidSignature = errors.New("invalid meta-transaction signature")
+
+// ForwardRequest is a meta-transaction, the call a user wants to have executed
+// by the forwarder contract on their behalf.
+type ForwardRequest struct {
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Value *hexutil.Big   `json:"value"`
+	Gas   hexutil.Uint64 `json:"gas"`
+	Nonce *hexutil.Big   `json:"nonce"`
+	Data  hexutil.Bytes  `json:"data"`
+}
+
+// value returns the value transferred by the request, defaulting to zero.
+func (req *ForwardRequest) value() *big.Int {
+	if req.Value == nil {
+		return new(big.Int)
+	}
+	return req.Value.ToInt()
+}
+
+// nonce returns the forwarder nonce of the request, defaulting to zero.
+func (req *ForwardRequest) nonce() *big.Int {
+	if req.Nonce == nil {
+		return new(big.Int)
+	}
+	return req.Nonce.ToInt()
+}
+
+// typedData returns the EIP-712 representation of the request, as signed by
+// the user for the given forwarder domain.
+func (req *ForwardRequest) typedData(domain apitypes.TypedDataDomain) apitypes.TypedData {
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"ForwardRequest": {
+				{Name: "from", Type: "address"},
+				{Name: "to", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "gas", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "data", Type: "bytes"},
+			},
+		},
+		PrimaryType: "ForwardRequest",
+		Domain:      domain,
+		Message: apitypes.TypedDataMessage{
+			"from":  req.From.Hex(),
+			"to":    req.To.Hex(),
+			"value": req.value(),
+			"gas":   new(big.Int).SetUint64(uint64(req.Gas)),
+			"nonce": req.nonce(),
+			"data":  []byte(req.Data),
+		},
+	}
+}
+
+// forwarderDomain returns the EIP-712 domain of the forwarder contract.
+func forwarderDomain(name, version string, chainID *big.Int, forwarder common.Address) apitypes.TypedDataDomain {
+	return apitypes.TypedDataDomain{
+		Name:              name,
+		Version:           version,
+		ChainId:           (*math.HexOrDecimal256)(chainID),
+		VerifyingContract: forwarder.Hex(),
+	}
+}
+
+// hash returns the EIP-712 signing hash of the request.
+func (req *ForwardRequest) hash(domain apitypes.TypedDataDomain) (common.Hash, error) {
+	hash, _, err := apitypes.TypedDataAndHash(req.typedData(domain))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(hash), nil
+}
+
+// verify checks that the request was signed by its sender, returning the
+// EIP-712 hash of the request.
+func (req *ForwardRequest) verify(domain apitypes.TypedDataDomain, sig []byte) (common.Hash, error) {
+	hash, err := req.hash(domain)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(sig) != crypto.SignatureLength {
+		return common.Hash{}, errInvalidSignature
+	}
+	// Accept both the legacy (27/28) and the plain (0/1) recovery ids
+	sig = common.CopyBytes(sig)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pub, err := crypto.SigToPub(hash[:], sig)
+	if err != nil {
+		return common.Hash{}, errInvalidSignature
+	}
+	if crypto.PubkeyToAddress(*pub) != req.From {
+		return common.Hash{}, errInvalidSignature
+	}
+	return hash, nil
+}
+
+// verifyCall returns the calldata checking the request against the forwarder.
+func (req *ForwardRequest) verifyCall(sig []byte) ([]byte, error) {
+	return req.pack("verify", sig)
+}
+
+// executeCall returns the calldata invoking the forwarder with the request.
+func (req *ForwardRequest) executeCall(sig []byte) ([]byte, error) {
+	return req.pack("execute", sig)
+}
+
+// pack returns the calldata of a forwarder method taking the request and its
+// signature.
+func (req *ForwardRequest) pack(method string, sig []byte) ([]byte, error) {
+	type forwardRequest struct {
+		From  common.Address
+		To    common.Address
+		Value *big.Int
+		Gas   *big.Int
+		Nonce *big.Int
+		Data  []byte
+	}
+	return parsedForwarderABI.Pack(method, forwardRequest{
+		From:  req.From,
+		To:    req.To,
+		Value: req.value(),
+		Gas:   new(big.Int).SetUint64(uint64(req.Gas)),
+		Nonce: req.nonce(),
+		Data:  req.Data,
+	}, sig)
+}
@@ -33,6 +33,11 @@ type Config struct {
 	InfluxDBToken        string `toml:",omitempty"`
 	InfluxDBBucket       string `toml:",omitempty"`
 	InfluxDBOrganization string `toml:",omitempty"`
+
+	RemoteWriteEndpoint string `toml:",omitempty"`
+	RemoteWriteUsername string `toml:",omitempty"`
+	RemoteWritePassword string `toml:",omitempty"`
+	RemoteWriteLabels   string `toml:",omitempty"`
 }
 
 // DefaultConfig is the default config for metrics used in go-ethereum.
@@ -0,0 +1,218 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package prometheus
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/golang/snappy"
+)
+
+// remoteWriteTimeout is the timeout of a single push to the remote endpoint.
+const remoteWriteTimeout = 10 * time.Second
+
+// label is a single Prometheus label.
+type label struct {
+	name, value string
+}
+
+// series is a single Prometheus time series with one sample.
+type series struct {
+	labels []label
+	value  float64
+}
+
+// remoteWriter pushes the metrics of a registry to a Prometheus remote-write
+// endpoint, e.g. of a Prometheus server, Cortex, Mimir or VictoriaMetrics.
+type remoteWriter struct {
+	reg      metrics.Registry
+	endpoint string
+	username string
+	password string
+	labels   map[string]string
+	client   *http.Client
+}
+
+// RemoteWrite pushes the metrics of the registry to the given Prometheus
+// remote-write endpoint at the given interval, attaching the given labels to
+// all series. This allows exporting metrics from nodes which can't be scraped.
+// If a username is given, the requests use HTTP basic authentication.
+func RemoteWrite(r metrics.Registry, d time.Duration, endpoint, username, password string, labels map[string]string) {
+	w := &remoteWriter{
+		reg:      r,
+		endpoint: endpoint,
+		username: username,
+		password: password,
+		labels:   labels,
+		client:   &http.Client{Timeout: remoteWriteTimeout},
+	}
+	ticker := time.NewTicker(d)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := w.send(time.Now()); err != nil {
+			log.Warn("Unable to push metrics to Prometheus remote-write endpoint", "err", err)
+		}
+	}
+}
+
+// send pushes a snapshot of all metrics to the endpoint.
+func (w *remoteWriter) send(now time.Time) error {
+	body := snappy.Encode(nil, encodeWriteRequest(w.collect(), now.UnixMilli()))
+
+	req, err := http.NewRequest(http.MethodPost, w.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if w.username != "" {
+		req.SetBasicAuth(w.username, w.password)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("server returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// collect converts all metrics of the registry into Prometheus series, using
+// the same names and quantiles as the scrape endpoint.
+func (w *remoteWriter) collect() []series {
+	var names []string
+	w.reg.Each(func(name string, i interface{}) {
+		names = append(names, name)
+	})
+	sort.Strings(names)
+
+	var out []series
+	add := func(name string, value float64, extra ...label) {
+		labels := append([]label{{"__name__", sanitizeName(name)}}, extra...)
+		for k, v := range w.labels {
+			labels = append(labels, label{sanitizeName(k), v})
+		}
+		sort.Slice(labels, func(i, j int) bool { return labels[i].name < labels[j].name })
+		out = append(out, series{labels: labels, value: value})
+	}
+	addQuantiles := func(name string, count int64, quantiles []float64, values []float64) {
+		add(name+"_count", float64(count))
+		for i, q := range quantiles {
+			add(name, values[i], label{"quantile", strconv.FormatFloat(q, 'f', -1, 64)})
+		}
+	}
+	quantiles := []float64{0.5, 0.75, 0.95, 0.99, 0.999, 0.9999}
+
+	for _, name := range names {
+		switch m := w.reg.Get(name).(type) {
+		case metrics.Counter:
+			add(name, float64(m.Snapshot().Count()))
+		case metrics.CounterFloat64:
+			add(name, m.Snapshot().Count())
+		case metrics.Gauge:
+			add(name, float64(m.Snapshot().Value()))
+		case metrics.GaugeFloat64:
+			add(name, m.Snapshot().Value())
+		case metrics.GaugeInfo:
+			var extra []label
+			for k, v := range m.Snapshot().Value() {
+				extra = append(extra, label{sanitizeName(k), v})
+			}
+			add(name, 1, extra...)
+		case metrics.Histogram:
+			ms := m.Snapshot()
+			addQuantiles(name, ms.Count(), quantiles, ms.Percentiles(quantiles))
+		case metrics.Meter:
+			add(name, float64(m.Snapshot().Count()))
+		case metrics.Timer:
+			ms := m.Snapshot()
+			addQuantiles(name, ms.Count(), quantiles, ms.Percentiles(quantiles))
+		case metrics.ResettingTimer:
+			ms := m.Snapshot()
+			if ms.Count() > 0 {
+				short := []float64{0.50, 0.95, 0.99}
+				addQuantiles(name, int64(ms.Count()), short, ms.Percentiles(short))
+			}
+		}
+	}
+	return out
+}
+
+// sanitizeName converts a metric or label name into a valid Prometheus name,
+// replacing all unsupported characters with underscores.
+func sanitizeName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		valid := c == '_' || c == ':' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9')
+		if !valid {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+// encodeWriteRequest encodes the series as a remote-write WriteRequest protobuf
+// message, with all samples at the given timestamp in milliseconds:
+//
+//	message WriteRequest { repeated TimeSeries timeseries = 1; }
+//	message TimeSeries   { repeated Label labels = 1; repeated Sample samples = 2; }
+//	message Label        { string name = 1; string value = 2; }
+//	message Sample       { double value = 1; int64 timestamp = 2; }
+func encodeWriteRequest(all []series, timestamp int64) []byte {
+	var req []byte
+	for _, s := range all {
+		var ts []byte
+		for _, l := range s.labels {
+			var lb []byte
+			lb = appendProtoBytes(lb, 1, []byte(l.name))
+			lb = appendProtoBytes(lb, 2, []byte(l.value))
+			ts = appendProtoBytes(ts, 1, lb)
+		}
+		var sample []byte
+		sample = binary.AppendUvarint(sample, 1<<3|1) // field 1, 64-bit
+		sample = binary.LittleEndian.AppendUint64(sample, math.Float64bits(s.value))
+		sample = binary.AppendUvarint(sample, 2<<3|0) // field 2, varint
+		sample = binary.AppendUvarint(sample, uint64(timestamp))
+		ts = appendProtoBytes(ts, 2, sample)
+
+		req = appendProtoBytes(req, 1, ts)
+	}
+	return req
+}
+
+// appendProtoBytes appends a length-delimited protobuf field.
+func appendProtoBytes(b []byte, field int, data []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(data)))
+	return append(b, data...)
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package prometheus
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/golang/snappy"
+)
+
+func TestEncodeWriteRequest(t *testing.T) {
+	all := []series{{labels: []label{{"__name__", "a"}}, value: 1}}
+	have := encodeWriteRequest(all, 2)
+	want := []byte{
+		0x0a, 0x1c, // timeseries, 28 bytes
+		0x0a, 0x0d, // labels, 13 bytes
+		0x0a, 0x08, '_', '_', 'n', 'a', 'm', 'e', '_', '_',
+		0x12, 0x01, 'a',
+		0x12, 0x0b, // samples, 11 bytes
+		0x09, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f, // value 1.0
+		0x10, 0x02, // timestamp 2
+	}
+	if !bytes.Equal(have, want) {
+		t.Fatalf("wrong encoding:\nhave %x\nwant %x", have, want)
+	}
+}
+
+func TestRemoteWrite(t *testing.T) {
+	reg := metrics.NewRegistry()
+	metrics.NewRegisteredGaugeInfo("test/info", reg).Update(metrics.GaugeInfoValue{"version": "1.0"})
+	gauge := metrics.NewRegisteredGauge("test/gauge", reg)
+	gauge.Update(42)
+
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "snappy" || r.Header.Get("X-Prometheus-Remote-Write-Version") == "" {
+			http.Error(w, "missing headers", http.StatusBadRequest)
+			return
+		}
+		if user, pass, _ := r.BasicAuth(); user != "user" || pass != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		compressed, _ := io.ReadAll(r.Body)
+		body, _ = snappy.Decode(nil, compressed)
+	}))
+	defer srv.Close()
+
+	w := &remoteWriter{
+		reg:      reg,
+		endpoint: srv.URL,
+		username: "user",
+		password: "secret",
+		labels:   map[string]string{"host": "node-1"},
+		client:   srv.Client(),
+	}
+	if err := w.send(time.Now()); err != nil {
+		t.Fatalf("failed to push metrics: %v", err)
+	}
+	for _, want := range []string{"test_gauge", "test_info", "version", "host", "node-1"} {
+		if !bytes.Contains(body, []byte(want)) {
+			t.Errorf("pushed metrics don't contain %q", want)
+		}
+	}
+	w.password = "wrong"
+	if err := w.send(time.Now()); err == nil {
+		t.Error("expected error for rejected push")
+	}
+}
+
+func TestSanitizeName(t *testing.T) {
+	tests := map[string]string{
+		"chain/head/block": "chain_head_block",
+		"p2p.peers-in":     "p2p_peers_in",
+		"1st:metric":       "_st:metric",
+	}
+	for in, want := range tests {
+		if have := sanitizeName(in); have != want {
+			t.Errorf("sanitizeName(%q) = %q, want %q", in, have, want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"math/big"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// fillCandidates fills the given alternative sealing environments concurrently,
+// each with a different transaction selection strategy. The strategy of the
+// i-th alternative is i+1, strategy 0 being the default of fillTransactions.
+func (w *worker) fillCandidates(interrupt *atomic.Int32, envs []*environment) *sync.WaitGroup {
+	var wg sync.WaitGroup
+	for i, env := range envs {
+		wg.Add(1)
+		go func(strategy int, env *environment) {
+			defer wg.Done()
+			if err := w.fillCandidate(interrupt, env, strategy); err != nil {
+				log.Trace("Candidate block filling interrupted", "strategy", strategy, "err", err)
+			}
+		}(i+1, env)
+	}
+	return &wg
+}
+
+// fillCandidate fills an alternative sealing environment with the pending plain
+// transactions. Strategy 1 orders all transactions purely by fee, without giving
+// local ones priority. Strategy n > 1 additionally skips any transaction using
+// more than 1/n of the block gas limit (and the ones depending on it), trading
+// a few large transactions for a denser packing of small ones.
+func (w *worker) fillCandidate(interrupt *atomic.Int32, env *environment, strategy int) error {
+	w.commitSponsoredBundles(env)
+
+	filter := w.pendingFilter(env)
+	filter.OnlyPlainTxs, filter.OnlyBlobTxs = true, false
+	pending := w.eth.TxPool().Pending(filter)
+
+	if strategy > 1 {
+		maxGas := env.header.GasLimit / uint64(strategy)
+		for addr, txs := range pending {
+			for i, ltx := range txs {
+				if ltx.Gas > maxGas {
+					pending[addr] = txs[:i]
+					break
+				}
+			}
+			if len(pending[addr]) == 0 {
+				delete(pending, addr)
+			}
+		}
+	}
+	plainTxs := newTransactionsByPriceAndNonce(env.signer, pending, env.header.BaseFee)
+	blobTxs := newTransactionsByPriceAndNonce(env.signer, nil, env.header.BaseFee)
+	return w.commitTransactions(env, plainTxs, blobTxs, interrupt)
+}
+
+// envFees returns the total miner fees collected by the transactions in the
+// sealing environment.
+func envFees(env *environment) *big.Int {
+	fees := new(big.Int)
+	for i, tx := range env.txs {
+		tip, _ := tx.EffectiveGasTip(env.header.BaseFee)
+		fees.Add(fees, new(big.Int).Mul(new(big.Int).SetUint64(env.receipts[i].GasUsed), tip))
+	}
+	return fees
+}
+
+// bestCandidate selects the sealing environment yielding the highest fees out
+// of the default one and the alternatives, discarding all the others.
+func bestCandidate(work *environment, alternatives []*environment) *environment {
+	var (
+		best     = work
+		bestFees = envFees(work)
+		bestIdx  = 0
+	)
+	for i, env := range alternatives {
+		if fees := envFees(env); fees.Cmp(bestFees) > 0 {
+			best, bestFees, bestIdx = env, fees, i+1
+		}
+	}
+	for _, env := range append([]*environment{work}, alternatives...) {
+		if env != best {
+			env.discard()
+		}
+	}
+	log.Debug("Selected candidate block", "number", best.header.Number, "strategy", bestIdx, "candidates", len(alternatives)+1,
+		"txs", len(best.txs), "fees", bestFees, "gas", best.header.GasUsed)
+	return best
+}
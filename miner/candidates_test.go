@@ -0,0 +1,55 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params/vars"
+)
+
+// newFeeEnv creates a sealing environment containing a single plain transfer
+// paying the given gas price.
+func newFeeEnv(price int64) *environment {
+	tx := types.NewTransaction(0, testUserAddress, big.NewInt(0), vars.TxGas, big.NewInt(price), nil)
+	return &environment{
+		header:   &types.Header{Number: big.NewInt(1)},
+		txs:      []*types.Transaction{tx},
+		receipts: []*types.Receipt{{GasUsed: vars.TxGas}},
+	}
+}
+
+// Tests that the candidate block paying the most fees is selected.
+func TestBestCandidate(t *testing.T) {
+	var (
+		work = newFeeEnv(1)
+		alts = []*environment{newFeeEnv(3), newFeeEnv(2)}
+	)
+	if best := bestCandidate(work, alts); best != alts[0] {
+		t.Fatalf("wrong candidate selected: fees %v", envFees(best))
+	}
+	if want := new(big.Int).SetUint64(3 * vars.TxGas); envFees(alts[0]).Cmp(want) != 0 {
+		t.Fatalf("fee mismatch: have %v, want %v", envFees(alts[0]), want)
+	}
+	// Ties keep the default candidate
+	work, alts = newFeeEnv(2), []*environment{newFeeEnv(2)}
+	if best := bestCandidate(work, alts); best != work {
+		t.Fatalf("default candidate not preferred on tie")
+	}
+}
@@ -55,9 +55,14 @@ type Config struct {
 	GasCeil    uint64         // Target gas ceiling for mined blocks.
 	GasPrice   *big.Int       // Minimum gas price for mining a transaction
 	Recommit   time.Duration  // The time interval for miner to re-create mining work.
+	Candidates int            `toml:",omitempty"` // Number of candidate blocks with different transaction selections built per work cycle
 	Noverify   bool           // Disable remote mining solution verification(only useful in ethash).
 
 	NewPayloadTimeout time.Duration // The maximum time allowance for creating a new payload
+
+	SpeculativeTxs int `toml:",omitempty"` // Number of top pending transactions to keep pre-executed on the head state (0 = disabled)
+
+	Sponsored bool `toml:",omitempty"` // Accept sponsored transaction bundles through the experimental sponsor API
 }
 
 // DefaultConfig contains default settings for miner.
@@ -233,6 +238,17 @@ func (miner *Miner) PendingBlockAndReceipts() (*types.Block, types.Receipts) {
 	return miner.worker.pendingBlockAndReceipts()
 }
 
+// AddSponsoredBundle validates a sponsor and a user transaction against the
+// pending state and queues them for adjacent inclusion into locally built blocks.
+func (miner *Miner) AddSponsoredBundle(sponsor, user *types.Transaction) (*SponsoredBundle, error) {
+	return miner.worker.addSponsoredBundle(sponsor, user)
+}
+
+// SponsoredBundles returns the sponsored bundles waiting for inclusion.
+func (miner *Miner) SponsoredBundles() []*SponsoredBundle {
+	return miner.worker.sponsored.list()
+}
+
 func (miner *Miner) SetEtherbase(addr common.Address) {
 	miner.worker.setEtherbase(addr)
 }
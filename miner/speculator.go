@@ -0,0 +1,228 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params/vars"
+)
+
+// speculateDelay is the time to wait after a new head or new pending transactions
+// before starting a speculative run, batching up bursts of events.
+const speculateDelay = 250 * time.Millisecond
+
+var (
+	speculatedTxMeter   = metrics.NewRegisteredMeter("miner/speculate/txs", nil)
+	speculateFailMeter  = metrics.NewRegisteredMeter("miner/speculate/failed", nil)
+	speculateAbortMeter = metrics.NewRegisteredMeter("miner/speculate/aborted", nil)
+	speculateHitMeter   = metrics.NewRegisteredMeter("miner/speculate/hit", nil)
+	speculateMissMeter  = metrics.NewRegisteredMeter("miner/speculate/miss", nil)
+	speculateTimer      = metrics.NewRegisteredTimer("miner/speculate/time", nil)
+)
+
+// speculateLoop keeps the highest paying pending transactions pre-executed on
+// top of the current chain head while mining. Each run prepares the sealing
+// environment of the current round and executes the transactions into it, so
+// the next block assembly on the same head can start from the speculated
+// environment instead of executing them again.
+func (w *worker) speculateLoop() {
+	defer w.wg.Done()
+
+	var (
+		headCh = make(chan core.ChainHeadEvent, chainHeadChanSize)
+		txsCh  = make(chan core.NewTxsEvent, txChanSize)
+
+		timer     = time.NewTimer(0)
+		scheduled bool         // Whether the timer is armed for a new run
+		interrupt *atomic.Bool // Abort flag of the currently running speculation
+	)
+	<-timer.C // discard the initial tick
+	defer timer.Stop()
+
+	headSub := w.chain.SubscribeChainHeadEvent(headCh)
+	defer headSub.Unsubscribe()
+	txsSub := w.eth.TxPool().SubscribeTransactions(txsCh, false)
+	defer txsSub.Unsubscribe()
+
+	schedule := func() {
+		if !scheduled {
+			timer.Reset(speculateDelay)
+			scheduled = true
+		}
+	}
+	abort := func() {
+		if interrupt != nil {
+			interrupt.Store(true)
+			interrupt = nil
+		}
+	}
+	defer abort()
+
+	for {
+		select {
+		case <-headCh:
+			// Anything executed on the previous head is stale, stop it
+			abort()
+			schedule()
+
+		case <-txsCh:
+			schedule()
+
+		case <-timer.C:
+			scheduled = false
+			if !w.isRunning() {
+				continue
+			}
+			abort()
+			interrupt = new(atomic.Bool)
+
+			w.wg.Add(1)
+			go func(interrupt *atomic.Bool) {
+				defer w.wg.Done()
+				w.speculate(interrupt)
+			}(interrupt)
+
+		case <-w.exitCh:
+			return
+		case <-headSub.Err():
+			return
+		case <-txsSub.Err():
+			return
+		}
+	}
+}
+
+// speculate prepares the sealing environment of the current round and executes
+// up to the configured number of the best paying pending transactions into it,
+// in the order the block assembly would pick them. Unless aborted, the result
+// replaces any previous speculation. The number of executed transactions is
+// returned.
+func (w *worker) speculate(interrupt *atomic.Bool) int {
+	start := time.Now()
+
+	w.specMu.Lock()
+	timestamp := w.specTime
+	w.specMu.Unlock()
+	if timestamp == 0 {
+		timestamp = time.Now().Unix()
+	}
+	env, err := w.prepareWork(&generateParams{
+		timestamp: uint64(timestamp),
+		coinbase:  w.etherbase(),
+	})
+	if err != nil {
+		log.Debug("Failed to prepare speculative environment", "err", err)
+		return 0
+	}
+	filter := w.pendingFilter(env)
+	filter.OnlyPlainTxs = true
+
+	var (
+		txs = newTransactionsByPriceAndNonce(env.signer, w.eth.TxPool().Pending(filter), env.header.BaseFee)
+
+		failed int
+	)
+	env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+
+	for env.tcount < w.config.SpeculativeTxs && env.gasPool.Gas() >= vars.TxGas {
+		if interrupt.Load() {
+			speculateAbortMeter.Mark(1)
+			env.discard()
+			return 0
+		}
+		ltx, _ := txs.Peek()
+		if ltx == nil {
+			break
+		}
+		if env.gasPool.Gas() < ltx.Gas {
+			txs.Pop()
+			continue
+		}
+		tx := ltx.Resolve()
+		if tx == nil {
+			txs.Pop()
+			continue
+		}
+		env.state.SetTxContext(tx.Hash(), env.tcount)
+		if _, err := w.commitTransaction(env, tx); err != nil {
+			failed++
+			txs.Pop()
+			continue
+		}
+		env.tcount++
+		txs.Shift()
+	}
+	if interrupt.Load() {
+		speculateAbortMeter.Mark(1)
+		env.discard()
+		return 0
+	}
+	executed := env.tcount
+	speculatedTxMeter.Mark(int64(executed))
+	speculateFailMeter.Mark(int64(failed))
+	speculateTimer.UpdateSince(start)
+
+	log.Debug("Speculatively executed pending transactions", "number", env.header.Number, "txs", executed, "failed", failed, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	w.specMu.Lock()
+	if w.speculated != nil {
+		w.speculated.discard()
+	}
+	w.speculated = env
+	w.specMu.Unlock()
+	return executed
+}
+
+// takeSpeculation returns the speculated sealing environment if it was built
+// on the same header as the given freshly prepared one, nil otherwise. Either
+// way the speculation is consumed.
+func (w *worker) takeSpeculation(header *types.Header) *environment {
+	w.specMu.Lock()
+	env := w.speculated
+	w.speculated = nil
+	w.specMu.Unlock()
+
+	if env == nil {
+		return nil
+	}
+	// Apart from the gas used, the header is untouched by the transactions
+	prepared := types.CopyHeader(env.header)
+	prepared.GasUsed = 0
+	if prepared.Hash() != header.Hash() {
+		speculateMissMeter.Mark(1)
+		env.discard()
+		return nil
+	}
+	speculateHitMeter.Mark(1)
+	return env
+}
+
+// setSpeculationTime sets the timestamp of the current sealing round, which
+// speculative environments are prepared with.
+func (w *worker) setSpeculationTime(timestamp int64) {
+	w.specMu.Lock()
+	defer w.specMu.Unlock()
+
+	w.specTime = timestamp
+}
@@ -0,0 +1,107 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Tests that speculation executes the pending transactions on the head state,
+// honouring both the configured limit and the abort flag.
+func TestSpeculate(t *testing.T) {
+	t.Parallel()
+
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	backend := newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	backend.txPool.Add(pendingTxs, true, true)
+	backend.txPool.Sync()
+
+	config := *testConfig
+	config.SpeculativeTxs = len(pendingTxs)
+	w := newWorker(&config, ethashChainConfig, engine, backend, new(event.TypeMux), nil, false)
+	defer w.close()
+
+	if executed := w.speculate(new(atomic.Bool)); executed != len(pendingTxs) {
+		t.Fatalf("speculated transaction count mismatch: have %d, want %d", executed, len(pendingTxs))
+	}
+	aborted := new(atomic.Bool)
+	aborted.Store(true)
+	if executed := w.speculate(aborted); executed != 0 {
+		t.Fatalf("aborted speculation executed %d transactions", executed)
+	}
+}
+
+// Tests that block assembly picks up the speculated environment only if it was
+// prepared on the same header.
+func TestTakeSpeculation(t *testing.T) {
+	t.Parallel()
+
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	backend := newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	backend.txPool.Add(pendingTxs, true, true)
+	backend.txPool.Sync()
+
+	config := *testConfig
+	config.SpeculativeTxs = len(pendingTxs)
+	w := newWorker(&config, ethashChainConfig, engine, backend, new(event.TypeMux), nil, false)
+	defer w.close()
+
+	timestamp := time.Now().Unix()
+	prepare := func(timestamp int64) *environment {
+		env, err := w.prepareWork(&generateParams{timestamp: uint64(timestamp), coinbase: w.etherbase()})
+		if err != nil {
+			t.Fatalf("failed to prepare work: %v", err)
+		}
+		return env
+	}
+	// Speculation on another round's header is discarded
+	w.setSpeculationTime(timestamp)
+	w.speculate(new(atomic.Bool))
+	if env := w.takeSpeculation(prepare(timestamp + 1).header); env != nil {
+		t.Fatalf("speculation on mismatching header reused")
+	}
+	// Speculation on the same header is picked up, only once
+	w.speculate(new(atomic.Bool))
+	env := w.takeSpeculation(prepare(timestamp).header)
+	if env == nil {
+		t.Fatalf("speculation on matching header not reused")
+	}
+	defer env.discard()
+	if len(env.txs) != len(pendingTxs) || len(env.receipts) != len(pendingTxs) {
+		t.Fatalf("speculated environment mismatch: %d txs, %d receipts, want %d", len(env.txs), len(env.receipts), len(pendingTxs))
+	}
+	if w.takeSpeculation(prepare(timestamp).header) != nil {
+		t.Fatalf("speculation reused twice")
+	}
+	// Filling the speculated environment skips the already executed transactions
+	if err := w.fillTransactions(nil, env); err != nil {
+		t.Fatalf("failed to fill speculated environment: %v", err)
+	}
+	if len(env.txs) != len(pendingTxs) {
+		t.Fatalf("transaction count mismatch after filling: have %d, want %d", len(env.txs), len(pendingTxs))
+	}
+}
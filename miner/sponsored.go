@@ -0,0 +1,204 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// maxSponsoredBundles is the maximum number of sponsored bundles waiting
+	// for inclusion at any time.
+	maxSponsoredBundles = 64
+
+	// sponsoredBundleLifetime is the number of blocks after which a sponsored
+	// bundle which could not be included is discarded.
+	sponsoredBundleLifetime = 64
+)
+
+var (
+	errSponsoredQueueFull = errors.New("too many pending sponsored bundles")
+	errSponsoredBlobTx    = errors.New("blob transactions cannot be sponsored")
+	errSponsoredDuplicate = errors.New("sponsored bundle already known")
+	errNoPendingState     = errors.New("pending state not available")
+	errSponsoredDisabled  = errors.New("sponsored bundles are not enabled")
+)
+
+// SponsoredBundle is a pair of transactions, where the sponsor transaction
+// enables the execution of the user transaction (e.g. by funding its sender).
+// The bundle is included atomically: the sponsor transaction directly followed
+// by the user transaction, or none of them at all.
+type SponsoredBundle struct {
+	Sponsor *types.Transaction
+	User    *types.Transaction
+	Number  uint64 // Number of the pending block the bundle was validated on
+}
+
+// sponsoredQueue is the set of sponsored bundles waiting to be included into
+// a locally built block.
+type sponsoredQueue struct {
+	bundles []*SponsoredBundle
+	lock    sync.Mutex
+}
+
+// add appends a bundle to the queue, unless it is full or the bundle is known.
+func (q *sponsoredQueue) add(bundle *SponsoredBundle) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for _, b := range q.bundles {
+		if b.Sponsor.Hash() == bundle.Sponsor.Hash() && b.User.Hash() == bundle.User.Hash() {
+			return errSponsoredDuplicate
+		}
+	}
+	if len(q.bundles) >= maxSponsoredBundles {
+		return errSponsoredQueueFull
+	}
+	q.bundles = append(q.bundles, bundle)
+	return nil
+}
+
+// list returns the bundles currently waiting for inclusion, in arrival order.
+func (q *sponsoredQueue) list() []*SponsoredBundle {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return append([]*SponsoredBundle(nil), q.bundles...)
+}
+
+// remove drops the given bundle from the queue.
+func (q *sponsoredQueue) remove(bundle *SponsoredBundle) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for i, b := range q.bundles {
+		if b == bundle {
+			q.bundles = append(q.bundles[:i], q.bundles[i+1:]...)
+			return
+		}
+	}
+}
+
+// addSponsoredBundle validates the sponsor and user transactions by executing
+// them in order on top of the pending state, and queues them for inclusion if
+// both succeed.
+func (w *worker) addSponsoredBundle(sponsor, user *types.Transaction) (*SponsoredBundle, error) {
+	if !w.config.Sponsored {
+		return nil, errSponsoredDisabled
+	}
+	if sponsor.Type() == types.BlobTxType || user.Type() == types.BlobTxType {
+		return nil, errSponsoredBlobTx
+	}
+	block, statedb := w.pending()
+	if block == nil || statedb == nil {
+		return nil, errNoPendingState
+	}
+	var (
+		header = types.CopyHeader(block.Header())
+		gp     = new(core.GasPool).AddGas(header.GasLimit)
+		used   uint64
+	)
+	for i, tx := range []*types.Transaction{sponsor, user} {
+		statedb.SetTxContext(tx.Hash(), len(block.Transactions())+i)
+		if _, err := core.ApplyTransaction(w.chainConfig, w.chain, &header.Coinbase, gp, statedb, header, tx, &used, *w.chain.GetVMConfig()); err != nil {
+			if tx == sponsor {
+				return nil, fmt.Errorf("sponsor transaction %v: %w", tx.Hash(), err)
+			}
+			return nil, fmt.Errorf("user transaction %v: %w", tx.Hash(), err)
+		}
+	}
+	bundle := &SponsoredBundle{
+		Sponsor: sponsor,
+		User:    user,
+		Number:  block.NumberU64(),
+	}
+	if err := w.sponsored.add(bundle); err != nil {
+		return nil, err
+	}
+	log.Info("Queued sponsored bundle", "sponsor", sponsor.Hash(), "user", user.Hash(), "number", bundle.Number)
+	return bundle, nil
+}
+
+// commitSponsoredBundles includes the queued sponsored bundles at the front of
+// the block being built. Every bundle is either included as a whole, with the
+// sponsor and user transactions adjacent, or not at all. Bundles which became
+// stale (already included or expired) are dropped from the queue.
+func (w *worker) commitSponsoredBundles(env *environment) {
+	if !w.config.Sponsored {
+		return
+	}
+	bundles := w.sponsored.list()
+	if len(bundles) == 0 {
+		return
+	}
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	}
+	number := env.header.Number.Uint64()
+	for _, bundle := range bundles {
+		if number > bundle.Number+sponsoredBundleLifetime {
+			log.Debug("Dropping expired sponsored bundle", "sponsor", bundle.Sponsor.Hash(), "user", bundle.User.Hash())
+			w.sponsored.remove(bundle)
+			continue
+		}
+		err := w.commitSponsoredBundle(env, bundle)
+		switch {
+		case err == nil:
+			log.Debug("Included sponsored bundle", "sponsor", bundle.Sponsor.Hash(), "user", bundle.User.Hash(), "number", number)
+
+		case errors.Is(err, core.ErrNonceTooLow):
+			// Either transaction is already on chain, the bundle can never
+			// be included anymore.
+			log.Debug("Dropping stale sponsored bundle", "sponsor", bundle.Sponsor.Hash(), "user", bundle.User.Hash(), "err", err)
+			w.sponsored.remove(bundle)
+
+		default:
+			log.Trace("Skipping sponsored bundle", "sponsor", bundle.Sponsor.Hash(), "user", bundle.User.Hash(), "err", err)
+		}
+	}
+}
+
+// commitSponsoredBundle commits the sponsor and the user transaction of the
+// bundle, reverting the environment if either of them fails.
+func (w *worker) commitSponsoredBundle(env *environment, bundle *SponsoredBundle) error {
+	var (
+		snap   = env.state.Snapshot()
+		gas    = env.gasPool.Gas()
+		used   = env.header.GasUsed
+		txs    = len(env.txs)
+		tcount = env.tcount
+	)
+	for _, tx := range []*types.Transaction{bundle.Sponsor, bundle.User} {
+		env.state.SetTxContext(tx.Hash(), env.tcount)
+		if _, err := w.commitTransaction(env, tx); err != nil {
+			env.state.RevertToSnapshot(snap)
+			env.gasPool.SetGas(gas)
+			env.header.GasUsed = used
+			env.txs, env.receipts = env.txs[:txs], env.receipts[:txs]
+			env.tcount = tcount
+			return err
+		}
+		env.tcount++
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/vars"
+)
+
+// Tests that sponsored bundles are validated in order against the pending state
+// and included adjacently at the front of the built blocks.
+func TestSponsoredBundle(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	backend := newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	backend.txPool.Add(pendingTxs, true, false)
+	config := *testConfig
+	config.Sponsored = true
+	w := newWorker(&config, ethashChainConfig, engine, backend, new(event.TypeMux), nil, false)
+	w.setEtherbase(testBankAddress)
+	defer w.close()
+
+	// Create the pending state to validate against
+	timestamp := uint64(time.Now().Unix())
+	env, err := w.prepareWork(&generateParams{timestamp: timestamp, coinbase: testBankAddress})
+	if err != nil {
+		t.Fatalf("failed to prepare work: %v", err)
+	}
+	w.updateSnapshot(env)
+	env.discard()
+
+	var (
+		signer   = types.LatestSigner(params.TestChainConfig)
+		price    = big.NewInt(10 * vars.InitialBaseFee)
+		key, _   = crypto.GenerateKey()
+		addr     = crypto.PubkeyToAddress(key.PublicKey)
+		fee      = new(big.Int).Mul(price, big.NewInt(int64(vars.TxGas)))
+		funding  = new(big.Int).Add(fee, big.NewInt(1000))
+		sponsor  = types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{Nonce: 0, To: &addr, Value: funding, Gas: vars.TxGas, GasPrice: price})
+		user     = types.MustSignNewTx(key, signer, &types.LegacyTx{Nonce: 0, To: &testUserAddress, Value: big.NewInt(1000), Gas: vars.TxGas, GasPrice: price})
+		unfunded = types.MustSignNewTx(key, signer, &types.LegacyTx{Nonce: 0, To: &testUserAddress, Value: big.NewInt(1001), Gas: vars.TxGas, GasPrice: price})
+	)
+	// The user transaction can't be executed before being sponsored
+	if _, err := w.addSponsoredBundle(user, sponsor); err == nil {
+		t.Fatal("bundle executing the user transaction first accepted")
+	}
+	// The user transaction can't spend more than the sponsor provides
+	if _, err := w.addSponsoredBundle(sponsor, unfunded); err == nil {
+		t.Fatal("bundle overspending the sponsorship accepted")
+	}
+	if n := len(w.sponsored.list()); n != 0 {
+		t.Fatalf("invalid bundles queued: have %d", n)
+	}
+	if _, err := w.addSponsoredBundle(sponsor, user); err != nil {
+		t.Fatalf("failed to add sponsored bundle: %v", err)
+	}
+	if _, err := w.addSponsoredBundle(sponsor, user); !errors.Is(err, errSponsoredDuplicate) {
+		t.Fatalf("duplicate bundle error mismatch: have %v, want %v", err, errSponsoredDuplicate)
+	}
+	// Build a block and ensure the bundle is included at its front
+	res := w.generateWork(&generateParams{timestamp: timestamp, coinbase: testBankAddress})
+	if res.err != nil {
+		t.Fatalf("failed to generate block: %v", res.err)
+	}
+	txs := res.block.Transactions()
+	if len(txs) < 2 {
+		t.Fatalf("bundle not included: have %d transactions", len(txs))
+	}
+	if txs[0].Hash() != sponsor.Hash() || txs[1].Hash() != user.Hash() {
+		t.Fatalf("bundle not included adjacently: have %x, %x", txs[0].Hash(), txs[1].Hash())
+	}
+}
+
+// Tests that sponsored bundles are rejected unless explicitly enabled.
+func TestSponsoredBundleDisabled(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, _ := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	var (
+		signer = types.LatestSigner(params.TestChainConfig)
+		price  = big.NewInt(10 * vars.InitialBaseFee)
+		tx     = types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{Nonce: 0, To: &testUserAddress, Value: big.NewInt(1000), Gas: vars.TxGas, GasPrice: price})
+	)
+	if _, err := w.addSponsoredBundle(tx, tx); !errors.Is(err, errSponsoredDisabled) {
+		t.Fatalf("error mismatch: have %v, want %v", err, errSponsoredDisabled)
+	}
+}
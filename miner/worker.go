@@ -248,6 +248,12 @@ type worker struct {
 	pendingMu    sync.RWMutex
 	pendingTasks map[common.Hash]*task
 
+	sponsored sponsoredQueue // Sponsored transaction bundles waiting for inclusion
+
+	specMu     sync.Mutex   // The lock used to protect the speculation fields below
+	specTime   int64        // Timestamp of the current sealing round
+	speculated *environment // Sealing environment prefilled by the speculator
+
 	snapshotMu       sync.RWMutex // The lock used to protect the snapshots below
 	snapshotBlock    *types.Block
 	snapshotReceipts types.Receipts
@@ -345,6 +351,10 @@ func newWorker(config *Config, chainConfig ctypes.ChainConfigurator, engine cons
 	go worker.resultLoop()
 	go worker.taskLoop()
 
+	if config.SpeculativeTxs > 0 {
+		worker.wg.Add(1)
+		go worker.speculateLoop()
+	}
 	// Submit first work to initialize pending state.
 	if init {
 		worker.startCh <- struct{}{}
@@ -453,6 +463,10 @@ func (w *worker) close() {
 	w.running.Store(false)
 	close(w.exitCh)
 	w.wg.Wait()
+
+	if w.speculated != nil {
+		w.speculated.discard()
+	}
 }
 
 // recalcRecommit recalculates the resubmitting interval upon feedback.
@@ -1203,20 +1217,8 @@ func (w *worker) prepareWork(genParams *generateParams) (*environment, error) {
 // into the given sealing block. The transaction selection and ordering strategy can
 // be customized with the plugin in the future.
 func (w *worker) fillTransactions(interrupt *atomic.Int32, env *environment) error {
-	w.mu.RLock()
-	tip := w.tip
-	w.mu.RUnlock()
-
 	// Retrieve the pending transactions pre-filtered by the 1559/4844 dynamic fees
-	filter := txpool.PendingFilter{
-		MinTip: tip,
-	}
-	if env.header.BaseFee != nil {
-		filter.BaseFee = uint256.MustFromBig(env.header.BaseFee)
-	}
-	if env.header.ExcessBlobGas != nil {
-		filter.BlobFee = uint256.MustFromBig(eip4844.CalcBlobFee(*env.header.ExcessBlobGas))
-	}
+	filter := w.pendingFilter(env)
 	filter.OnlyPlainTxs, filter.OnlyBlobTxs = true, false
 	pendingPlainTxs := w.eth.TxPool().Pending(filter)
 
@@ -1237,6 +1239,9 @@ func (w *worker) fillTransactions(interrupt *atomic.Int32, env *environment) err
 			localBlobTxs[account] = txs
 		}
 	}
+	// Sponsored bundles go first, so their transactions stay adjacent.
+	w.commitSponsoredBundles(env)
+
 	// Fill the block with all available pending transactions.
 	if len(localPlainTxs) > 0 || len(localBlobTxs) > 0 {
 		plainTxs := newTransactionsByPriceAndNonce(env.signer, localPlainTxs, env.header.BaseFee)
@@ -1257,6 +1262,25 @@ func (w *worker) fillTransactions(interrupt *atomic.Int32, env *environment) err
 	return nil
 }
 
+// pendingFilter returns the txpool filter selecting the transactions which are
+// includable in the given sealing environment.
+func (w *worker) pendingFilter(env *environment) txpool.PendingFilter {
+	w.mu.RLock()
+	tip := w.tip
+	w.mu.RUnlock()
+
+	filter := txpool.PendingFilter{
+		MinTip: tip,
+	}
+	if env.header.BaseFee != nil {
+		filter.BaseFee = uint256.MustFromBig(env.header.BaseFee)
+	}
+	if env.header.ExcessBlobGas != nil {
+		filter.BlobFee = uint256.MustFromBig(eip4844.CalcBlobFee(*env.header.ExcessBlobGas))
+	}
+	return filter
+}
+
 // generateWork generates a sealing block based on the given parameters.
 func (w *worker) generateWork(params *generateParams) *newPayloadResult {
 	work, err := w.prepareWork(params)
@@ -1313,13 +1337,34 @@ func (w *worker) commitWork(interrupt *atomic.Int32, noempty bool, timestamp int
 	if err != nil {
 		return
 	}
+	// Continue from the speculatively executed transactions if they were run on
+	// the same header. Sponsored bundles need to go first though.
+	if w.config.SpeculativeTxs > 0 {
+		w.setSpeculationTime(timestamp)
+		if len(w.sponsored.list()) == 0 {
+			if spec := w.takeSpeculation(work.header); spec != nil {
+				work.discard()
+				work = spec
+			}
+		}
+	}
 	// Create an empty block based on temporary copied state for
 	// sealing in advance without waiting block execution finished.
 	if !noempty && !w.noempty.Load() {
 		w.commit(work.copy(), nil, false, start)
 	}
+	// Branch off the alternative candidate blocks from the same base, if enabled,
+	// and fill them concurrently with the default one.
+	var alternatives []*environment
+	for i := 1; i < w.config.Candidates; i++ {
+		alternatives = append(alternatives, work.copy())
+	}
+	pending := w.fillCandidates(interrupt, alternatives)
+
 	// Fill pending transactions from the txpool into the block.
 	err = w.fillTransactions(interrupt, work)
+	pending.Wait()
+
 	switch {
 	case err == nil:
 		// The entire block is filled, decrease resubmit interval in case
@@ -1345,8 +1390,15 @@ func (w *worker) commitWork(interrupt *atomic.Int32, noempty bool, timestamp int
 		// delay, and possibly causes miner to mine on the previous head,
 		// which could result in higher uncle rate.
 		work.discard()
+		for _, env := range alternatives {
+			env.discard()
+		}
 		return
 	}
+	// Pick the most profitable of the candidate blocks
+	if len(alternatives) > 0 {
+		work = bestCandidate(work, alternatives)
+	}
 	// Submit the generated block for consensus sealing.
 	w.commit(work.copy(), w.fullTaskHook, true, start)
 
@@ -0,0 +1,76 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"net"
+	"os"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Names of the activated sockets recognised by the node. They are set with the
+// FileDescriptorName= option of the systemd socket units.
+const (
+	activatedIPC  = "ipc"
+	activatedHTTP = "http"
+)
+
+// assignListeners converts the activated socket files into listeners and maps
+// them to the endpoints they serve. Sockets named after an endpoint are assigned
+// to it, any other socket is assigned by its type: unix sockets to IPC and TCP
+// sockets to HTTP. Sockets not usable by any endpoint are closed. The files are
+// closed in all cases, as the listeners hold their own descriptors.
+func assignListeners(logger log.Logger, files []*os.File, names []string) map[string]net.Listener {
+	var (
+		listeners = make(map[string]net.Listener)
+		unnamed   []net.Listener
+	)
+	for i, file := range files {
+		listener, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			logger.Warn("Failed to use activated socket", "fd", file.Name(), "err", err)
+			continue
+		}
+		var name string
+		if i < len(names) {
+			name = names[i]
+		}
+		if (name == activatedIPC || name == activatedHTTP) && listeners[name] == nil {
+			listeners[name] = listener
+		} else {
+			unnamed = append(unnamed, listener)
+		}
+	}
+	for _, listener := range unnamed {
+		var name string
+		switch listener.(type) {
+		case *net.UnixListener:
+			name = activatedIPC
+		case *net.TCPListener:
+			name = activatedHTTP
+		}
+		if name == "" || listeners[name] != nil {
+			logger.Warn("Closing unused activated socket", "addr", listener.Addr())
+			listener.Close()
+			continue
+		}
+		listeners[name] = listener
+	}
+	return listeners
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build !windows
+// +build !windows
+
+package node
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+type fileListener interface {
+	net.Listener
+	File() (*os.File, error)
+}
+
+func listenerFile(t *testing.T, network, addr string) *os.File {
+	t.Helper()
+
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		t.Fatalf("failed to listen on %s %s: %v", network, addr, err)
+	}
+	defer l.Close()
+
+	file, err := l.(fileListener).File()
+	if err != nil {
+		t.Fatalf("failed to get listener file: %v", err)
+	}
+	return file
+}
+
+// Tests that activated sockets are assigned to endpoints by name, falling back
+// to the socket type for unnamed ones.
+func TestAssignActivatedListeners(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		networks []string
+		names    []string
+		want     map[string]string // endpoint -> listener network
+	}{
+		// Unnamed sockets are assigned by type
+		{
+			networks: []string{"tcp", "unix"},
+			want:     map[string]string{activatedHTTP: "tcp", activatedIPC: "unix"},
+		},
+		// Named sockets override the type, e.g. HTTP over a unix socket
+		{
+			networks: []string{"unix", "unix"},
+			names:    []string{"http", "ipc"},
+			want:     map[string]string{activatedHTTP: "unix", activatedIPC: "unix"},
+		},
+		// Surplus sockets are dropped
+		{
+			networks: []string{"tcp", "tcp"},
+			names:    []string{"geth.socket", "geth.socket"},
+			want:     map[string]string{activatedHTTP: "tcp"},
+		},
+	}
+	for i, tt := range tests {
+		var files []*os.File
+		for j, network := range tt.networks {
+			addr := "127.0.0.1:0"
+			if network == "unix" {
+				addr = filepath.Join(dir, fmt.Sprintf("%d-%d.sock", i, j))
+			}
+			files = append(files, listenerFile(t, network, addr))
+		}
+		listeners := assignListeners(log.Root(), files, tt.names)
+		if len(listeners) != len(tt.want) {
+			t.Errorf("test %d: listener count mismatch: have %d, want %d", i, len(listeners), len(tt.want))
+		}
+		for name, network := range tt.want {
+			l, ok := listeners[name]
+			if !ok {
+				t.Errorf("test %d: missing %s listener", i, name)
+				continue
+			}
+			if have := l.Addr().Network(); have != network {
+				t.Errorf("test %d: %s listener network mismatch: have %s, want %s", i, name, have, network)
+			}
+		}
+		for _, l := range listeners {
+			l.Close()
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build !windows
+// +build !windows
+
+package node
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// listenFdsStart is the first file descriptor passed by the service manager,
+// see sd_listen_fds(3).
+const listenFdsStart = 3
+
+// activatedListeners returns the listening sockets passed to the process by
+// systemd socket activation, keyed by the endpoint they serve. The environment
+// is consumed on the first call, so only a single node in the process receives
+// the sockets.
+func activatedListeners(logger log.Logger) map[string]net.Listener {
+	if os.Getenv("LISTEN_PID") != strconv.Itoa(os.Getpid()) {
+		return nil
+	}
+	var (
+		fds   = os.Getenv("LISTEN_FDS")
+		names = os.Getenv("LISTEN_FDNAMES")
+	)
+
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	count, err := strconv.Atoi(fds)
+	if err != nil || count <= 0 {
+		logger.Warn("Invalid socket activation environment", "fds", fds)
+		return nil
+	}
+	files := make([]*os.File, count)
+	for i := range files {
+		fd := listenFdsStart + i
+		syscall.CloseOnExec(fd)
+		files[i] = os.NewFile(uintptr(fd), "LISTEN_FD_"+strconv.Itoa(fd))
+	}
+	var fdnames []string
+	if names != "" {
+		fdnames = strings.Split(names, ":")
+	}
+	return assignListeners(logger, files, fdnames)
+}
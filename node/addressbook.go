@@ -0,0 +1,146 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// addressBookNameRegexp restricts the names of address book entries.
+var addressBookNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// AddressBookEntry is a named account address.
+type AddressBookEntry struct {
+	Name    string         `json:"name"`
+	Address common.Address `json:"address"`
+}
+
+// addressBook is a persisted set of named account addresses, which can be used
+// in place of raw addresses in IPC and in-process RPC call parameters as "@name".
+type addressBook struct {
+	path    string // File the entries are persisted to, empty for an in-memory book
+	lock    sync.RWMutex
+	entries map[string]common.Address
+}
+
+// newAddressBook loads the address book persisted in the given file, if any.
+func newAddressBook(path string) (*addressBook, error) {
+	book := &addressBook{path: path, entries: make(map[string]common.Address)}
+	if path == "" {
+		return book, nil
+	}
+	blob, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return book, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(blob, &book.entries); err != nil {
+		return nil, fmt.Errorf("invalid address book %s: %v", path, err)
+	}
+	return book, nil
+}
+
+// add sets the address of the named entry, replacing any previous one.
+func (b *addressBook) add(name string, addr common.Address) error {
+	if !addressBookNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid name %q, must be 1-64 letters, digits or any of '_.-'", name)
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	prev, existed := b.entries[name]
+	b.entries[name] = addr
+	if err := b.save(); err != nil {
+		if existed {
+			b.entries[name] = prev
+		} else {
+			delete(b.entries, name)
+		}
+		return err
+	}
+	return nil
+}
+
+// remove deletes the named entry, reporting whether it existed.
+func (b *addressBook) remove(name string) (bool, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	addr, ok := b.entries[name]
+	if !ok {
+		return false, nil
+	}
+	delete(b.entries, name)
+	if err := b.save(); err != nil {
+		b.entries[name] = addr
+		return false, err
+	}
+	return true, nil
+}
+
+// list returns all entries, sorted by name.
+func (b *addressBook) list() []AddressBookEntry {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	entries := make([]AddressBookEntry, 0, len(b.entries))
+	for name, addr := range b.entries {
+		entries = append(entries, AddressBookEntry{Name: name, Address: addr})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+// resolve is the rpc.NameResolver of the address book.
+func (b *addressBook) resolve(name string) (string, bool) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	addr, ok := b.entries[name]
+	if !ok {
+		return "", false
+	}
+	return addr.Hex(), true
+}
+
+// save atomically writes the entries to the address book file. The caller must
+// hold the write lock.
+func (b *addressBook) save() error {
+	if b.path == "" {
+		return nil
+	}
+	blob, err := json.MarshalIndent(b.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(filepath.Dir(b.path), "."+filepath.Base(b.path)+".tmp")
+	if err := os.WriteFile(tmp, blob, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, b.path)
+}
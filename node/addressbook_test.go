@@ -0,0 +1,85 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Tests that address book entries are persisted and can be used in place of
+// addresses in RPC call parameters over the in-process endpoint, but not over
+// HTTP.
+func TestAddressBook(t *testing.T) {
+	var (
+		config = testNodeConfig()
+		alice  = common.HexToAddress("0xa11ce")
+	)
+	config.DataDir = t.TempDir()
+	config.HTTPHost = "127.0.0.1"
+	config.HTTPModules = []string{"admin"}
+
+	stack, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to create protocol stack: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start protocol stack: %v", err)
+	}
+	client := stack.Attach()
+
+	var ok bool
+	if err := client.Call(&ok, "admin_addressBookAdd", "alice", alice); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+	if err := client.Call(&ok, "admin_addressBookAdd", "bob", "@alice"); err != nil {
+		t.Fatalf("failed to add entry by name: %v", err)
+	}
+	if err := client.Call(&ok, "admin_addressBookAdd", "@carol", alice); err == nil {
+		t.Fatal("added entry with invalid name")
+	}
+	remote, err := rpc.Dial(stack.HTTPEndpoint())
+	if err != nil {
+		t.Fatalf("failed to dial HTTP endpoint: %v", err)
+	}
+	if err := remote.Call(&ok, "admin_addressBookAdd", "dave", "@alice"); err == nil {
+		t.Fatal("name resolved over HTTP")
+	}
+	remote.Close()
+	if err := client.Call(&ok, "admin_addressBookRemove", "bob"); err != nil || !ok {
+		t.Fatalf("failed to remove entry: %v", err)
+	}
+	client.Close()
+	stack.Close()
+
+	// Reopen the node and check the entries were persisted
+	if stack, err = New(config); err != nil {
+		t.Fatalf("failed to recreate protocol stack: %v", err)
+	}
+	defer stack.Close()
+
+	want := []AddressBookEntry{{Name: "alice", Address: alice}}
+	if have := stack.addressBook.list(); !reflect.DeepEqual(have, want) {
+		t.Fatalf("entries mismatch: have %v, want %v", have, want)
+	}
+	if addr, ok := stack.addressBook.resolve("alice"); !ok || addr != alice.Hex() {
+		t.Fatalf("resolved address mismatch: have %s, want %s", addr, alice.Hex())
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/internal/debug"
@@ -28,6 +29,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/discover"
 	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
@@ -50,6 +52,18 @@ func (n *Node) apis() []rpc.API {
 	}
 }
 
+// localAPIs returns the built-in RPC APIs restricted to the node operator. They
+// are only served in-process and over IPC, never on the HTTP, WebSocket or
+// authenticated endpoints.
+func (n *Node) localAPIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "debug",
+			Service:   new(debug.ProfileAPI),
+		},
+	}
+}
+
 // adminAPI is the collection of administrative API methods exposed over
 // both secure and unsecure RPC channels.
 type adminAPI struct {
@@ -183,6 +197,8 @@ func (api *adminAPI) StartHTTP(host *string, port *int, cors *string, apis *stri
 		rpcEndpointConfig: rpcEndpointConfig{
 			batchItemLimit:         api.node.config.BatchRequestLimit,
 			batchResponseSizeLimit: api.node.config.BatchResponseMaxSize,
+			lenientParams:          api.node.config.LenientParams,
+			subscriptionLimit:      api.node.config.SubscriptionLimit,
 		},
 	}
 	if cors != nil {
@@ -261,6 +277,8 @@ func (api *adminAPI) StartWS(host *string, port *int, allowedOrigins *string, ap
 		rpcEndpointConfig: rpcEndpointConfig{
 			batchItemLimit:         api.node.config.BatchRequestLimit,
 			batchResponseSizeLimit: api.node.config.BatchResponseMaxSize,
+			lenientParams:          api.node.config.LenientParams,
+			subscriptionLimit:      api.node.config.SubscriptionLimit,
 		},
 	}
 	if apis != nil {
@@ -319,11 +337,113 @@ func (api *adminAPI) NodeInfo() (*p2p.NodeInfo, error) {
 	return server.NodeInfo(), nil
 }
 
+// nodeRecord is the local node record as returned by admin_getENR.
+type nodeRecord struct {
+	ENR     string                   `json:"enr"`
+	Seq     uint64                   `json:"seq"`
+	Entries map[string]hexutil.Bytes `json:"entries"` // RLP encoded values by key
+}
+
+// GetENR retrieves the local node record along with all of its entries.
+func (api *adminAPI) GetENR() (*nodeRecord, error) {
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	self := server.Self()
+	record := &nodeRecord{
+		ENR:     self.String(),
+		Seq:     self.Seq(),
+		Entries: make(map[string]hexutil.Bytes),
+	}
+	// The elements are the sequence number followed by the key/value pairs
+	elems := self.Record().AppendElements(nil)
+	for i := 1; i+1 < len(elems); i += 2 {
+		record.Entries[elems[i].(string)] = hexutil.Bytes(elems[i+1].(rlp.RawValue))
+	}
+	return record, nil
+}
+
+// SetENR sets a custom entry in the local node record, or removes it if the
+// value is empty. The value must be RLP encoded. The updated record is signed
+// with a new sequence number and propagated through discovery.
+func (api *adminAPI) SetENR(key string, value hexutil.Bytes) (bool, error) {
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	if err := server.SetRecordEntry(key, value); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// discoveryTable contains the table contents and traffic statistics of a
+// discovery protocol, as returned by admin_discoveryTable.
+type discoveryTable struct {
+	Nodes   int                     `json:"nodes"`
+	Live    int                     `json:"live"`
+	Buckets [][]discover.BucketNode `json:"buckets"`
+	Packets discover.PacketStats    `json:"packets"`
+}
+
+func newDiscoveryTable(buckets [][]discover.BucketNode, packets discover.PacketStats) *discoveryTable {
+	table := &discoveryTable{Buckets: buckets, Packets: packets}
+	for _, bucket := range buckets {
+		for _, n := range bucket {
+			table.Nodes++
+			if n.Live {
+				table.Live++
+			}
+		}
+	}
+	return table
+}
+
+// DiscoveryTable retrieves the bucket contents of the node discovery tables,
+// including the liveness of every node, along with the number of discovery
+// packets sent and received by type.
+func (api *adminAPI) DiscoveryTable() (map[string]*discoveryTable, error) {
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	tables := make(map[string]*discoveryTable)
+	if disc := server.DiscoveryV4(); disc != nil {
+		tables["v4"] = newDiscoveryTable(disc.TableBuckets(), disc.PacketStats())
+	}
+	if disc := server.DiscoveryV5(); disc != nil {
+		tables["v5"] = newDiscoveryTable(disc.TableBuckets(), disc.PacketStats())
+	}
+	return tables, nil
+}
+
 // Datadir retrieves the current data directory the node is using.
 func (api *adminAPI) Datadir() string {
 	return api.node.DataDir()
 }
 
+// AddressBookAdd names an account address in the address book, so it can be
+// given as "@name" in place of the address in call parameters over IPC and the
+// in-process endpoint. The public HTTP and WebSocket endpoints don't resolve
+// names, so that remote callers can't probe the address book.
+func (api *adminAPI) AddressBookAdd(name string, address common.Address) (bool, error) {
+	if err := api.node.addressBook.add(name, address); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// AddressBookRemove deletes a named address from the address book.
+func (api *adminAPI) AddressBookRemove(name string) (bool, error) {
+	return api.node.addressBook.remove(name)
+}
+
+// AddressBookList retrieves all named addresses of the address book.
+func (api *adminAPI) AddressBookList() []AddressBookEntry {
+	return api.node.addressBook.list()
+}
+
 // web3API offers helper utils
 type web3API struct {
 	stack *Node
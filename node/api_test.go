@@ -25,6 +25,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/stretchr/testify/assert"
 )
@@ -353,3 +354,35 @@ func not(ok bool) string {
 	}
 	return "not "
 }
+
+// Tests that the profile API is only served in-process and over IPC, and doesn't
+// start the authenticated endpoint.
+func TestProfileAPIExposure(t *testing.T) {
+	config := testNodeConfig()
+	config.HTTPHost = "127.0.0.1"
+	config.HTTPModules = []string{"debug"}
+
+	stack, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to create protocol stack: %v", err)
+	}
+	defer stack.Close()
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start protocol stack: %v", err)
+	}
+	if stack.httpAuth.listenAddr() != "" {
+		t.Fatal("authenticated endpoint started")
+	}
+	var profile hexutil.Bytes
+	if err := stack.Attach().Call(&profile, "debug_captureProfile", "heap", 0); err != nil {
+		t.Fatalf("in-process capture failed: %v", err)
+	}
+	remote, err := rpc.Dial(stack.HTTPEndpoint())
+	if err != nil {
+		t.Fatalf("failed to dial HTTP endpoint: %v", err)
+	}
+	defer remote.Close()
+	if err := remote.Call(&profile, "debug_captureProfile", "heap", 0); err == nil {
+		t.Fatal("profile captured over HTTP")
+	}
+}
@@ -41,6 +41,7 @@ const (
 	datadirStaticNodes     = "static-nodes.json"  // Path within the datadir to the static node list
 	datadirTrustedNodes    = "trusted-nodes.json" // Path within the datadir to the trusted node list
 	datadirNodeDatabase    = "nodes"              // Path within the datadir to store the node infos
+	datadirAddressBook     = "addressbook.json"   // Path within the datadir to the address book
 )
 
 // Config represents a small collection of configuration values to fine tune the
@@ -206,6 +207,14 @@ type Config struct {
 	// BatchResponseMaxSize is the maximum number of bytes returned from a batched rpc call.
 	BatchResponseMaxSize int `toml:",omitempty"`
 
+	// LenientParams makes the RPC servers accept hex encoded call parameters
+	// lacking the 0x prefix, for compatibility with legacy tooling.
+	LenientParams bool `toml:",omitempty"`
+
+	// SubscriptionLimit is the maximum number of active subscriptions per RPC
+	// connection, zero meaning unlimited.
+	SubscriptionLimit int `toml:",omitempty"`
+
 	// JWTSecret is the path to the hex-encoded jwt secret.
 	JWTSecret string `toml:",omitempty"`
 
@@ -213,6 +222,12 @@ type Config struct {
 	EnablePersonal bool `toml:"-"`
 
 	DBEngine string `toml:",omitempty"`
+
+	// DataDirOverlay, if set, opens the databases in DataDir read-only and
+	// redirects all writes into copy-on-write databases in this folder. All
+	// other files of the node, such as its key, lock and journals, are kept in
+	// this folder too, only the keystore is still resolved against DataDir.
+	DataDirOverlay string `toml:",omitempty"`
 }
 
 // IPCEndpoint resolves an IPC endpoint based on a configured value, taking into
@@ -235,7 +250,7 @@ func (c *Config) IPCEndpoint() string {
 		if c.DataDir == "" {
 			return filepath.Join(os.TempDir(), c.IPCPath)
 		}
-		return filepath.Join(c.DataDir, c.IPCPath)
+		return filepath.Join(c.dataDir(), c.IPCPath)
 	}
 	return c.IPCPath
 }
@@ -339,6 +354,17 @@ var isOldGethResource = map[string]bool{
 
 // ResolvePath resolves path in the instance directory.
 func (c *Config) ResolvePath(path string) string {
+	return c.resolvePath(c.dataDir(), path)
+}
+
+// resolveBasePath resolves path in the instance directory of DataDir, even if
+// the node writes its files into an overlay directory.
+func (c *Config) resolveBasePath(path string) string {
+	return c.resolvePath(c.DataDir, path)
+}
+
+// resolvePath resolves path in the instance directory of the given datadir.
+func (c *Config) resolvePath(datadir string, path string) string {
 	if filepath.IsAbs(path) {
 		return path
 	}
@@ -350,7 +376,7 @@ func (c *Config) ResolvePath(path string) string {
 	if warn, isOld := isOldGethResource[path]; isOld {
 		oldpath := ""
 		if c.name() == "geth" {
-			oldpath = filepath.Join(c.DataDir, path)
+			oldpath = filepath.Join(datadir, path)
 		}
 		if oldpath != "" && common.FileExist(oldpath) {
 			if warn && !c.oldGethResourceWarning {
@@ -360,14 +386,23 @@ func (c *Config) ResolvePath(path string) string {
 			return oldpath
 		}
 	}
-	return filepath.Join(c.instanceDir(), path)
+	return filepath.Join(datadir, c.name(), path)
+}
+
+// dataDir returns the folder the node writes its files into, which is the
+// overlay directory if one is configured.
+func (c *Config) dataDir() string {
+	if c.DataDir != "" && c.DataDirOverlay != "" {
+		return c.DataDirOverlay
+	}
+	return c.DataDir
 }
 
 func (c *Config) instanceDir() string {
 	if c.DataDir == "" {
 		return ""
 	}
-	return filepath.Join(c.DataDir, c.name())
+	return filepath.Join(c.dataDir(), c.name())
 }
 
 // NodeKey retrieves the currently configured private key of the node, checking
@@ -396,7 +431,7 @@ func (c *Config) NodeKey() *ecdsa.PrivateKey {
 	if err != nil {
 		log.Crit(fmt.Sprintf("Failed to generate node key: %v", err))
 	}
-	instanceDir := filepath.Join(c.DataDir, c.name())
+	instanceDir := c.instanceDir()
 	if err := os.MkdirAll(instanceDir, 0700); err != nil {
 		log.Error(fmt.Sprintf("Failed to persist node key: %v", err))
 		return key
@@ -65,8 +65,10 @@ type Node struct {
 	wsAuth        *httpServer //
 	ipc           *ipcServer  // Stores information about the ipc http server
 	inprocHandler *rpc.Server // In-process RPC request handler to process the API requests
+	addressBook   *addressBook
 
-	databases map[*closeTrackingDB]struct{} // All open databases
+	databases  map[*closeTrackingDB]struct{} // All open databases
+	handlerRPC []*rpc.Server                 // RPC servers backing handlers created by HTTPHandler
 
 	inprocOpenRPC   *go_openrpc_reflect.Document
 	ipcOpenRPC      *go_openrpc_reflect.Document
@@ -112,6 +114,7 @@ func New(conf *Config) (*Node, error) {
 	}
 	server := rpc.NewServer()
 	server.SetBatchLimits(conf.BatchRequestLimit, conf.BatchResponseMaxSize)
+	server.SetLenientParams(conf.LenientParams)
 	node := &Node{
 		config:        conf,
 		inprocHandler: server,
@@ -135,6 +138,10 @@ func New(conf *Config) (*Node, error) {
 	}
 	node.keyDir = keyDir
 	node.keyDirTemp = isEphem
+	if node.addressBook, err = newAddressBook(conf.ResolvePath(datadirAddressBook)); err != nil {
+		return nil, err
+	}
+	server.SetNameResolver(node.addressBook.resolve)
 	// Creates an empty AccountManager with no backends. Callers (e.g. cmd/geth)
 	// are required to add the backends later on.
 	node.accman = accounts.NewManager(&accounts.Config{InsecureUnlockAllowed: conf.InsecureUnlockAllowed})
@@ -162,7 +169,14 @@ func New(conf *Config) (*Node, error) {
 	node.ws = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts)
 	node.wsAuth = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts)
 	node.ipc = newIPCServer(node.log, conf.IPCEndpoint())
+	node.ipc.resolver = node.addressBook.resolve
+	node.ipc.lenient = conf.LenientParams
 
+	// Serve the endpoints on any sockets passed in by the service manager.
+	if listeners := activatedListeners(node.log); listeners != nil {
+		node.http.activated = listeners[activatedHTTP]
+		node.ipc.activated = listeners[activatedIPC]
+	}
 	return node, nil
 }
 
@@ -327,7 +341,7 @@ func (n *Node) openDataDir() error {
 		return nil // ephemeral
 	}
 
-	instdir := filepath.Join(n.config.DataDir, n.config.name())
+	instdir := n.config.instanceDir()
 	if err := os.MkdirAll(instdir, 0700); err != nil {
 		return err
 	}
@@ -352,10 +366,13 @@ func (n *Node) closeDataDir() {
 }
 
 func (n *Node) setupOpenRPC() error {
+	// Document the same APIs the endpoints serve, see startRPC.
+	openAPIs, allAPIs := n.getAPIs()
+
 	// In-proc RPC is always available. It's created and assigned in the Node.New construction.
 	n.inprocOpenRPC = newOpenRPCDocument()
 	registerOpenRPCAPIs(n.inprocOpenRPC, n.rpcAPIs)
-	if err := n.inprocHandler.RegisterName("rpc", &RPCDiscoveryService{d: n.inprocOpenRPC}); err != nil {
+	if err := n.inprocHandler.RegisterName("rpc", newRPCDiscoveryService(n.inprocOpenRPC, n.rpcAPIs)); err != nil {
 		return err
 	}
 	n.inprocOpenRPC.WithMeta(metaRegistererForURL(""))
@@ -365,9 +382,7 @@ func (n *Node) setupOpenRPC() error {
 		n.ipcOpenRPC = newOpenRPCDocument()
 		registerOpenRPCAPIs(n.ipcOpenRPC, n.rpcAPIs)
 		n.ipcOpenRPC.RegisterListener(n.ipc.listener)
-		if err := n.ipc.srv.RegisterName("rpc", &RPCDiscoveryService{
-			d: n.ipcOpenRPC,
-		}); err != nil {
+		if err := n.ipc.srv.RegisterName("rpc", newRPCDiscoveryService(n.ipcOpenRPC, n.rpcAPIs)); err != nil {
 			return err
 		}
 		n.ipcOpenRPC.WithMeta(metaRegistererForURL(""))
@@ -375,10 +390,10 @@ func (n *Node) setupOpenRPC() error {
 	if n.http.rpcAllowed() {
 		n.httpOpenRPC = newOpenRPCDocument()
 		h := n.http.httpHandler.Load().(*rpcHandler)
-		registeredAPIs := GetAPIsByWhitelist(n.rpcAPIs, n.config.HTTPModules, false)
+		registeredAPIs := GetAPIsByWhitelist(openAPIs, n.config.HTTPModules, false)
 		registerOpenRPCAPIs(n.httpOpenRPC, registeredAPIs)
 		n.httpOpenRPC.RegisterListener(n.http.listener)
-		if err := h.server.RegisterName("rpc", &RPCDiscoveryService{d: n.httpOpenRPC}); err != nil {
+		if err := h.server.RegisterName("rpc", newRPCDiscoveryService(n.httpOpenRPC, registeredAPIs)); err != nil {
 			return err
 		}
 		n.httpOpenRPC.WithMeta(metaRegistererForURL("http://"))
@@ -386,10 +401,10 @@ func (n *Node) setupOpenRPC() error {
 	if n.httpAuth.rpcAllowed() {
 		n.httpAuthOpenRPC = newOpenRPCDocument()
 		h := n.httpAuth.httpHandler.Load().(*rpcHandler)
-		registeredAPIs := GetAPIsByWhitelist(n.rpcAPIs, n.config.HTTPModules, false)
+		registeredAPIs := GetAPIsByWhitelist(allAPIs, DefaultAuthModules, false)
 		registerOpenRPCAPIs(n.httpAuthOpenRPC, registeredAPIs)
 		n.httpAuthOpenRPC.RegisterListener(n.httpAuth.listener)
-		if err := h.server.RegisterName("rpc", &RPCDiscoveryService{d: n.httpAuthOpenRPC}); err != nil {
+		if err := h.server.RegisterName("rpc", newRPCDiscoveryService(n.httpAuthOpenRPC, registeredAPIs)); err != nil {
 			return err
 		}
 		n.httpAuthOpenRPC.WithMeta(metaRegistererForURL("http://"))
@@ -398,10 +413,10 @@ func (n *Node) setupOpenRPC() error {
 	if wsServer.wsAllowed() {
 		n.wsOpenRPC = newOpenRPCDocument()
 		h := wsServer.wsHandler.Load().(*rpcHandler)
-		registeredAPIs := GetAPIsByWhitelist(n.rpcAPIs, n.config.WSModules, false)
+		registeredAPIs := GetAPIsByWhitelist(openAPIs, n.config.WSModules, false)
 		registerOpenRPCAPIs(n.wsOpenRPC, registeredAPIs)
 		n.wsOpenRPC.RegisterListener(wsServer.listener)
-		if err := h.server.RegisterName("rpc", &RPCDiscoveryService{d: n.wsOpenRPC}); err != nil {
+		if err := h.server.RegisterName("rpc", newRPCDiscoveryService(n.wsOpenRPC, registeredAPIs)); err != nil {
 			return err
 		}
 		n.wsOpenRPC.WithMeta(metaRegistererForURL("ws://"))
@@ -410,10 +425,10 @@ func (n *Node) setupOpenRPC() error {
 	if wsAuthServer.wsAllowed() {
 		n.wsAuthOpenRPC = newOpenRPCDocument()
 		h := wsAuthServer.wsHandler.Load().(*rpcHandler)
-		registeredAPIs := GetAPIsByWhitelist(n.rpcAPIs, n.config.WSModules, false)
+		registeredAPIs := GetAPIsByWhitelist(allAPIs, DefaultAuthModules, false)
 		registerOpenRPCAPIs(n.wsAuthOpenRPC, registeredAPIs)
 		n.wsAuthOpenRPC.RegisterListener(wsAuthServer.listener)
-		if err := h.server.RegisterName("rpc", &RPCDiscoveryService{d: n.wsAuthOpenRPC}); err != nil {
+		if err := h.server.RegisterName("rpc", newRPCDiscoveryService(n.wsAuthOpenRPC, registeredAPIs)); err != nil {
 			return err
 		}
 		n.wsAuthOpenRPC.WithMeta(metaRegistererForURL("ws://"))
@@ -471,6 +486,8 @@ func (n *Node) startRPC() error {
 		}
 		apis = append(apis, api)
 	}
+	// The operator-only APIs are served in-process and over IPC alone
+	apis = append(apis, n.localAPIs()...)
 	if err := n.startInProc(apis); err != nil {
 		return err
 	}
@@ -489,6 +506,8 @@ func (n *Node) startRPC() error {
 	rpcConfig := rpcEndpointConfig{
 		batchItemLimit:         n.config.BatchRequestLimit,
 		batchResponseSizeLimit: n.config.BatchResponseMaxSize,
+		lenientParams:          n.config.LenientParams,
+		subscriptionLimit:      n.config.SubscriptionLimit,
 	}
 
 	initHttp := func(server *httpServer, port int) error {
@@ -617,6 +636,9 @@ func (n *Node) stopRPC() {
 	n.wsAuth.stop()
 	n.ipc.stop()
 	n.stopInProc()
+	for _, srv := range n.handlerRPC {
+		srv.Stop()
+	}
 }
 
 // startInProc registers all RPC APIs on the inproc server.
@@ -626,6 +648,11 @@ func (n *Node) startInProc(apis []rpc.API) error {
 			return err
 		}
 	}
+	for _, api := range apis {
+		if err := n.inprocHandler.RegisterDeprecations(api); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -686,6 +713,36 @@ func (n *Node) getAPIs() (unauthenticated, all []rpc.API) {
 	return unauthenticated, n.rpcAPIs
 }
 
+// HTTPHandler creates a handler serving the unauthenticated HTTP JSON-RPC APIs
+// of the node, honouring its configured modules, CORS and virtual hosts. This
+// allows mounting the node on an HTTP server managed outside of it, e.g. one
+// shared between several nodes running in the same process. The handler stops
+// serving requests when the node is closed.
+func (n *Node) HTTPHandler() (http.Handler, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.state == closedState {
+		return nil, ErrNodeStopped
+	}
+	var apis []rpc.API
+	open, _ := n.getAPIs()
+	for _, api := range open {
+		if api.Namespace == "personal" && !n.config.EnablePersonal {
+			continue
+		}
+		apis = append(apis, api)
+	}
+	srv := rpc.NewServer()
+	srv.SetBatchLimits(n.config.BatchRequestLimit, n.config.BatchResponseMaxSize)
+	srv.SetLenientParams(n.config.LenientParams)
+	if err := RegisterApis(apis, n.config.HTTPModules, srv); err != nil {
+		return nil, err
+	}
+	n.handlerRPC = append(n.handlerRPC, srv)
+	return NewHTTPHandlerStack(srv, n.config.HTTPCors, n.config.HTTPVirtualHosts, nil), nil
+}
+
 // RegisterHandler mounts a handler on the given path on the canonical HTTP server.
 //
 // The name of the handler is shown in a log message when the HTTP server starts
@@ -808,12 +865,13 @@ func (n *Node) OpenDatabase(name string, cache, handles int, namespace string, r
 		db = rawdb.NewMemoryDatabase()
 	} else {
 		db, err = rawdb.Open(rawdb.OpenOptions{
-			Type:      n.config.DBEngine,
-			Directory: n.ResolvePath(name),
-			Namespace: namespace,
-			Cache:     cache,
-			Handles:   handles,
-			ReadOnly:  readonly,
+			Type:             n.config.DBEngine,
+			Directory:        n.config.resolveBasePath(name),
+			Namespace:        namespace,
+			Cache:            cache,
+			Handles:          handles,
+			ReadOnly:         readonly,
+			OverlayDirectory: n.resolveOverlay(name),
 		})
 	}
 
@@ -841,12 +899,13 @@ func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, ancient
 	} else {
 		db, err = rawdb.Open(rawdb.OpenOptions{
 			Type:              n.config.DBEngine,
-			Directory:         n.ResolvePath(name),
+			Directory:         n.config.resolveBasePath(name),
 			AncientsDirectory: n.ResolveAncient(name, ancient),
 			Namespace:         namespace,
 			Cache:             cache,
 			Handles:           handles,
 			ReadOnly:          readonly,
+			OverlayDirectory:  n.resolveOverlay(name),
 		})
 	}
 
@@ -861,17 +920,28 @@ func (n *Node) ResolvePath(x string) string {
 	return n.config.ResolvePath(x)
 }
 
-// ResolveAncient returns the absolute path of the root ancient directory.
+// ResolveAncient returns the absolute path of the root ancient directory. With
+// an overlay directory configured, this is the read-only ancient directory of
+// the datadir.
 func (n *Node) ResolveAncient(name string, ancient string) string {
 	switch {
 	case ancient == "":
-		ancient = filepath.Join(n.ResolvePath(name), "ancient")
+		ancient = filepath.Join(n.config.resolveBasePath(name), "ancient")
 	case !filepath.IsAbs(ancient):
-		ancient = n.ResolvePath(ancient)
+		ancient = n.config.resolveBasePath(ancient)
 	}
 	return ancient
 }
 
+// resolveOverlay returns the absolute path of the copy-on-write overlay of the
+// named database, or an empty string if no overlay directory is configured.
+func (n *Node) resolveOverlay(name string) string {
+	if n.config.DataDirOverlay == "" {
+		return ""
+	}
+	return n.ResolvePath(name)
+}
+
 // closeTrackingDB wraps the Close method of a database. When the database is closed by the
 // service, the wrapper removes it from the node's database map. This ensures that Node
 // won't auto-close the database if it is closed by the service that opened it.
@@ -22,8 +22,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -31,6 +33,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/p2p"
@@ -111,6 +115,94 @@ func TestNodeUsedDataDir(t *testing.T) {
 	}
 }
 
+// Tests that a node running on an overlay directory leaves the files of the
+// datadir untouched, writing its own files and chain data into the overlay.
+func TestNodeDataDirOverlay(t *testing.T) {
+	var (
+		base    = t.TempDir()
+		overlay = t.TempDir()
+		genesis = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0)})
+	)
+	// Create a datadir with a database and some frozen chain data
+	stack, err := New(&Config{Name: "unit-test", DataDir: base})
+	if err != nil {
+		t.Fatalf("failed to create base protocol stack: %v", err)
+	}
+	db, err := stack.OpenDatabaseWithFreezer("chaindata", 0, 0, "", "", false)
+	if err != nil {
+		t.Fatalf("failed to open base database: %v", err)
+	}
+	if _, err := rawdb.WriteAncientBlocks(db, []*types.Block{genesis}, []types.Receipts{nil}, big.NewInt(0)); err != nil {
+		t.Fatalf("failed to freeze genesis: %v", err)
+	}
+	db.Put([]byte("key"), []byte("base"))
+	stack.Close()
+
+	before := dirContent(t, base)
+
+	// Modify both the key-value store and the freezer through the overlay
+	stack, err = New(&Config{Name: "unit-test", DataDir: base, DataDirOverlay: overlay})
+	if err != nil {
+		t.Fatalf("failed to create overlay protocol stack: %v", err)
+	}
+	db, err = stack.OpenDatabaseWithFreezer("chaindata", 0, 0, "", "", false)
+	if err != nil {
+		t.Fatalf("failed to open overlay database: %v", err)
+	}
+	if frozen, _ := db.Ancients(); frozen != 1 {
+		t.Fatalf("overlay ancient count mismatch: have %d, want 1", frozen)
+	}
+	if _, err := db.TruncateHead(0); err != nil {
+		t.Fatalf("failed to truncate overlay freezer: %v", err)
+	}
+	db.Put([]byte("key"), []byte("overlay"))
+	if nodedb := stack.config.NodeDB(); !strings.HasPrefix(nodedb, overlay) {
+		t.Errorf("node database outside of overlay: %s", nodedb)
+	}
+	stack.Close()
+
+	if after := dirContent(t, base); !reflect.DeepEqual(before, after) {
+		t.Fatalf("datadir modified through overlay: have %v, want %v", after, before)
+	}
+	for _, file := range []string{"LOCK", datadirPrivateKey, "chaindata/ancient"} {
+		if _, err := os.Stat(filepath.Join(overlay, "unit-test", file)); err != nil {
+			t.Errorf("%s missing from overlay: %v", file, err)
+		}
+	}
+	// Ensure the datadir still serves the original chain data
+	stack, err = New(&Config{Name: "unit-test", DataDir: base})
+	if err != nil {
+		t.Fatalf("failed to reopen base protocol stack: %v", err)
+	}
+	defer stack.Close()
+	db, err = stack.OpenDatabaseWithFreezer("chaindata", 0, 0, "", "", true)
+	if err != nil {
+		t.Fatalf("failed to reopen base database: %v", err)
+	}
+	if frozen, _ := db.Ancients(); frozen != 1 {
+		t.Errorf("base ancient count mismatch: have %d, want 1", frozen)
+	}
+	if value, _ := db.Get([]byte("key")); string(value) != "base" {
+		t.Errorf("base value mismatch: have %q, want %q", value, "base")
+	}
+}
+
+// dirContent returns the sizes of all files below a directory, by path.
+func dirContent(t *testing.T, dir string) map[string]int64 {
+	content := make(map[string]int64)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		content[path] = info.Size()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to list %s: %v", dir, err)
+	}
+	return content
+}
+
 // Tests whether a Lifecycle can be registered.
 func TestLifecycleRegistry_Successful(t *testing.T) {
 	stack, err := New(testNodeConfig())
@@ -625,6 +717,55 @@ func TestRegisterHandler_Unsuccessful(t *testing.T) {
 	node.RegisterHandler("test", "/test", handler)
 }
 
+// Tests that the standalone HTTP handler serves the unauthenticated APIs of the
+// node and stops serving once the node is closed.
+func TestHTTPHandler(t *testing.T) {
+	conf := testNodeConfig()
+	conf.HTTPModules = []string{"eth", "engine"}
+
+	node, err := New(conf)
+	if err != nil {
+		t.Fatalf("could not create new node: %v", err)
+	}
+	node.RegisterAPIs([]rpc.API{
+		{Namespace: "eth", Service: helloRPC("hello eth")},
+		{Namespace: "engine", Service: helloRPC("hello engine"), Authenticated: true},
+	})
+	if err := node.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+	handler, err := node.HTTPHandler()
+	if err != nil {
+		t.Fatalf("could not create handler: %v", err)
+	}
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	client, err := rpc.DialHTTP(srv.URL)
+	if err != nil {
+		t.Fatalf("could not dial handler: %v", err)
+	}
+	defer client.Close()
+
+	var res string
+	if err := client.Call(&res, "eth_helloWorld"); err != nil {
+		t.Fatalf("unauthenticated call failed: %v", err)
+	}
+	if res != "hello eth" {
+		t.Fatalf("result mismatch: have %q, want %q", res, "hello eth")
+	}
+	if err := client.Call(&res, "engine_helloWorld"); err == nil {
+		t.Fatalf("authenticated API served without authentication")
+	}
+	node.Close()
+	if err := client.Call(&res, "eth_helloWorld"); err == nil {
+		t.Fatalf("handler still serving after node was closed")
+	}
+	if _, err := node.HTTPHandler(); !errors.Is(err, ErrNodeStopped) {
+		t.Fatalf("handler creation error mismatch: have %v, want %v", err, ErrNodeStopped)
+	}
+}
+
 // Tests whether websocket requests can be handled on the same port as a regular http server.
 func TestWebsocketHTTPOnSamePort_WebsocketRequest(t *testing.T) {
 	node := startHTTP(t, 0, 0)
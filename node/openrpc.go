@@ -12,6 +12,7 @@ import (
 	"net"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -30,12 +31,86 @@ import (
 
 // RPCDiscoveryService defines a receiver type used for RPC discovery by reflection.
 type RPCDiscoveryService struct {
-	d *go_openrpc_reflect.Document
+	d          *go_openrpc_reflect.Document
+	aliases    map[string]string // Method aliases, mapped to the methods they resolve to
+	deprecated map[string]string // Deprecated methods, mapped to their replacements
+}
+
+// newRPCDiscoveryService creates the discovery service of the given document,
+// collecting the method aliases and deprecations declared by the APIs, which
+// can not be reflected from the receivers.
+func newRPCDiscoveryService(d *go_openrpc_reflect.Document, apis []rpc.API) *RPCDiscoveryService {
+	r := &RPCDiscoveryService{
+		d:          d,
+		aliases:    make(map[string]string),
+		deprecated: make(map[string]string),
+	}
+	for _, api := range apis {
+		for alias, method := range api.Aliases {
+			r.aliases[alias] = method
+		}
+		for method, replacement := range api.Deprecated {
+			r.deprecated[method] = replacement
+		}
+	}
+	return r
 }
 
 // Discover exposes a Discover method to the RPC receiver registration.
 func (r *RPCDiscoveryService) Discover() (*meta_schema.OpenrpcDocument, error) {
-	return r.d.Discover()
+	doc, err := r.d.Discover()
+	if err != nil || doc.Methods == nil {
+		return doc, err
+	}
+	methods := deprecateOpenRPCMethods(*doc.Methods, r.aliases, r.deprecated)
+	doc.Methods = &methods
+	return doc, nil
+}
+
+// deprecateOpenRPCMethods flags the deprecated methods of the document, and
+// appends the aliases as deprecated copies of the methods they resolve to.
+func deprecateOpenRPCMethods(methods meta_schema.Methods, aliases, deprecated map[string]string) meta_schema.Methods {
+	index := make(map[string]int, len(methods))
+	for i, method := range methods {
+		if method.Name != nil {
+			index[string(*method.Name)] = i
+		}
+	}
+	flag := func(method *meta_schema.MethodObject, replacement string) {
+		yes := meta_schema.MethodObjectDeprecated(true)
+		method.Deprecated = &yes
+		if replacement == "" {
+			return
+		}
+		notice := "Deprecated: use " + replacement + " instead."
+		if method.Description != nil && *method.Description != "" {
+			notice = string(*method.Description) + "\n\n" + notice
+		}
+		method.Description = (*meta_schema.MethodObjectDescription)(&notice)
+	}
+	for name, replacement := range deprecated {
+		if i, ok := index[name]; ok {
+			flag(&methods[i], replacement)
+		}
+	}
+	// Sort the aliases to keep the generated document stable
+	names := make([]string, 0, len(aliases))
+	for alias := range aliases {
+		names = append(names, alias)
+	}
+	sort.Strings(names)
+	for _, alias := range names {
+		i, ok := index[aliases[alias]]
+		if !ok {
+			continue
+		}
+		method := methods[i]
+		name := meta_schema.MethodObjectName(alias)
+		method.Name = &name
+		flag(&method, aliases[alias])
+		methods = append(methods, method)
+	}
+	return methods
 }
 
 // sharedMetaRegisterer defines common metadata to all possible servers.
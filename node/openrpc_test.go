@@ -3,6 +3,8 @@ package node
 import (
 	"reflect"
 	"testing"
+
+	meta_schema "github.com/open-rpc/meta-schema"
 )
 
 type TestReceiver struct{}
@@ -57,3 +59,45 @@ func TestEligibleReturnSignature(t *testing.T) {
 		}
 	}
 }
+
+func TestDeprecateOpenRPCMethods(t *testing.T) {
+	method := func(name, description string) meta_schema.MethodObject {
+		n := meta_schema.MethodObjectName(name)
+		d := meta_schema.MethodObjectDescription(description)
+		return meta_schema.MethodObject{Name: &n, Description: &d}
+	}
+	methods := meta_schema.Methods{
+		method("admin_ecbp1100", "Toggles ECBP1100."),
+		method("admin_peers", "Returns the peers."),
+		method("debug_legacy", ""),
+	}
+	aliases := map[string]string{"admin_mess": "admin_ecbp1100", "admin_missing": "admin_unknown"}
+	deprecated := map[string]string{"debug_legacy": "", "admin_unknown": ""}
+
+	got := deprecateOpenRPCMethods(methods, aliases, deprecated)
+	if len(got) != 4 {
+		t.Fatalf("wrong number of methods: got %d, want 4", len(got))
+	}
+	cases := []struct {
+		name        string
+		deprecated  bool
+		description string
+	}{
+		{"admin_ecbp1100", false, "Toggles ECBP1100."},
+		{"admin_peers", false, "Returns the peers."},
+		{"debug_legacy", true, ""},
+		{"admin_mess", true, "Toggles ECBP1100.\n\nDeprecated: use admin_ecbp1100 instead."},
+	}
+	for i, c := range cases {
+		m := got[i]
+		if string(*m.Name) != c.name {
+			t.Errorf("method %d: wrong name: got %s, want %s", i, *m.Name, c.name)
+		}
+		if (m.Deprecated != nil && bool(*m.Deprecated)) != c.deprecated {
+			t.Errorf("method %s: wrong deprecation flag, want %v", c.name, c.deprecated)
+		}
+		if string(*m.Description) != c.description {
+			t.Errorf("method %s: wrong description: got %q, want %q", c.name, *m.Description, c.description)
+		}
+	}
+}
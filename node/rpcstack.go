@@ -58,6 +58,8 @@ type rpcEndpointConfig struct {
 	batchItemLimit         int
 	batchResponseSizeLimit int
 	httpBodyLimit          int
+	lenientParams          bool // accept hex parameters lacking the 0x prefix
+	subscriptionLimit      int  // maximum number of subscriptions per connection
 }
 
 type rpcHandler struct {
@@ -74,6 +76,10 @@ type httpServer struct {
 	server   *http.Server
 	listener net.Listener // non-nil when server is running
 
+	// Listener passed in by socket activation, used once instead of listening
+	// on the configured endpoint.
+	activated net.Listener
+
 	// HTTP RPC handler things.
 
 	httpConfig  httpConfig
@@ -149,7 +155,16 @@ func (h *httpServer) start() error {
 	}
 
 	// Start the server.
-	listener, err := net.Listen("tcp", h.endpoint)
+	var (
+		listener net.Listener
+		err      error
+	)
+	if h.activated != nil {
+		h.log.Info("Using socket activated listener", "endpoint", h.endpoint, "addr", h.activated.Addr())
+		listener, h.activated = h.activated, nil
+	} else {
+		listener, err = net.Listen("tcp", h.endpoint)
+	}
 	if err != nil {
 		// If the server fails to start, we need to clear out the RPC and WS
 		// configuration so they can be configured another time.
@@ -309,6 +324,8 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 	if config.httpBodyLimit > 0 {
 		srv.SetHTTPBodyLimit(config.httpBodyLimit)
 	}
+	srv.SetLenientParams(config.lenientParams)
+	srv.SetSubscriptionLimit(config.subscriptionLimit)
 	if err := RegisterApis(apis, config.Modules, srv); err != nil {
 		return err
 	}
@@ -344,6 +361,8 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 	if config.httpBodyLimit > 0 {
 		srv.SetHTTPBodyLimit(config.httpBodyLimit)
 	}
+	srv.SetLenientParams(config.lenientParams)
+	srv.SetSubscriptionLimit(config.subscriptionLimit)
 	if err := RegisterApis(apis, config.Modules, srv); err != nil {
 		return err
 	}
@@ -587,10 +606,16 @@ func newGzipHandler(next http.Handler) http.Handler {
 type ipcServer struct {
 	log      log.Logger
 	endpoint string
+	resolver rpc.NameResolver // optional resolver of "@name" parameters
+	lenient  bool             // accept hex parameters lacking the 0x prefix
 
 	mu       sync.Mutex
 	listener net.Listener
 	srv      *rpc.Server
+
+	// Listener passed in by socket activation, used once instead of listening
+	// on the configured endpoint.
+	activated net.Listener
 }
 
 func newIPCServer(log log.Logger, endpoint string) *ipcServer {
@@ -605,11 +630,24 @@ func (is *ipcServer) start(apis []rpc.API) error {
 	if is.listener != nil {
 		return nil // already running
 	}
-	listener, srv, err := rpc.StartIPCEndpoint(is.endpoint, apis)
+	var (
+		listener net.Listener
+		srv      *rpc.Server
+		err      error
+	)
+	if is.activated != nil {
+		is.log.Info("Using socket activated listener", "url", is.endpoint, "addr", is.activated.Addr())
+		listener, is.activated = is.activated, nil
+		srv, err = rpc.StartIPCListener(listener, apis)
+	} else {
+		listener, srv, err = rpc.StartIPCEndpoint(is.endpoint, apis)
+	}
 	if err != nil {
 		is.log.Warn("IPC opening failed", "url", is.endpoint, "error", err)
 		return err
 	}
+	srv.SetNameResolver(is.resolver)
+	srv.SetLenientParams(is.lenient)
 	is.log.Info("IPC endpoint opened", "url", is.endpoint)
 	is.listener, is.srv = listener, srv
 	return nil
@@ -648,5 +686,13 @@ func RegisterApis(apis []rpc.API, modules []string, srv *rpc.Server) error {
 			}
 		}
 	}
+	// Register the method aliases and deprecations once all methods exist
+	for _, api := range apis {
+		if allowList[api.Namespace] || len(allowList) == 0 {
+			if err := srv.RegisterDeprecations(api); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
@@ -19,6 +19,7 @@ package discover
 import (
 	"fmt"
 	"net/netip"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/metrics"
 )
@@ -71,3 +72,55 @@ func (c *meteredUdpConn) WriteToUDP(b []byte, addr netip.AddrPort) (n int, err e
 	egressTrafficMeter.Mark(int64(n))
 	return n, err
 }
+
+// PacketStats contains the number of discovery packets sent and received since
+// startup, by packet type.
+type PacketStats struct {
+	Sent     map[string]uint64 `json:"sent"`
+	Received map[string]uint64 `json:"received"`
+}
+
+// packetCounter counts discovery packets by type. The zero value is ready to use.
+type packetCounter struct {
+	lock     sync.Mutex
+	sent     map[string]uint64
+	received map[string]uint64
+}
+
+func (c *packetCounter) countSent(name string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.sent == nil {
+		c.sent = make(map[string]uint64)
+	}
+	c.sent[name]++
+}
+
+func (c *packetCounter) countReceived(name string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.received == nil {
+		c.received = make(map[string]uint64)
+	}
+	c.received[name]++
+}
+
+// stats returns a copy of the current packet counts.
+func (c *packetCounter) stats() PacketStats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	stats := PacketStats{
+		Sent:     make(map[string]uint64, len(c.sent)),
+		Received: make(map[string]uint64, len(c.received)),
+	}
+	for name, n := range c.sent {
+		stats.Sent[name] = n
+	}
+	for name, n := range c.received {
+		stats.Received[name] = n
+	}
+	return stats
+}
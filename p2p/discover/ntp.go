@@ -48,6 +48,11 @@ func checkClockDrift() {
 	}
 }
 
+// ClockDrift measures the drift of the local clock against the NTP pool.
+func ClockDrift() (time.Duration, error) {
+	return sntpDrift(ntpChecks)
+}
+
 // sntpDrift does a naive time resolution against an NTP server and returns the
 // measured drift. This method uses the simple version of NTP. It's not precise
 // but should be fine for these purposes.
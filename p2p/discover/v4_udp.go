@@ -80,6 +80,7 @@ type UDPv4 struct {
 	gotreply        chan reply
 	closeCtx        context.Context
 	cancelCloseCtx  context.CancelFunc
+	stats           packetCounter
 }
 
 // replyMatcher represents a pending reply.
@@ -391,6 +392,11 @@ func (t *UDPv4) TableBuckets() [][]BucketNode {
 	return t.tab.Nodes()
 }
 
+// PacketStats returns the number of packets sent and received, by type.
+func (t *UDPv4) PacketStats() PacketStats {
+	return t.stats.stats()
+}
+
 // pending adds a reply matcher to the pending reply queue.
 // see the documentation of type replyMatcher for a detailed explanation.
 func (t *UDPv4) pending(id enode.ID, ip netip.Addr, ptype byte, callback replyMatchFunc) *replyMatcher {
@@ -523,6 +529,9 @@ func (t *UDPv4) send(toaddr netip.AddrPort, toid enode.ID, req v4wire.Packet) ([
 func (t *UDPv4) write(toaddr netip.AddrPort, toid enode.ID, what string, packet []byte) error {
 	_, err := t.conn.WriteToUDPAddrPort(packet, toaddr)
 	t.log.Trace(">> "+what, "id", toid, "addr", toaddr, "err", err)
+	if err == nil {
+		t.stats.countSent(what)
+	}
 	return err
 }
 
@@ -569,6 +578,7 @@ func (t *UDPv4) handlePacket(from netip.AddrPort, buf []byte) error {
 	}
 	packet := t.wrapPacket(rawpacket)
 	fromID := fromKey.ID()
+	t.stats.countReceived(packet.Name())
 	if err == nil && packet.preverify != nil {
 		err = packet.preverify(packet, from, fromID, fromKey)
 	}
@@ -451,6 +451,27 @@ func TestUDPv4_successfulPing(t *testing.T) {
 	}
 }
 
+// This test checks that sent and received packets are counted.
+func TestUDPv4_packetStats(t *testing.T) {
+	test := newUDPTest(t)
+	defer test.close()
+
+	go test.packetIn(nil, &v4wire.Ping{From: testRemote, To: testLocalAnnounced, Version: 4, Expiration: futureExp})
+	test.waitPacketOut(func(p *v4wire.Pong, to netip.AddrPort, hash []byte) {})
+	test.waitPacketOut(func(p *v4wire.Ping, to netip.AddrPort, hash []byte) {})
+
+	stats := test.udp.PacketStats()
+	if n := stats.Received["PING/v4"]; n != 1 {
+		t.Errorf("wrong received PING/v4 count: got %d, want 1", n)
+	}
+	if n := stats.Sent["PONG/v4"]; n != 1 {
+		t.Errorf("wrong sent PONG/v4 count: got %d, want 1", n)
+	}
+	if n := stats.Received["FINDNODE/v4"]; n != 0 {
+		t.Errorf("wrong received FINDNODE/v4 count: got %d, want 0", n)
+	}
+}
+
 // This test checks that EIP-868 requests work.
 func TestUDPv4_EIP868(t *testing.T) {
 	test := newUDPTest(t)
@@ -97,6 +97,8 @@ type UDPv5 struct {
 	closeCtx       context.Context
 	cancelCloseCtx context.CancelFunc
 	wg             sync.WaitGroup
+
+	stats packetCounter
 }
 
 type sendRequest struct {
@@ -225,6 +227,16 @@ func (t *UDPv5) Resolve(n *enode.Node) *enode.Node {
 	return n
 }
 
+// TableBuckets returns the contents of the table buckets.
+func (t *UDPv5) TableBuckets() [][]BucketNode {
+	return t.tab.Nodes()
+}
+
+// PacketStats returns the number of packets sent and received, by type.
+func (t *UDPv5) PacketStats() PacketStats {
+	return t.stats.stats()
+}
+
 // AllNodes returns all the nodes stored in the local table.
 func (t *UDPv5) AllNodes() []*enode.Node {
 	t.tab.mutex.Lock()
@@ -655,6 +667,9 @@ func (t *UDPv5) send(toID enode.ID, toAddr netip.AddrPort, packet v5wire.Packet,
 
 	_, err = t.conn.WriteToUDPAddrPort(enc, toAddr)
 	t.log.Trace(">> "+packet.Name(), t.logcontext...)
+	if err == nil {
+		t.stats.countSent(packet.Name())
+	}
 	return nonce, err
 }
 
@@ -714,6 +729,7 @@ func (t *UDPv5) handlePacket(rawpacket []byte, fromAddr netip.AddrPort) error {
 		// Handshake succeeded, add to table.
 		t.tab.addInboundNode(fromNode)
 	}
+	t.stats.countReceived(packet.Name())
 	if packet.Kind() != v5wire.WhoareyouPacket {
 		// WHOAREYOU logged separately to report errors.
 		t.logcontext = append(t.logcontext[:0], "id", fromID, "addr", addr)
@@ -18,11 +18,13 @@ package enode
 
 import (
 	"bytes"
+	"cmp"
 	"crypto/rand"
 	"encoding/binary"
 	"fmt"
 	"net/netip"
 	"os"
+	"slices"
 	"sync"
 	"time"
 
@@ -41,6 +43,7 @@ const (
 	dbVersionKey   = "version" // Version of the database to flush if changes
 	dbNodePrefix   = "n:"      // Identifier to prefix node entries with
 	dbLocalPrefix  = "local:"
+	dbPeerPrefix   = "peer:" // Identifier to prefix previously connected peers with
 	dbDiscoverRoot = "v4"
 	dbDiscv5Root   = "v5"
 
@@ -60,6 +63,8 @@ const (
 	dbNodeExpiration = 24 * time.Hour // Time after which an unseen node should be dropped.
 	dbCleanupCycle   = time.Hour      // Time period for running the expiration task.
 	dbVersion        = 9
+
+	dbPeerExpiration = 30 * 24 * time.Hour // Time after which a disconnected peer should be dropped.
 )
 
 var (
@@ -315,6 +320,7 @@ func (db *DB) expirer() {
 		select {
 		case <-tick.C:
 			db.expireNodes()
+			db.expirePeers()
 		case <-db.quit:
 			return
 		}
@@ -427,6 +433,91 @@ func (db *DB) UpdateFindFailsV5(id ID, ip netip.Addr, fails int) error {
 	return db.storeInt64(v5Key(id, ip, dbNodeFindFails), int64(fails))
 }
 
+// peerEntry is the connection history of a previously connected peer.
+type peerEntry struct {
+	Record   enr.Record
+	LastSeen uint64 // Unix time of the last disconnect
+	Uptime   uint64 // Total connection time in seconds
+	Sessions uint64
+}
+
+// peerKey returns the database key of a previously connected peer.
+func peerKey(id ID) []byte {
+	return append([]byte(dbPeerPrefix), id[:]...)
+}
+
+// UpdatePeerSession records a finished connection to the given node, adding the
+// session duration to the total time the node has been connected as a peer.
+func (db *DB) UpdatePeerSession(node *Node, duration time.Duration) error {
+	var entry peerEntry
+	if blob, err := db.lvl.Get(peerKey(node.ID()), nil); err == nil {
+		if err := rlp.DecodeBytes(blob, &entry); err != nil {
+			entry = peerEntry{}
+		}
+	}
+	entry.Record = node.r
+	entry.LastSeen = uint64(time.Now().Unix())
+	entry.Uptime += uint64(duration / time.Second)
+	entry.Sessions++
+
+	blob, err := rlp.EncodeToBytes(&entry)
+	if err != nil {
+		return err
+	}
+	return db.lvl.Put(peerKey(node.ID()), blob, nil)
+}
+
+// QueryPeers retrieves up to n previously connected peers, ordered by the total
+// time they were connected. Peers that were last seen more than maxAge ago, or
+// that were connected for less than minUptime in total, are skipped.
+func (db *DB) QueryPeers(n int, maxAge, minUptime time.Duration) []*Node {
+	type candidate struct {
+		node   *Node
+		uptime uint64
+	}
+	var (
+		threshold  = time.Now().Add(-maxAge).Unix()
+		candidates []candidate
+		it         = db.lvl.NewIterator(util.BytesPrefix([]byte(dbPeerPrefix)), nil)
+	)
+	defer it.Release()
+
+	for it.Next() {
+		var entry peerEntry
+		if err := rlp.DecodeBytes(it.Value(), &entry); err != nil {
+			continue
+		}
+		if int64(entry.LastSeen) < threshold || entry.Uptime < uint64(minUptime/time.Second) {
+			continue
+		}
+		id := ID(it.Key()[len(dbPeerPrefix):])
+		candidates = append(candidates, candidate{newNodeWithID(&entry.Record, id), entry.Uptime})
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return cmp.Compare(b.uptime, a.uptime)
+	})
+	nodes := make([]*Node, 0, min(n, len(candidates)))
+	for i := 0; i < len(candidates) && i < n; i++ {
+		nodes = append(nodes, candidates[i].node)
+	}
+	return nodes
+}
+
+// expirePeers deletes all previously connected peers that have not been
+// connected for some time.
+func (db *DB) expirePeers() {
+	it := db.lvl.NewIterator(util.BytesPrefix([]byte(dbPeerPrefix)), nil)
+	defer it.Release()
+
+	threshold := time.Now().Add(-dbPeerExpiration).Unix()
+	for it.Next() {
+		var entry peerEntry
+		if err := rlp.DecodeBytes(it.Value(), &entry); err != nil || int64(entry.LastSeen) < threshold {
+			db.lvl.Delete(it.Key(), nil)
+		}
+	}
+}
+
 // localSeq retrieves the local record sequence counter, defaulting to the current
 // timestamp if no previous exists. This ensures that wiping all data associated
 // with a node (apart from its key) will not generate already used sequence nums.
@@ -25,6 +25,8 @@ import (
 	"reflect"
 	"testing"
 	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 var keytestID = HexID("51232b8d7821617d2b29b54b81cdefb9b3e9c37d7fd5f63270bcc9e1a6f6a439")
@@ -470,3 +472,46 @@ func TestDBExpireV5(t *testing.T) {
 	db.UpdateFindFailsV5(ID{}, ip, 4)
 	db.expireNodes()
 }
+
+func TestDBPeerQuery(t *testing.T) {
+	db, _ := OpenDB("")
+	defer db.Close()
+
+	var (
+		short  = nodeDBSeedQueryNodes[0].node
+		medium = nodeDBSeedQueryNodes[1].node
+		long   = nodeDBSeedQueryNodes[2].node
+	)
+	db.UpdatePeerSession(short, 10*time.Second)
+	db.UpdatePeerSession(medium, 2*time.Minute)
+	db.UpdatePeerSession(long, 3*time.Minute)
+	db.UpdatePeerSession(medium, 2*time.Minute)
+
+	// The short-lived peer is filtered, the others are ranked by total uptime.
+	peers := db.QueryPeers(10, time.Hour, time.Minute)
+	if len(peers) != 2 {
+		t.Fatalf("wrong number of peers: have %d, want 2", len(peers))
+	}
+	if peers[0].ID() != medium.ID() || peers[1].ID() != long.ID() {
+		t.Errorf("wrong peer order: have %v, %v", peers[0].ID(), peers[1].ID())
+	}
+	if !reflect.DeepEqual(peers[0].IPAddr(), medium.IPAddr()) || peers[0].TCP() != medium.TCP() {
+		t.Errorf("wrong endpoint for peer: have %v:%d", peers[0].IPAddr(), peers[0].TCP())
+	}
+	if peers := db.QueryPeers(1, time.Hour, 0); len(peers) != 1 || peers[0].ID() != medium.ID() {
+		t.Errorf("wrong peers for limited query: %v", peers)
+	}
+
+	// Expiration removes all peers that weren't seen recently.
+	db.expirePeers()
+	if peers := db.QueryPeers(10, time.Hour, 0); len(peers) != 3 {
+		t.Errorf("wrong number of peers after expiration: have %d, want 3", len(peers))
+	}
+	entry := peerEntry{Record: short.r, LastSeen: uint64(time.Now().Add(-2 * dbPeerExpiration).Unix())}
+	blob, _ := rlp.EncodeToBytes(&entry)
+	db.lvl.Put(peerKey(short.ID()), blob, nil)
+	db.expirePeers()
+	if peers := db.QueryPeers(10, 10*dbPeerExpiration, 0); len(peers) != 2 {
+		t.Errorf("wrong number of peers after expiring old peer: have %d, want 2", len(peers))
+	}
+}
@@ -39,6 +39,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/ethereum/go-ethereum/p2p/nat"
 	"github.com/ethereum/go-ethereum/p2p/netutil"
+	"github.com/ethereum/go-ethereum/rlp"
 	"golang.org/x/exp/slices"
 )
 
@@ -63,6 +64,12 @@ const (
 
 	// Maximum amount of time allowed for writing a complete message.
 	frameWriteTimeout = 20 * time.Second
+
+	// Previously connected peers are dialed on startup if they were connected
+	// within peerHistoryMaxAge and for at least peerHistoryMinUptime in total.
+	peerBackfillCount    = 50
+	peerHistoryMaxAge    = 7 * 24 * time.Hour
+	peerHistoryMinUptime = 5 * time.Minute
 )
 
 var (
@@ -130,6 +137,10 @@ type Config struct {
 	// live nodes in the network.
 	NodeDatabase string `toml:",omitempty"`
 
+	// PinnedPeers is the number of most useful peers of previous runs, ranked
+	// by their total connection time, that are maintained like static nodes.
+	PinnedPeers int `toml:",omitempty"`
+
 	// Protocols should contain the protocols supported
 	// by the server. Matching protocols are launched for
 	// each peer.
@@ -401,6 +412,56 @@ func (srv *Server) Self() *enode.Node {
 	return ln.Node()
 }
 
+// reservedRecordKeys are the node record entries maintained by the server and
+// the discovery protocols.
+var reservedRecordKeys = map[string]bool{
+	"id": true, "secp256k1": true,
+	"ip": true, "ip6": true,
+	"tcp": true, "tcp6": true,
+	"udp": true, "udp6": true,
+}
+
+// SetRecordEntry sets a custom entry in the local node record, or removes it if
+// the value is empty. The value must be a single RLP encoded item. Entries that
+// are maintained by the server or its protocols can't be modified. The updated
+// record is re-signed and propagated through discovery.
+func (srv *Server) SetRecordEntry(key string, value []byte) error {
+	srv.lock.Lock()
+	ln := srv.localnode
+	srv.lock.Unlock()
+
+	if ln == nil {
+		return errServerStopped
+	}
+	if reservedRecordKeys[key] {
+		return fmt.Errorf("record entry %q is managed by the server", key)
+	}
+	for _, p := range srv.Protocols {
+		for _, e := range p.Attributes {
+			if e.ENRKey() == key {
+				return fmt.Errorf("record entry %q is managed by protocol %s", key, p.Name)
+			}
+		}
+	}
+	if len(value) == 0 {
+		ln.Delete(enr.WithEntry(key, nil))
+		return nil
+	}
+	if _, _, rest, err := rlp.Split(value); err != nil || len(rest) != 0 {
+		return errors.New("record entry value is not a single RLP item")
+	}
+	entry := enr.WithEntry(key, rlp.RawValue(value))
+
+	// Make sure the updated record can still be signed, i.e. it's not too large
+	record := *ln.Node().Record()
+	record.Set(entry)
+	if err := enode.SignV4(&record, srv.PrivateKey); err != nil {
+		return err
+	}
+	ln.Set(entry)
+	return nil
+}
+
 // DiscoveryV4 returns the discovery v4 instance, if configured.
 func (srv *Server) DiscoveryV4() *discover.UDPv4 {
 	return srv.discv4
@@ -600,6 +661,13 @@ func (srv *Server) setupDiscovery() error {
 			added[proto.Name] = true
 		}
 	}
+	// Add the previously connected peers, best first, so that connections are
+	// made quickly even while the discovery table is still being filled.
+	backfill := srv.nodedb.QueryPeers(peerBackfillCount, peerHistoryMaxAge, peerHistoryMinUptime)
+	if len(backfill) > 0 {
+		srv.log.Debug("Backfilling dial candidates from node database", "count", len(backfill))
+		srv.discmix.AddSource(enode.IterNodes(backfill))
+	}
 	return nil
 }
 
@@ -623,6 +691,13 @@ func (srv *Server) setupDialScheduler() {
 	for _, n := range srv.StaticNodes {
 		srv.dialsched.addStatic(n)
 	}
+	if srv.PinnedPeers > 0 {
+		pinned := srv.nodedb.QueryPeers(srv.PinnedPeers, peerHistoryMaxAge, peerHistoryMinUptime)
+		for _, n := range pinned {
+			srv.dialsched.addStatic(n)
+		}
+		srv.log.Info("Pinned previously connected peers", "count", len(pinned))
+	}
 }
 
 func (srv *Server) maxInboundConns() int {
@@ -797,6 +872,7 @@ running:
 			delete(peers, pd.ID())
 			srv.log.Debug("Removing p2p peer", "peercount", len(peers), "id", pd.ID(), "duration", d, "req", pd.requested, "err", pd.err)
 			srv.dialsched.peerRemoved(pd.rw)
+			srv.recordPeerSession(pd.Peer)
 			if pd.Inbound() {
 				inboundCount--
 				activeInboundPeerGauge.Dec(1)
@@ -826,10 +902,24 @@ running:
 	for len(peers) > 0 {
 		p := <-srv.delpeer
 		p.log.Trace("<-delpeer (spindown)")
+		srv.recordPeerSession(p.Peer)
 		delete(peers, p.ID())
 	}
 }
 
+// recordPeerSession stores the connection time of a dialed peer in the node
+// database, so useful peers can be reconnected quickly after a restart. Inbound
+// peers are not recorded as their listening endpoint is unknown.
+func (srv *Server) recordPeerSession(p *Peer) {
+	if p.Inbound() {
+		return
+	}
+	duration := time.Duration(mclock.Now() - p.created)
+	if err := srv.nodedb.UpdatePeerSession(p.Node(), duration); err != nil {
+		srv.log.Debug("Failed to record peer session", "id", p.ID(), "err", err)
+	}
+}
+
 func (srv *Server) postHandshakeChecks(peers map[enode.ID]*Peer, inboundCount int, c *conn) error {
 	switch {
 	case !c.is(trustedConn) && len(peers) >= srv.MaxPeers:
@@ -35,6 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/ethereum/go-ethereum/p2p/rlpx"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 type testTransport struct {
@@ -90,6 +91,59 @@ func startTestServer(t *testing.T, remoteKey *ecdsa.PublicKey, pf func(*Peer)) *
 	return server
 }
 
+// Tests that custom node record entries can be set and removed, while entries
+// maintained by the server and its protocols are protected.
+func TestServerSetRecordEntry(t *testing.T) {
+	srv := &Server{
+		Config: Config{
+			PrivateKey:  newkey(),
+			MaxPeers:    10,
+			NoDial:      true,
+			NoDiscovery: true,
+			Protocols:   []Protocol{{Name: "test", Attributes: []enr.Entry{enr.WithEntry("test", uint(1))}}},
+			Logger:      testlog.Logger(t, log.LvlTrace),
+		},
+	}
+	if err := srv.SetRecordEntry("geo", []byte{0x80}); err != errServerStopped {
+		t.Fatalf("stopped server: have %v, want %v", err, errServerStopped)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("could not start server: %v", err)
+	}
+	defer srv.Stop()
+
+	seq := srv.Self().Seq()
+	value, _ := rlp.EncodeToBytes("eu-west")
+	if err := srv.SetRecordEntry("geo", value); err != nil {
+		t.Fatalf("failed to set entry: %v", err)
+	}
+	var geo string
+	if err := srv.Self().Load(enr.WithEntry("geo", &geo)); err != nil || geo != "eu-west" {
+		t.Fatalf("entry mismatch: have %q (err %v), want %q", geo, err, "eu-west")
+	}
+	if srv.Self().Seq() <= seq {
+		t.Fatalf("sequence number not increased")
+	}
+	for _, key := range []string{"id", "ip", "udp", "test"} {
+		if err := srv.SetRecordEntry(key, value); err == nil {
+			t.Errorf("managed entry %q modified", key)
+		}
+	}
+	if err := srv.SetRecordEntry("bad", []byte{0x82, 0x01}); err == nil {
+		t.Errorf("invalid RLP accepted")
+	}
+	large, _ := rlp.EncodeToBytes(make([]byte, 300))
+	if err := srv.SetRecordEntry("large", large); err == nil {
+		t.Errorf("oversized record accepted")
+	}
+	if err := srv.SetRecordEntry("geo", nil); err != nil {
+		t.Fatalf("failed to delete entry: %v", err)
+	}
+	if err := srv.Self().Load(enr.WithEntry("geo", &geo)); !enr.IsNotFound(err) {
+		t.Fatalf("entry not deleted: %v", err)
+	}
+}
+
 func TestServerListen(t *testing.T) {
 	// start the test server
 	connected := make(chan *Peer)
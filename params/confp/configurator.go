@@ -173,6 +173,20 @@ func IsValid(conf ctypes.ChainConfigurator, head *uint64) *ConfigValidError {
 	if conf.GetNetworkID() == nil {
 		return NewValidErr("NetworkID cannot be nil", "!=nil", conf.GetNetworkID())
 	}
+	// The BLS12-381 precompiles start at the address of the KZG point evaluation
+	// precompile, so the two can't be enabled on the same chain.
+	bls := conf.GetEIP2537Transition() != nil || conf.GetEIP2537TransitionTime() != nil
+	kzg := conf.GetEIP4844Transition() != nil || conf.GetEIP4844TransitionTime() != nil
+	if bls && kzg {
+		return NewValidErr("EIP2537 and EIP4844 precompiles collide at address 0x0a. A:EIP2537/B:EIP4844", "enabled", "enabled")
+	}
+	if ctypes.MaxInitCodeSize(conf) < ctypes.MaxCodeSize(conf) {
+		return NewValidErr("MaxInitCodeSize must not be below MaxCodeSize. A:MaxInitCodeSize/B:MaxCodeSize", ctypes.MaxInitCodeSize(conf), ctypes.MaxCodeSize(conf))
+	}
+	// Bound the init code size, so that its gas cost can't overflow
+	if ctypes.MaxInitCodeSize(conf) > math.MaxUint32 {
+		return NewValidErr("MaxInitCodeSize too large", "<=MaxUint32", ctypes.MaxInitCodeSize(conf))
+	}
 	if head == nil {
 		return nil
 	}
@@ -262,6 +276,15 @@ func compatible(headBlock *big.Int, headTime *uint64, a, b ctypes.ChainConfigura
 				return newBlockCompatError("mismatching chain ids after EIP155 transition", tai, tbi)
 			}
 		}
+		// Contract size limits can't be changed once enforced
+		if a.IsEnabled(a.GetEIP170Transition, headBlock) && ctypes.MaxCodeSize(a) != ctypes.MaxCodeSize(b) {
+			t := new(big.Int).SetUint64(*a.GetEIP170Transition())
+			return newBlockCompatError("mismatching max code size after EIP170 transition", t, t)
+		}
+		if a.IsEnabled(a.GetEIP3860Transition, headBlock) && ctypes.MaxInitCodeSize(a) != ctypes.MaxInitCodeSize(b) {
+			t := new(big.Int).SetUint64(*a.GetEIP3860Transition())
+			return newBlockCompatError("mismatching max init code size after EIP3860 transition", t, t)
+		}
 	}
 
 	// Handle forks by time.
@@ -288,6 +311,10 @@ func compatible(headBlock *big.Int, headTime *uint64, a, b ctypes.ChainConfigura
 				return err
 			}
 		}
+		if a.IsEnabledByTime(a.GetEIP3860TransitionTime, headTime) && ctypes.MaxInitCodeSize(a) != ctypes.MaxInitCodeSize(b) {
+			t := a.GetEIP3860TransitionTime()
+			return newTimestampCompatError("mismatching max init code size after EIP3860 transition", t, t)
+		}
 	}
 
 	return nil
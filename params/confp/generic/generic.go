@@ -58,6 +58,7 @@ var (
 		"requireBlockHashes", "config.requireBlockHashes",
 		"eip2FBlock", "config.eip2FBlock",
 		"supportedProtocolVersions", "config.supportedProtocolVersions",
+		"config.engine",
 	}
 	// Fields unknown for etclabscore/core-geth.
 	coregethSchemaMustNot = []string{
@@ -75,7 +76,7 @@ var (
 	}
 	// Fields unknown to ethereum/go-ethereum.
 	goethereumSchemaMustNot = []string{
-		"engine",
+		"engine", "config.engine",
 		"genesis.seal",
 		"networkId", "config.networkId",
 	}
@@ -17,11 +17,15 @@
 package coregeth
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/params/confp"
 	"github.com/ethereum/go-ethereum/params/types/ctypes"
 )
@@ -40,6 +44,10 @@ type CoreGethChainConfig struct {
 	ChainID                   *big.Int `json:"chainId"`                             // chainId identifies the current chain and is used for replay protection
 	SupportedProtocolVersions []uint   `json:"supportedProtocolVersions,omitempty"` // supportedProtocolVersions identifies the supported eth protocol versions for the current chain
 
+	// Contract size limits enforced from EIP-170 and EIP-3860 on (nil = protocol default).
+	MaxCodeSize     *uint64 `json:"maxCodeSize,omitempty"`
+	MaxInitCodeSize *uint64 `json:"maxInitCodeSize,omitempty"`
+
 	// HF: Homestead
 	// HomesteadBlock *big.Int `json:"homesteadBlock,omitempty"` // Homestead switch block (nil = no fork, 0 = already homestead)
 	// "Homestead Hard-fork Changes"
@@ -241,6 +249,9 @@ type CoreGethChainConfig struct {
 	EIP6780FTime *uint64 `json:"eip6780FTime,omitempty"` // EIP-6780: SELFDESTRUCT only in same transaction https://eips.ethereum.org/EIPS/eip-6780
 	EIP4788FTime *uint64 `json:"eip4788FTime,omitempty"` // EIP-4788: Beacon block root in the EVM https://eips.ethereum.org/EIPS/eip-4788
 
+	// EIP-2537: BLS12-381 curve operations, activated by timestamp
+	EIP2537FTime *uint64 `json:"eip2537FTime,omitempty"`
+
 	// Cancun with block activations
 	EIP4844FBlock *big.Int `json:"eip4844FBlock,omitempty"` // EIP-4844: Shard Blob Transactions https://eips.ethereum.org/EIPS/eip-4844
 	EIP7516FBlock *big.Int `json:"eip7516FBlock,omitempty"` // EIP-7516: Blob Base Fee Opcode https://eips.ethereum.org/EIPS/eip-7516
@@ -309,3 +320,110 @@ func (c *CoreGethChainConfig) String() string {
 	}
 	return str
 }
+
+// Names accepted by the "engine" field of a chain config, which selects the
+// consensus engine for networks not built into the client.
+const (
+	EngineNameEthash  = "ethash"
+	EngineNameEtchash = "etchash"
+	EngineNameClique  = "clique"
+	EngineNameLyra2   = "lyra2"
+)
+
+// etchashEngineParams are the "engineParams" understood by the etchash engine.
+type etchashEngineParams struct {
+	ECIP1099Block *math.HexOrDecimal256 `json:"ecip1099Block,omitempty"`
+}
+
+// UnmarshalJSON decodes the chain config, additionally resolving the optional
+// "engine" and "engineParams" fields into the matching consensus engine config.
+func (c *CoreGethChainConfig) UnmarshalJSON(input []byte) error {
+	type chainConfig CoreGethChainConfig
+	dec := struct {
+		*chainConfig
+		Engine       string          `json:"engine,omitempty"`
+		EngineParams json.RawMessage `json:"engineParams,omitempty"`
+	}{chainConfig: (*chainConfig)(c)}
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	if dec.Engine == "" {
+		if len(dec.EngineParams) > 0 {
+			return errors.New("engineParams given without engine")
+		}
+		return nil
+	}
+	return c.setEngine(dec.Engine, dec.EngineParams)
+}
+
+// setEngine configures the consensus engine by name, decoding any
+// engine-specific parameters. An engine section already present in the
+// config must agree with the named engine.
+func (c *CoreGethChainConfig) setEngine(name string, params json.RawMessage) error {
+	decodeParams := func(v interface{}) error {
+		if len(params) == 0 {
+			return nil
+		}
+		d := json.NewDecoder(bytes.NewReader(params))
+		d.DisallowUnknownFields()
+		if err := d.Decode(v); err != nil {
+			return fmt.Errorf("invalid %s engineParams: %w", name, err)
+		}
+		return nil
+	}
+	var want ctypes.ConsensusEngineT
+	switch name {
+	case EngineNameEthash, EngineNameEtchash:
+		want = ctypes.ConsensusEngineT_Ethash
+	case EngineNameClique:
+		want = ctypes.ConsensusEngineT_Clique
+	case EngineNameLyra2:
+		want = ctypes.ConsensusEngineT_Lyra2
+	default:
+		return fmt.Errorf("unsupported consensus engine %q", name)
+	}
+	if have := c.GetConsensusEngineType(); !have.IsUnknown() && have != want {
+		return fmt.Errorf("engine %q conflicts with configured %s engine", name, have)
+	}
+
+	switch name {
+	case EngineNameEthash:
+		if err := decodeParams(&struct{}{}); err != nil {
+			return err
+		}
+		if c.Ethash == nil {
+			c.Ethash = new(ctypes.EthashConfig)
+		}
+	case EngineNameEtchash:
+		var p etchashEngineParams
+		if err := decodeParams(&p); err != nil {
+			return err
+		}
+		if c.Ethash == nil {
+			c.Ethash = new(ctypes.EthashConfig)
+		}
+		// Etchash is ethash with the ECIP-1099 epoch length. Unless told
+		// otherwise, the network uses it from genesis onwards.
+		switch {
+		case p.ECIP1099Block != nil:
+			c.ECIP1099FBlock = (*big.Int)(p.ECIP1099Block)
+		case c.ECIP1099FBlock == nil:
+			c.ECIP1099FBlock = new(big.Int)
+		}
+	case EngineNameClique:
+		if c.Clique == nil {
+			c.Clique = new(ctypes.CliqueConfig)
+		}
+		if err := decodeParams(c.Clique); err != nil {
+			return err
+		}
+	case EngineNameLyra2:
+		if err := decodeParams(&struct{}{}); err != nil {
+			return err
+		}
+		if c.Lyra2 == nil {
+			c.Lyra2 = new(ctypes.Lyra2Config)
+		}
+	}
+	return nil
+}
@@ -135,10 +135,19 @@ func (c *CoreGethChainConfig) SetSupportedProtocolVersions(p []uint) error {
 }
 
 func (c *CoreGethChainConfig) GetMaxCodeSize() *uint64 {
-	return internal.GlobalConfigurator().GetMaxCodeSize()
+	return c.MaxCodeSize
 }
 func (c *CoreGethChainConfig) SetMaxCodeSize(n *uint64) error {
-	return internal.GlobalConfigurator().SetMaxCodeSize(n)
+	c.MaxCodeSize = n
+	return nil
+}
+
+func (c *CoreGethChainConfig) GetMaxInitCodeSize() *uint64 {
+	return c.MaxInitCodeSize
+}
+func (c *CoreGethChainConfig) SetMaxInitCodeSize(n *uint64) error {
+	c.MaxInitCodeSize = n
+	return nil
 }
 
 func (c *CoreGethChainConfig) GetElasticityMultiplier() uint64 {
@@ -627,6 +636,16 @@ func (c *CoreGethChainConfig) SetEIP6049Transition(n *uint64) error {
 	return nil
 }
 
+// GetEIP2537TransitionTime EIP2537: BLS12-381 curve operations
+func (c *CoreGethChainConfig) GetEIP2537TransitionTime() *uint64 {
+	return c.EIP2537FTime
+}
+
+func (c *CoreGethChainConfig) SetEIP2537TransitionTime(n *uint64) error {
+	c.EIP2537FTime = n
+	return nil
+}
+
 // GetEIP4844TransitionTime EIP4844: Shard Blob Transactions
 func (c *CoreGethChainConfig) GetEIP4844TransitionTime() *uint64 {
 	return c.EIP4844FTime
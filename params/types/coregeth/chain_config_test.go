@@ -1,7 +1,9 @@
 package coregeth
 
 import (
+	"encoding/json"
 	"math/big"
+	"reflect"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -89,3 +91,49 @@ func TestCoreGethChainConfig_ECBP1100Deactivate(t *testing.T) {
 		t.Errorf("ECBP1100 should be deactivated at block %d", n)
 	}
 }
+
+func TestCoreGethChainConfig_UnmarshalEngine(t *testing.T) {
+	tests := []struct {
+		input    string
+		engine   ctypes.ConsensusEngineT
+		ecip1099 *uint64
+		clique   *ctypes.CliqueConfig
+		wantErr  bool
+	}{
+		{input: `{"networkId": 1, "engine": "ethash"}`, engine: ctypes.ConsensusEngineT_Ethash},
+		{input: `{"networkId": 1, "engine": "etchash"}`, engine: ctypes.ConsensusEngineT_Ethash, ecip1099: newU64(0)},
+		{input: `{"networkId": 1, "engine": "etchash", "engineParams": {"ecip1099Block": 100}}`, engine: ctypes.ConsensusEngineT_Ethash, ecip1099: newU64(100)},
+		{input: `{"networkId": 1, "engine": "clique", "engineParams": {"period": 5, "epoch": 100}}`, engine: ctypes.ConsensusEngineT_Clique, clique: &ctypes.CliqueConfig{Period: 5, Epoch: 100}},
+		{input: `{"networkId": 1, "engine": "clique", "clique": {"period": 15}}`, engine: ctypes.ConsensusEngineT_Clique, clique: &ctypes.CliqueConfig{Period: 15}},
+		{input: `{"networkId": 1, "engine": "lyra2"}`, engine: ctypes.ConsensusEngineT_Lyra2},
+		{input: `{"networkId": 1, "clique": {"period": 15}}`, engine: ctypes.ConsensusEngineT_Clique, clique: &ctypes.CliqueConfig{Period: 15}},
+
+		{input: `{"networkId": 1, "engine": "aura"}`, wantErr: true},
+		{input: `{"networkId": 1, "engine": "ethash", "clique": {}}`, wantErr: true},
+		{input: `{"networkId": 1, "engine": "clique", "engineParams": {"period": 5, "blocks": 1}}`, wantErr: true},
+		{input: `{"networkId": 1, "engine": "lyra2", "engineParams": {"foo": 1}}`, wantErr: true},
+		{input: `{"networkId": 1, "engineParams": {"period": 5}}`, wantErr: true},
+	}
+	for i, tt := range tests {
+		var c CoreGethChainConfig
+		err := json.Unmarshal([]byte(tt.input), &c)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("test %d: expected error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("test %d: unexpected error: %v", i, err)
+		}
+		if have := c.GetConsensusEngineType(); have != tt.engine {
+			t.Errorf("test %d: engine mismatch: have %v, want %v", i, have, tt.engine)
+		}
+		if have := c.GetEthashECIP1099Transition(); !reflect.DeepEqual(have, tt.ecip1099) {
+			t.Errorf("test %d: ECIP-1099 transition mismatch: have %v, want %v", i, have, tt.ecip1099)
+		}
+		if !reflect.DeepEqual(c.Clique, tt.clique) {
+			t.Errorf("test %d: clique config mismatch: have %+v, want %+v", i, c.Clique, tt.clique)
+		}
+	}
+}
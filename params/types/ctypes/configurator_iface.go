@@ -67,8 +67,13 @@ type ProtocolSpecifier interface {
 	SetChainID(i *big.Int) error
 	GetSupportedProtocolVersions() []uint
 	SetSupportedProtocolVersions(p []uint) error
+	// GetMaxCodeSize and GetMaxInitCodeSize return the contract size limits of
+	// EIP-170 and EIP-3860, nil meaning the protocol defaults. Use the MaxCodeSize
+	// and MaxInitCodeSize helpers to resolve the effective limits.
 	GetMaxCodeSize() *uint64
 	SetMaxCodeSize(n *uint64) error
+	GetMaxInitCodeSize() *uint64
+	SetMaxInitCodeSize(n *uint64) error
 
 	GetElasticityMultiplier() uint64
 	SetElasticityMultiplier(n uint64) error
@@ -257,6 +262,11 @@ type ProtocolSpecifier interface {
 	GetEIP4788TransitionTime() *uint64
 	SetEIP4788TransitionTime(n *uint64) error
 
+	// GetEIP2537TransitionTime implements EIP2537 - Precompile for BLS12-381 curve operations - https://eips.ethereum.org/EIPS/eip-2537
+	// It is not scheduled on any public network, but may be enabled by private ones.
+	GetEIP2537TransitionTime() *uint64
+	SetEIP2537TransitionTime(n *uint64) error
+
 	// Cancun expressed as block activation numbers:
 
 	GetEIP4844Transition() *uint64
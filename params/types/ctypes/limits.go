@@ -0,0 +1,42 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ctypes
+
+import "github.com/ethereum/go-ethereum/params/vars"
+
+// MaxCodeSize returns the maximum contract code size of the chain, enforced
+// from EIP-170 on, falling back to the protocol default if not configured.
+func MaxCodeSize(c ChainConfigurator) uint64 {
+	if c != nil {
+		if n := c.GetMaxCodeSize(); n != nil {
+			return *n
+		}
+	}
+	return vars.MaxCodeSize
+}
+
+// MaxInitCodeSize returns the maximum contract init code size of the chain,
+// enforced from EIP-3860 on. If not configured, it is twice the maximum code
+// size, as in the protocol default.
+func MaxInitCodeSize(c ChainConfigurator) uint64 {
+	if c != nil {
+		if n := c.GetMaxInitCodeSize(); n != nil {
+			return *n
+		}
+	}
+	return 2 * MaxCodeSize(c)
+}
@@ -30,6 +30,7 @@ func (g Genesis) MarshalJSON() ([]byte, error) {
 		Mixhash       common.Hash                                 `json:"mixHash"`
 		Coinbase      common.Address                              `json:"coinbase"`
 		Alloc         map[common.UnprefixedAddress]GenesisAccount `json:"alloc"      gencodec:"required"`
+		StateLayout   string                                      `json:"stateLayout,omitempty"`
 		Number        math.HexOrDecimal64                         `json:"number"`
 		GasUsed       math.HexOrDecimal64                         `json:"gasUsed"`
 		ParentHash    common.Hash                                 `json:"parentHash"`
@@ -52,6 +53,7 @@ func (g Genesis) MarshalJSON() ([]byte, error) {
 			enc.Alloc[common.UnprefixedAddress(k)] = v
 		}
 	}
+	enc.StateLayout = g.StateLayout
 	enc.Number = math.HexOrDecimal64(g.Number)
 	enc.GasUsed = math.HexOrDecimal64(g.GasUsed)
 	enc.ParentHash = g.ParentHash
@@ -73,6 +75,7 @@ func (g *Genesis) UnmarshalJSON(input []byte) error {
 		Mixhash       *common.Hash                                `json:"mixHash"`
 		Coinbase      *common.Address                             `json:"coinbase"`
 		Alloc         map[common.UnprefixedAddress]GenesisAccount `json:"alloc"      gencodec:"required"`
+		StateLayout   *string                                     `json:"stateLayout,omitempty"`
 		Number        *math.HexOrDecimal64                        `json:"number"`
 		GasUsed       *math.HexOrDecimal64                        `json:"gasUsed"`
 		ParentHash    *common.Hash                                `json:"parentHash"`
@@ -133,6 +136,9 @@ func (g *Genesis) UnmarshalJSON(input []byte) error {
 	for k, v := range dec.Alloc {
 		g.Alloc[common.Address(k)] = v
 	}
+	if dec.StateLayout != nil {
+		g.StateLayout = *dec.StateLayout
+	}
 	if dec.Number != nil {
 		g.Number = uint64(*dec.Number)
 	}
@@ -50,6 +50,10 @@ type Genesis struct {
 	Coinbase   common.Address           `json:"coinbase"`
 	Alloc      GenesisAlloc             `json:"alloc"      gencodec:"required"`
 
+	// StateLayout selects an experimental state commitment layout for private
+	// networks, "binary" for binary tries. Empty means merkle-patricia tries.
+	StateLayout string `json:"stateLayout,omitempty"`
+
 	// These fields are used for consensus tests. Please don't use them
 	// in actual genesis blocks.
 	Number        uint64      `json:"number"`
@@ -156,6 +160,14 @@ func (g *Genesis) SetEIP6049Transition(n *uint64) error {
 	return g.Config.SetEIP6049Transition(n)
 }
 
+func (g *Genesis) GetEIP2537TransitionTime() *uint64 {
+	return g.Config.GetEIP2537TransitionTime()
+}
+
+func (g *Genesis) SetEIP2537TransitionTime(n *uint64) error {
+	return g.Config.SetEIP2537TransitionTime(n)
+}
+
 func (g *Genesis) GetEIP4844TransitionTime() *uint64 {
 	return g.Config.GetEIP4844TransitionTime()
 }
@@ -274,6 +286,12 @@ func (g *Genesis) IsVerkle() bool {
 	return g.IsEnabledByTime(g.GetVerkleTransitionTime, &g.Timestamp) || g.IsEnabled(g.GetVerkleTransition, new(big.Int).SetUint64(g.Number))
 }
 
+// IsBinaryTrie returns whether the genesis selects the experimental binary
+// trie state layout.
+func (g *Genesis) IsBinaryTrie() bool {
+	return g.StateLayout == "binary"
+}
+
 func (g *Genesis) IsEnabledByTime(fn func() *uint64, n *uint64) bool {
 	return g.Config.IsEnabledByTime(fn, n)
 }
@@ -568,6 +586,14 @@ func (g *Genesis) SetMaxCodeSize(n *uint64) error {
 	return g.Config.SetMaxCodeSize(n)
 }
 
+func (g *Genesis) GetMaxInitCodeSize() *uint64 {
+	return g.Config.GetMaxInitCodeSize()
+}
+
+func (g *Genesis) SetMaxInitCodeSize(n *uint64) error {
+	return g.Config.SetMaxInitCodeSize(n)
+}
+
 func (g *Genesis) GetEIP7Transition() *uint64 {
 	return g.Config.GetEIP7Transition()
 }
@@ -30,6 +30,10 @@ type ChainConfig struct {
 	ChainID                   *big.Int `json:"chainId"`                             // chainId identifies the current chain and is used for replay protection
 	SupportedProtocolVersions []uint   `json:"supportedProtocolVersions,omitempty"` // supportedProtocolVersions identifies the supported eth protocol versions for the current chain
 
+	// Contract size limits enforced from EIP-170 and EIP-3860 on (nil = protocol default).
+	MaxCodeSize     *uint64 `json:"maxCodeSize,omitempty"`
+	MaxInitCodeSize *uint64 `json:"maxInitCodeSize,omitempty"`
+
 	// HF: Homestead
 	HomesteadBlock *big.Int `json:"homesteadBlock,omitempty"` // Homestead switch block (nil = no fork, 0 = already homestead)
 
@@ -98,6 +102,11 @@ type ChainConfig struct {
 	EIP1706Transition  *big.Int `json:"-"`
 	ECIP1080Transition *big.Int `json:"-"`
 
+	// EIP-2537 BLS12-381 precompiles, not scheduled on any public network but
+	// available to private ones by block number or timestamp.
+	EIP2537Block *big.Int `json:"eip2537Block,omitempty"`
+	EIP2537Time  *uint64  `json:"eip2537Time,omitempty"`
+
 	// Cache types for use with testing, but will not show up in config API.
 	ecbp1100Transition           *big.Int
 	ecbp1100DeactivateTransition *big.Int
@@ -169,11 +169,21 @@ func (c *ChainConfig) SetSupportedProtocolVersions(p []uint) error {
 }
 
 func (c *ChainConfig) GetMaxCodeSize() *uint64 {
-	return internal.GlobalConfigurator().GetMaxCodeSize()
+	return c.MaxCodeSize
 }
 
 func (c *ChainConfig) SetMaxCodeSize(n *uint64) error {
-	return internal.GlobalConfigurator().SetMaxCodeSize(n)
+	c.MaxCodeSize = n
+	return nil
+}
+
+func (c *ChainConfig) GetMaxInitCodeSize() *uint64 {
+	return c.MaxInitCodeSize
+}
+
+func (c *ChainConfig) SetMaxInitCodeSize(n *uint64) error {
+	c.MaxInitCodeSize = n
+	return nil
 }
 
 func (c *ChainConfig) GetEIP7Transition() *uint64 {
@@ -433,13 +443,11 @@ func (c *ChainConfig) SetEIP1706Transition(n *uint64) error {
 // GetEIP2537Transition implements EIP2537.
 // This logic is written but not configured for any Ethereum-supported networks, yet.
 func (c *ChainConfig) GetEIP2537Transition() *uint64 {
-	return nil
+	return bigNewU64(c.EIP2537Block)
 }
 
 func (c *ChainConfig) SetEIP2537Transition(n *uint64) error {
-	if n != nil {
-		return ctypes.ErrUnsupportedConfigFatal
-	}
+	c.EIP2537Block = setBig(c.EIP2537Block, n)
 	return nil
 }
 
@@ -649,6 +657,16 @@ func (c *ChainConfig) SetEIP6049Transition(n *uint64) error {
 	return ctypes.ErrUnsupportedConfigNoop
 }
 
+// GetEIP2537TransitionTime EIP2537: BLS12-381 curve operations
+func (c *ChainConfig) GetEIP2537TransitionTime() *uint64 {
+	return c.EIP2537Time
+}
+
+func (c *ChainConfig) SetEIP2537TransitionTime(n *uint64) error {
+	c.EIP2537Time = n
+	return nil
+}
+
 // GetEIP4844TransitionTime EIP4844: Shard Block Transactions
 func (c *ChainConfig) GetEIP4844TransitionTime() *uint64 {
 	return c.CancunTime
@@ -17,6 +17,7 @@
 package rpc
 
 import (
+	"crypto/tls"
 	"net/http"
 
 	"github.com/gorilla/websocket"
@@ -33,6 +34,9 @@ type clientConfig struct {
 	httpHeaders http.Header
 	httpAuth    HTTPAuth
 
+	// TLS settings of the default HTTP client and WebSocket dialer
+	tlsConfig *tls.Config
+
 	// WebSocket options
 	wsDialer           *websocket.Dialer
 	wsMessageSizeLimit *int64 // wsMessageSizeLimit nil = default, 0 = no limit
@@ -102,6 +106,16 @@ func WithHTTPClient(c *http.Client) ClientOption {
 	})
 }
 
+// WithTLSConfig configures the TLS settings, e.g. the client certificate and the
+// trusted certificate authorities, used when dialing HTTPS and secure WebSocket
+// endpoints. It has no effect on the http.Client or websocket.Dialer configured
+// with WithHTTPClient or WithWebsocketDialer.
+func WithTLSConfig(config *tls.Config) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.tlsConfig = config
+	})
+}
+
 // WithHTTPAuth configures HTTP request authentication. The given provider will be called
 // whenever a request is made. Note that only one authentication provider can be active at
 // any time.
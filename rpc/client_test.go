@@ -450,6 +450,35 @@ func TestClientSubscribe(t *testing.T) {
 	}
 }
 
+func TestServerSubscriptionLimit(t *testing.T) {
+	server := newTestServer()
+	server.SetSubscriptionLimit(2)
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	var subs []*ClientSubscription
+	for i := 0; i < 2; i++ {
+		sub, err := client.Subscribe(context.Background(), "nftest", make(chan int), "someSubscription", 0, 0)
+		if err != nil {
+			t.Fatalf("subscription %d failed: %v", i, err)
+		}
+		subs = append(subs, sub)
+	}
+	_, err := client.Subscribe(context.Background(), "nftest", make(chan int), "someSubscription", 0, 0)
+	if rpcErr, ok := err.(Error); !ok || rpcErr.ErrorCode() != errcodeLimitExceeded {
+		t.Fatalf("wrong error subscribing beyond the limit: %v", err)
+	}
+	// Unsubscribing frees a slot
+	subs[0].Unsubscribe()
+	sub, err := client.Subscribe(context.Background(), "nftest", make(chan int), "someSubscription", 0, 0)
+	if err != nil {
+		t.Fatalf("subscription failed after unsubscribe: %v", err)
+	}
+	sub.Unsubscribe()
+	subs[1].Unsubscribe()
+}
+
 // In this test, the connection drops while Subscribe is waiting for a response.
 func TestClientSubscribeClose(t *testing.T) {
 	server := newTestServer()
@@ -0,0 +1,175 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// deprecationWarnCache is the number of method and client combinations for
+// which a deprecation warning has been logged that are remembered, to avoid
+// logging the warning on every call.
+const deprecationWarnCache = 1024
+
+// deprecation describes a deprecated method.
+type deprecation struct {
+	replacement string // Full name of the method superseding the deprecated one, may be empty
+	message     string // Notice to the callers of the deprecated method
+}
+
+// jsonDeprecation is the extension member added to the responses to calls of
+// deprecated methods.
+type jsonDeprecation struct {
+	Message     string `json:"message"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+func newDeprecation(method, replacement, message string) *deprecation {
+	if message == "" {
+		message = method + " is deprecated"
+		if replacement != "" {
+			message += ", use " + replacement + " instead"
+		}
+	}
+	return &deprecation{replacement: replacement, message: message}
+}
+
+// RegisterAlias makes the given method callable under the alias too. Both are
+// full method names, e.g. "admin_ecbp1100". Calls of the alias are answered by
+// the method, but flagged as deprecated in favor of it.
+func (s *Server) RegisterAlias(alias, method string) error {
+	return s.services.registerAlias(alias, method)
+}
+
+// Deprecate flags the calls of the given method as deprecated. The responses to
+// them carry a "deprecation" member with the message, and the replacement if
+// given. An empty message is substituted with a generic notice.
+func (s *Server) Deprecate(method, replacement, message string) error {
+	return s.services.deprecate(method, replacement, message)
+}
+
+// RegisterDeprecations registers the aliases and deprecated methods declared by
+// the API. It must be called once the services of the API are registered.
+func (s *Server) RegisterDeprecations(api API) error {
+	for alias, method := range api.Aliases {
+		if err := s.RegisterAlias(alias, method); err != nil {
+			return err
+		}
+	}
+	for method, replacement := range api.Deprecated {
+		if err := s.Deprecate(method, replacement, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *serviceRegistry) registerAlias(alias, method string) error {
+	if _, _, err := elementizeMethodName(alias); err != nil {
+		return fmt.Errorf("invalid alias %q: %v", alias, err)
+	}
+	module, name, err := elementizeMethodName(method)
+	if err != nil {
+		return fmt.Errorf("invalid method %q: %v", method, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.aliases[method]; ok {
+		return fmt.Errorf("method %s is an alias itself", method)
+	}
+	if r.services[module].callbacks[name] == nil {
+		return fmt.Errorf("method %s not found", method)
+	}
+	if module, name, _ := elementizeMethodName(alias); r.services[module].callbacks[name] != nil {
+		return fmt.Errorf("alias %s clashes with a registered method", alias)
+	}
+	if r.aliases == nil {
+		r.aliases = make(map[string]string)
+	}
+	r.aliases[alias] = method
+	r.setDeprecation(alias, newDeprecation(alias, method, ""))
+	return nil
+}
+
+func (r *serviceRegistry) deprecate(method, replacement, message string) error {
+	if _, _, err := elementizeMethodName(method); err != nil {
+		return fmt.Errorf("invalid method %q: %v", method, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.setDeprecation(method, newDeprecation(method, replacement, message))
+	return nil
+}
+
+// setDeprecation records the deprecation of a method, the lock must be held.
+func (r *serviceRegistry) setDeprecation(method string, dep *deprecation) {
+	if r.deprecated == nil {
+		r.deprecated = make(map[string]*deprecation)
+	}
+	r.deprecated[method] = dep
+}
+
+// deprecation returns the deprecation of the given method, nil if the method
+// is not deprecated.
+func (r *serviceRegistry) deprecation(method string) *deprecation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.deprecated[method]
+}
+
+// firstDeprecatedCall reports whether the deprecated method is called by the
+// given client for the first time recently.
+func (r *serviceRegistry) firstDeprecatedCall(method string, info PeerInfo) bool {
+	key := method + "\x00" + info.Transport + "\x00" + info.RemoteAddr + "\x00" + info.HTTP.UserAgent
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.warned == nil {
+		warned := lru.NewBasicLRU[string, struct{}](deprecationWarnCache)
+		r.warned = &warned
+	}
+	if r.warned.Contains(key) {
+		return false
+	}
+	r.warned.Add(key, struct{}{})
+	return true
+}
+
+// handleDeprecated flags the answer to a call of a deprecated method, and logs
+// the first call of it by every client.
+func (h *handler) handleDeprecated(ctx context.Context, msg, answer *jsonrpcMessage) {
+	dep := h.reg.deprecation(msg.Method)
+	if dep == nil {
+		return
+	}
+	metrics.GetOrRegisterCounter("rpc/deprecated/"+msg.Method, nil).Inc(1)
+	if answer != nil {
+		answer.Deprecation = &jsonDeprecation{Message: dep.message, Replacement: dep.replacement}
+	}
+	if info := PeerInfoFromContext(ctx); h.reg.firstDeprecatedCall(msg.Method, info) {
+		h.log.Warn("Deprecated RPC method called", "method", msg.Method, "replacement", dep.replacement,
+			"transport", info.Transport, "remote", info.RemoteAddr, "useragent", info.HTTP.UserAgent, "origin", info.HTTP.Origin)
+	}
+}
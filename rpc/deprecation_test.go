@@ -0,0 +1,81 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServerDeprecations(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+
+	if err := server.RegisterAlias("test_oldEcho", "test_missing"); err == nil {
+		t.Fatal("alias of unknown method registered")
+	}
+	if err := server.RegisterAlias("test_echo", "test_repeat"); err == nil {
+		t.Fatal("alias clashing with a method registered")
+	}
+	err := server.RegisterDeprecations(API{
+		Namespace:  "test",
+		Aliases:    map[string]string{"test_oldRepeat": "test_repeat"},
+		Deprecated: map[string]string{"test_noArgsRets": ""},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		req  string
+		want string
+	}{
+		{
+			`{"jsonrpc":"2.0","id":1,"method":"test_repeat","params":["a",2]}`,
+			`{"jsonrpc":"2.0","id":1,"result":"aa"}`,
+		},
+		{
+			`{"jsonrpc":"2.0","id":2,"method":"test_oldRepeat","params":["a",2]}`,
+			`{"jsonrpc":"2.0","id":2,"result":"aa","deprecation":{"message":"test_oldRepeat is deprecated, use test_repeat instead","replacement":"test_repeat"}}`,
+		},
+		{
+			`{"jsonrpc":"2.0","id":3,"method":"test_noArgsRets","params":[]}`,
+			`{"jsonrpc":"2.0","id":3,"result":null,"deprecation":{"message":"test_noArgsRets is deprecated"}}`,
+		},
+	}
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	go server.ServeCodec(NewCodec(serverConn), 0)
+
+	readbuf := bufio.NewReader(clientConn)
+	for _, tt := range tests {
+		clientConn.SetDeadline(time.Now().Add(5 * time.Second))
+		if _, err := io.WriteString(clientConn, tt.req+"\n"); err != nil {
+			t.Fatalf("write error: %v", err)
+		}
+		resp, err := readbuf.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read error: %v", err)
+		}
+		if resp = strings.TrimRight(resp, "\r\n"); resp != tt.want {
+			t.Errorf("wrong response\ngot:  %s\nwant: %s", resp, tt.want)
+		}
+	}
+}
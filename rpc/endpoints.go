@@ -25,7 +25,32 @@ import (
 
 // StartIPCEndpoint starts an IPC endpoint.
 func StartIPCEndpoint(ipcEndpoint string, apis []API) (net.Listener, *Server, error) {
-	// Register all the APIs exposed by the services.
+	handler, err := newIPCServer(apis)
+	if err != nil {
+		return nil, nil, err
+	}
+	// All APIs registered, start the IPC listener.
+	listener, err := ipcListen(ipcEndpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	go handler.ServeListener(listener)
+	return listener, handler, nil
+}
+
+// StartIPCListener serves the APIs on an already open IPC listener, e.g. one
+// passed in by the service manager.
+func StartIPCListener(listener net.Listener, apis []API) (*Server, error) {
+	handler, err := newIPCServer(apis)
+	if err != nil {
+		return nil, err
+	}
+	go handler.ServeListener(listener)
+	return handler, nil
+}
+
+// newIPCServer creates an RPC server with all the APIs exposed by the services.
+func newIPCServer(apis []API) (*Server, error) {
 	var (
 		handler    = NewServer()
 		regMap     = make(map[string]struct{})
@@ -34,19 +59,18 @@ func StartIPCEndpoint(ipcEndpoint string, apis []API) (net.Listener, *Server, er
 	for _, api := range apis {
 		if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
 			log.Info("IPC registration failed", "namespace", api.Namespace, "error", err)
-			return nil, nil, err
+			return nil, err
 		}
 		if _, ok := regMap[api.Namespace]; !ok {
 			registered = append(registered, api.Namespace)
 			regMap[api.Namespace] = struct{}{}
 		}
 	}
-	log.Debug("IPCs registered", "namespaces", strings.Join(registered, ","))
-	// All APIs registered, start the IPC listener.
-	listener, err := ipcListen(ipcEndpoint)
-	if err != nil {
-		return nil, nil, err
+	for _, api := range apis {
+		if err := handler.RegisterDeprecations(api); err != nil {
+			return nil, err
+		}
 	}
-	go handler.ServeListener(listener)
-	return listener, handler, nil
+	log.Debug("IPCs registered", "namespaces", strings.Join(registered, ","))
+	return handler, nil
 }
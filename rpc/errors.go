@@ -50,6 +50,7 @@ type DataError interface {
 var (
 	_ Error = new(methodNotFoundError)
 	_ Error = new(subscriptionNotFoundError)
+	_ Error = new(subscriptionLimitError)
 	_ Error = new(parseError)
 	_ Error = new(invalidRequestError)
 	_ Error = new(invalidMessageError)
@@ -61,6 +62,7 @@ const (
 	errcodeDefault          = -32000
 	errcodeTimeout          = -32002
 	errcodeResponseTooLarge = -32003
+	errcodeLimitExceeded    = -32005
 	errcodePanic            = -32603
 	errcodeMarshalError     = -32603
 
@@ -117,6 +119,16 @@ func (e *subscriptionNotFoundError) Error() string {
 	return fmt.Sprintf("no %q subscription in %s namespace", e.subscription, e.namespace)
 }
 
+// subscriptionLimitError is returned when a connection attempts to create more
+// subscriptions than allowed.
+type subscriptionLimitError struct{ limit int }
+
+func (e *subscriptionLimitError) ErrorCode() int { return errcodeLimitExceeded }
+
+func (e *subscriptionLimitError) Error() string {
+	return fmt.Sprintf("too many subscriptions on the connection, limit is %d", e.limit)
+}
+
 // Invalid JSON was received by the server.
 type parseError struct{ message string }
 
@@ -139,12 +151,22 @@ func (e *invalidMessageError) ErrorCode() int { return -32700 }
 func (e *invalidMessageError) Error() string { return e.message }
 
 // unable to decode supplied params, or an invalid number of parameters
-type invalidParamsError struct{ message string }
+type invalidParamsError struct {
+	message string
+	data    *paramError // details of the invalid argument, may be nil
+}
 
 func (e *invalidParamsError) ErrorCode() int { return -32602 }
 
 func (e *invalidParamsError) Error() string { return e.message }
 
+func (e *invalidParamsError) ErrorData() interface{} {
+	if e.data == nil {
+		return nil
+	}
+	return e.data
+}
+
 // internalServerError is used for server errors during request processing.
 type internalServerError struct {
 	code    int
@@ -20,11 +20,13 @@ import (
 	"context"
 	"encoding/json"
 	"reflect"
+	"runtime/pprof"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/internal/watchdog"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -361,6 +363,7 @@ func (h *handler) addSubscriptions(nn []*Notifier) {
 	for _, n := range nn {
 		if sub := n.takeSubscription(); sub != nil {
 			h.serverSubs[sub.ID] = sub
+			activeSubscriptionGauge.Inc(1)
 		}
 	}
 }
@@ -374,6 +377,7 @@ func (h *handler) cancelServerSubscriptions(err error) {
 		s.err <- err
 		close(s.err)
 		delete(h.serverSubs, id)
+		activeSubscriptionGauge.Dec(1)
 	}
 }
 
@@ -384,7 +388,9 @@ func (h *handler) startCallProc(fn func(*callProc)) {
 		ctx, cancel := context.WithCancel(h.rootCtx)
 		defer h.callWG.Done()
 		defer cancel()
-		fn(&callProc{ctx: ctx})
+		pprof.Do(ctx, pprof.Labels("subsystem", "rpc"), func(ctx context.Context) {
+			fn(&callProc{ctx: ctx})
+		})
 	}()
 }
 
@@ -504,12 +510,19 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 		return msg.errorResponse(&methodNotFoundError{method: msg.Method})
 	}
 
-	args, err := parsePositionalArguments(msg.Params, callb.argTypes)
+	params, err := resolveNames(msg.Params, callb.argTypes, h.reg.nameResolver())
+	if err != nil {
+		return msg.errorResponse(&invalidParamsError{message: err.Error()})
+	}
+	args, err := parsePositionalArguments(params, callb.argTypes, h.reg.lenientParams())
 	if err != nil {
-		return msg.errorResponse(&invalidParamsError{err.Error()})
+		return msg.errorResponse(newInvalidParamsError(err))
 	}
 	start := time.Now()
+	section := watchdog.Call("rpc " + msg.Method)
 	answer := h.runMethod(cp.ctx, msg, callb, args)
+	section.Done()
+	h.handleDeprecated(cp.ctx, msg, answer)
 
 	// Collect the statistics for RPC calls if metrics is enabled.
 	// We only care about pure rpc call. Filter out subscription.
@@ -521,6 +534,7 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 			successfulRequestGauge.Inc(1)
 		}
 		rpcServingTimer.UpdateSince(start)
+		recentServeTimes.add(time.Now(), time.Since(start))
 		updateServeTimeHistogram(msg.Method, answer.Error == nil, time.Since(start))
 	}
 
@@ -536,7 +550,7 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 	// Subscription method name is first argument.
 	name, err := parseSubscriptionName(msg.Params)
 	if err != nil {
-		return msg.errorResponse(&invalidParamsError{err.Error()})
+		return msg.errorResponse(&invalidParamsError{message: err.Error()})
 	}
 	namespace := msg.namespace()
 	callb := h.reg.subscription(namespace, name)
@@ -546,12 +560,24 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 
 	// Parse subscription name arg too, but remove it before calling the callback.
 	argTypes := append([]reflect.Type{stringType}, callb.argTypes...)
-	args, err := parsePositionalArguments(msg.Params, argTypes)
+	args, err := parsePositionalArguments(msg.Params, argTypes, h.reg.lenientParams())
 	if err != nil {
-		return msg.errorResponse(&invalidParamsError{err.Error()})
+		return msg.errorResponse(newInvalidParamsError(err))
 	}
 	args = args[1:]
 
+	// Reject the subscription if the connection has too many already. The ones
+	// created earlier by the same batch are not yet active, count them too.
+	if limit := h.reg.subscriptionLimit(); limit > 0 {
+		h.subLock.Lock()
+		active := len(h.serverSubs)
+		h.subLock.Unlock()
+		if active+len(cp.notifiers) >= limit {
+			rejectedSubscriptionMeter.Mark(1)
+			return msg.errorResponse(&subscriptionLimitError{limit})
+		}
+	}
+
 	// Install notifier in context so the subscription handler can find it.
 	n := &Notifier{h: h, namespace: namespace}
 	cp.notifiers = append(cp.notifiers, n)
@@ -580,6 +606,7 @@ func (h *handler) unsubscribe(ctx context.Context, id ID) (bool, error) {
 	}
 	close(s.err)
 	delete(h.serverSubs, id)
+	activeSubscriptionGauge.Dec(1)
 	return true, nil
 }
 
@@ -153,6 +153,11 @@ func newClientTransportHTTP(endpoint string, cfg *clientConfig) reconnectFunc {
 	client := cfg.httpClient
 	if client == nil {
 		client = new(http.Client)
+		if cfg.tlsConfig != nil {
+			transport := http.DefaultTransport.(*http.Transport).Clone()
+			transport.TLSClientConfig = cfg.tlsConfig
+			client.Transport = transport
+		}
 	}
 
 	hc := &httpConn{
@@ -253,11 +258,43 @@ type httpServerConn struct {
 	r *http.Request
 }
 
-func (s *Server) newHTTPServerConn(r *http.Request, w http.ResponseWriter) ServerCodec {
+// responseHeaders collects the HTTP response headers set by method handlers while
+// serving a request, until the response is written.
+type responseHeaders struct {
+	header http.Header
+	lock   sync.Mutex
+}
+
+type responseHeadersContextKey struct{}
+
+// SetResponseHeader sets a header on the HTTP response of the request being served.
+// Use this with the context passed to RPC method handler functions. It is a no-op
+// for requests not received over HTTP.
+func SetResponseHeader(ctx context.Context, key, value string) {
+	if h, ok := ctx.Value(responseHeadersContextKey{}).(*responseHeaders); ok {
+		h.lock.Lock()
+		defer h.lock.Unlock()
+
+		h.header.Set(key, value)
+	}
+}
+
+// flush copies the collected headers into the response headers.
+func (h *responseHeaders) flush(dst http.Header) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for key, values := range h.header {
+		dst[key] = values
+	}
+}
+
+func (s *Server) newHTTPServerConn(r *http.Request, w http.ResponseWriter, headers *responseHeaders) ServerCodec {
 	body := io.LimitReader(r.Body, int64(s.httpBodyLimit))
 	conn := &httpServerConn{Reader: body, Writer: w, r: r}
 
 	encoder := func(v any, isErrorResponse bool) error {
+		headers.flush(w.Header())
 		if !isErrorResponse {
 			return json.NewEncoder(conn).Encode(v)
 		}
@@ -326,11 +363,14 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	ctx = context.WithValue(ctx, peerInfoContextKey{}, connInfo)
 
+	headers := &responseHeaders{header: make(http.Header)}
+	ctx = context.WithValue(ctx, responseHeadersContextKey{}, headers)
+
 	// All checks passed, create a codec that reads directly from the request body
 	// until EOF, writes the response to w, and orders the server to process a
 	// single request.
 	w.Header().Set("content-type", contentType)
-	codec := s.newHTTPServerConn(r, w)
+	codec := s.newHTTPServerConn(r, w, headers)
 	defer codec.close()
 	s.serveSingleRequest(ctx, codec)
 }
@@ -18,11 +18,19 @@ package rpc
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"fmt"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func confirmStatusCode(t *testing.T, got, want int) {
@@ -129,6 +137,79 @@ func TestHTTPRespBodyUnlimited(t *testing.T) {
 	}
 }
 
+// newClientCertificate creates a self-signed TLS client certificate.
+func newClientCertificate(t *testing.T) (tls.Certificate, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, cert
+}
+
+// Tests that the client can dial HTTPS and WSS endpoints requiring a client
+// certificate, when configured with one.
+func TestClientTLS(t *testing.T) {
+	s := newTestServer()
+	defer s.Stop()
+
+	clientCert, clientCA := newClientCertificate(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCA)
+
+	for _, scheme := range []string{"https", "wss"} {
+		var handler http.Handler = s
+		if scheme == "wss" {
+			handler = s.WebsocketHandler([]string{"*"})
+		}
+		ts := httptest.NewUnstartedServer(handler)
+		ts.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+		ts.StartTLS()
+		defer ts.Close()
+
+		rootCAs := x509.NewCertPool()
+		rootCAs.AddCert(ts.Certificate())
+		endpoint := scheme + strings.TrimPrefix(ts.URL, "https")
+
+		// Without the client certificate, the server rejects the connection.
+		c, err := DialOptions(context.Background(), endpoint, WithTLSConfig(&tls.Config{RootCAs: rootCAs}))
+		if err == nil {
+			err = c.Call(nil, "test_noArgsRets")
+			c.Close()
+		}
+		if err == nil {
+			t.Fatalf("%s: expected error without client certificate", scheme)
+		}
+		// With the client certificate, the call succeeds.
+		config := &tls.Config{RootCAs: rootCAs, Certificates: []tls.Certificate{clientCert}}
+		c, err = DialOptions(context.Background(), endpoint, WithTLSConfig(config))
+		if err != nil {
+			t.Fatalf("%s: dial failed: %v", scheme, err)
+		}
+		if err := c.Call(nil, "test_noArgsRets"); err != nil {
+			t.Fatalf("%s: call failed: %v", scheme, err)
+		}
+		c.Close()
+	}
+}
+
 // Tests that an HTTP error results in an HTTPError instance
 // being returned with the expected attributes.
 func TestHTTPErrorResponse(t *testing.T) {
@@ -204,6 +285,36 @@ func TestHTTPPeerInfo(t *testing.T) {
 	}
 }
 
+// headerService sets a response header from within a method handler.
+type headerService struct{}
+
+func (headerService) Set(ctx context.Context, key, value string) {
+	SetResponseHeader(ctx, key, value)
+}
+
+// Tests that method handlers can set headers on the HTTP response.
+func TestHTTPResponseHeaders(t *testing.T) {
+	s := NewServer()
+	defer s.Stop()
+	s.RegisterName("header", headerService{})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"header_set","params":["X-Test-Header","value"]}`
+	resp, err := http.Post(ts.URL, contentType, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if have := resp.Header.Get("X-Test-Header"); have != "value" {
+		t.Errorf("response header mismatch: have %q, want %q", have, "value")
+	}
+	if have := resp.Header.Get("Content-Type"); have != contentType {
+		t.Errorf("content type mismatch: have %q, want %q", have, contentType)
+	}
+}
+
 func TestNewContextWithHeaders(t *testing.T) {
 	expectedHeaders := 0
 	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
@@ -86,6 +86,10 @@ type jsonrpcMessage struct {
 	Params  json.RawMessage `json:"params,omitempty"`
 	Error   *jsonError      `json:"error,omitempty"`
 	Result  json.RawMessage `json:"result,omitempty"`
+
+	// Deprecation is an extension member flagging responses to calls of
+	// deprecated methods.
+	Deprecation *jsonDeprecation `json:"deprecation,omitempty"`
 }
 
 func (msg *jsonrpcMessage) isNotification() bool {
@@ -326,7 +330,7 @@ func isBatch(raw json.RawMessage) bool {
 // parsePositionalArguments tries to parse the given args to an array of values with the
 // given types. It returns the parsed values or an error when the args could not be
 // parsed. Missing optional arguments are returned as reflect.Zero values.
-func parsePositionalArguments(rawArgs json.RawMessage, types []reflect.Type) ([]reflect.Value, error) {
+func parsePositionalArguments(rawArgs json.RawMessage, types []reflect.Type, lenient bool) ([]reflect.Value, error) {
 	dec := json.NewDecoder(bytes.NewReader(rawArgs))
 	var args []reflect.Value
 	tok, err := dec.Token()
@@ -338,7 +342,7 @@ func parsePositionalArguments(rawArgs json.RawMessage, types []reflect.Type) ([]
 		return nil, err
 	case tok == json.Delim('['):
 		// Read argument array.
-		if args, err = parseArgumentArray(dec, types); err != nil {
+		if args, err = parseArgumentArray(dec, types, lenient); err != nil {
 			return nil, err
 		}
 	default:
@@ -347,25 +351,31 @@ func parsePositionalArguments(rawArgs json.RawMessage, types []reflect.Type) ([]
 	// Set any missing args to nil.
 	for i := len(args); i < len(types); i++ {
 		if types[i].Kind() != reflect.Ptr {
-			return nil, fmt.Errorf("missing value for required argument %d", i)
+			return nil, newParamError(i, types[i], nil)
 		}
 		args = append(args, reflect.Zero(types[i]))
 	}
 	return args, nil
 }
 
-func parseArgumentArray(dec *json.Decoder, types []reflect.Type) ([]reflect.Value, error) {
+func parseArgumentArray(dec *json.Decoder, types []reflect.Type, lenient bool) ([]reflect.Value, error) {
 	args := make([]reflect.Value, 0, len(types))
 	for i := 0; dec.More(); i++ {
 		if i >= len(types) {
 			return args, fmt.Errorf("too many arguments, want at most %d", len(types))
 		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return args, newParamError(i, types[i], err)
+		}
 		argval := reflect.New(types[i])
-		if err := dec.Decode(argval.Interface()); err != nil {
-			return args, fmt.Errorf("invalid argument %d: %v", i, err)
+		if err := decodeArgument(raw, argval.Interface(), lenient); err != nil {
+			perr := newParamError(i, types[i], err)
+			perr.describeArgument(raw, types[i])
+			return args, perr
 		}
 		if argval.IsNil() && types[i].Kind() != reflect.Ptr {
-			return args, fmt.Errorf("missing value for required argument %d", i)
+			return args, newParamError(i, types[i], nil)
 		}
 		args = append(args, argval.Elem())
 	}
@@ -18,6 +18,8 @@ package rpc
 
 import (
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/metrics"
@@ -32,8 +34,72 @@ var (
 	serveTimeHistName = "rpc/duration"
 
 	rpcServingTimer = metrics.NewRegisteredTimer("rpc/duration/all", nil)
+
+	activeSubscriptionGauge   = metrics.NewRegisteredGauge("rpc/subscriptions/active", nil)
+	rejectedSubscriptionMeter = metrics.NewRegisteredMeter("rpc/subscriptions/rejected", nil)
+
+	// recentServeTimes tracks the serving times of the most recent calls even
+	// if metrics are disabled, allowing the node to react to its RPC load.
+	recentServeTimes = newServeTimeWindow(1024)
 )
 
+// serveTimeWindow is a ring buffer of the serving times of the recent calls.
+type serveTimeWindow struct {
+	lock  sync.Mutex
+	times []time.Time
+	durs  []time.Duration
+	next  int
+}
+
+func newServeTimeWindow(size int) *serveTimeWindow {
+	return &serveTimeWindow{
+		times: make([]time.Time, size),
+		durs:  make([]time.Duration, size),
+	}
+}
+
+// add records the serving time of a call finished at the given time.
+func (w *serveTimeWindow) add(done time.Time, elapsed time.Duration) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.times[w.next], w.durs[w.next] = done, elapsed
+	w.next = (w.next + 1) % len(w.times)
+}
+
+// percentile returns the p-th percentile (0 < p <= 1) of the serving times of
+// the tracked calls finished after since, or zero if there are none.
+func (w *serveTimeWindow) percentile(p float64, since time.Time) time.Duration {
+	w.lock.Lock()
+	var durs []time.Duration
+	for i, done := range w.times {
+		if done.After(since) {
+			durs = append(durs, w.durs[i])
+		}
+	}
+	w.lock.Unlock()
+
+	if len(durs) == 0 {
+		return 0
+	}
+	sort.Slice(durs, func(i, j int) bool { return durs[i] < durs[j] })
+	idx := int(p*float64(len(durs))+0.5) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(durs) {
+		idx = len(durs) - 1
+	}
+	return durs[idx]
+}
+
+// ServeTimePercentile returns the p-th percentile (0 < p <= 1) of the serving
+// times of the RPC calls finished within the given window, across all servers
+// of the process. Only the most recent 1024 calls are tracked.
+func ServeTimePercentile(p float64, window time.Duration) time.Duration {
+	return recentServeTimes.percentile(p, time.Now().Add(-window))
+}
+
 // updateServeTimeHistogram tracks the serving time of a remote RPC call.
 func updateServeTimeHistogram(method string, success bool, elapsed time.Duration) {
 	note := "success"
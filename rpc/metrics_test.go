@@ -0,0 +1,46 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestServeTimeWindow(t *testing.T) {
+	var (
+		w   = newServeTimeWindow(100)
+		now = time.Now()
+	)
+	// Fill the window with stale calls, then overwrite most of them
+	for i := 0; i < 100; i++ {
+		w.add(now.Add(-time.Hour), time.Hour)
+	}
+	for i := 1; i <= 90; i++ {
+		w.add(now, time.Duration(i)*time.Millisecond)
+	}
+	since := now.Add(-time.Minute)
+	if have, want := w.percentile(0.99, since), 89*time.Millisecond; have != want {
+		t.Errorf("p99 mismatch: have %v, want %v", have, want)
+	}
+	if have, want := w.percentile(0.5, since), 45*time.Millisecond; have != want {
+		t.Errorf("p50 mismatch: have %v, want %v", have, want)
+	}
+	if have := w.percentile(0.99, now.Add(time.Minute)); have != 0 {
+		t.Errorf("percentile of empty window: have %v, want 0", have)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var addressType = reflect.TypeOf(common.Address{})
+
+// NameResolver resolves a name, given as an "@name" string in place of an
+// address in call parameters, to the address substituted for it. It reports
+// false for unknown names.
+type NameResolver func(name string) (string, bool)
+
+// resolveNames replaces the "@name" strings known to the resolver in the given
+// call parameters. Only the parameters decoded into addresses are resolved,
+// including the ones nested in structs, slices and maps, other strings starting
+// with "@" are passed through unchanged.
+func resolveNames(params json.RawMessage, types []reflect.Type, resolve NameResolver) (json.RawMessage, error) {
+	if resolve == nil || !bytes.Contains(params, []byte(`"@`)) {
+		return params, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.UseNumber()
+
+	var args []interface{}
+	if err := dec.Decode(&args); err != nil {
+		// Leave reporting malformed parameters to the argument parser.
+		return params, nil
+	}
+	var replaced bool
+	for i := range args {
+		if i < len(types) {
+			args[i] = resolveValue(args[i], types[i], resolve, &replaced)
+		}
+	}
+	if !replaced {
+		return params, nil
+	}
+	return json.Marshal(args)
+}
+
+// resolveValue resolves the names in a decoded JSON value, which is going to be
+// decoded into the given type.
+func resolveValue(value interface{}, typ reflect.Type, resolve NameResolver, replaced *bool) interface{} {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == addressType {
+		if s, ok := value.(string); ok {
+			if name, ok := strings.CutPrefix(s, "@"); ok {
+				if resolved, ok := resolve(name); ok {
+					*replaced = true
+					return resolved
+				}
+			}
+		}
+		return value
+	}
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		if v, ok := value.([]interface{}); ok {
+			for i := range v {
+				v[i] = resolveValue(v[i], typ.Elem(), resolve, replaced)
+			}
+		}
+	case reflect.Map:
+		if v, ok := value.(map[string]interface{}); ok {
+			for key := range v {
+				v[key] = resolveValue(v[key], typ.Elem(), resolve, replaced)
+			}
+		}
+	case reflect.Struct:
+		if v, ok := value.(map[string]interface{}); ok {
+			for _, field := range jsonFields(typ) {
+				for key := range v {
+					if strings.EqualFold(key, field.name) {
+						v[key] = resolveValue(v[key], field.typ, resolve, replaced)
+					}
+				}
+			}
+		}
+	}
+	return value
+}
@@ -0,0 +1,118 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type nameTestArgs struct {
+	From  *common.Address `json:"from"`
+	To    common.Address
+	Label string `json:"label"`
+	nameTestEmbedded
+}
+
+type nameTestEmbedded struct {
+	Owners []common.Address `json:"owners"`
+}
+
+func TestResolveNames(t *testing.T) {
+	resolve := func(name string) (string, bool) {
+		if name == "alice" {
+			return "0x000000000000000000000000000000000000a11c", true
+		}
+		return "", false
+	}
+	var (
+		address = reflect.TypeOf(common.Address{})
+		str     = reflect.TypeOf("")
+		args    = reflect.TypeOf(&nameTestArgs{})
+		list    = reflect.TypeOf([]common.Address{})
+		dict    = reflect.TypeOf(map[string]common.Address{})
+		number  = reflect.TypeOf(uint64(0))
+	)
+	tests := []struct {
+		params string
+		types  []reflect.Type
+		want   string
+	}{
+		{`[]`, nil, `[]`},
+		{`["@bob", 1]`, []reflect.Type{address, number}, `["@bob", 1]`},
+		{`["@alice", 1]`, []reflect.Type{address, number}, `["0x000000000000000000000000000000000000a11c",1]`},
+		// Only address parameters are resolved
+		{`["@alice", 1]`, []reflect.Type{str, number}, `["@alice", 1]`},
+		{`["@alice"]`, nil, `["@alice"]`},
+		{
+			`[{"from":"@alice","to":"@alice","label":"@alice","owners":["@alice"]},["@alice"],{"x":"@alice"},12345678901234567890]`,
+			[]reflect.Type{args, list, dict, number},
+			`[{"from":"0x000000000000000000000000000000000000a11c","label":"@alice","owners":["0x000000000000000000000000000000000000a11c"],"to":"0x000000000000000000000000000000000000a11c"},["0x000000000000000000000000000000000000a11c"],{"x":"0x000000000000000000000000000000000000a11c"},12345678901234567890]`,
+		},
+		// Malformed parameters are left to the argument parser
+		{`{"from":"@alice"}`, []reflect.Type{args}, `{"from":"@alice"}`},
+	}
+	for _, test := range tests {
+		have, err := resolveNames(json.RawMessage(test.params), test.types, resolve)
+		if err != nil {
+			t.Fatalf("%s: %v", test.params, err)
+		}
+		if string(have) != test.want {
+			t.Errorf("%s: have %s, want %s", test.params, have, test.want)
+		}
+	}
+}
+
+type nameTestService struct{}
+
+type nameTestResult struct {
+	Address common.Address
+	Label   string
+}
+
+func (nameTestService) Echo(addr common.Address, label string) nameTestResult {
+	return nameTestResult{addr, label}
+}
+
+func TestServerNameResolver(t *testing.T) {
+	alice := common.HexToAddress("0xa11c")
+
+	server := NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("names", nameTestService{}); err != nil {
+		t.Fatal(err)
+	}
+	server.SetNameResolver(func(name string) (string, bool) {
+		return alice.Hex(), name == "alice"
+	})
+	client := DialInProc(server)
+	defer client.Close()
+
+	var result nameTestResult
+	if err := client.Call(&result, "names_echo", "@alice", "@alice"); err != nil {
+		t.Fatal(err)
+	}
+	if want := (nameTestResult{alice, "@alice"}); result != want {
+		t.Fatalf("wrong result: have %+v, want %+v", result, want)
+	}
+	if err := client.Call(&result, "names_echo", "@bob", ""); err == nil {
+		t.Fatal("unknown name accepted as address")
+	}
+}
@@ -0,0 +1,347 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// lenientRepairLimit is the maximum number of fields repaired in a single
+// argument when decoding parameters leniently.
+const lenientRepairLimit = 32
+
+var (
+	bigIntType            = reflect.TypeOf(big.Int{})
+	blockNumberType       = reflect.TypeOf(BlockNumber(0))
+	blockNumberOrHashType = reflect.TypeOf(BlockNumberOrHash{})
+	hexutilPkgPath        = reflect.TypeOf(hexutil.Big{}).PkgPath()
+	unmarshalerType       = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// paramError describes an invalid call argument. It is returned as the data
+// of the invalid params error answering the call.
+type paramError struct {
+	Argument int    `json:"argument"`           // Position of the invalid argument
+	Field    string `json:"field,omitempty"`    // Path of the invalid value within the argument
+	Expected string `json:"expected,omitempty"` // Format the value must have
+	Reason   string `json:"reason"`             // What is wrong with the value
+
+	err error // Original decoding error
+}
+
+func (e *paramError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("missing value for required argument %d", e.Argument)
+	}
+	return fmt.Sprintf("invalid argument %d: %v", e.Argument, e.err)
+}
+
+func (e *paramError) Unwrap() error { return e.err }
+
+// newInvalidParamsError creates the error answering a call with the given
+// invalid parameters, carrying the details of the invalid argument if known.
+func newInvalidParamsError(err error) *invalidParamsError {
+	var perr *paramError
+	if errors.As(err, &perr) {
+		return &invalidParamsError{message: err.Error(), data: perr}
+	}
+	return &invalidParamsError{message: err.Error()}
+}
+
+// newParamError describes the failure to decode the argument at the given
+// position into the given type.
+func newParamError(index int, typ reflect.Type, err error) *paramError {
+	perr := &paramError{Argument: index, err: err}
+	if err == nil {
+		perr.Expected, perr.Reason = expectedFormat(typ), "missing value"
+		return perr
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		perr.Reason = "malformed JSON: " + syntaxErr.Error()
+		return perr
+	}
+	perr.Expected, perr.Reason = expectedFormat(typ), err.Error()
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		perr.Field, perr.Reason = typeErr.Field, typeErrorReason(typeErr)
+	}
+	return perr
+}
+
+// describeArgument locates the invalid value within the encoded argument,
+// refining the error describing it.
+func (e *paramError) describeArgument(raw json.RawMessage, typ reflect.Type) {
+	path, leaf, err := locateInvalid(raw, typ)
+	if err == nil {
+		return
+	}
+	e.Field, e.Expected, e.Reason = strings.Join(path, "."), expectedFormat(leaf), err.Error()
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		e.Reason = typeErrorReason(typeErr)
+	}
+}
+
+// typeErrorReason describes what is wrong with the value rejected by the
+// given decoding error.
+func typeErrorReason(err *json.UnmarshalTypeError) string {
+	switch kind, _, _ := strings.Cut(err.Value, " "); kind {
+	case "string", "number", "bool", "array", "object":
+		return "unexpected " + err.Value
+	default:
+		// Hex decoding errors carry the problem as the value
+		return err.Value
+	}
+}
+
+// locateInvalid decodes the encoded value into the given type piecewise,
+// returning the path and type of the first value failing to decode, along with
+// the decoding error.
+func locateInvalid(raw json.RawMessage, typ reflect.Type) ([]string, reflect.Type, error) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if reflect.PointerTo(typ).Implements(unmarshalerType) || bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		return nil, typ, json.Unmarshal(raw, reflect.New(typ).Interface())
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return nil, typ, json.Unmarshal(raw, reflect.New(typ).Interface())
+		}
+		for _, field := range jsonFields(typ) {
+			value, ok := fields[field.name]
+			if !ok {
+				for key := range fields {
+					if strings.EqualFold(key, field.name) {
+						value, ok = fields[key], true
+						break
+					}
+				}
+			}
+			if !ok {
+				continue
+			}
+			if path, leaf, err := locateInvalid(value, field.typ); err != nil {
+				return append([]string{field.name}, path...), leaf, err
+			}
+		}
+		return nil, typ, nil
+
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		var elems []json.RawMessage
+		if err := json.Unmarshal(raw, &elems); err != nil {
+			break
+		}
+		for i, elem := range elems {
+			if path, leaf, err := locateInvalid(elem, typ.Elem()); err != nil {
+				return append([]string{strconv.Itoa(i)}, path...), leaf, err
+			}
+		}
+		return nil, typ, nil
+
+	case reflect.Map:
+		var elems map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &elems); err != nil {
+			break
+		}
+		keys := make([]string, 0, len(elems))
+		for key := range elems {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if path, leaf, err := locateInvalid(elems[key], typ.Elem()); err != nil {
+				return append([]string{key}, path...), leaf, err
+			}
+		}
+	}
+	return nil, typ, json.Unmarshal(raw, reflect.New(typ).Interface())
+}
+
+// jsonField is a struct field as seen by the JSON decoder.
+type jsonField struct {
+	name string
+	typ  reflect.Type
+}
+
+// jsonFields returns the fields of the struct type decoded from JSON objects,
+// including those promoted from embedded structs.
+func jsonFields(typ reflect.Type) []jsonField {
+	var fields []jsonField
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, jsonFields(embedded)...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, jsonField{name: name, typ: field.Type})
+	}
+	return fields
+}
+
+// expectedFormat describes the JSON encoding of the given type.
+func expectedFormat(typ reflect.Type) string {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch {
+	case typ == blockNumberType:
+		return `hex encoded block number with 0x prefix, or block tag ("latest", "earliest", "pending", "safe", "finalized")`
+	case typ == blockNumberOrHashType:
+		return "hex encoded block number with 0x prefix, block tag, or 32 byte block hash"
+	case typ.Kind() == reflect.Array && typ.Elem().Kind() == reflect.Uint8:
+		return fmt.Sprintf("hex encoded %d bytes with 0x prefix", typ.Len())
+	case typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8:
+		return "hex encoded bytes with 0x prefix"
+	case typ.PkgPath() == hexutilPkgPath, typ.ConvertibleTo(bigIntType):
+		return "hex encoded quantity with 0x prefix"
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	}
+	return ""
+}
+
+// decodeArgument decodes a single call argument into the given value. When
+// decoding leniently, hex strings lacking the 0x prefix are accepted.
+func decodeArgument(raw json.RawMessage, val interface{}, lenient bool) error {
+	err := json.Unmarshal(raw, val)
+	for i := 0; err != nil && lenient && i < lenientRepairLimit; i++ {
+		path, _, leafErr := locateInvalid(raw, reflect.TypeOf(val).Elem())
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(leafErr, &typeErr) || typeErr.Value != hexutil.ErrMissingPrefix.Error() {
+			break
+		}
+		repaired, ok := prefixHex(raw, path)
+		if !ok {
+			break
+		}
+		raw = repaired
+		reflect.ValueOf(val).Elem().SetZero()
+		err = json.Unmarshal(raw, val)
+	}
+	return err
+}
+
+// prefixHex adds the missing 0x prefix to the hex string at the given path of
+// the encoded value.
+func prefixHex(raw json.RawMessage, path []string) (json.RawMessage, bool) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, false
+	}
+	var repaired bool
+	value = prefixHexValue(value, path, &repaired)
+	if !repaired {
+		return nil, false
+	}
+	enc, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+	return enc, true
+}
+
+func prefixHexValue(value interface{}, path []string, repaired *bool) interface{} {
+	if len(path) == 0 {
+		if s, ok := value.(string); ok && isUnprefixedHex(s) {
+			*repaired = true
+			return "0x" + s
+		}
+		return value
+	}
+	switch v := value.(type) {
+	case []interface{}:
+		if i, err := strconv.Atoi(path[0]); err == nil && i >= 0 && i < len(v) {
+			v[i] = prefixHexValue(v[i], path[1:], repaired)
+		}
+	case map[string]interface{}:
+		key := path[0]
+		if _, ok := v[key]; !ok {
+			for k := range v {
+				if strings.EqualFold(k, key) {
+					key = k
+					break
+				}
+			}
+		}
+		if elem, ok := v[key]; ok {
+			v[key] = prefixHexValue(elem, path[1:], repaired)
+		}
+	}
+	return value
+}
+
+func isUnprefixedHex(s string) bool {
+	if s == "" || strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		return false
+	}
+	for _, c := range []byte(s) {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+type paramsTestCall struct {
+	To    *common.Address `json:"to"`
+	Value *hexutil.Big    `json:"value"`
+	Input hexutil.Bytes   `json:"input"`
+}
+
+func TestParseArgumentErrors(t *testing.T) {
+	types := []reflect.Type{reflect.TypeOf(paramsTestCall{}), reflect.TypeOf(hexutil.Uint64(0))}
+	tests := []struct {
+		params string
+		want   paramError
+	}{
+		{
+			`[{"to":"000000000000000000000000000000000000a11c"}, "0x1"]`,
+			paramError{Argument: 0, Field: "to", Expected: "hex encoded 20 bytes with 0x prefix", Reason: "hex string without 0x prefix"},
+		},
+		{
+			`[{"to":"0xa11c"}, "0x1"]`,
+			paramError{Argument: 0, Field: "to", Expected: "hex encoded 20 bytes with 0x prefix", Reason: "hex string has length 4, want 40 for common.Address"},
+		},
+		{
+			`[{"value":12}, "0x1"]`,
+			paramError{Argument: 0, Field: "value", Expected: "hex encoded quantity with 0x prefix", Reason: "non-string"},
+		},
+		{
+			`[{"input":"0x1"}, "0x1"]`,
+			paramError{Argument: 0, Field: "input", Expected: "hex encoded bytes with 0x prefix", Reason: "hex string of odd length"},
+		},
+		{
+			`[{}, "1"]`,
+			paramError{Argument: 1, Expected: "hex encoded quantity with 0x prefix", Reason: "hex string without 0x prefix"},
+		},
+		{
+			`[{}]`,
+			paramError{Argument: 1, Expected: "hex encoded quantity with 0x prefix", Reason: "missing value"},
+		},
+	}
+	for _, test := range tests {
+		_, err := parsePositionalArguments(json.RawMessage(test.params), types, false)
+		var perr *paramError
+		if !errors.As(err, &perr) {
+			t.Errorf("%s: expected parameter error, got %v", test.params, err)
+			continue
+		}
+		perr.err = nil
+		if *perr != test.want {
+			t.Errorf("%s: wrong error\nhave %+v\nwant %+v", test.params, *perr, test.want)
+		}
+	}
+}
+
+func TestParseArgumentsLenient(t *testing.T) {
+	types := []reflect.Type{reflect.TypeOf([]paramsTestCall{}), reflect.TypeOf(hexutil.Uint64(0))}
+	params := json.RawMessage(`[[{"to":"000000000000000000000000000000000000a11c","input":"beef"},{"value":"0x10"}], "2a"]`)
+
+	if _, err := parsePositionalArguments(params, types, false); err == nil {
+		t.Fatal("unprefixed hex accepted in strict mode")
+	}
+	args, err := parsePositionalArguments(params, types, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := args[0].Interface().([]paramsTestCall)
+	if want := common.HexToAddress("0xa11c"); calls[0].To == nil || *calls[0].To != want {
+		t.Errorf("wrong address: have %v, want %v", calls[0].To, want)
+	}
+	if want := (hexutil.Bytes{0xbe, 0xef}); !reflect.DeepEqual(calls[0].Input, want) {
+		t.Errorf("wrong input: have %v, want %v", calls[0].Input, want)
+	}
+	if calls[1].Value == nil || calls[1].Value.ToInt().Uint64() != 16 {
+		t.Errorf("wrong value: have %v, want 0x10", calls[1].Value)
+	}
+	if n := args[1].Interface().(hexutil.Uint64); n != 42 {
+		t.Errorf("wrong quantity: have %d, want 42", n)
+	}
+	// Values which aren't hex must not be altered
+	if _, err := parsePositionalArguments(json.RawMessage(`[[], "xyz"]`), types, true); err == nil {
+		t.Fatal("invalid hex accepted in lenient mode")
+	}
+}
@@ -87,6 +87,33 @@ func (s *Server) SetHTTPBodyLimit(limit int) {
 	s.httpBodyLimit = limit
 }
 
+// SetNameResolver sets the resolver of address call parameters given as "@name"
+// strings. Parameters with names unknown to the resolver are passed through
+// unchanged. As the resolver discloses the names known to it, it should only be
+// set on servers restricted to the node operator.
+func (s *Server) SetNameResolver(resolver NameResolver) {
+	s.services.mu.Lock()
+	defer s.services.mu.Unlock()
+	s.services.resolver = resolver
+}
+
+// SetLenientParams sets whether hex encoded call parameters lacking the 0x prefix
+// are accepted, for compatibility with legacy tooling. By default such parameters
+// are rejected.
+func (s *Server) SetLenientParams(lenient bool) {
+	s.services.mu.Lock()
+	defer s.services.mu.Unlock()
+	s.services.lenient = lenient
+}
+
+// SetSubscriptionLimit sets the maximum number of active subscriptions a single
+// connection may have. Zero means unlimited, which is the default.
+func (s *Server) SetSubscriptionLimit(limit int) {
+	s.services.mu.Lock()
+	defer s.services.mu.Unlock()
+	s.services.subLimit = limit
+}
+
 // RegisterName creates a service for the given receiver type under the given name. When no
 // methods on the given receiver match the criteria to be either a RPC method or a
 // subscription an error is returned. Otherwise a new service is created and added to the
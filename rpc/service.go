@@ -24,6 +24,7 @@ import (
 	"sync"
 	"unicode"
 
+	"github.com/ethereum/go-ethereum/common/lru"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -37,6 +38,13 @@ var (
 type serviceRegistry struct {
 	mu       sync.Mutex
 	services map[string]service
+	resolver NameResolver // resolves "@name" call parameters, may be nil
+	lenient  bool         // accept hex call parameters lacking the 0x prefix
+	subLimit int          // maximum number of subscriptions per connection, 0 if unlimited
+
+	aliases    map[string]string               // deprecated method names mapped to the methods serving them
+	deprecated map[string]*deprecation         // deprecated methods, including aliases
+	warned     *lru.BasicLRU[string, struct{}] // method and client combinations warned about deprecation
 }
 
 // service represents a registered object.
@@ -92,13 +100,39 @@ func (r *serviceRegistry) registerName(name string, rcvr interface{}) error {
 
 // callback returns the callback corresponding to the given RPC method name.
 func (r *serviceRegistry) callback(method string) *callback {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if target, ok := r.aliases[method]; ok {
+		method = target
+	}
 	module, mthd, err := elementizeMethodName(method)
 	if err != nil {
 		return nil
 	}
+	return r.services[module].callbacks[mthd]
+}
+
+// nameResolver returns the resolver of "@name" call parameters.
+func (r *serviceRegistry) nameResolver() NameResolver {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	return r.services[module].callbacks[mthd]
+	return r.resolver
+}
+
+// lenientParams reports whether hex call parameters lacking the 0x prefix are
+// accepted.
+func (r *serviceRegistry) lenientParams() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lenient
+}
+
+// subscriptionLimit returns the maximum number of subscriptions per connection.
+func (r *serviceRegistry) subscriptionLimit() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.subLimit
 }
 
 // subscription returns a subscription callback in the given service.
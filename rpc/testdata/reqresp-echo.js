@@ -1,10 +1,10 @@
 // This test calls the test_echo method.
 
 --> {"jsonrpc": "2.0", "id": 2, "method": "test_echo", "params": []}
-<-- {"jsonrpc":"2.0","id":2,"error":{"code":-32602,"message":"missing value for required argument 0"}}
+<-- {"jsonrpc":"2.0","id":2,"error":{"code":-32602,"message":"missing value for required argument 0","data":{"argument":0,"expected":"string","reason":"missing value"}}}
 
 --> {"jsonrpc": "2.0", "id": 2, "method": "test_echo", "params": ["x"]}
-<-- {"jsonrpc":"2.0","id":2,"error":{"code":-32602,"message":"missing value for required argument 1"}}
+<-- {"jsonrpc":"2.0","id":2,"error":{"code":-32602,"message":"missing value for required argument 1","data":{"argument":1,"expected":"number","reason":"missing value"}}}
 
 --> {"jsonrpc": "2.0", "id": 2, "method": "test_echo", "params": ["x", 3]}
 <-- {"jsonrpc":"2.0","id":2,"result":{"String":"x","Int":3,"Args":null}}
@@ -35,6 +35,15 @@ type API struct {
 	Service       interface{} // receiver instance which holds the methods
 	Public        bool        // deprecated - this field is no longer used, but retained for compatibility
 	Authenticated bool        // whether the api should only be available behind authentication.
+
+	// Aliases maps deprecated method names to the methods of the API serving
+	// them, e.g. "admin_oldName" to "admin_newName". Calls of an alias are
+	// flagged as deprecated in favor of the method.
+	Aliases map[string]string
+
+	// Deprecated maps the deprecated methods of the API to their replacements,
+	// which may be empty if there is none.
+	Deprecated map[string]string
 }
 
 // ServerCodec implements reading, parsing and writing RPC messages for the server side of
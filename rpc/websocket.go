@@ -225,6 +225,7 @@ func newClientTransportWS(endpoint string, cfg *clientConfig) (reconnectFunc, er
 			WriteBufferSize: wsWriteBuffer,
 			WriteBufferPool: wsBufferPool,
 			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: cfg.tlsConfig,
 		}
 	}
 
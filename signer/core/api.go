@@ -557,6 +557,9 @@ func (api *SignerAPI) SignTransaction(ctx context.Context, args apitypes.SendTxA
 			return nil, err
 		}
 	}
+	// Transactions are only signed, replay protected, for the configured chain id.
+	// The node's guard against signatures replayable on a sibling chain (e.g.
+	// ETH/ETC) is not applied here, so the chain id must match the node's chain.
 	if args.ChainID != nil {
 		requestedChainId := (*big.Int)(args.ChainID)
 		if api.chainID.Cmp(requestedChainId) != 0 {
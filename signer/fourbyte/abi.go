@@ -43,14 +43,17 @@ type decodedArgument struct {
 
 // String implements stringer interface, tries to use the underlying value-type
 func (arg decodedArgument) String() string {
-	var value string
+	return fmt.Sprintf("%v: %v", arg.soltype.Type.String(), arg.valueString())
+}
+
+// valueString formats the decoded value, using its own stringer if available.
+func (arg decodedArgument) valueString() string {
 	switch val := arg.value.(type) {
 	case fmt.Stringer:
-		value = val.String()
+		return val.String()
 	default:
-		value = fmt.Sprintf("%v", val)
+		return fmt.Sprintf("%v", val)
 	}
-	return fmt.Sprintf("%v: %v", arg.soltype.Type.String(), value)
 }
 
 // String implements stringer interface for decodedCallData
@@ -114,6 +114,75 @@ func (db *Database) Selector(id []byte) (string, error) {
 	return "", fmt.Errorf("signature %v not found", sig)
 }
 
+// Selectors returns all the known ABI methods matching the given 4byte ID, the
+// embedded one first. Normally there is at most a single candidate, but the
+// custom dataset may define a different signature colliding with an embedded one.
+func (db *Database) Selectors(id []byte) []string {
+	if len(id) < 4 {
+		return nil
+	}
+	var (
+		sig       = hex.EncodeToString(id[:4])
+		selectors []string
+	)
+	if selector, exists := db.embedded[sig]; exists {
+		selectors = append(selectors, selector)
+	}
+	if selector, exists := db.custom[sig]; exists && (len(selectors) == 0 || selectors[0] != selector) {
+		selectors = append(selectors, selector)
+	}
+	return selectors
+}
+
+// DecodedCall is a method call decoded according to a signature from the database.
+type DecodedCall struct {
+	Signature string       `json:"signature"`
+	Name      string       `json:"name"`
+	Args      []DecodedArg `json:"args"`
+}
+
+// DecodedArg is a single decoded argument of a method call.
+type DecodedArg struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Decode looks up the method selector of the call data and decodes the arguments
+// according to each known candidate signature. Only the candidates which decode
+// the call data exactly are returned.
+func (db *Database) Decode(calldata []byte) ([]DecodedCall, error) {
+	if len(calldata) < 4 {
+		return nil, fmt.Errorf("expected at least 4 bytes of call data, got %d", len(calldata))
+	}
+	selectors := db.Selectors(calldata[:4])
+	if len(selectors) == 0 {
+		return nil, fmt.Errorf("signature %x not found", calldata[:4])
+	}
+	var (
+		calls []DecodedCall
+		err   error
+	)
+	for _, selector := range selectors {
+		var decoded *decodedCallData
+		if decoded, err = verifySelector(selector, calldata); err != nil {
+			continue
+		}
+		call := DecodedCall{
+			Signature: decoded.signature,
+			Name:      decoded.name,
+			Args:      make([]DecodedArg, len(decoded.inputs)),
+		}
+		for i, arg := range decoded.inputs {
+			call.Args[i] = DecodedArg{Type: arg.soltype.Type.String(), Value: arg.valueString()}
+		}
+		calls = append(calls, call)
+	}
+	if len(calls) == 0 {
+		return nil, err
+	}
+	return calls, nil
+}
+
 // AddSelector inserts a new 4byte entry into the database. If custom database
 // saving is enabled, the new dataset is also persisted to disk.
 //
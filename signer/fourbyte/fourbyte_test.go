@@ -87,3 +87,40 @@ func TestCustomDatabase(t *testing.T) {
 		t.Fatalf("Failed to find a match for persisted abi signature: %v", err)
 	}
 }
+
+// Tests that call data is decoded against all the candidate signatures.
+func TestDecode(t *testing.T) {
+	t.Parallel()
+	db := newEmpty()
+	db.embedded["a9059cbb"] = "transfer(address,uint256)"
+	db.custom["a9059cbb"] = "many_msg_babbage(bytes1)"
+
+	calldata := common.Hex2Bytes("a9059cbb" +
+		"000000000000000000000000000000000000000000000000000000000000dead" +
+		"0000000000000000000000000000000000000000000000000000000000000005")
+	calls, err := db.Decode(calldata)
+	if err != nil {
+		t.Fatalf("Failed to decode call data: %v", err)
+	}
+	if len(calls) != 1 {
+		t.Fatalf("Candidate count mismatch: have %d, want 1", len(calls))
+	}
+	if calls[0].Signature != "transfer(address,uint256)" || calls[0].Name != "transfer" {
+		t.Errorf("Decoded method mismatch: have %s (%s)", calls[0].Signature, calls[0].Name)
+	}
+	want := []DecodedArg{
+		{Type: "address", Value: "0x000000000000000000000000000000000000dEaD"},
+		{Type: "uint256", Value: "5"},
+	}
+	if len(calls[0].Args) != len(want) {
+		t.Fatalf("Argument count mismatch: have %d, want %d", len(calls[0].Args), len(want))
+	}
+	for i, arg := range calls[0].Args {
+		if arg != want[i] {
+			t.Errorf("Argument %d mismatch: have %v, want %v", i, arg, want[i])
+		}
+	}
+	if _, err := db.Decode(common.Hex2Bytes("deadbeef")); err == nil {
+		t.Errorf("Decoded call data with unknown selector")
+	}
+}
@@ -0,0 +1,840 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package trie
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie/trienode"
+	"github.com/ethereum/go-ethereum/triedb/database"
+)
+
+const (
+	binInternalTag = 0x00 // Leading byte of an encoded internal node
+	binLeafTag     = 0x01 // Leading byte of an encoded leaf node
+
+	binInternalSize = 1 + 2*common.HashLength // Size of an encoded internal node
+)
+
+var errBinaryNotAtLeaf = errors.New("binary iterator is not positioned at a leaf")
+
+// BinaryTrie is an experimental binary merkle trie implementing the state.Trie
+// interface, used to research alternative state layouts on private networks.
+//
+// Keys are hashed with keccak256 like in the StateTrie and walked bit by bit,
+// most significant bit first. Every leaf lives at the shallowest depth where
+// its key is unique, so the shape of the trie only depends on its content.
+// Nodes are encoded as
+//
+//	internal: 0x00 || hash(left) || hash(right)
+//	leaf:     0x01 || keccak256(key) || value
+//
+// with the zero hash standing in for an empty child. The hash of a node is the
+// keccak256 of its encoding and the root of an empty trie is EmptyRootHash, so
+// the code treating empty storage roots specially keeps working unchanged.
+//
+// Nodes are never modified in place, copying a trie is therefore cheap and the
+// copies can be used independently.
+type BinaryTrie struct {
+	root        binNode
+	owner       common.Hash
+	committed   bool
+	reader      *trieReader
+	db          database.Database
+	secKeyCache map[string][]byte
+}
+
+type (
+	binNode interface{}
+
+	binInternal struct {
+		left, right binNode
+		hash        common.Hash // Cached node hash, zero if not yet computed
+		dirty       bool        // Flag whether the node is not yet committed
+	}
+	binLeaf struct {
+		key   []byte // Hashed key of the leaf
+		value []byte
+		hash  common.Hash
+		dirty bool
+	}
+	binHashNode common.Hash
+)
+
+// child returns the child of the internal node at the given side.
+func (n *binInternal) child(bit byte) binNode {
+	if bit == 0 {
+		return n.left
+	}
+	return n.right
+}
+
+// withChild returns a dirty copy of the internal node with the child at the
+// given side replaced.
+func (n *binInternal) withChild(bit byte, child binNode) *binInternal {
+	cpy := &binInternal{left: n.left, right: n.right, dirty: true}
+	if bit == 0 {
+		cpy.left = child
+	} else {
+		cpy.right = child
+	}
+	return cpy
+}
+
+// NewBinaryTrie creates a binary trie with the root and owner specified by id,
+// resolving the nodes on demand from the given database.
+func NewBinaryTrie(id *ID, db database.Database) (*BinaryTrie, error) {
+	reader, err := newTrieReader(id.StateRoot, id.Owner, db)
+	if err != nil {
+		return nil, err
+	}
+	t := &BinaryTrie{
+		owner:  id.Owner,
+		reader: reader,
+		db:     db,
+	}
+	if id.Root != (common.Hash{}) && id.Root != types.EmptyRootHash {
+		root, err := t.resolve(nil, id.Root)
+		if err != nil {
+			return nil, err
+		}
+		t.root = root
+	}
+	return t, nil
+}
+
+// GetKey returns the sha3 preimage of a hashed key that was previously used
+// to store a value.
+func (t *BinaryTrie) GetKey(shaKey []byte) []byte {
+	if key, ok := t.getSecKeyCache()[string(shaKey)]; ok {
+		return key
+	}
+	return t.db.Preimage(common.BytesToHash(shaKey))
+}
+
+// GetAccount implements state.Trie, retrieving the account with the specified
+// address. If the account is not in the trie, nil will be returned.
+func (t *BinaryTrie) GetAccount(address common.Address) (*types.StateAccount, error) {
+	res, err := t.get(crypto.Keccak256(address.Bytes()))
+	if res == nil || err != nil {
+		return nil, err
+	}
+	ret := new(types.StateAccount)
+	err = rlp.DecodeBytes(res, ret)
+	return ret, err
+}
+
+// GetStorage implements state.Trie, retrieving the storage slot with the
+// specified key. If the slot is not in the trie, nil will be returned.
+func (t *BinaryTrie) GetStorage(_ common.Address, key []byte) ([]byte, error) {
+	enc, err := t.get(crypto.Keccak256(key))
+	if err != nil || len(enc) == 0 {
+		return nil, err
+	}
+	_, content, _, err := rlp.Split(enc)
+	return content, err
+}
+
+// UpdateAccount implements state.Trie, writing the provided account into the
+// trie.
+func (t *BinaryTrie) UpdateAccount(address common.Address, acc *types.StateAccount) error {
+	data, err := rlp.EncodeToBytes(acc)
+	if err != nil {
+		return err
+	}
+	return t.update(address.Bytes(), data)
+}
+
+// UpdateStorage implements state.Trie, writing the provided storage slot into
+// the trie. The value is stored rlp-encoded like in the StateTrie.
+func (t *BinaryTrie) UpdateStorage(_ common.Address, key, value []byte) error {
+	v, _ := rlp.EncodeToBytes(value)
+	return t.update(key, v)
+}
+
+// UpdateContractCode implements state.Trie. Contract code is not part of the
+// binary trie, it's a no-op.
+func (t *BinaryTrie) UpdateContractCode(_ common.Address, _ common.Hash, _ []byte) error {
+	return nil
+}
+
+// DeleteAccount implements state.Trie, removing the account from the trie.
+func (t *BinaryTrie) DeleteAccount(address common.Address) error {
+	return t.delete(address.Bytes())
+}
+
+// DeleteStorage implements state.Trie, removing the storage slot from the trie.
+func (t *BinaryTrie) DeleteStorage(_ common.Address, key []byte) error {
+	return t.delete(key)
+}
+
+// get retrieves the value of the given hashed key, caching the resolved nodes
+// in the trie.
+func (t *BinaryTrie) get(key []byte) ([]byte, error) {
+	if t.committed {
+		return nil, ErrCommitted
+	}
+	value, newroot, didResolve, err := t.getAt(t.root, nil, key)
+	if err == nil && didResolve {
+		t.root = newroot
+	}
+	return value, err
+}
+
+func (t *BinaryTrie) getAt(n binNode, path []byte, key []byte) ([]byte, binNode, bool, error) {
+	switch n := n.(type) {
+	case nil:
+		return nil, nil, false, nil
+	case *binLeaf:
+		if bytes.Equal(n.key, key) {
+			return n.value, n, false, nil
+		}
+		return nil, n, false, nil
+	case *binInternal:
+		bit := keyBit(key, len(path))
+		value, child, didResolve, err := t.getAt(n.child(bit), binChildPath(path, bit), key)
+		if err == nil && didResolve {
+			cpy := *n
+			if bit == 0 {
+				cpy.left = child
+			} else {
+				cpy.right = child
+			}
+			return value, &cpy, true, nil
+		}
+		return value, n, false, err
+	case binHashNode:
+		child, err := t.resolve(path, common.Hash(n))
+		if err != nil {
+			return nil, n, false, err
+		}
+		value, child, _, err := t.getAt(child, path, key)
+		return value, child, true, err
+	default:
+		panic(fmt.Sprintf("%T: invalid binary node: %v", n, n))
+	}
+}
+
+// UpdateHashed inserts the value under an already hashed key. It's meant for
+// converting states whose key preimages are unknown, the value is stored as
+// is without any further encoding.
+func (t *BinaryTrie) UpdateHashed(hashedKey, value []byte) error {
+	if t.committed {
+		return ErrCommitted
+	}
+	root, err := t.insert(t.root, nil, common.CopyBytes(hashedKey), value)
+	if err != nil {
+		return err
+	}
+	t.root = root
+	return nil
+}
+
+// update hashes the key, records its preimage and inserts the value.
+func (t *BinaryTrie) update(key, value []byte) error {
+	hk := crypto.Keccak256(key)
+	if err := t.UpdateHashed(hk, value); err != nil {
+		return err
+	}
+	t.getSecKeyCache()[string(hk)] = common.CopyBytes(key)
+	return nil
+}
+
+func (t *BinaryTrie) insert(n binNode, path []byte, key, value []byte) (binNode, error) {
+	switch n := n.(type) {
+	case nil:
+		return &binLeaf{key: key, value: value, dirty: true}, nil
+	case *binLeaf:
+		if !bytes.Equal(n.key, key) {
+			return splitBinLeaves(n, &binLeaf{key: key, value: value, dirty: true}, len(path)), nil
+		}
+		if bytes.Equal(n.value, value) {
+			return n, nil
+		}
+		return &binLeaf{key: key, value: value, dirty: true}, nil
+	case *binInternal:
+		bit := keyBit(key, len(path))
+		child, err := t.insert(n.child(bit), binChildPath(path, bit), key, value)
+		if err != nil {
+			return n, err
+		}
+		if child == n.child(bit) {
+			return n, nil
+		}
+		return n.withChild(bit, child), nil
+	case binHashNode:
+		resolved, err := t.resolve(path, common.Hash(n))
+		if err != nil {
+			return n, err
+		}
+		return t.insert(resolved, path, key, value)
+	default:
+		panic(fmt.Sprintf("%T: invalid binary node: %v", n, n))
+	}
+}
+
+// splitBinLeaves creates the internal nodes needed to hold two leaves with
+// different keys sharing the path up to the given depth.
+func splitBinLeaves(a, b *binLeaf, depth int) binNode {
+	n := new(binInternal)
+	abit, bbit := keyBit(a.key, depth), keyBit(b.key, depth)
+	if abit == bbit {
+		return n.withChild(abit, splitBinLeaves(a, b, depth+1))
+	}
+	return n.withChild(abit, a).withChild(bbit, b)
+}
+
+// delete hashes the key and removes it from the trie.
+func (t *BinaryTrie) delete(key []byte) error {
+	if t.committed {
+		return ErrCommitted
+	}
+	hk := crypto.Keccak256(key)
+	root, err := t.remove(t.root, nil, hk)
+	if err != nil {
+		return err
+	}
+	t.root = root
+	delete(t.getSecKeyCache(), string(hk))
+	return nil
+}
+
+func (t *BinaryTrie) remove(n binNode, path []byte, key []byte) (binNode, error) {
+	switch n := n.(type) {
+	case nil:
+		return nil, nil
+	case *binLeaf:
+		if bytes.Equal(n.key, key) {
+			return nil, nil
+		}
+		return n, nil
+	case *binInternal:
+		bit := keyBit(key, len(path))
+		child, err := t.remove(n.child(bit), binChildPath(path, bit), key)
+		if err != nil {
+			return n, err
+		}
+		if child == n.child(bit) {
+			return n, nil
+		}
+		// An internal node must hold at least two leaves below it, collapse
+		// it if only a single leaf is left.
+		sibling := n.child(1 - bit)
+		if child == nil {
+			if hash, ok := sibling.(binHashNode); ok {
+				if sibling, err = t.resolve(binChildPath(path, 1-bit), common.Hash(hash)); err != nil {
+					return n, err
+				}
+			}
+			if leaf, ok := sibling.(*binLeaf); ok {
+				return leaf, nil
+			}
+			return n.withChild(bit, nil).withChild(1-bit, sibling), nil
+		}
+		if leaf, ok := child.(*binLeaf); ok && sibling == nil {
+			return leaf, nil
+		}
+		return n.withChild(bit, child), nil
+	case binHashNode:
+		resolved, err := t.resolve(path, common.Hash(n))
+		if err != nil {
+			return n, err
+		}
+		return t.remove(resolved, path, key)
+	default:
+		panic(fmt.Sprintf("%T: invalid binary node: %v", n, n))
+	}
+}
+
+// resolve loads the node with the given hash from the database.
+func (t *BinaryTrie) resolve(path []byte, hash common.Hash) (binNode, error) {
+	blob, err := t.reader.node(path, hash)
+	if err != nil {
+		return nil, err
+	}
+	return decodeBinNode(hash, blob)
+}
+
+// Hash returns the root hash of the trie. It does not write to the database
+// and can be used even if the trie doesn't have one.
+func (t *BinaryTrie) Hash() common.Hash {
+	if t.root == nil {
+		return types.EmptyRootHash
+	}
+	hash, cached := hashBinNode(t.root)
+	t.root = cached
+	return hash
+}
+
+// hashBinNode computes the hash of the node, returning a copy of the node
+// with the hashes of the node and all of its descendants cached.
+func hashBinNode(n binNode) (common.Hash, binNode) {
+	switch n := n.(type) {
+	case nil:
+		return common.Hash{}, nil
+	case binHashNode:
+		return common.Hash(n), n
+	case *binLeaf:
+		if n.hash != (common.Hash{}) {
+			return n.hash, n
+		}
+		cpy := *n
+		cpy.hash = crypto.Keccak256Hash(encodeBinNode(n))
+		return cpy.hash, &cpy
+	case *binInternal:
+		if n.hash != (common.Hash{}) {
+			return n.hash, n
+		}
+		cpy := &binInternal{dirty: n.dirty}
+		_, cpy.left = hashBinNode(n.left)
+		_, cpy.right = hashBinNode(n.right)
+		cpy.hash = crypto.Keccak256Hash(encodeBinNode(cpy))
+		return cpy.hash, cpy
+	default:
+		panic(fmt.Sprintf("%T: invalid binary node: %v", n, n))
+	}
+}
+
+// binNodeHash returns the hash of an already hashed node, or the zero hash
+// for an empty one.
+func binNodeHash(n binNode) common.Hash {
+	switch n := n.(type) {
+	case nil:
+		return common.Hash{}
+	case binHashNode:
+		return common.Hash(n)
+	case *binLeaf:
+		return n.hash
+	case *binInternal:
+		return n.hash
+	default:
+		panic(fmt.Sprintf("%T: invalid binary node: %v", n, n))
+	}
+}
+
+// encodeBinNode returns the encoding of a node. The children of an internal
+// node must already be hashed.
+func encodeBinNode(n binNode) []byte {
+	switch n := n.(type) {
+	case *binLeaf:
+		blob := make([]byte, 0, 1+len(n.key)+len(n.value))
+		blob = append(blob, binLeafTag)
+		blob = append(blob, n.key...)
+		return append(blob, n.value...)
+	case *binInternal:
+		left, right := binNodeHash(n.left), binNodeHash(n.right)
+		blob := make([]byte, 0, binInternalSize)
+		blob = append(blob, binInternalTag)
+		blob = append(blob, left[:]...)
+		return append(blob, right[:]...)
+	default:
+		panic(fmt.Sprintf("%T: cannot encode binary node: %v", n, n))
+	}
+}
+
+// decodeBinNode parses the encoding of a node with the given hash.
+func decodeBinNode(hash common.Hash, blob []byte) (binNode, error) {
+	switch {
+	case len(blob) == binInternalSize && blob[0] == binInternalTag:
+		n := &binInternal{hash: hash}
+		if left := common.BytesToHash(blob[1 : 1+common.HashLength]); left != (common.Hash{}) {
+			n.left = binHashNode(left)
+		}
+		if right := common.BytesToHash(blob[1+common.HashLength:]); right != (common.Hash{}) {
+			n.right = binHashNode(right)
+		}
+		return n, nil
+	case len(blob) > common.HashLength && blob[0] == binLeafTag:
+		return &binLeaf{
+			key:   common.CopyBytes(blob[1 : 1+common.HashLength]),
+			value: common.CopyBytes(blob[1+common.HashLength:]),
+			hash:  hash,
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid binary trie node %x", hash)
+	}
+}
+
+// Commit collects all dirty nodes in the trie and replaces them with the
+// corresponding node hash. All collected nodes (including dirty leaves if
+// collectLeaf is true) will be encapsulated into a nodeset for return.
+// The returned nodeset can be nil if the trie is clean (nothing to commit).
+// Once the trie is committed, it's not usable anymore.
+func (t *BinaryTrie) Commit(collectLeaf bool) (common.Hash, *trienode.NodeSet, error) {
+	if len(t.getSecKeyCache()) > 0 {
+		preimages := make(map[common.Hash][]byte)
+		for hk, key := range t.secKeyCache {
+			preimages[common.BytesToHash([]byte(hk))] = key
+		}
+		t.db.InsertPreimage(preimages)
+		t.secKeyCache = make(map[string][]byte)
+	}
+	defer func() { t.committed = true }()
+
+	root := t.Hash()
+	if root == types.EmptyRootHash {
+		return root, nil, nil
+	}
+	nodes := trienode.NewNodeSet(t.owner)
+	commitBinNode(t.root, nil, nodes, collectLeaf)
+	t.root = binHashNode(root)
+	if len(nodes.Nodes) == 0 {
+		return root, nil, nil
+	}
+	return root, nodes, nil
+}
+
+// commitBinNode adds the dirty nodes below and including n to the set.
+func commitBinNode(n binNode, path []byte, nodes *trienode.NodeSet, collectLeaf bool) {
+	switch n := n.(type) {
+	case *binInternal:
+		if !n.dirty {
+			return
+		}
+		commitBinNode(n.left, binChildPath(path, 0), nodes, collectLeaf)
+		commitBinNode(n.right, binChildPath(path, 1), nodes, collectLeaf)
+		nodes.AddNode(path, trienode.New(n.hash, encodeBinNode(n)))
+	case *binLeaf:
+		if !n.dirty {
+			return
+		}
+		nodes.AddNode(path, trienode.New(n.hash, encodeBinNode(n)))
+		if collectLeaf {
+			nodes.AddLeaf(n.hash, n.value)
+		}
+	}
+}
+
+// Prove constructs a proof for the given hashed key, writing all nodes on the
+// path to the key into proofDb, keyed by their hash. The last node is either
+// the leaf holding the key or the node proving its absence.
+func (t *BinaryTrie) Prove(key []byte, proofDb ethdb.KeyValueWriter) error {
+	if t.committed {
+		return ErrCommitted
+	}
+	t.Hash()
+
+	var (
+		path []byte
+		n    = t.root
+	)
+	for n != nil {
+		if hash, ok := n.(binHashNode); ok {
+			resolved, err := t.resolve(path, common.Hash(hash))
+			if err != nil {
+				return err
+			}
+			n = resolved
+			continue
+		}
+		if err := proofDb.Put(binNodeHash(n).Bytes(), encodeBinNode(n)); err != nil {
+			return err
+		}
+		internal, ok := n.(*binInternal)
+		if !ok {
+			break
+		}
+		bit := keyBit(key, len(path))
+		n, path = internal.child(bit), binChildPath(path, bit)
+	}
+	return nil
+}
+
+// VerifyBinaryProof checks a proof created by BinaryTrie.Prove against the
+// given root hash, returning the value stored for the hashed key, or nil if
+// the proof shows the key is absent.
+func VerifyBinaryProof(root common.Hash, key []byte, proofDb ethdb.KeyValueReader) ([]byte, error) {
+	if root == types.EmptyRootHash {
+		return nil, nil
+	}
+	hash := root
+	for depth := 0; ; depth++ {
+		blob, _ := proofDb.Get(hash[:])
+		if blob == nil {
+			return nil, fmt.Errorf("proof node %d (hash %064x) missing", depth, hash)
+		}
+		if crypto.Keccak256Hash(blob) != hash {
+			return nil, fmt.Errorf("bad proof node %d: hash mismatch", depth)
+		}
+		n, err := decodeBinNode(hash, blob)
+		if err != nil {
+			return nil, fmt.Errorf("bad proof node %d: %v", depth, err)
+		}
+		switch n := n.(type) {
+		case *binLeaf:
+			if bytes.Equal(n.key, key) {
+				return n.value, nil
+			}
+			return nil, nil
+		case *binInternal:
+			child := n.child(keyBit(key, depth))
+			if child == nil {
+				return nil, nil
+			}
+			hash = common.Hash(child.(binHashNode))
+		}
+	}
+}
+
+// Copy returns a copy of the trie.
+func (t *BinaryTrie) Copy() *BinaryTrie {
+	return &BinaryTrie{
+		root:        t.root,
+		owner:       t.owner,
+		committed:   t.committed,
+		reader:      t.reader,
+		db:          t.db,
+		secKeyCache: t.secKeyCache,
+	}
+}
+
+// getSecKeyCache returns the current secure key cache, creating a new one if
+// ownership changed (i.e. the current trie is a copy of another owner).
+func (t *BinaryTrie) getSecKeyCache() map[string][]byte {
+	if t.secKeyCache == nil {
+		t.secKeyCache = make(map[string][]byte)
+	}
+	return t.secKeyCache
+}
+
+// NodeIterator returns an iterator over the nodes of the trie in pre-order.
+// Iteration starts at the key after the given start key.
+func (t *BinaryTrie) NodeIterator(start []byte) (NodeIterator, error) {
+	if t.committed {
+		return nil, ErrCommitted
+	}
+	t.Hash()
+	return &binIterator{trie: t, start: start}, nil
+}
+
+// keyBit returns the bit of the key at the given depth.
+func keyBit(key []byte, depth int) byte {
+	return (key[depth/8] >> (7 - depth%8)) & 1
+}
+
+// binChildPath returns the path of the child at the given side.
+func binChildPath(path []byte, bit byte) []byte {
+	child := make([]byte, len(path)+1)
+	copy(child, path)
+	child[len(path)] = bit
+	return child
+}
+
+// BinaryResolver is the children resolver of binary trie nodes.
+type BinaryResolver struct{}
+
+// ForEach implements childResolver, decodes the provided node and
+// traverses the children inside.
+func (resolver BinaryResolver) ForEach(node []byte, onChild func(common.Hash)) {
+	if len(node) != binInternalSize || node[0] != binInternalTag {
+		return
+	}
+	for _, child := range [][]byte{node[1 : 1+common.HashLength], node[1+common.HashLength:]} {
+		if hash := common.BytesToHash(child); hash != (common.Hash{}) {
+			onChild(hash)
+		}
+	}
+}
+
+// binIteratorEntry is a node on the path of the binary iterator.
+type binIteratorEntry struct {
+	node   binNode
+	parent common.Hash
+	path   []byte
+}
+
+// binIterator is a pre-order iterator over the nodes of a binary trie.
+type binIterator struct {
+	trie    *BinaryTrie
+	start   []byte
+	stack   []*binIteratorEntry
+	started bool
+	err     error
+}
+
+// Next moves the iterator to the next node. If descend is false, the children
+// of the current node are skipped.
+func (it *binIterator) Next(descend bool) bool {
+	for it.step(descend) {
+		if !it.beforeStart(it.stack[len(it.stack)-1]) {
+			return true
+		}
+		descend = false
+	}
+	return false
+}
+
+func (it *binIterator) step(descend bool) bool {
+	if it.err != nil {
+		return false
+	}
+	if len(it.stack) == 0 {
+		if it.started || it.trie.root == nil {
+			return false
+		}
+		it.started = true
+		return it.push(it.trie.root, nil, common.Hash{})
+	}
+	top := it.stack[len(it.stack)-1]
+	if internal, ok := top.node.(*binInternal); ok && descend {
+		if internal.left != nil {
+			return it.push(internal.left, binChildPath(top.path, 0), internal.hash)
+		}
+		return it.push(internal.right, binChildPath(top.path, 1), internal.hash)
+	}
+	for len(it.stack) > 1 {
+		last := it.stack[len(it.stack)-1]
+		it.stack = it.stack[:len(it.stack)-1]
+
+		parent := it.stack[len(it.stack)-1]
+		internal := parent.node.(*binInternal)
+		if last.path[len(last.path)-1] == 0 && internal.right != nil {
+			return it.push(internal.right, binChildPath(parent.path, 1), internal.hash)
+		}
+	}
+	it.stack = it.stack[:0]
+	return false
+}
+
+func (it *binIterator) push(n binNode, path []byte, parent common.Hash) bool {
+	if hash, ok := n.(binHashNode); ok {
+		resolved, err := it.trie.resolve(path, common.Hash(hash))
+		if err != nil {
+			it.err = err
+			return false
+		}
+		n = resolved
+	}
+	it.stack = append(it.stack, &binIteratorEntry{node: n, parent: parent, path: path})
+	return true
+}
+
+// beforeStart reports whether the whole subtree of the entry is positioned
+// before the start key.
+func (it *binIterator) beforeStart(entry *binIteratorEntry) bool {
+	if len(it.start) == 0 {
+		return false
+	}
+	for i, bit := range entry.path {
+		if i/8 >= len(it.start) {
+			break
+		}
+		if want := keyBit(it.start, i); bit != want {
+			return bit < want
+		}
+	}
+	if leaf, ok := entry.node.(*binLeaf); ok {
+		return bytes.Compare(leaf.key, it.start) < 0
+	}
+	return false
+}
+
+func (it *binIterator) current() *binIteratorEntry {
+	if len(it.stack) == 0 {
+		return nil
+	}
+	return it.stack[len(it.stack)-1]
+}
+
+// Error returns the error status of the iterator.
+func (it *binIterator) Error() error {
+	return it.err
+}
+
+// Hash returns the hash of the current node.
+func (it *binIterator) Hash() common.Hash {
+	if entry := it.current(); entry != nil {
+		return binNodeHash(entry.node)
+	}
+	return common.Hash{}
+}
+
+// Parent returns the hash of the parent of the current node.
+func (it *binIterator) Parent() common.Hash {
+	if entry := it.current(); entry != nil {
+		return entry.parent
+	}
+	return common.Hash{}
+}
+
+// Path returns the bit path to the current node, one byte per bit.
+func (it *binIterator) Path() []byte {
+	if entry := it.current(); entry != nil {
+		return entry.path
+	}
+	return nil
+}
+
+// NodeBlob returns the encoding of the current node.
+func (it *binIterator) NodeBlob() []byte {
+	if entry := it.current(); entry != nil {
+		return encodeBinNode(entry.node)
+	}
+	return nil
+}
+
+// Leaf returns true iff the current node is a leaf node.
+func (it *binIterator) Leaf() bool {
+	if entry := it.current(); entry != nil {
+		_, ok := entry.node.(*binLeaf)
+		return ok
+	}
+	return false
+}
+
+// LeafKey returns the hashed key of the leaf. The method panics if the
+// iterator is not positioned at a leaf.
+func (it *binIterator) LeafKey() []byte {
+	return it.leaf().key
+}
+
+// LeafBlob returns the value of the leaf. The method panics if the iterator
+// is not positioned at a leaf.
+func (it *binIterator) LeafBlob() []byte {
+	return it.leaf().value
+}
+
+// LeafProof returns the encoding of all nodes on the path to the leaf. The
+// method panics if the iterator is not positioned at a leaf.
+func (it *binIterator) LeafProof() [][]byte {
+	it.leaf()
+	proofs := make([][]byte, 0, len(it.stack))
+	for _, entry := range it.stack {
+		proofs = append(proofs, encodeBinNode(entry.node))
+	}
+	return proofs
+}
+
+func (it *binIterator) leaf() *binLeaf {
+	if entry := it.current(); entry != nil {
+		if leaf, ok := entry.node.(*binLeaf); ok {
+			return leaf
+		}
+	}
+	panic(errBinaryNotAtLeaf)
+}
+
+// AddResolver is a no-op, binary tries are always resolved from the trie
+// database.
+func (it *binIterator) AddResolver(NodeResolver) {}
@@ -0,0 +1,242 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package trie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/trie/trienode"
+	"github.com/holiman/uint256"
+)
+
+func newEmptyBinaryTrie(t *testing.T, db *testDb) *BinaryTrie {
+	tr, err := NewBinaryTrie(TrieID(types.EmptyRootHash), db)
+	if err != nil {
+		t.Fatalf("Failed to create binary trie: %v", err)
+	}
+	return tr
+}
+
+func mustUpdateBinary(t *BinaryTrie, key, value []byte) {
+	if err := t.update(key, value); err != nil {
+		panic(err)
+	}
+}
+
+func binaryTestKey(i int) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(i))
+}
+
+func TestBinaryTrieReadWrite(t *testing.T) {
+	tr := newEmptyBinaryTrie(t, newTestDatabase(rawdb.NewMemoryDatabase(), rawdb.HashScheme))
+
+	for addr, acct := range accounts {
+		if err := tr.UpdateAccount(addr, acct); err != nil {
+			t.Fatalf("Failed to update account: %v", err)
+		}
+	}
+	for addr, acct := range accounts {
+		stored, err := tr.GetAccount(addr)
+		if err != nil {
+			t.Fatalf("Failed to get account: %v", err)
+		}
+		if stored.Nonce != acct.Nonce || stored.Balance.Cmp(acct.Balance) != 0 || !bytes.Equal(stored.CodeHash, acct.CodeHash) {
+			t.Fatalf("Account mismatch: have %v, want %v", stored, acct)
+		}
+	}
+	if acct, err := tr.GetAccount(common.Address{0xff}); acct != nil || err != nil {
+		t.Fatalf("Unexpected missing account: %v %v", acct, err)
+	}
+	for key, val := range storages[common.Address{1}] {
+		if err := tr.UpdateStorage(common.Address{}, key.Bytes(), val); err != nil {
+			t.Fatalf("Failed to update storage: %v", err)
+		}
+	}
+	for key, val := range storages[common.Address{1}] {
+		stored, err := tr.GetStorage(common.Address{}, key.Bytes())
+		if err != nil {
+			t.Fatalf("Failed to get storage: %v", err)
+		}
+		if !bytes.Equal(stored, val) {
+			t.Fatalf("Storage mismatch: have %x, want %x", stored, val)
+		}
+	}
+	// Deleting everything must restore the empty root
+	for addr := range accounts {
+		if err := tr.DeleteAccount(addr); err != nil {
+			t.Fatalf("Failed to delete account: %v", err)
+		}
+	}
+	for key := range storages[common.Address{1}] {
+		if err := tr.DeleteStorage(common.Address{}, key.Bytes()); err != nil {
+			t.Fatalf("Failed to delete storage: %v", err)
+		}
+	}
+	if root := tr.Hash(); root != types.EmptyRootHash {
+		t.Fatalf("Root mismatch after deletion: have %x, want %x", root, types.EmptyRootHash)
+	}
+}
+
+// Tests that the root only depends on the content of the trie, not on the
+// order or history of the updates.
+func TestBinaryTrieCanonicalRoot(t *testing.T) {
+	db := newTestDatabase(rawdb.NewMemoryDatabase(), rawdb.HashScheme)
+
+	var (
+		a   = newEmptyBinaryTrie(t, db)
+		b   = newEmptyBinaryTrie(t, db)
+		rnd = rand.New(rand.NewSource(1))
+	)
+	for i := 0; i < 500; i++ {
+		mustUpdateBinary(a, binaryTestKey(i), []byte{byte(i), 1})
+	}
+	for _, i := range rnd.Perm(1000) {
+		mustUpdateBinary(b, binaryTestKey(i), []byte{byte(i), 1})
+	}
+	for i := 500; i < 1000; i++ {
+		if err := b.delete(binaryTestKey(i)); err != nil {
+			t.Fatalf("Failed to delete key %d: %v", i, err)
+		}
+	}
+	if a.Hash() != b.Hash() {
+		t.Fatalf("Root mismatch: %x != %x", a.Hash(), b.Hash())
+	}
+	// Single leaf tries are the leaf itself
+	c := newEmptyBinaryTrie(t, db)
+	mustUpdateBinary(c, binaryTestKey(0), []byte{1})
+
+	leaf := append([]byte{binLeafTag}, crypto.Keccak256(binaryTestKey(0))...)
+	if want := crypto.Keccak256Hash(append(leaf, 1)); c.Hash() != want {
+		t.Fatalf("Single leaf root mismatch: have %x, want %x", c.Hash(), want)
+	}
+}
+
+func TestBinaryTrieCommit(t *testing.T) {
+	db := newTestDatabase(rawdb.NewMemoryDatabase(), rawdb.HashScheme)
+	tr := newEmptyBinaryTrie(t, db)
+
+	for i := 0; i < 300; i++ {
+		mustUpdateBinary(tr, binaryTestKey(i), []byte{byte(i), 2})
+	}
+	root, nodes, err := tr.Commit(false)
+	if err != nil {
+		t.Fatalf("Failed to commit trie: %v", err)
+	}
+	if _, err := tr.get(crypto.Keccak256(binaryTestKey(0))); err != ErrCommitted {
+		t.Fatalf("Unexpected error for committed trie: %v", err)
+	}
+	db.Update(root, types.EmptyRootHash, trienode.NewWithNodeSet(nodes))
+	if err := db.Commit(root); err != nil {
+		t.Fatalf("Failed to commit database: %v", err)
+	}
+	// Reopen the trie from disk and check all content is retrievable
+	tr, err = NewBinaryTrie(TrieID(root), db)
+	if err != nil {
+		t.Fatalf("Failed to reopen trie: %v", err)
+	}
+	for i := 0; i < 300; i++ {
+		val, err := tr.get(crypto.Keccak256(binaryTestKey(i)))
+		if err != nil {
+			t.Fatalf("Failed to get key %d: %v", i, err)
+		}
+		if !bytes.Equal(val, []byte{byte(i), 2}) {
+			t.Fatalf("Value mismatch for key %d: %x", i, val)
+		}
+	}
+	// Iterate over the leaves, they must be ordered by hashed key
+	it, err := tr.NodeIterator(nil)
+	if err != nil {
+		t.Fatalf("Failed to create iterator: %v", err)
+	}
+	var (
+		leaves = NewIterator(it)
+		prev   []byte
+		count  int
+	)
+	for leaves.Next() {
+		if prev != nil && bytes.Compare(prev, leaves.Key) >= 0 {
+			t.Fatalf("Leaves out of order: %x >= %x", prev, leaves.Key)
+		}
+		prev = leaves.Key
+		count++
+	}
+	if leaves.Err != nil || count != 300 {
+		t.Fatalf("Iteration failed: %d leaves, err %v", count, leaves.Err)
+	}
+	// Iteration from a start key must skip the leaves before it
+	it, _ = tr.NodeIterator(prev)
+	if leaves = NewIterator(it); !leaves.Next() || !bytes.Equal(leaves.Key, prev) || leaves.Next() {
+		t.Fatalf("Iteration from the last leaf failed")
+	}
+	// Modify the reopened trie and verify proofs against the new root
+	mustUpdateBinary(tr, binaryTestKey(300), []byte{1})
+	if err := tr.delete(binaryTestKey(0)); err != nil {
+		t.Fatalf("Failed to delete key: %v", err)
+	}
+	for i, want := range map[int][]byte{1: {1, 2}, 300: {1}, 0: nil} {
+		proof := rawdb.NewMemoryDatabase()
+		key := crypto.Keccak256(binaryTestKey(i))
+		if err := tr.Prove(key, proof); err != nil {
+			t.Fatalf("Failed to prove key %d: %v", i, err)
+		}
+		val, err := VerifyBinaryProof(tr.Hash(), key, proof)
+		if err != nil {
+			t.Fatalf("Failed to verify proof of key %d: %v", i, err)
+		}
+		if !bytes.Equal(val, want) {
+			t.Fatalf("Proven value mismatch for key %d: have %x, want %x", i, val, want)
+		}
+	}
+}
+
+func TestBinaryResolver(t *testing.T) {
+	tr := newEmptyBinaryTrie(t, newTestDatabase(rawdb.NewMemoryDatabase(), rawdb.HashScheme))
+	tr.UpdateAccount(common.Address{1}, &types.StateAccount{Balance: uint256.NewInt(1), Root: types.EmptyRootHash, CodeHash: types.EmptyCodeHash[:]})
+	tr.UpdateAccount(common.Address{2}, &types.StateAccount{Balance: uint256.NewInt(2), Root: types.EmptyRootHash, CodeHash: types.EmptyCodeHash[:]})
+
+	root, nodes, err := tr.Commit(true)
+	if err != nil {
+		t.Fatalf("Failed to commit trie: %v", err)
+	}
+	if len(nodes.Leaves) != 2 {
+		t.Fatalf("Leaf count mismatch: have %d, want 2", len(nodes.Leaves))
+	}
+	// Every node hash referenced by an internal node must be in the set
+	hashes := make(map[common.Hash]bool)
+	for _, n := range nodes.Nodes {
+		hashes[n.Hash] = true
+	}
+	var children int
+	for _, n := range nodes.Nodes {
+		BinaryResolver{}.ForEach(n.Blob, func(hash common.Hash) {
+			if !hashes[hash] {
+				t.Errorf("Unknown child %x", hash)
+			}
+			children++
+		})
+	}
+	if children != len(nodes.Nodes)-1 || !hashes[root] {
+		t.Fatalf("Node linkage mismatch: %d children of %d nodes", children, len(nodes.Nodes))
+	}
+}
@@ -43,6 +43,10 @@ var ErrAlreadyProcessed = errors.New("already processed")
 // memory if the node was configured with a significant number of peers.
 const maxFetchesPerDepth = 16384
 
+// maxHotPaths is the maximum number of node path prefixes tracked for the
+// prioritized accounts. Beyond this, older prioritizations are forgotten.
+const maxHotPaths = 65536
+
 var (
 	// deletionGauge is the metric to track how many trie node deletions
 	// are performed in total during the sync process.
@@ -123,6 +127,9 @@ type nodeRequest struct {
 	parent   *nodeRequest // Parent state node referencing this entry
 	deps     int          // Number of dependencies before allowed to commit this node
 	callback LeafCallback // Callback to invoke if a leaf node it reached on this branch
+
+	hot      bool // Whether the request is queued for prioritized retrieval
+	fetching bool // Whether the request was already handed out for retrieval
 }
 
 // codeRequest represents a scheduled or already in-flight bytecode retrieval request.
@@ -235,6 +242,10 @@ type Sync struct {
 	codeReqs map[common.Hash]*codeRequest // Pending requests pertaining to a code hash
 	queue    *prque.Prque[int64, any]     // Priority queue with the pending requests
 	fetches  map[int]int                  // Number of active fetches per trie node depth
+
+	hot      map[string]struct{}         // Node path prefixes leading to prioritized accounts
+	hotQueue *prque.Prque[int64, string] // Priority queue with the pending prioritized node requests
+	promoted map[string]struct{}         // Prioritized node requests also present in the normal queue
 }
 
 // NewSync creates a new trie data download scheduler.
@@ -247,6 +258,9 @@ func NewSync(root common.Hash, database ethdb.KeyValueReader, callback LeafCallb
 		codeReqs: make(map[common.Hash]*codeRequest),
 		queue:    prque.New[int64, any](nil), // Ugh, can contain both string and hash, whyyy
 		fetches:  make(map[int]int),
+		hot:      make(map[string]struct{}),
+		hotQueue: prque.New[int64, string](nil),
+		promoted: make(map[string]struct{}),
 	}
 	ts.AddSubTrie(root, nil, common.Hash{}, nil, callback)
 	return ts
@@ -331,10 +345,32 @@ func (s *Sync) Missing(max int) ([]string, []common.Hash, []common.Hash) {
 		nodeHashes []common.Hash
 		codeHashes []common.Hash
 	)
+	// Hand out the prioritized node requests first, regardless of depth
+	for !s.hotQueue.Empty() && (max == 0 || len(nodeHashes) < max) {
+		path, prio := s.hotQueue.Pop()
+
+		req, ok := s.nodeReqs[path]
+		if !ok || req.fetching {
+			continue
+		}
+		req.fetching = true
+		s.fetches[int(prio>>56)]++
+
+		nodePaths = append(nodePaths, path)
+		nodeHashes = append(nodeHashes, req.hash)
+	}
 	for !s.queue.Empty() && (max == 0 || len(nodeHashes)+len(codeHashes) < max) {
 		// Retrieve the next item in line
 		item, prio := s.queue.Peek()
 
+		// If the item was already handed out via the prioritized queue, drop it
+		if path, ok := item.(string); ok {
+			if _, ok := s.promoted[path]; ok {
+				delete(s.promoted, path)
+				s.queue.Pop()
+				continue
+			}
+		}
 		// If we have too many already-pending tasks for this depth, throttle
 		depth := int(prio >> 56)
 		if s.fetches[depth] > maxFetchesPerDepth {
@@ -342,10 +378,10 @@ func (s *Sync) Missing(max int) ([]string, []common.Hash, []common.Hash) {
 		}
 		// Item is allowed to be scheduled, add it to the task list
 		s.queue.Pop()
-		s.fetches[depth]++
 
 		switch item := item.(type) {
 		case common.Hash:
+			s.fetches[depth]++
 			codeHashes = append(codeHashes, item)
 		case string:
 			req, ok := s.nodeReqs[item]
@@ -353,6 +389,12 @@ func (s *Sync) Missing(max int) ([]string, []common.Hash, []common.Hash) {
 				log.Error("Missing node request", "path", item)
 				continue // System very wrong, shouldn't happen
 			}
+			if req.fetching {
+				continue // Rescheduled while the prioritized request was in flight
+			}
+			req.fetching = true
+			s.fetches[depth]++
+
 			nodePaths = append(nodePaths, item)
 			nodeHashes = append(nodeHashes, req.hash)
 		}
@@ -457,6 +499,43 @@ func (s *Sync) Commit(dbw ethdb.Batch) error {
 	return nil
 }
 
+// Prioritize marks the trie nodes leading to the given accounts, as well as the
+// storage tries of the accounts, for retrieval ahead of any other missing node.
+// Node requests already scheduled along the account paths are promoted too.
+func (s *Sync) Prioritize(accounts []common.Hash) {
+	if len(s.hot)+len(accounts)*(2*common.HashLength+1) > maxHotPaths {
+		s.hot = make(map[string]struct{})
+	}
+	for _, account := range accounts {
+		path := keybytesToHex(account[:])
+		path = path[:len(path)-1] // strip the terminator
+
+		for i := 0; i <= len(path); i++ {
+			prefix := string(path[:i])
+			s.hot[prefix] = struct{}{}
+
+			if req, ok := s.nodeReqs[prefix]; ok && !req.hot && !req.fetching {
+				req.hot = true
+				s.hotQueue.Push(prefix, s.priority(req.path))
+				s.promoted[prefix] = struct{}{}
+			}
+		}
+	}
+}
+
+// isHot returns whether the node at the given path leads to or belongs to the
+// storage trie of a prioritized account.
+func (s *Sync) isHot(path []byte) bool {
+	if len(s.hot) == 0 {
+		return false
+	}
+	if len(path) > 2*common.HashLength {
+		path = path[:2*common.HashLength]
+	}
+	_, ok := s.hot[string(path)]
+	return ok
+}
+
 // MemSize returns an estimated size (in bytes) of the data held in the membatch.
 func (s *Sync) MemSize() uint64 {
 	return s.membatch.size
@@ -474,12 +553,25 @@ func (s *Sync) scheduleNodeRequest(req *nodeRequest) {
 	s.nodeReqs[string(req.path)] = req
 
 	// Schedule the request for future retrieval. This queue is shared
-	// by both node requests and code requests.
-	prio := int64(len(req.path)) << 56 // depth >= 128 will never happen, storage leaves will be included in their parents
-	for i := 0; i < 14 && i < len(req.path); i++ {
-		prio |= int64(15-req.path[i]) << (52 - i*4) // 15-nibble => lexicographic order
+	// by both node requests and code requests, unless the node belongs
+	// to a prioritized account.
+	if s.isHot(req.path) {
+		req.hot = true
+		s.hotQueue.Push(string(req.path), s.priority(req.path))
+		return
+	}
+	s.queue.Push(string(req.path), s.priority(req.path))
+}
+
+// priority calculates the retrieval priority of a state entry at the given path.
+// Deeper entries are retrieved first to allow committing and releasing memory,
+// entries of the same depth are retrieved in lexicographic order.
+func (s *Sync) priority(path []byte) int64 {
+	prio := int64(len(path)) << 56 // depth >= 128 will never happen, storage leaves will be included in their parents
+	for i := 0; i < 14 && i < len(path); i++ {
+		prio |= int64(15-path[i]) << (52 - i*4) // 15-nibble => lexicographic order
 	}
-	s.queue.Push(string(req.path), prio)
+	return prio
 }
 
 // scheduleCodeRequest inserts a new state retrieval request into the fetch queue. If there
@@ -495,11 +587,7 @@ func (s *Sync) scheduleCodeRequest(req *codeRequest) {
 
 	// Schedule the request for future retrieval. This queue is shared
 	// by both node requests and code requests.
-	prio := int64(len(req.path)) << 56 // depth >= 128 will never happen, storage leaves will be included in their parents
-	for i := 0; i < 14 && i < len(req.path); i++ {
-		prio |= int64(15-req.path[i]) << (52 - i*4) // 15-nibble => lexicographic order
-	}
-	s.queue.Push(req.hash, prio)
+	s.queue.Push(req.hash, s.priority(req.path))
 }
 
 // children retrieves all the missing children of a state trie entry for future
@@ -684,6 +684,79 @@ func testSyncOrdering(t *testing.T, scheme string) {
 		}
 	}
 }
+
+// Tests that the nodes leading to prioritized accounts are retrieved ahead of
+// anything else, including requests already queued before the prioritization.
+func TestSyncPrioritized(t *testing.T) {
+	testSyncPrioritized(t, rawdb.HashScheme)
+	testSyncPrioritized(t, rawdb.PathScheme)
+}
+
+func testSyncPrioritized(t *testing.T, scheme string) {
+	// Create a random trie to copy
+	_, srcDb, srcTrie, srcData := makeTestTrie(scheme)
+
+	// Create a destination trie and sync with the scheduler
+	var (
+		diskdb  = rawdb.NewMemoryDatabase()
+		sched   = NewSync(srcTrie.Hash(), diskdb, nil, srcDb.Scheme())
+		account = crypto.Keccak256Hash(common.LeftPadBytes([]byte{7, 42}, 32))
+		hot     = keybytesToHex(account[:])
+	)
+	hot = hot[:len(hot)-1]
+
+	reader, err := srcDb.Reader(srcTrie.Hash())
+	if err != nil {
+		t.Fatalf("State is not available %x", srcTrie.Hash())
+	}
+	var (
+		prioritized int  // Number of nodes retrieved along the account path
+		passed      bool // Whether a node off the account path was retrieved
+	)
+	for round := 0; ; round++ {
+		// Prioritize the account after the root was expanded, so that the
+		// first node along the path is promoted from the normal queue
+		if round == 1 {
+			sched.Prioritize([]common.Hash{account})
+		}
+		paths, nodes, _ := sched.Missing(1)
+		if len(paths) == 0 {
+			break
+		}
+		for i, path := range paths {
+			if round > 0 {
+				onPath := bytes.HasPrefix(hot, []byte(path))
+				switch {
+				case onPath && passed:
+					t.Errorf("node %x on the prioritized path retrieved late", path)
+				case onPath:
+					prioritized++
+				default:
+					passed = true
+				}
+			}
+			owner, inner := ResolvePath([]byte(path))
+			data, err := reader.Node(owner, inner, nodes[i])
+			if err != nil {
+				t.Fatalf("failed to retrieve node data for %x: %v", nodes[i], err)
+			}
+			if err := sched.ProcessNode(NodeSyncResult{path, data}); err != nil {
+				t.Fatalf("failed to process result %v", err)
+			}
+		}
+		batch := diskdb.NewBatch()
+		if err := sched.Commit(batch); err != nil {
+			t.Fatalf("failed to commit data: %v", err)
+		}
+		batch.Write()
+	}
+	if prioritized == 0 {
+		t.Errorf("no nodes retrieved along the prioritized path")
+	}
+	// Cross check that the two tries are in sync
+	checkTrieContents(t, diskdb, srcDb.Scheme(), srcTrie.Hash().Bytes(), srcData, false)
+}
+
 func syncWith(t *testing.T, root common.Hash, db ethdb.Database, srcDb *testDb) {
 	syncWithHookWriter(t, root, db, srcDb, nil)
 }
@@ -34,6 +34,7 @@ import (
 type Config struct {
 	Preimages bool           // Flag whether the preimage of node key is recorded
 	IsVerkle  bool           // Flag whether the db is holding a verkle tree
+	IsBinary  bool           // Flag whether the db is holding binary tries (experimental)
 	HashDB    *hashdb.Config // Configs for hash-based scheme
 	PathDB    *pathdb.Config // Configs for experimental path-based scheme
 }
@@ -107,6 +108,9 @@ func NewDatabase(diskdb ethdb.Database, config *Config) *Database {
 	if config.HashDB != nil && config.PathDB != nil {
 		log.Crit("Both 'hash' and 'path' mode are configured")
 	}
+	if config.PathDB != nil && config.IsBinary {
+		log.Crit("Binary tries are only supported in 'hash' mode")
+	}
 	if config.PathDB != nil {
 		db.backend = pathdb.New(diskdb, config.PathDB)
 	} else {
@@ -114,6 +118,8 @@ func NewDatabase(diskdb ethdb.Database, config *Config) *Database {
 		if config.IsVerkle {
 			// TODO define verkle resolver
 			log.Crit("Verkle node resolver is not defined")
+		} else if config.IsBinary {
+			resolver = trie.BinaryResolver{}
 		} else {
 			resolver = trie.MerkleResolver{}
 		}
@@ -336,3 +342,8 @@ func (db *Database) SetBufferSize(size int) error {
 func (db *Database) IsVerkle() bool {
 	return db.config.IsVerkle
 }
+
+// IsBinary returns the indicator if the database is holding binary tries.
+func (db *Database) IsBinary() bool {
+	return db.config.IsBinary
+}